@@ -0,0 +1,162 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"novastream/config"
+	"novastream/handlers"
+	"novastream/internal/database"
+	"novastream/internal/integration"
+	"novastream/internal/metrics"
+	"novastream/internal/pool"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterMetricsRoutes exposes /metrics in the Prometheus text format. Scrapers
+// must send server.metricsToken as a bearer token; until a token is set the
+// endpoint refuses every request.
+func RegisterMetricsRoutes(r *mux.Router, cfgManager *config.Manager) {
+	handler := metrics.Handler()
+	r.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		settings, err := cfgManager.Load()
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+		token := strings.TrimSpace(settings.Server.MetricsToken)
+		if token == "" {
+			http.Error(w, "metrics are disabled until server.metricsToken is set", http.StatusForbidden)
+			return
+		}
+		if !validMetricsToken(req, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})).Methods(http.MethodGet)
+}
+
+func validMetricsToken(r *http.Request, token string) bool {
+	provided := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if provided == "" {
+		provided = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+// RegisterMetricCollectors registers gauges that are sampled from live components on
+// every scrape. Any argument may be nil when that subsystem is not running.
+func RegisterMetricCollectors(hlsManager *handlers.HLSManager, poolManager pool.Manager, nzbSystem *integration.NzbSystem) {
+	metrics.NewGaugeFunc("novastream_hls_sessions_active", "Active HLS transcoding sessions.",
+		[]string{"type"}, func() []metrics.Sample {
+			if hlsManager == nil {
+				return nil
+			}
+			live, vod := hlsManager.SessionCounts()
+			return []metrics.Sample{
+				{LabelValues: []string{"live"}, Value: float64(live)},
+				{LabelValues: []string{"vod"}, Value: float64(vod)},
+			}
+		})
+
+	metrics.NewGaugeFunc("novastream_import_queue_items", "Import queue items by status.",
+		[]string{"status"}, func() []metrics.Sample {
+			if nzbSystem == nil {
+				return nil
+			}
+			counts, err := nzbSystem.QueueDepthByStatus()
+			if err != nil {
+				log.Printf("[metrics] failed to read import queue depth: %v", err)
+				return nil
+			}
+			statuses := []database.QueueStatus{
+				database.QueueStatusPending,
+				database.QueueStatusProcessing,
+				database.QueueStatusRetrying,
+				database.QueueStatusCompleted,
+				database.QueueStatusFailed,
+			}
+			samples := make([]metrics.Sample, 0, len(statuses))
+			for _, status := range statuses {
+				samples = append(samples, metrics.Sample{LabelValues: []string{string(status)}, Value: float64(counts[status])})
+			}
+			return samples
+		})
+
+	// Providers are labelled by host only, since the pool's provider IDs carry the account
+	// username. Accounts on the same host and tier are summed into one series.
+	providerStats := func() []pool.ProviderStats {
+		if poolManager == nil {
+			return nil
+		}
+		var merged []pool.ProviderStats
+		index := make(map[[2]string]int)
+		for _, p := range poolManager.ProviderStats() {
+			key := [2]string{p.Host, p.Tier}
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged)
+				merged = append(merged, pool.ProviderStats{Host: p.Host, Tier: p.Tier})
+				i = len(merged) - 1
+			}
+			merged[i].MaxConnections += p.MaxConnections
+			merged[i].OpenConnections += p.OpenConnections
+			merged[i].IdleConnections += p.IdleConnections
+			merged[i].ActiveConnections += p.ActiveConnections
+			merged[i].Requests += p.Requests
+			merged[i].BytesDownloaded += p.BytesDownloaded
+			merged[i].ArticleErrors += p.ArticleErrors
+		}
+		return merged
+	}
+	metrics.NewGaugeFunc("novastream_nntp_connections", "NNTP connections per provider by state (open, idle, active, max).",
+		[]string{"provider", "tier", "state"}, func() []metrics.Sample {
+			var samples []metrics.Sample
+			for _, p := range providerStats() {
+				samples = append(samples,
+					metrics.Sample{LabelValues: []string{p.Host, p.Tier, "open"}, Value: float64(p.OpenConnections)},
+					metrics.Sample{LabelValues: []string{p.Host, p.Tier, "idle"}, Value: float64(p.IdleConnections)},
+					metrics.Sample{LabelValues: []string{p.Host, p.Tier, "active"}, Value: float64(p.ActiveConnections)},
+					metrics.Sample{LabelValues: []string{p.Host, p.Tier, "max"}, Value: float64(p.MaxConnections)},
+				)
+			}
+			return samples
+		})
+	metrics.NewCounterFunc("novastream_nntp_requests_total", "NNTP connection requests served per provider.",
+		[]string{"provider", "tier"}, func() []metrics.Sample {
+			var samples []metrics.Sample
+			for _, p := range providerStats() {
+				samples = append(samples, metrics.Sample{LabelValues: []string{p.Host, p.Tier}, Value: float64(p.Requests)})
+			}
+			return samples
+		})
+	metrics.NewCounterFunc("novastream_nntp_downloaded_bytes_total", "Decoded article bytes downloaded per provider.",
+		[]string{"provider", "tier"}, func() []metrics.Sample {
+			var samples []metrics.Sample
+			for _, p := range providerStats() {
+				samples = append(samples, metrics.Sample{LabelValues: []string{p.Host, p.Tier}, Value: float64(p.BytesDownloaded)})
+			}
+			return samples
+		})
+	metrics.NewCounterFunc("novastream_nntp_article_errors_total", "Article fetches that failed per provider (missing, corrupt or interrupted).",
+		[]string{"provider"}, func() []metrics.Sample {
+			perHost := make(map[string]int64)
+			var hosts []string
+			for _, p := range providerStats() {
+				if _, ok := perHost[p.Host]; !ok {
+					hosts = append(hosts, p.Host)
+				}
+				perHost[p.Host] += p.ArticleErrors
+			}
+			samples := make([]metrics.Sample, 0, len(hosts))
+			for _, host := range hosts {
+				samples = append(samples, metrics.Sample{LabelValues: []string{host}, Value: float64(perHost[host])})
+			}
+			return samples
+		})
+}
@@ -218,6 +218,10 @@ func Register(
 	protected.HandleFunc("/live/categories", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/live/cache/clear", liveHandler.ClearCache).Methods(http.MethodPost)
 	protected.HandleFunc("/live/cache/clear", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/epg/now", liveHandler.GetEPGNowNext).Methods(http.MethodGet)
+	protected.HandleFunc("/live/epg/now", handleOptions).Methods(http.MethodOptions)
+	protected.HandleFunc("/live/epg/grid", liveHandler.GetEPGGrid).Methods(http.MethodGet)
+	protected.HandleFunc("/live/epg/grid", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/live/stream", liveHandler.StreamChannel).Methods(http.MethodGet, http.MethodHead)
 	protected.HandleFunc("/live/stream", handleOptions).Methods(http.MethodOptions)
 	protected.HandleFunc("/live/hls/start", videoHandler.StartLiveHLSSession).Methods(http.MethodGet, http.MethodOptions)
@@ -456,3 +460,36 @@ func RegisterTraktRoutes(r *mux.Router, traktHandler *handlers.TraktAccountsHand
 	api.HandleFunc("/accounts/{accountID}/history", traktHandler.GetHistory).Methods(http.MethodGet)
 	api.HandleFunc("/accounts/{accountID}/history", handleOptions).Methods(http.MethodOptions)
 }
+
+// RegisterReleaseBlocklistRoutes registers the failed release blocklist endpoints. Any
+// account can view the blocklist; only the master account can clear it.
+func RegisterReleaseBlocklistRoutes(r *mux.Router, blocklistHandler *handlers.ReleaseBlocklistHandler, sessionsSvc *sessions.Service) {
+	api := r.PathPrefix("/api/playback/blocklist").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+
+	api.HandleFunc("", blocklistHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("", handleOptions).Methods(http.MethodOptions)
+
+	masterOnly := api.PathPrefix("").Subrouter()
+	masterOnly.Use(MasterOnlyMiddleware())
+	masterOnly.HandleFunc("", blocklistHandler.Delete).Methods(http.MethodDelete)
+}
+
+// RegisterDVRRoutes registers Live TV recording endpoints under the profile routes.
+func RegisterDVRRoutes(r *mux.Router, dvrHandler *handlers.DVRHandler, sessionsSvc *sessions.Service, usersSvc *users.Service) {
+	api := r.PathPrefix("/api/users").Subrouter()
+	api.Use(corsMiddleware)
+	api.Use(AccountAuthMiddleware(sessionsSvc))
+	api.Use(ProfileOwnershipMiddleware(usersSvc))
+
+	api.HandleFunc("/{userID}/recordings", dvrHandler.List).Methods(http.MethodGet)
+	api.HandleFunc("/{userID}/recordings", dvrHandler.Schedule).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/recordings", handleOptions).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/recordings/{recordingID}", dvrHandler.Delete).Methods(http.MethodDelete)
+	api.HandleFunc("/{userID}/recordings/{recordingID}", handleOptions).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/recordings/{recordingID}/stop", dvrHandler.Stop).Methods(http.MethodPost)
+	api.HandleFunc("/{userID}/recordings/{recordingID}/stop", handleOptions).Methods(http.MethodOptions)
+	api.HandleFunc("/{userID}/recordings/{recordingID}/stream", dvrHandler.Stream).Methods(http.MethodGet, http.MethodHead)
+	api.HandleFunc("/{userID}/recordings/{recordingID}/stream", handleOptions).Methods(http.MethodOptions)
+}
@@ -131,10 +131,11 @@ const (
 	defaultMaxConnectionTTLSeconds = 900
 )
 
+// Providers are returned primaries first, ordered by priority, with backups flagged via IsBackupProvider.
 func ToNNTPProviders(settings []UsenetSettings) []nntppool.UsenetProviderConfig {
 	providers := make([]nntppool.UsenetProviderConfig, 0, len(settings))
 
-	for _, s := range settings {
+	for _, s := range SortUsenetProviders(settings) {
 		// Skip disabled providers or those without a host
 		if !s.Enabled || s.Host == "" {
 			continue
@@ -149,6 +150,7 @@ func ToNNTPProviders(settings []UsenetSettings) []nntppool.UsenetProviderConfig
 			TLS:                            s.SSL,
 			MaxConnectionIdleTimeInSeconds: defaultMaxConnectionIdleTimeSeconds,
 			MaxConnectionTTLInSeconds:      defaultMaxConnectionTTLSeconds,
+			IsBackupProvider:               s.IsBackup(),
 		})
 	}
 
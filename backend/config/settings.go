@@ -8,46 +8,58 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 // Settings represents the application configuration persisted to disk.
 type Settings struct {
-	Server          ServerSettings         `json:"server"`
-	Usenet          []UsenetSettings       `json:"usenet"`
-	Indexers        []IndexerConfig        `json:"indexers"`
-	TorrentScrapers []TorrentScraperConfig `json:"torrentScrapers"`
-	Metadata        MetadataSettings       `json:"metadata"`
-	Cache           CacheSettings          `json:"cache"`
-	WebDAV          WebDAVSettings         `json:"webdav"`
-	Database        DatabaseSettings       `json:"database"`
-	Streaming       StreamingSettings      `json:"streaming"`
-	Import          ImportSettings         `json:"import"`
-	SABnzbd         SABnzbdSettings        `json:"sabnzbd"`
-	AltMount        *AltMountSettings      `json:"altmount,omitempty"`
-	Transmux        TransmuxSettings       `json:"transmux"`
-	Playback        PlaybackSettings       `json:"playback"`
-	Live            LiveSettings           `json:"live"`
-	HomeShelves     HomeShelvesSettings    `json:"homeShelves"`
-	Filtering       FilterSettings         `json:"filtering"`
-	UI              UISettings             `json:"ui"`
-	Display         DisplaySettings        `json:"display"`
-	Subtitles       SubtitleSettings       `json:"subtitles"`
-	MDBList         MDBListSettings        `json:"mdblist"`
-	Trakt           TraktSettings          `json:"trakt,omitempty"`
-	Plex            PlexSettings           `json:"plex,omitempty"`
-	Log             LogConfig              `json:"log"`
-	ScheduledTasks  ScheduledTasksSettings `json:"scheduledTasks,omitempty"`
-	Network         NetworkSettings        `json:"network,omitempty"`
-	Ranking         RankingSettings        `json:"ranking,omitempty"`
+	Server           ServerSettings           `json:"server"`
+	Usenet           []UsenetSettings         `json:"usenet"`
+	Indexers         []IndexerConfig          `json:"indexers"`
+	TorrentScrapers  []TorrentScraperConfig   `json:"torrentScrapers"`
+	Metadata         MetadataSettings         `json:"metadata"`
+	Cache            CacheSettings            `json:"cache"`
+	WebDAV           WebDAVSettings           `json:"webdav"`
+	Database         DatabaseSettings         `json:"database"`
+	Streaming        StreamingSettings        `json:"streaming"`
+	Import           ImportSettings           `json:"import"`
+	SABnzbd          SABnzbdSettings          `json:"sabnzbd"`
+	AltMount         *AltMountSettings        `json:"altmount,omitempty"`
+	Transmux         TransmuxSettings         `json:"transmux"`
+	Playback         PlaybackSettings         `json:"playback"`
+	Live             LiveSettings             `json:"live"`
+	HomeShelves      HomeShelvesSettings      `json:"homeShelves"`
+	Filtering        FilterSettings           `json:"filtering"`
+	UI               UISettings               `json:"ui"`
+	Display          DisplaySettings          `json:"display"`
+	Subtitles        SubtitleSettings         `json:"subtitles"`
+	MDBList          MDBListSettings          `json:"mdblist"`
+	Trakt            TraktSettings            `json:"trakt,omitempty"`
+	Plex             PlexSettings             `json:"plex,omitempty"`
+	Log              LogConfig                `json:"log"`
+	ScheduledTasks   ScheduledTasksSettings   `json:"scheduledTasks,omitempty"`
+	Network          NetworkSettings          `json:"network,omitempty"`
+	Ranking          RankingSettings          `json:"ranking,omitempty"`
+	Webhooks         []WebhookSettings        `json:"webhooks"`
+	StreamHistory    StreamHistorySettings    `json:"streamHistory"`
+	ReleaseBlocklist ReleaseBlocklistSettings `json:"releaseBlocklist"`
 }
 
 type ServerSettings struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	MetricsToken string `json:"metricsToken,omitempty"` // Bearer token required by /metrics (empty = endpoint disabled)
 }
 
+// Usenet provider tiers. Backup providers are passed to nntppool with
+// IsBackupProvider set, so they only serve articles a primary reported missing (430).
+const (
+	UsenetTierPrimary = "primary"
+	UsenetTierBackup  = "backup"
+)
+
 type UsenetSettings struct {
 	Name        string `json:"name"`
 	Host        string `json:"host"`
@@ -57,6 +69,26 @@ type UsenetSettings struct {
 	Password    string `json:"password"`
 	Connections int    `json:"connections"`
 	Enabled     bool   `json:"enabled"`
+	Tier        string `json:"tier,omitempty"`     // "primary" (default) or "backup"
+	Priority    int    `json:"priority,omitempty"` // Lower values are tried first within a tier
+}
+
+// IsBackup reports whether the provider is a backup/fill server.
+func (u UsenetSettings) IsBackup() bool {
+	return strings.EqualFold(strings.TrimSpace(u.Tier), UsenetTierBackup)
+}
+
+// SortUsenetProviders returns providers ordered by tier (primaries first) and then priority.
+// The input order is preserved for providers with the same tier and priority.
+func SortUsenetProviders(providers []UsenetSettings) []UsenetSettings {
+	sorted := append([]UsenetSettings(nil), providers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsBackup() != sorted[j].IsBackup() {
+			return !sorted[i].IsBackup()
+		}
+		return sorted[i].Priority < sorted[j].Priority
+	})
+	return sorted
 }
 
 type IndexerConfig struct {
@@ -66,12 +98,32 @@ type IndexerConfig struct {
 	Type       string `json:"type"`       // newznab | torznab
 	Categories string `json:"categories"` // Comma-separated newznab category IDs (e.g., "2000,2010,2020" for movies, "5000,5010,5020" for TV)
 	Enabled    bool   `json:"enabled"`
+	APILimit   int    `json:"apiLimit"`  // Searches allowed per day (0 = no limit)
+	GrabLimit  int    `json:"grabLimit"` // NZB downloads allowed per day (0 = no limit)
+}
+
+// Webhook payload formats.
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatDiscord = "discord"
+	WebhookFormatNtfy    = "ntfy"
+	WebhookFormatGotify  = "gotify"
+)
+
+type WebhookSettings struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Format   string   `json:"format"`   // json | discord | ntfy | gotify
+	Events   []string `json:"events"`   // Event types to send (empty = all)
+	Template string   `json:"template"` // Optional Go text/template for the request body
+	Token    string   `json:"token"`    // Bearer token (json, ntfy) or application token (gotify)
+	Enabled  bool     `json:"enabled"`
 }
 
 type TorrentScraperConfig struct {
-	Name    string            `json:"name"`    // "Torrentio", "Prowlarr", "Jackett", "Zilean", "AIOStreams", "Nyaa"
-	Type    string            `json:"type"`    // "torrentio", "prowlarr", "jackett", "zilean", "aiostreams", "nyaa"
-	URL     string            `json:"url"`     // For Prowlarr/Jackett/Zilean/AIOStreams/Nyaa (full URL with config token)
+	Name    string            `json:"name"`    // "Torrentio", "Prowlarr", "Jackett", "Zilean", "AIOStreams", "Nyaa", "Comet"
+	Type    string            `json:"type"`    // "torrentio", "prowlarr", "jackett", "zilean", "aiostreams", "nyaa", "stremio"
+	URL     string            `json:"url"`     // For Prowlarr/Jackett/Zilean/AIOStreams/Nyaa (full URL with config token), Stremio addons (manifest URL)
 	APIKey  string            `json:"apiKey"`  // For Prowlarr/Jackett
 	Options string            `json:"options"` // For Torrentio: URL path options (e.g., "sort=qualitysize|qualityfilter=480p,scr,cam")
 	Enabled bool              `json:"enabled"`
@@ -130,6 +182,8 @@ type StreamingSettings struct {
 	MultiProviderMode           MultiProviderMode        `json:"multiProviderMode,omitempty"`     // How to select provider when multiple are enabled
 	UsenetResolutionTimeoutSec  int                      `json:"usenetResolutionTimeoutSec"`      // Timeout for usenet content resolution in seconds (0 = no limit)
 	IndexerTimeoutSec           int                      `json:"indexerTimeoutSec"`               // Timeout for indexer/scraper searches in seconds (default: 5)
+	CacheIndexCachedTTLHours    int                      `json:"cacheIndexCachedTTLHours"`        // How long a known-cached torrent is trusted without re-checking the provider (default: 24)
+	CacheIndexUncachedTTLHours  int                      `json:"cacheIndexUncachedTTLHours"`      // How long a known-uncached torrent is trusted without re-checking the provider (default: 6)
 }
 
 type StreamingServicePriority string
@@ -176,11 +230,18 @@ type ImportSettings struct {
 	SkipHealthCheck                bool `json:"skipHealthCheck"` // Skip segment health check for faster playback
 }
 
-// SABnzbdSettings defines SABnzbd fallback configuration
+// SABnzbdSettings defines SABnzbd fallback configuration and the SABnzbd-compatible
+// download API that Sonarr and Radarr use to hand NZBs to the importer.
 type SABnzbdSettings struct {
 	Enabled        *bool  `json:"enabled"`
 	FallbackHost   string `json:"fallbackHost"`
 	FallbackAPIKey string `json:"fallbackApiKey"`
+	// APIKey enables the download API at /sabnzbd/api when set
+	APIKey string `json:"apiKey"`
+	// CompleteDir is where the WebDAV share is mounted on the Sonarr/Radarr host;
+	// completed jobs are reported below it
+	CompleteDir string   `json:"completeDir"`
+	Categories  []string `json:"categories"`
 }
 
 // AltMountSettings captures legacy AltMount configuration and is ignored by the
@@ -203,6 +264,26 @@ type PlaybackSettings struct {
 	ForceAACTranscoding       bool    `json:"forceAacTranscoding"`       // Force transcoding of AC3/EAC3/DTS audio to AAC for Bluetooth compatibility
 }
 
+// StreamHistorySettings controls the persisted playback session log.
+type StreamHistorySettings struct {
+	RetentionDays int `json:"retentionDays"` // Sessions older than this are pruned (default 30)
+}
+
+// ReleaseBlocklistSettings controls how releases that failed playback are treated in searches.
+type ReleaseBlocklistSettings struct {
+	Mode       ReleaseBlocklistMode `json:"mode"`       // What searches do with failed releases (default demote)
+	ExpiryDays int                  `json:"expiryDays"` // Days after the last failure an entry is forgotten (default 14)
+}
+
+// ReleaseBlocklistMode determines what happens to blocklisted releases in search results.
+type ReleaseBlocklistMode string
+
+const (
+	ReleaseBlocklistModeDemote ReleaseBlocklistMode = "demote" // Rank failed releases below all others
+	ReleaseBlocklistModeDrop   ReleaseBlocklistMode = "drop"   // Remove failed releases from results
+	ReleaseBlocklistModeOff    ReleaseBlocklistMode = "off"    // Record failures but leave results untouched
+)
+
 // LiveTVFilterSettings controls backend-side filtering for Live TV channels.
 type LiveTVFilterSettings struct {
 	EnabledCategories []string `json:"enabledCategories"` // Only show channels in these categories (empty = show all)
@@ -216,10 +297,13 @@ type LiveSettings struct {
 	XtreamHost            string               `json:"xtreamHost"`            // Xtream Codes server URL (e.g., "http://example.com:8080")
 	XtreamUsername        string               `json:"xtreamUsername"`        // Xtream Codes username
 	XtreamPassword        string               `json:"xtreamPassword"`        // Xtream Codes password
+	EPGURL                string               `json:"epgUrl"`                // XMLTV guide URL (empty = Xtream xmltv.php when in xtream mode)
 	PlaylistCacheTTLHours int                  `json:"playlistCacheTtlHours"`
 	ProbeSizeMB           int                  `json:"probeSizeMb"`           // FFmpeg probesize in MB (0 = default ~5MB)
 	AnalyzeDurationSec    int                  `json:"analyzeDurationSec"`    // FFmpeg analyzeduration in seconds (0 = default ~5s)
 	LowLatency            bool                 `json:"lowLatency"`            // Enable low-latency mode (nobuffer + low_delay flags)
+	MaxConnections        int                  `json:"maxConnections"`        // Provider connection limit for recordings (0 = unlimited, or as reported by Xtream)
+	RecordingsDirectory   string               `json:"recordingsDirectory"`   // Where DVR recordings are stored (empty = <cache>/recordings)
 	Filtering             LiveTVFilterSettings `json:"filtering"`             // Backend-side channel filtering
 }
 
@@ -234,6 +318,20 @@ func (ls *LiveSettings) GetEffectivePlaylistURL() string {
 	return ls.PlaylistURL
 }
 
+// GetEffectiveEPGURL returns the XMLTV guide URL. An explicit EPG URL wins; otherwise
+// Xtream Codes mode falls back to the provider's xmltv.php endpoint.
+func (ls *LiveSettings) GetEffectiveEPGURL() string {
+	if strings.TrimSpace(ls.EPGURL) != "" {
+		return strings.TrimSpace(ls.EPGURL)
+	}
+	if ls.Mode == "xtream" && ls.XtreamHost != "" && ls.XtreamUsername != "" && ls.XtreamPassword != "" {
+		host := strings.TrimRight(ls.XtreamHost, "/")
+		return fmt.Sprintf("%s/xmltv.php?username=%s&password=%s",
+			host, url.QueryEscape(ls.XtreamUsername), url.QueryEscape(ls.XtreamPassword))
+	}
+	return ""
+}
+
 // ShelfConfig represents a configurable home screen shelf.
 type ShelfConfig struct {
 	ID             string `json:"id"`                       // Unique identifier (e.g., "continue-watching", "watchlist", "trending-movies")
@@ -307,6 +405,7 @@ type DisplaySettings struct {
 
 // SubtitleSettings defines subtitle provider configuration.
 type SubtitleSettings struct {
+	OpenSubtitlesAPIKey   string `json:"openSubtitlesApiKey"`
 	OpenSubtitlesUsername string `json:"openSubtitlesUsername"`
 	OpenSubtitlesPassword string `json:"openSubtitlesPassword"`
 }
@@ -436,6 +535,8 @@ type ScheduledTaskType string
 const (
 	ScheduledTaskTypePlexWatchlistSync ScheduledTaskType = "plex_watchlist_sync"
 	ScheduledTaskTypeTraktListSync     ScheduledTaskType = "trakt_list_sync"
+	ScheduledTaskTypeEPGRefresh        ScheduledTaskType = "epg_refresh"
+	ScheduledTaskTypeDebridCleanup     ScheduledTaskType = "debrid_cleanup"
 )
 
 // ScheduledTaskFrequency defines how often a task runs
@@ -512,9 +613,12 @@ type RankingCriterionID string
 
 const (
 	RankingServicePriority RankingCriterionID = "service-priority"
+	RankingCustomFormats   RankingCriterionID = "custom-formats"
 	RankingPreferredTerms  RankingCriterionID = "preferred-terms"
 	RankingResolution      RankingCriterionID = "resolution"
 	RankingHDR             RankingCriterionID = "hdr"
+	RankingAudio           RankingCriterionID = "audio"
+	RankingVideoCodec      RankingCriterionID = "video-codec"
 	RankingLanguage        RankingCriterionID = "language"
 	RankingSize            RankingCriterionID = "size"
 )
@@ -527,21 +631,113 @@ type RankingCriterion struct {
 	Order   int                `json:"order"`
 }
 
-// RankingSettings holds the ordered list of ranking criteria.
+// RankingSettings holds the ordered list of ranking criteria, the custom formats scored by
+// the custom-formats criterion and the preference orders of the audio and video codec criteria.
 type RankingSettings struct {
-	Criteria []RankingCriterion `json:"criteria"`
+	Criteria             []RankingCriterion `json:"criteria"`
+	CustomFormats        []CustomFormat     `json:"customFormats"`
+	AudioPreference      []string           `json:"audioPreference"`      // Audio formats, most preferred first (Atmos, TrueHD, DTS Lossless, DDP, ...)
+	VideoCodecPreference []string           `json:"videoCodecPreference"` // Video codecs, most preferred first (hevc, avc, av1, ...)
+}
+
+// CustomFormat is a user-defined release format in the style of Sonarr/Radarr custom formats.
+// A release matches when every condition that is set matches; patterns are case-insensitive
+// regular expressions. The scores of all matching formats add up to the release's custom
+// format score, so negative scores push unwanted releases down.
+type CustomFormat struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Enabled      bool   `json:"enabled"`
+	Score        int    `json:"score"`
+	ReleaseTitle string `json:"releaseTitle,omitempty"` // Matched against the full release title
+	ReleaseGroup string `json:"releaseGroup,omitempty"` // Matched against the parsed release group
+	Codec        string `json:"codec,omitempty"`        // Matched against the parsed codec (avc, hevc, av1, xvid, mpeg2)
+	Source       string `json:"source,omitempty"`       // Matched against the parsed source (WEB-DL, WEBRip, BluRay, BluRay REMUX, HDTV, ...)
+	AudioFormat  string `json:"audioFormat,omitempty"`  // Matched against each parsed audio format (TrueHD, Atmos, DTS Lossless, DDP, AAC, ...)
+	Resolution   string `json:"resolution,omitempty"`   // Matched against the parsed resolution (2160p, 1080p, ...)
+	HDR          string `json:"hdr,omitempty"`          // Matched against each parsed HDR format (DV, HDR10+, HDR)
+	Remux        string `json:"remux,omitempty"`        // "only" or "exclude" to require or reject remuxes
 }
 
+const (
+	CustomFormatRemuxOnly    = "only"
+	CustomFormatRemuxExclude = "exclude"
+)
+
 // DefaultRankingCriteria returns the default ranking criteria in their default order.
 func DefaultRankingCriteria() []RankingCriterion {
 	return []RankingCriterion{
 		{ID: RankingServicePriority, Name: "Service Priority", Enabled: true, Order: 0},
-		{ID: RankingPreferredTerms, Name: "Preferred Terms", Enabled: true, Order: 1},
-		{ID: RankingResolution, Name: "Resolution", Enabled: true, Order: 2},
-		{ID: RankingHDR, Name: "HDR/Dolby Vision", Enabled: true, Order: 3},
-		{ID: RankingLanguage, Name: "Language", Enabled: true, Order: 4},
-		{ID: RankingSize, Name: "File Size", Enabled: true, Order: 5},
+		{ID: RankingCustomFormats, Name: "Custom Formats", Enabled: true, Order: 1},
+		{ID: RankingPreferredTerms, Name: "Preferred Terms", Enabled: true, Order: 2},
+		{ID: RankingResolution, Name: "Resolution", Enabled: true, Order: 3},
+		{ID: RankingHDR, Name: "HDR/Dolby Vision", Enabled: true, Order: 4},
+		{ID: RankingAudio, Name: "Audio Format", Enabled: false, Order: 5},
+		{ID: RankingVideoCodec, Name: "Video Codec", Enabled: false, Order: 6},
+		{ID: RankingLanguage, Name: "Language", Enabled: true, Order: 7},
+		{ID: RankingSize, Name: "File Size", Enabled: true, Order: 8},
+	}
+}
+
+// DefaultAudioPreference returns the default audio format order, lossless object-based
+// audio first. Values are the audio formats parsed from release titles.
+func DefaultAudioPreference() []string {
+	return []string{"Atmos", "TrueHD", "DTS Lossless", "DDP", "DTS Lossy", "DD", "FLAC", "AAC", "OPUS", "MP3"}
+}
+
+// DefaultVideoCodecPreference returns the default video codec order. Values are the codecs
+// parsed from release titles.
+func DefaultVideoCodecPreference() []string {
+	return []string{"hevc", "avc", "av1", "mpeg2", "xvid"}
+}
+
+// backfillRankingCriteria adds criteria introduced after the settings were saved, at their
+// default position relative to the criterion before them, keeping the user's order of the rest.
+func backfillRankingCriteria(criteria []RankingCriterion) []RankingCriterion {
+	present := make(map[RankingCriterionID]bool, len(criteria))
+	for _, c := range criteria {
+		present[c.ID] = true
+	}
+
+	defaults := DefaultRankingCriteria()
+	for i, def := range defaults {
+		if present[def.ID] {
+			continue
+		}
+		order := 0
+		if i > 0 {
+			for _, c := range criteria {
+				if c.ID == defaults[i-1].ID {
+					order = c.Order + 1
+				}
+			}
+		}
+		for j := range criteria {
+			if criteria[j].Order >= order {
+				criteria[j].Order++
+			}
+		}
+		def.Order = order
+		criteria = append(criteria, def)
+		present[def.ID] = true
 	}
+	return criteria
+}
+
+// customFormatID derives a stable ID for a custom format from its name.
+func customFormatID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if b.Len() > 0 && !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
 }
 
 // DefaultSettings returns sane defaults for a fresh install.
@@ -558,9 +754,9 @@ func DefaultSettings() Settings {
 		Cache:     CacheSettings{Directory: "cache", MetadataTTLHours: 24},
 		WebDAV:    WebDAVSettings{Enabled: true, Prefix: "/webdav", Username: "novastream", Password: ""},
 		Database:  DatabaseSettings{Path: "cache/queue.db"},
-		Streaming: StreamingSettings{MaxDownloadWorkers: 15, MaxCacheSizeMB: 100, ServiceMode: StreamingServiceModeUsenet, ServicePriority: StreamingServicePriorityNone, DebridProviders: []DebridProviderSettings{}, UsenetResolutionTimeoutSec: 0, IndexerTimeoutSec: 5},
+		Streaming: StreamingSettings{MaxDownloadWorkers: 15, MaxCacheSizeMB: 100, ServiceMode: StreamingServiceModeUsenet, ServicePriority: StreamingServicePriorityNone, DebridProviders: []DebridProviderSettings{}, UsenetResolutionTimeoutSec: 0, IndexerTimeoutSec: 5, CacheIndexCachedTTLHours: 24, CacheIndexUncachedTTLHours: 6},
 		Import:    ImportSettings{QueueProcessingIntervalSeconds: 1, RarMaxWorkers: 40, RarMaxCacheSizeMB: 128, RarEnableMemoryPreload: true, RarMaxMemoryGB: 8},
-		SABnzbd:   SABnzbdSettings{Enabled: &sabnzbdEnabled, FallbackHost: "", FallbackAPIKey: ""},
+		SABnzbd:   SABnzbdSettings{Enabled: &sabnzbdEnabled, FallbackHost: "", FallbackAPIKey: "", Categories: []string{"movies", "tv"}},
 		AltMount:  nil,
 		Transmux:  TransmuxSettings{Enabled: true, FFmpegPath: "ffmpeg", FFprobePath: "ffprobe", HLSTempDirectory: "/tmp/novastream-hls"},
 		Playback:  PlaybackSettings{PreferredPlayer: "native", UseLoadingScreen: false, SubtitleSize: 1.0, SeekForwardSeconds: 30, SeekBackwardSeconds: 10},
@@ -587,6 +783,7 @@ func DefaultSettings() Settings {
 			BadgeVisibility: []string{"watchProgress"},
 		},
 		Subtitles: SubtitleSettings{
+			OpenSubtitlesAPIKey:   "",
 			OpenSubtitlesUsername: "",
 			OpenSubtitlesPassword: "",
 		},
@@ -615,8 +812,14 @@ func DefaultSettings() Settings {
 			RemoteBackendUrl: "",
 		},
 		Ranking: RankingSettings{
-			Criteria: DefaultRankingCriteria(),
+			Criteria:             DefaultRankingCriteria(),
+			CustomFormats:        []CustomFormat{},
+			AudioPreference:      DefaultAudioPreference(),
+			VideoCodecPreference: DefaultVideoCodecPreference(),
 		},
+		Webhooks:         []WebhookSettings{},
+		StreamHistory:    StreamHistorySettings{RetentionDays: 30},
+		ReleaseBlocklist: ReleaseBlocklistSettings{Mode: ReleaseBlocklistModeDemote, ExpiryDays: 14},
 	}
 }
 
@@ -849,6 +1052,7 @@ func (m *Manager) Load() (Settings, error) {
 			{Name: "Real Debrid", Provider: "realdebrid"},
 			{Name: "Torbox", Provider: "torbox"},
 			{Name: "AllDebrid", Provider: "alldebrid"},
+			{Name: "Premiumize", Provider: "premiumize"},
 		}
 	}
 	// Backfill MultiProviderMode if not set (default to fastest for best UX)
@@ -859,6 +1063,13 @@ func (m *Manager) Load() (Settings, error) {
 	if s.Streaming.IndexerTimeoutSec <= 0 {
 		s.Streaming.IndexerTimeoutSec = 5
 	}
+	// Backfill debrid cache index TTLs
+	if s.Streaming.CacheIndexCachedTTLHours <= 0 {
+		s.Streaming.CacheIndexCachedTTLHours = 24
+	}
+	if s.Streaming.CacheIndexUncachedTTLHours <= 0 {
+		s.Streaming.CacheIndexUncachedTTLHours = 6
+	}
 
 	// Backfill Import settings
 	if s.Import.QueueProcessingIntervalSeconds == 0 {
@@ -879,6 +1090,9 @@ func (m *Manager) Load() (Settings, error) {
 		sabnzbdEnabled := false
 		s.SABnzbd.Enabled = &sabnzbdEnabled
 	}
+	if s.SABnzbd.Categories == nil {
+		s.SABnzbd.Categories = []string{"movies", "tv"}
+	}
 
 	// Backfill Live settings
 	if s.Live.PlaylistCacheTTLHours == 0 {
@@ -955,6 +1169,45 @@ func (m *Manager) Load() (Settings, error) {
 	// Backfill Ranking settings
 	if len(s.Ranking.Criteria) == 0 {
 		s.Ranking.Criteria = DefaultRankingCriteria()
+	} else {
+		s.Ranking.Criteria = backfillRankingCriteria(s.Ranking.Criteria)
+	}
+	if s.Ranking.CustomFormats == nil {
+		s.Ranking.CustomFormats = []CustomFormat{}
+	}
+	for i := range s.Ranking.CustomFormats {
+		if strings.TrimSpace(s.Ranking.CustomFormats[i].ID) == "" {
+			s.Ranking.CustomFormats[i].ID = customFormatID(s.Ranking.CustomFormats[i].Name)
+		}
+	}
+	if s.Ranking.AudioPreference == nil {
+		s.Ranking.AudioPreference = DefaultAudioPreference()
+	}
+	if s.Ranking.VideoCodecPreference == nil {
+		s.Ranking.VideoCodecPreference = DefaultVideoCodecPreference()
+	}
+
+	// Backfill Webhooks so the admin UI always receives an array
+	if s.Webhooks == nil {
+		s.Webhooks = []WebhookSettings{}
+	}
+	for i := range s.Webhooks {
+		if strings.TrimSpace(s.Webhooks[i].Format) == "" {
+			s.Webhooks[i].Format = WebhookFormatJSON
+		}
+	}
+
+	// Backfill stream history retention
+	if s.StreamHistory.RetentionDays <= 0 {
+		s.StreamHistory.RetentionDays = 30
+	}
+
+	// Backfill failed release blocklist policy
+	if s.ReleaseBlocklist.Mode == "" {
+		s.ReleaseBlocklist.Mode = ReleaseBlocklistModeDemote
+	}
+	if s.ReleaseBlocklist.ExpiryDays <= 0 {
+		s.ReleaseBlocklist.ExpiryDays = 14
 	}
 
 	// Legacy AltMount configuration is ignored going forward.
@@ -8,6 +8,7 @@ import (
 	"embed"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -15,7 +16,6 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -24,13 +24,17 @@ import (
 
 	"novastream/config"
 	"novastream/internal/auth"
+	"novastream/internal/events"
+	"novastream/internal/pool"
 	"novastream/models"
 	"novastream/services/accounts"
+	content_restrictions "novastream/services/content_restrictions"
 	"novastream/services/debrid"
 	"novastream/services/history"
 	"novastream/services/invitations"
 	"novastream/services/plex"
 	"novastream/services/sessions"
+	"novastream/services/subtitles"
 	"novastream/services/trakt"
 	"novastream/services/watchlist"
 	user_settings "novastream/services/user_settings"
@@ -195,8 +199,9 @@ var SettingsSchema = map[string]interface{}{
 		"group": "server",
 		"order": 0,
 		"fields": map[string]interface{}{
-			"host": map[string]interface{}{"type": "text", "label": "Host", "description": "Server bind address"},
-			"port": map[string]interface{}{"type": "number", "label": "Port", "description": "Server port"},
+			"host":         map[string]interface{}{"type": "text", "label": "Host", "description": "Server bind address"},
+			"port":         map[string]interface{}{"type": "number", "label": "Port", "description": "Server port"},
+			"metricsToken": map[string]interface{}{"type": "password", "label": "Metrics Token", "description": "Bearer token Prometheus must send to scrape /metrics (/metrics is disabled until a token is set)"},
 		},
 	},
 	"network": map[string]interface{}{
@@ -228,6 +233,46 @@ var SettingsSchema = map[string]interface{}{
 			},
 		},
 	},
+	"webhooks": map[string]interface{}{
+		"label":    "Webhooks",
+		"icon":     "bell",
+		"group":    "server",
+		"order":    2,
+		"is_array": true,
+		"fields": map[string]interface{}{
+			"name":   map[string]interface{}{"type": "text", "label": "Name", "description": "Webhook name", "order": 0},
+			"url":    map[string]interface{}{"type": "text", "label": "URL", "description": "Endpoint to POST to (Discord webhook URL, ntfy topic URL, Gotify /message URL, or any JSON endpoint)", "order": 1},
+			"format": map[string]interface{}{"type": "select", "label": "Format", "options": []string{"json", "discord", "ntfy", "gotify"}, "description": "Payload format", "order": 2},
+			"token":  map[string]interface{}{"type": "password", "label": "Token", "description": "Sent as a bearer token (json, ntfy) or X-Gotify-Key (gotify). Leave empty if the URL already authenticates.", "order": 3},
+			"events": map[string]interface{}{
+				"type":        "checkboxes",
+				"label":       "Events",
+				"description": "Events to send (none selected = all events)",
+				"order":       4,
+				"options": []map[string]interface{}{
+					{"value": "playback.started", "label": "Playback started"},
+					{"value": "playback.stopped", "label": "Playback stopped"},
+					{"value": "import.failed", "label": "Import failed"},
+					{"value": "file.corrupted", "label": "File marked corrupted"},
+					{"value": "debrid.expiring", "label": "Debrid account expiring"},
+					{"value": "task.failed", "label": "Scheduled task failed"},
+					{"value": "profile.created", "label": "Invitation sign-up"},
+					{"value": "auth.login_failed", "label": "Login failed"},
+				},
+			},
+			"template": map[string]interface{}{"type": "text", "label": "Template", "description": "Optional Go template for the request body, e.g. {\"text\": {{json .Message}}}. Available: .Type .Title .Message .Fields .Time", "order": 5},
+			"enabled":  map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Send events to this webhook", "order": 6},
+		},
+	},
+	"streamHistory": map[string]interface{}{
+		"label": "Playback History",
+		"icon":  "clock",
+		"group": "server",
+		"order": 3,
+		"fields": map[string]interface{}{
+			"retentionDays": map[string]interface{}{"type": "number", "label": "Retention (days)", "description": "How long finished playback sessions are kept in the history on the status page", "min": 1},
+		},
+	},
 	"streaming": map[string]interface{}{
 		"label": "Streaming",
 		"icon":  "play-circle",
@@ -259,6 +304,18 @@ var SettingsSchema = map[string]interface{}{
 				"label":       "Indexer Timeout (seconds)",
 				"description": "Maximum time to wait for indexer/scraper searches (default: 5). Increase if using Aiostreams, which may need more time to respond.",
 			},
+			"cacheIndexCachedTTLHours": map[string]interface{}{
+				"type":        "number",
+				"label":       "Cached Index TTL (hours)",
+				"description": "How long a torrent known to be cached on a debrid provider is trusted before it is checked again (default: 24)",
+				"min":         1,
+			},
+			"cacheIndexUncachedTTLHours": map[string]interface{}{
+				"type":        "number",
+				"label":       "Uncached Index TTL (hours)",
+				"description": "How long a torrent known to be uncached on a debrid provider is skipped before it is checked again (default: 6)",
+				"min":         1,
+			},
 		},
 	},
 	"debridProviders": map[string]interface{}{
@@ -271,7 +328,7 @@ var SettingsSchema = map[string]interface{}{
 		"key":      "debridProviders",
 		"fields": map[string]interface{}{
 			"name":     map[string]interface{}{"type": "text", "label": "Name", "description": "Provider display name", "order": 1},
-			"provider": map[string]interface{}{"type": "select", "label": "Provider", "options": []string{"realdebrid", "torbox", "alldebrid"}, "description": "Provider type", "order": 2},
+			"provider": map[string]interface{}{"type": "select", "label": "Provider", "options": []string{"realdebrid", "torbox", "alldebrid", "premiumize"}, "description": "Provider type", "order": 2},
 			"apiKey":   map[string]interface{}{"type": "password", "label": "API Key", "description": "Provider API key", "order": 3},
 			"enabled":  map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this provider", "order": 4},
 			"config.autoClearQueue": map[string]interface{}{
@@ -297,7 +354,17 @@ var SettingsSchema = map[string]interface{}{
 			"username":    map[string]interface{}{"type": "text", "label": "Username", "description": "NNTP username"},
 			"password":    map[string]interface{}{"type": "password", "label": "Password", "description": "NNTP password"},
 			"connections": map[string]interface{}{"type": "number", "label": "Connections", "description": "Max connections"},
-			"enabled":     map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this provider"},
+			"tier": map[string]interface{}{
+				"type":  "select",
+				"label": "Tier",
+				"options": []map[string]string{
+					{"value": "primary", "label": "Primary"},
+					{"value": "backup", "label": "Backup"},
+				},
+				"description": "Backup providers are only used for articles a primary is missing (e.g. block accounts)",
+			},
+			"priority": map[string]interface{}{"type": "number", "label": "Priority", "description": "Order within the tier (lower is tried first)"},
+			"enabled":  map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this provider"},
 		},
 	},
 	"filtering": map[string]interface{}{
@@ -330,7 +397,10 @@ var SettingsSchema = map[string]interface{}{
 		"icon":   "list",
 		"group":  "sources",
 		"order":  1,
-		"fields": map[string]interface{}{},
+		"fields": map[string]interface{}{
+			"audioPreference":      map[string]interface{}{"type": "tags", "label": "Audio Preference", "description": "Audio formats for the Audio Format criterion, most preferred first: Atmos, TrueHD, DTS Lossless, DDP, DTS Lossy, DD, FLAC, AAC, OPUS, MP3. With Force AAC Audio Transcoding on, releases with AAC audio rank first as they play without a transcode.", "order": 0},
+			"videoCodecPreference": map[string]interface{}{"type": "tags", "label": "Video Codec Preference", "description": "Video codecs for the Video Codec criterion, most preferred first: hevc, avc, av1, mpeg2, xvid. Leave out codecs a device can't decode to rank them last.", "order": 1},
+		},
 	},
 	"ranking.criteria": map[string]interface{}{
 		"label":    "Ranking Criteria",
@@ -345,6 +415,52 @@ var SettingsSchema = map[string]interface{}{
 			"order":   map[string]interface{}{"type": "number", "label": "Order", "description": "Sort priority (lower = higher priority)", "order": 3},
 		},
 	},
+	"ranking.customFormats": map[string]interface{}{
+		"label":    "Custom Formats",
+		"icon":     "tag",
+		"is_array": true,
+		"parent":   "ranking",
+		"key":      "customFormats",
+		"fields": map[string]interface{}{
+			"name":         map[string]interface{}{"type": "text", "label": "Name", "description": "Format name", "order": 0},
+			"enabled":      map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Score releases matching this format", "order": 1},
+			"score":        map[string]interface{}{"type": "number", "label": "Score", "description": "Added to the score of matching releases (negative to demote). Releases are ranked by their total score under the Custom Formats criterion.", "order": 2},
+			"releaseTitle": map[string]interface{}{"type": "text", "label": "Release Title", "description": "Regex matched against the full release title (case-insensitive)", "placeholder": "\\b(IMAX|Criterion)\\b", "order": 3},
+			"releaseGroup": map[string]interface{}{"type": "text", "label": "Release Group", "description": "Regex matched against the release group", "placeholder": "^(FLUX|NTb)$", "order": 4},
+			"codec":        map[string]interface{}{"type": "text", "label": "Codec", "description": "Regex matched against the video codec: avc, hevc, av1, xvid, mpeg2", "placeholder": "^hevc$", "order": 5},
+			"source":       map[string]interface{}{"type": "text", "label": "Source", "description": "Regex matched against the source: WEB-DL, WEBRip, BluRay, BluRay REMUX, HDTV, ...", "placeholder": "^WEB", "order": 6},
+			"audioFormat":  map[string]interface{}{"type": "text", "label": "Audio Format", "description": "Regex matched against each audio format: TrueHD, Atmos, DTS Lossless, DTS Lossy, DDP, DD, AAC, ...", "placeholder": "TrueHD|Atmos", "order": 7},
+			"resolution":   map[string]interface{}{"type": "text", "label": "Resolution", "description": "Regex matched against the resolution: 2160p, 1080p, 720p, ...", "order": 8},
+			"hdr":          map[string]interface{}{"type": "text", "label": "HDR", "description": "Regex matched against each HDR format: DV, HDR10+, HDR", "order": 9},
+			"remux": map[string]interface{}{
+				"type":        "select",
+				"label":       "Remux",
+				"options":     []map[string]string{{"value": "", "label": "Any"}, {"value": "only", "label": "Remuxes only"}, {"value": "exclude", "label": "No remuxes"}},
+				"description": "Require or reject remuxes",
+				"order":       10,
+			},
+		},
+	},
+	"releaseBlocklist": map[string]interface{}{
+		"label": "Failed Releases",
+		"icon":  "shield",
+		"group": "sources",
+		"order": 2,
+		"fields": map[string]interface{}{
+			"mode": map[string]interface{}{
+				"type":  "select",
+				"label": "Search Behaviour",
+				"options": []map[string]string{
+					{"value": "demote", "label": "Rank below other results"},
+					{"value": "drop", "label": "Remove from results"},
+					{"value": "off", "label": "Keep ranking unchanged"},
+				},
+				"description": "What searches do with releases that failed playback of the same title (failed health check, resolution error, DV profile 5 or unplayable stream)",
+				"order":       0,
+			},
+			"expiryDays": map[string]interface{}{"type": "number", "label": "Expiry (days)", "description": "Days after its last failure a release is taken off the blocklist (default: 14)", "min": 1, "order": 1},
+		},
+	},
 	"live": map[string]interface{}{
 		"label": "Live TV",
 		"icon":  "tv",
@@ -356,12 +472,15 @@ var SettingsSchema = map[string]interface{}{
 			"xtreamHost":                   map[string]interface{}{"type": "text", "label": "Server URL", "description": "Xtream Codes server URL (e.g., http://example.com:8080)", "placeholder": "http://example.com:8080", "showWhen": map[string]interface{}{"field": "mode", "value": "xtream"}, "order": 2},
 			"xtreamUsername":               map[string]interface{}{"type": "text", "label": "Username", "description": "Xtream Codes username", "showWhen": map[string]interface{}{"field": "mode", "value": "xtream"}, "order": 3},
 			"xtreamPassword":               map[string]interface{}{"type": "password", "label": "Password", "description": "Xtream Codes password", "showWhen": map[string]interface{}{"field": "mode", "value": "xtream"}, "order": 4},
-			"playlistCacheTtlHours":        map[string]interface{}{"type": "number", "label": "Cache TTL (hours)", "description": "Playlist cache duration", "order": 5},
-			"probeSizeMb":                  map[string]interface{}{"type": "number", "label": "Probe Size (MB)", "description": "FFmpeg probesize for stream analysis (0 = default ~5MB). Higher values improve stability but increase initial buffering.", "order": 6},
-			"analyzeDurationSec":           map[string]interface{}{"type": "number", "label": "Analyze Duration (sec)", "description": "FFmpeg analyzeduration in seconds (0 = default ~5s). Higher values help with problematic streams.", "order": 7},
-			"lowLatency":                   map[string]interface{}{"type": "boolean", "label": "Low Latency Mode", "description": "Reduce buffering for lower latency (may cause instability with poor connections)", "order": 8},
-			"filtering.enabledCategories": map[string]interface{}{"type": "multiselect", "label": "Enabled Categories", "description": "Only show channels in these categories (empty = show all)", "optionsEndpoint": "/live/categories", "order": 9},
-			"filtering.maxChannels":       map[string]interface{}{"type": "number", "label": "Max Total Channels", "description": "Overall channel limit (0 = no limit)", "order": 10},
+			"epgUrl":                       map[string]interface{}{"type": "text", "label": "EPG URL", "description": "XMLTV program guide URL (leave empty to use the Xtream Codes guide)", "order": 5},
+			"playlistCacheTtlHours":        map[string]interface{}{"type": "number", "label": "Cache TTL (hours)", "description": "Playlist and program guide cache duration", "order": 6},
+			"probeSizeMb":                  map[string]interface{}{"type": "number", "label": "Probe Size (MB)", "description": "FFmpeg probesize for stream analysis (0 = default ~5MB). Higher values improve stability but increase initial buffering.", "order": 7},
+			"analyzeDurationSec":           map[string]interface{}{"type": "number", "label": "Analyze Duration (sec)", "description": "FFmpeg analyzeduration in seconds (0 = default ~5s). Higher values help with problematic streams.", "order": 8},
+			"lowLatency":                   map[string]interface{}{"type": "boolean", "label": "Low Latency Mode", "description": "Reduce buffering for lower latency (may cause instability with poor connections)", "order": 9},
+			"maxConnections":               map[string]interface{}{"type": "number", "label": "Max Connections", "description": "Concurrent streams allowed by the provider for recordings (0 = ask the Xtream Codes server, or no limit)", "order": 10},
+			"recordingsDirectory":          map[string]interface{}{"type": "text", "label": "Recordings Directory", "description": "Where Live TV recordings are saved (leave empty to use the cache directory)", "order": 11},
+			"filtering.enabledCategories": map[string]interface{}{"type": "multiselect", "label": "Enabled Categories", "description": "Only show channels in these categories (empty = show all)", "optionsEndpoint": "/live/categories", "order": 12},
+			"filtering.maxChannels":       map[string]interface{}{"type": "number", "label": "Max Total Channels", "description": "Overall channel limit (0 = no limit)", "order": 13},
 		},
 	},
 	"indexers": map[string]interface{}{
@@ -377,6 +496,8 @@ var SettingsSchema = map[string]interface{}{
 			"type":       map[string]interface{}{"type": "select", "label": "Type", "options": []string{"newznab"}, "description": "Indexer type", "order": 3},
 			"categories": map[string]interface{}{"type": "text", "label": "Categories", "description": "Comma-separated newznab category IDs to filter results (e.g., 2000,2010,2020 for movies, 5000,5010,5020 for TV). Leave empty to search all categories.", "placeholder": "2000,5000", "order": 4},
 			"enabled":    map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this indexer", "order": 5},
+			"apiLimit":   map[string]interface{}{"type": "number", "label": "Daily API Limit", "description": "Searches allowed per day (0 = no limit). Limits the indexer reports are honoured as well.", "order": 6},
+			"grabLimit":  map[string]interface{}{"type": "number", "label": "Daily Grab Limit", "description": "NZB downloads allowed per day (0 = no limit)", "order": 7},
 		},
 	},
 	"torrentScrapers": map[string]interface{}{
@@ -387,14 +508,16 @@ var SettingsSchema = map[string]interface{}{
 		"is_array": true,
 		"fields": map[string]interface{}{
 			"name":    map[string]interface{}{"type": "text", "label": "Name", "description": "Scraper name", "order": 0},
-			"type":    map[string]interface{}{"type": "select", "label": "Type", "options": []string{"torrentio", "jackett", "zilean", "aiostreams", "nyaa"}, "description": "Scraper type", "order": 1},
+			"type":    map[string]interface{}{"type": "select", "label": "Type", "options": []string{"torrentio", "jackett", "prowlarr", "zilean", "aiostreams", "stremio", "nyaa"}, "description": "Scraper type (stremio: any Stremio addon such as Comet, MediaFusion or Jackettio)", "order": 1},
 			"options": map[string]interface{}{"type": "text", "label": "Options", "description": "Torrentio URL options (e.g., sort=qualitysize|qualityfilter=480p,scr,cam)", "showWhen": map[string]interface{}{"field": "type", "value": "torrentio"}, "order": 2, "placeholder": "sort=qualitysize|qualityfilter=480p,scr,cam"},
-			"url":     map[string]interface{}{"type": "text", "label": "URL", "description": "API URL (for AIOStreams: full Stremio addon URL)", "showWhen": map[string]interface{}{"operator": "or", "conditions": []map[string]interface{}{{"field": "type", "value": "jackett"}, {"field": "type", "value": "zilean"}, {"field": "type", "value": "aiostreams"}}}, "order": 3},
-			"apiKey":  map[string]interface{}{"type": "password", "label": "API Key", "description": "Jackett API key", "showWhen": map[string]interface{}{"field": "type", "value": "jackett"}, "order": 4},
+			"url":     map[string]interface{}{"type": "text", "label": "URL", "description": "API URL (for AIOStreams and Stremio addons: full addon manifest URL)", "showWhen": map[string]interface{}{"operator": "or", "conditions": []map[string]interface{}{{"field": "type", "value": "jackett"}, {"field": "type", "value": "prowlarr"}, {"field": "type", "value": "zilean"}, {"field": "type", "value": "aiostreams"}, {"field": "type", "value": "stremio"}}}, "order": 3},
+			"apiKey":  map[string]interface{}{"type": "password", "label": "API Key", "description": "Jackett/Prowlarr API key", "showWhen": map[string]interface{}{"operator": "or", "conditions": []map[string]interface{}{{"field": "type", "value": "jackett"}, {"field": "type", "value": "prowlarr"}}}, "order": 4},
 			"config.passthroughFormat": map[string]interface{}{"type": "boolean", "label": "Passthrough Format", "description": "Show raw AIOStreams format in manual selection (emoji-formatted details)", "showWhen": map[string]interface{}{"field": "type", "value": "aiostreams"}, "order": 5},
 			"config.category": map[string]interface{}{"type": "select", "label": "Category", "options": []string{"1_0", "1_2", "1_3", "1_4"}, "description": "Nyaa category (1_0=All Anime, 1_2=English-translated, 1_3=Non-English, 1_4=Raw)", "showWhen": map[string]interface{}{"field": "type", "value": "nyaa"}, "order": 6},
 			"config.filter": map[string]interface{}{"type": "select", "label": "Filter", "options": []string{"0", "1", "2"}, "description": "Nyaa filter (0=All, 1=No remakes, 2=Trusted only)", "showWhen": map[string]interface{}{"field": "type", "value": "nyaa"}, "order": 7},
-			"enabled": map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this scraper", "order": 8},
+			"config.indexerIds": map[string]interface{}{"type": "text", "label": "Indexer IDs", "description": "Comma-separated Prowlarr indexer IDs to search. Leave empty to search all torrent indexers.", "placeholder": "1,4,7", "showWhen": map[string]interface{}{"field": "type", "value": "prowlarr"}, "order": 8},
+			"config.categories": map[string]interface{}{"type": "text", "label": "Categories", "description": "Comma-separated newznab category IDs. Leave empty to use 2000 for movies and 5000 for TV.", "placeholder": "2000,5000", "showWhen": map[string]interface{}{"field": "type", "value": "prowlarr"}, "order": 9},
+			"enabled": map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable this scraper", "order": 10},
 		},
 	},
 	"playback": map[string]interface{}{
@@ -540,11 +663,22 @@ var SettingsSchema = map[string]interface{}{
 			"rarMaxMemoryGB":    map[string]interface{}{"type": "number", "label": "RAR Max Memory (GB)", "description": "Maximum memory for RAR operations"},
 		},
 	},
+	"sabnzbd": map[string]interface{}{
+		"label": "SABnzbd API",
+		"icon":  "download-cloud",
+		"group": "storage",
+		"order": 3,
+		"fields": map[string]interface{}{
+			"apiKey":      map[string]interface{}{"type": "password", "label": "API Key", "description": "Enables the SABnzbd-compatible download API for Sonarr/Radarr (host: this server, URL base: /sabnzbd). Leave empty to disable.", "order": 0},
+			"completeDir": map[string]interface{}{"type": "text", "label": "Completed Folder", "description": "Where the WebDAV share is mounted on the Sonarr/Radarr host, e.g. /mnt/novastream. Completed downloads are reported below it.", "order": 1},
+			"categories":  map[string]interface{}{"type": "tags", "label": "Categories", "description": "Categories offered to Sonarr/Radarr. Each category is imported into a folder of the same name.", "order": 2},
+		},
+	},
 	"transmux": map[string]interface{}{
 		"label": "Transmux Settings",
 		"icon":  "film",
 		"group": "storage",
-		"order": 3,
+		"order": 4,
 		"fields": map[string]interface{}{
 			"enabled":          map[string]interface{}{"type": "boolean", "label": "Enabled", "description": "Enable video transmuxing for HLS streaming"},
 			"ffmpegPath":       map[string]interface{}{"type": "text", "label": "FFmpeg Path", "description": "Path to ffmpeg binary"},
@@ -559,8 +693,9 @@ var SettingsSchema = map[string]interface{}{
 		"order":    4,
 		"testable": true,
 		"fields": map[string]interface{}{
-			"openSubtitlesUsername": map[string]interface{}{"type": "text", "label": "OpenSubtitles Username", "description": "OpenSubtitles.org username (optional, enables more results)", "order": 0},
-			"openSubtitlesPassword": map[string]interface{}{"type": "password", "label": "OpenSubtitles Password", "description": "OpenSubtitles.org password", "order": 1},
+			"openSubtitlesApiKey":   map[string]interface{}{"type": "password", "label": "OpenSubtitles API Key", "description": "OpenSubtitles.com API consumer key (enables OpenSubtitles and exact release matching)", "order": 0},
+			"openSubtitlesUsername": map[string]interface{}{"type": "text", "label": "OpenSubtitles Username", "description": "OpenSubtitles.com username (optional, raises the daily download limit)", "order": 1},
+			"openSubtitlesPassword": map[string]interface{}{"type": "password", "label": "OpenSubtitles Password", "description": "OpenSubtitles.com password", "order": 2},
 		},
 	},
 	"mdblist": map[string]interface{}{
@@ -615,6 +750,8 @@ type AdminUIHandler struct {
 	metadataService       MetadataService
 	clientsService        clientsService
 	clientSettingsService clientSettingsService
+	poolManager           pool.Manager
+	blockedAttempts       *content_restrictions.Service
 }
 
 // MetadataService interface for metadata operations
@@ -664,6 +801,16 @@ func (h *AdminUIHandler) SetClientSettingsService(css clientSettingsService) {
 	h.clientSettingsService = css
 }
 
+// SetPoolManager sets the NNTP pool manager for per-provider usage stats
+func (h *AdminUIHandler) SetPoolManager(pm pool.Manager) {
+	h.poolManager = pm
+}
+
+// SetBlockedAttemptsService sets the log of titles refused to kids profiles
+func (h *AdminUIHandler) SetBlockedAttemptsService(bs *content_restrictions.Service) {
+	h.blockedAttempts = bs
+}
+
 // NewAdminUIHandler creates a new admin UI handler
 func NewAdminUIHandler(settingsPath string, hlsManager *HLSManager, usersService *users.Service, userSettingsService *user_settings.Service, configManager *config.Manager) *AdminUIHandler {
 	funcMap := template.FuncMap{
@@ -730,6 +877,26 @@ func NewAdminUIHandler(settingsPath string, hlsManager *HLSManager, usersService
 			}
 			return total
 		},
+		"providerUsage": func(stats []pool.ProviderStats, host, username string) *pool.ProviderStats {
+			for i := range stats {
+				if stats[i].Host == host && stats[i].Username == username {
+					return &stats[i]
+				}
+			}
+			return nil
+		},
+		"formatBytes": func(n int64) string {
+			const unit = 1024
+			if n < unit {
+				return fmt.Sprintf("%d B", n)
+			}
+			div, exp := int64(unit), 0
+			for v := n / unit; v >= unit && exp < 3; v /= unit {
+				div *= unit
+				exp++
+			}
+			return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+		},
 		"hasFiltering": func(f config.FilterSettings) bool {
 			return f.HDRDVPolicy != "" && f.HDRDVPolicy != config.HDRDVPolicyNoExclusion || f.MaxSizeMovieGB > 0 || len(f.FilterOutTerms) > 0
 		},
@@ -829,6 +996,8 @@ type AdminStatus struct {
 	Timestamp        time.Time `json:"timestamp"`
 	UsenetTotal      int       `json:"usenet_total"`
 	DebridStatus     string    `json:"debrid_status"`
+
+	UsenetProviders []pool.ProviderStats `json:"usenet_providers,omitempty"`
 }
 
 // SettingsPage serves the settings management page
@@ -1241,6 +1410,9 @@ func (h *AdminUIHandler) getStatus(settings config.Settings) AdminStatus {
 			status.UsenetTotal += p.Connections
 		}
 	}
+	if h.poolManager != nil {
+		status.UsenetProviders = h.poolManager.ProviderStats()
+	}
 
 	// Check debrid providers
 	enabledDebrid := 0
@@ -1339,6 +1511,18 @@ func (h *AdminUIHandler) GetDebridStatus(w http.ResponseWriter, r *http.Request)
 				} else {
 					status.Error = err.Error()
 				}
+			case "premiumize":
+				client := debrid.NewPremiumizeClient(p.APIKey)
+				if info, err := client.GetAccountInfo(ctx); err == nil {
+					status.Username = info.Username
+					status.PremiumActive = info.PremiumActive
+					if info.ExpiresAt != nil {
+						status.ExpiresAt = info.ExpiresAt.Format("2006-01-02")
+						status.DaysRemaining = info.DaysRemaining
+					}
+				} else {
+					status.Error = err.Error()
+				}
 			}
 		}
 
@@ -1800,6 +1984,7 @@ func (h *AdminUIHandler) LoginSubmit(w http.ResponseWriter, r *http.Request) {
 	// Authenticate using accounts service
 	account, err := h.accountsService.Authenticate(username, password)
 	if err != nil {
+		publishLoginFailure(r, username, "admin")
 		h.renderLoginError(w, "Invalid username or password")
 		return
 	}
@@ -1930,7 +2115,8 @@ type TestScraperRequest struct {
 	Type    string `json:"type"`
 	URL     string `json:"url"`
 	APIKey  string `json:"apiKey"`
-	Options string `json:"options"` // Torrentio URL options
+	Options string            `json:"options"` // Torrentio URL options
+	Config  map[string]string `json:"config,omitempty"`
 }
 
 // addBrowserHeaders adds browser-like headers to avoid being blocked
@@ -1956,10 +2142,14 @@ func (h *AdminUIHandler) TestScraper(w http.ResponseWriter, r *http.Request) {
 	switch strings.ToLower(req.Type) {
 	case "jackett":
 		h.testJackettScraper(w, req)
+	case "prowlarr":
+		h.testProwlarrScraper(w, req)
 	case "zilean":
 		h.testZileanScraper(w, req)
 	case "aiostreams":
 		h.testAIOStreamsScraper(w, req)
+	case "stremio":
+		h.testStremioScraper(w, req)
 	case "nyaa":
 		h.testNyaaScraper(w)
 	case "torrentio":
@@ -2114,6 +2304,45 @@ func (h *AdminUIHandler) testJackettScraper(w http.ResponseWriter, req TestScrap
 	})
 }
 
+// testProwlarrScraper tests Prowlarr by checking the API key and counting enabled torrent indexers
+func (h *AdminUIHandler) testProwlarrScraper(w http.ResponseWriter, req TestScraperRequest) {
+	if req.URL == "" || req.APIKey == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Prowlarr URL and API key are required",
+		})
+		return
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	scraper := debrid.NewProwlarrScraper(req.URL, req.APIKey, req.Name, req.Config["indexerIds"], req.Config["categories"], client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	count, err := scraper.TestConnection(ctx)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Prowlarr connection failed: %v", err),
+		})
+		return
+	}
+
+	if count == 0 {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Prowlarr is reachable but has no enabled torrent indexers",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Prowlarr is working (%d torrent indexers enabled)", count),
+	})
+}
+
 // testZileanScraper tests a Zilean instance by querying its DMM filtered API
 func (h *AdminUIHandler) testZileanScraper(w http.ResponseWriter, req TestScraperRequest) {
 	if req.URL == "" {
@@ -2269,6 +2498,48 @@ func (h *AdminUIHandler) testAIOStreamsScraper(w http.ResponseWriter, req TestSc
 	})
 }
 
+// testStremioScraper tests a Stremio addon by reading its manifest and querying a known title
+func (h *AdminUIHandler) testStremioScraper(w http.ResponseWriter, req TestScraperRequest) {
+	if req.URL == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Addon manifest URL is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	scraper := debrid.NewStremioAddonScraper(req.URL, req.Name, &http.Client{Timeout: 30 * time.Second})
+	manifest, err := scraper.Manifest(ctx)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Failed to read addon manifest: %v", err),
+		})
+		return
+	}
+
+	// Test a stream query (The Matrix - a known IMDB ID)
+	results, err := scraper.Search(ctx, debrid.SearchRequest{
+		IMDBID: "tt0133093",
+		Parsed: debrid.ParsedQuery{Title: "The Matrix", MediaType: debrid.MediaTypeMovie},
+	})
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Addon manifest OK (%s v%s), but stream test failed: %v", manifest.Name, manifest.Version, err),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("%s v%s is working (%d streams found)", manifest.Name, manifest.Version, len(results)),
+	})
+}
+
 // testNyaaScraper tests Nyaa by querying its RSS feed
 func (h *AdminUIHandler) testNyaaScraper(w http.ResponseWriter) {
 	client := &http.Client{Timeout: 15 * time.Second}
@@ -2447,17 +2718,18 @@ type TestDebridProviderRequest struct {
 
 // ProfileWithPinStatus represents a profile with its PIN status
 type ProfileWithPinStatus struct {
-	ID             string    `json:"id"`
-	AccountID      string    `json:"accountId,omitempty"`
-	Name           string    `json:"name"`
-	Color          string    `json:"color,omitempty"`
-	IconURL        string    `json:"iconUrl,omitempty"`
-	HasPin         bool      `json:"hasPin"`
-	HasIcon        bool      `json:"hasIcon"`
-	IsKidsProfile  bool      `json:"isKidsProfile"`
-	TraktAccountID string    `json:"traktAccountId,omitempty"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             string                     `json:"id"`
+	AccountID      string                     `json:"accountId,omitempty"`
+	Name           string                     `json:"name"`
+	Color          string                     `json:"color,omitempty"`
+	IconURL        string                     `json:"iconUrl,omitempty"`
+	HasPin         bool                       `json:"hasPin"`
+	HasIcon        bool                       `json:"hasIcon"`
+	IsKidsProfile  bool                       `json:"isKidsProfile"`
+	RatingLimits   *models.ContentRestriction `json:"ratingLimits,omitempty"`
+	TraktAccountID string                     `json:"traktAccountId,omitempty"`
+	CreatedAt      time.Time                  `json:"createdAt"`
+	UpdatedAt      time.Time                  `json:"updatedAt"`
 }
 
 // GetProfiles returns all profiles with their PIN status (for admin dashboard)
@@ -2482,6 +2754,7 @@ func (h *AdminUIHandler) GetProfiles(w http.ResponseWriter, r *http.Request) {
 			HasPin:         u.HasPin(),
 			HasIcon:        u.HasIcon(),
 			IsKidsProfile:  u.IsKidsProfile,
+			RatingLimits:   u.ContentRestriction(),
 			TraktAccountID: u.TraktAccountID,
 			CreatedAt:      u.CreatedAt,
 			UpdatedAt:      u.UpdatedAt,
@@ -2768,7 +3041,8 @@ func (h *AdminUIHandler) SetProfileColor(w http.ResponseWriter, r *http.Request)
 
 // SetKidsProfileRequest represents a request to set a profile's kids mode
 type SetKidsProfileRequest struct {
-	IsKidsProfile bool `json:"isKidsProfile"`
+	IsKidsProfile bool                       `json:"isKidsProfile"`
+	RatingLimits  *models.ContentRestriction `json:"ratingLimits,omitempty"` // Optional maximum certifications
 }
 
 // SetKidsProfile updates a profile's kids mode flag
@@ -2790,6 +3064,20 @@ func (h *AdminUIHandler) SetKidsProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if req.RatingLimits != nil {
+		if _, err := h.usersService.SetRatingLimits(profileID, req.RatingLimits); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, users.ErrUserNotFound):
+				status = http.StatusNotFound
+			case errors.Is(err, users.ErrInvalidRatingLimit):
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
 	user, err := h.usersService.SetKidsProfile(profileID, req.IsKidsProfile)
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -2809,11 +3097,43 @@ func (h *AdminUIHandler) SetKidsProfile(w http.ResponseWriter, r *http.Request)
 		HasPin:        user.HasPin(),
 		HasIcon:       user.HasIcon(),
 		IsKidsProfile: user.IsKidsProfile,
+		RatingLimits:  user.ContentRestriction(),
 		CreatedAt:     user.CreatedAt,
 		UpdatedAt:     user.UpdatedAt,
 	})
 }
 
+// GetBlockedAttempts returns titles refused to kids profiles, newest first.
+// Admin sees all attempts, regular accounts only their own profiles'.
+func (h *AdminUIHandler) GetBlockedAttempts(w http.ResponseWriter, r *http.Request) {
+	if h.blockedAttempts == nil {
+		http.Error(w, "Content restrictions not available", http.StatusInternalServerError)
+		return
+	}
+
+	isAdmin, accountID, _, _ := h.getPageRoleInfo(r)
+
+	var attempts []models.BlockedAttempt
+	if isAdmin {
+		attempts = h.blockedAttempts.ListAll()
+	} else {
+		attempts = h.blockedAttempts.ListForAccount(accountID)
+	}
+
+	if profileID := r.URL.Query().Get("profileId"); profileID != "" {
+		filtered := make([]models.BlockedAttempt, 0, len(attempts))
+		for _, a := range attempts {
+			if a.ProfileID == profileID {
+				filtered = append(filtered, a)
+			}
+		}
+		attempts = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempts)
+}
+
 // SetProfileIconRequest represents a request to set a profile's icon URL
 type SetProfileIconRequest struct {
 	IconURL string `json:"iconUrl"`
@@ -3430,6 +3750,13 @@ func (h *AdminUIHandler) RegisterWithInvitation(w http.ResponseWriter, r *http.R
 		fmt.Printf("Warning: failed to mark invitation as used: %v\n", err)
 	}
 
+	events.Publish(events.Event{
+		Type:    events.ProfileCreated,
+		Title:   "New account sign-up",
+		Message: fmt.Sprintf("%s created an account using an invitation.", account.Username),
+		Fields:  map[string]string{"username": account.Username, "ip": getClientIPAddress(r)},
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -3535,11 +3862,12 @@ func (h *AdminUIHandler) GetContinueWatching(w http.ResponseWriter, r *http.Requ
 
 // TestSubtitlesRequest represents a request to test OpenSubtitles credentials
 type TestSubtitlesRequest struct {
+	APIKey   string `json:"apiKey"`
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
-// TestSubtitles tests OpenSubtitles.org credentials by attempting to log in
+// TestSubtitles tests the OpenSubtitles.com API key and, when set, the account credentials
 func (h *AdminUIHandler) TestSubtitles(w http.ResponseWriter, r *http.Request) {
 	var req TestSubtitlesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -3549,166 +3877,47 @@ func (h *AdminUIHandler) TestSubtitles(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if req.Username == "" || req.Password == "" {
+	if strings.TrimSpace(req.APIKey) == "" {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Username and password are required",
+			"error":   "API key is required",
 		})
 		return
 	}
 
-	// Try XML-RPC first, fall back to subliminal library if that fails
-	success, xmlErr := testOpenSubtitlesXMLRPC(req.Username, req.Password)
-	if success {
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+
+	client := subtitles.NewOpenSubtitles(req.APIKey, req.Username, req.Password)
+	if req.Username == "" || req.Password == "" {
+		// Without an account only the API key can be checked, with a search
+		if _, err := client.Search(ctx, subtitles.Query{ImdbID: "tt0133093", Language: "en"}); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": true,
-			"message": "OpenSubtitles login successful",
+			"message": "OpenSubtitles API key is valid",
 		})
 		return
 	}
 
-	// XML-RPC failed, try using subliminal library as fallback
-	// This matches exactly what the actual subtitle search uses
-	success, subliminalErr := testOpenSubtitlesSubliminal(req.Username, req.Password)
-	if success {
+	if err := client.Login(ctx); err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": true,
-			"message": "OpenSubtitles login successful (via subliminal)",
+			"success": false,
+			"error":   err.Error(),
 		})
 		return
 	}
-
-	// Both methods failed - return the more specific error
-	errMsg := xmlErr
-	if subliminalErr != "" && subliminalErr != "unknown error" {
-		errMsg = subliminalErr
-	}
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": false,
-		"error":   errMsg,
+		"success": true,
+		"message": "OpenSubtitles login successful",
 	})
 }
 
-// testOpenSubtitlesXMLRPC tests credentials using direct XML-RPC call
-func testOpenSubtitlesXMLRPC(username, password string) (bool, string) {
-	client := &http.Client{Timeout: 15 * time.Second}
-
-	// Escape special XML characters in credentials
-	escapedUsername := template.HTMLEscapeString(username)
-	escapedPassword := template.HTMLEscapeString(password)
-
-	// Build XML-RPC request for LogIn
-	// Use VLSub user agent to match subliminal library (registered with OpenSubtitles)
-	xmlPayload := fmt.Sprintf(`<?xml version="1.0"?>
-<methodCall>
-  <methodName>LogIn</methodName>
-  <params>
-    <param><value><string>%s</string></value></param>
-    <param><value><string>%s</string></value></param>
-    <param><value><string>en</string></value></param>
-    <param><value><string>VLSub 0.11.1</string></value></param>
-  </params>
-</methodCall>`, escapedUsername, escapedPassword)
-
-	apiReq, err := http.NewRequest(http.MethodPost, "https://api.opensubtitles.org/xml-rpc", strings.NewReader(xmlPayload))
-	if err != nil {
-		return false, fmt.Sprintf("Failed to create request: %v", err)
-	}
-	apiReq.Header.Set("Content-Type", "text/xml")
-
-	resp, err := client.Do(apiReq)
-	if err != nil {
-		return false, fmt.Sprintf("Connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, fmt.Sprintf("Failed to read response: %v", err)
-	}
-
-	bodyStr := string(body)
-
-	// Check for successful login (status 200 OK in the XML response)
-	if strings.Contains(bodyStr, "<name>status</name>") && strings.Contains(bodyStr, "200 OK") {
-		return true, ""
-	}
-
-	// Check for common error statuses
-	if strings.Contains(bodyStr, "401") {
-		return false, "Invalid username or password"
-	}
-	if strings.Contains(bodyStr, "411") {
-		return false, "Invalid user agent (API blocked)"
-	}
-
-	return false, "Login failed - check credentials"
-}
-
-// testOpenSubtitlesSubliminal tests credentials using the subliminal Python library
-// This is the same library used for actual subtitle searches
-func testOpenSubtitlesSubliminal(username, password string) (bool, string) {
-	// Find Python path (Docker or local)
-	var pythonPath string
-	if _, err := os.Stat("/.venv/bin/python3"); err == nil {
-		pythonPath = "/.venv/bin/python3"
-	} else {
-		pythonPath = filepath.Join("..", ".venv", "bin", "python3")
-		if _, err := os.Stat(pythonPath); err != nil {
-			// Try from backend directory
-			pythonPath = filepath.Join(".venv", "bin", "python3")
-		}
-	}
-
-	// Python script to test OpenSubtitles login using subliminal
-	script := `
-import sys
-import json
-from subliminal.providers.opensubtitles import OpenSubtitlesProvider
-
-try:
-    params = json.loads(sys.argv[1])
-    provider = OpenSubtitlesProvider(username=params['username'], password=params['password'])
-    provider.initialize()  # This performs the login
-    provider.terminate()   # Logout
-    print(json.dumps({"success": True}))
-except Exception as e:
-    error_msg = str(e)
-    if "Unauthorized" in error_msg or "401" in error_msg:
-        error_msg = "Invalid username or password"
-    elif "UnknownUserAgent" in error_msg or "414" in error_msg:
-        error_msg = "Invalid user agent"
-    print(json.dumps({"success": False, "error": error_msg}))
-`
-
-	paramsJSON, _ := json.Marshal(map[string]string{
-		"username": username,
-		"password": password,
-	})
-
-	cmd := exec.Command(pythonPath, "-c", script, string(paramsJSON))
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return false, string(exitErr.Stderr)
-		}
-		return false, err.Error()
-	}
-
-	var result struct {
-		Success bool   `json:"success"`
-		Error   string `json:"error"`
-	}
-	if err := json.Unmarshal(output, &result); err != nil {
-		return false, "Failed to parse response"
-	}
-
-	if result.Success {
-		return true, ""
-	}
-	return false, result.Error
-}
-
 // TestDebridProvider tests a debrid provider by checking their API
 func (h *AdminUIHandler) TestDebridProvider(w http.ResponseWriter, r *http.Request) {
 	var req TestDebridProviderRequest
@@ -3939,6 +4148,29 @@ func (h *AdminUIHandler) TestDebridProvider(w http.ResponseWriter, r *http.Reque
 			"message": fmt.Sprintf("Connected as %s (%s)", adResult.Data.User.Username, accountType),
 		})
 
+	case "premiumize":
+		// Test Premiumize by getting account info
+		info, err := debrid.NewPremiumizeClient(req.APIKey).GetAccountInfo(r.Context())
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		accountType := "Free"
+		if info.PremiumActive {
+			accountType = "Premium"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("Connected as customer %s (%s)", info.Username, accountType),
+		})
+
 	default:
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
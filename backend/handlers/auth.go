@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"novastream/internal/events"
 	"novastream/models"
 	"novastream/services/accounts"
 	"novastream/services/sessions"
@@ -57,6 +59,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	account, err := h.accounts.Authenticate(req.Username, req.Password)
 	if err != nil {
+		publishLoginFailure(r, req.Username, "app")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(map[string]string{"error": "invalid username or password"})
@@ -89,6 +92,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// publishLoginFailure reports a failed sign-in. Repeats from the same address for the
+// same username are collapsed by the notifier.
+func publishLoginFailure(r *http.Request, username, source string) {
+	ip := getClientIPAddress(r)
+	events.Publish(events.Event{
+		Type:    events.LoginFailed,
+		Title:   "Failed login",
+		Message: fmt.Sprintf("Failed %s login for %q from %s.", source, username, ip),
+		Fields:  map[string]string{"username": username, "ip": ip, "source": source},
+		Key:     ip + "|" + strings.ToLower(username),
+	})
+}
+
 // Logout invalidates the current session.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	token := extractBearerToken(r)
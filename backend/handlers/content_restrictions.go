@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"novastream/internal/auth"
+	"novastream/models"
+	content_restrictions "novastream/services/content_restrictions"
+	metadatapkg "novastream/services/metadata"
+)
+
+// contentRatingChecker checks titles against kids profile rating limits.
+type contentRatingChecker interface {
+	CheckContentRestriction(ctx context.Context, title models.Title, restriction models.ContentRestriction) (bool, string)
+	FilterTrendingByRestriction(ctx context.Context, items []models.TrendingItem, restriction models.ContentRestriction) []models.TrendingItem
+	FilterSearchByRestriction(ctx context.Context, results []models.SearchResult, restriction models.ContentRestriction) []models.SearchResult
+}
+
+var _ contentRatingChecker = (*metadatapkg.Service)(nil)
+
+// profileLookup resolves profiles by ID.
+type profileLookup interface {
+	Get(id string) (models.User, bool)
+}
+
+// blockedAttemptRecorder logs titles refused to kids profiles.
+type blockedAttemptRecorder interface {
+	Record(attempt models.BlockedAttempt) error
+}
+
+var _ blockedAttemptRecorder = (*content_restrictions.Service)(nil)
+
+// ContentRestrictions enforces kids profile rating limits in handlers.
+type ContentRestrictions struct {
+	Ratings contentRatingChecker
+	Users   profileLookup
+	Blocked blockedAttemptRecorder
+}
+
+// NewContentRestrictions creates the kids profile enforcement shared by handlers.
+func NewContentRestrictions(ratings contentRatingChecker, users profileLookup, blocked blockedAttemptRecorder) *ContentRestrictions {
+	return &ContentRestrictions{Ratings: ratings, Users: users, Blocked: blocked}
+}
+
+// restrictionFor returns the profile and its rating limits, or nil limits for profiles that
+// aren't kids profiles. The profile must belong to the account authenticated on the request;
+// unknown profiles and profiles of other accounts get the default kids limits. Requests that
+// name no profile are not restricted.
+func (c *ContentRestrictions) restrictionFor(ctx context.Context, userID string) (models.User, *models.ContentRestriction) {
+	if c == nil || c.Users == nil || c.Ratings == nil {
+		return models.User{}, nil
+	}
+
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return models.User{}, nil
+	}
+
+	accountID, _ := ctx.Value(auth.ContextKeyAccountID).(string)
+	isMaster, _ := ctx.Value(auth.ContextKeyIsMaster).(bool)
+
+	user, ok := c.Users.Get(userID)
+	if ok && (accountID == "" || isMaster || user.AccountID == accountID) {
+		return user, user.ContentRestriction()
+	}
+	log.Printf("[content-restrictions] profile %q is not a profile of account %q; restricting", userID, accountID)
+	return models.User{ID: userID, AccountID: accountID}, defaultKidsRestriction()
+}
+
+// defaultKidsRestriction returns the rating limits of a kids profile without custom limits.
+func defaultKidsRestriction() *models.ContentRestriction {
+	return models.User{IsKidsProfile: true}.ContentRestriction()
+}
+
+// FilterTrending drops items above the profile's rating limits.
+func (c *ContentRestrictions) FilterTrending(ctx context.Context, userID string, items []models.TrendingItem) []models.TrendingItem {
+	_, restriction := c.restrictionFor(ctx, userID)
+	if restriction == nil {
+		return items
+	}
+	return c.Ratings.FilterTrendingByRestriction(ctx, items, *restriction)
+}
+
+// FilterSearch drops search results above the profile's rating limits.
+func (c *ContentRestrictions) FilterSearch(ctx context.Context, userID string, results []models.SearchResult) []models.SearchResult {
+	_, restriction := c.restrictionFor(ctx, userID)
+	if restriction == nil {
+		return results
+	}
+	return c.Ratings.FilterSearchByRestriction(ctx, results, *restriction)
+}
+
+// Allow reports whether the profile may watch a title. Refusals are recorded for the account
+// owner to review.
+func (c *ContentRestrictions) Allow(ctx context.Context, userID string, title models.Title, source string) bool {
+	user, restriction := c.restrictionFor(ctx, userID)
+	if restriction == nil {
+		return true
+	}
+
+	allowed, certification := c.Ratings.CheckContentRestriction(ctx, title, *restriction)
+	if allowed {
+		return true
+	}
+
+	if c.Blocked != nil {
+		mediaType := models.RatingMediaType(title.MediaType)
+		attempt := models.BlockedAttempt{
+			AccountID:     user.AccountID,
+			ProfileID:     user.ID,
+			ProfileName:   user.Name,
+			TitleID:       title.ID,
+			TitleName:     title.Name,
+			MediaType:     mediaType,
+			Certification: certification,
+			Limit:         restriction.Limit(mediaType),
+			Country:       restriction.Country,
+			Source:        source,
+		}
+		if err := c.Blocked.Record(attempt); err != nil {
+			log.Printf("[content-restrictions] failed to record blocked attempt: %v", err)
+		}
+	}
+	return false
+}
+
+// titleFromCandidate builds the title a playback candidate belongs to from the metadata
+// attributes attached during search.
+func titleFromCandidate(candidate models.NZBResult) models.Title {
+	attrs := candidate.Attributes
+	title := models.Title{
+		ID:        strings.TrimSpace(attrs["titleId"]),
+		Name:      strings.TrimSpace(attrs["titleName"]),
+		MediaType: strings.TrimSpace(attrs["targetMediaType"]),
+		IMDBID:    strings.TrimSpace(attrs["imdbid"]),
+	}
+	if title.Name == "" {
+		title.Name = strings.TrimSpace(attrs["targetTitle"])
+	}
+	if title.Name == "" {
+		title.Name = candidate.Title
+	}
+	title = withTitleIDs(title)
+
+	if title.MediaType == "" {
+		if attrs["targetSeason"] != "" || attrs["targetEpisode"] != "" || attrs["targetEpisodeCode"] != "" {
+			title.MediaType = "series"
+		} else {
+			title.MediaType = "movie"
+		}
+	}
+	return title
+}
+
+// withTitleIDs fills in the media type and TMDB, TVDB or IMDB ID encoded in a title ID.
+func withTitleIDs(title models.Title) models.Title {
+	// Title IDs look like "tmdb:movie:603", "tvdb:series:81189" or a bare IMDB ID
+	parts := strings.Split(title.ID, ":")
+	switch {
+	case len(parts) == 3:
+		if title.MediaType == "" {
+			title.MediaType = parts[1]
+		}
+		if id, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			switch parts[0] {
+			case "tmdb":
+				title.TMDBID = id
+			case "tvdb":
+				title.TVDBID = id
+			}
+		}
+	case strings.HasPrefix(title.ID, "tt") && title.IMDBID == "":
+		title.IMDBID = title.ID
+	}
+	return title
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/internal/auth"
+	"novastream/models"
+)
+
+// fakeRatingChecker rates titles by name using US certifications.
+type fakeRatingChecker struct {
+	ratings map[string]string
+}
+
+func (f *fakeRatingChecker) CheckContentRestriction(_ context.Context, title models.Title, restriction models.ContentRestriction) (bool, string) {
+	return restriction.Allows(title.MediaType, map[string]string{"US": f.ratings[title.Name]})
+}
+
+func (f *fakeRatingChecker) FilterTrendingByRestriction(ctx context.Context, items []models.TrendingItem, restriction models.ContentRestriction) []models.TrendingItem {
+	var result []models.TrendingItem
+	for _, item := range items {
+		if ok, _ := f.CheckContentRestriction(ctx, item.Title, restriction); ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (f *fakeRatingChecker) FilterSearchByRestriction(ctx context.Context, results []models.SearchResult, restriction models.ContentRestriction) []models.SearchResult {
+	var filtered []models.SearchResult
+	for _, result := range results {
+		if ok, _ := f.CheckContentRestriction(ctx, result.Title, restriction); ok {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+type fakeProfileLookup map[string]models.User
+
+func (f fakeProfileLookup) Get(id string) (models.User, bool) {
+	user, ok := f[id]
+	return user, ok
+}
+
+type fakeBlockedRecorder struct {
+	attempts []models.BlockedAttempt
+}
+
+func (f *fakeBlockedRecorder) Record(attempt models.BlockedAttempt) error {
+	f.attempts = append(f.attempts, attempt)
+	return nil
+}
+
+type fakePlaybackService struct {
+	resolved bool
+}
+
+func (f *fakePlaybackService) Resolve(_ context.Context, _ models.NZBResult) (*models.PlaybackResolution, error) {
+	f.resolved = true
+	return &models.PlaybackResolution{}, nil
+}
+
+func (f *fakePlaybackService) QueueStatus(_ context.Context, _ int64) (*models.PlaybackResolution, error) {
+	return nil, nil
+}
+
+func testContentRestrictions() (*ContentRestrictions, *fakeBlockedRecorder) {
+	ratings := &fakeRatingChecker{ratings: map[string]string{
+		"Bluey":        "TV-Y",
+		"The Wire":     "TV-MA",
+		"Toy Story":    "G",
+		"Alien":        "R",
+		"Lost Tapes":   "",
+		"Finding Nemo": "G",
+	}}
+	users := fakeProfileLookup{
+		"kid":   {ID: "kid", AccountID: "acct", Name: "Kid", IsKidsProfile: true},
+		"adult": {ID: "adult", AccountID: "acct", Name: "Adult"},
+	}
+	blocked := &fakeBlockedRecorder{}
+	return NewContentRestrictions(ratings, users, blocked), blocked
+}
+
+func TestMetadataHandler_DiscoverNewFiltersKidsProfile(t *testing.T) {
+	fake := &fakeMetadataService{
+		trendingResp: []models.TrendingItem{
+			{Rank: 1, Title: models.Title{Name: "Bluey", MediaType: "series"}},
+			{Rank: 2, Title: models.Title{Name: "The Wire", MediaType: "series"}},
+			{Rank: 3, Title: models.Title{Name: "Lost Tapes", MediaType: "series"}},
+		},
+	}
+	handler := NewMetadataHandler(fake, testConfigManager(t))
+	restrictions, _ := testContentRestrictions()
+	handler.SetContentRestrictions(restrictions)
+
+	for userID, expected := range map[string]int{"kid": 1, "adult": 3} {
+		req := httptest.NewRequest(http.MethodGet, "/api/discover/new?type=series&userId="+userID, nil)
+		rec := httptest.NewRecorder()
+
+		handler.DiscoverNew(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected %d, got %d", userID, http.StatusOK, rec.Code)
+		}
+		var payload DiscoverNewResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if len(payload.Items) != expected {
+			t.Fatalf("%s: expected %d items, got %+v", userID, expected, payload.Items)
+		}
+	}
+}
+
+func TestPlaybackHandler_ResolveRefusesAboveRatingLimit(t *testing.T) {
+	service := &fakePlaybackService{}
+	handler := NewPlaybackHandler(service)
+	restrictions, blocked := testContentRestrictions()
+	handler.SetContentRestrictions(restrictions)
+
+	body, _ := json.Marshal(map[string]any{
+		"userId": "kid",
+		"result": models.NZBResult{
+			Title:      "Alien.1979.1080p.BluRay",
+			Attributes: map[string]string{"titleId": "tmdb:movie:348", "titleName": "Alien"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/playback/resolve", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.Resolve(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if service.resolved {
+		t.Fatalf("expected resolve not to be called")
+	}
+	if len(blocked.attempts) != 1 {
+		t.Fatalf("expected one blocked attempt, got %d", len(blocked.attempts))
+	}
+	attempt := blocked.attempts[0]
+	if attempt.ProfileID != "kid" || attempt.AccountID != "acct" || attempt.Certification != "R" || attempt.Limit != "PG" || attempt.Source != "playback" {
+		t.Fatalf("unexpected blocked attempt: %+v", attempt)
+	}
+}
+
+func TestTitleFromCandidate(t *testing.T) {
+	title := titleFromCandidate(models.NZBResult{
+		Title: "Show.S01E02.720p",
+		Attributes: map[string]string{
+			"titleId":       "tvdb:series:81189",
+			"targetTitle":   "Breaking Bad",
+			"targetSeason":  "1",
+			"targetEpisode": "2",
+		},
+	})
+	if title.TVDBID != 81189 || title.MediaType != "series" || title.Name != "Breaking Bad" {
+		t.Fatalf("unexpected title: %+v", title)
+	}
+
+	title = titleFromCandidate(models.NZBResult{
+		Title:      "Heat.1995.2160p",
+		Attributes: map[string]string{"titleId": "tt0113277"},
+	})
+	if title.IMDBID != "tt0113277" || title.MediaType != "movie" || title.Name != "Heat.1995.2160p" {
+		t.Fatalf("unexpected title: %+v", title)
+	}
+}
+
+func TestContentRestrictions_UnverifiedProfilesAreRestricted(t *testing.T) {
+	restrictions, _ := testContentRestrictions()
+	restrictions.Users.(fakeProfileLookup)["other"] = models.User{ID: "other", AccountID: "other-acct", Name: "Other"}
+	alien := models.Title{Name: "Alien", MediaType: "movie"}
+
+	acct := context.WithValue(context.Background(), auth.ContextKeyAccountID, "acct")
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		userID  string
+		allowed bool
+	}{
+		{"adult profile of the account", acct, "adult", true},
+		{"kids profile of the account", acct, "kid", false},
+		{"unknown profile", acct, "ghost", false},
+		{"profile of another account", acct, "other", false},
+		{"no profile", acct, "", true},
+		{"master account", context.WithValue(acct, auth.ContextKeyIsMaster, true), "other", true},
+	}
+	for _, tc := range cases {
+		if got := restrictions.Allow(tc.ctx, tc.userID, alien, "playback"); got != tc.allowed {
+			t.Errorf("%s: Allow = %v, want %v", tc.name, got, tc.allowed)
+		}
+	}
+}
+
+func TestPrequeueHandler_RefusesAboveRatingLimit(t *testing.T) {
+	handler := NewPrequeueHandler(nil, nil, nil, nil, nil, false)
+	restrictions, blocked := testContentRestrictions()
+	handler.SetContentRestrictions(restrictions)
+
+	body, _ := json.Marshal(map[string]any{
+		"titleId":   "tmdb:movie:348",
+		"titleName": "Alien",
+		"mediaType": "movie",
+		"userId":    "kid",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/playback/prequeue", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.Prequeue(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if len(blocked.attempts) != 1 || blocked.attempts[0].Source != "prequeue" {
+		t.Fatalf("unexpected blocked attempts: %+v", blocked.attempts)
+	}
+}
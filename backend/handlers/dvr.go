@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"novastream/models"
+	"novastream/services/dvr"
+
+	"github.com/gorilla/mux"
+)
+
+type dvrService interface {
+	List(userID string) []models.Recording
+	Get(id string) (models.Recording, bool)
+	Schedule(ctx context.Context, rec models.Recording) (models.Recording, error)
+	Stop(id string) (models.Recording, error)
+	Delete(id string) error
+}
+
+var _ dvrService = (*dvr.Service)(nil)
+
+// programmeFinder resolves an EPG programme so recordings can be scheduled from the guide.
+type programmeFinder interface {
+	FindProgramme(ctx context.Context, tvgID string, at time.Time) (*EPGProgramme, error)
+}
+
+var _ programmeFinder = (*LiveHandler)(nil)
+
+type recordingProgressService interface {
+	GetPlaybackProgress(userID, mediaType, itemID string) (*models.PlaybackProgress, error)
+}
+
+// DVRHandler schedules Live TV recordings and serves the captured files.
+type DVRHandler struct {
+	Service    dvrService
+	Programmes programmeFinder
+	Progress   recordingProgressService
+}
+
+func NewDVRHandler(service dvrService, programmes programmeFinder, progress recordingProgressService) *DVRHandler {
+	return &DVRHandler{Service: service, Programmes: programmes, Progress: progress}
+}
+
+// scheduleRecordingRequest describes a recording either by explicit time range or by an EPG
+// programme (tvgId plus programmeStart), optionally padded on both sides. The channel is
+// looked up by ID in the configured Live TV source; its stream URL never comes from clients.
+type scheduleRecordingRequest struct {
+	ChannelID            string    `json:"channelId"`
+	TvgID                string    `json:"tvgId"`
+	Title                string    `json:"title"`
+	Description          string    `json:"description"`
+	StartAt              time.Time `json:"startAt"`
+	EndAt                time.Time `json:"endAt"`
+	ProgrammeStart       time.Time `json:"programmeStart"`
+	PaddingBeforeMinutes int       `json:"paddingBeforeMinutes"`
+	PaddingAfterMinutes  int       `json:"paddingAfterMinutes"`
+}
+
+// List returns the profile's recordings with their resume position.
+func (h *DVRHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+
+	recordings := h.Service.List(userID)
+	items := make([]models.RecordingItem, 0, len(recordings))
+	for _, rec := range recordings {
+		item := models.RecordingItem{Recording: rec}
+		if h.Progress != nil && rec.Status == models.RecordingStatusCompleted {
+			if progress, err := h.Progress.GetPlaybackProgress(userID, models.RecordingMediaType, rec.ID); err == nil {
+				item.Progress = progress
+			}
+		}
+		items = append(items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// Schedule creates a recording for the profile.
+func (h *DVRHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(mux.Vars(r)["userID"])
+
+	var body scheduleRecordingRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rec := models.Recording{
+		UserID:      userID,
+		ChannelID:   body.ChannelID,
+		TvgID:       body.TvgID,
+		Title:       body.Title,
+		Description: body.Description,
+		StartAt:     body.StartAt,
+		EndAt:       body.EndAt,
+	}
+
+	if !body.ProgrammeStart.IsZero() {
+		if h.Programmes == nil || strings.TrimSpace(body.TvgID) == "" {
+			writeJSONError(w, "tvgId and a program guide are required to record a programme", http.StatusBadRequest)
+			return
+		}
+		programme, err := h.Programmes.FindProgramme(r.Context(), body.TvgID, body.ProgrammeStart)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if rec.Title == "" {
+			rec.Title = programme.Title
+		}
+		if rec.Description == "" {
+			rec.Description = programme.Description
+		}
+		rec.StartAt = programme.Start.Add(-time.Duration(max(body.PaddingBeforeMinutes, 0)) * time.Minute)
+		rec.EndAt = programme.Stop.Add(time.Duration(max(body.PaddingAfterMinutes, 0)) * time.Minute)
+	}
+
+	scheduled, err := h.Service.Schedule(r.Context(), rec)
+	if err != nil {
+		writeJSONError(w, err.Error(), dvrErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(scheduled)
+}
+
+// Stop ends a running recording or cancels a scheduled one.
+func (h *DVRHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.ownedRecording(w, r)
+	if !ok {
+		return
+	}
+
+	stopped, err := h.Service.Stop(rec.ID)
+	if err != nil {
+		writeJSONError(w, err.Error(), dvrErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stopped)
+}
+
+// Delete removes a recording and its file.
+func (h *DVRHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.ownedRecording(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(rec.ID); err != nil {
+		writeJSONError(w, err.Error(), dvrErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stream serves a completed recording with range support so players can seek and resume.
+func (h *DVRHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.ownedRecording(w, r)
+	if !ok {
+		return
+	}
+	if rec.Status != models.RecordingStatusCompleted || rec.FilePath == "" {
+		writeJSONError(w, "recording is not available for playback", http.StatusConflict)
+		return
+	}
+
+	file, err := os.Open(rec.FilePath)
+	if err != nil {
+		writeJSONError(w, "recording file not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if strings.EqualFold(filepath.Ext(rec.FilePath), ".ts") {
+		w.Header().Set("Content-Type", "video/mp2t")
+	} else {
+		w.Header().Set("Content-Type", "video/mp4")
+	}
+	http.ServeContent(w, r, filepath.Base(rec.FilePath), info.ModTime(), file)
+}
+
+// ownedRecording loads the recording named in the path and checks it belongs to the profile.
+func (h *DVRHandler) ownedRecording(w http.ResponseWriter, r *http.Request) (models.Recording, bool) {
+	vars := mux.Vars(r)
+	rec, ok := h.Service.Get(strings.TrimSpace(vars["recordingID"]))
+	if !ok || rec.UserID != strings.TrimSpace(vars["userID"]) {
+		writeJSONError(w, dvr.ErrRecordingNotFound.Error(), http.StatusNotFound)
+		return models.Recording{}, false
+	}
+	return rec, true
+}
+
+func dvrErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dvr.ErrInvalidRecording), errors.Is(err, dvr.ErrUnknownChannel):
+		return http.StatusBadRequest
+	case errors.Is(err, dvr.ErrRecordingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dvr.ErrRecordingConflict), errors.Is(err, dvr.ErrConnectionLimit):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -25,8 +25,13 @@ import (
 	"syscall"
 	"time"
 
+	"novastream/models"
+	release_blocklist "novastream/services/release_blocklist"
 	"novastream/services/streaming"
 	"novastream/utils"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // debugReader wraps an io.Reader to log bytes read and detect EOF
@@ -306,7 +311,15 @@ type HLSSession struct {
 	// Profile tracking
 	ProfileID   string
 	ProfileName string
+	ClientID    string
+	UserAgent   string
 	ClientIP    string
+	playbackKey string // Released in CleanupSession for playback notifications
+
+	// Stream history
+	Delivery     string   // remux or transcode, as decided by the last FFmpeg run
+	AudioPlan    string   // How audio was handled, e.g. "copy" or "truehd -> aac"
+	ffmpegErrors []string // Fatal FFmpeg errors seen during the session
 
 	// Track selection (-1 means use default)
 	AudioTrackIndex    int // Selected audio stream index (ffprobe index), -1 = all/default
@@ -337,6 +350,15 @@ type HLSSession struct {
 	RecoveryAttempts   int  // Number of times we've attempted to recover this session
 	forceAAC           bool // Cached forceAAC setting for recovery restarts
 	SeekInProgress     bool // Set to true during user-initiated seek to prevent recovery logic
+	runStartSegment    int  // First segment number written by the current FFmpeg run
+
+	// Source failover (switch to the next ranked release when the source keeps failing)
+	source          models.NZBResult   // Release being streamed, when it came from a search
+	alternatives    []models.NZBResult // Remaining ranked candidates, best first
+	SourceService   string             // Service type of the current source (debrid, usenet, direct)
+	SourceTitle     string             // Release title of the current source
+	SourceChanges   int                // Number of times the session switched to another release
+	SourceChangedAt time.Time
 
 	// Fatal error tracking (unplayable streams)
 	FatalError       string // Set when stream is determined to be unplayable (persistent bitstream errors)
@@ -384,6 +406,24 @@ const (
 	hlsBufferResumeThreshold = 20 // ~80 seconds of buffer ahead
 )
 
+var (
+	hlsFFmpegRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_hls_ffmpeg_restarts_total",
+		Help: "FFmpeg restarts with a degraded pipeline after a bitstream error.",
+	}, []string{"reason"})
+	hlsRecoveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_hls_recoveries_total",
+		Help: "FFmpeg restarts that resume a session after the source stream failed.",
+	}, []string{"reason"})
+	hlsFatalErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_hls_fatal_errors_total",
+		Help: "Sessions that ended because FFmpeg could not continue.",
+	}, []string{"reason"})
+	hlsSegmentBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_hls_segment_bytes_served_total",
+		Help: "Bytes of HLS segments served to clients.",
+	}, []string{"type"})
+)
 
 // HLSManager manages HLS transcoding sessions
 type HLSManager struct {
@@ -400,6 +440,12 @@ type HLSManager struct {
 	// Global probe cache - shared between prequeue (ProbeVideoFull) and HLS (probeAllMetadata)
 	probeCache   map[string]*cachedProbeEntry
 	probeCacheMu sync.RWMutex
+	// Ranked fallback releases per resolved stream path, for source failover
+	alternatives   map[string]*sourceAlternatives
+	alternativesMu sync.RWMutex
+	sourceResolver SourceResolver
+	// Blocklists releases whose stream FFmpeg can't process
+	releaseFailures ReleaseFailureRecorder
 }
 
 // NewHLSManager creates a new HLS session manager
@@ -415,13 +461,14 @@ func NewHLSManager(baseDir, ffmpegPath, ffprobePath string, streamer streaming.P
 	}
 
 	manager := &HLSManager{
-		sessions:    make(map[string]*HLSSession),
-		baseDir:     baseDir,
-		ffmpegPath:  ffmpegPath,
-		ffprobePath: ffprobePath,
-		streamer:    streamer,
-		cleanupDone: make(chan struct{}),
-		probeCache:  make(map[string]*cachedProbeEntry),
+		sessions:     make(map[string]*HLSSession),
+		baseDir:      baseDir,
+		ffmpegPath:   ffmpegPath,
+		ffprobePath:  ffprobePath,
+		streamer:     streamer,
+		cleanupDone:  make(chan struct{}),
+		probeCache:   make(map[string]*cachedProbeEntry),
+		alternatives: make(map[string]*sourceAlternatives),
 	}
 
 	// Clean up any orphaned directories from previous runs
@@ -669,7 +716,7 @@ func (m *HLSManager) buildLocalWebDAVURLFromPath(path string) (string, bool) {
 }
 
 // CreateSession starts a new HLS transcoding session
-func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPath string, hasDV bool, dvProfile string, hasHDR bool, forceAAC bool, startOffset float64, transcodingOffset float64, audioTrackIndex int, subtitleTrackIndex int, profileID string, profileName string, clientIP string) (*HLSSession, error) {
+func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPath string, hasDV bool, dvProfile string, hasHDR bool, forceAAC bool, startOffset float64, transcodingOffset float64, audioTrackIndex int, subtitleTrackIndex int, profileID string, profileName string, clientIP string, clientID string, userAgent string) (*HLSSession, error) {
 	sessionID := generateSessionID()
 	outputDir := filepath.Join(m.baseDir, sessionID)
 
@@ -771,6 +818,22 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 		ProbeData:               probeData, // Cache unified probe results for startTranscoding
 	}
 
+	playbackPath := originalPath
+	if playbackPath == "" {
+		playbackPath = path
+	}
+	session.playbackKey = globalPlaybackNotifier.begin(playbackInfo{
+		Path:        playbackPath,
+		ProfileID:   profileID,
+		ProfileName: profileName,
+		ClientID:    clientID,
+		UserAgent:   userAgent,
+		ClientIP:    clientIP,
+		Kind:        "hls",
+	})
+
+	m.attachAlternatives(session)
+
 	m.mu.Lock()
 	m.sessions[sessionID] = session
 	m.mu.Unlock()
@@ -779,6 +842,7 @@ func (m *HLSManager) CreateSession(ctx context.Context, path string, originalPat
 	go func() {
 		if err := m.startTranscoding(bgCtx, session, forceAAC); err != nil {
 			log.Printf("[hls] session %s transcoding failed: %v", sessionID, err)
+			session.noteFFmpegError(err.Error())
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
@@ -831,8 +895,12 @@ func (m *HLSManager) CreateLiveSession(ctx context.Context, liveURL string) (*HL
 		EarliestBufferedSegment: -1,
 		AudioTrackIndex:         -1, // Use default
 		SubtitleTrackIndex:      -1, // No subtitles for live TV
+		Delivery:                "remux",
+		AudioPlan:               "aac",
 	}
 
+	session.playbackKey = globalPlaybackNotifier.begin(playbackInfo{Path: liveURL, Kind: "live"})
+
 	m.mu.Lock()
 	m.sessions[sessionID] = session
 	m.mu.Unlock()
@@ -841,6 +909,7 @@ func (m *HLSManager) CreateLiveSession(ctx context.Context, liveURL string) (*HL
 	go func() {
 		if err := m.startLiveTranscoding(bgCtx, session); err != nil {
 			log.Printf("[hls] live session %s transcoding failed: %v", sessionID, err)
+			session.noteFFmpegError(err.Error())
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
@@ -1460,7 +1529,9 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		needsVideoTranscode = IsIncompatibleVideoCodec(videoCodec)
 	}
 
+	delivery := "remux"
 	if needsVideoTranscode {
+		delivery = "transcode"
 		// Transcode incompatible video codec to H.264
 		// Use veryfast preset for real-time transcoding, CRF 23 for reasonable quality
 		log.Printf("[hls] session %s: incompatible video codec %q detected, transcoding to H.264", session.ID, videoCodec)
@@ -1533,6 +1604,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 
 	// Audio handling
 	audioCodecHandled := false
+	audioPlan := "copy"
 
 	// Check if a specific incompatible audio track was selected (TrueHD, DTS, etc.)
 	if mappedSpecificAudio && session.AudioTrackIndex >= 0 {
@@ -1546,6 +1618,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 					// async=1000 allows up to 1000 samples of drift correction per second
 					// Note: -start_at_zero (set earlier) normalizes all stream timestamps for proper A/V sync
 					log.Printf("[hls] session %s: transcoding selected %s track to AAC", session.ID, audioStreams[i].Codec)
+					audioPlan = strings.ToLower(audioStreams[i].Codec) + " -> aac"
 					args = append(args,
 						"-af", "aresample=async=1000",
 						"-c:a", "aac", "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
@@ -1561,6 +1634,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// Transcode first audio to AAC, copy others
 			// Must specify channel_layout for iOS AVPlayer compatibility
 			// Use aresample filter with async for proper A/V sync during transcoding
+			audioPlan = "first track -> aac"
 			args = append(args,
 				"-af", "aresample=async=1000",
 				"-c:a:0", "aac", "-ac:a:0", "6", "-ar:a:0", "48000", "-channel_layout:a:0", "5.1", "-b:a:0", "192k",
@@ -1570,6 +1644,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// Must specify channel_layout for iOS AVPlayer compatibility (otherwise shows "media may be damaged")
 			// TrueHD has variable timing - use aresample filter with async to maintain A/V sync
 			log.Printf("[hls] session %s: transcoding TrueHD to AAC (no compatible alternative)", session.ID)
+			audioPlan = "truehd -> aac"
 			args = append(args,
 				"-af", "aresample=async=1000",
 				"-c:a", "aac", "-ac", "6", "-ar", "48000", "-channel_layout", "5.1", "-b:a", "192k")
@@ -1579,6 +1654,11 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		}
 	}
 
+	session.mu.Lock()
+	session.Delivery = delivery
+	session.AudioPlan = audioPlan
+	session.mu.Unlock()
+
 	// Subtitle handling: All subtitles are served via sidecar VTT files for consistent overlay rendering.
 	// - fMP4 (Dolby Vision/HDR): Extract ALL text-based tracks upfront as additional ffmpeg outputs
 	// - MPEG-TS: On-demand extraction via extractSubtitleTrack when a track is requested
@@ -1629,17 +1709,22 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	// Determine segment start number - normally 0, but for recovery we continue from where we left off
 	segmentStartNum := "0"
 	session.mu.RLock()
-	isRecovery := session.RecoveryAttempts > 0
+	isRecovery := session.RecoveryAttempts > 0 || session.SourceChanges > 0
 	session.mu.RUnlock()
 
+	runStartSegment := 0
 	if isRecovery {
 		// Find highest existing segment and start from the next one
 		highestSegment := m.findHighestSegmentNumber(session)
 		if highestSegment >= 0 {
-			segmentStartNum = strconv.Itoa(highestSegment + 1)
+			runStartSegment = highestSegment + 1
+			segmentStartNum = strconv.Itoa(runStartSegment)
 			log.Printf("[hls] session %s: recovery mode - starting from segment %s", session.ID, segmentStartNum)
 		}
 	}
+	session.mu.Lock()
+	session.runStartSegment = runStartSegment
+	session.mu.Unlock()
 
 	// Increase muxing queue size to prevent A/V desync under load
 	// Default is 8 packets which can cause sync issues with variable bitrate streams
@@ -1850,8 +1935,10 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 						session.FatalError = "Stream contains malformed video data that cannot be processed"
 						session.FatalErrorTime = time.Now()
 						session.mu.Unlock()
+						session.noteFFmpegError(session.FatalError)
 						log.Printf("[hls] session %s: FATAL_ERROR - bitstream filter errors indicate corrupted stream data (count: %d)",
 							session.ID, bitstreamCount)
+						m.recordSourceFailure(session, release_blocklist.SourceTranscode, session.FatalError)
 
 						// Kill FFmpeg - no point continuing with a broken stream
 						if cmd.Process != nil {
@@ -1896,6 +1983,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 							select {
 							case inputErrorCh <- struct{}{}:
 								log.Printf("[hls] session %s: detected input stream error, will attempt recovery (attempt %d/%d)", session.ID, recoveryAttempts+1, hlsMaxRecoveryAttempts)
+								session.noteFFmpegError(ffmpegErrorLine(msg))
 							default:
 								// Already signaled
 							}
@@ -1926,6 +2014,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			session.mu.Lock()
 			session.DVDisabled = true
 			session.mu.Unlock()
+			session.noteFFmpegError("Dolby Vision metadata could not be parsed, restarted without Dolby Vision")
 			log.Printf("[hls] session %s: killing FFmpeg due to DV metadata errors", session.ID)
 			if cmd.Process != nil {
 				_ = cmd.Process.Kill()
@@ -1943,6 +2032,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			session.mu.Lock()
 			session.HDRMetadataDisabled = true
 			session.mu.Unlock()
+			session.noteFFmpegError("hevc_metadata failed on malformed SEI data, restarted without HDR signaling")
 			log.Printf("[hls] session %s: killing FFmpeg due to hevc_metadata errors (malformed SEI)", session.ID)
 			if cmd.Process != nil {
 				_ = cmd.Process.Kill()
@@ -2221,6 +2311,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		session.mu.Lock()
 		session.Completed = true
 		session.mu.Unlock()
+		hlsFatalErrors.WithLabelValues("bitstream").Inc()
 		return fmt.Errorf("fatal stream error: %s", fatalError)
 	}
 
@@ -2252,6 +2343,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		session.mu.Unlock()
 
 		// Restart transcoding - DVDisabled is already set to true
+		hlsFFmpegRestarts.WithLabelValues("dolby_vision").Inc()
 		log.Printf("[hls] session %s: restarting transcoding with DV disabled (will use HDR10 base layer)", session.ID)
 		return m.startTranscoding(ctx, session, forceAAC)
 	}
@@ -2283,6 +2375,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		session.mu.Unlock()
 
 		// Restart transcoding - HDRMetadataDisabled is already set to true
+		hlsFFmpegRestarts.WithLabelValues("hevc_metadata").Inc()
 		log.Printf("[hls] session %s: restarting transcoding without hevc_metadata filter (stream will still play, but may lack proper HDR color signaling)", session.ID)
 		return m.startTranscoding(ctx, session, forceAAC)
 	}
@@ -2294,17 +2387,23 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	cachedForceAAC := session.forceAAC
 	session.mu.RUnlock()
 
+	// After repeated input errors on the same source, switch to the next ranked release
+	// (debrid or usenet) at the current position instead of retrying the broken one
+	if inputErrorDetected && inputWasErrored && recoveryAttempts >= hlsFailoverAfterAttempts && m.hasAlternatives(session) {
+		if switched, err := m.failoverToAlternative(session); switched {
+			return err
+		}
+		log.Printf("[hls] session %s: no alternative source could be used, retrying the current source", session.ID)
+	}
+
 	if inputErrorDetected && inputWasErrored && recoveryAttempts < hlsMaxRecoveryAttempts {
 		// Find the highest segment number to calculate where to resume
 		highestSegment := m.findHighestSegmentNumber(session)
-		if highestSegment < 0 {
-			highestSegment = 0
-		}
 
 		// Calculate new transcoding offset based on segments already created
 		// Each segment is hlsSegmentDuration seconds
 		// Use TranscodingOffset as base (not StartOffset) - StartOffset is the original user position
-		newTranscodingOffset := session.TranscodingOffset + float64(highestSegment+1)*hlsSegmentDuration
+		newTranscodingOffset := m.resumeOffset(session, highestSegment)
 
 		// Don't exceed the total duration
 		if session.Duration > 0 && newTranscodingOffset >= session.Duration {
@@ -2352,6 +2451,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		// Subtitles will be re-extracted from TranscodingOffset (same as seek behavior)
 		log.Printf("[hls] session %s: restarting transcoding from %.2fs after input error (recovery attempt %d/%d)",
 			session.ID, newTranscodingOffset, recoveryAttempts+1, hlsMaxRecoveryAttempts)
+		hlsRecoveries.WithLabelValues("input_error").Inc()
 		return m.startTranscoding(newCtx, session, cachedForceAAC)
 	}
 
@@ -2412,7 +2512,7 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 		if recoveryAttempts < hlsMaxRecoveryAttempts {
 			// Calculate new transcoding offset based on segments already created
 			// Use TranscodingOffset (not StartOffset) as base - StartOffset is the original user position
-			newTranscodingOffset := session.TranscodingOffset + float64(highestSegment+1)*hlsSegmentDuration
+			newTranscodingOffset := m.resumeOffset(session, highestSegment)
 
 			// Don't exceed the total duration
 			if session.Duration > 0 && newTranscodingOffset >= session.Duration {
@@ -2451,10 +2551,12 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 			// Subtitles will be re-extracted from TranscodingOffset (same as seek behavior)
 			log.Printf("[hls] session %s: restarting transcoding from %.2fs after premature completion (recovery attempt %d/%d)",
 				session.ID, newTranscodingOffset, recoveryAttempts+1, hlsMaxRecoveryAttempts)
+			hlsRecoveries.WithLabelValues("premature_completion").Inc()
 			return m.startTranscoding(newCtx, session, cachedForceAAC)
 		}
 		log.Printf("[hls] session %s: premature completion recovery exhausted (%d/%d attempts)",
 			session.ID, recoveryAttempts, hlsMaxRecoveryAttempts)
+		hlsFatalErrors.WithLabelValues("recovery_exhausted").Inc()
 	} else if completionPercent < 95 && expectedSegments > 0 {
 		// Segment count mismatch but FFmpeg exited cleanly - likely incorrect metadata duration
 		log.Printf("[hls] session %s: transcoding completed in %v with segment mismatch (%.1f%% - expected %d segments, got %d) - FFmpeg exited cleanly so metadata duration was likely incorrect",
@@ -2466,6 +2568,19 @@ func (m *HLSManager) startTranscoding(ctx context.Context, session *HLSSession,
 	return nil
 }
 
+// resumeOffset returns the media time right after the given segment. Segments of the
+// current FFmpeg run start at runStartSegment and TranscodingOffset, so segments written by
+// earlier runs of a recovered session are not counted twice.
+func (m *HLSManager) resumeOffset(session *HLSSession, highestSegment int) float64 {
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+	produced := highestSegment + 1 - session.runStartSegment
+	if produced < 0 {
+		produced = 0
+	}
+	return session.TranscodingOffset + float64(produced)*hlsSegmentDuration
+}
+
 // findHighestSegmentNumber scans the output directory for segment files and returns the highest segment number found
 // Returns -1 if no segments are found
 func (m *HLSManager) findHighestSegmentNumber(session *HLSSession) int {
@@ -2529,6 +2644,23 @@ func (m *HLSManager) logProcessCPU(sessionID string, pid int) {
 		sessionID, pid, utime, stime, cpuSeconds)
 }
 
+// SessionCounts returns the number of active sessions split into live and on-demand.
+func (m *HLSManager) SessionCounts() (live, vod int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		session.mu.RLock()
+		if session.IsLive {
+			live++
+		} else {
+			vod++
+		}
+		session.mu.RUnlock()
+	}
+	return live, vod
+}
+
 // GetSession retrieves a session by ID and updates last access time
 func (m *HLSManager) GetSession(sessionID string) (*HLSSession, bool) {
 	m.mu.RLock()
@@ -2736,6 +2868,7 @@ func (m *HLSManager) Seek(w http.ResponseWriter, r *http.Request, sessionID stri
 	go func() {
 		if err := m.startTranscoding(newCtx, session, cachedForceAAC); err != nil {
 			log.Printf("[hls] session %s: seek transcoding failed: %v", sessionID, err)
+			session.noteFFmpegError(err.Error())
 			session.mu.Lock()
 			session.Completed = true
 			session.mu.Unlock()
@@ -2839,6 +2972,11 @@ type HLSSessionStatus struct {
 	HDRMetadataDisabled bool    `json:"hdrMetadataDisabled"`
 	DVDisabled          bool    `json:"dvDisabled"`
 	RecoveryAttempts    int     `json:"recoveryAttempts"`
+	// Source failover: the release being streamed and how often it was switched
+	Source          string `json:"source,omitempty"` // debrid, usenet or direct
+	SourceTitle     string `json:"sourceTitle,omitempty"`
+	SourceChanges   int    `json:"sourceChanges"`
+	SourceChangedAt int64  `json:"sourceChangedAt,omitempty"` // Unix timestamp
 }
 
 // GetSessionStatus returns the current status of an HLS session
@@ -2860,6 +2998,12 @@ func (m *HLSManager) GetSessionStatus(w http.ResponseWriter, r *http.Request, se
 		HDRMetadataDisabled: session.HDRMetadataDisabled,
 		DVDisabled:          session.DVDisabled,
 		RecoveryAttempts:    session.RecoveryAttempts,
+		Source:              session.SourceService,
+		SourceTitle:         session.SourceTitle,
+		SourceChanges:       session.SourceChanges,
+	}
+	if !session.SourceChangedAt.IsZero() {
+		status.SourceChangedAt = session.SourceChangedAt.Unix()
 	}
 
 	if session.FatalError != "" {
@@ -3156,7 +3300,13 @@ func (m *HLSManager) ServeSegment(w http.ResponseWriter, r *http.Request, sessio
 	// Track bytes served
 	session.mu.Lock()
 	session.BytesStreamed += segmentSize
+	isLive := session.IsLive
 	session.mu.Unlock()
+	if isLive {
+		hlsSegmentBytes.WithLabelValues("live").Add(float64(segmentSize))
+	} else {
+		hlsSegmentBytes.WithLabelValues("vod").Add(float64(segmentSize))
+	}
 
 	serveStart := time.Now()
 	http.ServeFile(w, r, segmentPath)
@@ -3505,6 +3655,43 @@ func alignMatroskaCluster(r io.Reader, maxScanBytes int64) (io.Reader, int64, er
 		fmt.Errorf("matroska cluster sync not found within %d bytes", maxScanBytes)
 }
 
+// noteFFmpegError records a fatal FFmpeg error for the stream history.
+func (s *HLSSession) noteFFmpegError(msg string) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
+	}
+	if len(msg) > 300 {
+		msg = msg[:300]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ffmpegErrors) >= maxPlaybackErrors {
+		return
+	}
+	for _, existing := range s.ffmpegErrors {
+		if existing == msg {
+			return
+		}
+	}
+	s.ffmpegErrors = append(s.ffmpegErrors, msg)
+}
+
+// ffmpegErrorLine picks the line describing the error out of an FFmpeg stderr chunk.
+func ffmpegErrorLine(msg string) string {
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "error") || strings.Contains(lower, "invalid") ||
+			strings.Contains(lower, "end of file") || strings.Contains(lower, "failed") ||
+			strings.Contains(lower, "connection") || strings.Contains(lower, "server returned") {
+			return strings.TrimSpace(line)
+		}
+	}
+	return strings.TrimSpace(lines[0])
+}
+
 // CleanupSession removes a session and its files
 func (m *HLSManager) CleanupSession(sessionID string) {
 	m.mu.Lock()
@@ -3518,6 +3705,12 @@ func (m *HLSManager) CleanupSession(sessionID string) {
 
 	// Log session summary
 	session.mu.RLock()
+	usage := playbackUsage{
+		Bytes:     session.BytesStreamed,
+		Delivery:  session.Delivery,
+		AudioPlan: session.AudioPlan,
+		Errors:    append([]string(nil), session.ffmpegErrors...),
+	}
 	elapsed := time.Since(session.CreatedAt)
 	streamDuration := time.Since(session.StreamStartTime)
 	bytesStreamed := session.BytesStreamed
@@ -3531,6 +3724,8 @@ func (m *HLSManager) CleanupSession(sessionID string) {
 	}
 	session.mu.RUnlock()
 
+	globalPlaybackNotifier.end(session.playbackKey, usage)
+
 	log.Printf("[hls] SESSION_SUMMARY: id=%s elapsed=%v stream_duration=%v bytes=%d segments_created=%d segments_requested=%d first_segment_delay=%v idle_timeout=%v",
 		sessionID, elapsed, streamDuration, bytesStreamed, segmentsCreated, segmentRequestCount, firstSegmentDelay, idleTriggered)
 
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"novastream/models"
+	release_blocklist "novastream/services/release_blocklist"
+)
+
+const (
+	// Input error recoveries on the same source before switching to the next candidate
+	hlsFailoverAfterAttempts = 2
+
+	// Maximum number of ranked alternatives remembered per stream
+	hlsMaxAlternatives = 10
+
+	// How long alternatives are kept for a resolved stream path
+	hlsAlternativesTTL = 6 * time.Hour
+
+	// Time allowed for resolving and probing one alternative during failover
+	hlsFailoverResolveTimeout = 2 * time.Minute
+)
+
+// SourceResolver resolves a search result into a playable stream. It is used to switch an
+// HLS session to another release when its source keeps failing.
+type SourceResolver interface {
+	Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error)
+}
+
+// SourceAlternativesRecorder remembers the ranked search results that were not picked for a
+// resolved stream, so HLS sessions started on that stream can fail over to them.
+type SourceAlternativesRecorder interface {
+	RememberAlternatives(path string, selected models.NZBResult, alternatives []models.NZBResult)
+}
+
+var _ SourceAlternativesRecorder = (*HLSManager)(nil)
+
+// sourceAlternatives is the selected release of a resolved stream and its fallbacks.
+type sourceAlternatives struct {
+	selected     models.NZBResult
+	alternatives []models.NZBResult
+	expiresAt    time.Time
+}
+
+// SetSourceResolver enables failover to alternative releases for sessions that have them.
+func (m *HLSManager) SetSourceResolver(resolver SourceResolver) {
+	m.alternativesMu.Lock()
+	defer m.alternativesMu.Unlock()
+	m.sourceResolver = resolver
+}
+
+// SetReleaseFailureRecorder enables blocklisting releases that fail during HLS playback.
+func (m *HLSManager) SetReleaseFailureRecorder(recorder ReleaseFailureRecorder) {
+	m.alternativesMu.Lock()
+	defer m.alternativesMu.Unlock()
+	m.releaseFailures = recorder
+}
+
+// RememberAlternatives records the ranked fallbacks of a resolved stream path. Sessions
+// created for the path within hlsAlternativesTTL pick them up.
+func (m *HLSManager) RememberAlternatives(path string, selected models.NZBResult, alternatives []models.NZBResult) {
+	key := hlsSourcePath(path)
+	if m == nil || key == "" {
+		return
+	}
+	if len(alternatives) > hlsMaxAlternatives {
+		alternatives = alternatives[:hlsMaxAlternatives]
+	}
+
+	m.alternativesMu.Lock()
+	defer m.alternativesMu.Unlock()
+	now := time.Now()
+	for p, entry := range m.alternatives {
+		if now.After(entry.expiresAt) {
+			delete(m.alternatives, p)
+		}
+	}
+	m.alternatives[key] = &sourceAlternatives{
+		selected:     selected,
+		alternatives: append([]models.NZBResult(nil), alternatives...),
+		expiresAt:    now.Add(hlsAlternativesTTL),
+	}
+	log.Printf("[hls] remembered %d alternative sources for %s", len(alternatives), key)
+}
+
+// attachAlternatives copies the remembered alternatives of the session's stream onto it.
+func (m *HLSManager) attachAlternatives(session *HLSSession) {
+	m.alternativesMu.RLock()
+	entry, ok := m.alternatives[hlsSourcePath(session.Path)]
+	if !ok {
+		entry, ok = m.alternatives[hlsSourcePath(session.OriginalPath)]
+	}
+	m.alternativesMu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return
+	}
+
+	session.mu.Lock()
+	session.source = entry.selected
+	session.alternatives = append([]models.NZBResult(nil), entry.alternatives...)
+	session.SourceService = string(entry.selected.ServiceType)
+	session.SourceTitle = entry.selected.Title
+	session.mu.Unlock()
+}
+
+// hasAlternatives reports whether the session can fail over to another release.
+func (m *HLSManager) hasAlternatives(session *HLSSession) bool {
+	m.alternativesMu.RLock()
+	resolver := m.sourceResolver
+	m.alternativesMu.RUnlock()
+	if resolver == nil {
+		return false
+	}
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+	return len(session.alternatives) > 0 && !session.IsLive
+}
+
+// failoverToAlternative switches a session whose source keeps failing to the next
+// alternative that resolves and probes, and restarts transcoding on it at the current
+// position. It returns false if no alternative could be used.
+func (m *HLSManager) failoverToAlternative(session *HLSSession) (bool, error) {
+	m.alternativesMu.RLock()
+	resolver := m.sourceResolver
+	failures := m.releaseFailures
+	m.alternativesMu.RUnlock()
+
+	highestSegment := m.findHighestSegmentNumber(session)
+	newTranscodingOffset := m.resumeOffset(session, highestSegment)
+
+	for {
+		session.mu.Lock()
+		if len(session.alternatives) == 0 || session.FatalError != "" {
+			session.mu.Unlock()
+			return false, nil
+		}
+		candidate := session.alternatives[0]
+		session.alternatives = session.alternatives[1:]
+		session.mu.Unlock()
+
+		log.Printf("[hls] session %s: source failed repeatedly, trying %s release %q at %.2fs",
+			session.ID, candidate.ServiceType, candidate.Title, newTranscodingOffset)
+
+		ctx, cancel := context.WithTimeout(context.Background(), hlsFailoverResolveTimeout)
+		path, originalPath, probe, err := m.resolveAlternative(ctx, session, resolver, candidate)
+		cancel()
+		if err != nil {
+			log.Printf("[hls] session %s: alternative %q unusable: %v", session.ID, candidate.Title, err)
+			var dvErr *DVProfileError
+			if errors.As(err, &dvErr) && failures != nil {
+				failures.RecordFailure(candidate, release_blocklist.SourceDVProfile, err.Error())
+			} else {
+				recordResolveFailure(failures, candidate, err)
+			}
+			continue
+		}
+
+		session.mu.Lock()
+		if session.FatalError != "" {
+			session.mu.Unlock()
+			return false, nil
+		}
+		previousTitle := session.SourceTitle
+		m.applyAlternativeProbe(session, probe)
+		session.Path = path
+		session.OriginalPath = originalPath
+		session.source = candidate
+		session.SourceService = string(candidate.ServiceType)
+		session.SourceTitle = candidate.Title
+		session.SourceChanges++
+		session.SourceChangedAt = time.Now()
+		session.FFmpegCmd = nil
+		session.FFmpegPID = 0
+		session.Completed = false
+		session.InputErrorDetected = false
+		session.RecoveryAttempts = 0 // The new source gets its own recovery attempts
+		session.TranscodingOffset = newTranscodingOffset
+		session.CreatedAt = time.Now()
+		session.LastSegmentRequest = time.Now()
+		forceAAC := session.forceAAC
+		newCtx, newCancel := context.WithCancel(context.Background())
+		session.Cancel = newCancel
+		session.mu.Unlock()
+
+		log.Printf("[hls] session %s: switched source from %q to %q, restarting transcoding from %.2fs",
+			session.ID, previousTitle, candidate.Title, newTranscodingOffset)
+		hlsRecoveries.WithLabelValues("source_failover").Inc()
+		return true, m.startTranscoding(newCtx, session, forceAAC)
+	}
+}
+
+// resolveAlternative resolves a candidate and probes the resulting stream, rejecting
+// releases this session's output can't carry.
+func (m *HLSManager) resolveAlternative(ctx context.Context, session *HLSSession, resolver SourceResolver, candidate models.NZBResult) (string, string, *UnifiedProbeResult, error) {
+	resolution, err := resolver.Resolve(ctx, candidate)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if resolution == nil || resolution.WebDAVPath == "" {
+		return "", "", nil, fmt.Errorf("release is not ready for streaming")
+	}
+
+	originalPath := resolution.WebDAVPath
+	path := hlsSourcePath(originalPath)
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		if resolved, err := m.resolveExternalURL(ctx, path); err == nil {
+			path = resolved
+		}
+	}
+
+	probe, err := m.probeAllMetadata(ctx, path)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("probe: %w", err)
+	}
+	if probe == nil {
+		return "", "", nil, fmt.Errorf("probe returned no metadata")
+	}
+
+	session.mu.RLock()
+	hasDV := session.HasDV && !session.DVDisabled
+	session.mu.RUnlock()
+	if probe.HasDolbyVision && parseDVProfileNumber(probe.DolbyVisionProfile) == 5 && !hasDV {
+		return "", "", nil, &DVProfileError{
+			Profile:     probe.DolbyVisionProfile,
+			ProfileNum:  5,
+			Policy:      "hdr",
+			Description: "profile 5 has no HDR fallback layer",
+		}
+	}
+	return path, originalPath, probe, nil
+}
+
+// recordSourceFailure blocklists the release a session is streaming, if it came from a search.
+func (m *HLSManager) recordSourceFailure(session *HLSSession, source, reason string) {
+	m.alternativesMu.RLock()
+	failures := m.releaseFailures
+	m.alternativesMu.RUnlock()
+	if failures == nil {
+		return
+	}
+
+	session.mu.RLock()
+	candidate := session.source
+	session.mu.RUnlock()
+	if candidate.Title == "" && candidate.GUID == "" {
+		return
+	}
+	failures.RecordFailure(candidate, source, reason)
+}
+
+// applyAlternativeProbe updates the session's stream metadata for a new source, keeping the
+// selected audio and subtitle languages. Must be called with session.mu held.
+func (m *HLSManager) applyAlternativeProbe(session *HLSSession, probe *UnifiedProbeResult) {
+	previous := session.ProbeData
+	if previous != nil {
+		session.AudioTrackIndex = remapAudioTrack(previous.AudioStreams, probe.AudioStreams, session.AudioTrackIndex)
+		session.SubtitleTrackIndex = remapSubtitleTrack(previous.SubtitleStreams, probe.SubtitleStreams, session.SubtitleTrackIndex)
+	} else {
+		session.AudioTrackIndex = -1
+		session.SubtitleTrackIndex = -1
+	}
+	session.ProbeData = probe
+	if probe.Duration > 0 {
+		session.Duration = probe.Duration
+	}
+
+	// Dolby Vision is only kept if both the session and the new source have it
+	keepDV := session.HasDV && !session.DVDisabled && probe.HasDolbyVision && !isDolbyVisionProfile7(probe.DolbyVisionProfile)
+	session.HasHDR = probe.HasHDR10 || (probe.HasDolbyVision && !keepDV)
+	session.HasDV = keepDV
+	if keepDV {
+		session.DVProfile = probe.DolbyVisionProfile
+	} else {
+		session.DVProfile = ""
+	}
+	session.DVDisabled = false
+	session.HDRMetadataDisabled = false
+	session.BitstreamErrors = 0
+}
+
+// remapAudioTrack returns the stream index in next with the language of the selected stream
+// in previous, or -1 for the default track.
+func remapAudioTrack(previous, next []audioStreamInfo, index int) int {
+	if index < 0 {
+		return -1
+	}
+	language := ""
+	for _, s := range previous {
+		if s.Index == index {
+			language = s.Language
+			break
+		}
+	}
+	if language == "" {
+		return -1
+	}
+	for _, s := range next {
+		if strings.EqualFold(s.Language, language) && !isHLSCommentaryTrack(s.Title) {
+			return s.Index
+		}
+	}
+	return -1
+}
+
+// remapSubtitleTrack returns the stream index in next with the language and forced flag of
+// the selected stream in previous, or -1 for no subtitles.
+func remapSubtitleTrack(previous, next []subtitleStreamInfo, index int) int {
+	if index < 0 {
+		return -1
+	}
+	var selected *subtitleStreamInfo
+	for i := range previous {
+		if previous[i].Index == index {
+			selected = &previous[i]
+			break
+		}
+	}
+	if selected == nil || selected.Language == "" {
+		return -1
+	}
+	for _, s := range next {
+		if strings.EqualFold(s.Language, selected.Language) && s.IsForced == selected.IsForced {
+			return s.Index
+		}
+	}
+	return -1
+}
+
+// hlsSourcePath strips the WebDAV mount prefix from a stream path, the way HLS sessions are
+// keyed. External URLs are returned unchanged.
+func hlsSourcePath(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "/webdav/") {
+		return strings.TrimPrefix(path, "/webdav")
+	}
+	if strings.HasPrefix(path, "webdav/") {
+		return "/" + strings.TrimPrefix(path, "webdav/")
+	}
+	return path
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestRememberAlternativesAttachesToMatchingSession(t *testing.T) {
+	m := &HLSManager{alternatives: make(map[string]*sourceAlternatives)}
+	selected := models.NZBResult{Title: "Movie.2024.2160p", ServiceType: models.ServiceTypeDebrid}
+	alternatives := []models.NZBResult{
+		{Title: "Movie.2024.1080p", ServiceType: models.ServiceTypeUsenet},
+		{Title: "Movie.2024.720p", ServiceType: models.ServiceTypeDebrid},
+	}
+	m.RememberAlternatives("/webdav/debrid/movie.mkv", selected, alternatives)
+
+	session := &HLSSession{Path: "/debrid/movie.mkv"}
+	m.attachAlternatives(session)
+	if len(session.alternatives) != 2 || session.alternatives[0].Title != "Movie.2024.1080p" {
+		t.Fatalf("expected alternatives to be attached in rank order, got %+v", session.alternatives)
+	}
+	if session.SourceService != string(models.ServiceTypeDebrid) || session.SourceTitle != selected.Title {
+		t.Fatalf("expected source %q/%q, got %q/%q", models.ServiceTypeDebrid, selected.Title, session.SourceService, session.SourceTitle)
+	}
+
+	// Without a resolver the session can't switch sources
+	if m.hasAlternatives(session) {
+		t.Fatalf("expected no failover without a source resolver")
+	}
+
+	other := &HLSSession{Path: "/debrid/other.mkv"}
+	m.attachAlternatives(other)
+	if len(other.alternatives) != 0 {
+		t.Fatalf("expected no alternatives for another path, got %+v", other.alternatives)
+	}
+}
+
+func TestRemapTracksKeepsLanguage(t *testing.T) {
+	previousAudio := []audioStreamInfo{{Index: 1, Language: "eng"}, {Index: 2, Language: "ger"}}
+	nextAudio := []audioStreamInfo{{Index: 1, Language: "ger"}, {Index: 2, Language: "ger", Title: "Director's Commentary"}, {Index: 3, Language: "GER"}}
+	if got := remapAudioTrack(previousAudio, nextAudio, 2); got != 1 {
+		t.Fatalf("expected German audio track 1, got %d", got)
+	}
+	if got := remapAudioTrack(previousAudio, nextAudio, 1); got != -1 {
+		t.Fatalf("expected default audio when the language is missing, got %d", got)
+	}
+
+	previousSubs := []subtitleStreamInfo{{Index: 4, Language: "eng", IsForced: true}}
+	nextSubs := []subtitleStreamInfo{{Index: 3, Language: "eng"}, {Index: 5, Language: "eng", IsForced: true}}
+	if got := remapSubtitleTrack(previousSubs, nextSubs, 4); got != 5 {
+		t.Fatalf("expected forced English subtitle 5, got %d", got)
+	}
+	if got := remapSubtitleTrack(previousSubs, nextSubs, -1); got != -1 {
+		t.Fatalf("expected subtitles to stay off, got %d", got)
+	}
+}
+
+func TestResumeOffsetCountsOnlyCurrentRun(t *testing.T) {
+	m := &HLSManager{}
+	// A recovered run that started at segment 10 from 40s has written segments 10-14
+	session := &HLSSession{TranscodingOffset: 40, runStartSegment: 10}
+	if got, want := m.resumeOffset(session, 14), 40+5*hlsSegmentDuration; got != want {
+		t.Fatalf("expected offset %.2f, got %.2f", want, got)
+	}
+	if got := m.resumeOffset(session, -1); got != 40 {
+		t.Fatalf("expected offset 40 without new segments, got %.2f", got)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 type indexerService interface {
 	Search(context.Context, indexer.SearchOptions) ([]models.NZBResult, error)
+	Usage() ([]indexer.IndexerUsageStatus, error)
 }
 
 var _ indexerService = (*indexer.Service)(nil)
@@ -38,10 +39,24 @@ func (h *IndexerHandler) SetMetadataService(svc SeriesDetailsProvider) {
 	h.MetadataSvc = svc
 }
 
+// Usage reports each indexer's API and grab usage today and whether it is paused.
+func (h *IndexerHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.Service.Usage()
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"indexers": usage,
+	})
+}
+
 func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 	query := strings.TrimSpace(r.URL.Query().Get("q"))
 	categories := r.URL.Query()["cat"]
 	imdbID := strings.TrimSpace(r.URL.Query().Get("imdbId"))
+	tvdbID := strings.TrimSpace(r.URL.Query().Get("tvdbId"))
 	mediaType := strings.TrimSpace(r.URL.Query().Get("mediaType"))
 	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
 	// Client ID from header (preferred) or query param
@@ -77,12 +92,17 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 		Categories:      categories,
 		MaxResults:      max,
 		IMDBID:          imdbID,
+		TVDBID:          tvdbID,
 		MediaType:       mediaType,
 		Year:            year,
 		UserID:          userID,
 		ClientID:        clientID,
 		EpisodeResolver: episodeResolver,
 	}
+	// Explanations name the real releases, which demo mode hides
+	if r.URL.Query().Get("explain") == "true" && !h.DemoMode {
+		opts.Explain = &indexer.SearchExplanation{}
+	}
 
 	results, err := h.Service.Search(r.Context(), opts)
 	if err != nil {
@@ -103,6 +123,13 @@ func (h *IndexerHandler) Search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if opts.Explain != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results":     results,
+			"explanation": opts.Explain,
+		})
+		return
+	}
 	json.NewEncoder(w).Encode(results)
 }
 
@@ -26,6 +26,10 @@ func (f *fakeIndexerService) Search(_ context.Context, opts indexer.SearchOption
 	return f.results, nil
 }
 
+func (f *fakeIndexerService) Usage() ([]indexer.IndexerUsageStatus, error) {
+	return nil, nil
+}
+
 func TestIndexerHandler_Search(t *testing.T) {
 	fake := &fakeIndexerService{
 		results: []models.NZBResult{{Title: "The Expanse", Indexer: "nzbPlanet", SizeBytes: 1234}},
@@ -97,3 +101,31 @@ func TestIndexerHandler_SearchError(t *testing.T) {
 		t.Fatalf("expected error message, got %v", payload)
 	}
 }
+
+func TestIndexerHandler_SearchExplain(t *testing.T) {
+	fake := &fakeIndexerService{results: []models.NZBResult{{Title: "The Expanse"}}}
+	handler := NewIndexerHandler(fake, false)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/indexers/search?q=expanse&explain=true", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Search(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if fake.lastOpts.Explain == nil {
+		t.Fatal("expected the search to be explained")
+	}
+
+	var payload struct {
+		Results     []models.NZBResult         `json:"results"`
+		Explanation *indexer.SearchExplanation `json:"explanation"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if len(payload.Results) != 1 || payload.Explanation == nil {
+		t.Fatalf("unexpected payload: %s", rec.Body.String())
+	}
+}
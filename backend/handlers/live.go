@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"novastream/config"
+	"novastream/services/dvr"
 )
 
 const (
@@ -97,6 +98,13 @@ type LiveHandler struct {
 	analyzeDurationSec int  // FFmpeg analyzeduration in seconds (0 = default)
 	lowLatency         bool // Enable low-latency mode
 	cfgManager         *config.Manager
+
+	// XMLTV guide, loaded lazily or by the EPG refresh task
+	epgMu             sync.RWMutex
+	epgRefreshMu      sync.Mutex
+	epg               *epgGuide
+	epgRefreshing     bool      // a background refresh of an expired guide is running
+	epgRefreshAttempt time.Time // start of the last background refresh
 }
 
 // NewLiveHandler creates a handler capable of fetching remote playlists.
@@ -426,7 +434,7 @@ func (h *LiveHandler) saveToCache(key string, data []byte, contentType string) e
 	return nil
 }
 
-// ClearCache removes all cached playlists, forcing a fresh fetch on next request.
+// ClearCache removes all cached playlists and guides, forcing a fresh fetch on next request.
 func (h *LiveHandler) ClearCache(w http.ResponseWriter, r *http.Request) {
 	h.cacheMu.Lock()
 	defer h.cacheMu.Unlock()
@@ -451,8 +459,8 @@ func (h *LiveHandler) ClearCache(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 		name := entry.Name()
-		// Only remove .m3u and .meta files
-		if strings.HasSuffix(name, ".m3u") || strings.HasSuffix(name, ".meta") {
+		// Only remove .m3u, .meta and .xmltv files
+		if strings.HasSuffix(name, ".m3u") || strings.HasSuffix(name, ".meta") || strings.HasSuffix(name, ".xmltv") {
 			path := filepath.Join(cacheDir, name)
 			if err := os.Remove(path); err != nil {
 				log.Printf("[live] failed to remove cache file %s: %v", name, err)
@@ -462,6 +470,10 @@ func (h *LiveHandler) ClearCache(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.epgMu.Lock()
+	h.epg = nil
+	h.epgMu.Unlock()
+
 	log.Printf("[live] cleared %d cached playlist files", cleared)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -803,6 +815,40 @@ func (h *LiveHandler) isXtreamMode() bool {
 		settings.Live.XtreamPassword != ""
 }
 
+// ResolveChannel looks up a channel of the configured playlist by ID, so stream URLs come
+// from the provider rather than from clients. Channels hidden by the Live TV filters are
+// not found.
+func (h *LiveHandler) ResolveChannel(ctx context.Context, channelID string) (dvr.Channel, error) {
+	if h.cfgManager == nil {
+		return dvr.Channel{}, errors.New("config manager not configured")
+	}
+	settings, err := h.cfgManager.Load()
+	if err != nil {
+		return dvr.Channel{}, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	var channels []LiveChannel
+	if h.isXtreamMode() {
+		channels, err = h.fetchXtreamChannels(ctx, &settings)
+		if err != nil {
+			return dvr.Channel{}, err
+		}
+	} else {
+		contents, err := h.fetchPlaylistContents(ctx)
+		if err != nil {
+			return dvr.Channel{}, err
+		}
+		channels = parseM3UPlaylist(contents)
+	}
+
+	for _, channel := range filterChannels(channels, settings.Live.Filtering) {
+		if channel.ID == channelID {
+			return dvr.Channel{ID: channel.ID, Name: channel.Name, TvgID: channel.TvgID, StreamURL: channel.URL}, nil
+		}
+	}
+	return dvr.Channel{}, fmt.Errorf("%w: %q", dvr.ErrUnknownChannel, channelID)
+}
+
 // GetChannels returns parsed and filtered channels from the configured playlist.
 func (h *LiveHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
 	var allChannels []LiveChannel
@@ -0,0 +1,595 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxEPGSize   = 512 * 1024 * 1024 // 512 MiB, full-country guides are large
+	defaultEPGTimeout   = 5 * time.Minute
+	epgRetryInterval    = 5 * time.Minute // between background refreshes of an expired guide
+	defaultGridHours    = 3
+	maxGridHours        = 48
+	xmltvTimeLayout     = "20060102150405 -0700"
+	xmltvTimeLayoutNoTZ = "20060102150405"
+)
+
+// EPGProgramme is a single guide entry for a channel.
+type EPGProgramme struct {
+	ChannelID   string    `json:"channelId"`
+	Title       string    `json:"title"`
+	SubTitle    string    `json:"subTitle,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Categories  []string  `json:"categories,omitempty"`
+	EpisodeNum  string    `json:"episodeNum,omitempty"`
+	Icon        string    `json:"icon,omitempty"`
+	Start       time.Time `json:"start"`
+	Stop        time.Time `json:"stop"`
+}
+
+// EPGNowNext holds the current and following programme of a channel.
+type EPGNowNext struct {
+	TvgID string        `json:"tvgId"`
+	Now   *EPGProgramme `json:"now,omitempty"`
+	Next  *EPGProgramme `json:"next,omitempty"`
+}
+
+// EPGNowNextResponse is the response for the GetEPGNowNext endpoint.
+type EPGNowNextResponse struct {
+	Channels  []EPGNowNext `json:"channels"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// EPGChannelSchedule lists the programmes of a channel within a grid window.
+type EPGChannelSchedule struct {
+	TvgID      string         `json:"tvgId"`
+	Name       string         `json:"name,omitempty"`
+	Icon       string         `json:"icon,omitempty"`
+	Programmes []EPGProgramme `json:"programmes"`
+}
+
+// EPGGridResponse is the response for the GetEPGGrid endpoint.
+type EPGGridResponse struct {
+	Start     time.Time            `json:"start"`
+	End       time.Time            `json:"end"`
+	Channels  []EPGChannelSchedule `json:"channels"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// epgChannel is a <channel> element of the guide.
+type epgChannel struct {
+	ID   string
+	Name string
+	Icon string
+}
+
+// epgGuide is a parsed XMLTV document indexed by lower-cased channel ID.
+type epgGuide struct {
+	sourceURL  string
+	loadedAt   time.Time
+	fetchedAt  time.Time     // when the cached copy was downloaded
+	ttl        time.Duration // how long the cached copy is used before downloading it again
+	channels   map[string]epgChannel
+	programmes map[string][]EPGProgramme // sorted by start
+}
+
+// expired reports whether the guide is older than its TTL.
+func (g *epgGuide) expired(now time.Time) bool {
+	return g.ttl > 0 && now.Sub(g.fetchedAt) > g.ttl
+}
+
+func (g *epgGuide) programmeCount() int {
+	count := 0
+	for _, list := range g.programmes {
+		count += len(list)
+	}
+	return count
+}
+
+// lookup finds a channel's programmes by tvg-id; matching ignores case since providers are
+// inconsistent between the playlist and the guide.
+func (g *epgGuide) lookup(tvgID string) (epgChannel, []EPGProgramme) {
+	key := strings.ToLower(strings.TrimSpace(tvgID))
+	return g.channels[key], g.programmes[key]
+}
+
+// nowNext returns the programme airing at the given time and the one following it.
+func (g *epgGuide) nowNext(tvgID string, at time.Time) EPGNowNext {
+	result := EPGNowNext{TvgID: tvgID}
+	_, programmes := g.lookup(tvgID)
+
+	// First programme that has not finished yet
+	idx := sort.Search(len(programmes), func(i int) bool { return programmes[i].Stop.After(at) })
+	if idx >= len(programmes) {
+		return result
+	}
+	if !programmes[idx].Start.After(at) {
+		now := programmes[idx]
+		result.Now = &now
+		idx++
+	}
+	if idx < len(programmes) {
+		next := programmes[idx]
+		result.Next = &next
+	}
+	return result
+}
+
+// window returns the programmes overlapping [start, end).
+func (g *epgGuide) window(tvgID string, start, end time.Time) EPGChannelSchedule {
+	channel, programmes := g.lookup(tvgID)
+	schedule := EPGChannelSchedule{TvgID: tvgID, Name: channel.Name, Icon: channel.Icon, Programmes: []EPGProgramme{}}
+
+	idx := sort.Search(len(programmes), func(i int) bool { return programmes[i].Stop.After(start) })
+	for ; idx < len(programmes) && programmes[idx].Start.Before(end); idx++ {
+		schedule.Programmes = append(schedule.Programmes, programmes[idx])
+	}
+	return schedule
+}
+
+// xmltvProgramme mirrors the <programme> element.
+type xmltvProgramme struct {
+	Start      string   `xml:"start,attr"`
+	Stop       string   `xml:"stop,attr"`
+	Channel    string   `xml:"channel,attr"`
+	Titles     []string `xml:"title"`
+	SubTitles  []string `xml:"sub-title"`
+	Descs      []string `xml:"desc"`
+	Categories []string `xml:"category"`
+	EpisodeNum []struct {
+		System string `xml:"system,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"episode-num"`
+	Icon struct {
+		Src string `xml:"src,attr"`
+	} `xml:"icon"`
+}
+
+// xmltvChannel mirrors the <channel> element.
+type xmltvChannel struct {
+	ID           string   `xml:"id,attr"`
+	DisplayNames []string `xml:"display-name"`
+	Icon         struct {
+		Src string `xml:"src,attr"`
+	} `xml:"icon"`
+}
+
+// parseXMLTV streams an XMLTV document (optionally gzip-compressed) into a guide.
+// Programmes that ended before the cutoff are dropped to keep memory bounded.
+func parseXMLTV(r io.Reader, cutoff time.Time) (*epgGuide, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip guide: %w", err)
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	guide := &epgGuide{
+		channels:   make(map[string]epgChannel),
+		programmes: make(map[string][]EPGProgramme),
+	}
+
+	decoder := xml.NewDecoder(br)
+	decoder.Strict = false
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse guide: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "channel":
+			var ch xmltvChannel
+			if err := decoder.DecodeElement(&ch, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse channel: %w", err)
+			}
+			key := strings.ToLower(strings.TrimSpace(ch.ID))
+			if key == "" {
+				continue
+			}
+			guide.channels[key] = epgChannel{ID: ch.ID, Name: firstNonEmpty(ch.DisplayNames), Icon: ch.Icon.Src}
+		case "programme":
+			var p xmltvProgramme
+			if err := decoder.DecodeElement(&p, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse programme: %w", err)
+			}
+			programme, ok := p.toProgramme()
+			if !ok || programme.Stop.Before(cutoff) {
+				continue
+			}
+			key := strings.ToLower(programme.ChannelID)
+			guide.programmes[key] = append(guide.programmes[key], programme)
+		}
+	}
+
+	for key, list := range guide.programmes {
+		sort.Slice(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+		guide.programmes[key] = list
+	}
+
+	return guide, nil
+}
+
+func (p xmltvProgramme) toProgramme() (EPGProgramme, bool) {
+	channelID := strings.TrimSpace(p.Channel)
+	start, err := parseXMLTVTime(p.Start)
+	if channelID == "" || err != nil {
+		return EPGProgramme{}, false
+	}
+	stop, err := parseXMLTVTime(p.Stop)
+	if err != nil || !stop.After(start) {
+		// Some guides omit stop; assume a half-hour slot rather than dropping the entry
+		stop = start.Add(30 * time.Minute)
+	}
+
+	programme := EPGProgramme{
+		ChannelID:   channelID,
+		Title:       firstNonEmpty(p.Titles),
+		SubTitle:    firstNonEmpty(p.SubTitles),
+		Description: firstNonEmpty(p.Descs),
+		Icon:        p.Icon.Src,
+		Start:       start,
+		Stop:        stop,
+	}
+	for _, c := range p.Categories {
+		if c = strings.TrimSpace(c); c != "" {
+			programme.Categories = append(programme.Categories, c)
+		}
+	}
+	for _, ep := range p.EpisodeNum {
+		value := strings.TrimSpace(ep.Value)
+		if value == "" {
+			continue
+		}
+		// Prefer the human-readable onscreen numbering (e.g. S01E02) over xmltv_ns
+		if programme.EpisodeNum == "" || ep.System == "onscreen" {
+			programme.EpisodeNum = value
+		}
+	}
+	return programme, true
+}
+
+// parseXMLTVTime parses "YYYYMMDDhhmmss +zzzz"; times without an offset are taken as UTC.
+func parseXMLTVTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(xmltvTimeLayout, value); err == nil {
+		return t.UTC(), nil
+	}
+	if len(value) >= len(xmltvTimeLayoutNoTZ) {
+		return time.Parse(xmltvTimeLayoutNoTZ, value[:len(xmltvTimeLayoutNoTZ)])
+	}
+	return time.Time{}, fmt.Errorf("invalid xmltv time %q", value)
+}
+
+func firstNonEmpty(values []string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (h *LiveHandler) getEPGFilePath(key string) string {
+	return filepath.Join(cacheDir, key+".xmltv")
+}
+
+// RefreshEPG loads the configured XMLTV guide, downloading it again only when the cached copy
+// is older than the playlist cache TTL. It returns the number of programmes loaded.
+func (h *LiveHandler) RefreshEPG(ctx context.Context) (int, error) {
+	h.epgRefreshMu.Lock()
+	defer h.epgRefreshMu.Unlock()
+
+	if h.cfgManager == nil {
+		return 0, errors.New("config manager not configured")
+	}
+	settings, err := h.cfgManager.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	epgURL := settings.Live.GetEffectiveEPGURL()
+	if epgURL == "" {
+		return 0, errors.New("no EPG source configured")
+	}
+	targetURL, err := h.parseRemoteURL(epgURL)
+	if err != nil {
+		return 0, err
+	}
+
+	ttl := h.cacheTTL
+	if settings.Live.PlaylistCacheTTLHours > 0 {
+		ttl = time.Duration(settings.Live.PlaylistCacheTTLHours) * time.Hour
+	}
+
+	cacheFile := h.getEPGFilePath(h.getCacheKey(targetURL.String()))
+	stat, statErr := os.Stat(cacheFile)
+	fresh := statErr == nil && time.Since(stat.ModTime()) <= ttl
+
+	if fresh {
+		h.epgMu.RLock()
+		current := h.epg
+		h.epgMu.RUnlock()
+		if current != nil && current.sourceURL == targetURL.String() && !current.loadedAt.Before(stat.ModTime()) {
+			return current.programmeCount(), nil
+		}
+	} else {
+		if err := h.downloadEPG(ctx, targetURL.String(), cacheFile); err != nil {
+			if statErr != nil {
+				return 0, err
+			}
+			// Keep serving the stale copy rather than an empty guide
+			log.Printf("[live] EPG download failed, using stale cache: %v", err)
+		}
+	}
+
+	file, err := os.Open(cacheFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open cached guide: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat cached guide: %w", err)
+	}
+
+	guide, err := parseXMLTV(file, time.Now().Add(-time.Hour))
+	if err != nil {
+		return 0, err
+	}
+	guide.sourceURL = targetURL.String()
+	guide.loadedAt = time.Now()
+	guide.fetchedAt = info.ModTime()
+	guide.ttl = ttl
+
+	h.epgMu.Lock()
+	h.epg = guide
+	h.epgMu.Unlock()
+
+	count := guide.programmeCount()
+	log.Printf("[live] loaded EPG with %d channels and %d programmes", len(guide.channels), count)
+	return count, nil
+}
+
+// downloadEPG fetches the guide into the cache, replacing the previous copy atomically.
+func (h *LiveHandler) downloadEPG(ctx context.Context, epgURL, cacheFile string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultEPGTimeout)
+	defer cancel()
+
+	log.Printf("[live] fetching EPG from: %s", epgURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, epgURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to construct EPG request: %w", err)
+	}
+
+	// The playlist client's timeout is too short for multi-megabyte guides
+	client := &http.Client{Transport: h.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download EPG: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("EPG fetch returned status %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(cacheDir, "epg-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create EPG cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(resp.Body, defaultMaxEPGSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read EPG: %w", err)
+	}
+	if written > defaultMaxEPGSize {
+		return errors.New("EPG exceeds size limit")
+	}
+
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+
+	if err := os.Rename(tmp.Name(), cacheFile); err != nil {
+		return fmt.Errorf("failed to write EPG cache file: %w", err)
+	}
+	return nil
+}
+
+// currentEPG returns the loaded guide, loading it on first use. A guide older than the TTL
+// keeps being served while a fresh copy is downloaded in the background.
+func (h *LiveHandler) currentEPG(ctx context.Context) (*epgGuide, error) {
+	h.epgMu.RLock()
+	guide := h.epg
+	h.epgMu.RUnlock()
+	if guide != nil {
+		if guide.expired(time.Now()) {
+			h.refreshEPGInBackground()
+		}
+		return guide, nil
+	}
+
+	if _, err := h.RefreshEPG(ctx); err != nil {
+		return nil, err
+	}
+
+	h.epgMu.RLock()
+	defer h.epgMu.RUnlock()
+	return h.epg, nil
+}
+
+// refreshEPGInBackground starts a refresh of an expired guide unless one is running or the
+// last one started within epgRetryInterval, so a failing source isn't fetched on every request.
+func (h *LiveHandler) refreshEPGInBackground() {
+	h.epgMu.Lock()
+	if h.epgRefreshing || time.Since(h.epgRefreshAttempt) < epgRetryInterval {
+		h.epgMu.Unlock()
+		return
+	}
+	h.epgRefreshing = true
+	h.epgRefreshAttempt = time.Now()
+	h.epgMu.Unlock()
+
+	go func() {
+		defer func() {
+			h.epgMu.Lock()
+			h.epgRefreshing = false
+			h.epgMu.Unlock()
+		}()
+		if _, err := h.RefreshEPG(context.Background()); err != nil {
+			log.Printf("[live] background EPG refresh failed: %v", err)
+		}
+	}()
+}
+
+// FindProgramme returns the programme airing on a channel at the given time.
+func (h *LiveHandler) FindProgramme(ctx context.Context, tvgID string, at time.Time) (*EPGProgramme, error) {
+	guide, err := h.currentEPG(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if programme := guide.nowNext(tvgID, at).Now; programme != nil {
+		return programme, nil
+	}
+	return nil, fmt.Errorf("no programme on %q at %s", tvgID, at.Format(time.RFC3339))
+}
+
+// requestedTvgIDs reads channel IDs from repeated or comma-separated tvgId parameters.
+// With none given, every channel in the guide is returned.
+func requestedTvgIDs(r *http.Request, guide *epgGuide) []string {
+	var ids []string
+	for _, raw := range r.URL.Query()["tvgId"] {
+		for _, id := range strings.Split(raw, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) > 0 {
+		return ids
+	}
+
+	for key := range guide.programmes {
+		id := key
+		if ch, ok := guide.channels[key]; ok {
+			id = ch.ID
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetEPGNowNext returns the current and next programme for the requested channels.
+// GET /api/live/epg/now?tvgId=a,b
+func (h *LiveHandler) GetEPGNowNext(w http.ResponseWriter, r *http.Request) {
+	guide, err := h.currentEPG(r.Context())
+	if err != nil {
+		log.Printf("[live] GetEPGNowNext error: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	now := time.Now()
+	ids := requestedTvgIDs(r, guide)
+	response := EPGNowNextResponse{Channels: make([]EPGNowNext, 0, len(ids)), UpdatedAt: guide.loadedAt.UTC()}
+	for _, id := range ids {
+		response.Channels = append(response.Channels, guide.nowNext(id, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[live] GetEPGNowNext JSON encode error: %v", err)
+	}
+}
+
+// GetEPGGrid returns the programmes of the requested channels within a time window.
+// GET /api/live/epg/grid?tvgId=a,b&start=<RFC3339|unix>&hours=3
+func (h *LiveHandler) GetEPGGrid(w http.ResponseWriter, r *http.Request) {
+	start := time.Now().Truncate(30 * time.Minute)
+	if raw := strings.TrimSpace(r.URL.Query().Get("start")); raw != "" {
+		parsed, err := parseGridStart(raw)
+		if err != nil {
+			http.Error(w, `{"error":"invalid start"}`, http.StatusBadRequest)
+			return
+		}
+		start = parsed
+	}
+
+	hours := defaultGridHours
+	if raw := strings.TrimSpace(r.URL.Query().Get("hours")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, `{"error":"invalid hours"}`, http.StatusBadRequest)
+			return
+		}
+		hours = min(parsed, maxGridHours)
+	}
+	end := start.Add(time.Duration(hours) * time.Hour)
+
+	guide, err := h.currentEPG(r.Context())
+	if err != nil {
+		log.Printf("[live] GetEPGGrid error: %v", err)
+		http.Error(w, fmt.Sprintf(`{"error":"%s"}`, err.Error()), http.StatusBadGateway)
+		return
+	}
+
+	ids := requestedTvgIDs(r, guide)
+	response := EPGGridResponse{
+		Start:     start.UTC(),
+		End:       end.UTC(),
+		Channels:  make([]EPGChannelSchedule, 0, len(ids)),
+		UpdatedAt: guide.loadedAt.UTC(),
+	}
+	for _, id := range ids {
+		response.Channels = append(response.Channels, guide.window(id, start, end))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("[live] GetEPGGrid JSON encode error: %v", err)
+	}
+}
+
+func parseGridStart(raw string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleXMLTV = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE tv SYSTEM "xmltv.dtd">
+<tv generator-info-name="test">
+  <channel id="BBCOne.uk">
+    <display-name>BBC One</display-name>
+    <icon src="http://example.com/bbc1.png"/>
+  </channel>
+  <programme start="20250101090000 +0000" stop="20250101100000 +0000" channel="BBCOne.uk">
+    <title lang="en">Breakfast</title>
+  </programme>
+  <programme start="20250101110000 +0100" stop="20250101113000 +0100" channel="BBCOne.uk">
+    <title lang="en">News</title>
+    <episode-num system="xmltv_ns">0.4.</episode-num>
+    <episode-num system="onscreen">S01E05</episode-num>
+    <category>News</category>
+  </programme>
+  <programme start="20250101103000 +0000" stop="20250101120000 +0000" channel="BBCOne.uk">
+    <title lang="en">Homes Under the Hammer</title>
+    <desc>Property auction show.</desc>
+  </programme>
+  <programme start="20241231090000 +0000" stop="20241231100000 +0000" channel="BBCOne.uk">
+    <title>Yesterday</title>
+  </programme>
+</tv>`
+
+func TestParseXMLTV(t *testing.T) {
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(sampleXMLTV))
+	_ = w.Close()
+
+	for name, input := range map[string][]byte{"plain": []byte(sampleXMLTV), "gzip": gz.Bytes()} {
+		guide, err := parseXMLTV(bytes.NewReader(input), cutoff)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %v", name, err)
+		}
+
+		channel, programmes := guide.lookup("bbcone.UK")
+		if channel.Name != "BBC One" || channel.Icon != "http://example.com/bbc1.png" {
+			t.Fatalf("%s: unexpected channel %+v", name, channel)
+		}
+		if len(programmes) != 3 {
+			t.Fatalf("%s: expected 3 programmes after cutoff, got %d", name, len(programmes))
+		}
+		titles := []string{programmes[0].Title, programmes[1].Title, programmes[2].Title}
+		if strings.Join(titles, "|") != "Breakfast|News|Homes Under the Hammer" {
+			t.Fatalf("%s: programmes not sorted by start: %v", name, titles)
+		}
+		if programmes[1].EpisodeNum != "S01E05" || len(programmes[1].Categories) != 1 {
+			t.Fatalf("%s: unexpected programme details %+v", name, programmes[1])
+		}
+	}
+}
+
+func TestEPGGuideNowNextAndWindow(t *testing.T) {
+	guide, err := parseXMLTV(strings.NewReader(sampleXMLTV), time.Time{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	at := time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)
+	nowNext := guide.nowNext("BBCOne.uk", at)
+	if nowNext.Now == nil || nowNext.Now.Title != "Breakfast" {
+		t.Fatalf("expected Breakfast now, got %+v", nowNext.Now)
+	}
+	if nowNext.Next == nil || nowNext.Next.Title != "News" {
+		t.Fatalf("expected News next, got %+v", nowNext.Next)
+	}
+
+	// Programme times with an offset are compared in UTC
+	later := guide.nowNext("BBCOne.uk", time.Date(2025, 1, 1, 10, 15, 0, 0, time.UTC))
+	if later.Now == nil || later.Now.Title != "News" || later.Next == nil || later.Next.Title != "Homes Under the Hammer" {
+		t.Fatalf("unexpected now/next at 10:15: %+v", later)
+	}
+
+	// After the last programme there is nothing on
+	if done := guide.nowNext("BBCOne.uk", time.Date(2025, 1, 1, 13, 0, 0, 0, time.UTC)); done.Now != nil || done.Next != nil {
+		t.Fatalf("expected empty now/next after guide ends, got %+v", done)
+	}
+
+	if missing := guide.nowNext("unknown", at); missing.Now != nil || missing.Next != nil {
+		t.Fatalf("expected empty now/next for unknown channel, got %+v", missing)
+	}
+
+	schedule := guide.window("BBCOne.uk", time.Date(2025, 1, 1, 9, 45, 0, 0, time.UTC), time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC))
+	if len(schedule.Programmes) != 2 || schedule.Programmes[0].Title != "Breakfast" || schedule.Programmes[1].Title != "News" {
+		t.Fatalf("unexpected window: %+v", schedule.Programmes)
+	}
+}
+
+func TestCurrentEPGServesExpiredGuideWhileRefreshing(t *testing.T) {
+	guide, err := parseXMLTV(strings.NewReader(sampleXMLTV), time.Time{})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	guide.fetchedAt = time.Now().Add(-2 * time.Hour)
+	guide.ttl = time.Hour
+
+	// Without a config manager the refresh fails, and the stale guide stays loaded
+	h := &LiveHandler{epg: guide}
+	got, err := h.currentEPG(context.Background())
+	if err != nil || got != guide {
+		t.Fatalf("expected the expired guide to be served, got %p, %v", got, err)
+	}
+
+	h.epgMu.RLock()
+	attempt := h.epgRefreshAttempt
+	h.epgMu.RUnlock()
+	if attempt.IsZero() {
+		t.Fatal("expected a background refresh of the expired guide")
+	}
+
+	// A second request within the retry interval doesn't start another refresh
+	h.currentEPG(context.Background())
+	h.epgMu.RLock()
+	defer h.epgMu.RUnlock()
+	if !h.epgRefreshAttempt.Equal(attempt) {
+		t.Fatal("expected refreshes to be rate limited")
+	}
+}
+
+func TestParseXMLTVTime(t *testing.T) {
+	tests := map[string]time.Time{
+		"20250101120000 +0200": time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		"20250101120000":       time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for input, expect := range tests {
+		got, err := parseXMLTVTime(input)
+		if err != nil {
+			t.Fatalf("parseXMLTVTime(%q) failed: %v", input, err)
+		}
+		if !got.Equal(expect) {
+			t.Fatalf("parseXMLTVTime(%q) = %v, want %v", input, got, expect)
+		}
+	}
+	if _, err := parseXMLTVTime("2025"); err == nil {
+		t.Fatal("expected error for truncated time")
+	}
+}
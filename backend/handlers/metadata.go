@@ -43,6 +43,7 @@ type MetadataHandler struct {
 	Service      metadataService
 	CfgManager   *config.Manager
 	UserSettings userSettingsProvider
+	Restrictions *ContentRestrictions
 }
 
 func NewMetadataHandler(s metadataService, cfgManager *config.Manager) *MetadataHandler {
@@ -54,6 +55,11 @@ func (h *MetadataHandler) SetUserSettingsProvider(provider userSettingsProvider)
 	h.UserSettings = provider
 }
 
+// SetContentRestrictions enables kids profile rating limits on discovery, search and lists.
+func (h *MetadataHandler) SetContentRestrictions(restrictions *ContentRestrictions) {
+	h.Restrictions = restrictions
+}
+
 // DiscoverNewResponse wraps trending items with total count for pagination
 type DiscoverNewResponse struct {
 	Items           []models.TrendingItem `json:"items"`
@@ -112,6 +118,9 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Kids profiles only see titles within their rating limits
+	items = h.Restrictions.FilterTrending(r.Context(), userID, items)
+
 	// Track pre-filter total for explore card logic
 	unfilteredTotal := len(items)
 
@@ -144,6 +153,7 @@ func (h *MetadataHandler) DiscoverNew(w http.ResponseWriter, r *http.Request) {
 func (h *MetadataHandler) Search(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
 	mediaType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
+	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
 	results, err := h.Service.Search(r.Context(), q, mediaType)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -151,6 +161,7 @@ func (h *MetadataHandler) Search(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+	results = h.Restrictions.FilterSearch(r.Context(), userID, results)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
 }
@@ -560,6 +571,8 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	hideUnreleased := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("hideUnreleased"))) == "true"
+	userID := strings.TrimSpace(r.URL.Query().Get("userId"))
+	_, restriction := h.Restrictions.restrictionFor(r.Context(), userID)
 
 	// Parse optional pagination parameters (0 = no limit/offset)
 	limit := 0
@@ -591,8 +604,9 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 
 	// When hideUnreleased is true, we need ALL items to get accurate filtered count
 	// Otherwise, fetch only what we need for pagination
+	// The same applies to kids profiles, whose rating filter also changes the count
 	fetchLimit := 0 // 0 = fetch all
-	if !hideUnreleased {
+	if !hideUnreleased && restriction == nil {
 		if limit > 0 && offset > 0 {
 			fetchLimit = limit + offset
 		} else if limit > 0 {
@@ -608,6 +622,12 @@ func (h *MetadataHandler) CustomList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Kids profiles only see titles within their rating limits
+	if restriction != nil {
+		items = h.Restrictions.FilterTrending(r.Context(), userID, items)
+		total = len(items)
+	}
+
 	// Track pre-filter total for explore card logic
 	unfilteredTotal := total
 
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"novastream/config"
+	"novastream/services/notifications"
+)
+
+type notificationsService interface {
+	Deliveries() []notifications.Delivery
+	ClearDeliveries() error
+	Test(ctx context.Context, hook config.WebhookSettings) (notifications.Delivery, error)
+}
+
+var _ notificationsService = (*notifications.Service)(nil)
+
+// NotificationsHandler exposes the webhook delivery log and test sends to the admin UI.
+type NotificationsHandler struct {
+	Service notificationsService
+}
+
+func NewNotificationsHandler(svc notificationsService) *NotificationsHandler {
+	return &NotificationsHandler{Service: svc}
+}
+
+// ListDeliveries returns recent webhook deliveries, newest first.
+func (h *NotificationsHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deliveries": h.Service.Deliveries(),
+	})
+}
+
+// ClearDeliveries empties the delivery log.
+func (h *NotificationsHandler) ClearDeliveries(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.ClearDeliveries(); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestWebhook sends a test event to the webhook in the request body, as currently edited
+// in the settings page.
+func (h *NotificationsHandler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	var hook config.WebhookSettings
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	delivery, err := h.Service.Test(r.Context(), hook)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "Test notification delivered",
+		"delivery": delivery,
+	})
+}
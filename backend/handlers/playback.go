@@ -21,8 +21,13 @@ type playbackService interface {
 // PlaybackHandler resolves NZB candidates into playable streams via the local registry.
 type PlaybackHandler struct {
 	Service           playbackService
-	SubtitleExtractor SubtitlePreExtractor // For pre-extracting subtitles
-	VideoProber       VideoFullProber      // For probing subtitle streams
+	SubtitleExtractor SubtitlePreExtractor       // For pre-extracting subtitles
+	VideoProber       VideoFullProber            // For probing subtitle streams
+	Restrictions      *ContentRestrictions       // Kids profile rating limits
+	Alternatives      SourceAlternativesRecorder // Remembers fallback releases for source failover
+	Failures          ReleaseFailureRecorder     // Blocklists releases that fail to resolve
+	Choices           ReleaseChoiceStore         // Remembers the release played per profile and title
+	SeasonPacks       SeasonPackIndex            // Remembers resolved season packs per series
 }
 
 var _ playbackService = (*playbacksvc.Service)(nil)
@@ -41,11 +46,46 @@ func (h *PlaybackHandler) SetVideoProber(prober VideoFullProber) {
 	h.VideoProber = prober
 }
 
+// SetContentRestrictions enables kids profile rating limits on playback
+func (h *PlaybackHandler) SetContentRestrictions(restrictions *ContentRestrictions) {
+	h.Restrictions = restrictions
+}
+
+// SetAlternativesRecorder sets where fallback releases sent with a resolve request are kept
+func (h *PlaybackHandler) SetAlternativesRecorder(recorder SourceAlternativesRecorder) {
+	h.Alternatives = recorder
+}
+
+// SetReleaseFailureRecorder enables blocklisting releases that fail to resolve
+func (h *PlaybackHandler) SetReleaseFailureRecorder(recorder ReleaseFailureRecorder) {
+	h.Failures = recorder
+}
+
+// SetReleaseChoiceStore enables remembering the release a profile plays for a title
+func (h *PlaybackHandler) SetReleaseChoiceStore(store ReleaseChoiceStore) {
+	h.Choices = store
+}
+
+// SetSeasonPackIndex enables remembering season packs resolved for a series, so later
+// episodes play from them
+func (h *PlaybackHandler) SetSeasonPackIndex(index SeasonPackIndex) {
+	h.SeasonPacks = index
+}
+
 // Resolve accepts an NZB indexer result and responds with a validated playback source.
 func (h *PlaybackHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		Result      models.NZBResult `json:"result"`
 		StartOffset float64          `json:"startOffset,omitempty"` // Seek position in seconds for subtitle extraction
+		UserID      string           `json:"userId,omitempty"`      // Profile requesting playback (for kids profile limits)
+		// Ranked search results below Result, tried in order if the stream breaks during HLS playback
+		Alternatives []models.NZBResult `json:"alternatives,omitempty"`
+		// Title the release is played for, remembered per profile so resume and next episode use it
+		TitleID       string `json:"titleId,omitempty"`
+		MediaType     string `json:"mediaType,omitempty"`
+		SeasonNumber  int    `json:"seasonNumber,omitempty"`
+		EpisodeNumber int    `json:"episodeNumber,omitempty"`
+		Manual        bool   `json:"manual,omitempty"` // Picked by hand from the release list
 	}
 
 	dec := json.NewDecoder(r.Body)
@@ -59,12 +99,30 @@ func (h *PlaybackHandler) Resolve(w http.ResponseWriter, r *http.Request) {
 		request.Result.Title, request.Result.GUID, request.Result.ServiceType,
 		request.Result.Attributes["titleId"], request.Result.Attributes["titleName"], request.StartOffset)
 
+	userID := request.UserID
+	if userID == "" {
+		userID = r.URL.Query().Get("userId")
+	}
+	if !h.Restrictions.Allow(r.Context(), userID, titleFromCandidate(request.Result), "playback") {
+		http.Error(w, "title is above the rating limit of this profile", http.StatusForbidden)
+		return
+	}
+
 	resolution, err := h.Service.Resolve(r.Context(), request.Result)
 	if err != nil {
+		recordResolveFailure(h.Failures, request.Result, err)
 		http.Error(w, err.Error(), http.StatusBadGateway)
 		return
 	}
 
+	// Remembered even without alternatives so HLS sessions know which release they stream
+	if h.Alternatives != nil && resolution.WebDAVPath != "" {
+		h.Alternatives.RememberAlternatives(resolution.WebDAVPath, request.Result, request.Alternatives)
+	}
+	recordReleaseChoice(h.Choices, userID, request.TitleID, request.MediaType, request.SeasonNumber,
+		request.EpisodeNumber, request.Result, resolution.WebDAVPath, request.Manual)
+	recordSeasonPack(h.SeasonPacks, request.TitleID, resolution)
+
 	// Pre-extract subtitles for direct streaming (non-HLS) path
 	if h.SubtitleExtractor != nil && h.VideoProber != nil && resolution.WebDAVPath != "" {
 		log.Printf("[playback-handler] Probing subtitle streams for pre-extraction")
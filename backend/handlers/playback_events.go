@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/internal/events"
+)
+
+// playbackStopGrace is how long a title must go without any stream or HLS session before
+// playback counts as stopped. Players issue many range requests and seeks recreate HLS
+// sessions, so individual requests are far too granular to notify on.
+const playbackStopGrace = 90 * time.Second
+
+// maxPlaybackErrors caps the FFmpeg errors kept per playback.
+const maxPlaybackErrors = 10
+
+// playbackInfo describes who is watching what.
+type playbackInfo struct {
+	Path        string
+	ProfileID   string
+	ProfileName string
+	ClientID    string
+	UserAgent   string
+	ClientIP    string
+	Kind        string // direct, hls or live
+}
+
+// playbackUsage is what a finished stream or HLS session contributed to its playback.
+type playbackUsage struct {
+	Bytes     int64
+	Delivery  string // direct, remux or transcode
+	AudioPlan string
+	Errors    []string
+}
+
+// StreamSessionRecorder persists finished playbacks to the stream session history.
+type StreamSessionRecorder interface {
+	Record(session *database.StreamSession) error
+}
+
+// SetStreamSessionRecorder enables the stream session history.
+func SetStreamSessionRecorder(recorder StreamSessionRecorder) {
+	globalPlaybackNotifier.mu.Lock()
+	defer globalPlaybackNotifier.mu.Unlock()
+	globalPlaybackNotifier.recorder = recorder
+}
+
+func (p playbackInfo) key() string {
+	return p.ProfileID + "|" + p.ClientIP + "|" + p.Path
+}
+
+type playbackState struct {
+	info      playbackInfo
+	refs      int
+	startedAt time.Time
+	endedAt   time.Time
+	usage     playbackUsage
+	stopTimer *time.Timer
+}
+
+// playbackNotifier collapses direct streams and HLS sessions into playbacks, publishing
+// start and stop events and recording each finished playback in the session history.
+type playbackNotifier struct {
+	mu       sync.Mutex
+	active   map[string]*playbackState
+	grace    time.Duration
+	recorder StreamSessionRecorder
+}
+
+var globalPlaybackNotifier = &playbackNotifier{
+	active: make(map[string]*playbackState),
+	grace:  playbackStopGrace,
+}
+
+// begin records a stream for the title and publishes a start event if nobody was
+// watching it. The returned key is passed to end.
+func (n *playbackNotifier) begin(info playbackInfo) string {
+	key := info.key()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if state, ok := n.active[key]; ok {
+		state.refs++
+		if state.stopTimer != nil {
+			state.stopTimer.Stop()
+			state.stopTimer = nil
+		}
+		return key
+	}
+
+	n.active[key] = &playbackState{info: info, refs: 1, startedAt: time.Now()}
+	events.Publish(events.Event{
+		Type:    events.PlaybackStarted,
+		Title:   "Playback started",
+		Message: fmt.Sprintf("%s started playing %s.", viewerName(info), mediaTitle(info.Path)),
+		Fields:  playbackFields(info),
+	})
+	return key
+}
+
+// end releases a stream, adding what it served to the playback, and publishes a stop
+// event once the title has been idle for the grace period.
+func (n *playbackNotifier) end(key string, usage playbackUsage) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	state, ok := n.active[key]
+	if !ok {
+		return
+	}
+	state.usage.add(usage)
+	state.endedAt = time.Now()
+	if state.refs--; state.refs > 0 {
+		return
+	}
+
+	state.stopTimer = time.AfterFunc(n.grace, func() {
+		n.mu.Lock()
+		current, ok := n.active[key]
+		if !ok || current != state || current.refs > 0 {
+			n.mu.Unlock()
+			return
+		}
+		delete(n.active, key)
+		recorder := n.recorder
+		n.mu.Unlock()
+
+		fields := playbackFields(state.info)
+		watched := state.endedAt.Sub(state.startedAt)
+		if watched > 0 {
+			fields["duration"] = watched.Round(time.Second).String()
+		}
+		events.Publish(events.Event{
+			Type:    events.PlaybackStopped,
+			Title:   "Playback stopped",
+			Message: fmt.Sprintf("%s stopped playing %s.", viewerName(state.info), mediaTitle(state.info.Path)),
+			Fields:  fields,
+		})
+
+		if recorder != nil {
+			if err := recorder.Record(state.streamSession()); err != nil {
+				log.Printf("[playback] failed to record stream session for %s: %v", mediaTitle(state.info.Path), err)
+			}
+		}
+	})
+}
+
+// add merges a finished stream into the playback. A playback that was transcoded at any
+// point counts as transcoded.
+func (u *playbackUsage) add(other playbackUsage) {
+	u.Bytes += other.Bytes
+	if deliveryRank[other.Delivery] > deliveryRank[u.Delivery] {
+		u.Delivery = other.Delivery
+	}
+	if other.AudioPlan != "" {
+		u.AudioPlan = other.AudioPlan
+	}
+	for _, msg := range other.Errors {
+		if len(u.Errors) >= maxPlaybackErrors {
+			break
+		}
+		seen := false
+		for _, existing := range u.Errors {
+			if existing == msg {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			u.Errors = append(u.Errors, msg)
+		}
+	}
+}
+
+var deliveryRank = map[string]int{"direct": 1, "remux": 2, "transcode": 3}
+
+// streamSession converts a finished playback into a history row.
+func (p *playbackState) streamSession() *database.StreamSession {
+	release := mediaTitle(p.info.Path)
+	return &database.StreamSession{
+		ProfileID:       p.info.ProfileID,
+		ProfileName:     p.info.ProfileName,
+		ClientID:        p.info.ClientID,
+		UserAgent:       p.info.UserAgent,
+		ClientIP:        p.info.ClientIP,
+		Title:           releaseTitle(release),
+		ReleaseName:     release,
+		Path:            redactedPath(p.info.Path),
+		Source:          playbackSource(p.info),
+		Delivery:        p.usage.Delivery,
+		AudioPlan:       p.usage.AudioPlan,
+		BytesServed:     p.usage.Bytes,
+		DurationSeconds: int64(p.endedAt.Sub(p.startedAt).Seconds()),
+		Errors:          p.usage.Errors,
+		StartedAt:       p.startedAt,
+		EndedAt:         p.endedAt,
+	}
+}
+
+// redactedPath is the form of a playback path kept in history. Debrid links and live
+// streams carry tokens or account credentials in the user info, path or query of their URL,
+// so URLs are reduced to their host and file name.
+func redactedPath(p string) string {
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return p
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if base := path.Base(u.Path); base != "." && base != "/" {
+		redacted += "/.../" + base
+	}
+	return redacted
+}
+
+// playbackSource reports whether a playback came from usenet, a debrid provider or live TV.
+// Debrid files are served from /debrid/ paths or straight from the provider's CDN.
+func playbackSource(info playbackInfo) string {
+	p := info.Path
+	switch {
+	case info.Kind == "live":
+		return "live"
+	case strings.HasPrefix(p, "debrid:"), strings.HasPrefix(strings.TrimLeft(p, "/"), "debrid/"),
+		strings.HasPrefix(p, "http://"), strings.HasPrefix(p, "https://"):
+		return "debrid"
+	default:
+		return "usenet"
+	}
+}
+
+// releaseTitleCutoff matches the first year, episode or quality token of a release name.
+var releaseTitleCutoff = regexp.MustCompile(`(?i)[ ._\-\[(]+((19|20)\d{2}|S\d{1,2}E\d{1,3}|S\d{1,2}|\d{3,4}p)([ ._\-\])]|$)`)
+
+// releaseTitle turns a release file name into a readable title, e.g.
+// "The.Matrix.1999.1080p.mkv" -> "The Matrix".
+func releaseTitle(release string) string {
+	name := strings.TrimSuffix(release, path.Ext(release))
+	if loc := releaseTitleCutoff.FindStringIndex(name); loc != nil && loc[0] > 0 {
+		name = name[:loc[0]]
+	}
+	name = strings.TrimSpace(strings.NewReplacer(".", " ", "_", " ").Replace(name))
+	if name == "" {
+		return release
+	}
+	return name
+}
+
+func playbackFields(info playbackInfo) map[string]string {
+	return map[string]string{
+		"title":    mediaTitle(info.Path),
+		"profile":  info.ProfileName,
+		"clientIp": info.ClientIP,
+		"type":     info.Kind,
+	}
+}
+
+func viewerName(info playbackInfo) string {
+	switch {
+	case info.ProfileName != "":
+		return info.ProfileName
+	case info.ClientIP != "":
+		return info.ClientIP
+	default:
+		return "Someone"
+	}
+}
+
+// mediaTitle returns the file name of a local path or stream URL, without any query string.
+func mediaTitle(p string) string {
+	if u, err := url.Parse(p); err == nil && u.Scheme != "" {
+		p = u.Path
+	}
+	p = strings.TrimPrefix(p, "debrid:")
+	if base := path.Base(strings.ReplaceAll(p, "\\", "/")); base != "." && base != "/" {
+		return base
+	}
+	return p
+}
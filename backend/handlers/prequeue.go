@@ -15,6 +15,7 @@ import (
 	"novastream/services/history"
 	"novastream/services/indexer"
 	"novastream/services/playback"
+	release_blocklist "novastream/services/release_blocklist"
 	user_settings "novastream/services/user_settings"
 	content_preferences "novastream/services/content_preferences"
 	"novastream/utils/filter"
@@ -43,6 +44,11 @@ type PrequeueHandler struct {
 	configManager           *config.Manager
 	metadataSvc        SeriesDetailsProvider // For episode counting
 	subtitleExtractor  SubtitlePreExtractor  // For pre-extracting subtitles
+	alternativesRecorder SourceAlternativesRecorder // Remembers fallback releases for source failover
+	releaseFailures      ReleaseFailureRecorder     // Blocklists releases that fail health checks, resolution or DV checks
+	releaseChoices       ReleaseChoiceStore         // Release each profile last played per title, tried first
+	seasonPacks          SeasonPackIndex            // Packs resolved per series, later episodes play from them
+	restrictions         *ContentRestrictions       // Kids profile rating limits
 	demoMode           bool
 }
 
@@ -146,6 +152,34 @@ func (h *PrequeueHandler) SetHLSCreator(creator HLSCreator) {
 	h.hlsCreator = creator
 }
 
+// SetAlternativesRecorder sets where the releases ranked below the resolved one are kept
+// so playback can fail over to them
+func (h *PrequeueHandler) SetAlternativesRecorder(recorder SourceAlternativesRecorder) {
+	h.alternativesRecorder = recorder
+}
+
+// SetReleaseFailureRecorder enables blocklisting releases that fail during prequeue
+func (h *PrequeueHandler) SetReleaseFailureRecorder(recorder ReleaseFailureRecorder) {
+	h.releaseFailures = recorder
+}
+
+// SetReleaseChoiceStore makes prequeue try the release a profile last played for a title
+// before the ranked search results, and remember the one it resolves
+func (h *PrequeueHandler) SetReleaseChoiceStore(store ReleaseChoiceStore) {
+	h.releaseChoices = store
+}
+
+// SetSeasonPackIndex makes prequeue play later episodes from season packs it resolved before,
+// without a new search
+func (h *PrequeueHandler) SetSeasonPackIndex(index SeasonPackIndex) {
+	h.seasonPacks = index
+}
+
+// SetContentRestrictions enables kids profile rating limits on prequeued playback
+func (h *PrequeueHandler) SetContentRestrictions(restrictions *ContentRestrictions) {
+	h.restrictions = restrictions
+}
+
 // SetMetadataProber sets the metadata prober for track selection
 func (h *PrequeueHandler) SetMetadataProber(prober VideoMetadataProber) {
 	h.metadataProber = prober
@@ -230,6 +264,25 @@ func (h *PrequeueHandler) Prequeue(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[prequeue] Received request: titleId=%s titleName=%q userId=%s clientId=%s mediaType=%s", req.TitleID, titleName, req.UserID, clientID, mediaType)
 
+	// Checked before searching so nothing above the rating limit is resolved for the profile
+	title := withTitleIDs(models.Title{
+		ID:        strings.TrimSpace(req.TitleID),
+		Name:      titleName,
+		MediaType: models.RatingMediaType(mediaType),
+		IMDBID:    strings.TrimSpace(req.ImdbID),
+		Year:      req.Year,
+	})
+	if !h.restrictions.Allow(r.Context(), req.UserID, title, "prequeue") {
+		http.Error(w, "title is above the rating limit of this profile", http.StatusForbidden)
+		return
+	}
+
+	// Indexers and scrapers that support it search series by TVDB ID
+	tvdbID := strings.TrimSpace(req.TvdbID)
+	if tvdbID == "" && title.TVDBID > 0 {
+		tvdbID = strconv.FormatInt(title.TVDBID, 10)
+	}
+
 	// For series, determine the target episode based on watch history
 	var targetEpisode *models.EpisodeReference
 	if mediaType == "series" || mediaType == "tv" || mediaType == "show" {
@@ -276,7 +329,7 @@ func (h *PrequeueHandler) Prequeue(w http.ResponseWriter, r *http.Request) {
 	entry, _ := h.store.Create(req.TitleID, titleName, req.UserID, mediaType, req.Year, targetEpisode)
 
 	// Start background worker with all the info needed for search
-	go h.runPrequeueWorker(entry.ID, titleName, req.ImdbID, mediaType, req.Year, req.UserID, clientID, targetEpisode, req.StartOffset)
+	go h.runPrequeueWorker(entry.ID, titleName, req.ImdbID, tvdbID, mediaType, req.Year, req.UserID, clientID, targetEpisode, req.StartOffset)
 
 	// Return response
 	resp := playback.PrequeueResponse{
@@ -345,7 +398,7 @@ func (h *PrequeueHandler) Options(w http.ResponseWriter, r *http.Request) {
 }
 
 // runPrequeueWorker runs the prequeue background task
-func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, mediaType string, year int, userID, clientID string, targetEpisode *models.EpisodeReference, startOffset float64) {
+func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, tvdbID, mediaType string, year int, userID, clientID string, targetEpisode *models.EpisodeReference, startOffset float64) {
 	// Create cancellable context
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
@@ -367,46 +420,22 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 		return
 	}
 
-	log.Printf("[prequeue] Searching with query: %q", query)
-
-	// Create episode resolver for TV shows to enable accurate pack size filtering
-	var episodeResolver *filter.SeriesEpisodeResolver
-	if mediaType == "series" && h.metadataSvc != nil {
-		episodeResolver = h.createEpisodeResolver(ctx, titleName, year, imdbID)
-		if episodeResolver != nil {
-			log.Printf("[prequeue] Episode resolver created: %d total episodes, %d seasons", episodeResolver.TotalEpisodes, len(episodeResolver.SeasonEpisodeCounts))
-		}
-	}
-
-	// Search for results (match manual selection limit for consistent fallback coverage)
-	results, err := h.indexerSvc.Search(ctx, indexer.SearchOptions{
-		Query:           query,
-		MaxResults:      50,
-		MediaType:       mediaType,
-		IMDBID:          imdbID,
-		Year:            year,
-		UserID:          userID,
-		ClientID:        clientID,
-		EpisodeResolver: episodeResolver,
-	})
-	if err != nil {
-		log.Printf("[prequeue] Search failed: %v", err)
-		h.failPrequeue(prequeueID, "search failed: "+err.Error())
-		return
+	titleID := ""
+	if entry, ok := h.store.Get(prequeueID); ok && entry != nil {
+		titleID = entry.TitleID
 	}
 
-	if len(results) == 0 {
-		h.failPrequeue(prequeueID, "no results found")
-		return
+	// Next episodes of a season pack resolved earlier play from it without a new search
+	var packResolution *models.PlaybackResolution
+	var packRelease models.NZBResult
+	fromPack := false
+	if mediaType == "series" {
+		packResolution, packRelease, fromPack = resolveFromSeasonPack(ctx, h.seasonPacks, h.playbackSvc, titleID, targetEpisode)
+		if fromPack {
+			log.Printf("[prequeue] Playing from season pack %q, skipping search", packRelease.Title)
+		}
 	}
 
-	log.Printf("[prequeue] Found %d results", len(results))
-
-	// Update status to resolving
-	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
-		e.Status = playback.PrequeueStatusResolving
-	})
-
 	// Load filter settings for DV profile compatibility checking
 	// Priority: client settings > user settings > global settings > default
 	var hdrDVPolicy models.HDRDVPolicy
@@ -443,6 +472,73 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 	needsDVCheck := hdrDVPolicy == models.HDRDVPolicyIncludeHDR
 	log.Printf("[prequeue] HDR/DV policy: %s, needsDVCheck: %v", hdrDVPolicy, needsDVCheck)
 
+	// Resume and next episode play the release (or season pack) this profile last played
+	// while it still resolves; the search only runs when it doesn't
+	var choiceResolution *models.PlaybackResolution
+	var choiceRelease models.NZBResult
+	var choiceProbe *VideoFullResult
+	fromChoice := false
+	if !fromPack {
+		if sticky, ok := releaseChoiceCandidate(h.releaseChoices, userID, titleID, targetEpisode); ok {
+			choiceResolution, choiceProbe, fromChoice = h.resolveReleaseChoice(ctx, sticky, needsDVCheck)
+			choiceRelease = sticky
+		}
+	}
+	searching := !fromPack && !fromChoice
+
+	if searching {
+		log.Printf("[prequeue] Searching with query: %q", query)
+	}
+
+	// Create episode resolver for TV shows to enable accurate pack size filtering
+	var episodeResolver *filter.SeriesEpisodeResolver
+	if mediaType == "series" && h.metadataSvc != nil && searching {
+		episodeResolver = h.createEpisodeResolver(ctx, titleName, year, imdbID)
+		if episodeResolver != nil {
+			log.Printf("[prequeue] Episode resolver created: %d total episodes, %d seasons", episodeResolver.TotalEpisodes, len(episodeResolver.SeasonEpisodeCounts))
+		}
+	}
+
+	// Search for results (match manual selection limit for consistent fallback coverage)
+	var results []models.NZBResult
+	var err error
+	switch {
+	case fromPack:
+		results = []models.NZBResult{packRelease}
+	case fromChoice:
+		results = []models.NZBResult{choiceRelease}
+	default:
+		results, err = h.indexerSvc.Search(ctx, indexer.SearchOptions{
+			Query:           query,
+			MaxResults:      50,
+			MediaType:       mediaType,
+			IMDBID:          imdbID,
+			TVDBID:          tvdbID,
+			Year:            year,
+			UserID:          userID,
+			ClientID:        clientID,
+			EpisodeResolver: episodeResolver,
+		})
+	}
+
+	if err != nil {
+		log.Printf("[prequeue] Search failed: %v", err)
+		h.failPrequeue(prequeueID, "search failed: "+err.Error())
+		return
+	}
+
+	if len(results) == 0 {
+		h.failPrequeue(prequeueID, "no results found")
+		return
+	}
+
+	log.Printf("[prequeue] Found %d results", len(results))
+
+	// Update status to resolving
+	h.store.Update(prequeueID, func(e *playback.PrequeueEntry) {
+		e.Status = playback.PrequeueStatusResolving
+	})
+
 	// Try to resolve the best result using parallel health checks for usenet
 	var resolution *models.PlaybackResolution
 	var lastErr error
@@ -455,11 +551,19 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 	if len(topResults) > parallelHealthCheckLimit {
 		topResults = results[:parallelHealthCheckLimit]
 	}
+	if fromPack {
+		// Already resolved from the pack; its episodes share the encode that passed the DV
+		// check when the pack was first played
+		resolution, selectedResult, topResults = packResolution, &results[0], nil
+	}
+	if fromChoice {
+		resolution, selectedResult, topResults = choiceResolution, &results[0], nil
+	}
 
 	// Count usenet vs debrid in top results
 	usenetInTop := 0
 	for _, r := range topResults {
-		if r.ServiceType != models.ServiceTypeDebrid {
+		if r.ServiceType != models.ServiceTypeDebrid && r.ServiceType != models.ServiceTypeDirect {
 			usenetInTop++
 		}
 	}
@@ -481,7 +585,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 	}
 
 	// Cached probe result for DV checking (reused later for track selection)
-	var cachedProbeResult *VideoFullResult
+	cachedProbeResult := choiceProbe
 
 	// Try to resolve top results in priority order
 	for i, result := range topResults {
@@ -492,7 +596,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 		default:
 		}
 
-		if result.ServiceType == models.ServiceTypeDebrid {
+		if result.ServiceType == models.ServiceTypeDebrid || result.ServiceType == models.ServiceTypeDirect {
 			// Debrid: resolve directly (no health check needed)
 			resolution, lastErr = h.playbackSvc.Resolve(ctx, result)
 			if lastErr == nil && resolution != nil && resolution.WebDAVPath != "" {
@@ -512,6 +616,9 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 						if err := ValidateDVProfile(probeResult.DolbyVisionProfile, "hdr", probeResult.HasDolbyVision); err != nil {
 							log.Printf("[prequeue] DV profile %s incompatible with 'hdr' policy: %v, trying next result",
 								probeResult.DolbyVisionProfile, err)
+							if h.releaseFailures != nil {
+								h.releaseFailures.RecordFailure(result, release_blocklist.SourceDVProfile, err.Error())
+							}
 							resolution = nil
 							lastErr = err
 							continue
@@ -526,6 +633,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 				break
 			}
 			log.Printf("[prequeue] Failed to resolve debrid %s: %v", result.Title, lastErr)
+			recordResolveFailure(h.releaseFailures, result, lastErr)
 			resolution = nil
 		} else {
 			// Usenet: use health check result
@@ -540,6 +648,10 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 			}
 			if !hr.Healthy {
 				log.Printf("[prequeue] Usenet %s unhealthy, skipping", result.Title)
+				if hr.Check != nil && h.releaseFailures != nil {
+					h.releaseFailures.RecordFailure(result, release_blocklist.SourceUsenetHealth,
+						(&playback.HealthError{Status: hr.Check.Status}).Error())
+				}
 				continue
 			}
 
@@ -561,6 +673,9 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 						if err := ValidateDVProfile(probeResult.DolbyVisionProfile, "hdr", probeResult.HasDolbyVision); err != nil {
 							log.Printf("[prequeue] DV profile %s incompatible with 'hdr' policy: %v, trying next result",
 								probeResult.DolbyVisionProfile, err)
+							if h.releaseFailures != nil {
+								h.releaseFailures.RecordFailure(result, release_blocklist.SourceDVProfile, err.Error())
+							}
 							resolution = nil
 							lastErr = err
 							continue
@@ -575,6 +690,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 				break
 			}
 			log.Printf("[prequeue] Failed to resolve usenet %s: %v", result.Title, lastErr)
+			recordResolveFailure(h.releaseFailures, result, lastErr)
 			resolution = nil
 		}
 	}
@@ -608,6 +724,9 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 						if err := ValidateDVProfile(probeResult.DolbyVisionProfile, "hdr", probeResult.HasDolbyVision); err != nil {
 							log.Printf("[prequeue] DV profile %s incompatible with 'hdr' policy: %v, trying next result",
 								probeResult.DolbyVisionProfile, err)
+							if h.releaseFailures != nil {
+								h.releaseFailures.RecordFailure(result, release_blocklist.SourceDVProfile, err.Error())
+							}
 							resolution = nil
 							lastErr = err
 							continue
@@ -622,6 +741,7 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 				break
 			}
 			log.Printf("[prequeue] Failed to resolve %s: %v", result.Title, lastErr)
+			recordResolveFailure(h.releaseFailures, result, lastErr)
 			resolution = nil
 		}
 	}
@@ -648,6 +768,21 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 		}
 	})
 
+	if h.alternativesRecorder != nil && selectedResult != nil {
+		h.alternativesRecorder.RememberAlternatives(resolution.WebDAVPath, *selectedResult,
+			rankedAlternatives(results, *selectedResult, healthResultMap))
+	}
+
+	if selectedResult != nil {
+		season, episode := 0, 0
+		if targetEpisode != nil {
+			season, episode = targetEpisode.SeasonNumber, targetEpisode.EpisodeNumber
+		}
+		recordReleaseChoice(h.releaseChoices, userID, titleID, mediaType, season, episode,
+			*selectedResult, resolution.WebDAVPath, false)
+	}
+	recordSeasonPack(h.seasonPacks, titleID, resolution)
+
 	// Select audio/subtitle tracks based on user preferences
 	selectedAudioTrack := -1
 	selectedSubtitleTrack := -1
@@ -885,6 +1020,39 @@ func (h *PrequeueHandler) runPrequeueWorker(prequeueID, titleName, imdbID, media
 	log.Printf("[prequeue] Prequeue %s is ready", prequeueID)
 }
 
+// resolveReleaseChoice resolves a release the profile played before, applying the DV check
+// of the current device policy. Failures about the release itself are blocklisted like any
+// other; an expired NZB or a torrent the provider dropped just falls back to a search.
+func (h *PrequeueHandler) resolveReleaseChoice(ctx context.Context, release models.NZBResult, needsDVCheck bool) (*models.PlaybackResolution, *VideoFullResult, bool) {
+	log.Printf("[prequeue] Trying last played release before searching: %s", release.Title)
+	resolution, err := h.playbackSvc.Resolve(ctx, release)
+	if err != nil || resolution == nil || resolution.WebDAVPath == "" {
+		log.Printf("[prequeue] Last played release %s no longer resolves, searching instead: %v", release.Title, err)
+		recordResolveFailure(h.releaseFailures, release, err)
+		return nil, nil, false
+	}
+	if !needsDVCheck || h.fullProber == nil {
+		return resolution, nil, true
+	}
+
+	probeResult, err := h.fullProber.ProbeVideoFull(ctx, resolution.WebDAVPath)
+	if err != nil {
+		log.Printf("[prequeue] Probe failed for last played release %s: %v, searching instead", release.Title, err)
+		return nil, nil, false
+	}
+	if probeResult != nil {
+		if err := ValidateDVProfile(probeResult.DolbyVisionProfile, "hdr", probeResult.HasDolbyVision); err != nil {
+			log.Printf("[prequeue] DV profile %s of last played release incompatible with 'hdr' policy: %v, searching instead",
+				probeResult.DolbyVisionProfile, err)
+			if h.releaseFailures != nil {
+				h.releaseFailures.RecordFailure(release, release_blocklist.SourceDVProfile, err.Error())
+			}
+			return nil, nil, false
+		}
+	}
+	return resolution, probeResult, true
+}
+
 // failPrequeue marks a prequeue as failed
 func (h *PrequeueHandler) failPrequeue(prequeueID, errMsg string) {
 	log.Printf("[prequeue] Prequeue %s failed: %s", prequeueID, errMsg)
@@ -1076,3 +1244,35 @@ func (h *PrequeueHandler) findAudioTrackByLanguage(streams []AudioStreamInfo, pr
 func (h *PrequeueHandler) findSubtitleTrackByPreference(streams []SubtitleStreamInfo, preferredLanguage, mode string) int {
 	return FindSubtitleTrackByPreference(streams, preferredLanguage, mode)
 }
+
+// rankedAlternatives returns the results ranked below the selected one, skipping usenet
+// releases whose health check already failed.
+func rankedAlternatives(results []models.NZBResult, selected models.NZBResult, health map[string]playback.HealthCheckResult) []models.NZBResult {
+	resultKey := func(r models.NZBResult) string {
+		if r.DownloadURL != "" {
+			return r.DownloadURL
+		}
+		return r.Link
+	}
+
+	selectedKey := resultKey(selected)
+	start := -1
+	for i, r := range results {
+		if resultKey(r) == selectedKey {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var alternatives []models.NZBResult
+	for _, r := range results[start:] {
+		if hr, ok := health[resultKey(r)]; ok && !hr.Healthy {
+			continue
+		}
+		alternatives = append(alternatives, r)
+	}
+	return alternatives
+}
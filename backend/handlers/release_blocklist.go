@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"novastream/internal/database"
+	"novastream/models"
+	"novastream/services/debrid"
+	playbacksvc "novastream/services/playback"
+	release_blocklist "novastream/services/release_blocklist"
+)
+
+// ReleaseFailureRecorder blocklists releases that failed playback so searches for the same
+// title stop picking them.
+type ReleaseFailureRecorder interface {
+	RecordFailure(candidate models.NZBResult, source, reason string)
+}
+
+var _ ReleaseFailureRecorder = (*release_blocklist.Service)(nil)
+
+// resolveFailureSource classifies a resolve error. It returns "" for errors that say nothing
+// about the release itself (cancellation, indexer quotas, debrid account, rate limit and
+// network trouble). Usenet releases count on a failed health check, debrid and direct
+// releases on a debrid.ReleaseError (not cached, no playable file, dead or infringing).
+func resolveFailureSource(candidate models.NZBResult, err error) string {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ""
+	}
+	var healthErr *playbacksvc.HealthError
+	if errors.As(err, &healthErr) {
+		return release_blocklist.SourceUsenetHealth
+	}
+	if (candidate.ServiceType == models.ServiceTypeDebrid || candidate.ServiceType == models.ServiceTypeDirect) && debrid.IsReleaseError(err) {
+		return release_blocklist.SourceResolve
+	}
+	return ""
+}
+
+// recordResolveFailure blocklists a candidate if its resolve error is about the release.
+func recordResolveFailure(recorder ReleaseFailureRecorder, candidate models.NZBResult, err error) {
+	if recorder == nil {
+		return
+	}
+	if source := resolveFailureSource(candidate, err); source != "" {
+		recorder.RecordFailure(candidate, source, err.Error())
+	}
+}
+
+type releaseBlocklistService interface {
+	List(titleKey string) ([]*database.BlockedRelease, error)
+	Remove(titleKey, releaseKey string) (int64, error)
+}
+
+var _ releaseBlocklistService = (*release_blocklist.Service)(nil)
+
+// ReleaseBlocklistHandler exposes the failed release blocklist.
+type ReleaseBlocklistHandler struct {
+	Service releaseBlocklistService
+}
+
+func NewReleaseBlocklistHandler(svc releaseBlocklistService) *ReleaseBlocklistHandler {
+	return &ReleaseBlocklistHandler{Service: svc}
+}
+
+// List returns the active blocklist entries, optionally for one titleKey.
+func (h *ReleaseBlocklistHandler) List(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.Service.List(strings.TrimSpace(r.URL.Query().Get("titleKey")))
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if releases == nil {
+		releases = []*database.BlockedRelease{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"releases": releases,
+	})
+}
+
+// Delete removes one release of a title (titleKey and releaseKey), every release of a
+// title (titleKey) or the whole blocklist (all=true).
+func (h *ReleaseBlocklistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	titleKey := strings.TrimSpace(query.Get("titleKey"))
+	releaseKey := strings.TrimSpace(query.Get("releaseKey"))
+	if titleKey == "" && releaseKey == "" && query.Get("all") != "true" {
+		writeJSONError(w, "titleKey, releaseKey or all=true is required", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := h.Service.Remove(titleKey, releaseKey)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"removed": removed,
+	})
+}
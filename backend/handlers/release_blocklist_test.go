@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"novastream/models"
+	"novastream/services/debrid"
+	playbacksvc "novastream/services/playback"
+	release_blocklist "novastream/services/release_blocklist"
+)
+
+func TestResolveFailureSource(t *testing.T) {
+	usenet := models.NZBResult{ServiceType: models.ServiceTypeUsenet}
+	torrent := models.NZBResult{ServiceType: models.ServiceTypeDebrid}
+	direct := models.NZBResult{ServiceType: models.ServiceTypeDirect}
+	notCached := fmt.Errorf("add torrent: %w", &debrid.ReleaseError{Reason: "torrent not cached (status: dead)"})
+
+	cases := []struct {
+		name      string
+		candidate models.NZBResult
+		err       error
+		want      string
+	}{
+		{"usenet health check", usenet, &playbacksvc.HealthError{Status: "missing_segments"}, release_blocklist.SourceUsenetHealth},
+		{"usenet nzb download", usenet, errors.New("download nzb failed: 503"), ""},
+		{"debrid release", torrent, notCached, release_blocklist.SourceResolve},
+		{"direct release", direct, &debrid.ReleaseError{Reason: "stream not cached"}, release_blocklist.SourceResolve},
+		{"debrid auth", torrent, errors.New("real-debrid authentication failed: invalid API key"), ""},
+		{"debrid rate limit", torrent, errors.New("add magnet failed with status 429: too many requests"), ""},
+		{"debrid server error", torrent, errors.New("torrent info failed with status 503: unavailable"), ""},
+		{"canceled", torrent, fmt.Errorf("resolve: %w", context.Canceled), ""},
+	}
+	for _, tc := range cases {
+		if got := resolveFailureSource(tc.candidate, tc.err); got != tc.want {
+			t.Errorf("%s: source = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"novastream/models"
+	release_choices "novastream/services/release_choices"
+)
+
+// ReleaseChoiceStore remembers the release each profile last played per title, so resume and
+// next episode playback can try it before a fresh search.
+type ReleaseChoiceStore interface {
+	Get(userID, contentID string) (*models.ReleaseChoice, error)
+	Record(userID string, choice models.ReleaseChoice) error
+}
+
+var _ ReleaseChoiceStore = (*release_choices.Service)(nil)
+
+// recordReleaseChoice stores a resolved release as the profile's choice for a title. Requests
+// without a profile or title are ignored.
+func recordReleaseChoice(store ReleaseChoiceStore, userID, contentID, contentType string, season, episode int, release models.NZBResult, sourcePath string, manual bool) {
+	userID = strings.TrimSpace(userID)
+	contentID = strings.TrimSpace(contentID)
+	if store == nil || userID == "" || contentID == "" {
+		return
+	}
+	err := store.Record(userID, models.ReleaseChoice{
+		ContentID:     contentID,
+		ContentType:   contentType,
+		SeasonNumber:  season,
+		EpisodeNumber: episode,
+		Release:       release,
+		SourcePath:    sourcePath,
+		Manual:        manual,
+	})
+	if err != nil {
+		log.Printf("[release-choice] failed to remember %q for %s: %v", release.Title, contentID, err)
+	}
+}
+
+// releaseChoiceCandidate returns the release the profile last played for a title if it can be
+// used for the target episode (nil for movies).
+func releaseChoiceCandidate(store ReleaseChoiceStore, userID, contentID string, targetEpisode *models.EpisodeReference) (models.NZBResult, bool) {
+	if store == nil || strings.TrimSpace(userID) == "" || strings.TrimSpace(contentID) == "" {
+		return models.NZBResult{}, false
+	}
+	choice, err := store.Get(userID, contentID)
+	if err != nil || choice == nil {
+		return models.NZBResult{}, false
+	}
+	season, episode := 0, 0
+	if targetEpisode != nil {
+		season, episode = targetEpisode.SeasonNumber, targetEpisode.EpisodeNumber
+	}
+	return release_choices.Candidate(choice, season, episode)
+}
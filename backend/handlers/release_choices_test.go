@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+type fakeReleaseChoices map[string]*models.ReleaseChoice
+
+func (f fakeReleaseChoices) Get(userID, contentID string) (*models.ReleaseChoice, error) {
+	return f[userID+"|"+contentID], nil
+}
+
+func (f fakeReleaseChoices) Record(userID string, choice models.ReleaseChoice) error {
+	f[userID+"|"+choice.ContentID] = &choice
+	return nil
+}
+
+func TestReleaseChoiceCandidate(t *testing.T) {
+	store := fakeReleaseChoices{}
+	recordReleaseChoice(store, "user-1", "tvdb:1", "series", 1, 1, models.NZBResult{Title: "Show.S01.1080p", GUID: "pack"}, "/debrid/pack", false)
+	store["user-1|tvdb:1"].SeasonPack = true
+
+	// The next episode of the pack last played is tried before a search
+	got, ok := releaseChoiceCandidate(store, "user-1", "tvdb:1", &models.EpisodeReference{SeasonNumber: 1, EpisodeNumber: 2})
+	if !ok || got.GUID != "pack" || got.Attributes["targetEpisode"] != "2" {
+		t.Fatalf("expected the pack targeted at episode 2, got %+v (ok=%v)", got, ok)
+	}
+
+	// Other profiles, seasons and requests without a profile search as usual
+	if _, ok := releaseChoiceCandidate(store, "user-2", "tvdb:1", &models.EpisodeReference{SeasonNumber: 1, EpisodeNumber: 2}); ok {
+		t.Fatal("expected no candidate for another profile")
+	}
+	if _, ok := releaseChoiceCandidate(store, "user-1", "tvdb:1", &models.EpisodeReference{SeasonNumber: 2, EpisodeNumber: 1}); ok {
+		t.Fatal("expected no candidate for another season")
+	}
+	if _, ok := releaseChoiceCandidate(store, "", "tvdb:1", nil); ok {
+		t.Fatal("expected no candidate without a profile")
+	}
+}
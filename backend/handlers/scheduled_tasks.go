@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -122,6 +123,23 @@ func (h *ScheduledTasksHandler) CreateTask(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	if req.Type == config.ScheduledTaskTypeDebridCleanup {
+		for _, key := range []string{"unplayedDays", "stuckHours"} {
+			value, ok := req.Config[key]
+			if !ok || value == "" {
+				continue
+			}
+			if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"error": "Debrid cleanup " + key + " must be a positive number",
+				})
+				return
+			}
+		}
+	}
+
 	task := config.ScheduledTask{
 		ID:         uuid.New().String(),
 		Type:       req.Type,
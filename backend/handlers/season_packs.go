@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"novastream/models"
+	season_packs "novastream/services/season_packs"
+)
+
+// SeasonPackIndex remembers the season and complete packs resolved per series, so later
+// episodes play from the torrent or usenet directory already there without a new search.
+type SeasonPackIndex interface {
+	Record(titleID string, pack models.SeasonPack)
+	Find(titleID string, season, episode int) (*models.SeasonPack, models.PackFile, bool)
+	Forget(pack models.SeasonPack)
+}
+
+var _ SeasonPackIndex = (*season_packs.Service)(nil)
+
+// seasonPackResolver resolves an episode from a pack resolved earlier.
+type seasonPackResolver interface {
+	ResolvePackFile(ctx context.Context, pack models.SeasonPack, file models.PackFile) (*models.PlaybackResolution, error)
+}
+
+// recordSeasonPack remembers the pack a resolution was played from. Resolutions of single
+// episodes or movies and requests without a title are ignored.
+func recordSeasonPack(index SeasonPackIndex, titleID string, resolution *models.PlaybackResolution) {
+	if index == nil || resolution == nil || resolution.Pack == nil || strings.TrimSpace(titleID) == "" {
+		return
+	}
+	index.Record(titleID, *resolution.Pack)
+}
+
+// resolveFromSeasonPack resolves the target episode from a pack remembered for the title,
+// returning the resolution and the pack release targeted at the episode. Packs that can no
+// longer be played from are forgotten so the caller falls back to a search.
+func resolveFromSeasonPack(ctx context.Context, index SeasonPackIndex, resolver seasonPackResolver, titleID string, targetEpisode *models.EpisodeReference) (*models.PlaybackResolution, models.NZBResult, bool) {
+	if index == nil || resolver == nil || targetEpisode == nil || strings.TrimSpace(titleID) == "" {
+		return nil, models.NZBResult{}, false
+	}
+	season, episode := targetEpisode.SeasonNumber, targetEpisode.EpisodeNumber
+	pack, file, ok := index.Find(titleID, season, episode)
+	if !ok {
+		return nil, models.NZBResult{}, false
+	}
+
+	resolution, err := resolver.ResolvePackFile(ctx, *pack, file)
+	if err != nil || resolution == nil || resolution.WebDAVPath == "" {
+		log.Printf("[season-packs] cannot play S%02dE%02d from %q anymore, searching instead: %v", season, episode, pack.Release.Title, err)
+		if ctx.Err() == nil {
+			index.Forget(*pack)
+		}
+		return nil, models.NZBResult{}, false
+	}
+	return resolution, season_packs.EpisodeRelease(pack.Release, season, episode), true
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+	"novastream/services/stream_history"
+)
+
+type streamHistoryService interface {
+	List(filter database.StreamSessionFilter) ([]*database.StreamSession, int, error)
+	Stats(groupBy string, filter database.StreamSessionFilter) ([]*database.StreamSessionStats, error)
+	Clear() error
+}
+
+var _ streamHistoryService = (*stream_history.Service)(nil)
+
+type streamHistoryClientLookup interface {
+	Get(id string) (*models.Client, error)
+}
+
+// StreamHistoryHandler exposes the persisted playback session log to the admin UI.
+type StreamHistoryHandler struct {
+	Service streamHistoryService
+	Clients streamHistoryClientLookup
+}
+
+func NewStreamHistoryHandler(svc streamHistoryService, clients streamHistoryClientLookup) *StreamHistoryHandler {
+	return &StreamHistoryHandler{Service: svc, Clients: clients}
+}
+
+// streamHistoryEntry is a session with the client's display name resolved.
+type streamHistoryEntry struct {
+	*database.StreamSession
+	ClientName string `json:"clientName,omitempty"`
+}
+
+// ListSessions returns recorded sessions, newest first. Supports profileId, clientId,
+// source, delivery, q, errors=true, since, until (RFC 3339), limit and offset.
+func (h *StreamHistoryHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStreamSessionFilter(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessions, total, err := h.Service.List(filter)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := map[string]string{}
+	entries := make([]streamHistoryEntry, 0, len(sessions))
+	for _, session := range sessions {
+		entries = append(entries, streamHistoryEntry{
+			StreamSession: session,
+			ClientName:    h.clientName(session.ClientID, names),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": entries,
+		"total":    total,
+	})
+}
+
+// GetStats aggregates sessions per profile (groupBy=profile, the default) or per client
+// (groupBy=client). Accepts the same filters as ListSessions.
+func (h *StreamHistoryHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStreamSessionFilter(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groupBy := r.URL.Query().Get("groupBy")
+	if groupBy == "" {
+		groupBy = "profile"
+	}
+	if groupBy != "profile" && groupBy != "client" {
+		writeJSONError(w, "groupBy must be profile or client", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.Service.Stats(groupBy, filter)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if groupBy == "client" {
+		names := map[string]string{}
+		for _, stat := range stats {
+			if name := h.clientName(stat.Key, names); name != "" {
+				stat.Name = name
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"groupBy": groupBy,
+		"stats":   stats,
+	})
+}
+
+// ClearSessions removes the whole session history.
+func (h *StreamHistoryHandler) ClearSessions(w http.ResponseWriter, r *http.Request) {
+	if err := h.Service.Clear(); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clientName resolves a registered client's display name, caching lookups per request.
+func (h *StreamHistoryHandler) clientName(id string, cache map[string]string) string {
+	if id == "" || h.Clients == nil {
+		return ""
+	}
+	if name, ok := cache[id]; ok {
+		return name
+	}
+
+	name := ""
+	if client, err := h.Clients.Get(id); err == nil && client != nil {
+		name = client.Name
+		if name == "" {
+			name = client.DeviceType
+		}
+	}
+	cache[id] = name
+	return name
+}
+
+func parseStreamSessionFilter(r *http.Request) (database.StreamSessionFilter, error) {
+	q := r.URL.Query()
+	filter := database.StreamSessionFilter{
+		ProfileID:  q.Get("profileId"),
+		ClientID:   q.Get("clientId"),
+		Source:     q.Get("source"),
+		Delivery:   q.Get("delivery"),
+		Search:     strings.TrimSpace(q.Get("q")),
+		ErrorsOnly: q.Get("errors") == "true",
+	}
+
+	for name, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := q.Get(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s, expected RFC 3339", name)
+			}
+			*dst = t
+		}
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if raw := q.Get(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*dst = n
+		}
+	}
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+	return filter, nil
+}
@@ -34,6 +34,7 @@ type TrackedStream struct {
 	UserAgent     string
 	done          chan struct{}
 	bytesCounter  *int64
+	playbackKey   string
 }
 
 // Global stream tracker instance
@@ -65,6 +66,10 @@ func (t *StreamTracker) StartStream(r *http.Request, path string, contentLength
 		profileID = r.URL.Query().Get("userId")
 	}
 	profileName := r.URL.Query().Get("profileName")
+	clientID := r.URL.Query().Get("clientId")
+	if clientID == "" {
+		clientID = r.Header.Get("X-Client-ID")
+	}
 
 	bytesCounter := new(int64)
 
@@ -85,6 +90,15 @@ func (t *StreamTracker) StartStream(r *http.Request, path string, contentLength
 		done:          make(chan struct{}),
 		bytesCounter:  bytesCounter,
 	}
+	stream.playbackKey = globalPlaybackNotifier.begin(playbackInfo{
+		Path:        path,
+		ProfileID:   profileID,
+		ProfileName: profileName,
+		ClientID:    clientID,
+		UserAgent:   r.UserAgent(),
+		ClientIP:    clientIP,
+		Kind:        "direct",
+	})
 
 	t.streams[id] = stream
 	return id, bytesCounter
@@ -110,6 +124,11 @@ func (t *StreamTracker) EndStream(id string) {
 	if stream, ok := t.streams[id]; ok {
 		close(stream.done)
 		delete(t.streams, id)
+		bytes := atomic.LoadInt64(&stream.BytesStreamed)
+		if stream.bytesCounter != nil {
+			bytes = atomic.LoadInt64(stream.bytesCounter)
+		}
+		globalPlaybackNotifier.end(stream.playbackKey, playbackUsage{Bytes: bytes, Delivery: "direct"})
 	}
 }
 
@@ -2,183 +2,82 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
 	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
+	"strconv"
+	"strings"
 
-	"novastream/config"
+	"novastream/services/subtitles"
 )
 
 // SubtitlesHandler handles subtitle search and download requests
 type SubtitlesHandler struct {
-	configManager *config.Manager
+	service *subtitles.Service
 }
 
 // NewSubtitlesHandler creates a new SubtitlesHandler
-func NewSubtitlesHandler() *SubtitlesHandler {
-	return &SubtitlesHandler{}
+func NewSubtitlesHandler(service *subtitles.Service) *SubtitlesHandler {
+	return &SubtitlesHandler{service: service}
 }
 
-// NewSubtitlesHandlerWithConfig creates a new SubtitlesHandler with config manager
-func NewSubtitlesHandlerWithConfig(configManager *config.Manager) *SubtitlesHandler {
-	return &SubtitlesHandler{configManager: configManager}
-}
-
-// getSubtitleScriptPaths returns paths to the subtitle Python scripts
-func getSubtitleScriptPaths(scriptName string) (scriptPath, pythonPath string, err error) {
-	// Docker paths (scripts copied to / in container)
-	dockerScript := "/" + scriptName
-	dockerPython := "/.venv/bin/python3"
-
-	if _, err := os.Stat(dockerScript); err == nil {
-		if _, err := os.Stat(dockerPython); err == nil {
-			return dockerScript, dockerPython, nil
-		}
-	}
-
-	// Local development paths
-	_, currentFile, _, ok := runtime.Caller(1)
-	if !ok {
-		return "", "", fmt.Errorf("failed to get current file path")
-	}
-
-	// From backend/handlers/, go up 1 level to backend/
-	scriptPath = filepath.Join(filepath.Dir(currentFile), "..", scriptName)
-	// From backend/handlers/, go up 2 levels to project root for .venv
-	pythonPath = filepath.Join(filepath.Dir(currentFile), "..", "..", ".venv", "bin", "python3")
-
-	return scriptPath, pythonPath, nil
-}
-
-// SubtitleSearchParams represents the search parameters
-type SubtitleSearchParams struct {
-	ImdbID                string `json:"imdb_id"`
-	Title                 string `json:"title"`
-	Year                  *int   `json:"year,omitempty"`
-	Season                *int   `json:"season,omitempty"`
-	Episode               *int   `json:"episode,omitempty"`
-	Language              string `json:"language"`
-	OpenSubtitlesUsername string `json:"opensubtitles_username,omitempty"`
-	OpenSubtitlesPassword string `json:"opensubtitles_password,omitempty"`
-}
-
-// SubtitleResult represents a single subtitle search result
-type SubtitleResult struct {
-	ID              string `json:"id"`
-	Provider        string `json:"provider"`
-	Language        string `json:"language"`
-	Release         string `json:"release"`
-	Downloads       int    `json:"downloads"`
-	HearingImpaired bool   `json:"hearing_impaired"`
-	PageLink        string `json:"page_link"`
+// subtitleQuery reads the title, episode and language of a search or download request.
+func subtitleQuery(r *http.Request) subtitles.Query {
+	q := r.URL.Query()
+	query := subtitles.Query{
+		ImdbID:   strings.TrimSpace(q.Get("imdbId")),
+		Title:    strings.TrimSpace(q.Get("title")),
+		Language: strings.TrimSpace(q.Get("language")),
+	}
+	if query.Language == "" {
+		query.Language = "en"
+	}
+	query.Year, _ = strconv.Atoi(q.Get("year"))
+	query.Season, _ = strconv.Atoi(q.Get("season"))
+	query.Episode, _ = strconv.Atoi(q.Get("episode"))
+	return query
 }
 
-// Search searches for subtitles using subliminal
+// Search searches the subtitle providers. An optional path or external URL of the stream
+// being played is hashed so subtitles for that exact release are found and listed first.
 func (h *SubtitlesHandler) Search(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	q := r.URL.Query()
-	imdbID := q.Get("imdbId")
-	title := q.Get("title")
-	language := q.Get("language")
-	if language == "" {
-		language = "en"
-	}
-
-	params := SubtitleSearchParams{
-		ImdbID:   imdbID,
-		Title:    title,
-		Language: language,
-	}
-
-	// Load OpenSubtitles credentials from config if available
-	if h.configManager != nil {
-		if settings, err := h.configManager.Load(); err == nil {
-			params.OpenSubtitlesUsername = settings.Subtitles.OpenSubtitlesUsername
-			params.OpenSubtitlesPassword = settings.Subtitles.OpenSubtitlesPassword
-		}
-	}
-
-	// Parse year, season and episode if provided
-	if yearStr := q.Get("year"); yearStr != "" {
-		var year int
-		fmt.Sscanf(yearStr, "%d", &year)
-		params.Year = &year
-	}
-	if seasonStr := q.Get("season"); seasonStr != "" {
-		var season int
-		fmt.Sscanf(seasonStr, "%d", &season)
-		params.Season = &season
-	}
-	if episodeStr := q.Get("episode"); episodeStr != "" {
-		var episode int
-		fmt.Sscanf(episodeStr, "%d", &episode)
-		params.Episode = &episode
-	}
-
-	// Convert params to JSON for Python script
-	paramsJSON, err := json.Marshal(params)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	if h.service == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "subtitle search is not available"})
 		return
 	}
 
-	scriptPath, pythonPath, err := getSubtitleScriptPaths("search_subtitles.py")
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		return
+	streamPath := strings.TrimSpace(r.URL.Query().Get("path"))
+	if strings.HasPrefix(streamPath, "/webdav/") {
+		streamPath = strings.TrimPrefix(streamPath, "/webdav")
+	} else if strings.HasPrefix(streamPath, "webdav/") {
+		streamPath = "/" + strings.TrimPrefix(streamPath, "webdav/")
 	}
 
-	cmd := exec.Command(pythonPath, scriptPath, string(paramsJSON))
-	output, err := cmd.Output()
+	query := subtitleQuery(r)
+	results, err := h.service.Search(r.Context(), query, streamPath)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": string(exitErr.Stderr)})
-			return
+		status := http.StatusInternalServerError
+		if errors.Is(err, subtitles.ErrRateLimited) {
+			status = http.StatusTooManyRequests
 		}
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	// Output is already JSON, write it directly
-	w.Write(output)
-}
-
-// SubtitleDownloadParams represents the download parameters
-type SubtitleDownloadParams struct {
-	ImdbID                string `json:"imdb_id"`
-	Title                 string `json:"title"`
-	Year                  *int   `json:"year,omitempty"`
-	Season                *int   `json:"season,omitempty"`
-	Episode               *int   `json:"episode,omitempty"`
-	Language              string `json:"language"`
-	SubtitleID            string `json:"subtitle_id"`
-	Provider              string `json:"provider"`
-	OpenSubtitlesUsername string `json:"opensubtitles_username,omitempty"`
-	OpenSubtitlesPassword string `json:"opensubtitles_password,omitempty"`
+	json.NewEncoder(w).Encode(results)
 }
 
 // Download downloads a specific subtitle and returns VTT content
 func (h *SubtitlesHandler) Download(w http.ResponseWriter, r *http.Request) {
-	log.Printf("[subtitles] Download request: %s", r.URL.String())
 	q := r.URL.Query()
 	subtitleID := q.Get("subtitleId")
 	provider := q.Get("provider")
-	imdbID := q.Get("imdbId")
-	title := q.Get("title")
-	language := q.Get("language")
-	if language == "" {
-		language = "en"
-	}
-	log.Printf("[subtitles] Download params: subtitleID=%s provider=%s imdbID=%s title=%s language=%s", subtitleID, provider, imdbID, title, language)
+	query := subtitleQuery(r)
+	log.Printf("[subtitles] Download params: subtitleID=%s provider=%s imdbID=%s title=%s language=%s", subtitleID, provider, query.ImdbID, query.Title, query.Language)
 
 	if subtitleID == "" || provider == "" {
 		w.Header().Set("Content-Type", "application/json")
@@ -186,78 +85,31 @@ func (h *SubtitlesHandler) Download(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"error": "subtitleId and provider are required"})
 		return
 	}
-
-	params := SubtitleDownloadParams{
-		ImdbID:     imdbID,
-		Title:      title,
-		Language:   language,
-		SubtitleID: subtitleID,
-		Provider:   provider,
-	}
-
-	// Load OpenSubtitles credentials from config if available
-	if h.configManager != nil {
-		if settings, err := h.configManager.Load(); err == nil {
-			params.OpenSubtitlesUsername = settings.Subtitles.OpenSubtitlesUsername
-			params.OpenSubtitlesPassword = settings.Subtitles.OpenSubtitlesPassword
-		}
-	}
-
-	// Parse year, season and episode if provided
-	if yearStr := q.Get("year"); yearStr != "" {
-		var year int
-		fmt.Sscanf(yearStr, "%d", &year)
-		params.Year = &year
-	}
-	if seasonStr := q.Get("season"); seasonStr != "" {
-		var season int
-		fmt.Sscanf(seasonStr, "%d", &season)
-		params.Season = &season
-	}
-	if episodeStr := q.Get("episode"); episodeStr != "" {
-		var episode int
-		fmt.Sscanf(episodeStr, "%d", &episode)
-		params.Episode = &episode
-	}
-
-	// Convert params to JSON for Python script
-	paramsJSON, err := json.Marshal(params)
-	if err != nil {
+	if h.service == nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "subtitle download is not available"})
 		return
 	}
 
-	scriptPath, pythonPath, err := getSubtitleScriptPaths("download_subtitle.py")
+	vtt, err := h.service.Download(r.Context(), query, provider, subtitleID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		return
-	}
-
-	log.Printf("[subtitles] Running Python script: %s with params: %s", scriptPath, string(paramsJSON))
-	cmd := exec.Command(pythonPath, scriptPath, string(paramsJSON))
-	output, err := cmd.Output()
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Printf("[subtitles] Python script error: %s", string(exitErr.Stderr))
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": string(exitErr.Stderr)})
-			return
+		log.Printf("[subtitles] download of %s subtitle %s failed: %v", provider, subtitleID, err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, subtitles.ErrNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, subtitles.ErrRateLimited):
+			status = http.StatusTooManyRequests
 		}
-		log.Printf("[subtitles] Python script exec error: %v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	log.Printf("[subtitles] Python script output: %d bytes", len(output))
-	// Output is VTT content
 	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
-	w.Write(output)
+	w.Write(vtt)
 }
 
 // Options handles OPTIONS requests for CORS preflight
@@ -36,6 +36,7 @@ type usersService interface {
 	SetPlexAccountID(id, plexAccountID string) (models.User, error)
 	ClearPlexAccountID(id string) (models.User, error)
 	SetKidsProfile(id string, isKids bool) (models.User, error)
+	SetRatingLimits(id string, limits *models.ContentRestriction) (models.User, error)
 }
 
 var _ usersService = (*users.Service)(nil)
@@ -598,7 +599,8 @@ func (h *UsersHandler) SetKidsProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		IsKidsProfile bool `json:"isKidsProfile"`
+		IsKidsProfile bool                       `json:"isKidsProfile"`
+		RatingLimits  *models.ContentRestriction `json:"ratingLimits,omitempty"` // Optional maximum certifications
 	}
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
@@ -607,6 +609,20 @@ func (h *UsersHandler) SetKidsProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if body.RatingLimits != nil {
+		if _, err := h.Service.SetRatingLimits(id, body.RatingLimits); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, users.ErrUserNotFound):
+				status = http.StatusNotFound
+			case errors.Is(err, users.ErrInvalidRatingLimit):
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
 	user, err := h.Service.SetKidsProfile(id, body.IsKidsProfile)
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -213,11 +213,13 @@ func (h *VideoHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 	if transmuxReason != "" {
 	}
 	forceAAC := target == "web" || target == "browser"
-	// Check global setting for forced AAC transcoding (for Bluetooth compatibility)
-	if !forceAAC && h.configManager != nil {
-		if settings, err := h.configManager.Load(); err == nil {
-			forceAAC = settings.Playback.ForceAACTranscoding
+	// Check settings for forced AAC transcoding (for Bluetooth compatibility)
+	if !forceAAC {
+		clientID := r.URL.Query().Get("clientId")
+		if clientID == "" {
+			clientID = r.Header.Get("X-Client-ID")
 		}
+		forceAAC = h.getForceAACTranscoding(clientID)
 	}
 	rangeHeader := strings.TrimSpace(r.Header.Get("Range"))
 	rangeSummary := rangeHeader
@@ -1966,11 +1968,13 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 	dvProfile := r.URL.Query().Get("dvProfile")
 	hasHDR := r.URL.Query().Get("hdr") == "true"
 	forceAAC := r.URL.Query().Get("forceAAC") == "true"
-	// Check global setting for forced AAC transcoding (for Bluetooth compatibility)
-	if !forceAAC && h.configManager != nil {
-		if settings, err := h.configManager.Load(); err == nil {
-			forceAAC = settings.Playback.ForceAACTranscoding
+	// Check settings for forced AAC transcoding (for Bluetooth compatibility)
+	if !forceAAC {
+		clientID := r.URL.Query().Get("clientId")
+		if clientID == "" {
+			clientID = r.Header.Get("X-Client-ID")
 		}
+		forceAAC = h.getForceAACTranscoding(clientID)
 	}
 	// Check both "startOffset" (frontend) and "start" (legacy) parameter names
 	startParam := strings.TrimSpace(r.URL.Query().Get("startOffset"))
@@ -2062,7 +2066,7 @@ func (h *VideoHandler) StartHLSSession(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[video] creating HLS session for path=%q dv=%v dvProfile=%q hdr=%v start=%.3fs transcodingOffset=%.3fs audioTrack=%d subtitleTrack=%d",
 		cleanPath, hasDV, dvProfile, hasHDR, startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex)
 
-	session, err := h.hlsManager.CreateSession(r.Context(), cleanPath, path, hasDV, dvProfile, hasHDR, forceAAC, startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex, profileID, profileName, getClientIP(r))
+	session, err := h.hlsManager.CreateSession(r.Context(), cleanPath, path, hasDV, dvProfile, hasHDR, forceAAC, startSeconds, transcodingOffset, audioTrackIndex, subtitleTrackIndex, profileID, profileName, getClientIP(r), clientID, r.UserAgent())
 	if err != nil {
 		log.Printf("[video] failed to create HLS session: %v", err)
 		http.Error(w, fmt.Sprintf("failed to create HLS session: %v", err), http.StatusInternalServerError)
@@ -2355,7 +2359,7 @@ func (h *VideoHandler) CreateHLSSession(ctx context.Context, path string, hasDV
 		}
 	}
 
-	session, err := h.hlsManager.CreateSession(ctx, path, path, hasDV, dvProfile, hasHDR, false, startOffset, 0, audioTrackIndex, subtitleTrackIndex, profileID, "", "")
+	session, err := h.hlsManager.CreateSession(ctx, path, path, hasDV, dvProfile, hasHDR, false, startOffset, 0, audioTrackIndex, subtitleTrackIndex, profileID, "", "", "", "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HLS session: %w", err)
 	}
@@ -3109,6 +3113,26 @@ func (h *VideoHandler) getHDRDVPolicy(userID, clientID string) models.HDRDVPolic
 	return policy
 }
 
+// getForceAACTranscoding reports whether audio should be transcoded to AAC for a client.
+// Cascade: Global -> Client (client overrides global)
+func (h *VideoHandler) getForceAACTranscoding(clientID string) bool {
+	forceAAC := false
+	if h.configManager != nil {
+		if settings, err := h.configManager.Load(); err == nil {
+			forceAAC = settings.Playback.ForceAACTranscoding
+		}
+	}
+
+	if h.clientSettingsSvc != nil && clientID != "" {
+		clientSettings, err := h.clientSettingsSvc.Get(clientID)
+		if err == nil && clientSettings != nil && clientSettings.ForceAACTranscoding != nil {
+			forceAAC = *clientSettings.ForceAACTranscoding
+		}
+	}
+
+	return forceAAC
+}
+
 // parseDVProfileNumber extracts the profile number from a DV profile string like "dvhe.05.06"
 func parseDVProfileNumber(dvProfile string) int {
 	parts := strings.Split(dvProfile, ".")
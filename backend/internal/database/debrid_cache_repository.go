@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DebridCacheRepository handles the debrid cache-availability index
+type DebridCacheRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewDebridCacheRepository creates a new debrid cache repository
+func NewDebridCacheRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *DebridCacheRepository {
+	return &DebridCacheRepository{db: db}
+}
+
+// UpsertDebridCacheEntry records the cache state of a torrent on a provider. A previously
+// stored file list is kept when the new entry has none.
+func (r *DebridCacheRepository) UpsertDebridCacheEntry(entry *DebridCacheEntry) error {
+	var filesJSON *string
+	if len(entry.Files) > 0 {
+		data, err := json.Marshal(entry.Files)
+		if err != nil {
+			return fmt.Errorf("failed to encode debrid cache files: %w", err)
+		}
+		encoded := string(data)
+		filesJSON = &encoded
+	}
+
+	query := `
+		INSERT INTO debrid_cache_index (info_hash, provider, cached, files, checked_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(info_hash, provider) DO UPDATE SET
+			cached = excluded.cached,
+			files = COALESCE(excluded.files, files),
+			checked_at = excluded.checked_at
+	`
+
+	if _, err := r.db.Exec(query, entry.InfoHash, entry.Provider, entry.Cached, filesJSON, entry.CheckedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to upsert debrid cache entry: %w", err)
+	}
+	return nil
+}
+
+// ListDebridCacheEntries returns every provider's entry for the given info hashes
+func (r *DebridCacheRepository) ListDebridCacheEntries(infoHashes []string) ([]*DebridCacheEntry, error) {
+	if len(infoHashes) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(infoHashes)), ",")
+	args := make([]interface{}, len(infoHashes))
+	for i, hash := range infoHashes {
+		args[i] = hash
+	}
+
+	query := `SELECT info_hash, provider, cached, files, checked_at FROM debrid_cache_index WHERE info_hash IN (` + placeholders + `)`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list debrid cache entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*DebridCacheEntry
+	for rows.Next() {
+		var entry DebridCacheEntry
+		var filesJSON sql.NullString
+		if err := rows.Scan(&entry.InfoHash, &entry.Provider, &entry.Cached, &filesJSON, &entry.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan debrid cache entry: %w", err)
+		}
+		if filesJSON.Valid && filesJSON.String != "" {
+			if err := json.Unmarshal([]byte(filesJSON.String), &entry.Files); err != nil {
+				return nil, fmt.Errorf("failed to decode debrid cache files: %w", err)
+			}
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteDebridCacheEntriesBefore removes entries last checked before the cutoff
+func (r *DebridCacheRepository) DeleteDebridCacheEntriesBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM debrid_cache_index WHERE checked_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete debrid cache entries: %w", err)
+	}
+	return result.RowsAffected()
+}
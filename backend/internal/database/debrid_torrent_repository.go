@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DebridTorrentRepository handles the torrents strmr added to debrid accounts
+type DebridTorrentRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewDebridTorrentRepository creates a new debrid torrent repository
+func NewDebridTorrentRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *DebridTorrentRepository {
+	return &DebridTorrentRepository{db: db}
+}
+
+// RecordDebridTorrent stores a newly added torrent. Adding the same torrent again keeps
+// its original added time.
+func (r *DebridTorrentRepository) RecordDebridTorrent(torrent *DebridTorrent) error {
+	query := `
+		INSERT INTO debrid_torrents (provider, torrent_id, info_hash, added_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(provider, torrent_id) DO UPDATE SET
+			info_hash = CASE WHEN excluded.info_hash != '' THEN excluded.info_hash ELSE info_hash END
+	`
+
+	if _, err := r.db.Exec(query, torrent.Provider, torrent.TorrentID, torrent.InfoHash, torrent.AddedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to record debrid torrent: %w", err)
+	}
+	return nil
+}
+
+// MarkDebridTorrentPlayed updates the last time a torrent was streamed
+func (r *DebridTorrentRepository) MarkDebridTorrentPlayed(provider, torrentID string, playedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE debrid_torrents SET last_played_at = ? WHERE provider = ? AND torrent_id = ?`,
+		playedAt.UTC(), provider, torrentID); err != nil {
+		return fmt.Errorf("failed to mark debrid torrent played: %w", err)
+	}
+	return nil
+}
+
+// ListDebridTorrents returns every tracked torrent on a provider
+func (r *DebridTorrentRepository) ListDebridTorrents(provider string) ([]*DebridTorrent, error) {
+	rows, err := r.db.Query(`SELECT provider, torrent_id, info_hash, added_at, last_played_at FROM debrid_torrents WHERE provider = ?`, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list debrid torrents: %w", err)
+	}
+	defer rows.Close()
+
+	var torrents []*DebridTorrent
+	for rows.Next() {
+		var t DebridTorrent
+		var lastPlayed sql.NullTime
+		if err := rows.Scan(&t.Provider, &t.TorrentID, &t.InfoHash, &t.AddedAt, &lastPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan debrid torrent: %w", err)
+		}
+		if lastPlayed.Valid {
+			t.LastPlayedAt = &lastPlayed.Time
+		}
+		torrents = append(torrents, &t)
+	}
+
+	return torrents, rows.Err()
+}
+
+// DeleteDebridTorrent stops tracking a torrent
+func (r *DebridTorrentRepository) DeleteDebridTorrent(provider, torrentID string) error {
+	if _, err := r.db.Exec(`DELETE FROM debrid_torrents WHERE provider = ? AND torrent_id = ?`, provider, torrentID); err != nil {
+		return fmt.Errorf("failed to delete debrid torrent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// IndexerUsageRepository handles daily indexer API usage counters
+type IndexerUsageRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewIndexerUsageRepository creates a new indexer usage repository
+func NewIndexerUsageRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *IndexerUsageRepository {
+	return &IndexerUsageRepository{db: db}
+}
+
+// IncrementIndexerUsage adds to an indexer's counters for the given day
+func (r *IndexerUsageRepository) IncrementIndexerUsage(indexer, day string, searches, grabs, failures int) error {
+	query := `
+		INSERT INTO indexer_usage (indexer, day, searches, grabs, failures, updated_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))
+		ON CONFLICT(indexer, day) DO UPDATE SET
+			searches = searches + excluded.searches,
+			grabs = grabs + excluded.grabs,
+			failures = failures + excluded.failures,
+			updated_at = datetime('now')
+	`
+
+	if _, err := r.db.Exec(query, indexer, day, searches, grabs, failures); err != nil {
+		return fmt.Errorf("failed to increment indexer usage: %w", err)
+	}
+	return nil
+}
+
+// ListIndexerUsage returns every indexer's counters for the given day
+func (r *IndexerUsageRepository) ListIndexerUsage(day string) ([]*IndexerUsage, error) {
+	rows, err := r.db.Query(`SELECT indexer, day, searches, grabs, failures FROM indexer_usage WHERE day = ? ORDER BY indexer`, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexer usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*IndexerUsage
+	for rows.Next() {
+		var u IndexerUsage
+		if err := rows.Scan(&u.Indexer, &u.Day, &u.Searches, &u.Grabs, &u.Failures); err != nil {
+			return nil, fmt.Errorf("failed to scan indexer usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
+
+// DeleteIndexerUsageBefore removes counters for days before the given day
+func (r *IndexerUsageRepository) DeleteIndexerUsageBefore(day string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM indexer_usage WHERE day < ?`, day)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete indexer usage: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Playback session history: one row per playback of a title by a client, collapsing
+-- the individual range requests and HLS sessions that made it up
+CREATE TABLE stream_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    profile_id TEXT NOT NULL DEFAULT '',
+    profile_name TEXT NOT NULL DEFAULT '',
+    client_id TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    client_ip TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    release_name TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '' CHECK(source IN ('', 'usenet', 'debrid', 'live')),
+    delivery TEXT NOT NULL DEFAULT '' CHECK(delivery IN ('', 'direct', 'remux', 'transcode')),
+    audio_plan TEXT NOT NULL DEFAULT '',
+    bytes_served INTEGER NOT NULL DEFAULT 0,
+    duration_seconds INTEGER NOT NULL DEFAULT 0,
+    errors TEXT DEFAULT NULL, -- JSON array of FFmpeg fatal errors
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_stream_sessions_started_at ON stream_sessions(started_at);
+CREATE INDEX idx_stream_sessions_profile_id ON stream_sessions(profile_id);
+CREATE INDEX idx_stream_sessions_client_id ON stream_sessions(client_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_stream_sessions_client_id;
+DROP INDEX IF EXISTS idx_stream_sessions_profile_id;
+DROP INDEX IF EXISTS idx_stream_sessions_started_at;
+DROP TABLE IF EXISTS stream_sessions;
+
+-- +goose StatementEnd
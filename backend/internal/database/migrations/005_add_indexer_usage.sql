@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Daily per-indexer API usage, used to honour indexer quotas across restarts
+CREATE TABLE indexer_usage (
+    indexer TEXT NOT NULL,
+    day TEXT NOT NULL, -- UTC date, YYYY-MM-DD
+    searches INTEGER NOT NULL DEFAULT 0,
+    grabs INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (indexer, day)
+);
+
+CREATE INDEX idx_indexer_usage_day ON indexer_usage(day);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_indexer_usage_day;
+DROP TABLE IF EXISTS indexer_usage;
+
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Last known cache state of each torrent per debrid provider, so searches and health
+-- checks can skip the add/check/delete round trip for hashes checked recently
+CREATE TABLE debrid_cache_index (
+    info_hash TEXT NOT NULL, -- lowercase hex
+    provider TEXT NOT NULL,
+    cached INTEGER NOT NULL DEFAULT 0,
+    files TEXT DEFAULT NULL, -- JSON array of {path, bytes}
+    checked_at DATETIME NOT NULL,
+    PRIMARY KEY (info_hash, provider)
+);
+
+CREATE INDEX idx_debrid_cache_index_checked_at ON debrid_cache_index(checked_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_debrid_cache_index_checked_at;
+DROP TABLE IF EXISTS debrid_cache_index;
+
+-- +goose StatementEnd
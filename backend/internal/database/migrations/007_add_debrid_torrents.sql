@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Torrents strmr added to debrid accounts, so housekeeping only ever removes its own
+CREATE TABLE debrid_torrents (
+    provider TEXT NOT NULL,
+    torrent_id TEXT NOT NULL,
+    info_hash TEXT NOT NULL DEFAULT '',
+    added_at DATETIME NOT NULL,
+    last_played_at DATETIME DEFAULT NULL,
+    PRIMARY KEY (provider, torrent_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS debrid_torrents;
+
+-- +goose StatementEnd
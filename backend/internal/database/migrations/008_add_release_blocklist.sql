@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Releases that failed playback, per title, so searches stop picking them again
+CREATE TABLE release_blocklist (
+    title_key TEXT NOT NULL,
+    release_key TEXT NOT NULL,
+    title_name TEXT NOT NULL DEFAULT '',
+    release_title TEXT NOT NULL DEFAULT '',
+    service_type TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    failures INTEGER NOT NULL DEFAULT 1,
+    first_failed_at DATETIME NOT NULL,
+    last_failed_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (title_key, release_key)
+);
+
+CREATE INDEX idx_release_blocklist_expires_at ON release_blocklist(expires_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_release_blocklist_expires_at;
+DROP TABLE IF EXISTS release_blocklist;
+
+-- +goose StatementEnd
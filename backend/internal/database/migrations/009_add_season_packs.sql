@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Season and complete packs resolved for a series, so later episodes are played from the
+-- torrent or usenet directory already there instead of searching again
+CREATE TABLE season_packs (
+    title_id TEXT NOT NULL,
+    season_number INTEGER NOT NULL,
+    service_type TEXT NOT NULL,
+    provider TEXT NOT NULL DEFAULT '',
+    pack_id TEXT NOT NULL,
+    info_hash TEXT NOT NULL DEFAULT '',
+    release_title TEXT NOT NULL DEFAULT '',
+    pack TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME NOT NULL,
+    PRIMARY KEY (title_id, season_number)
+);
+
+CREATE INDEX idx_season_packs_last_used_at ON season_packs(last_used_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_season_packs_last_used_at;
+DROP TABLE IF EXISTS season_packs;
+
+-- +goose StatementEnd
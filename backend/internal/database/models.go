@@ -115,3 +115,111 @@ type MediaFile struct {
 	CreatedAt    time.Time `db:"created_at"`    // When record was created
 	UpdatedAt    time.Time `db:"updated_at"`    // When record was last updated
 }
+
+// StreamSession records one playback of a title by a client
+type StreamSession struct {
+	ID              int64     `db:"id" json:"id"`
+	ProfileID       string    `db:"profile_id" json:"profileId"`
+	ProfileName     string    `db:"profile_name" json:"profileName"`
+	ClientID        string    `db:"client_id" json:"clientId"`
+	UserAgent       string    `db:"user_agent" json:"userAgent"`
+	ClientIP        string    `db:"client_ip" json:"clientIp"`
+	Title           string    `db:"title" json:"title"`
+	ReleaseName     string    `db:"release_name" json:"releaseName"`
+	Path            string    `db:"path" json:"path"`
+	Source          string    `db:"source" json:"source"`        // usenet, debrid or live
+	Delivery        string    `db:"delivery" json:"delivery"`    // direct, remux or transcode
+	AudioPlan       string    `db:"audio_plan" json:"audioPlan"` // e.g. "copy", "truehd -> aac"
+	BytesServed     int64     `db:"bytes_served" json:"bytesServed"`
+	DurationSeconds int64     `db:"duration_seconds" json:"durationSeconds"`
+	Errors          []string  `db:"errors" json:"errors"` // FFmpeg fatal errors, stored as JSON
+	StartedAt       time.Time `db:"started_at" json:"startedAt"`
+	EndedAt         time.Time `db:"ended_at" json:"endedAt"`
+}
+
+// StreamSessionFilter narrows stream session history queries. Zero values match everything.
+type StreamSessionFilter struct {
+	ProfileID  string
+	ClientID   string
+	Source     string
+	Delivery   string
+	Search     string // Matched against title and release name
+	ErrorsOnly bool
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// StreamSessionStats aggregates stream sessions for one profile or client
+type StreamSessionStats struct {
+	Key             string    `json:"key"`
+	Name            string    `json:"name"`
+	Sessions        int       `json:"sessions"`
+	FailedSessions  int       `json:"failedSessions"`
+	BytesServed     int64     `json:"bytesServed"`
+	DurationSeconds int64     `json:"durationSeconds"`
+	LastSeenAt      time.Time `json:"lastSeenAt"`
+}
+
+// IndexerUsage counts one indexer's API hits on one UTC day
+type IndexerUsage struct {
+	Indexer  string `db:"indexer" json:"indexer"`
+	Day      string `db:"day" json:"day"` // YYYY-MM-DD
+	Searches int    `db:"searches" json:"searches"`
+	Grabs    int    `db:"grabs" json:"grabs"`
+	Failures int    `db:"failures" json:"failures"`
+}
+
+// DebridCacheEntry records whether a debrid provider had a torrent cached when it was last checked
+type DebridCacheEntry struct {
+	InfoHash  string            `db:"info_hash" json:"infoHash"`
+	Provider  string            `db:"provider" json:"provider"`
+	Cached    bool              `db:"cached" json:"cached"`
+	Files     []DebridCacheFile `db:"files" json:"files,omitempty"` // Stored as JSON
+	CheckedAt time.Time         `db:"checked_at" json:"checkedAt"`
+}
+
+// DebridCacheFile is one file of a torrent as listed by the provider
+type DebridCacheFile struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// DebridTorrent is a torrent strmr added to a debrid account
+type DebridTorrent struct {
+	Provider     string     `db:"provider" json:"provider"`
+	TorrentID    string     `db:"torrent_id" json:"torrentId"`
+	InfoHash     string     `db:"info_hash" json:"infoHash"`
+	AddedAt      time.Time  `db:"added_at" json:"addedAt"`
+	LastPlayedAt *time.Time `db:"last_played_at" json:"lastPlayedAt,omitempty"`
+}
+
+// BlockedRelease is a release that failed playback for a title
+type BlockedRelease struct {
+	TitleKey      string    `db:"title_key" json:"titleKey"`
+	ReleaseKey    string    `db:"release_key" json:"releaseKey"` // "hash:<infohash>", "guid:<guid>" or "url:<download url>"
+	TitleName     string    `db:"title_name" json:"titleName"`
+	ReleaseTitle  string    `db:"release_title" json:"releaseTitle"`
+	ServiceType   string    `db:"service_type" json:"serviceType"`
+	Source        string    `db:"source" json:"source"` // What failed: usenet_health, resolve, dv_profile, transcode
+	Reason        string    `db:"reason" json:"reason"`
+	Failures      int       `db:"failures" json:"failures"`
+	FirstFailedAt time.Time `db:"first_failed_at" json:"firstFailedAt"`
+	LastFailedAt  time.Time `db:"last_failed_at" json:"lastFailedAt"`
+	ExpiresAt     time.Time `db:"expires_at" json:"expiresAt"`
+}
+
+// SeasonPackEntry is a season or complete pack resolved for one season of a series
+type SeasonPackEntry struct {
+	TitleID      string    `db:"title_id" json:"titleId"`
+	SeasonNumber int       `db:"season_number" json:"seasonNumber"`
+	ServiceType  string    `db:"service_type" json:"serviceType"`
+	Provider     string    `db:"provider" json:"provider"`
+	PackID       string    `db:"pack_id" json:"packId"` // Debrid torrent ID or usenet directory
+	InfoHash     string    `db:"info_hash" json:"infoHash"`
+	ReleaseTitle string    `db:"release_title" json:"releaseTitle"`
+	Pack         string    `db:"pack" json:"pack"` // Release and file map, stored as JSON
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+	LastUsedAt   time.Time `db:"last_used_at" json:"lastUsedAt"`
+}
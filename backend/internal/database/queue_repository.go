@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -237,6 +238,27 @@ func (r *QueueRepository) TryClaimQueueItem(id int64) (bool, error) {
 	return rowsAffected > 0, nil
 }
 
+// CountQueueItemsByStatus returns the number of queue items in each status
+func (r *QueueRepository) CountQueueItemsByStatus() (map[QueueStatus]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM import_queue GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queue items by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[QueueStatus]int)
+	for rows.Next() {
+		var status QueueStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan queue status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // GetQueueStats returns current queue statistics
 func (r *QueueRepository) GetQueueStats() (*QueueStats, error) {
 	// Count items by status
@@ -355,6 +377,66 @@ func (r *QueueRepository) GetQueueItem(id int64) (*ImportQueueItem, error) {
 	return &item, nil
 }
 
+// ListCategorizedQueueItems returns items queued with a category, newest first. These
+// are the jobs handed over through the SABnzbd-compatible API.
+func (r *QueueRepository) ListCategorizedQueueItems(statuses []QueueStatus, limit int) ([]*ImportQueueItem, error) {
+	query := `
+		SELECT id, nzb_path, relative_path, category, priority, status, created_at, updated_at,
+		       started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path
+		FROM import_queue WHERE category IS NOT NULL`
+
+	args := make([]interface{}, 0, len(statuses)+1)
+	if len(statuses) > 0 {
+		placeholders := make([]string, len(statuses))
+		for i, status := range statuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		query += " AND status IN (" + strings.Join(placeholders, ",") + ")"
+	}
+	query += " ORDER BY created_at DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categorized queue items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*ImportQueueItem
+	for rows.Next() {
+		var item ImportQueueItem
+		if err := rows.Scan(
+			&item.ID, &item.NzbPath, &item.RelativePath, &item.Category, &item.Priority, &item.Status,
+			&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt,
+			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan queue item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// RemoveQueueItem deletes an item unless a worker is currently processing it.
+// It returns false when nothing was removed.
+func (r *QueueRepository) RemoveQueueItem(id int64) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM import_queue WHERE id = ? AND status != 'processing'`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove queue item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // withQueueTransaction executes a function within a queue database transaction
 func (r *QueueRepository) withQueueTransaction(fn func(*QueueRepository) error) error {
 	// Cast to *sql.DB to access Begin method
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ReleaseBlocklistRepository handles releases that failed playback
+type ReleaseBlocklistRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewReleaseBlocklistRepository creates a new release blocklist repository
+func NewReleaseBlocklistRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *ReleaseBlocklistRepository {
+	return &ReleaseBlocklistRepository{db: db}
+}
+
+// RecordBlockedRelease stores a failed release. A repeated failure of the same release for
+// the same title bumps its failure count, reason and expiry.
+func (r *ReleaseBlocklistRepository) RecordBlockedRelease(release *BlockedRelease) error {
+	query := `
+		INSERT INTO release_blocklist (title_key, release_key, title_name, release_title, service_type, source, reason,
+			failures, first_failed_at, last_failed_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT(title_key, release_key) DO UPDATE SET
+			title_name = CASE WHEN excluded.title_name != '' THEN excluded.title_name ELSE title_name END,
+			release_title = CASE WHEN excluded.release_title != '' THEN excluded.release_title ELSE release_title END,
+			service_type = excluded.service_type,
+			source = excluded.source,
+			reason = excluded.reason,
+			failures = CASE WHEN expires_at > excluded.last_failed_at THEN failures + 1 ELSE 1 END,
+			first_failed_at = CASE WHEN expires_at > excluded.last_failed_at THEN first_failed_at ELSE excluded.first_failed_at END,
+			last_failed_at = excluded.last_failed_at,
+			expires_at = excluded.expires_at
+	`
+
+	failedAt := release.LastFailedAt.UTC()
+	if _, err := r.db.Exec(query, release.TitleKey, release.ReleaseKey, release.TitleName, release.ReleaseTitle,
+		release.ServiceType, release.Source, release.Reason, failedAt, failedAt, release.ExpiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to record blocked release: %w", err)
+	}
+	return nil
+}
+
+// ListBlockedReleases returns the entries that have not expired by now, most recent failure
+// first. An empty titleKey lists every title.
+func (r *ReleaseBlocklistRepository) ListBlockedReleases(titleKey string, now time.Time) ([]*BlockedRelease, error) {
+	query := `
+		SELECT title_key, release_key, title_name, release_title, service_type, source, reason,
+			failures, first_failed_at, last_failed_at, expires_at
+		FROM release_blocklist
+		WHERE expires_at > ? AND (? = '' OR title_key = ?)
+		ORDER BY last_failed_at DESC
+	`
+
+	rows, err := r.db.Query(query, now.UTC(), titleKey, titleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked releases: %w", err)
+	}
+	defer rows.Close()
+
+	var releases []*BlockedRelease
+	for rows.Next() {
+		var b BlockedRelease
+		if err := rows.Scan(&b.TitleKey, &b.ReleaseKey, &b.TitleName, &b.ReleaseTitle, &b.ServiceType, &b.Source, &b.Reason,
+			&b.Failures, &b.FirstFailedAt, &b.LastFailedAt, &b.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan blocked release: %w", err)
+		}
+		releases = append(releases, &b)
+	}
+
+	return releases, rows.Err()
+}
+
+// DeleteBlockedReleases removes entries and returns how many were removed. An empty
+// releaseKey removes every entry of the title; empty keys for both clear the blocklist.
+func (r *ReleaseBlocklistRepository) DeleteBlockedReleases(titleKey, releaseKey string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM release_blocklist WHERE (? = '' OR title_key = ?) AND (? = '' OR release_key = ?)`,
+		titleKey, titleKey, releaseKey, releaseKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete blocked releases: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpiredBlockedReleases removes entries that expired before now
+func (r *ReleaseBlocklistRepository) DeleteExpiredBlockedReleases(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM release_blocklist WHERE expires_at <= ?`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired blocked releases: %w", err)
+	}
+	return result.RowsAffected()
+}
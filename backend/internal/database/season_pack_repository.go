@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SeasonPackRepository handles the season packs resolved per series
+type SeasonPackRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewSeasonPackRepository creates a new season pack repository
+func NewSeasonPackRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *SeasonPackRepository {
+	return &SeasonPackRepository{db: db}
+}
+
+// UpsertSeasonPack stores the pack for a season of a series, replacing the previous one.
+func (r *SeasonPackRepository) UpsertSeasonPack(entry *SeasonPackEntry) error {
+	query := `
+		INSERT INTO season_packs (title_id, season_number, service_type, provider, pack_id, info_hash, release_title,
+			pack, created_at, last_used_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(title_id, season_number) DO UPDATE SET
+			service_type = excluded.service_type,
+			provider = excluded.provider,
+			pack_id = excluded.pack_id,
+			info_hash = excluded.info_hash,
+			release_title = excluded.release_title,
+			pack = excluded.pack,
+			created_at = CASE WHEN pack_id = excluded.pack_id THEN created_at ELSE excluded.created_at END,
+			last_used_at = excluded.last_used_at
+	`
+
+	if _, err := r.db.Exec(query, entry.TitleID, entry.SeasonNumber, entry.ServiceType, entry.Provider, entry.PackID,
+		entry.InfoHash, entry.ReleaseTitle, entry.Pack, entry.CreatedAt.UTC(), entry.LastUsedAt.UTC()); err != nil {
+		return fmt.Errorf("failed to upsert season pack: %w", err)
+	}
+	return nil
+}
+
+// GetSeasonPack returns the pack for a season of a series, or nil if there is none
+func (r *SeasonPackRepository) GetSeasonPack(titleID string, season int) (*SeasonPackEntry, error) {
+	query := `
+		SELECT title_id, season_number, service_type, provider, pack_id, info_hash, release_title, pack,
+			created_at, last_used_at
+		FROM season_packs
+		WHERE title_id = ? AND season_number = ?
+	`
+
+	var e SeasonPackEntry
+	err := r.db.QueryRow(query, titleID, season).Scan(&e.TitleID, &e.SeasonNumber, &e.ServiceType, &e.Provider,
+		&e.PackID, &e.InfoHash, &e.ReleaseTitle, &e.Pack, &e.CreatedAt, &e.LastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season pack: %w", err)
+	}
+	return &e, nil
+}
+
+// TouchSeasonPack updates the last time a pack was played from
+func (r *SeasonPackRepository) TouchSeasonPack(titleID string, season int, usedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE season_packs SET last_used_at = ? WHERE title_id = ? AND season_number = ?`,
+		usedAt.UTC(), titleID, season); err != nil {
+		return fmt.Errorf("failed to touch season pack: %w", err)
+	}
+	return nil
+}
+
+// DeleteSeasonPacks removes every season entry of a pack that can no longer be played from
+func (r *SeasonPackRepository) DeleteSeasonPacks(serviceType, provider, packID string) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM season_packs WHERE service_type = ? AND provider = ? AND pack_id = ?`,
+		serviceType, provider, packID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete season packs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteSeasonPacksUnusedSince removes packs that were not played from since the cutoff
+func (r *SeasonPackRepository) DeleteSeasonPacksUnusedSince(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM season_packs WHERE last_used_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete unused season packs: %w", err)
+	}
+	return result.RowsAffected()
+}
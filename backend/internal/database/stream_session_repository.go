@@ -0,0 +1,225 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StreamSessionRepository handles playback session history
+type StreamSessionRepository struct {
+	db interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}
+}
+
+// NewStreamSessionRepository creates a new stream session repository
+func NewStreamSessionRepository(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) *StreamSessionRepository {
+	return &StreamSessionRepository{db: db}
+}
+
+// AddStreamSession inserts a finished playback session
+func (r *StreamSessionRepository) AddStreamSession(session *StreamSession) error {
+	var errorsJSON *string
+	if len(session.Errors) > 0 {
+		data, err := json.Marshal(session.Errors)
+		if err != nil {
+			return fmt.Errorf("failed to encode stream session errors: %w", err)
+		}
+		encoded := string(data)
+		errorsJSON = &encoded
+	}
+
+	query := `
+		INSERT INTO stream_sessions (profile_id, profile_name, client_id, user_agent, client_ip, title, release_name, path,
+		                             source, delivery, audio_plan, bytes_served, duration_seconds, errors, started_at, ended_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		session.ProfileID, session.ProfileName, session.ClientID, session.UserAgent, session.ClientIP,
+		session.Title, session.ReleaseName, session.Path, session.Source, session.Delivery, session.AudioPlan,
+		session.BytesServed, session.DurationSeconds, errorsJSON, session.StartedAt.UTC(), session.EndedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add stream session: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get stream session id: %w", err)
+	}
+	session.ID = id
+	return nil
+}
+
+// ListStreamSessions returns sessions matching the filter, newest first, along with the
+// total number of matches
+func (r *StreamSessionRepository) ListStreamSessions(filter StreamSessionFilter) ([]*StreamSession, int, error) {
+	where, args := streamSessionConditions(filter)
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM stream_sessions"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count stream sessions: %w", err)
+	}
+
+	query := `SELECT id, profile_id, profile_name, client_id, user_agent, client_ip, title, release_name, path,
+	                 source, delivery, audio_plan, bytes_served, duration_seconds, errors, started_at, ended_at
+	          FROM stream_sessions` + where + " ORDER BY started_at DESC"
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, limit, filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list stream sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*StreamSession{}
+	for rows.Next() {
+		var session StreamSession
+		var errorsJSON sql.NullString
+		if err := rows.Scan(
+			&session.ID, &session.ProfileID, &session.ProfileName, &session.ClientID, &session.UserAgent, &session.ClientIP,
+			&session.Title, &session.ReleaseName, &session.Path, &session.Source, &session.Delivery, &session.AudioPlan,
+			&session.BytesServed, &session.DurationSeconds, &errorsJSON, &session.StartedAt, &session.EndedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan stream session: %w", err)
+		}
+		if errorsJSON.Valid && errorsJSON.String != "" {
+			if err := json.Unmarshal([]byte(errorsJSON.String), &session.Errors); err != nil {
+				session.Errors = []string{errorsJSON.String}
+			}
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, total, rows.Err()
+}
+
+// GetStreamSessionStats aggregates sessions matching the filter per profile ("profile")
+// or per client ("client"), busiest first. Limit and Offset are ignored.
+func (r *StreamSessionRepository) GetStreamSessionStats(groupBy string, filter StreamSessionFilter) ([]*StreamSessionStats, error) {
+	var key, name string
+	switch groupBy {
+	case "profile":
+		key, name = "profile_id", "MAX(profile_name)"
+	case "client":
+		// Fall back to the client IP for players that don't identify themselves
+		key, name = "CASE WHEN client_id != '' THEN client_id ELSE client_ip END", "MAX(user_agent)"
+	default:
+		return nil, fmt.Errorf("unsupported stream session grouping %q", groupBy)
+	}
+
+	where, args := streamSessionConditions(filter)
+	query := fmt.Sprintf(`
+		SELECT %s AS grouping_key, %s, COUNT(*),
+		       SUM(CASE WHEN errors IS NOT NULL THEN 1 ELSE 0 END),
+		       SUM(bytes_served), SUM(duration_seconds), MAX(started_at)
+		FROM stream_sessions%s
+		GROUP BY grouping_key
+		ORDER BY COUNT(*) DESC`, key, name, where)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate stream sessions: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []*StreamSessionStats{}
+	for rows.Next() {
+		var stat StreamSessionStats
+		var lastSeen string
+		if err := rows.Scan(&stat.Key, &stat.Name, &stat.Sessions, &stat.FailedSessions,
+			&stat.BytesServed, &stat.DurationSeconds, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan stream session stats: %w", err)
+		}
+		stat.LastSeenAt = parseSQLiteTime(lastSeen)
+		stats = append(stats, &stat)
+	}
+
+	return stats, rows.Err()
+}
+
+// DeleteStreamSessionsBefore removes sessions that started before the cutoff. A zero
+// cutoff removes everything.
+func (r *StreamSessionRepository) DeleteStreamSessionsBefore(cutoff time.Time) (int64, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+	if cutoff.IsZero() {
+		result, err = r.db.Exec(`DELETE FROM stream_sessions`)
+	} else {
+		result, err = r.db.Exec(`DELETE FROM stream_sessions WHERE started_at < ?`, cutoff.UTC())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stream sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func streamSessionConditions(filter StreamSessionFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ProfileID != "" {
+		conditions = append(conditions, "profile_id = ?")
+		args = append(args, filter.ProfileID)
+	}
+	if filter.ClientID != "" {
+		conditions = append(conditions, "(client_id = ? OR (client_id = '' AND client_ip = ?))")
+		args = append(args, filter.ClientID, filter.ClientID)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if filter.Delivery != "" {
+		conditions = append(conditions, "delivery = ?")
+		args = append(args, filter.Delivery)
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "(title LIKE ? OR release_name LIKE ?)")
+		pattern := "%" + filter.Search + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.ErrorsOnly {
+		conditions = append(conditions, "errors IS NOT NULL")
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "started_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "started_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// parseSQLiteTime parses timestamps returned by aggregate functions, which the driver
+// hands back as text rather than time.Time
+func parseSQLiteTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
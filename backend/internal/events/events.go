@@ -0,0 +1,95 @@
+// Package events is a small process-wide publish/subscribe bus for server
+// events that are interesting outside the process (playback, import failures,
+// corrupted files, ...). Publishers live anywhere in the tree; the
+// notifications service subscribes and turns events into webhook deliveries.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies a kind of event. Values are stable and used in webhook
+// configuration to select which events a webhook receives.
+type Type string
+
+const (
+	PlaybackStarted       Type = "playback.started"
+	PlaybackStopped       Type = "playback.stopped"
+	ImportFailed          Type = "import.failed"
+	FileCorrupted         Type = "file.corrupted"
+	DebridAccountExpiring Type = "debrid.expiring"
+	TaskFailed            Type = "task.failed"
+	ProfileCreated        Type = "profile.created"
+	LoginFailed           Type = "auth.login_failed"
+	Test                  Type = "test"
+)
+
+// AllTypes lists every event type that can be subscribed to.
+var AllTypes = []Type{
+	PlaybackStarted,
+	PlaybackStopped,
+	ImportFailed,
+	FileCorrupted,
+	DebridAccountExpiring,
+	TaskFailed,
+	ProfileCreated,
+	LoginFailed,
+}
+
+// Event is a single server event.
+type Event struct {
+	Type    Type              `json:"type"`
+	Title   string            `json:"title"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Time    time.Time         `json:"time"`
+
+	// Key identifies the subject of the event (a file path, a task ID, ...).
+	// Subscribers use it to suppress repeats of the same event.
+	Key string `json:"-"`
+}
+
+// Handler receives published events. Handlers run synchronously on the
+// publisher's goroutine and must not block.
+type Handler func(Event)
+
+var (
+	mu       sync.RWMutex
+	nextID   int
+	handlers = map[int]Handler{}
+)
+
+// Subscribe registers h for all future events and returns a function that
+// removes it again.
+func Subscribe(h Handler) (unsubscribe func()) {
+	mu.Lock()
+	id := nextID
+	nextID++
+	handlers[id] = h
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		delete(handlers, id)
+		mu.Unlock()
+	}
+}
+
+// Publish delivers e to every subscriber. Time defaults to now.
+func Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	mu.RLock()
+	subscribers := make([]Handler, 0, len(handlers))
+	for _, h := range handlers {
+		subscribers = append(subscribers, h)
+	}
+	mu.RUnlock()
+
+	for _, h := range subscribers {
+		h(e)
+	}
+}
@@ -45,6 +45,9 @@ type ParsedNzb struct {
 	Files         []ParsedFile
 	SegmentsCount int
 	SegmentSize   int64
+
+	// Par2Files holds the PAR2 index and recovery volumes, kept aside for repair
+	Par2Files []ParsedFile
 }
 
 // ParsedFile represents a file extracted from the NZB
@@ -130,7 +133,9 @@ func (p *Parser) ParseFileWithContext(ctx context.Context, r io.Reader, nzbPath
 	for _, file := range n.Files {
 		if !par2Pattern.MatchString(file.Filename) {
 			validFiles = append(validFiles, file)
+			continue
 		}
+		parsed.Par2Files = append(parsed.Par2Files, p.parsePar2File(file))
 	}
 
 	if len(validFiles) == 0 {
@@ -222,6 +227,33 @@ func (p *Parser) ParseFileWithContext(ctx context.Context, r io.Reader, nzbPath
 	return parsed, nil
 }
 
+// parsePar2File converts a PAR2 entry without any network access. PAR2 volumes are
+// always downloaded whole, so the NZB segment sizes are only informational.
+func (p *Parser) parsePar2File(file nzbparser.NzbFile) ParsedFile {
+	// NZBs don't always list segments in order
+	sort.Sort(file.Segments)
+
+	segments := make([]*metapb.SegmentData, len(file.Segments))
+	var size int64
+	for i, seg := range file.Segments {
+		segments[i] = &metapb.SegmentData{
+			Id:          seg.ID,
+			StartOffset: 0,
+			EndOffset:   int64(seg.Bytes - 1),
+			SegmentSize: int64(seg.Bytes),
+		}
+		size += int64(seg.Bytes)
+	}
+
+	return ParsedFile{
+		Subject:  file.Subject,
+		Filename: file.Filename,
+		Size:     size,
+		Segments: segments,
+		Groups:   file.Groups,
+	}
+}
+
 // parseFile processes a single file entry from the NZB (legacy, no context)
 func (p *Parser) parseFile(file nzbparser.NzbFile, meta map[string]string, allFiles []nzbparser.NzbFile, nzbFilename string) (*ParsedFile, error) {
 	return p.parseFileWithContext(context.Background(), file, meta, allFiles, nzbFilename)
@@ -24,7 +24,8 @@ type Processor struct {
 	metadataService   *metadata.MetadataService
 	rarProcessor      RarProcessor
 	sevenZipProcessor SevenZipProcessor
-	poolManager       pool.Manager // Pool manager for dynamic pool access
+	poolManager       pool.Manager        // Pool manager for dynamic pool access
+	par2Store         *metadata.Par2Store // Optional store for PAR2 repair manifests
 	configGetter      config.ConfigGetter
 	log               *slog.Logger
 	rarMaxWorkers     int
@@ -188,6 +189,9 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 	default:
 	}
 
+	// Keep the PAR2 volumes around so damaged articles can be repaired later
+	proc.recordPar2Manifest(parsed)
+
 	// Calculate the relative virtual directory path for this file
 	virtualDir := proc.calculateVirtualDirectory(filePath, relativePath)
 
@@ -612,14 +616,15 @@ func (proc *Processor) analyzeDirectoryStructureWithBase(files []ParsedFile, bas
 
 // calculateVirtualDirectory determines the virtual directory path based on NZB file location relative to watch root
 func (proc *Processor) calculateVirtualDirectory(nzbPath, relativePath string) string {
-	// Check if this is a queue item (temp directory NZB)
-	if strings.Contains(nzbPath, "/novastream-nzbs/") || strings.Contains(nzbPath, "\\novastream-nzbs\\") {
-		// For queue items, use root directory and let the playback service use storage_path
+	if relativePath == "" {
+		// No watch root specified, place in root directory
 		return "/"
 	}
 
-	if relativePath == "" {
-		// No watch root specified, place in root directory
+	// Check if this is a queue item (temp directory NZB). Categorized queue items carry
+	// their job directory as the relative path and resolve like a watch folder below.
+	if isQueuedNZBPath(nzbPath) && !isQueuedNZBPath(relativePath) {
+		// For queue items, use root directory and let the playback service use storage_path
 		return "/"
 	}
 
@@ -648,6 +653,11 @@ func (proc *Processor) calculateVirtualDirectory(nzbPath, relativePath string) s
 	return filepath.Clean(virtualPath)
 }
 
+// isQueuedNZBPath reports whether the path lies in the temp directory for uploaded NZBs
+func isQueuedNZBPath(path string) bool {
+	return strings.Contains(path, "/novastream-nzbs/") || strings.Contains(path, "\\novastream-nzbs\\")
+}
+
 // ensureDirectoryExists creates directory structure in the metadata filesystem
 func (proc *Processor) ensureDirectoryExists(virtualDir string) error {
 	if virtualDir == "/" {
@@ -696,6 +706,47 @@ func (proc *Processor) separatePar2Files(files []ParsedFile) ([]ParsedFile, []Pa
 	return regularFiles, par2Files
 }
 
+// recordPar2Manifest stores the PAR2 volumes and data file articles of an NZB for later repair
+func (proc *Processor) recordPar2Manifest(parsed *ParsedNzb) {
+	if proc.par2Store == nil || len(parsed.Par2Files) == 0 {
+		return
+	}
+
+	toManifestFile := func(file ParsedFile) metadata.Par2ManifestFile {
+		mf := metadata.Par2ManifestFile{
+			Name:     file.Filename,
+			Size:     file.Size,
+			Groups:   file.Groups,
+			Segments: make([]metadata.Par2ManifestSegment, 0, len(file.Segments)),
+		}
+		for _, seg := range file.Segments {
+			mf.Segments = append(mf.Segments, metadata.Par2ManifestSegment{
+				ID:   seg.Id,
+				Size: seg.EndOffset - seg.StartOffset + 1,
+			})
+		}
+		return mf
+	}
+
+	manifest := &metadata.Par2Manifest{}
+	for _, file := range parsed.Par2Files {
+		manifest.Par2Files = append(manifest.Par2Files, toManifestFile(file))
+	}
+	for _, file := range parsed.Files {
+		manifest.DataFiles = append(manifest.DataFiles, toManifestFile(file))
+	}
+
+	if err := proc.par2Store.WriteManifest(parsed.Path, manifest); err != nil {
+		proc.log.Warn("Failed to record PAR2 manifest", "nzb", parsed.Path, "error", err)
+		return
+	}
+
+	proc.log.Debug("Recorded PAR2 manifest",
+		"nzb", parsed.Path,
+		"par2_files", len(manifest.Par2Files),
+		"data_files", len(manifest.DataFiles))
+}
+
 // separateRarFiles separates RAR files from regular files
 func (proc *Processor) separateRarFiles(files []ParsedFile) ([]ParsedFile, []ParsedFile) {
 	var regularFiles []ParsedFile
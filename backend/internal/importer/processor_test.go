@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessor_CalculateVirtualDirectory(t *testing.T) {
+	proc := &Processor{}
+
+	tests := []struct {
+		name         string
+		nzbPath      string
+		relativePath string
+		expected     string
+	}{
+		{
+			name:     "no watch root",
+			nzbPath:  "/watch/movies/movie.nzb",
+			expected: "/",
+		},
+		{
+			name:         "nested in watch root",
+			nzbPath:      "/watch/movies/action/movie.nzb",
+			relativePath: "/watch",
+			expected:     "/movies/action",
+		},
+		{
+			name:         "uncategorized queue upload",
+			nzbPath:      "/tmp/novastream-nzbs/1700000000_movie.nzb",
+			relativePath: "/watch",
+			expected:     "/",
+		},
+		{
+			name:         "categorized queue upload",
+			nzbPath:      "/tmp/novastream-nzbs/1700000000/tv/show.nzb",
+			relativePath: "/tmp/novastream-nzbs/1700000000",
+			expected:     "/tv",
+		},
+		{
+			name:         "default category queue upload",
+			nzbPath:      "/tmp/novastream-nzbs/1700000000/show.nzb",
+			relativePath: "/tmp/novastream-nzbs/1700000000",
+			expected:     "/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, proc.calculateVirtualDirectory(tt.nzbPath, tt.relativePath))
+		})
+	}
+}
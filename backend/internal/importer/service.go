@@ -19,6 +19,7 @@ import (
 
 	"novastream/config"
 	"novastream/internal/database"
+	"novastream/internal/events"
 	"novastream/internal/nzb/metadata"
 	"novastream/internal/pool"
 	"novastream/internal/sabnzbd"
@@ -28,7 +29,8 @@ import (
 
 // ServiceConfig holds configuration for the NZB import service
 type ServiceConfig struct {
-	Workers int // Number of parallel queue workers (default: 4)
+	Workers   int                 // Number of parallel queue workers (default: 4)
+	Par2Store *metadata.Par2Store // Where PAR2 repair manifests are kept (nil disables)
 }
 
 // ScanStatus represents the current status of a manual scan
@@ -86,6 +88,7 @@ func NewService(config ServiceConfig, metadataService *metadata.MetadataService,
 
 	// Create processor with poolManager for dynamic pool access
 	processor := NewProcessor(metadataService, poolManager, configGetter)
+	processor.par2Store = config.Par2Store
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -580,6 +583,25 @@ func (s *Service) handleProcessingFailure(item *database.ImportQueueItem, proces
 				"retry_count", item.RetryCount)
 		}
 
+		// Nothing was imported, so the PAR2 manifest recorded while parsing is of no use
+		if s.processor.par2Store != nil {
+			if err := s.processor.par2Store.Delete(item.NzbPath); err != nil {
+				log.Warn("Failed to delete PAR2 data", "queue_id", item.ID, "error", err)
+			}
+		}
+
+		fields := map[string]string{"file": filepath.Base(item.NzbPath), "error": errorMessage}
+		if item.Category != nil {
+			fields["category"] = *item.Category
+		}
+		events.Publish(events.Event{
+			Type:    events.ImportFailed,
+			Title:   "Import failed",
+			Message: fmt.Sprintf("%s could not be imported after %d attempt(s).", filepath.Base(item.NzbPath), item.RetryCount+1),
+			Fields:  fields,
+			Key:     item.NzbPath,
+		})
+
 		// Attempt SABnzbd fallback if configured
 		s.attemptSABnzbdFallback(item, log)
 	}
@@ -912,12 +934,13 @@ func (s *Service) convertPriorityToSABnzbd(priority database.QueuePriority) stri
 	}
 }
 
-// AddNZBToQueue adds an NZB file to the queue for processing
-func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes []byte) (*database.ImportQueueItem, error) {
+// AddNZBToQueue adds an NZB file to the queue for processing with optional category and
+// priority. Categorized NZBs are imported below a virtual directory named after the category.
+func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes []byte, category *string, priority *database.QueuePriority) (*database.ImportQueueItem, error) {
 	s.log.InfoContext(ctx, "Adding NZB to queue", "fileName", fileName, "size", len(nzbBytes))
 
 	// Create temp directory for NZBs if it doesn't exist
-	tempDir := filepath.Join(os.TempDir(), "novastream-nzbs")
+	tempDir := queuedNZBDir()
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
 		return nil, fmt.Errorf("create temp dir: %w", err)
 	}
@@ -927,6 +950,22 @@ func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes [
 	safeFileName := sanitizeFileName(fileName)
 	nzbPath := filepath.Join(tempDir, fmt.Sprintf("%d_%s", timestamp, safeFileName))
 
+	// Categorized jobs get their own directory so the category becomes the virtual
+	// directory and multi-file folders keep the plain NZB name
+	var relativePath *string
+	if category != nil {
+		jobDir := filepath.Join(tempDir, fmt.Sprintf("%d", timestamp))
+		nzbDir := jobDir
+		if *category != "" {
+			nzbDir = filepath.Join(jobDir, sanitizeFileName(*category))
+		}
+		if err := os.MkdirAll(nzbDir, 0755); err != nil {
+			return nil, fmt.Errorf("create job dir: %w", err)
+		}
+		nzbPath = filepath.Join(nzbDir, safeFileName)
+		relativePath = &jobDir
+	}
+
 	// Write NZB bytes to file
 	if err := os.WriteFile(nzbPath, nzbBytes, 0644); err != nil {
 		return nil, fmt.Errorf("write NZB file: %w", err)
@@ -951,32 +990,41 @@ func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes [
 	select {
 	case <-ctx.Done():
 		// Clean up temp file since we're not queueing
-		os.Remove(nzbPath)
+		removeQueuedNZB(nzbPath, relativePath)
 		return nil, ctx.Err()
 	default:
 	}
 
+	// Use default priority if not specified
+	itemPriority := database.QueuePriorityNormal
+	if priority != nil {
+		itemPriority = *priority
+	}
+
 	// Create queue item
 	queueItem := &database.ImportQueueItem{
-		NzbPath:    nzbPath,
-		Priority:   database.QueuePriorityNormal,
-		Status:     database.QueueStatusPending,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-		MaxRetries: 3,
-		FileSize:   &estimatedSize,
+		NzbPath:      nzbPath,
+		RelativePath: relativePath,
+		Category:     category,
+		Priority:     itemPriority,
+		Status:       database.QueueStatusPending,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		MaxRetries:   3,
+		FileSize:     &estimatedSize,
 	}
 
 	// Add to queue
 	if err := s.database.Repository.AddToQueue(queueItem); err != nil {
 		// Clean up temp file on error
-		os.Remove(nzbPath)
+		removeQueuedNZB(nzbPath, relativePath)
 		return nil, fmt.Errorf("add to queue: %w", err)
 	}
 
 	s.log.InfoContext(ctx, "NZB added to queue",
 		"id", queueItem.ID,
 		"fileName", fileName,
+		"category", category,
 		"estimatedSize", estimatedSize)
 
 	// Notify workers that new work is available (non-blocking)
@@ -989,6 +1037,45 @@ func (s *Service) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes [
 	return queueItem, nil
 }
 
+// ListCategorizedQueueItems returns queue items that were added with a category, newest first
+func (s *Service) ListCategorizedQueueItems(statuses []database.QueueStatus, limit int) ([]*database.ImportQueueItem, error) {
+	return s.database.Repository.ListCategorizedQueueItems(statuses, limit)
+}
+
+// RemoveQueueItem deletes a queue item that is not being processed. The uploaded NZB is
+// removed too unless the item completed, since imported files still reference it.
+func (s *Service) RemoveQueueItem(id int64) (bool, error) {
+	item, err := s.database.Repository.GetQueueItem(id)
+	if err != nil || item == nil {
+		return false, err
+	}
+
+	removed, err := s.database.Repository.RemoveQueueItem(id)
+	if err != nil || !removed {
+		return removed, err
+	}
+
+	if item.Status != database.QueueStatusCompleted && strings.HasPrefix(item.NzbPath, queuedNZBDir()+string(filepath.Separator)) {
+		removeQueuedNZB(item.NzbPath, item.RelativePath)
+	}
+	s.log.Info("Removed queue item", "queue_id", id, "file", item.NzbPath)
+	return true, nil
+}
+
+// queuedNZBDir is the temp directory uploaded NZBs are written to
+func queuedNZBDir() string {
+	return filepath.Join(os.TempDir(), "novastream-nzbs")
+}
+
+// removeQueuedNZB deletes an uploaded NZB along with its job directory, if it has one
+func removeQueuedNZB(nzbPath string, jobDir *string) {
+	if jobDir != nil && filepath.Dir(*jobDir) == queuedNZBDir() {
+		os.RemoveAll(*jobDir)
+		return
+	}
+	os.Remove(nzbPath)
+}
+
 // ProcessNZBImmediately processes an NZB file immediately without queuing
 // Returns the resulting storage path for the processed content
 func (s *Service) ProcessNZBImmediately(ctx context.Context, fileName string, nzbBytes []byte) (string, error) {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ type NzbConfig struct {
 	Salt                string // Global salt for .bin files
 	MaxProcessorWorkers int    // Number of queue workers (default: 2)
 	MaxDownloadWorkers  int    // Number of download workers (default: 15)
+	Par2CachePath       string // Path for PAR2 manifests and repaired articles (default: next to metadata root)
 }
 
 // NzbSystem represents the complete NZB-backed filesystem
@@ -39,6 +41,7 @@ type NzbSystem struct {
 	fs             afero.Fs
 	nzbFs          *nzbfilesystem.NzbFilesystem // Concrete type for context-aware operations
 	poolManager    pool.Manager
+	par2Repairer   *nzbfilesystem.Par2Repairer
 
 	// Configuration tracking for dynamic updates
 	maxDownloadWorkers  int
@@ -73,9 +76,17 @@ func NewNzbSystem(config NzbConfig, poolManager pool.Manager, configGetter confi
 		maxDownloadWorkers = 15 // Default: 15 download workers
 	}
 
+	// PAR2 data lives outside the metadata root so it never shows up in the virtual filesystem
+	par2CachePath := config.Par2CachePath
+	if par2CachePath == "" {
+		par2CachePath = filepath.Join(filepath.Dir(config.MetadataRootPath), "par2")
+	}
+	par2Store := metadata.NewPar2Store(par2CachePath)
+
 	// Create NZB service using metadata + queue
 	serviceConfig := importer.ServiceConfig{
-		Workers: maxProcessorWorkers,
+		Workers:   maxProcessorWorkers,
+		Par2Store: par2Store,
 	}
 
 	// Create service with poolManager for dynamic pool access
@@ -103,6 +114,10 @@ func NewNzbSystem(config NzbConfig, poolManager pool.Manager, configGetter confi
 		configGetter,
 	)
 
+	// Rebuild missing articles from PAR2 volumes instead of leaving files corrupted
+	par2Repairer := nzbfilesystem.NewPar2Repairer(par2Store, metadataService, healthRepo, poolManager, configGetter)
+	metadataRemoteFile.SetPar2Repairer(par2Repairer)
+
 	// Create filesystem backed by metadata
 	fs := nzbfilesystem.NewNzbFilesystem(metadataRemoteFile)
 
@@ -112,6 +127,9 @@ func NewNzbSystem(config NzbConfig, poolManager pool.Manager, configGetter confi
 		return nil, fmt.Errorf("failed to convert filesystem to NzbFilesystem type")
 	}
 
+	// Drop PAR2 data of releases removed while the server was down, before imports write more
+	par2Repairer.Prune()
+
 	ctx := context.Background()
 
 	if err := service.Start(ctx); err != nil {
@@ -125,6 +143,7 @@ func NewNzbSystem(config NzbConfig, poolManager pool.Manager, configGetter confi
 		fs:                  fs,
 		nzbFs:               nzbFs,
 		poolManager:         poolManager,
+		par2Repairer:        par2Repairer,
 		maxDownloadWorkers:  maxDownloadWorkers,
 		maxProcessorWorkers: maxProcessorWorkers,
 	}, nil
@@ -135,6 +154,11 @@ func (ns *NzbSystem) GetQueueStats(ctx context.Context) (*database.QueueStats, e
 	return ns.service.GetQueueStats(ctx)
 }
 
+// QueueDepthByStatus returns the number of import queue items in each status
+func (ns *NzbSystem) QueueDepthByStatus() (map[database.QueueStatus]int, error) {
+	return ns.database.Repository.CountQueueItemsByStatus()
+}
+
 // GetServiceStats returns service statistics including queue stats
 func (ns *NzbSystem) GetServiceStats(ctx context.Context) (*importer.ServiceStats, error) {
 	return ns.service.GetStats(ctx)
@@ -172,6 +196,8 @@ func (ns *NzbSystem) StopService(ctx context.Context) error {
 
 // Close closes the NZB system and releases resources
 func (ns *NzbSystem) Close() error {
+	ns.par2Repairer.Close()
+
 	if err := ns.service.Close(); err != nil {
 		return err
 	}
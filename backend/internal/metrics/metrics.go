@@ -0,0 +1,73 @@
+// Package metrics holds the shared pieces of the Prometheus instrumentation.
+// Counters and histograms are defined with client_golang's promauto as
+// package-level vars next to the code they measure; values that are cheaper to
+// read on demand (session counts, queue depth, pool connections) are registered
+// here as collectors and sampled on every scrape.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets are latency buckets in seconds suited to remote API calls.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+func init() {
+	started := time.Now()
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "novastream_uptime_seconds",
+		Help: "Seconds since the server started.",
+	}, func() float64 {
+		return time.Since(started).Seconds()
+	}))
+}
+
+// Handler serves the default registry, which also carries the Go runtime and
+// process collectors, in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Sample is a single value reported by a collector.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// sampledCollector reports the samples returned by collect on every scrape.
+type sampledCollector struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	collect   func() []Sample
+}
+
+func (c *sampledCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+
+func (c *sampledCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.collect() {
+		ch <- prometheus.MustNewConstMetric(c.desc, c.valueType, s.Value, s.LabelValues...)
+	}
+}
+
+func newSampledCollector(name, help string, valueType prometheus.ValueType, labels []string, collect func() []Sample) *sampledCollector {
+	return &sampledCollector{
+		desc:      prometheus.NewDesc(name, help, labels, nil),
+		valueType: valueType,
+		collect:   collect,
+	}
+}
+
+// NewGaugeFunc registers a gauge whose samples are read on every scrape.
+func NewGaugeFunc(name, help string, labels []string, collect func() []Sample) {
+	prometheus.MustRegister(newSampledCollector(name, help, prometheus.GaugeValue, labels, collect))
+}
+
+// NewCounterFunc registers a counter whose samples are read on every scrape. The
+// collector must report values that never decrease.
+func NewCounterFunc(name, help string, labels []string, collect func() []Sample) {
+	prometheus.MustRegister(newSampledCollector(name, help, prometheus.CounterValue, labels, collect))
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSampledCollector(t *testing.T) {
+	queue := newSampledCollector("test_queue", "Queue.", prometheus.GaugeValue, []string{"status"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"pending"}, Value: 3}, {LabelValues: []string{`we"ird`}, Value: 1}}
+	})
+
+	want := `
+# HELP test_queue Queue.
+# TYPE test_queue gauge
+test_queue{status="pending"} 3
+test_queue{status="we\"ird"} 1
+`
+	if err := testutil.CollectAndCompare(queue, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSampledCounter(t *testing.T) {
+	lookups := newSampledCollector("test_lookups_total", "Lookups.", prometheus.CounterValue, nil, func() []Sample {
+		return []Sample{{Value: 7}}
+	})
+
+	want := `
+# HELP test_lookups_total Lookups.
+# TYPE test_lookups_total counter
+test_lookups_total 7
+`
+	if err := testutil.CollectAndCompare(lookups, strings.NewReader(want)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlerServesRuntimeMetrics(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{"go_goroutines ", "novastream_uptime_seconds "} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, rec.Body.String())
+		}
+	}
+}
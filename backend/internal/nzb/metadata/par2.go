@@ -0,0 +1,190 @@
+package metadata
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Par2Manifest records the PAR2 volumes and data files of an imported NZB so damaged
+// articles can be rebuilt after import
+type Par2Manifest struct {
+	Par2Files []Par2ManifestFile `json:"par2_files"`
+	DataFiles []Par2ManifestFile `json:"data_files"`
+}
+
+// Par2ManifestFile is one file of the NZB with its ordered article list
+type Par2ManifestFile struct {
+	Name     string                `json:"name"`
+	Size     int64                 `json:"size"`
+	Groups   []string              `json:"groups,omitempty"`
+	Segments []Par2ManifestSegment `json:"segments"`
+}
+
+// Par2ManifestSegment is a single article and its decoded size
+type Par2ManifestSegment struct {
+	ID   string `json:"id"`
+	Size int64  `json:"size"`
+}
+
+// Par2Store persists PAR2 manifests and repaired article bodies keyed by source NZB path.
+// It lives outside the metadata root so nothing shows up in the virtual filesystem.
+type Par2Store struct {
+	rootPath string
+}
+
+// NewPar2Store creates a PAR2 store rooted at rootPath
+func NewPar2Store(rootPath string) *Par2Store {
+	os.MkdirAll(rootPath, 0755)
+
+	return &Par2Store{
+		rootPath: rootPath,
+	}
+}
+
+func hashKey(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (ps *Par2Store) releaseDir(sourceNzbPath string) string {
+	return filepath.Join(ps.rootPath, hashKey(sourceNzbPath))
+}
+
+// WriteManifest stores the manifest for an imported NZB
+func (ps *Par2Store) WriteManifest(sourceNzbPath string, manifest *Par2Manifest) error {
+	dir := ps.releaseDir(sourceNzbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create par2 directory: %w", err)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal par2 manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write par2 manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadManifest returns the manifest for an NZB, or nil if none was recorded
+func (ps *Par2Store) ReadManifest(sourceNzbPath string) (*Par2Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(ps.releaseDir(sourceNzbPath), "manifest.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read par2 manifest: %w", err)
+	}
+
+	var manifest Par2Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal par2 manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// WritePatches stores repaired article bodies (decoded) for an NZB. The index is written
+// last so readers never see a patch set that is only partially on disk.
+func (ps *Par2Store) WritePatches(sourceNzbPath string, patches map[string][]byte) error {
+	dir := filepath.Join(ps.releaseDir(sourceNzbPath), "patches")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create patch directory: %w", err)
+	}
+
+	index, err := ps.ReadPatchIndex(sourceNzbPath)
+	if err != nil {
+		return err
+	}
+	if index == nil {
+		index = make(map[string]string, len(patches))
+	}
+
+	for messageID, body := range patches {
+		name := hashKey(messageID) + ".bin"
+		if err := os.WriteFile(filepath.Join(dir, name), body, 0644); err != nil {
+			return fmt.Errorf("failed to write patch for %s: %w", messageID, err)
+		}
+		index[messageID] = name
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch index: %w", err)
+	}
+
+	tmp := filepath.Join(dir, "index.json.tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write patch index: %w", err)
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, "index.json"))
+}
+
+// ReadPatchIndex returns message ID -> patch file name for an NZB, or nil if nothing was repaired
+func (ps *Par2Store) ReadPatchIndex(sourceNzbPath string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(ps.releaseDir(sourceNzbPath), "patches", "index.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read patch index: %w", err)
+	}
+
+	var index map[string]string
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal patch index: %w", err)
+	}
+
+	return index, nil
+}
+
+// ReadPatch returns a repaired article body by patch file name from the index
+func (ps *Par2Store) ReadPatch(sourceNzbPath, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(ps.releaseDir(sourceNzbPath), "patches", filepath.Base(name)))
+}
+
+// Delete removes the manifest and patches of an NZB
+func (ps *Par2Store) Delete(sourceNzbPath string) error {
+	if err := os.RemoveAll(ps.releaseDir(sourceNzbPath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete par2 data: %w", err)
+	}
+
+	return nil
+}
+
+// Prune removes the data of every NZB not in keep and returns how many were removed
+func (ps *Par2Store) Prune(keep []string) (int, error) {
+	entries, err := os.ReadDir(ps.rootPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to list par2 data: %w", err)
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, sourceNzbPath := range keep {
+		kept[hashKey(sourceNzbPath)] = true
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || kept[entry.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(ps.rootPath, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to delete par2 data: %w", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
@@ -15,6 +15,7 @@ import (
 	"novastream/internal/database"
 	"novastream/internal/encryption"
 	"novastream/internal/encryption/rclone"
+	"novastream/internal/events"
 	"novastream/internal/nzb/metadata"
 	metapb "novastream/internal/nzb/metadata/proto"
 	"novastream/internal/nzb/utils"
@@ -31,6 +32,7 @@ type MetadataRemoteFile struct {
 	poolManager      pool.Manager        // Pool manager for dynamic pool access
 	configGetter     config.ConfigGetter // Dynamic config access
 	rcloneCipher     encryption.Cipher   // For rclone encryption/decryption
+	par2Repairer     *Par2Repairer       // Optional PAR2 repair of missing articles
 }
 
 // Configuration is now accessed dynamically through config.ConfigGetter
@@ -61,6 +63,11 @@ func NewMetadataRemoteFile(
 	}
 }
 
+// SetPar2Repairer enables PAR2 repair for corrupted files
+func (mrf *MetadataRemoteFile) SetPar2Repairer(repairer *Par2Repairer) {
+	mrf.par2Repairer = repairer
+}
+
 // Helper methods to get dynamic config values
 func (mrf *MetadataRemoteFile) getMaxDownloadWorkers() int {
 	return mrf.configGetter().Streaming.MaxDownloadWorkers
@@ -132,7 +139,13 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string, r util
 	}
 
 	if fileMeta.Status == metapb.FileStatus_FILE_STATUS_CORRUPTED {
-		return false, nil, ErrFileIsCorrupted
+		// Files with repaired articles can be served while the status catches up
+		if mrf.par2Repairer == nil || !mrf.par2Repairer.HasPatches(fileMeta.SourceNzbPath) {
+			if mrf.par2Repairer != nil {
+				mrf.par2Repairer.Schedule(normalizedName, fileMeta.SourceNzbPath)
+			}
+			return false, nil, ErrFileIsCorrupted
+		}
 	}
 
 	// Create a metadata-based virtual file handle
@@ -149,6 +162,7 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string, r util
 		rcloneCipher:     mrf.rcloneCipher,
 		globalPassword:   mrf.getGlobalPassword(),
 		globalSalt:       mrf.getGlobalSalt(),
+		par2Repairer:     mrf.par2Repairer,
 	}
 
 	return true, virtualFile, nil
@@ -166,8 +180,14 @@ func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string)
 
 	// Check if this is a directory
 	if mrf.metadataService.DirectoryExists(normalizedName) {
+		sourceNzbs := mrf.sourceNzbsUnder(normalizedName)
+
 		// Use MetadataService's directory delete operation
-		return true, mrf.metadataService.DeleteDirectory(normalizedName)
+		if err := mrf.metadataService.DeleteDirectory(normalizedName); err != nil {
+			return true, err
+		}
+		mrf.forgetPar2Data(sourceNzbs)
+		return true, nil
 	}
 
 	// Check if this path exists as a file in our metadata
@@ -177,8 +197,51 @@ func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string)
 		return false, nil
 	}
 
+	var sourceNzbs []string
+	if fileMeta, err := mrf.metadataService.ReadFileMetadata(normalizedName); err == nil && fileMeta != nil {
+		sourceNzbs = append(sourceNzbs, fileMeta.SourceNzbPath)
+	}
+
 	// Use MetadataService's file delete operation
-	return true, mrf.metadataService.DeleteFileMetadata(normalizedName)
+	if err := mrf.metadataService.DeleteFileMetadata(normalizedName); err != nil {
+		return true, err
+	}
+	mrf.forgetPar2Data(sourceNzbs)
+	return true, nil
+}
+
+// sourceNzbsUnder returns the source NZB paths of the files in a directory tree
+func (mrf *MetadataRemoteFile) sourceNzbsUnder(dirPath string) []string {
+	if mrf.par2Repairer == nil {
+		return nil
+	}
+
+	var sourceNzbs []string
+	var walk func(dir string)
+	walk = func(dir string) {
+		files, _ := mrf.metadataService.ListDirectory(dir)
+		for _, name := range files {
+			fileMeta, err := mrf.metadataService.ReadFileMetadata(filepath.Join(dir, name))
+			if err == nil && fileMeta != nil {
+				sourceNzbs = append(sourceNzbs, fileMeta.SourceNzbPath)
+			}
+		}
+		subdirs, _ := mrf.metadataService.ListSubdirectories(dir)
+		for _, sub := range subdirs {
+			walk(filepath.Join(dir, sub))
+		}
+	}
+	walk(dirPath)
+
+	return sourceNzbs
+}
+
+// forgetPar2Data deletes the PAR2 data of removed releases once none of their files are left
+func (mrf *MetadataRemoteFile) forgetPar2Data(sourceNzbs []string) {
+	if mrf.par2Repairer == nil || len(sourceNzbs) == 0 {
+		return
+	}
+	go mrf.par2Repairer.Forget(sourceNzbs...)
 }
 
 // RenameFile renames a virtual file or directory in the metadata
@@ -479,6 +542,7 @@ type MetadataVirtualFile struct {
 	rcloneCipher     encryption.Cipher
 	globalPassword   string
 	globalSalt       string
+	par2Repairer     *Par2Repairer
 
 	// Reader state and position tracking
 	reader            io.ReadCloser
@@ -853,6 +917,11 @@ func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, e
 		}
 	}
 
+	// Serve articles rebuilt from PAR2 volumes in place of the missing ones
+	if mvf.par2Repairer != nil {
+		cp = mvf.par2Repairer.WrapPool(cp, mvf.fileMeta.SourceNzbPath)
+	}
+
 	loader := newMetadataSegmentLoader(mvf.fileMeta.SegmentData)
 	rg := usenet.GetSegmentsInRangeWithLimit(start, end, loader, maxSegments)
 	return usenet.NewUsenetReader(ctx, cp, rg, mvf.maxWorkers, mvf.maxCacheSizeMB)
@@ -948,8 +1017,24 @@ func (mvf *MetadataVirtualFile) updateFileHealthOnError(articleErr *usenet.Artic
 			&errorDetails,
 		); err != nil {
 			fmt.Printf("Warning: failed to update file health for %s: %v\n", mvf.name, err)
+			return
+		}
+
+		if dbStatus == database.HealthStatusCorrupted {
+			events.Publish(events.Event{
+				Type:    events.FileCorrupted,
+				Title:   "File marked corrupted",
+				Message: fmt.Sprintf("%s is missing articles and has been marked corrupted.", filepath.Base(mvf.name)),
+				Fields:  map[string]string{"file": mvf.name, "error": errorMsg},
+				Key:     mvf.name,
+			})
 		}
 	}()
+
+	// Try to rebuild the missing articles from the release's PAR2 volumes
+	if mvf.par2Repairer != nil {
+		mvf.par2Repairer.Schedule(mvf.name, mvf.fileMeta.SourceNzbPath)
+	}
 }
 
 // isValidEmptyDirectory checks if a path could represent a valid empty directory
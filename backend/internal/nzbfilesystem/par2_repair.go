@@ -0,0 +1,650 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"novastream/config"
+	"novastream/internal/database"
+	"novastream/internal/nzb/metadata"
+	metapb "novastream/internal/nzb/metadata/proto"
+	"novastream/internal/par2"
+	"novastream/internal/pool"
+	"novastream/internal/usenet"
+
+	"github.com/javi11/nntppool"
+	concpool "github.com/sourcegraph/conc/pool"
+)
+
+// Default memory budget for recovery blocks and accumulators during a repair
+const defaultPar2RepairMemoryBytes = 1 << 30
+
+var (
+	ErrNoPar2Data         = errors.New("no PAR2 volumes recorded for this release")
+	ErrNothingToRepair    = errors.New("no missing articles found")
+	ErrPar2RepairTooLarge = errors.New("damage exceeds the PAR2 repair memory budget")
+)
+
+// Par2Repairer rebuilds missing articles of imported releases from their PAR2 recovery
+// volumes. Repaired article bodies are written to the PAR2 store and served in place of
+// the missing articles, so a corrupted file becomes playable without another search.
+//
+// Repair works on whole articles: PAR2 slices overlapping a missing article are
+// reconstructed and the article body is cut back out of them. This is transparent to
+// RAR-extracted files, which read the same articles at an offset.
+type Par2Repairer struct {
+	store            *metadata.Par2Store
+	metadataService  *metadata.MetadataService
+	healthRepository *database.HealthRepository
+	poolManager      pool.Manager
+	configGetter     config.ConfigGetter
+	maxMemoryBytes   int64
+	log              *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{} // one repair at a time; they download whole releases
+
+	mu       sync.Mutex
+	inflight map[string]struct{}          // source NZB paths being repaired
+	indexes  map[string]map[string]string // cached patch indexes by source NZB path
+}
+
+// NewPar2Repairer creates a repairer backed by the given PAR2 store
+func NewPar2Repairer(
+	store *metadata.Par2Store,
+	metadataService *metadata.MetadataService,
+	healthRepository *database.HealthRepository,
+	poolManager pool.Manager,
+	configGetter config.ConfigGetter,
+) *Par2Repairer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Par2Repairer{
+		store:            store,
+		metadataService:  metadataService,
+		healthRepository: healthRepository,
+		poolManager:      poolManager,
+		configGetter:     configGetter,
+		maxMemoryBytes:   defaultPar2RepairMemoryBytes,
+		log:              slog.Default().With("component", "par2-repair"),
+		ctx:              ctx,
+		cancel:           cancel,
+		sem:              make(chan struct{}, 1),
+		inflight:         make(map[string]struct{}),
+		indexes:          make(map[string]map[string]string),
+	}
+}
+
+// Close cancels any running repair
+func (r *Par2Repairer) Close() {
+	r.cancel()
+}
+
+func (r *Par2Repairer) downloadWorkers() int {
+	if r.configGetter != nil {
+		if cfg := r.configGetter(); cfg != nil && cfg.Streaming.MaxDownloadWorkers > 0 {
+			return cfg.Streaming.MaxDownloadWorkers
+		}
+	}
+	return 15
+}
+
+// patchIndex returns the cached patch index for a release, loading it from disk once
+func (r *Par2Repairer) patchIndex(sourceNzbPath string) map[string]string {
+	if sourceNzbPath == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if index, ok := r.indexes[sourceNzbPath]; ok {
+		return index
+	}
+
+	index, err := r.store.ReadPatchIndex(sourceNzbPath)
+	if err != nil {
+		r.log.Warn("Failed to read PAR2 patch index", "nzb", sourceNzbPath, "error", err)
+		return nil
+	}
+	r.indexes[sourceNzbPath] = index
+	return index
+}
+
+// HasPatches reports whether repaired articles exist for a release
+func (r *Par2Repairer) HasPatches(sourceNzbPath string) bool {
+	return len(r.patchIndex(sourceNzbPath)) > 0
+}
+
+// HasManifest reports whether a release was imported with PAR2 volumes
+func (r *Par2Repairer) HasManifest(sourceNzbPath string) bool {
+	if sourceNzbPath == "" {
+		return false
+	}
+	manifest, err := r.store.ReadManifest(sourceNzbPath)
+	return err == nil && manifest != nil && len(manifest.Par2Files) > 0
+}
+
+// referencedNzbs returns the source NZB paths of every file still in the metadata
+func (r *Par2Repairer) referencedNzbs() (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	err := r.metadataService.WalkMetadata(func(_ string, fileMeta *metapb.FileMetadata) error {
+		if fileMeta.SourceNzbPath != "" {
+			referenced[fileMeta.SourceNzbPath] = true
+		}
+		return nil
+	})
+	return referenced, err
+}
+
+// Forget deletes the PAR2 data of releases no file in the metadata comes from anymore.
+// Call it after removing files, with the source NZB paths of the removed files.
+func (r *Par2Repairer) Forget(sourceNzbPaths ...string) {
+	if len(sourceNzbPaths) == 0 {
+		return
+	}
+
+	referenced, err := r.referencedNzbs()
+	if err != nil {
+		r.log.Warn("Failed to check PAR2 data references", "error", err)
+		return
+	}
+
+	for _, sourceNzbPath := range sourceNzbPaths {
+		if sourceNzbPath == "" || referenced[sourceNzbPath] {
+			continue
+		}
+
+		r.mu.Lock()
+		_, repairing := r.inflight[sourceNzbPath]
+		delete(r.indexes, sourceNzbPath)
+		r.mu.Unlock()
+		if repairing {
+			continue
+		}
+
+		if err := r.store.Delete(sourceNzbPath); err != nil {
+			r.log.Warn("Failed to delete PAR2 data", "nzb", sourceNzbPath, "error", err)
+			continue
+		}
+		r.log.Debug("Deleted PAR2 data", "nzb", sourceNzbPath)
+	}
+}
+
+// Prune deletes the PAR2 data of every release no file in the metadata comes from, such as
+// releases removed while the server was down
+func (r *Par2Repairer) Prune() {
+	referenced, err := r.referencedNzbs()
+	if err != nil {
+		r.log.Warn("Failed to check PAR2 data references", "error", err)
+		return
+	}
+
+	keep := make([]string, 0, len(referenced))
+	for sourceNzbPath := range referenced {
+		keep = append(keep, sourceNzbPath)
+	}
+
+	r.mu.Lock()
+	for sourceNzbPath := range r.inflight {
+		keep = append(keep, sourceNzbPath)
+	}
+	r.indexes = make(map[string]map[string]string)
+	r.mu.Unlock()
+
+	removed, err := r.store.Prune(keep)
+	if err != nil {
+		r.log.Warn("Failed to prune PAR2 data", "error", err)
+	}
+	if removed > 0 {
+		r.log.Info("Pruned orphaned PAR2 data", "releases", removed)
+	}
+}
+
+// WrapPool returns a pool that serves repaired articles of the release before asking providers
+func (r *Par2Repairer) WrapPool(cp nntppool.UsenetConnectionPool, sourceNzbPath string) nntppool.UsenetConnectionPool {
+	index := r.patchIndex(sourceNzbPath)
+	if len(index) == 0 {
+		return cp
+	}
+	return &patchedPool{
+		UsenetConnectionPool: cp,
+		store:                r.store,
+		sourceNzbPath:        sourceNzbPath,
+		index:                index,
+	}
+}
+
+// Schedule starts a background repair for a file unless its release is already being repaired
+func (r *Par2Repairer) Schedule(virtualPath, sourceNzbPath string) {
+	if !r.HasManifest(sourceNzbPath) {
+		return
+	}
+
+	r.mu.Lock()
+	if _, running := r.inflight[sourceNzbPath]; running {
+		r.mu.Unlock()
+		return
+	}
+	r.inflight[sourceNzbPath] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.inflight, sourceNzbPath)
+			r.mu.Unlock()
+		}()
+
+		if err := r.Repair(r.ctx, virtualPath); err != nil {
+			r.log.Warn("PAR2 repair failed", "file", virtualPath, "nzb", sourceNzbPath, "error", err)
+		}
+	}()
+}
+
+// Repair rebuilds the missing articles of a file's release and marks the file healthy
+func (r *Par2Repairer) Repair(ctx context.Context, virtualPath string) error {
+	fileMeta, err := r.metadataService.ReadFileMetadata(virtualPath)
+	if err != nil {
+		return err
+	}
+	if fileMeta == nil {
+		return fmt.Errorf("metadata not found for %s", virtualPath)
+	}
+
+	select {
+	case r.sem <- struct{}{}:
+		defer func() { <-r.sem }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	patched, err := r.repairRelease(ctx, fileMeta.SourceNzbPath)
+	if err != nil {
+		return err
+	}
+
+	r.log.Info("PAR2 repair complete", "file", virtualPath, "repaired_articles", patched)
+	r.markHealthy(virtualPath, fileMeta.SourceNzbPath)
+	return nil
+}
+
+func (r *Par2Repairer) markHealthy(virtualPath, sourceNzbPath string) {
+	if err := r.metadataService.UpdateFileStatus(virtualPath, metapb.FileStatus_FILE_STATUS_HEALTHY); err != nil {
+		r.log.Warn("Failed to update metadata status after repair", "file", virtualPath, "error", err)
+	}
+	if r.healthRepository == nil {
+		return
+	}
+	var source *string
+	if sourceNzbPath != "" {
+		source = &sourceNzbPath
+	}
+	if err := r.healthRepository.UpdateFileHealth(virtualPath, database.HealthStatusHealthy, nil, source, nil); err != nil {
+		r.log.Warn("Failed to update file health after repair", "file", virtualPath, "error", err)
+	}
+}
+
+// releaseMember pairs a file of the recovery set with its articles from the NZB
+type releaseMember struct {
+	file    *par2.File
+	data    *metadata.Par2ManifestFile
+	offsets []int64 // file offset of each segment
+}
+
+func (r *Par2Repairer) repairRelease(ctx context.Context, sourceNzbPath string) (int, error) {
+	manifest, err := r.store.ReadManifest(sourceNzbPath)
+	if err != nil {
+		return 0, err
+	}
+	if manifest == nil || len(manifest.Par2Files) == 0 {
+		return 0, ErrNoPar2Data
+	}
+
+	cp, err := r.poolManager.GetPool()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection pool: %w", err)
+	}
+
+	// Smallest PAR2 file first: that is the index, larger ones are recovery volumes
+	par2Files := append([]metadata.Par2ManifestFile(nil), manifest.Par2Files...)
+	sort.SliceStable(par2Files, func(i, j int) bool { return par2Files[i].Size < par2Files[j].Size })
+
+	set := par2.NewRecoverySet()
+	next := 0
+	for ; next < len(par2Files); next++ {
+		data, err := r.downloadFile(ctx, cp, &par2Files[next])
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			r.log.Debug("Skipping unreadable PAR2 file", "file", par2Files[next].Name, "error", err)
+			continue
+		}
+		if err := set.Add(data, 0); err != nil {
+			return 0, err
+		}
+		if _, err := set.Files(); err == nil {
+			next++
+			break
+		}
+	}
+
+	members, err := matchRecoverySet(set, manifest)
+	if err != nil {
+		return 0, err
+	}
+
+	damaged, err := r.findDamagedSlices(ctx, cp, set.SliceSize, members)
+	if err != nil {
+		return 0, err
+	}
+	if len(damaged) == 0 {
+		return 0, ErrNothingToRepair
+	}
+
+	// A little headroom for damage only visible once slices are read and checksummed
+	want := len(damaged) + 1 + len(damaged)/10
+	if int64(want)*set.SliceSize*2 > r.maxMemoryBytes {
+		return 0, fmt.Errorf("%w: %d damaged slices of %d bytes", ErrPar2RepairTooLarge, len(damaged), set.SliceSize)
+	}
+
+	for ; next < len(par2Files) && len(set.Blocks) < want; next++ {
+		data, err := r.downloadFile(ctx, cp, &par2Files[next])
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			r.log.Debug("Skipping unreadable PAR2 volume", "file", par2Files[next].Name, "error", err)
+			continue
+		}
+		if err := set.Add(data, want-len(set.Blocks)); err != nil {
+			return 0, err
+		}
+	}
+
+	r.log.Info("Starting PAR2 repair",
+		"nzb", sourceNzbPath,
+		"damaged_slices", len(damaged),
+		"recovery_blocks", len(set.Blocks),
+		"slice_size", set.SliceSize)
+
+	solver, err := par2.NewSolver(set.SliceSize, set.SliceCount(), damaged, set.Blocks)
+	if err != nil {
+		return 0, err
+	}
+	set.Blocks = nil // the solver keeps its own copy
+
+	if err := r.accumulate(ctx, cp, solver, set.SliceSize, members); err != nil {
+		return 0, err
+	}
+
+	repaired, err := solver.Solve()
+	if err != nil {
+		return 0, err
+	}
+
+	patches, err := buildPatches(repaired, set.SliceSize, members)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.store.WritePatches(sourceNzbPath, patches); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	delete(r.indexes, sourceNzbPath)
+	r.mu.Unlock()
+
+	return len(patches), nil
+}
+
+// matchRecoverySet maps every file protected by the PAR2 set to a file in the NZB,
+// by name first and then by unique size for obfuscated uploads
+func matchRecoverySet(set *par2.RecoverySet, manifest *metadata.Par2Manifest) ([]releaseMember, error) {
+	files, err := set.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*metadata.Par2ManifestFile, len(manifest.DataFiles))
+	bySize := make(map[int64][]*metadata.Par2ManifestFile)
+	for i := range manifest.DataFiles {
+		df := &manifest.DataFiles[i]
+		byName[df.Name] = df
+		bySize[df.Size] = append(bySize[df.Size], df)
+	}
+
+	members := make([]releaseMember, 0, len(files))
+	for _, f := range files {
+		data := byName[f.Name]
+		if data == nil && len(bySize[f.Length]) == 1 {
+			data = bySize[f.Length][0]
+		}
+		if data == nil {
+			return nil, fmt.Errorf("PAR2 file %s not found in NZB", f.Name)
+		}
+
+		offsets := make([]int64, len(data.Segments))
+		var pos int64
+		for i, seg := range data.Segments {
+			offsets[i] = pos
+			pos += seg.Size
+		}
+		if pos != f.Length {
+			return nil, fmt.Errorf("articles of %s cover %d bytes, PAR2 expects %d", f.Name, pos, f.Length)
+		}
+
+		members = append(members, releaseMember{file: f, data: data, offsets: offsets})
+	}
+	return members, nil
+}
+
+// findDamagedSlices checks every article of the set and returns the global indexes of
+// slices overlapping a missing one
+func (r *Par2Repairer) findDamagedSlices(ctx context.Context, cp nntppool.UsenetConnectionPool, sliceSize int64, members []releaseMember) ([]int, error) {
+	var (
+		mu      sync.Mutex
+		damaged = make(map[int]struct{})
+	)
+
+	p := concpool.New().WithMaxGoroutines(r.downloadWorkers()).WithContext(ctx)
+	for _, m := range members {
+		m := m
+		for i, seg := range m.data.Segments {
+			start := m.offsets[i]
+			end := start + seg.Size - 1
+			id := seg.ID
+			p.Go(func(ctx context.Context) error {
+				// Backups are only asked about articles the primaries don't carry
+				found, err := pool.StatPrimaries(ctx, cp, id, m.data.Groups)
+				if err == nil && !found {
+					_, err = cp.Stat(ctx, id, m.data.Groups)
+				}
+				if err != nil {
+					if !errors.Is(err, nntppool.ErrArticleNotFoundInProviders) {
+						if ctx.Err() != nil {
+							return ctx.Err()
+						}
+						// Unknown state: the checksum pass will catch real damage
+						return nil
+					}
+					mu.Lock()
+					for s := start / sliceSize; s <= end/sliceSize; s++ {
+						damaged[m.file.FirstSlice+int(s)] = struct{}{}
+					}
+					mu.Unlock()
+				}
+				return nil
+			})
+		}
+	}
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, 0, len(damaged))
+	for idx := range damaged {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// accumulate streams every intact slice of the set through the solver, marking slices
+// that cannot be read or fail their checksum as damaged
+func (r *Par2Repairer) accumulate(ctx context.Context, cp nntppool.UsenetConnectionPool, solver *par2.Solver, sliceSize int64, members []releaseMember) error {
+	for _, m := range members {
+		count := m.file.SliceCount(sliceSize)
+		for s := 0; s < count; s++ {
+			global := m.file.FirstSlice + s
+			if solver.IsDamaged(global) {
+				continue
+			}
+
+			start := int64(s) * sliceSize
+			end := min(start+sliceSize, m.file.Length) - 1
+			data, err := r.readRange(ctx, cp, m.data, start, end)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.log.Debug("Slice unreadable, marking damaged", "file", m.file.Name, "slice", s, "error", err)
+				if err := solver.MarkDamaged(global); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if int64(len(data)) != end-start+1 || md5.Sum(padTo(data, sliceSize)) != m.file.Checksums[s].MD5 {
+				r.log.Debug("Slice checksum mismatch, marking damaged", "file", m.file.Name, "slice", s)
+				if err := solver.MarkDamaged(global); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := solver.Add(global, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildPatches cuts the article bodies fully covered by repaired slices back out of them.
+// Articles that were missing always are; articles straddling an intact slice are served
+// by the providers as before.
+func buildPatches(repaired map[int][]byte, sliceSize int64, members []releaseMember) (map[string][]byte, error) {
+	patches := make(map[string][]byte)
+	for _, m := range members {
+		for s := 0; s < m.file.SliceCount(sliceSize); s++ {
+			if data, ok := repaired[m.file.FirstSlice+s]; ok && md5.Sum(data) != m.file.Checksums[s].MD5 {
+				return nil, fmt.Errorf("repaired slice %d of %s failed verification", s, m.file.Name)
+			}
+		}
+
+		for i, seg := range m.data.Segments {
+			start := m.offsets[i]
+			end := start + seg.Size - 1
+			first, last := start/sliceSize, end/sliceSize
+
+			covered := true
+			for s := first; s <= last; s++ {
+				if _, ok := repaired[m.file.FirstSlice+int(s)]; !ok {
+					covered = false
+					break
+				}
+			}
+			if !covered {
+				continue
+			}
+
+			body := make([]byte, 0, seg.Size)
+			for s := first; s <= last; s++ {
+				slice := repaired[m.file.FirstSlice+int(s)]
+				sliceStart := s * sliceSize
+				from := max(start, sliceStart) - sliceStart
+				to := min(end+1, sliceStart+sliceSize) - sliceStart
+				body = append(body, slice[from:to]...)
+			}
+			patches[seg.ID] = body
+		}
+	}
+	return patches, nil
+}
+
+func padTo(data []byte, size int64) []byte {
+	if int64(len(data)) >= size {
+		return data
+	}
+	padded := make([]byte, size)
+	copy(padded, data)
+	return padded
+}
+
+// downloadFile fetches and decodes a whole file from its articles
+func (r *Par2Repairer) downloadFile(ctx context.Context, cp nntppool.UsenetConnectionPool, file *metadata.Par2ManifestFile) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(int(file.Size))
+	for _, seg := range file.Segments {
+		if _, err := cp.Body(ctx, seg.ID, &buf, file.Groups); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", file.Name, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readRange reads [start, end] of a data file through the regular segment reader
+func (r *Par2Repairer) readRange(ctx context.Context, cp nntppool.UsenetConnectionPool, file *metadata.Par2ManifestFile, start, end int64) ([]byte, error) {
+	rg := usenet.GetSegmentsInRange(start, end, manifestSegmentLoader{file: file})
+	reader, err := usenet.NewUsenetReader(ctx, cp, rg, r.downloadWorkers())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(io.LimitReader(reader, end-start+1))
+}
+
+// manifestSegmentLoader adapts manifest articles to the usenet.SegmentLoader interface
+type manifestSegmentLoader struct {
+	file *metadata.Par2ManifestFile
+}
+
+func (l manifestSegmentLoader) GetSegment(index int) (usenet.Segment, []string, bool) {
+	if index < 0 || index >= len(l.file.Segments) {
+		return usenet.Segment{}, nil, false
+	}
+	seg := l.file.Segments[index]
+	return usenet.Segment{Id: seg.ID, Start: 0, Size: seg.Size}, l.file.Groups, true
+}
+
+// patchedPool serves repaired article bodies ahead of the providers. Only Body is
+// intercepted; that is all the usenet reader uses.
+type patchedPool struct {
+	nntppool.UsenetConnectionPool
+	store         *metadata.Par2Store
+	sourceNzbPath string
+	index         map[string]string
+}
+
+func (p *patchedPool) Body(ctx context.Context, msgID string, w io.Writer, nntpGroups []string) (int64, error) {
+	if name, ok := p.index[msgID]; ok {
+		data, err := p.store.ReadPatch(p.sourceNzbPath, name)
+		if err == nil {
+			n, err := w.Write(data)
+			return int64(n), err
+		}
+		slog.Default().Warn("Failed to read PAR2 patch, falling back to providers", "message_id", msgID, "error", err)
+	}
+	return p.UsenetConnectionPool.Body(ctx, msgID, w, nntpGroups)
+}
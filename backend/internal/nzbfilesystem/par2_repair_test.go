@@ -0,0 +1,145 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"io"
+	"testing"
+
+	"novastream/internal/nzb/metadata"
+	metapb "novastream/internal/nzb/metadata/proto"
+	"novastream/internal/par2"
+
+	"github.com/javi11/nntppool"
+)
+
+// bodyOnlyPool answers Body from a fixed article map; other methods are never called
+type bodyOnlyPool struct {
+	nntppool.UsenetConnectionPool
+	articles map[string][]byte
+}
+
+func (p *bodyOnlyPool) Body(ctx context.Context, msgID string, w io.Writer, nntpGroups []string) (int64, error) {
+	data, ok := p.articles[msgID]
+	if !ok {
+		return 0, nntppool.ErrArticleNotFoundInProviders
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func TestBuildPatchesCutsCoveredArticles(t *testing.T) {
+	data := make([]byte, 250)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	const sliceSize = 100
+
+	member := releaseMember{
+		file: &par2.File{Name: "movie.rar", Length: int64(len(data))},
+		data: &metadata.Par2ManifestFile{
+			Name: "movie.rar",
+			Size: int64(len(data)),
+			Segments: []metadata.Par2ManifestSegment{
+				{ID: "a", Size: 80}, // slice 0
+				{ID: "b", Size: 80}, // slices 0-1
+				{ID: "c", Size: 90}, // slices 1-2
+			},
+		},
+		offsets: []int64{0, 80, 160},
+	}
+
+	// Slices 1 and 2 were rebuilt; the last one is zero padded
+	repaired := map[int][]byte{
+		1: append([]byte(nil), data[100:200]...),
+		2: padTo(append([]byte(nil), data[200:]...), sliceSize),
+	}
+	for i := 0; i < 3; i++ {
+		end := min((i+1)*sliceSize, len(data))
+		member.file.Checksums = append(member.file.Checksums, par2.SliceChecksum{
+			MD5: md5.Sum(padTo(data[i*sliceSize:end], sliceSize)),
+		})
+	}
+
+	patches, err := buildPatches(repaired, sliceSize, []releaseMember{member})
+	if err != nil {
+		t.Fatalf("buildPatches failed: %v", err)
+	}
+	if len(patches) != 1 {
+		t.Fatalf("expected only article c to be patched, got %d patches", len(patches))
+	}
+	if !bytes.Equal(patches["c"], data[160:]) {
+		t.Fatalf("article c body mismatch")
+	}
+
+	// A slice that does not match its checksum must not produce patches
+	repaired[1][0] ^= 0xFF
+	if _, err := buildPatches(repaired, sliceSize, []releaseMember{member}); err == nil {
+		t.Fatal("expected verification error for a bad repaired slice")
+	}
+}
+
+func TestPatchedPoolServesRepairedArticles(t *testing.T) {
+	store := metadata.NewPar2Store(t.TempDir())
+	const source = "/nzbs/movie.nzb"
+	if err := store.WritePatches(source, map[string][]byte{"missing@test": []byte("repaired")}); err != nil {
+		t.Fatalf("WritePatches failed: %v", err)
+	}
+
+	repairer := NewPar2Repairer(store, nil, nil, nil, nil)
+	defer repairer.Close()
+
+	base := &bodyOnlyPool{articles: map[string][]byte{"present@test": []byte("original")}}
+	if repairer.WrapPool(base, "/nzbs/other.nzb") != nntppool.UsenetConnectionPool(base) {
+		t.Fatal("expected releases without patches to use the pool unchanged")
+	}
+	if !repairer.HasPatches(source) {
+		t.Fatal("expected HasPatches to report stored patches")
+	}
+
+	cp := repairer.WrapPool(base, source)
+	for id, want := range map[string]string{"missing@test": "repaired", "present@test": "original"} {
+		var buf bytes.Buffer
+		if _, err := cp.Body(context.Background(), id, &buf, nil); err != nil {
+			t.Fatalf("Body(%s) failed: %v", id, err)
+		}
+		if buf.String() != want {
+			t.Fatalf("Body(%s) = %q, want %q", id, buf.String(), want)
+		}
+	}
+}
+
+func TestForgetKeepsPar2DataOfReferencedReleases(t *testing.T) {
+	store := metadata.NewPar2Store(t.TempDir())
+	metadataService := metadata.NewMetadataService(t.TempDir())
+	const kept, removed = "/nzbs/kept.nzb", "/nzbs/removed.nzb"
+	for _, source := range []string{kept, removed} {
+		if err := store.WritePatches(source, map[string][]byte{"missing@test": []byte("repaired")}); err != nil {
+			t.Fatalf("WritePatches failed: %v", err)
+		}
+	}
+	fileMeta := metadataService.CreateFileMetadata(8, kept, metapb.FileStatus_FILE_STATUS_HEALTHY, nil, metapb.Encryption_NONE, "", "")
+	if err := metadataService.WriteFileMetadata("/movies/kept.mkv", fileMeta); err != nil {
+		t.Fatalf("WriteFileMetadata failed: %v", err)
+	}
+
+	repairer := NewPar2Repairer(store, metadataService, nil, nil, nil)
+	defer repairer.Close()
+
+	repairer.Forget(kept, removed)
+	if !repairer.HasPatches(kept) {
+		t.Fatal("expected PAR2 data of a release with files left to be kept")
+	}
+	if repairer.HasPatches(removed) {
+		t.Fatal("expected PAR2 data of a release without files to be deleted")
+	}
+
+	if err := metadataService.DeleteFileMetadata("/movies/kept.mkv"); err != nil {
+		t.Fatalf("DeleteFileMetadata failed: %v", err)
+	}
+	repairer.Prune()
+	if repairer.HasPatches(kept) {
+		t.Fatal("expected Prune to delete PAR2 data of releases without files")
+	}
+}
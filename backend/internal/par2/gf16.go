@@ -0,0 +1,96 @@
+package par2
+
+// PAR2 Reed-Solomon coding works in GF(2^16) with the generator polynomial
+// x^16 + x^12 + x^3 + x + 1 (0x1100B). Slice data is treated as a sequence of
+// little-endian 16-bit words.
+const (
+	gfPolynomial = 0x1100B
+	gfLimit      = 65535 // multiplicative group order
+)
+
+var (
+	gfLog [1 << 16]uint16
+	gfExp [2 * gfLimit]uint16
+)
+
+func init() {
+	b := uint32(1)
+	for l := 0; l < gfLimit; l++ {
+		gfExp[l] = uint16(b)
+		gfExp[l+gfLimit] = uint16(b)
+		gfLog[b] = uint16(l)
+		b <<= 1
+		if b&0x10000 != 0 {
+			b ^= gfPolynomial
+		}
+	}
+}
+
+func gfMul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b uint16) uint16 {
+	if a == 0 {
+		return 0
+	}
+	if b == 0 {
+		panic("par2: division by zero in GF(2^16)")
+	}
+	return gfExp[int(gfLog[a])+gfLimit-int(gfLog[b])]
+}
+
+func gfPow(base uint16, exponent uint32) uint16 {
+	if exponent == 0 {
+		return 1
+	}
+	if base == 0 {
+		return 0
+	}
+	return gfExp[(uint64(gfLog[base])*uint64(exponent))%gfLimit]
+}
+
+// inputConstants returns the PAR2 base value for each of n input slices: powers of
+// two whose logarithm is coprime to 65535 (not a multiple of 3, 5, 17 or 257).
+func inputConstants(n int) []uint16 {
+	constants := make([]uint16, n)
+	logBase := 0
+	for i := range constants {
+		for logBase%3 == 0 || logBase%5 == 0 || logBase%17 == 0 || logBase%257 == 0 {
+			logBase++
+		}
+		constants[i] = gfExp[logBase]
+		logBase++
+	}
+	return constants
+}
+
+// mulAdd computes dst ^= factor * src word by word. Both slices must have the same
+// even length. Multiplication distributes over XOR, so each word is split into its
+// low and high byte and looked up in two 256-entry tables built for factor.
+func mulAdd(dst, src []byte, factor uint16) {
+	if factor == 0 {
+		return
+	}
+	if factor == 1 {
+		for i := range src {
+			dst[i] ^= src[i]
+		}
+		return
+	}
+
+	var lo, hi [256]uint16
+	for i := 1; i < 256; i++ {
+		lo[i] = gfMul(factor, uint16(i))
+		hi[i] = gfMul(factor, uint16(i)<<8)
+	}
+
+	for i := 0; i+1 < len(src); i += 2 {
+		v := lo[src[i]] ^ hi[src[i+1]]
+		dst[i] ^= byte(v)
+		dst[i+1] ^= byte(v >> 8)
+	}
+}
@@ -0,0 +1,259 @@
+// Package par2 reads PAR2 recovery sets and rebuilds damaged input slices with
+// the Reed-Solomon recovery data they carry.
+package par2
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const packetHeaderSize = 64
+
+var (
+	packetMagic = []byte("PAR2\x00PKT")
+
+	typeMain     = packetType("PAR 2.0\x00Main\x00\x00\x00\x00")
+	typeFileDesc = packetType("PAR 2.0\x00FileDesc")
+	typeIFSC     = packetType("PAR 2.0\x00IFSC\x00\x00\x00\x00")
+	typeRecvSlic = packetType("PAR 2.0\x00RecvSlic")
+)
+
+var (
+	// ErrIncompleteSet is returned when the main, file description or slice checksum
+	// packets needed to map input slices have not been seen.
+	ErrIncompleteSet = errors.New("par2: recovery set is missing critical packets")
+	// ErrSetMismatch is returned when packets from a different recovery set are added.
+	ErrSetMismatch = errors.New("par2: packets belong to a different recovery set")
+)
+
+func packetType(s string) [16]byte {
+	var t [16]byte
+	copy(t[:], s)
+	return t
+}
+
+// SliceChecksum is the MD5 and CRC32 of one input slice, zero padded to the slice size.
+type SliceChecksum struct {
+	MD5   [16]byte
+	CRC32 uint32
+}
+
+// File describes one file protected by the recovery set.
+type File struct {
+	ID        [16]byte
+	Name      string
+	Length    int64
+	Hash      [16]byte
+	Hash16k   [16]byte
+	Checksums []SliceChecksum
+
+	// FirstSlice is the global index of the file's first input slice.
+	FirstSlice int
+}
+
+// SliceCount returns the number of input slices the file occupies.
+func (f *File) SliceCount(sliceSize int64) int {
+	if sliceSize <= 0 {
+		return 0
+	}
+	return int((f.Length + sliceSize - 1) / sliceSize)
+}
+
+// RecoveryBlock is one recovery slice and the exponent it was computed with.
+type RecoveryBlock struct {
+	Exponent uint32
+	Data     []byte
+}
+
+// RecoverySet accumulates packets from the index file and recovery volumes of a release.
+type RecoverySet struct {
+	ID        [16]byte
+	SliceSize int64
+	Blocks    []RecoveryBlock
+
+	fileIDs  [][16]byte
+	files    map[[16]byte]*File
+	haveMain bool
+	haveID   bool
+	seen     map[uint32]struct{}
+}
+
+// NewRecoverySet returns an empty recovery set.
+func NewRecoverySet() *RecoverySet {
+	return &RecoverySet{
+		files: make(map[[16]byte]*File),
+		seen:  make(map[uint32]struct{}),
+	}
+}
+
+// Add parses the packets in data and merges them into the set. At most maxBlocks new
+// recovery slices are kept (negative keeps all, zero keeps none). Packets that fail
+// their MD5 check are skipped; the parser resynchronises on the next packet header.
+func (s *RecoverySet) Add(data []byte, maxBlocks int) error {
+	kept := 0
+	for pos := 0; pos+packetHeaderSize <= len(data); {
+		if !bytes.Equal(data[pos:pos+8], packetMagic) {
+			next := bytes.Index(data[pos+1:], packetMagic)
+			if next < 0 {
+				break
+			}
+			pos += next + 1
+			continue
+		}
+
+		length := binary.LittleEndian.Uint64(data[pos+8:])
+		if length < packetHeaderSize || length%4 != 0 || uint64(len(data)-pos) < length {
+			pos += 8
+			continue
+		}
+		packet := data[pos : pos+int(length)]
+
+		var sum [16]byte
+		copy(sum[:], packet[16:32])
+		if md5.Sum(packet[32:]) != sum {
+			pos += 8
+			continue
+		}
+		pos += int(length)
+
+		var setID, ptype [16]byte
+		copy(setID[:], packet[32:48])
+		copy(ptype[:], packet[48:64])
+		if s.haveID && setID != s.ID {
+			return ErrSetMismatch
+		}
+		s.ID = setID
+		s.haveID = true
+
+		body := packet[packetHeaderSize:]
+		switch ptype {
+		case typeMain:
+			s.parseMain(body)
+		case typeFileDesc:
+			s.parseFileDesc(body)
+		case typeIFSC:
+			s.parseIFSC(body)
+		case typeRecvSlic:
+			if maxBlocks >= 0 && kept >= maxBlocks {
+				continue
+			}
+			if s.parseRecvSlic(body) {
+				kept++
+			}
+		}
+	}
+	return nil
+}
+
+func (s *RecoverySet) parseMain(body []byte) {
+	if s.haveMain || len(body) < 12 {
+		return
+	}
+	s.SliceSize = int64(binary.LittleEndian.Uint64(body[0:8]))
+	count := int(binary.LittleEndian.Uint32(body[8:12]))
+	if len(body) < 12+count*16 {
+		return
+	}
+	s.fileIDs = make([][16]byte, count)
+	for i := range s.fileIDs {
+		copy(s.fileIDs[i][:], body[12+i*16:])
+	}
+	s.haveMain = true
+}
+
+func (s *RecoverySet) file(id [16]byte) *File {
+	f, ok := s.files[id]
+	if !ok {
+		f = &File{ID: id}
+		s.files[id] = f
+	}
+	return f
+}
+
+func (s *RecoverySet) parseFileDesc(body []byte) {
+	if len(body) < 56 {
+		return
+	}
+	var id [16]byte
+	copy(id[:], body[0:16])
+	f := s.file(id)
+	copy(f.Hash[:], body[16:32])
+	copy(f.Hash16k[:], body[32:48])
+	f.Length = int64(binary.LittleEndian.Uint64(body[48:56]))
+	f.Name = strings.TrimRight(string(body[56:]), "\x00")
+}
+
+func (s *RecoverySet) parseIFSC(body []byte) {
+	if len(body) < 16 {
+		return
+	}
+	var id [16]byte
+	copy(id[:], body[0:16])
+	f := s.file(id)
+	if f.Checksums != nil {
+		return
+	}
+	entries := body[16:]
+	f.Checksums = make([]SliceChecksum, len(entries)/20)
+	for i := range f.Checksums {
+		copy(f.Checksums[i].MD5[:], entries[i*20:])
+		f.Checksums[i].CRC32 = binary.LittleEndian.Uint32(entries[i*20+16:])
+	}
+}
+
+func (s *RecoverySet) parseRecvSlic(body []byte) bool {
+	if len(body) < 4 {
+		return false
+	}
+	exponent := binary.LittleEndian.Uint32(body[0:4])
+	if _, dup := s.seen[exponent]; dup {
+		return false
+	}
+	s.seen[exponent] = struct{}{}
+	s.Blocks = append(s.Blocks, RecoveryBlock{
+		Exponent: exponent,
+		Data:     append([]byte(nil), body[4:]...),
+	})
+	return true
+}
+
+// Files returns the protected files in input slice order with FirstSlice assigned.
+// It fails with ErrIncompleteSet until every file's description and checksums are known.
+func (s *RecoverySet) Files() ([]*File, error) {
+	if !s.haveMain || s.SliceSize <= 0 || s.SliceSize%4 != 0 {
+		return nil, ErrIncompleteSet
+	}
+	files := make([]*File, 0, len(s.fileIDs))
+	next := 0
+	for _, id := range s.fileIDs {
+		f, ok := s.files[id]
+		if !ok || f.Name == "" {
+			return nil, fmt.Errorf("%w: no description for file %x", ErrIncompleteSet, id)
+		}
+		count := f.SliceCount(s.SliceSize)
+		if len(f.Checksums) < count {
+			return nil, fmt.Errorf("%w: no slice checksums for %s", ErrIncompleteSet, f.Name)
+		}
+		f.FirstSlice = next
+		next += count
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// SliceCount returns the total number of input slices in the set.
+func (s *RecoverySet) SliceCount() int {
+	files, err := s.Files()
+	if err != nil {
+		return 0
+	}
+	total := 0
+	for _, f := range files {
+		total += f.SliceCount(s.SliceSize)
+	}
+	return total
+}
@@ -0,0 +1,258 @@
+package par2
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type testFile struct {
+	name string
+	data []byte
+}
+
+func buildPacket(setID [16]byte, ptype [16]byte, body []byte) []byte {
+	packet := make([]byte, packetHeaderSize+len(body))
+	copy(packet[0:8], packetMagic)
+	binary.LittleEndian.PutUint64(packet[8:16], uint64(len(packet)))
+	copy(packet[32:48], setID[:])
+	copy(packet[48:64], ptype[:])
+	copy(packet[64:], body)
+	sum := md5.Sum(packet[32:])
+	copy(packet[16:32], sum[:])
+	return packet
+}
+
+func padSlice(data []byte, sliceSize int) []byte {
+	padded := make([]byte, sliceSize)
+	copy(padded, data)
+	return padded
+}
+
+// buildSet encodes files into an index stream and a volume stream holding recovery
+// blocks for the given exponents. It also returns every input slice in set order.
+func buildSet(t *testing.T, files []testFile, sliceSize int, exponents []uint32) (index, volume []byte, slices [][]byte) {
+	t.Helper()
+
+	type described struct {
+		id   [16]byte
+		file testFile
+	}
+	var descs []described
+	for _, f := range files {
+		first := f.data
+		if len(first) > 16384 {
+			first = first[:16384]
+		}
+		hash16k := md5.Sum(first)
+		idInput := append(append([]byte{}, hash16k[:]...), make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(idInput[16:], uint64(len(f.data)))
+		idInput = append(idInput, []byte(f.name)...)
+		descs = append(descs, described{id: md5.Sum(idInput), file: f})
+	}
+	sort.Slice(descs, func(i, j int) bool { return bytes.Compare(descs[i].id[:], descs[j].id[:]) < 0 })
+
+	mainBody := make([]byte, 12)
+	binary.LittleEndian.PutUint64(mainBody[0:8], uint64(sliceSize))
+	binary.LittleEndian.PutUint32(mainBody[8:12], uint32(len(descs)))
+	for _, d := range descs {
+		mainBody = append(mainBody, d.id[:]...)
+	}
+	setID := md5.Sum(mainBody)
+
+	var idx bytes.Buffer
+	idx.Write(buildPacket(setID, typeMain, mainBody))
+	for _, d := range descs {
+		full := md5.Sum(d.file.data)
+		first := d.file.data
+		if len(first) > 16384 {
+			first = first[:16384]
+		}
+		h16 := md5.Sum(first)
+		body := append([]byte{}, d.id[:]...)
+		body = append(body, full[:]...)
+		body = append(body, h16[:]...)
+		length := make([]byte, 8)
+		binary.LittleEndian.PutUint64(length, uint64(len(d.file.data)))
+		body = append(body, length...)
+		name := []byte(d.file.name)
+		for len(name)%4 != 0 {
+			name = append(name, 0)
+		}
+		body = append(body, name...)
+		idx.Write(buildPacket(setID, typeFileDesc, body))
+
+		ifsc := append([]byte{}, d.id[:]...)
+		for off := 0; off < len(d.file.data); off += sliceSize {
+			end := off + sliceSize
+			if end > len(d.file.data) {
+				end = len(d.file.data)
+			}
+			slice := padSlice(d.file.data[off:end], sliceSize)
+			slices = append(slices, slice)
+			sum := md5.Sum(slice)
+			ifsc = append(ifsc, sum[:]...)
+			crc := make([]byte, 4)
+			binary.LittleEndian.PutUint32(crc, crc32.ChecksumIEEE(slice))
+			ifsc = append(ifsc, crc...)
+		}
+		idx.Write(buildPacket(setID, typeIFSC, ifsc))
+	}
+
+	constants := inputConstants(len(slices))
+	var vol bytes.Buffer
+	for _, e := range exponents {
+		block := make([]byte, sliceSize)
+		for i, s := range slices {
+			mulAdd(block, s, gfPow(constants[i], e))
+		}
+		body := make([]byte, 4, 4+sliceSize)
+		binary.LittleEndian.PutUint32(body, e)
+		body = append(body, block...)
+		vol.Write(buildPacket(setID, typeRecvSlic, body))
+	}
+	// Recovery volumes repeat the critical packets
+	vol.Write(idx.Bytes())
+
+	return idx.Bytes(), vol.Bytes(), slices
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	return b
+}
+
+func TestInputConstants(t *testing.T) {
+	// Base values from the PAR2 specification
+	want := []uint16{2, 4, 16, 128, 256, 2048, 8192, 16384, 4107}
+	got := inputConstants(len(want))
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("constant %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGFArithmetic(t *testing.T) {
+	for _, a := range []uint16{1, 2, 3, 0x100B, 0xFFFF} {
+		for _, b := range []uint16{1, 7, 0x8000, 0xABCD} {
+			if got := gfDiv(gfMul(a, b), b); got != a {
+				t.Fatalf("(%d*%d)/%d = %d", a, b, b, got)
+			}
+		}
+	}
+	if gfMul(0x8000, 2) != 0x100B {
+		t.Fatalf("expected reduction by the generator polynomial, got %#x", gfMul(0x8000, 2))
+	}
+}
+
+func TestRecoverySetParse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	files := []testFile{
+		{name: "movie.part01.rar", data: randomBytes(r, 1000)},
+		{name: "movie.part02.rar", data: randomBytes(r, 300)},
+	}
+	index, volume, _ := buildSet(t, files, 128, []uint32{0, 1, 2})
+
+	set := NewRecoverySet()
+	if err := set.Add(index, 0); err != nil {
+		t.Fatalf("Add(index) failed: %v", err)
+	}
+	parsed, err := set.Files()
+	if err != nil {
+		t.Fatalf("Files() failed: %v", err)
+	}
+	if set.SliceSize != 128 || len(parsed) != 2 || set.SliceCount() != 8+3 {
+		t.Fatalf("unexpected set: slice size %d, files %d, slices %d", set.SliceSize, len(parsed), set.SliceCount())
+	}
+	byName := map[string]*File{}
+	for _, f := range parsed {
+		byName[f.Name] = f
+	}
+	if f := byName["movie.part02.rar"]; f == nil || f.Length != 300 || len(f.Checksums) != 3 {
+		t.Fatalf("unexpected file description: %+v", f)
+	}
+
+	// Corrupt one recovery packet: it must be skipped without losing the others
+	damaged := append([]byte(nil), volume...)
+	damaged[packetHeaderSize+10] ^= 0xFF
+	if err := set.Add(damaged, 1); err != nil {
+		t.Fatalf("Add(volume) failed: %v", err)
+	}
+	if len(set.Blocks) != 1 || set.Blocks[0].Exponent != 1 {
+		t.Fatalf("expected only the intact block with exponent 1 (maxBlocks=1), got %+v", set.Blocks)
+	}
+
+	other, _, _ := buildSet(t, []testFile{{name: "other.mkv", data: randomBytes(r, 64)}}, 64, nil)
+	if err := set.Add(other, 0); err != ErrSetMismatch {
+		t.Fatalf("expected ErrSetMismatch, got %v", err)
+	}
+}
+
+func TestSolverRepairsDamagedSlices(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	files := []testFile{
+		{name: "a.mkv", data: randomBytes(r, 4096+100)},
+		{name: "b.nfo", data: randomBytes(r, 77)},
+	}
+	const sliceSize = 256
+	_, volume, slices := buildSet(t, files, sliceSize, []uint32{0, 1, 5, 9})
+
+	set := NewRecoverySet()
+	if err := set.Add(volume, -1); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(set.Blocks) != 4 {
+		t.Fatalf("expected 4 recovery blocks, got %d", len(set.Blocks))
+	}
+
+	damaged := []int{0, 7, len(slices) - 1}
+	solver, err := NewSolver(set.SliceSize, set.SliceCount(), damaged, set.Blocks)
+	if err != nil {
+		t.Fatalf("NewSolver failed: %v", err)
+	}
+	// Damage discovered while streaming uses the spare block
+	if err := solver.MarkDamaged(3); err != nil {
+		t.Fatalf("MarkDamaged failed: %v", err)
+	}
+	for i, s := range slices {
+		if solver.IsDamaged(i) {
+			continue
+		}
+		if err := solver.Add(i, s); err != nil {
+			t.Fatalf("Add(%d) failed: %v", i, err)
+		}
+	}
+
+	repaired, err := solver.Solve()
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if len(repaired) != 4 {
+		t.Fatalf("expected 4 repaired slices, got %d", len(repaired))
+	}
+	for idx, data := range repaired {
+		if !bytes.Equal(data, slices[idx]) {
+			t.Fatalf("slice %d was not reconstructed correctly", idx)
+		}
+	}
+}
+
+func TestSolverInsufficientRecovery(t *testing.T) {
+	blocks := []RecoveryBlock{{Exponent: 0, Data: make([]byte, 64)}}
+	if _, err := NewSolver(64, 10, []int{1, 2}, blocks); err == nil {
+		t.Fatal("expected error with fewer blocks than damaged slices")
+	}
+	solver, err := NewSolver(64, 10, []int{1}, blocks)
+	if err != nil {
+		t.Fatalf("NewSolver failed: %v", err)
+	}
+	if err := solver.MarkDamaged(2); err == nil {
+		t.Fatal("expected MarkDamaged to fail once damage exceeds recovery blocks")
+	}
+}
@@ -0,0 +1,206 @@
+package par2
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInsufficientRecovery is returned when fewer recovery blocks than damaged slices are available.
+	ErrInsufficientRecovery = errors.New("par2: not enough recovery blocks to repair damaged slices")
+	// ErrSingularMatrix is returned for the rare exponent/slice combinations PAR2 cannot solve.
+	ErrSingularMatrix = errors.New("par2: recovery matrix is singular")
+)
+
+// Solver rebuilds damaged input slices from recovery blocks.
+//
+// Every recovery block is R_e = sum(c_i^e * D_i) over all input slices. The solver
+// streams the intact slices through Add, folding each one into per-block accumulators,
+// so only the accumulators (one slice each) are held in memory regardless of the set
+// size. After the last intact slice the accumulators hold sum(c_d^e * D_d) over the
+// damaged slices alone, which Solve inverts.
+type Solver struct {
+	sliceSize int
+	constants []uint16
+	damaged   map[int]struct{}
+	added     map[int]struct{}
+	blocks    []RecoveryBlock
+	acc       [][]byte
+}
+
+// NewSolver prepares a solver for a set of sliceCount slices. blocks may hold more
+// recovery blocks than damaged slices to leave room for damage discovered while
+// streaming (see MarkDamaged).
+func NewSolver(sliceSize int64, sliceCount int, damaged []int, blocks []RecoveryBlock) (*Solver, error) {
+	if sliceSize <= 0 || sliceSize%2 != 0 {
+		return nil, fmt.Errorf("par2: invalid slice size %d", sliceSize)
+	}
+	if len(blocks) < len(damaged) {
+		return nil, fmt.Errorf("%w: %d damaged, %d blocks", ErrInsufficientRecovery, len(damaged), len(blocks))
+	}
+
+	s := &Solver{
+		sliceSize: int(sliceSize),
+		constants: inputConstants(sliceCount),
+		damaged:   make(map[int]struct{}, len(damaged)),
+		added:     make(map[int]struct{}),
+		blocks:    blocks,
+		acc:       make([][]byte, len(blocks)),
+	}
+	for _, idx := range damaged {
+		if idx < 0 || idx >= sliceCount {
+			return nil, fmt.Errorf("par2: damaged slice %d out of range", idx)
+		}
+		s.damaged[idx] = struct{}{}
+	}
+	for i, b := range blocks {
+		if len(b.Data) != s.sliceSize {
+			return nil, fmt.Errorf("par2: recovery block %d has %d bytes, want %d", b.Exponent, len(b.Data), s.sliceSize)
+		}
+		s.acc[i] = append([]byte(nil), b.Data...)
+	}
+	return s, nil
+}
+
+// IsDamaged reports whether slice index is scheduled for reconstruction.
+func (s *Solver) IsDamaged(index int) bool {
+	_, ok := s.damaged[index]
+	return ok
+}
+
+// MarkDamaged schedules a slice for reconstruction after the solver was created,
+// e.g. when its data failed the checksum. It must be called before the slice is added.
+func (s *Solver) MarkDamaged(index int) error {
+	if index < 0 || index >= len(s.constants) {
+		return fmt.Errorf("par2: damaged slice %d out of range", index)
+	}
+	if _, ok := s.added[index]; ok {
+		return fmt.Errorf("par2: slice %d was already added as intact", index)
+	}
+	s.damaged[index] = struct{}{}
+	if len(s.damaged) > len(s.blocks) {
+		return fmt.Errorf("%w: %d damaged, %d blocks", ErrInsufficientRecovery, len(s.damaged), len(s.blocks))
+	}
+	return nil
+}
+
+// Add folds an intact slice into the accumulators. Short data (the tail of a file)
+// is zero padded to the slice size, as PAR2 requires.
+func (s *Solver) Add(index int, data []byte) error {
+	if index < 0 || index >= len(s.constants) {
+		return fmt.Errorf("par2: slice %d out of range", index)
+	}
+	if _, ok := s.damaged[index]; ok {
+		return fmt.Errorf("par2: slice %d is marked damaged", index)
+	}
+	if _, ok := s.added[index]; ok {
+		return nil
+	}
+	if len(data) > s.sliceSize {
+		return fmt.Errorf("par2: slice %d has %d bytes, want at most %d", index, len(data), s.sliceSize)
+	}
+	if len(data) < s.sliceSize {
+		padded := make([]byte, s.sliceSize)
+		copy(padded, data)
+		data = padded
+	}
+
+	base := s.constants[index]
+	for i, b := range s.blocks {
+		mulAdd(s.acc[i], data, gfPow(base, b.Exponent))
+	}
+	s.added[index] = struct{}{}
+	return nil
+}
+
+// Solve reconstructs the damaged slices. Every slice not marked damaged must have
+// been added first. The result maps slice index to its zero padded data.
+func (s *Solver) Solve() (map[int][]byte, error) {
+	if missing := len(s.constants) - len(s.added) - len(s.damaged); missing > 0 {
+		return nil, fmt.Errorf("par2: %d intact slices were not added", missing)
+	}
+
+	damaged := make([]int, 0, len(s.damaged))
+	for idx := range s.damaged {
+		damaged = append(damaged, idx)
+	}
+	sort.Ints(damaged)
+
+	k := len(damaged)
+	if k == 0 {
+		return map[int][]byte{}, nil
+	}
+	if k > len(s.blocks) {
+		return nil, fmt.Errorf("%w: %d damaged, %d blocks", ErrInsufficientRecovery, k, len(s.blocks))
+	}
+
+	// matrix[r][j] = c_{damaged[j]}^{e_r} for the first k recovery blocks
+	matrix := make([][]uint16, k)
+	for r := 0; r < k; r++ {
+		matrix[r] = make([]uint16, k)
+		for j, idx := range damaged {
+			matrix[r][j] = gfPow(s.constants[idx], s.blocks[r].Exponent)
+		}
+	}
+	inverse, err := invert(matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int][]byte, k)
+	for j, idx := range damaged {
+		data := make([]byte, s.sliceSize)
+		for r := 0; r < k; r++ {
+			mulAdd(data, s.acc[r], inverse[j][r])
+		}
+		out[idx] = data
+	}
+	return out, nil
+}
+
+// invert returns the inverse of a square matrix over GF(2^16) by Gauss-Jordan elimination.
+func invert(m [][]uint16) ([][]uint16, error) {
+	n := len(m)
+	work := make([][]uint16, n)
+	inv := make([][]uint16, n)
+	for i := range m {
+		work[i] = append([]uint16(nil), m[i]...)
+		inv[i] = make([]uint16, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if work[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, ErrSingularMatrix
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		if p := work[col][col]; p != 1 {
+			for j := 0; j < n; j++ {
+				work[col][j] = gfDiv(work[col][j], p)
+				inv[col][j] = gfDiv(inv[col][j], p)
+			}
+		}
+
+		for row := 0; row < n; row++ {
+			if row == col || work[row][col] == 0 {
+				continue
+			}
+			f := work[row][col]
+			for j := 0; j < n; j++ {
+				work[row][j] ^= gfMul(f, work[col][j])
+				inv[row][j] ^= gfMul(f, inv[col][j])
+			}
+		}
+	}
+	return inv, nil
+}
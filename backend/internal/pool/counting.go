@@ -0,0 +1,119 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/javi11/nntpcli"
+)
+
+// providerCounter accumulates a count per provider ID. It outlives the pools it is handed
+// to so totals survive pool reloads.
+type providerCounter struct {
+	counts sync.Map // provider ID -> *atomic.Int64
+}
+
+func (b *providerCounter) add(providerID string, n int64) {
+	if n <= 0 {
+		return
+	}
+	v, _ := b.counts.LoadOrStore(providerID, new(atomic.Int64))
+	v.(*atomic.Int64).Add(n)
+}
+
+func (b *providerCounter) get(providerID string) int64 {
+	if v, ok := b.counts.Load(providerID); ok {
+		return v.(*atomic.Int64).Load()
+	}
+	return 0
+}
+
+// countingClient wraps the NNTP client used by nntppool so the bytes each connection
+// downloads, and the article fetches that fail on it, are attributed to its provider.
+// nntppool's own per-provider byte metrics sum every active connection regardless of
+// provider, and its errors only say that no provider had an article.
+type countingClient struct {
+	nntpcli.Client
+	bytes         *providerCounter
+	articleErrors *providerCounter
+}
+
+func (c *countingClient) Dial(ctx context.Context, host string, port int, config ...nntpcli.DialConfig) (nntpcli.Connection, error) {
+	conn, err := c.Client.Dial(ctx, host, port, config...)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Connection: conn, host: host, bytes: c.bytes, articleErrors: c.articleErrors}, nil
+}
+
+func (c *countingClient) DialTLS(ctx context.Context, host string, port int, insecureSSL bool, config ...nntpcli.DialConfig) (nntpcli.Connection, error) {
+	conn, err := c.Client.DialTLS(ctx, host, port, insecureSSL, config...)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Connection: conn, host: host, bytes: c.bytes, articleErrors: c.articleErrors}, nil
+}
+
+// countingConn learns the account from Authenticate, which nntppool calls right after
+// dialing, so its provider ID matches UsenetProviderConfig.ID().
+type countingConn struct {
+	nntpcli.Connection
+	host          string
+	username      string
+	bytes         *providerCounter
+	articleErrors *providerCounter
+}
+
+func (c *countingConn) providerID() string {
+	return fmt.Sprintf("%s_%s", c.host, c.username)
+}
+
+func (c *countingConn) Authenticate(username, password string) error {
+	c.username = username
+	return c.Connection.Authenticate(username, password)
+}
+
+func (c *countingConn) BodyDecoded(msgID string, w io.Writer, discard int64) (int64, error) {
+	n, err := c.Connection.BodyDecoded(msgID, w, discard)
+	c.bytes.add(c.providerID(), n)
+	countArticleError(c.articleErrors, c.providerID(), err)
+	return n, err
+}
+
+func (c *countingConn) BodyReader(msgID string) (nntpcli.ArticleBodyReader, error) {
+	r, err := c.Connection.BodyReader(msgID)
+	if err != nil {
+		countArticleError(c.articleErrors, c.providerID(), err)
+		return nil, err
+	}
+	return &countingReader{ArticleBodyReader: r, providerID: c.providerID(), bytes: c.bytes, articleErrors: c.articleErrors}, nil
+}
+
+type countingReader struct {
+	nntpcli.ArticleBodyReader
+	providerID    string
+	bytes         *providerCounter
+	articleErrors *providerCounter
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ArticleBodyReader.Read(p)
+	r.bytes.add(r.providerID, int64(n))
+	countArticleError(r.articleErrors, r.providerID, err)
+	return n, err
+}
+
+// countArticleError counts a failed article fetch: a missing article, a broken yEnc body
+// or a connection dropping mid-article. Reads ended by the caller don't count.
+func countArticleError(counter *providerCounter, providerID string, err error) {
+	if counter == nil || err == nil || errors.Is(err, io.EOF) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+	counter.add(providerID, 1)
+}
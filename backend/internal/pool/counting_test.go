@@ -0,0 +1,109 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/javi11/nntpcli"
+)
+
+type fakeBodyReader struct {
+	io.Reader
+}
+
+func (fakeBodyReader) Close() error { return nil }
+
+func (fakeBodyReader) GetYencHeaders() (nntpcli.YencHeaders, error) {
+	return nntpcli.YencHeaders{}, nil
+}
+
+type fakeConn struct {
+	nntpcli.Connection
+	body string
+	err  error
+}
+
+func (c *fakeConn) Authenticate(username, password string) error { return nil }
+
+func (c *fakeConn) BodyDecoded(msgID string, w io.Writer, discard int64) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := io.WriteString(w, c.body)
+	return int64(n), err
+}
+
+func (c *fakeConn) BodyReader(msgID string) (nntpcli.ArticleBodyReader, error) {
+	return fakeBodyReader{strings.NewReader(c.body)}, nil
+}
+
+type fakeClient struct {
+	nntpcli.Client
+	body string
+	err  error
+}
+
+func (c *fakeClient) DialTLS(ctx context.Context, host string, port int, insecureSSL bool, config ...nntpcli.DialConfig) (nntpcli.Connection, error) {
+	return &fakeConn{body: c.body, err: c.err}, nil
+}
+
+func TestCountingClientAttributesBytesToProvider(t *testing.T) {
+	counter := &providerCounter{}
+	cli := &countingClient{Client: &fakeClient{body: "0123456789"}, bytes: counter}
+
+	conn, err := cli.DialTLS(context.Background(), "news.example.com", 563, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Authenticate("alice", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.BodyDecoded("<a@b>", &bytes.Buffer{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	r, err := conn.BodyReader("<c@d>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := counter.get("news.example.com_alice"); got != 20 {
+		t.Fatalf("bytes for provider = %d, want 20", got)
+	}
+	if got := counter.get("news.example.com_"); got != 0 {
+		t.Fatalf("bytes for unauthenticated ID = %d, want 0", got)
+	}
+}
+
+func TestCountingClientCountsArticleErrors(t *testing.T) {
+	counter := &providerCounter{}
+	cases := []struct {
+		err  error
+		want int64
+	}{
+		{fmt.Errorf("body: %w", nntpcli.ErrArticleNotFound), 1},
+		{fmt.Errorf("read: %w", context.Canceled), 1},
+		{nil, 1},
+	}
+	for _, tc := range cases {
+		cli := &countingClient{Client: &fakeClient{body: "0123456789", err: tc.err}, bytes: &providerCounter{}, articleErrors: counter}
+		conn, err := cli.DialTLS(context.Background(), "news.example.com", 563, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Authenticate("alice", "secret"); err != nil {
+			t.Fatal(err)
+		}
+		conn.BodyDecoded("<a@b>", &bytes.Buffer{}, 0)
+		if got := counter.get("news.example.com_alice"); got != tc.want {
+			t.Fatalf("article errors after %v = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
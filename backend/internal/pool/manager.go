@@ -6,6 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"novastream/config"
+
+	"github.com/javi11/nntpcli"
 	"github.com/javi11/nntppool"
 )
 
@@ -22,17 +25,41 @@ type Manager interface {
 
 	// HasPool returns true if a pool is currently available
 	HasPool() bool
+
+	// ProviderStats returns per-provider tier and connection usage
+	ProviderStats() []ProviderStats
+}
+
+// ProviderStats holds per-provider tier and connection usage for the admin status page.
+// Request and byte counts accumulate for the lifetime of the process and survive pool reloads.
+type ProviderStats struct {
+	ID                string `json:"id"`
+	Host              string `json:"host"`
+	Username          string `json:"username"`
+	Tier              string `json:"tier"`
+	State             string `json:"state"`
+	MaxConnections    int    `json:"max_connections"`
+	OpenConnections   int32  `json:"open_connections"`
+	IdleConnections   int32  `json:"idle_connections"`
+	ActiveConnections int32  `json:"active_connections"`
+	Requests          int64  `json:"requests"`
+	BytesDownloaded   int64  `json:"bytes_downloaded"`
+	ArticleErrors     int64  `json:"article_errors"`
 }
 
 // manager implements the Manager interface
 type manager struct {
-	mu   sync.RWMutex
-	pool nntppool.UsenetConnectionPool
+	mu        sync.RWMutex
+	pool      nntppool.UsenetConnectionPool
+	providers []nntppool.UsenetProviderConfig
+	requests  map[string]int64 // requests served by pools that were replaced, keyed by provider ID
+	bytes     *providerCounter // decoded article bytes per provider ID, across pools
+	failures  *providerCounter // failed article fetches per provider ID, across pools
 }
 
 // NewManager creates a new pool manager
 func NewManager() Manager {
-	return &manager{}
+	return &manager{requests: make(map[string]int64), bytes: &providerCounter{}, failures: &providerCounter{}}
 }
 
 // GetPool returns the current connection pool or error if not available
@@ -47,7 +74,9 @@ func (m *manager) GetPool() (nntppool.UsenetConnectionPool, error) {
 	return m.pool, nil
 }
 
-// SetProviders creates/recreates the pool with new providers
+// SetProviders creates/recreates the pool with new providers.
+// Providers flagged IsBackupProvider are only used by nntppool once an article is
+// missing on the provider tried first.
 func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -55,8 +84,10 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 	// Shut down existing pool if present
 	if m.pool != nil {
 		slog.Info("Shutting down existing NNTP connection pool")
+		m.keepRequestCounts()
 		m.pool.Quit()
 		m.pool = nil
+		m.providers = nil
 	}
 
 	// Return early if no providers (clear pool scenario)
@@ -71,6 +102,7 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 	slog.Info("Creating NNTP connection pool", "provider_count", len(providers))
 	pool, err := nntppool.NewConnectionPool(nntppool.Config{
 		Providers:      providers,
+		NntpCli:        &countingClient{Client: nntpcli.New(), bytes: m.bytes, articleErrors: m.failures},
 		Logger:         slog.Default(),
 		DelayType:      nntppool.DelayTypeFixed,
 		RetryDelay:     10 * time.Millisecond,
@@ -81,6 +113,7 @@ func (m *manager) SetProviders(providers []nntppool.UsenetProviderConfig) error
 	}
 
 	m.pool = pool
+	m.providers = providers
 	slog.Info("NNTP connection pool created successfully")
 	return nil
 }
@@ -92,13 +125,23 @@ func (m *manager) ClearPool() error {
 
 	if m.pool != nil {
 		slog.Info("Clearing NNTP connection pool")
+		m.keepRequestCounts()
 		m.pool.Quit()
 		m.pool = nil
+		m.providers = nil
 	}
 
 	return nil
 }
 
+// keepRequestCounts carries the request counts of the current pool over to the next one.
+// Callers must hold the write lock.
+func (m *manager) keepRequestCounts() {
+	for _, snap := range m.pool.GetMetricsSnapshot().ProviderMetrics {
+		m.requests[snap.ProviderID] += snap.AcquireCount
+	}
+}
+
 // HasPool returns true if a pool is currently available
 func (m *manager) HasPool() bool {
 	m.mu.RLock()
@@ -106,3 +149,45 @@ func (m *manager) HasPool() bool {
 
 	return m.pool != nil
 }
+
+// ProviderStats returns per-provider tier and connection usage for the current pool.
+func (m *manager) ProviderStats() []ProviderStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.pool == nil {
+		return nil
+	}
+
+	snapshots := make(map[string]nntppool.ProviderMetricsSnapshot)
+	for _, snap := range m.pool.GetMetricsSnapshot().ProviderMetrics {
+		snapshots[snap.ProviderID] = snap
+	}
+
+	stats := make([]ProviderStats, 0, len(m.providers))
+	for _, provider := range m.providers {
+		tier := config.UsenetTierPrimary
+		if provider.IsBackupProvider {
+			tier = config.UsenetTierBackup
+		}
+		entry := ProviderStats{
+			ID:              provider.ID(),
+			Host:            provider.Host,
+			Username:        provider.Username,
+			Tier:            tier,
+			MaxConnections:  provider.MaxConnections,
+			Requests:        m.requests[provider.ID()],
+			BytesDownloaded: m.bytes.get(provider.ID()),
+			ArticleErrors:   m.failures.get(provider.ID()),
+		}
+		if snap, ok := snapshots[provider.ID()]; ok {
+			entry.State = snap.State.String()
+			entry.OpenConnections = snap.TotalConnections
+			entry.IdleConnections = snap.IdleConnections
+			entry.ActiveConnections = snap.AcquiredConnections
+			entry.Requests += snap.AcquireCount
+		}
+		stats = append(stats, entry)
+	}
+	return stats
+}
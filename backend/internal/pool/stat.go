@@ -0,0 +1,80 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+
+	"github.com/javi11/nntpcli"
+	"github.com/javi11/nntppool"
+)
+
+// statAttempts bounds how often a STAT is retried after connection errors.
+const statAttempts = 3
+
+// StatPrimaries reports whether an article exists on any primary provider. nntppool's
+// Stat may hand the command to a backup provider whenever the primaries are busy, which
+// spends backup quota on articles the primaries carry; this only asks primaries, moving
+// on to the next one when a provider doesn't have the article. Callers check the
+// articles reported missing against the backups with the pool's regular Stat.
+func StatPrimaries(ctx context.Context, cp nntppool.UsenetConnectionPool, msgID string, groups []string) (bool, error) {
+	var skip []string
+	var lastErr error
+
+	for attempt := 0; attempt < statAttempts; {
+		conn, err := cp.GetConnection(ctx, skip, false)
+		if errors.Is(err, nntppool.ErrArticleNotFoundInProviders) {
+			// Every primary was asked, or none is accepting connections.
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		found, err := statOnConnection(conn.Connection(), msgID, groups)
+		switch {
+		case err == nil && found:
+			_ = conn.Free()
+			return true, nil
+		case err == nil:
+			skip = append(skip, conn.Provider().ID())
+			_ = conn.Free()
+		default:
+			_ = conn.Close()
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			lastErr = err
+			attempt++
+		}
+	}
+	return false, fmt.Errorf("stat %s: %w", msgID, lastErr)
+}
+
+// statOnConnection reports found=false without an error when the server answers that
+// it doesn't carry the article or any of its groups.
+func statOnConnection(conn nntpcli.Connection, msgID string, groups []string) (bool, error) {
+	if len(groups) > 0 {
+		var err error
+		for _, group := range groups {
+			if err = conn.JoinGroup(group); err == nil {
+				break
+			}
+		}
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("join group: %w", err)
+		}
+	}
+	if _, err := conn.Stat(msgID); err != nil {
+		if nntpcli.IsArticleNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
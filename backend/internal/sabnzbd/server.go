@@ -0,0 +1,466 @@
+package sabnzbd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+// APIVersion is the SABnzbd version reported to clients. Sonarr and Radarr refuse
+// versions they consider too old, so this tracks a current SABnzbd release.
+const APIVersion = "4.3.3"
+
+const (
+	nzoIDPrefix     = "SABnzbd_nzo_"
+	defaultCategory = "*"
+	maxNZBSize      = 100 << 20
+)
+
+// Importer is the part of the import service the download API drives
+type Importer interface {
+	AddNZBToQueue(ctx context.Context, fileName string, nzbBytes []byte, category *string, priority *database.QueuePriority) (*database.ImportQueueItem, error)
+	ListCategorizedQueueItems(statuses []database.QueueStatus, limit int) ([]*database.ImportQueueItem, error)
+	RemoveQueueItem(id int64) (bool, error)
+}
+
+// Server exposes the import queue through the SABnzbd API so Sonarr and Radarr can use
+// novastream as a download client. Jobs complete as soon as the NZB is imported into the
+// virtual filesystem, and are reported below the configured WebDAV mount point.
+type Server struct {
+	importer   Importer
+	cfgManager *config.Manager
+	httpClient *http.Client
+}
+
+// NewServer creates a SABnzbd API server backed by the import queue
+func NewServer(importer Importer, cfgManager *config.Manager) *Server {
+	return &Server{
+		importer:   importer,
+		cfgManager: cfgManager,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// ServeHTTP handles /sabnzbd/api requests. Like SABnzbd, errors are reported with
+// status false in a 200 response.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	settings, err := s.cfgManager.Load()
+	if err != nil {
+		writeError(w, "failed to load settings")
+		return
+	}
+	cfg := settings.SABnzbd
+	if cfg.APIKey == "" {
+		writeError(w, "SABnzbd API is disabled")
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxNZBSize); err != nil && err != http.ErrNotMultipart {
+		writeError(w, "invalid request body")
+		return
+	}
+
+	mode := r.FormValue("mode")
+	if mode == "version" {
+		writeJSON(w, map[string]string{"version": APIVersion})
+		return
+	}
+
+	apiKey := r.FormValue("apikey")
+	if apiKey == "" {
+		writeError(w, "API Key Required")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(cfg.APIKey)) != 1 {
+		writeError(w, "API Key Incorrect")
+		return
+	}
+
+	switch mode {
+	case "auth":
+		writeJSON(w, map[string]string{"auth": "apikey"})
+	case "addfile":
+		s.addFile(w, r, cfg)
+	case "addurl":
+		s.addURL(w, r, cfg)
+	case "queue":
+		s.queue(w, r)
+	case "history":
+		s.history(w, r, cfg)
+	case "get_config":
+		writeJSON(w, map[string]interface{}{"config": buildConfig(cfg)})
+	case "get_cats":
+		writeJSON(w, map[string]interface{}{"categories": categoryNames(cfg)})
+	case "fullstatus":
+		writeJSON(w, map[string]interface{}{
+			"status": map[string]interface{}{"completedir": cfg.CompleteDir, "paused": false},
+		})
+	default:
+		writeError(w, "not implemented")
+	}
+}
+
+func (s *Server) addFile(w http.ResponseWriter, r *http.Request, cfg config.SABnzbdSettings) {
+	file, header, err := r.FormFile("name")
+	if err != nil {
+		file, header, err = r.FormFile("nzbfile")
+	}
+	if err != nil {
+		writeError(w, "expects one parameter")
+		return
+	}
+	defer file.Close()
+
+	nzbBytes, err := io.ReadAll(io.LimitReader(file, maxNZBSize))
+	if err != nil {
+		writeError(w, "failed to read NZB")
+		return
+	}
+
+	s.enqueue(w, r, cfg, header.Filename, nzbBytes)
+}
+
+func (s *Server) addURL(w http.ResponseWriter, r *http.Request, cfg config.SABnzbdSettings) {
+	nzbURL := r.FormValue("name")
+	parsed, err := url.Parse(nzbURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		writeError(w, "expects one parameter")
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, nzbURL, nil)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to fetch NZB: %v", err))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		writeError(w, fmt.Sprintf("failed to fetch NZB: HTTP %d", resp.StatusCode))
+		return
+	}
+	nzbBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxNZBSize))
+	if err != nil {
+		writeError(w, fmt.Sprintf("failed to fetch NZB: %v", err))
+		return
+	}
+
+	fileName := path.Base(parsed.Path)
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		fileName = params["filename"]
+	}
+	s.enqueue(w, r, cfg, fileName, nzbBytes)
+}
+
+func (s *Server) enqueue(w http.ResponseWriter, r *http.Request, cfg config.SABnzbdSettings, fileName string, nzbBytes []byte) {
+	if name := strings.TrimSpace(r.FormValue("nzbname")); name != "" {
+		fileName = name
+	}
+	if !strings.HasSuffix(strings.ToLower(fileName), ".nzb") {
+		fileName += ".nzb"
+	}
+
+	category := resolveCategory(cfg, r.FormValue("cat"))
+	priority := parsePriority(r.FormValue("priority"))
+
+	item, err := s.importer.AddNZBToQueue(r.Context(), fileName, nzbBytes, &category, &priority)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":  true,
+		"nzo_ids": []string{formatNzoID(item.ID)},
+	})
+}
+
+func (s *Server) queue(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("name") == "delete" {
+		s.remove(w, r.FormValue("value"))
+		return
+	}
+
+	items, err := s.importer.ListCategorizedQueueItems([]database.QueueStatus{
+		database.QueueStatusPending, database.QueueStatusProcessing, database.QueueStatusRetrying,
+	}, 0)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	// Report jobs in the order workers claim them
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority < items[j].Priority
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	slots := make([]map[string]interface{}, 0, len(items))
+	status := "Idle"
+	for i, item := range items {
+		mb := fmt.Sprintf("%.2f", float64(itemSize(item))/(1<<20))
+		slotStatus := "Queued"
+		if item.Status == database.QueueStatusProcessing {
+			slotStatus = "Downloading"
+			status = "Downloading"
+		}
+		slots = append(slots, map[string]interface{}{
+			"index":      i,
+			"nzo_id":     formatNzoID(item.ID),
+			"filename":   jobName(item),
+			"cat":        displayCategory(item),
+			"priority":   formatPriority(item.Priority),
+			"status":     slotStatus,
+			"mb":         mb,
+			"mbleft":     mb,
+			"percentage": "0",
+			"timeleft":   "0:00:00",
+		})
+	}
+	if status == "Idle" && len(slots) > 0 {
+		status = "Queued"
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"queue": map[string]interface{}{
+			"status":     status,
+			"paused":     false,
+			"noofslots":  len(slots),
+			"slots":      slots,
+			"speedlimit": "100",
+			"kbpersec":   "0",
+			"timeleft":   "0:00:00",
+		},
+	})
+}
+
+func (s *Server) history(w http.ResponseWriter, r *http.Request, cfg config.SABnzbdSettings) {
+	if r.FormValue("name") == "delete" {
+		s.remove(w, r.FormValue("value"))
+		return
+	}
+
+	// Categories are matched by their display name, so a category filter applies the
+	// limit to the filtered slots instead of the query
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	category := r.FormValue("category")
+	queryLimit := limit
+	if category != "" {
+		queryLimit = 0
+	}
+	items, err := s.importer.ListCategorizedQueueItems([]database.QueueStatus{
+		database.QueueStatusCompleted, database.QueueStatusFailed,
+	}, queryLimit)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+
+	slots := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if limit > 0 && len(slots) >= limit {
+			break
+		}
+		if category != "" && !strings.EqualFold(category, displayCategory(item)) {
+			continue
+		}
+
+		slot := map[string]interface{}{
+			"nzo_id":        formatNzoID(item.ID),
+			"name":          jobName(item),
+			"nzb_name":      path.Base(strings.ReplaceAll(item.NzbPath, "\\", "/")),
+			"category":      displayCategory(item),
+			"bytes":         itemSize(item),
+			"status":        "Completed",
+			"fail_message":  "",
+			"storage":       "",
+			"completed":     item.UpdatedAt.Unix(),
+			"download_time": 0,
+		}
+		if item.CompletedAt != nil {
+			slot["completed"] = item.CompletedAt.Unix()
+		}
+		if item.StartedAt != nil && item.CompletedAt != nil {
+			slot["download_time"] = int64(item.CompletedAt.Sub(*item.StartedAt).Seconds())
+		}
+		if item.Status == database.QueueStatusFailed {
+			slot["status"] = "Failed"
+			if item.ErrorMessage != nil {
+				slot["fail_message"] = *item.ErrorMessage
+			}
+		} else if item.StoragePath != nil {
+			slot["storage"] = completedPath(cfg.CompleteDir, *item.StoragePath)
+		}
+		slots = append(slots, slot)
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"history": map[string]interface{}{
+			"noofslots": len(slots),
+			"slots":     slots,
+		},
+	})
+}
+
+// remove deletes the comma-separated jobs in value. Completed imports stay in the
+// virtual filesystem; only the job is forgotten.
+func (s *Server) remove(w http.ResponseWriter, value string) {
+	removed := false
+	for _, raw := range strings.Split(value, ",") {
+		id, ok := parseNzoID(raw)
+		if !ok {
+			continue
+		}
+		deleted, err := s.importer.RemoveQueueItem(id)
+		if err != nil {
+			writeError(w, err.Error())
+			return
+		}
+		removed = removed || deleted
+	}
+	writeJSON(w, map[string]interface{}{"status": removed})
+}
+
+func buildConfig(cfg config.SABnzbdSettings) map[string]interface{} {
+	categories := []map[string]interface{}{
+		{"name": defaultCategory, "order": 0, "pp": "3", "script": "None", "dir": "", "priority": -100},
+	}
+	for i, name := range cfg.Categories {
+		categories = append(categories, map[string]interface{}{
+			"name": name, "order": i + 1, "pp": "", "script": "Default", "dir": name, "priority": -100,
+		})
+	}
+
+	return map[string]interface{}{
+		"misc": map[string]interface{}{
+			"complete_dir":         cfg.CompleteDir,
+			"download_dir":         cfg.CompleteDir,
+			"pre_check":            false,
+			"history_retention":    "",
+			"enable_tv_sorting":    false,
+			"enable_movie_sorting": false,
+			"enable_date_sorting":  false,
+			"tv_categories":        []string{},
+			"movie_categories":     []string{},
+			"date_categories":      []string{},
+		},
+		"categories": categories,
+		"sorters":    []interface{}{},
+	}
+}
+
+func categoryNames(cfg config.SABnzbdSettings) []string {
+	return append([]string{defaultCategory}, cfg.Categories...)
+}
+
+// resolveCategory maps the requested category onto a configured one. Like SABnzbd,
+// unknown categories fall back to the default category, stored as "".
+func resolveCategory(cfg config.SABnzbdSettings, requested string) string {
+	requested = strings.TrimSpace(requested)
+	for _, name := range cfg.Categories {
+		if strings.EqualFold(name, requested) {
+			return name
+		}
+	}
+	return ""
+}
+
+func displayCategory(item *database.ImportQueueItem) string {
+	if item.Category == nil || *item.Category == "" {
+		return defaultCategory
+	}
+	return *item.Category
+}
+
+// parsePriority converts a SABnzbd priority (-1 low, 0 normal, 1 high, 2 force) to a
+// queue priority. Paused (-2) and default (-100) queue normally.
+func parsePriority(value string) database.QueuePriority {
+	switch strings.TrimSpace(value) {
+	case "1", "2":
+		return database.QueuePriorityHigh
+	case "-1":
+		return database.QueuePriorityLow
+	default:
+		return database.QueuePriorityNormal
+	}
+}
+
+func formatPriority(priority database.QueuePriority) string {
+	switch priority {
+	case database.QueuePriorityHigh:
+		return "High"
+	case database.QueuePriorityLow:
+		return "Low"
+	default:
+		return "Normal"
+	}
+}
+
+func formatNzoID(id int64) string {
+	return nzoIDPrefix + strconv.FormatInt(id, 10)
+}
+
+func parseNzoID(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, nzoIDPrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(value, nzoIDPrefix), 10, 64)
+	return id, err == nil
+}
+
+func jobName(item *database.ImportQueueItem) string {
+	name := path.Base(strings.ReplaceAll(item.NzbPath, "\\", "/"))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func itemSize(item *database.ImportQueueItem) int64 {
+	if item.FileSize == nil {
+		return 0
+	}
+	return *item.FileSize
+}
+
+// completedPath places a virtual filesystem path below the WebDAV mount on the client host
+func completedPath(completeDir, storagePath string) string {
+	if completeDir == "" {
+		return storagePath
+	}
+	sep := "/"
+	if strings.Contains(completeDir, "\\") && !strings.Contains(completeDir, "/") {
+		sep = "\\"
+		storagePath = strings.ReplaceAll(storagePath, "/", "\\")
+	}
+	return strings.TrimRight(completeDir, "/\\") + sep + strings.TrimLeft(storagePath, "/\\")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, message string) {
+	writeJSON(w, map[string]interface{}{"status": false, "error": message})
+}
@@ -0,0 +1,198 @@
+package sabnzbd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+type fakeImporter struct {
+	added   []*database.ImportQueueItem
+	items   []*database.ImportQueueItem
+	removed []int64
+}
+
+func (f *fakeImporter) AddNZBToQueue(ctx context.Context, fileName string, nzbBytes []byte, category *string, priority *database.QueuePriority) (*database.ImportQueueItem, error) {
+	item := &database.ImportQueueItem{
+		ID:       int64(len(f.added) + 1),
+		NzbPath:  filepath.Join("/tmp/novastream-nzbs/1", *category, fileName),
+		Category: category,
+		Priority: *priority,
+		Status:   database.QueueStatusPending,
+	}
+	f.added = append(f.added, item)
+	return item, nil
+}
+
+func (f *fakeImporter) ListCategorizedQueueItems(statuses []database.QueueStatus, limit int) ([]*database.ImportQueueItem, error) {
+	var out []*database.ImportQueueItem
+	for _, item := range f.items {
+		for _, status := range statuses {
+			if item.Status == status {
+				out = append(out, item)
+			}
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeImporter) RemoveQueueItem(id int64) (bool, error) {
+	f.removed = append(f.removed, id)
+	return true, nil
+}
+
+func newTestServer(t *testing.T) (*Server, *fakeImporter) {
+	t.Helper()
+
+	cfg := config.DefaultSettings()
+	cfg.SABnzbd.APIKey = "secret"
+	cfg.SABnzbd.CompleteDir = "/mnt/novastream"
+
+	mgr := config.NewManager(filepath.Join(t.TempDir(), "settings.json"))
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("save cfg: %v", err)
+	}
+
+	importer := &fakeImporter{}
+	return NewServer(importer, mgr), importer
+}
+
+func call(t *testing.T, srv *Server, req *http.Request) map[string]interface{} {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+func TestServerRequiresAPIKey(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	body := call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=version", nil))
+	if body["version"] != APIVersion {
+		t.Fatalf("version should not need a key, got %v", body)
+	}
+
+	body = call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=queue&apikey=wrong", nil))
+	if body["status"] != false || body["error"] != "API Key Incorrect" {
+		t.Fatalf("expected key error, got %v", body)
+	}
+}
+
+func TestServerAddFile(t *testing.T) {
+	srv, importer := newTestServer(t)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, _ := writer.CreateFormFile("name", "Show.S01E01.nzb")
+	part.Write([]byte("<nzb></nzb>"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/sabnzbd/api?mode=addfile&apikey=secret&cat=TV&priority=1", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	body := call(t, srv, req)
+
+	ids, _ := body["nzo_ids"].([]interface{})
+	if body["status"] != true || len(ids) != 1 || ids[0] != "SABnzbd_nzo_1" {
+		t.Fatalf("unexpected response %v", body)
+	}
+	item := importer.added[0]
+	if *item.Category != "tv" || item.Priority != database.QueuePriorityHigh {
+		t.Fatalf("unexpected queue item %+v", item)
+	}
+
+	// Unknown categories fall back to the default category
+	buf.Reset()
+	writer = multipart.NewWriter(&buf)
+	part, _ = writer.CreateFormFile("name", "Other.nzb")
+	part.Write([]byte("<nzb></nzb>"))
+	writer.Close()
+	req = httptest.NewRequest(http.MethodPost, "/sabnzbd/api?mode=addfile&apikey=secret&cat=music", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	call(t, srv, req)
+	if *importer.added[1].Category != "" || importer.added[1].Priority != database.QueuePriorityNormal {
+		t.Fatalf("unexpected queue item %+v", importer.added[1])
+	}
+}
+
+func TestServerQueueAndHistory(t *testing.T) {
+	srv, importer := newTestServer(t)
+
+	tv, empty := "tv", ""
+	size := int64(2 << 20)
+	storage := "/tv/Show.S01E01/Show.S01E01.mkv"
+	failure := "no valid files"
+	now := time.Now()
+	importer.items = []*database.ImportQueueItem{
+		{ID: 1, NzbPath: "/tmp/novastream-nzbs/1/tv/Queued.nzb", Category: &tv, Priority: database.QueuePriorityNormal, Status: database.QueueStatusPending, FileSize: &size, CreatedAt: now},
+		{ID: 2, NzbPath: "/tmp/novastream-nzbs/2/tv/Show.S01E01.nzb", Category: &tv, Status: database.QueueStatusCompleted, StoragePath: &storage, StartedAt: &now, CompletedAt: &now},
+		{ID: 3, NzbPath: "/tmp/novastream-nzbs/3/Broken.nzb", Category: &empty, Status: database.QueueStatusFailed, ErrorMessage: &failure},
+	}
+
+	body := call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=queue&apikey=secret", nil))
+	queue := body["queue"].(map[string]interface{})
+	slots := queue["slots"].([]interface{})
+	if len(slots) != 1 {
+		t.Fatalf("expected one queue slot, got %v", queue)
+	}
+	slot := slots[0].(map[string]interface{})
+	if slot["nzo_id"] != "SABnzbd_nzo_1" || slot["filename"] != "Queued" || slot["cat"] != "tv" || slot["mb"] != "2.00" {
+		t.Fatalf("unexpected queue slot %v", slot)
+	}
+
+	body = call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=history&apikey=secret", nil))
+	slots = body["history"].(map[string]interface{})["slots"].([]interface{})
+	if len(slots) != 2 {
+		t.Fatalf("expected two history slots, got %v", slots)
+	}
+	completed := slots[0].(map[string]interface{})
+	if completed["status"] != "Completed" || completed["storage"] != "/mnt/novastream/tv/Show.S01E01/Show.S01E01.mkv" || completed["name"] != "Show.S01E01" {
+		t.Fatalf("unexpected completed slot %v", completed)
+	}
+	failed := slots[1].(map[string]interface{})
+	if failed["status"] != "Failed" || failed["fail_message"] != failure || failed["category"] != "*" {
+		t.Fatalf("unexpected failed slot %v", failed)
+	}
+
+	// The limit counts slots of the requested category, not every history item
+	body = call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=history&category=*&limit=1&apikey=secret", nil))
+	slots = body["history"].(map[string]interface{})["slots"].([]interface{})
+	if len(slots) != 1 || slots[0].(map[string]interface{})["nzo_id"] != "SABnzbd_nzo_3" {
+		t.Fatalf("expected the failed slot of category *, got %v", slots)
+	}
+
+	body = call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=history&name=delete&value=SABnzbd_nzo_3,bogus&apikey=secret", nil))
+	if body["status"] != true || len(importer.removed) != 1 || importer.removed[0] != 3 {
+		t.Fatalf("unexpected delete result %v (removed %v)", body, importer.removed)
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	body := call(t, srv, httptest.NewRequest(http.MethodGet, "/sabnzbd/api?mode=get_config&apikey=secret", nil))
+	cfg := body["config"].(map[string]interface{})
+	if cfg["misc"].(map[string]interface{})["complete_dir"] != "/mnt/novastream" {
+		t.Fatalf("unexpected misc config %v", cfg["misc"])
+	}
+	categories := cfg["categories"].([]interface{})
+	if len(categories) != 3 || categories[0].(map[string]interface{})["name"] != "*" || categories[1].(map[string]interface{})["dir"] != "movies" {
+		t.Fatalf("unexpected categories %v", categories)
+	}
+}
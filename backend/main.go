@@ -21,16 +21,20 @@ import (
 	"novastream/internal/database"
 	"novastream/internal/integration"
 	"novastream/internal/pool"
+	"novastream/internal/sabnzbd"
 	"novastream/internal/webdav"
 	"novastream/services/accounts"
 	"novastream/services/debrid"
+	"novastream/services/dvr"
 	"novastream/services/history"
 	"novastream/services/indexer"
 	"novastream/services/invitations"
 	"novastream/services/metadata"
+	"novastream/services/notifications"
 	"novastream/services/playback"
 	"novastream/services/plex"
 	"novastream/services/sessions"
+	"novastream/services/subtitles"
 	"novastream/services/trakt"
 	"novastream/services/usenet"
 	user_settings "novastream/services/user_settings"
@@ -38,7 +42,12 @@ import (
 	"novastream/services/clients"
 	client_settings "novastream/services/client_settings"
 	content_preferences "novastream/services/content_preferences"
+	release_choices "novastream/services/release_choices"
+	season_packs "novastream/services/season_packs"
+	content_restrictions "novastream/services/content_restrictions"
 	"novastream/services/scheduler"
+	release_blocklist "novastream/services/release_blocklist"
+	stream_history "novastream/services/stream_history"
 	"novastream/services/watchlist"
 	"novastream/utils"
 
@@ -100,6 +109,14 @@ func main() {
 		settings.Server.Port = *portOverride
 	}
 
+	// Webhook notifications subscribe to server events before any subsystem starts publishing
+	notificationsService, err := notifications.NewService(settings.Cache.Directory, cfgManager)
+	if err != nil {
+		log.Fatalf("failed to initialise notifications: %v", err)
+	}
+	notificationsService.Start()
+	notificationsService.StartDebridExpiryWatcher()
+
 	// Construct router
 	var r *mux.Router = utils.NewRouter()
 
@@ -184,6 +201,7 @@ func main() {
 		Salt:                "", // Not used
 		MaxProcessorWorkers: 2,
 		MaxDownloadWorkers:  settings.Streaming.MaxDownloadWorkers,
+		Par2CachePath:       filepath.Join(settings.Cache.Directory, "par2"),
 	}
 
 	nzbSystem, err := integration.NewNzbSystem(nzbSystemConfig, poolManager, configAdapter.GetConfigGetter())
@@ -227,7 +245,38 @@ func main() {
 	}
 
 	playbackService := playback.NewService(cfgManager, usenetService, nzbSystem, nzbSystem.MetadataReader())
+
+	// Indexer quotas: usage counters live in the queue database, grabs are counted where NZBs are fetched
+	indexerService.SetUsageRepository(database.NewIndexerUsageRepository(nzbSystem.Database().Connection()))
+	playbackService.SetGrabTracker(indexerService)
+	usenetService.SetGrabTracker(indexerService)
+
+	// Debrid cache availability is remembered across searches, health checks and restarts
+	debrid.SetCacheIndexRepository(database.NewDebridCacheRepository(nzbSystem.Database().Connection()), cfgManager)
+	debrid.SetTorrentRepository(database.NewDebridTorrentRepository(nzbSystem.Database().Connection()))
+
+	// Releases that fail playback are blocklisted per title and ranked last (or dropped) by searches
+	releaseBlocklist, err := release_blocklist.NewService(database.NewReleaseBlocklistRepository(nzbSystem.Database().Connection()), cfgManager)
+	if err != nil {
+		log.Fatalf("failed to initialise release blocklist: %v", err)
+	}
+	if _, err := releaseBlocklist.Prune(); err != nil {
+		log.Printf("release blocklist prune failed: %v", err)
+	}
+	indexerService.SetReleaseBlocklist(releaseBlocklist)
+
+	// Season packs resolved per series, so later episodes play from them without a new search
+	seasonPacks, err := season_packs.NewService(database.NewSeasonPackRepository(nzbSystem.Database().Connection()))
+	if err != nil {
+		log.Fatalf("failed to initialise season pack index: %v", err)
+	}
+	if _, err := seasonPacks.Prune(); err != nil {
+		log.Printf("season pack prune failed: %v", err)
+	}
+
 	playbackHandler := handlers.NewPlaybackHandler(playbackService)
+	playbackHandler.SetReleaseFailureRecorder(releaseBlocklist)
+	playbackHandler.SetSeasonPackIndex(seasonPacks)
 	// Prequeue handler will be created later after historyService is available
 	var prequeueHandler *handlers.PrequeueHandler
 	usenetHandler := handlers.NewUsenetHandler(usenetService)
@@ -273,6 +322,22 @@ func main() {
 	}
 	contentPreferencesHandler := handlers.NewContentPreferencesHandler(contentPreferencesService, userService)
 
+	// Initialize release choices so resume and next episode replay the release a profile last played
+	releaseChoicesService, err := release_choices.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise release choices: %v", err)
+	}
+	playbackHandler.SetReleaseChoiceStore(releaseChoicesService)
+
+	// Initialize blocked attempts log and kids profile rating enforcement
+	blockedAttemptsService, err := content_restrictions.NewService(settings.Cache.Directory)
+	if err != nil {
+		log.Fatalf("failed to initialise content restrictions: %v", err)
+	}
+	contentRestrictions := handlers.NewContentRestrictions(metadataService, userService, blockedAttemptsService)
+	metadataHandler.SetContentRestrictions(contentRestrictions)
+	playbackHandler.SetContentRestrictions(contentRestrictions)
+
 	// Initialize clients service for device tracking
 	clientsService, err := clients.NewService(settings.Cache.Directory)
 	if err != nil {
@@ -312,6 +377,7 @@ func main() {
 	// Create prequeue handler now that history service is available
 	// Video prober and HLS creator are optional - we'll set them after videoHandler is created
 	prequeueHandler = handlers.NewPrequeueHandler(indexerService, playbackService, historyService, nil, nil, *demoMode)
+	prequeueHandler.SetContentRestrictions(contentRestrictions)
 
 	if settings.Transmux.FFmpegPath == "" {
 		settings.Transmux.FFmpegPath = "ffmpeg"
@@ -350,6 +416,9 @@ func main() {
 		prequeueHandler.SetClientSettingsService(clientSettingsService)
 		prequeueHandler.SetConfigManager(cfgManager)
 		prequeueHandler.SetMetadataService(metadataService) // For episode counting in pack size filtering
+		prequeueHandler.SetReleaseFailureRecorder(releaseBlocklist)
+		prequeueHandler.SetReleaseChoiceStore(releaseChoicesService)
+		prequeueHandler.SetSeasonPackIndex(seasonPacks)
 
 		// Wire up subtitle pre-extraction for direct streaming (SDR content)
 		if subtitleMgr := videoHandler.GetSubtitleExtractManager(); subtitleMgr != nil {
@@ -358,6 +427,14 @@ func main() {
 			playbackHandler.SetVideoProber(videoHandler) // For probing subtitle streams
 			log.Printf("[main] Subtitle pre-extraction configured for prequeue and playback handlers")
 		}
+
+		// Let HLS sessions fail over to the next ranked release when their source keeps breaking
+		if hlsManager := videoHandler.GetHLSManager(); hlsManager != nil {
+			hlsManager.SetSourceResolver(playbackService)
+			hlsManager.SetReleaseFailureRecorder(releaseBlocklist)
+			prequeueHandler.SetAlternativesRecorder(hlsManager)
+			playbackHandler.SetAlternativesRecorder(hlsManager)
+		}
 		log.Printf("[main] Prequeue handler configured with video prober, HLS creator, full prober, user settings, client settings, config, and metadata")
 
 		// Configure video handler with user settings for HDR/DV policy checks
@@ -368,8 +445,13 @@ func main() {
 
 	liveHandler := handlers.NewLiveHandler(nil, settings.Transmux.Enabled, settings.Transmux.FFmpegPath, settings.Live.PlaylistCacheTTLHours, settings.Live.ProbeSizeMB, settings.Live.AnalyzeDurationSec, settings.Live.LowLatency, cfgManager)
 
-	// Create subtitles handler for external subtitle search
-	subtitlesHandler := handlers.NewSubtitlesHandlerWithConfig(cfgManager)
+	// Create subtitles handler for external subtitle search, hashing streams through the
+	// composite provider so results match the release being played
+	subtitleCache, err := subtitles.NewCache(filepath.Join(settings.Cache.Directory, "subtitles"))
+	if err != nil {
+		log.Fatalf("failed to initialise subtitle cache: %v", err)
+	}
+	subtitlesHandler := handlers.NewSubtitlesHandler(subtitles.NewService(cfgManager, compositeProvider, subtitleCache))
 
 	// Create image proxy handler for resizing and caching TMDB images
 	imageHandler := handlers.NewImageHandler(settings.Cache.Directory)
@@ -404,12 +486,32 @@ func main() {
 	traktAccountsHandler := handlers.NewTraktAccountsHandler(cfgManager, traktClient, userService, accountsService)
 	api.RegisterTraktRoutes(r, traktAccountsHandler, sessionsService)
 
+	// Failed release blocklist, viewable by all accounts and cleared by the master account
+	releaseBlocklistHandler := handlers.NewReleaseBlocklistHandler(releaseBlocklist)
+	api.RegisterReleaseBlocklistRoutes(r, releaseBlocklistHandler, sessionsService)
+
+	// Prometheus metrics for Grafana dashboards
+	api.RegisterMetricsRoutes(r, cfgManager)
+	api.RegisterMetricCollectors(videoHandler.GetHLSManager(), poolManager, nzbSystem)
+
 	// Create Plex client and register Plex accounts handler
 	plexClient := plex.NewClient(plex.GenerateClientID())
 	plexAccountsHandler := handlers.NewPlexAccountsHandler(cfgManager, plexClient, userService, accountsService)
 
 	// Create scheduler service for background tasks
 	schedulerService := scheduler.NewService(cfgManager, plexClient, traktClient, watchlistService)
+	schedulerService.SetEPGRefresher(liveHandler)
+	schedulerService.SetDebridHousekeeper(debrid.NewHousekeeper(cfgManager))
+
+	// Live TV recordings are armed by the scheduler loop and served under the profile routes
+	dvrService, err := dvr.NewService(settings.Cache.Directory, cfgManager, settings.Transmux.FFmpegPath)
+	if err != nil {
+		log.Fatalf("failed to initialise dvr service: %v", err)
+	}
+	dvrService.SetChannelResolver(liveHandler)
+	schedulerService.SetRecordingRunner(dvrService)
+	dvrHandler := handlers.NewDVRHandler(dvrService, liveHandler, historyService)
+	api.RegisterDVRRoutes(r, dvrHandler, sessionsService, userService)
 	scheduledTasksHandler := handlers.NewScheduledTasksHandler(cfgManager, schedulerService)
 
 	// Register admin UI routes
@@ -422,6 +524,8 @@ func main() {
 	adminUIHandler.SetSessionsService(sessionsService)
 	adminUIHandler.SetClientsService(clientsService)
 	adminUIHandler.SetClientSettingsService(clientSettingsService)
+	adminUIHandler.SetBlockedAttemptsService(blockedAttemptsService)
+	adminUIHandler.SetPoolManager(poolManager)
 
 	// Login/logout routes (no auth required)
 	r.HandleFunc("/admin/login", adminUIHandler.LoginPage).Methods(http.MethodGet)
@@ -455,6 +559,9 @@ func main() {
 	r.HandleFunc("/admin/api/search", adminUIHandler.RequireAuth(metadataHandler.Search)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/metadata/series/details", adminUIHandler.RequireAuth(metadataHandler.SeriesDetails)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/indexers/search", adminUIHandler.RequireAuth(indexerHandler.Search)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/indexers/usage", adminUIHandler.RequireMasterAuth(indexerHandler.Usage)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/blocklist", adminUIHandler.RequireAuth(releaseBlocklistHandler.List)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/blocklist", adminUIHandler.RequireMasterAuth(releaseBlocklistHandler.Delete)).Methods(http.MethodDelete)
 
 	// Provider test endpoints
 	r.HandleFunc("/admin/api/test/indexer", adminUIHandler.RequireAuth(adminUIHandler.TestIndexer)).Methods(http.MethodPost)
@@ -463,6 +570,26 @@ func main() {
 	r.HandleFunc("/admin/api/test/debrid-provider", adminUIHandler.RequireAuth(adminUIHandler.TestDebridProvider)).Methods(http.MethodPost)
 	r.HandleFunc("/admin/api/test/subtitles", adminUIHandler.RequireAuth(adminUIHandler.TestSubtitles)).Methods(http.MethodPost)
 
+	// Webhook notifications
+	notificationsHandler := handlers.NewNotificationsHandler(notificationsService)
+	r.HandleFunc("/admin/api/test/webhook", adminUIHandler.RequireMasterAuth(notificationsHandler.TestWebhook)).Methods(http.MethodPost)
+	r.HandleFunc("/admin/api/webhooks/deliveries", adminUIHandler.RequireMasterAuth(notificationsHandler.ListDeliveries)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/webhooks/deliveries", adminUIHandler.RequireMasterAuth(notificationsHandler.ClearDeliveries)).Methods(http.MethodDelete)
+
+	// Stream session history: finished playbacks are recorded by the playback notifier
+	streamHistoryService, err := stream_history.NewService(database.NewStreamSessionRepository(nzbSystem.Database().Connection()), cfgManager)
+	if err != nil {
+		log.Fatalf("failed to initialise stream history: %v", err)
+	}
+	if _, err := streamHistoryService.Prune(); err != nil {
+		log.Printf("stream history prune failed: %v", err)
+	}
+	handlers.SetStreamSessionRecorder(streamHistoryService)
+	streamHistoryHandler := handlers.NewStreamHistoryHandler(streamHistoryService, clientsService)
+	r.HandleFunc("/admin/api/streams/history", adminUIHandler.RequireMasterAuth(streamHistoryHandler.ListSessions)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/api/streams/history", adminUIHandler.RequireMasterAuth(streamHistoryHandler.ClearSessions)).Methods(http.MethodDelete)
+	r.HandleFunc("/admin/api/streams/history/stats", adminUIHandler.RequireMasterAuth(streamHistoryHandler.GetStats)).Methods(http.MethodGet)
+
 	// Profile management endpoints
 	r.HandleFunc("/admin/api/profiles", adminUIHandler.RequireAuth(adminUIHandler.GetProfiles)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/profiles", adminUIHandler.RequireAuth(adminUIHandler.CreateProfile)).Methods(http.MethodPost)
@@ -472,6 +599,7 @@ func main() {
 	r.HandleFunc("/admin/api/profiles/pin", adminUIHandler.RequireAuth(adminUIHandler.ClearProfilePin)).Methods(http.MethodDelete)
 	r.HandleFunc("/admin/api/profiles/color", adminUIHandler.RequireAuth(adminUIHandler.SetProfileColor)).Methods(http.MethodPut)
 	r.HandleFunc("/admin/api/profiles/kids", adminUIHandler.RequireAuth(adminUIHandler.SetKidsProfile)).Methods(http.MethodPut)
+	r.HandleFunc("/admin/api/profiles/blocked", adminUIHandler.RequireAuth(adminUIHandler.GetBlockedAttempts)).Methods(http.MethodGet)
 	r.HandleFunc("/admin/api/profiles/icon", adminUIHandler.RequireAuth(adminUIHandler.SetProfileIcon)).Methods(http.MethodPut)
 	r.HandleFunc("/admin/api/profiles/icon", adminUIHandler.RequireAuth(adminUIHandler.ClearProfileIcon)).Methods(http.MethodDelete)
 	r.HandleFunc("/admin/api/profiles/icon", adminUIHandler.RequireAuth(adminUIHandler.ServeProfileIcon)).Methods(http.MethodGet)
@@ -613,6 +741,7 @@ func main() {
 	r.HandleFunc("/account/api/profiles/pin", adminUIHandler.RequireAuth(adminUIHandler.SetProfilePin)).Methods(http.MethodPut)
 	r.HandleFunc("/account/api/profiles/pin", adminUIHandler.RequireAuth(adminUIHandler.ClearProfilePin)).Methods(http.MethodDelete)
 	r.HandleFunc("/account/api/profiles/kids", adminUIHandler.RequireAuth(adminUIHandler.SetKidsProfile)).Methods(http.MethodPut)
+	r.HandleFunc("/account/api/profiles/blocked", adminUIHandler.RequireAuth(adminUIHandler.GetBlockedAttempts)).Methods(http.MethodGet)
 	r.HandleFunc("/account/api/password", accountUIHandler.RequireAuth(accountUIHandler.ChangePassword)).Methods(http.MethodPut)
 
 	// Protected account routes - User Settings API
@@ -650,6 +779,9 @@ func main() {
 		http.Redirect(w, r, "/admin", http.StatusFound)
 	}).Methods(http.MethodGet)
 
+	// SABnzbd-compatible download API for Sonarr/Radarr (disabled until an API key is set)
+	r.Handle("/sabnzbd/api", sabnzbd.NewServer(nzbSystem.ImporterService(), cfgManager)).Methods(http.MethodGet, http.MethodPost)
+
 	// Mount WebDAV handler if enabled
 	if webdavHandler != nil {
 		r.PathPrefix(settings.WebDAV.Prefix + "/").Handler(webdavHandler)
@@ -716,6 +848,18 @@ func main() {
 		log.Printf("Scheduler shutdown error: %v", err)
 	}
 
+	// Stop running recordings so their files are finalised
+	log.Println("🧹 Stopping Live TV recordings...")
+	if err := dvrService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("DVR shutdown error: %v", err)
+	}
+
+	// Stop webhook deliveries; retries still waiting are recorded as failed
+	log.Println("🧹 Stopping notifications...")
+	if err := notificationsService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Notifications shutdown error: %v", err)
+	}
+
 	// Stop NZB system workers first to cancel background processing
 	log.Println("🧹 Stopping NZB system workers...")
 	if err := nzbSystem.StopService(shutdownCtx); err != nil {
@@ -19,8 +19,14 @@ type ClientFilterSettings struct {
 	HomeBackendUrl   *string `json:"homeBackendUrl,omitempty"`
 	RemoteBackendUrl *string `json:"remoteBackendUrl,omitempty"`
 
-	// Ranking criteria overrides
-	RankingCriteria *[]ClientRankingCriterion `json:"rankingCriteria,omitempty"`
+	// Ranking criteria, custom format and codec preference overrides
+	RankingCriteria      *[]ClientRankingCriterion     `json:"rankingCriteria,omitempty"`
+	CustomFormats        *[]ClientCustomFormatOverride `json:"customFormats,omitempty"`
+	AudioPreference      *[]string                     `json:"audioPreference,omitempty"`
+	VideoCodecPreference *[]string                     `json:"videoCodecPreference,omitempty"`
+
+	// Playback overrides
+	ForceAACTranscoding *bool `json:"forceAacTranscoding,omitempty"`
 }
 
 // IsEmpty returns true if no settings are configured
@@ -36,5 +42,9 @@ func (c *ClientFilterSettings) IsEmpty() bool {
 		c.HomeWifiSSID == nil &&
 		c.HomeBackendUrl == nil &&
 		c.RemoteBackendUrl == nil &&
-		c.RankingCriteria == nil
+		c.RankingCriteria == nil &&
+		c.CustomFormats == nil &&
+		c.AudioPreference == nil &&
+		c.VideoCodecPreference == nil &&
+		c.ForceAACTranscoding == nil
 }
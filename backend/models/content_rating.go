@@ -0,0 +1,144 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRatingCountry is the certification country used when a kids profile has none set.
+	DefaultRatingCountry = "US"
+	// DefaultKidsMovieRating is the highest movie certification allowed by default on kids profiles.
+	DefaultKidsMovieRating = "PG"
+	// DefaultKidsTVRating is the highest TV content rating allowed by default on kids profiles.
+	DefaultKidsTVRating = "TV-PG"
+)
+
+// ratingLadder lists a country's certifications from least to most restrictive audience.
+type ratingLadder struct {
+	Movie []string
+	TV    []string
+}
+
+// ratingLadders holds the certification systems TMDB reports, keyed by ISO 3166-1 country code.
+var ratingLadders = map[string]ratingLadder{
+	"US": {
+		Movie: []string{"G", "PG", "PG-13", "R", "NC-17"},
+		TV:    []string{"TV-Y", "TV-Y7", "TV-G", "TV-PG", "TV-14", "TV-MA"},
+	},
+	"GB": {
+		Movie: []string{"U", "PG", "12A", "12", "15", "18", "R18"},
+		TV:    []string{"U", "PG", "12", "15", "18"},
+	},
+	"CA": {
+		Movie: []string{"G", "PG", "14A", "18A", "R"},
+		TV:    []string{"C", "C8", "G", "PG", "14+", "18+"},
+	},
+	"AU": {
+		Movie: []string{"G", "PG", "M", "MA15+", "R18+", "X18+"},
+		TV:    []string{"P", "C", "G", "PG", "M", "MA15+", "AV15+", "R18+"},
+	},
+	"DE": {
+		Movie: []string{"0", "6", "12", "16", "18"},
+		TV:    []string{"0", "6", "12", "16", "18"},
+	},
+	"FR": {
+		Movie: []string{"U", "10", "12", "16", "18"},
+		TV:    []string{"NR", "10", "12", "16", "18"},
+	},
+	"NL": {
+		Movie: []string{"AL", "6", "9", "12", "14", "16", "18"},
+		TV:    []string{"AL", "6", "9", "12", "14", "16", "18"},
+	},
+}
+
+// RatingCountries returns the country codes with a known certification system.
+func RatingCountries() []string {
+	return []string{"US", "GB", "CA", "AU", "DE", "FR", "NL"}
+}
+
+// RatingMediaType maps a media type to the rating ladder it uses: "series" for TV content,
+// "movie" for everything else.
+func RatingMediaType(mediaType string) string {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "series", "tv", "show", "episode":
+		return "series"
+	default:
+		return "movie"
+	}
+}
+
+// RatingsFor returns the certifications of a country for a media type (movie|series), ordered
+// from the youngest audience up. It returns nil for unknown countries.
+func RatingsFor(country, mediaType string) []string {
+	ladder, ok := ratingLadders[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return nil
+	}
+	if RatingMediaType(mediaType) == "series" {
+		return ladder.TV
+	}
+	return ladder.Movie
+}
+
+// ratingRank returns the position of a certification in a country's ladder, or -1 if unknown.
+func ratingRank(country, mediaType, certification string) int {
+	certification = strings.ToUpper(strings.TrimSpace(certification))
+	for i, r := range RatingsFor(country, mediaType) {
+		if r == certification {
+			return i
+		}
+	}
+	return -1
+}
+
+// ContentRestriction is the maximum certification a kids profile may watch, expressed in the
+// rating system of a single country.
+type ContentRestriction struct {
+	Country        string `json:"country"`
+	MaxMovieRating string `json:"maxMovieRating"`
+	MaxTVRating    string `json:"maxTvRating"`
+}
+
+// Validate reports whether the country and both limits belong to a known rating system.
+func (r ContentRestriction) Validate() bool {
+	return ratingRank(r.Country, "movie", r.MaxMovieRating) >= 0 &&
+		ratingRank(r.Country, "series", r.MaxTVRating) >= 0
+}
+
+// Limit returns the maximum certification for a media type.
+func (r ContentRestriction) Limit(mediaType string) string {
+	if RatingMediaType(mediaType) == "series" {
+		return r.MaxTVRating
+	}
+	return r.MaxMovieRating
+}
+
+// Allows reports whether a title with the given certifications (country -> rating) may be shown,
+// along with the certification it was judged on. Titles without a recognised rating for the
+// restriction's country are not allowed.
+func (r ContentRestriction) Allows(mediaType string, certifications map[string]string) (bool, string) {
+	country := strings.ToUpper(strings.TrimSpace(r.Country))
+	certification := strings.TrimSpace(certifications[country])
+	rank := ratingRank(country, mediaType, certification)
+	if rank < 0 {
+		return false, certification
+	}
+	return rank <= ratingRank(country, mediaType, r.Limit(mediaType)), certification
+}
+
+// BlockedAttempt records a kids profile being refused a title, for the account owner to review.
+type BlockedAttempt struct {
+	ID            string    `json:"id"`
+	AccountID     string    `json:"accountId"`
+	ProfileID     string    `json:"profileId"`
+	ProfileName   string    `json:"profileName"`
+	TitleID       string    `json:"titleId,omitempty"`
+	TitleName     string    `json:"titleName"`
+	MediaType     string    `json:"mediaType"`
+	Certification string    `json:"certification,omitempty"` // empty when the title has no rating
+	Limit         string    `json:"limit"`
+	Country       string    `json:"country"`
+	Source        string    `json:"source"` // playback, details
+	BlockedAt     time.Time `json:"blockedAt"`
+}
@@ -8,6 +8,9 @@ const (
 	ServiceTypeUnknown ContentServiceType = ""
 	ServiceTypeUsenet  ContentServiceType = "usenet"
 	ServiceTypeDebrid  ContentServiceType = "debrid"
+	// ServiceTypeDirect is a stream a Stremio addon already resolved to a playable URL.
+	// It is played through the debrid playback path without adding a torrent.
+	ServiceTypeDirect ContentServiceType = "direct"
 )
 
 // NZBResult represents a normalized search result from a Torznab/Newznab indexer.
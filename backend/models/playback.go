@@ -1,5 +1,7 @@
 package models
 
+import "sort"
+
 // SubtitleSessionInfo represents a pre-extracted subtitle track session
 type SubtitleSessionInfo struct {
 	SessionID    string  `json:"sessionId"`
@@ -22,4 +24,52 @@ type PlaybackResolution struct {
 	SourceNZBPath string `json:"sourceNzbPath,omitempty"`
 	// Pre-extracted subtitles (for manual selection path)
 	SubtitleSessions map[int]*SubtitleSessionInfo `json:"subtitleSessions,omitempty"`
+	// Episode files of the resolved release when it is a season or complete pack
+	Pack *SeasonPack `json:"-"`
+}
+
+// SeasonPack is a resolved release holding several episodes, either a debrid torrent on the
+// account or an imported usenet directory. Later episodes are played from it without a new
+// search.
+type SeasonPack struct {
+	ServiceType ContentServiceType `json:"serviceType"`
+	Provider    string             `json:"provider,omitempty"`  // Debrid provider holding the torrent
+	TorrentID   string             `json:"torrentId,omitempty"` // Debrid torrent on the account
+	InfoHash    string             `json:"infoHash,omitempty"`
+	Directory   string             `json:"directory,omitempty"` // Imported usenet directory
+	Release     NZBResult          `json:"release"`
+	Files       []PackFile         `json:"files"`
+}
+
+// PackFile is an episode file inside a season pack.
+type PackFile struct {
+	ID            string `json:"id,omitempty"` // Debrid file ID
+	Path          string `json:"path"`
+	Size          int64  `json:"size,omitempty"`
+	SeasonNumber  int    `json:"seasonNumber"`
+	EpisodeNumber int    `json:"episodeNumber"`
+}
+
+// File returns the pack's file for an episode.
+func (p SeasonPack) File(season, episode int) (PackFile, bool) {
+	for _, f := range p.Files {
+		if f.SeasonNumber == season && f.EpisodeNumber == episode {
+			return f, true
+		}
+	}
+	return PackFile{}, false
+}
+
+// Seasons returns the seasons the pack has episodes of, in ascending order.
+func (p SeasonPack) Seasons() []int {
+	var seasons []int
+	seen := make(map[int]bool)
+	for _, f := range p.Files {
+		if f.SeasonNumber > 0 && !seen[f.SeasonNumber] {
+			seen[f.SeasonNumber] = true
+			seasons = append(seasons, f.SeasonNumber)
+		}
+	}
+	sort.Ints(seasons)
+	return seasons
 }
@@ -11,8 +11,19 @@ type UserRankingCriterion struct {
 }
 
 // UserRankingSettings holds per-user ranking overrides.
+// Preference orders replace the global order when set.
 type UserRankingSettings struct {
-	Criteria []UserRankingCriterion `json:"criteria,omitempty"`
+	Criteria             []UserRankingCriterion     `json:"criteria,omitempty"`
+	CustomFormats        []UserCustomFormatOverride `json:"customFormats,omitempty"`
+	AudioPreference      []string                   `json:"audioPreference,omitempty"`
+	VideoCodecPreference []string                   `json:"videoCodecPreference,omitempty"`
+}
+
+// UserCustomFormatOverride represents a per-user override for a global custom format.
+type UserCustomFormatOverride struct {
+	ID      string `json:"id"`
+	Enabled *bool  `json:"enabled,omitempty"`
+	Score   *int   `json:"score,omitempty"`
 }
 
 // ClientRankingCriterion represents a per-client override for a ranking criterion.
@@ -21,3 +32,10 @@ type ClientRankingCriterion struct {
 	Enabled *bool                     `json:"enabled,omitempty"`
 	Order   *int                      `json:"order,omitempty"`
 }
+
+// ClientCustomFormatOverride represents a per-client override for a global custom format.
+type ClientCustomFormatOverride struct {
+	ID      string `json:"id"`
+	Enabled *bool  `json:"enabled,omitempty"`
+	Score   *int   `json:"score,omitempty"`
+}
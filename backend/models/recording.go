@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// RecordingStatus is the lifecycle state of a Live TV recording.
+type RecordingStatus string
+
+const (
+	RecordingStatusScheduled RecordingStatus = "scheduled"
+	RecordingStatusRecording RecordingStatus = "recording"
+	RecordingStatusCompleted RecordingStatus = "completed"
+	RecordingStatusFailed    RecordingStatus = "failed"
+	RecordingStatusCancelled RecordingStatus = "cancelled"
+)
+
+// RecordingMediaType is the history media type used for recording playback progress.
+const RecordingMediaType = "recording"
+
+// Recording is a scheduled or captured Live TV recording.
+type Recording struct {
+	ID          string          `json:"id"`
+	UserID      string          `json:"userId"` // Profile that scheduled the recording
+	ChannelID   string          `json:"channelId"`
+	ChannelName string          `json:"channelName"`
+	TvgID       string          `json:"tvgId,omitempty"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	StartAt     time.Time       `json:"startAt"`
+	EndAt       time.Time       `json:"endAt"`
+	Status      RecordingStatus `json:"status"`
+	FilePath    string          `json:"-"`
+	FileSize    int64           `json:"fileSize,omitempty"`
+	Duration    float64         `json:"duration,omitempty"` // Captured length in seconds
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// Active reports whether the recording still holds or will hold a provider connection.
+func (r Recording) Active() bool {
+	return r.Status == RecordingStatusScheduled || r.Status == RecordingStatusRecording
+}
+
+// Overlaps reports whether the recording's time range intersects [start, end).
+func (r Recording) Overlaps(start, end time.Time) bool {
+	return r.StartAt.Before(end) && start.Before(r.EndAt)
+}
+
+// RecordingItem is a recording as listed in the library, with the profile's resume position.
+type RecordingItem struct {
+	Recording
+	Progress *PlaybackProgress `json:"progress,omitempty"`
+}
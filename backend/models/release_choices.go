@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ReleaseChoice remembers the release a profile last played for a title, so resuming on
+// another device or continuing with the next episode plays the same cut and encode.
+// For series, one choice is kept per series and records the episode it was played for.
+type ReleaseChoice struct {
+	ContentID     string    `json:"contentId"`               // e.g., "tmdb:tv:12345" for series, "tmdb:movie:67890" for movies
+	ContentType   string    `json:"contentType"`             // "series" or "movie"
+	SeasonNumber  int       `json:"seasonNumber,omitempty"`  // Episode the release was played for (series only)
+	EpisodeNumber int       `json:"episodeNumber,omitempty"` // Episode the release was played for (series only)
+	SeasonPack    bool      `json:"seasonPack,omitempty"`    // Release holds the whole season, not just one episode
+	Release       NZBResult `json:"release"`
+	SourcePath    string    `json:"sourcePath,omitempty"` // Resolved WebDAV path of the release
+	Manual        bool      `json:"manual,omitempty"`     // Picked by hand from the release list
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
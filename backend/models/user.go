@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -14,17 +15,18 @@ const (
 
 // User models a NovaStream profile capable of holding watchlist data.
 type User struct {
-	ID             string    `json:"id"`
-	AccountID      string    `json:"accountId"`                // ID of the owning account
-	Name           string    `json:"name"`
-	Color          string    `json:"color,omitempty"`
-	IconURL        string    `json:"iconUrl,omitempty"`        // Local path to downloaded profile icon image (set via admin UI)
-	PinHash        string    `json:"-"`                        // bcrypt hash of PIN, excluded from JSON (security)
-	TraktAccountID string    `json:"traktAccountId,omitempty"` // ID of the linked Trakt account (from config.TraktAccount)
-	PlexAccountID  string    `json:"plexAccountId,omitempty"`  // ID of the linked Plex account (from config.PlexAccount)
-	IsKidsProfile  bool      `json:"isKidsProfile"`            // Whether this is a kids profile with content restrictions
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             string              `json:"id"`
+	AccountID      string              `json:"accountId"` // ID of the owning account
+	Name           string              `json:"name"`
+	Color          string              `json:"color,omitempty"`
+	IconURL        string              `json:"iconUrl,omitempty"`        // Local path to downloaded profile icon image (set via admin UI)
+	PinHash        string              `json:"-"`                        // bcrypt hash of PIN, excluded from JSON (security)
+	TraktAccountID string              `json:"traktAccountId,omitempty"` // ID of the linked Trakt account (from config.TraktAccount)
+	PlexAccountID  string              `json:"plexAccountId,omitempty"`  // ID of the linked Plex account (from config.PlexAccount)
+	IsKidsProfile  bool                `json:"isKidsProfile"`            // Whether this is a kids profile with content restrictions
+	RatingLimits   *ContentRestriction `json:"ratingLimits,omitempty"`   // Maximum certifications for kids profiles (defaults to US PG / TV-PG)
+	CreatedAt      time.Time           `json:"createdAt"`
+	UpdatedAt      time.Time           `json:"updatedAt"`
 }
 
 // HasPin returns true if the user has a PIN set.
@@ -37,6 +39,24 @@ func (u User) HasIcon() bool {
 	return u.IconURL != ""
 }
 
+// ContentRestriction returns the rating limits enforced for the profile, or nil when the
+// profile is not a kids profile.
+func (u User) ContentRestriction() *ContentRestriction {
+	if !u.IsKidsProfile {
+		return nil
+	}
+	if u.RatingLimits != nil && u.RatingLimits.Validate() {
+		limits := *u.RatingLimits
+		limits.Country = strings.ToUpper(strings.TrimSpace(limits.Country))
+		return &limits
+	}
+	return &ContentRestriction{
+		Country:        DefaultRatingCountry,
+		MaxMovieRating: DefaultKidsMovieRating,
+		MaxTVRating:    DefaultKidsTVRating,
+	}
+}
+
 // MarshalJSON implements custom JSON marshaling to include the computed hasPin field.
 func (u User) MarshalJSON() ([]byte, error) {
 	type UserAlias User // prevent recursion
@@ -0,0 +1,150 @@
+package content_restrictions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/models"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// maxBlockedAttempts caps the log; the oldest entries are dropped first.
+const maxBlockedAttempts = 1000
+
+// Service keeps a log of titles refused to kids profiles so account owners can review them.
+type Service struct {
+	mu       sync.RWMutex
+	path     string
+	attempts []models.BlockedAttempt // oldest first
+}
+
+// NewService constructs a blocked attempts log backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create content restrictions dir: %w", err)
+	}
+
+	svc := &Service{
+		path: filepath.Join(storageDir, "blocked_attempts.json"),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Record appends a blocked attempt to the log.
+func (s *Service) Record(attempt models.BlockedAttempt) error {
+	if attempt.ID == "" {
+		attempt.ID = uuid.NewString()
+	}
+	if attempt.BlockedAt.IsZero() {
+		attempt.BlockedAt = time.Now().UTC()
+	}
+
+	log.Printf("[content-restrictions] blocked %s %q (%s) for profile %s (limit %s %s)",
+		attempt.MediaType, attempt.TitleName, attempt.Certification, attempt.ProfileName, attempt.Country, attempt.Limit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = append(s.attempts, attempt)
+	if len(s.attempts) > maxBlockedAttempts {
+		s.attempts = append([]models.BlockedAttempt(nil), s.attempts[len(s.attempts)-maxBlockedAttempts:]...)
+	}
+
+	return s.saveLocked()
+}
+
+// ListForAccount returns the blocked attempts of an account's profiles, newest first.
+func (s *Service) ListForAccount(accountID string) []models.BlockedAttempt {
+	return s.list(func(a models.BlockedAttempt) bool { return a.AccountID == accountID })
+}
+
+// ListAll returns every blocked attempt, newest first.
+// This should only be used by master accounts.
+func (s *Service) ListAll() []models.BlockedAttempt {
+	return s.list(func(models.BlockedAttempt) bool { return true })
+}
+
+func (s *Service) list(match func(models.BlockedAttempt) bool) []models.BlockedAttempt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.BlockedAttempt, 0)
+	for _, a := range s.attempts {
+		if match(a) {
+			result = append(result, a)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].BlockedAt.After(result[j].BlockedAt)
+	})
+
+	return result
+}
+
+// load reads the log from disk.
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.attempts = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open blocked attempts: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read blocked attempts: %w", err)
+	}
+	if len(data) == 0 {
+		s.attempts = nil
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &s.attempts); err != nil {
+		return fmt.Errorf("decode blocked attempts: %w", err)
+	}
+
+	return nil
+}
+
+// saveLocked writes the log to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.attempts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode blocked attempts: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write blocked attempts: %w", err)
+	}
+
+	return nil
+}
@@ -251,3 +251,52 @@ func (c *AllDebridClient) GetAccountInfo(ctx context.Context) (*AccountInfo, err
 
 	return info, nil
 }
+
+// GetAccountInfo retrieves account information from Premiumize.
+func (c *PremiumizeClient) GetAccountInfo(ctx context.Context) (*AccountInfo, error) {
+	var result struct {
+		premiumizeStatus
+		CustomerID   interface{} `json:"customer_id"`
+		PremiumUntil interface{} `json:"premium_until"` // Unix timestamp, or false without premium
+	}
+	if err := c.do(ctx, http.MethodGet, "/account/info", nil, &result); err != nil {
+		return nil, fmt.Errorf("account info request failed: %w", err)
+	}
+
+	info := &AccountInfo{}
+	switch id := result.CustomerID.(type) {
+	case string:
+		info.Username = id
+	case float64:
+		info.Username = fmt.Sprintf("%.0f", id)
+	}
+
+	if until, ok := result.PremiumUntil.(float64); ok && until > 0 {
+		expiresAt := time.Unix(int64(until), 0)
+		info.ExpiresAt = &expiresAt
+		info.PremiumActive = true
+		info.DaysRemaining = int(time.Until(expiresAt).Hours() / 24)
+		if info.DaysRemaining < 0 {
+			info.DaysRemaining = 0
+			info.PremiumActive = false
+		}
+	}
+
+	return info, nil
+}
+
+// FetchAccountInfo looks up account info for a configured provider by name.
+func FetchAccountInfo(ctx context.Context, provider, apiKey string) (*AccountInfo, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "realdebrid":
+		return NewRealDebridClient(apiKey).GetAccountInfo(ctx)
+	case "torbox":
+		return NewTorboxClient(apiKey).GetAccountInfo(ctx)
+	case "alldebrid":
+		return NewAllDebridClient(apiKey).GetAccountInfo(ctx)
+	case "premiumize":
+		return NewPremiumizeClient(apiKey).GetAccountInfo(ctx)
+	default:
+		return nil, fmt.Errorf("account info not supported for provider %q", provider)
+	}
+}
@@ -31,7 +31,7 @@ var _ Provider = (*AllDebridClient)(nil)
 func NewAllDebridClient(apiKey string) *AllDebridClient {
 	return &AllDebridClient{
 		apiKey:     strings.TrimSpace(apiKey),
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: newMetricsTransport("alldebrid")},
 		baseURL:    "https://api.alldebrid.com/v4",
 		agent:      "strmr",
 	}
@@ -419,6 +419,74 @@ func (c *AllDebridClient) GetTorrentInfo(ctx context.Context, torrentID string)
 	return info, nil
 }
 
+// ListTorrents returns every magnet on the AllDebrid account. File lists are not
+// included by the list endpoint.
+func (c *AllDebridClient) ListTorrents(ctx context.Context) ([]TorrentInfo, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("alldebrid API key not configured")
+	}
+
+	endpoint := fmt.Sprintf("%s/magnet/status?agent=%s",
+		strings.Replace(c.baseURL, "/v4", "/v4.1", 1),
+		url.QueryEscape(c.agent))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build list torrents request: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("list torrents request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("alldebrid authentication failed: invalid API key")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	var result allDebridResponse[allDebridStatusData]
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decode list torrents response: %w (body: %s)", err, string(body))
+	}
+
+	if result.Status != "success" {
+		errMsg := "unknown error"
+		if result.Error != nil {
+			errMsg = result.Error.Message
+		}
+		return nil, fmt.Errorf("list torrents failed: %s", errMsg)
+	}
+
+	var magnets []allDebridStatus
+	if len(result.Data.Magnets) > 0 {
+		if err := json.Unmarshal(result.Data.Magnets, &magnets); err != nil {
+			return nil, fmt.Errorf("decode magnets array: %w", err)
+		}
+	}
+
+	infos := make([]TorrentInfo, 0, len(magnets))
+	for _, m := range magnets {
+		info := TorrentInfo{
+			ID:       strconv.Itoa(m.ID),
+			Filename: m.Filename,
+			Hash:     m.Hash,
+			Bytes:    m.Size,
+			Status:   c.mapStatusCode(m.StatusCode),
+		}
+		if m.UploadDate > 0 {
+			info.Added = time.Unix(m.UploadDate, 0).UTC().Format(time.RFC3339)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 // flattenFileTree recursively flattens the nested v4.1 file tree into Files and Links slices.
 func (c *AllDebridClient) flattenFileTree(nodes []allDebridFileNode, basePath string, info *TorrentInfo) {
 	for _, node := range nodes {
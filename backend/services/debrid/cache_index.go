@@ -0,0 +1,254 @@
+package debrid
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+// cacheIndexPruneInterval is how often recording a check also removes expired entries.
+const cacheIndexPruneInterval = time.Hour
+
+// cacheIndexStore persists the last known cache state per info hash and provider.
+type cacheIndexStore interface {
+	UpsertDebridCacheEntry(entry *database.DebridCacheEntry) error
+	ListDebridCacheEntries(infoHashes []string) ([]*database.DebridCacheEntry, error)
+	DeleteDebridCacheEntriesBefore(cutoff time.Time) (int64, error)
+}
+
+// cacheIndex remembers which providers had which torrents cached. Every health check and
+// resolution feeds it, and fresh entries let later checks skip the provider round trip,
+// which for Real-Debrid means adding and deleting a torrent. It is shared by all debrid
+// services in the process and does nothing until a repository is set.
+type cacheIndex struct {
+	mu        sync.Mutex
+	store     cacheIndexStore
+	cfg       *config.Manager
+	now       func() time.Time
+	lastPrune time.Time
+}
+
+var availability = &cacheIndex{now: time.Now}
+
+// SetCacheIndexRepository enables the persistent cache-availability index. TTLs are read
+// from the streaming settings on every lookup.
+func SetCacheIndexRepository(repo *database.DebridCacheRepository, cfg *config.Manager) {
+	availability.mu.Lock()
+	defer availability.mu.Unlock()
+	availability.store = nil
+	if repo != nil {
+		availability.store = repo
+	}
+	availability.cfg = cfg
+}
+
+func (c *cacheIndex) snapshot() (cacheIndexStore, *config.Manager) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.store, c.cfg
+}
+
+// ttls returns how long cached and uncached entries stay trusted.
+func (c *cacheIndex) ttls(cfg *config.Manager) (time.Duration, time.Duration) {
+	streaming := config.DefaultSettings().Streaming
+	if cfg != nil {
+		if settings, err := cfg.Load(); err == nil {
+			streaming = settings.Streaming
+		}
+	}
+	return time.Duration(streaming.CacheIndexCachedTTLHours) * time.Hour,
+		time.Duration(streaming.CacheIndexUncachedTTLHours) * time.Hour
+}
+
+// lookup returns the fresh entries for the given hashes, keyed by hash and then provider.
+func (c *cacheIndex) lookup(infoHashes ...string) map[string]map[string]*database.DebridCacheEntry {
+	store, cfg := c.snapshot()
+	if store == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(infoHashes))
+	seen := make(map[string]struct{}, len(infoHashes))
+	for _, hash := range infoHashes {
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if hash == "" {
+			continue
+		}
+		if _, dup := seen[hash]; dup {
+			continue
+		}
+		seen[hash] = struct{}{}
+		normalized = append(normalized, hash)
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+
+	entries, err := store.ListDebridCacheEntries(normalized)
+	if err != nil {
+		log.Printf("[debrid-cache-index] lookup failed: %v", err)
+		return nil
+	}
+
+	cachedTTL, uncachedTTL := c.ttls(cfg)
+	now := c.now()
+	fresh := make(map[string]map[string]*database.DebridCacheEntry)
+	for _, entry := range entries {
+		ttl := uncachedTTL
+		if entry.Cached {
+			ttl = cachedTTL
+		}
+		if now.Sub(entry.CheckedAt) > ttl {
+			continue
+		}
+		if fresh[entry.InfoHash] == nil {
+			fresh[entry.InfoHash] = make(map[string]*database.DebridCacheEntry)
+		}
+		fresh[entry.InfoHash][entry.Provider] = entry
+	}
+	return fresh
+}
+
+// entry returns the fresh entry for one hash on one provider, if any.
+func (c *cacheIndex) entry(infoHash, provider string) (*database.DebridCacheEntry, bool) {
+	infoHash = strings.ToLower(strings.TrimSpace(infoHash))
+	entry, ok := c.lookup(infoHash)[infoHash][strings.ToLower(provider)]
+	return entry, ok
+}
+
+// cachedOn reports whether a hash is cached on any of the providers. known is false unless
+// a fresh entry says it is cached somewhere or every provider has a fresh uncached entry.
+func (c *cacheIndex) cachedOn(infoHash string, providers []string) (cached []string, known bool) {
+	infoHash = strings.ToLower(strings.TrimSpace(infoHash))
+	if infoHash == "" || len(providers) == 0 {
+		return nil, false
+	}
+	return cachedProviders(c.lookup(infoHash)[infoHash], providers)
+}
+
+func cachedProviders(entries map[string]*database.DebridCacheEntry, providers []string) ([]string, bool) {
+	uncachedCount := 0
+	var cached []string
+	for _, provider := range providers {
+		entry, ok := entries[provider]
+		if !ok {
+			continue
+		}
+		if entry.Cached {
+			cached = append(cached, provider)
+		} else {
+			uncachedCount++
+		}
+	}
+	return cached, len(cached) > 0 || uncachedCount == len(providers)
+}
+
+// record stores the outcome of a cache check. Errors that say nothing about the cache
+// state (failed requests, rejected file selections) must not be recorded.
+func (c *cacheIndex) record(infoHash, provider string, cached bool, files []File) {
+	store, cfg := c.snapshot()
+	infoHash = strings.ToLower(strings.TrimSpace(infoHash))
+	if store == nil || infoHash == "" || provider == "" {
+		return
+	}
+
+	entry := &database.DebridCacheEntry{
+		InfoHash:  infoHash,
+		Provider:  strings.ToLower(provider),
+		Cached:    cached,
+		CheckedAt: c.now(),
+	}
+	for _, file := range files {
+		entry.Files = append(entry.Files, database.DebridCacheFile{Path: file.Path, Bytes: file.Bytes})
+	}
+	if err := store.UpsertDebridCacheEntry(entry); err != nil {
+		log.Printf("[debrid-cache-index] failed to record %s on %s: %v", infoHash, provider, err)
+		return
+	}
+
+	c.mu.Lock()
+	due := c.now().Sub(c.lastPrune) >= cacheIndexPruneInterval
+	if due {
+		c.lastPrune = c.now()
+	}
+	c.mu.Unlock()
+
+	if due {
+		cachedTTL, uncachedTTL := c.ttls(cfg)
+		if uncachedTTL > cachedTTL {
+			cachedTTL = uncachedTTL
+		}
+		if removed, err := store.DeleteDebridCacheEntriesBefore(c.now().Add(-cachedTTL)); err != nil {
+			log.Printf("[debrid-cache-index] prune failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("[debrid-cache-index] pruned %d expired entries", removed)
+		}
+	}
+}
+
+// annotate marks results whose cache state is known on the enabled providers: "cached" is
+// "true" or "false" and "cachedProviders" lists the providers that have it.
+func (c *cacheIndex) annotate(results []models.NZBResult, providers []string) {
+	if len(results) == 0 || len(providers) == 0 {
+		return
+	}
+
+	hashes := make([]string, len(results))
+	for i := range results {
+		hashes[i] = candidateInfoHash(results[i])
+	}
+	fresh := c.lookup(hashes...)
+	if len(fresh) == 0 {
+		return
+	}
+
+	for i := range results {
+		entries, ok := fresh[hashes[i]]
+		if !ok {
+			continue
+		}
+		cached, known := cachedProviders(entries, providers)
+		if !known {
+			continue
+		}
+		if results[i].Attributes == nil {
+			results[i].Attributes = make(map[string]string)
+		}
+		if len(cached) > 0 {
+			sort.Strings(cached)
+			results[i].Attributes["cached"] = "true"
+			results[i].Attributes["cachedProviders"] = strings.Join(cached, ",")
+		} else {
+			results[i].Attributes["cached"] = "false"
+		}
+	}
+}
+
+// enabledProviderNames returns the registry names of enabled providers with API keys.
+func enabledProviderNames(providers []config.DebridProviderSettings) []string {
+	var names []string
+	for _, p := range providers {
+		if !p.Enabled || strings.TrimSpace(p.APIKey) == "" {
+			continue
+		}
+		names = append(names, strings.ToLower(p.Provider))
+	}
+	return names
+}
+
+// candidateInfoHash returns a result's info hash from its attributes or magnet link.
+func candidateInfoHash(result models.NZBResult) string {
+	if hash := strings.TrimSpace(result.Attributes["infoHash"]); hash != "" {
+		return strings.ToLower(hash)
+	}
+	if strings.HasPrefix(strings.ToLower(result.Link), "magnet:") {
+		return extractInfoHashFromMagnet(result.Link)
+	}
+	return ""
+}
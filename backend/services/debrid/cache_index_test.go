@@ -0,0 +1,141 @@
+package debrid
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+const cacheIndexTestHash = "0123456789abcdef0123456789abcdef01234567"
+
+// countingProvider is a Provider whose torrents are always in the given status.
+type countingProvider struct {
+	name   string
+	status string
+	adds   int
+}
+
+func (p *countingProvider) Name() string { return p.name }
+func (p *countingProvider) AddMagnet(ctx context.Context, magnetURL string) (*AddMagnetResult, error) {
+	p.adds++
+	return &AddMagnetResult{ID: "t1"}, nil
+}
+func (p *countingProvider) AddTorrentFile(ctx context.Context, data []byte, filename string) (*AddMagnetResult, error) {
+	p.adds++
+	return &AddMagnetResult{ID: "t1"}, nil
+}
+func (p *countingProvider) GetTorrentInfo(ctx context.Context, torrentID string) (*TorrentInfo, error) {
+	return &TorrentInfo{
+		ID:     torrentID,
+		Status: p.status,
+		Files:  []File{{ID: 1, Path: "/Movie.2020.1080p.mkv", Bytes: 4096, Selected: 1}},
+		Links:  []string{"https://example.com/d/1"},
+	}, nil
+}
+func (p *countingProvider) SelectFiles(ctx context.Context, torrentID, fileIDs string) error {
+	return nil
+}
+func (p *countingProvider) DeleteTorrent(ctx context.Context, torrentID string) error { return nil }
+func (p *countingProvider) UnrestrictLink(ctx context.Context, link string) (*UnrestrictResult, error) {
+	return &UnrestrictResult{DownloadURL: link}, nil
+}
+func (p *countingProvider) CheckInstantAvailability(ctx context.Context, infoHash string) (bool, error) {
+	return false, nil
+}
+
+func newCacheIndexTestRepo(t *testing.T) *database.DebridCacheRepository {
+	t.Helper()
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "cache.db")})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	repo := database.NewDebridCacheRepository(db.Connection())
+	SetCacheIndexRepository(repo, nil)
+	t.Cleanup(func() {
+		SetCacheIndexRepository(nil, nil)
+		availability.now = time.Now
+		db.Close()
+	})
+	return repo
+}
+
+func TestCacheIndex_HealthChecksReuseRecordedState(t *testing.T) {
+	repo := newCacheIndexTestRepo(t)
+	provider := &countingProvider{name: "realdebrid", status: "downloaded"}
+	result := models.NZBResult{Title: "Movie 2020 1080p", Link: "magnet:?xt=urn:btih:" + cacheIndexTestHash}
+
+	svc := NewHealthService(nil)
+	for i := 0; i < 2; i++ {
+		health, err := svc.checkProviderHealth(context.Background(), provider, result, cacheIndexTestHash, "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !health.Healthy || !health.Cached {
+			t.Fatalf("check %d: expected cached result, got %+v", i+1, health)
+		}
+	}
+	if provider.adds != 1 {
+		t.Fatalf("expected the second check to be answered by the index, got %d adds", provider.adds)
+	}
+
+	entries, err := repo.ListDebridCacheEntries([]string{cacheIndexTestHash})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Cached || len(entries[0].Files) != 1 || entries[0].Files[0].Path != "/Movie.2020.1080p.mkv" {
+		t.Fatalf("unexpected index entries %+v", entries)
+	}
+
+	// A provider that had it uncached is not asked again while the entry is fresh
+	torbox := &countingProvider{name: "torbox", status: "downloading"}
+	if _, err := svc.checkProviderHealth(context.Background(), torbox, result, cacheIndexTestHash, "", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	playback := &PlaybackService{}
+	if _, err := playback.resolveWithProvider(context.Background(), torbox, result, cacheIndexTestHash, ""); err == nil {
+		t.Fatal("expected resolution to fail fast for a known uncached torrent")
+	}
+	if torbox.adds != 1 {
+		t.Fatalf("expected a single torbox round trip, got %d", torbox.adds)
+	}
+}
+
+func TestCacheIndex_AnnotatesResultsUntilEntriesExpire(t *testing.T) {
+	newCacheIndexTestRepo(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	availability.now = func() time.Time { return now }
+
+	availability.record(cacheIndexTestHash, "realdebrid", false, nil)
+	availability.record(cacheIndexTestHash, "torbox", true, nil)
+
+	magnet := "magnet:?xt=urn:btih:" + cacheIndexTestHash
+	results := []models.NZBResult{{Title: "Movie", Link: magnet}, {Title: "Other", Link: "magnet:?xt=urn:btih:ffff"}}
+	availability.annotate(results, []string{"realdebrid", "torbox"})
+	if results[0].Attributes["cached"] != "true" || results[0].Attributes["cachedProviders"] != "torbox" {
+		t.Fatalf("expected result to be marked cached on torbox, got %+v", results[0].Attributes)
+	}
+	if results[1].Attributes != nil {
+		t.Fatalf("expected unknown hash to stay unmarked, got %+v", results[1].Attributes)
+	}
+
+	results = []models.NZBResult{{Title: "Movie", Link: magnet}}
+	availability.annotate(results, []string{"realdebrid"})
+	if results[0].Attributes["cached"] != "false" {
+		t.Fatalf("expected result to be marked uncached, got %+v", results[0].Attributes)
+	}
+
+	// Uncached entries expire first (default 6h), cached ones later (default 24h)
+	defaults := config.DefaultSettings().Streaming
+	now = now.Add(time.Duration(defaults.CacheIndexUncachedTTLHours)*time.Hour + time.Minute)
+	if _, known := availability.cachedOn(cacheIndexTestHash, []string{"realdebrid"}); known {
+		t.Fatal("expected expired uncached entry to be unknown")
+	}
+	if cached, known := availability.cachedOn(cacheIndexTestHash, []string{"realdebrid", "torbox"}); !known || len(cached) != 1 {
+		t.Fatalf("expected cached entry to still be fresh, got %v known=%t", cached, known)
+	}
+}
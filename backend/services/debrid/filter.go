@@ -19,6 +19,7 @@ type FilterOptions struct {
 	FilterOutTerms      []string                     // Terms to filter out from results (case-insensitive match in title)
 	TotalSeriesEpisodes int                          // Deprecated: use EpisodeResolver instead
 	EpisodeResolver     filter.EpisodeCountResolver  // Resolver for accurate episode counts from metadata
+	Rejections          *filter.Rejections           // Optional: records the results that are dropped and why
 }
 
 // FilterResults filters search results based on parsed title information
@@ -38,6 +39,7 @@ func FilterResults(results []models.NZBResult, opts FilterOptions) []models.NZBR
 		FilterOutTerms:      opts.FilterOutTerms,
 		TotalSeriesEpisodes: opts.TotalSeriesEpisodes,
 		EpisodeResolver:     opts.EpisodeResolver,
+		Rejections:          opts.Rejections,
 	}
 	return filter.Results(results, filterOpts)
 }
@@ -247,6 +247,23 @@ func (s *HealthService) CheckHealth(ctx context.Context, result models.NZBResult
 func (s *HealthService) checkProviderHealth(ctx context.Context, client Provider, result models.NZBResult, infoHash, torrentURL string, verifyUncached bool) (*DebridHealthCheck, error) {
 	providerName := client.Name()
 
+	// A recent check on this provider answers without another round trip. Uncached entries
+	// are re-verified when the caller asks for it.
+	if entry, ok := availability.entry(infoHash, providerName); ok && (entry.Cached || !verifyUncached) {
+		log.Printf("[debrid-health] %s cache index hit for %s: cached=%t (checked %s ago)", providerName, infoHash, entry.Cached, time.Since(entry.CheckedAt).Round(time.Second))
+		status := "not_cached"
+		if entry.Cached {
+			status = "cached"
+		}
+		return &DebridHealthCheck{
+			Healthy:  entry.Cached,
+			Status:   status,
+			Cached:   entry.Cached,
+			Provider: providerName,
+			InfoHash: infoHash,
+		}, nil
+	}
+
 	// Use add+check+remove method to verify cache status
 	identifier := infoHash
 	if identifier == "" {
@@ -313,6 +330,7 @@ func (s *HealthService) checkProviderHealth(ctx context.Context, client Provider
 	}
 
 	torrentID := addResp.ID
+	addedTorrents.added(providerName, torrentID, infoHash)
 	log.Printf("[debrid-health] %s torrent added with ID %s, getting file list", providerName, torrentID)
 
 	// First, get the torrent info to see what files are available
@@ -409,6 +427,10 @@ func (s *HealthService) checkProviderHealth(ctx context.Context, client Provider
 	// Check if the torrent is already downloaded (cached)
 	isCached := strings.ToLower(info.Status) == "downloaded"
 	log.Printf("[debrid-health] %s torrent %s status=%s cached=%t", providerName, torrentID, info.Status, isCached)
+	if infoHash == "" {
+		infoHash = strings.ToLower(info.Hash)
+	}
+	availability.record(infoHash, providerName, isCached, info.Files)
 
 	// Always remove the torrent after checking - especially important for non-cached torrents
 	// which may have started downloading (e.g., Torbox starts downloads immediately)
@@ -0,0 +1,199 @@
+package debrid
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+// CleanupOptions controls which tracked torrents housekeeping removes. A zero duration
+// disables that rule.
+type CleanupOptions struct {
+	// UnplayedFor removes finished torrents not streamed (or, if never streamed, added)
+	// within this long.
+	UnplayedFor time.Duration
+	// StuckFor removes torrents still downloading or queued this long after being added.
+	StuckFor time.Duration
+	// DryRun reports what would be removed without deleting anything.
+	DryRun bool
+}
+
+// CleanupCandidate is a torrent selected for removal.
+type CleanupCandidate struct {
+	Provider  string
+	TorrentID string
+	Name      string
+	Reason    string
+}
+
+// CleanupReport summarizes a housekeeping run.
+type CleanupReport struct {
+	Candidates []CleanupCandidate
+	Removed    int
+}
+
+// Housekeeper removes torrents strmr added to debrid accounts once they are no longer
+// useful, so health checks and playback don't fill the account up to the provider's
+// active-torrent limit. Torrents the user added outside strmr are never touched.
+type Housekeeper struct {
+	cfg *config.Manager
+	now func() time.Time
+}
+
+// NewHousekeeper creates a debrid account housekeeper.
+func NewHousekeeper(cfg *config.Manager) *Housekeeper {
+	return &Housekeeper{cfg: cfg, now: time.Now}
+}
+
+// CleanupTorrents lists the torrents on every enabled provider that supports listing and
+// removes the tracked ones that are unplayed, stuck or in an error state.
+func (h *Housekeeper) CleanupTorrents(ctx context.Context, opts CleanupOptions) (*CleanupReport, error) {
+	settings, err := h.cfg.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load settings: %w", err)
+	}
+
+	report := &CleanupReport{}
+	attempted := 0
+	var firstErr error
+	for i := range settings.Streaming.DebridProviders {
+		p := &settings.Streaming.DebridProviders[i]
+		if !p.Enabled || strings.TrimSpace(p.APIKey) == "" {
+			continue
+		}
+
+		client, ok := GetProvider(strings.ToLower(p.Provider), p.APIKey)
+		if !ok {
+			log.Printf("[debrid-housekeeping] provider %q not registered, skipping", p.Provider)
+			continue
+		}
+		if configurable, ok := client.(Configurable); ok && p.Config != nil {
+			configurable.Configure(p.Config)
+		}
+		lister, ok := client.(TorrentLister)
+		if !ok {
+			log.Printf("[debrid-housekeeping] %s: provider cannot list torrents, skipping", client.Name())
+			continue
+		}
+
+		attempted++
+		if err := h.cleanupProvider(ctx, client, lister, opts, report); err != nil {
+			log.Printf("[debrid-housekeeping] %s: %v", client.Name(), err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", client.Name(), err)
+			}
+		}
+	}
+
+	if attempted > 0 && firstErr != nil && len(report.Candidates) == 0 {
+		return report, firstErr
+	}
+	return report, nil
+}
+
+func (h *Housekeeper) cleanupProvider(ctx context.Context, client Provider, lister TorrentLister, opts CleanupOptions, report *CleanupReport) error {
+	providerName := client.Name()
+
+	tracked, err := addedTorrents.list(providerName)
+	if err != nil {
+		return fmt.Errorf("list tracked torrents: %w", err)
+	}
+	trackedByID := make(map[string]*database.DebridTorrent, len(tracked))
+	for _, t := range tracked {
+		trackedByID[t.TorrentID] = t
+	}
+
+	torrents, err := lister.ListTorrents(ctx)
+	if err != nil {
+		return fmt.Errorf("list torrents: %w", err)
+	}
+
+	now := h.now()
+	var candidates []CleanupCandidate
+	for _, info := range torrents {
+		t, ok := trackedByID[info.ID]
+		if !ok {
+			continue
+		}
+		delete(trackedByID, info.ID)
+
+		reason := cleanupReason(info, t, now, opts)
+		if reason == "" {
+			continue
+		}
+		candidates = append(candidates, CleanupCandidate{
+			Provider:  providerName,
+			TorrentID: info.ID,
+			Name:      info.Filename,
+			Reason:    reason,
+		})
+	}
+
+	removed := 0
+	if !opts.DryRun {
+		for _, c := range candidates {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := client.DeleteTorrent(ctx, c.TorrentID); err != nil {
+				log.Printf("[debrid-housekeeping] %s: failed to delete torrent %s (%s): %v", providerName, c.TorrentID, c.Name, err)
+				continue
+			}
+			log.Printf("[debrid-housekeeping] %s: deleted torrent %s (%s): %s", providerName, c.TorrentID, c.Name, c.Reason)
+			addedTorrents.forget(providerName, c.TorrentID)
+			removed++
+		}
+
+		// Torrents removed from the account by other means no longer need tracking
+		for id := range trackedByID {
+			addedTorrents.forget(providerName, id)
+		}
+	}
+
+	log.Printf("[debrid-housekeeping] %s: %d torrents on account, %d added by strmr, %d selected, %d removed (dryRun=%t)",
+		providerName, len(torrents), len(tracked)-len(trackedByID), len(candidates), removed, opts.DryRun)
+
+	report.Candidates = append(report.Candidates, candidates...)
+	report.Removed += removed
+	return nil
+}
+
+// cleanupReason returns why a tracked torrent should be removed, or "" to keep it.
+func cleanupReason(info TorrentInfo, tracked *database.DebridTorrent, now time.Time, opts CleanupOptions) string {
+	status := strings.ToLower(strings.TrimSpace(info.Status))
+	switch status {
+	case "error", "magnet_error", "virus", "dead":
+		return fmt.Sprintf("in %s state", status)
+	case "downloaded":
+		if opts.UnplayedFor <= 0 {
+			return ""
+		}
+		if tracked.LastPlayedAt == nil {
+			if now.Sub(tracked.AddedAt) >= opts.UnplayedFor {
+				return fmt.Sprintf("never played, added %s", formatAge(now.Sub(tracked.AddedAt)))
+			}
+			return ""
+		}
+		if now.Sub(*tracked.LastPlayedAt) >= opts.UnplayedFor {
+			return fmt.Sprintf("last played %s", formatAge(now.Sub(*tracked.LastPlayedAt)))
+		}
+		return ""
+	default:
+		if opts.StuckFor > 0 && now.Sub(tracked.AddedAt) >= opts.StuckFor {
+			return fmt.Sprintf("stuck in %s state, added %s", status, formatAge(now.Sub(tracked.AddedAt)))
+		}
+		return ""
+	}
+}
+
+func formatAge(age time.Duration) string {
+	if age >= 48*time.Hour {
+		return fmt.Sprintf("%d days ago", int(age/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%d hours ago", int(age/time.Hour))
+}
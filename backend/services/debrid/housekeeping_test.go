@@ -0,0 +1,119 @@
+package debrid
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+// listingProvider is a countingProvider that can list its torrents and records deletions.
+type listingProvider struct {
+	countingProvider
+	torrents []TorrentInfo
+	deleted  []string
+}
+
+func (p *listingProvider) ListTorrents(ctx context.Context) ([]TorrentInfo, error) {
+	return p.torrents, nil
+}
+func (p *listingProvider) DeleteTorrent(ctx context.Context, torrentID string) error {
+	p.deleted = append(p.deleted, torrentID)
+	return nil
+}
+
+func TestHousekeeper_RemovesOnlyStaleTrackedTorrents(t *testing.T) {
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "torrents.db")})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	repo := database.NewDebridTorrentRepository(db.Connection())
+	SetTorrentRepository(repo)
+	t.Cleanup(func() {
+		SetTorrentRepository(nil)
+		addedTorrents.now = time.Now
+		db.Close()
+	})
+
+	provider := &listingProvider{countingProvider: countingProvider{name: "housekeeping-test"}}
+	RegisterProvider("housekeeping-test", func(apiKey string) Provider { return provider })
+
+	mgr := config.NewManager(filepath.Join(t.TempDir(), "settings.json"))
+	settings := config.DefaultSettings()
+	settings.Streaming.DebridProviders = []config.DebridProviderSettings{
+		{Name: "Test", Provider: "housekeeping-test", APIKey: "key", Enabled: true},
+	}
+	if err := mgr.Save(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	track := func(id string, age time.Duration) {
+		addedTorrents.now = func() time.Time { return now.Add(-age) }
+		addedTorrents.added("housekeeping-test", id, "")
+	}
+	track("unplayed", 10*24*time.Hour)
+	track("played", 10*24*time.Hour)
+	track("stuck", 8*time.Hour)
+	track("failed", time.Hour)
+	track("fresh", time.Hour)
+	track("gone", 2*time.Hour)
+	addedTorrents.now = func() time.Time { return now.Add(-24 * time.Hour) }
+	addedTorrents.played("housekeeping-test", "played")
+
+	provider.torrents = []TorrentInfo{
+		{ID: "unplayed", Filename: "Old.Movie", Status: "downloaded"},
+		{ID: "played", Filename: "Watched.Show", Status: "downloaded"},
+		{ID: "stuck", Filename: "Slow.Movie", Status: "downloading"},
+		{ID: "failed", Filename: "Broken.Movie", Status: "magnet_error"},
+		{ID: "fresh", Filename: "New.Movie", Status: "queued"},
+		{ID: "mine", Filename: "User.Added", Status: "error"},
+	}
+
+	housekeeper := NewHousekeeper(mgr)
+	housekeeper.now = func() time.Time { return now }
+	opts := CleanupOptions{UnplayedFor: 7 * 24 * time.Hour, StuckFor: 6 * time.Hour, DryRun: true}
+
+	report, err := housekeeper.CleanupTorrents(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var selected []string
+	for _, c := range report.Candidates {
+		selected = append(selected, c.TorrentID)
+	}
+	sort.Strings(selected)
+	if len(selected) != 3 || selected[0] != "failed" || selected[1] != "stuck" || selected[2] != "unplayed" {
+		t.Fatalf("unexpected candidates %+v", report.Candidates)
+	}
+	if report.Removed != 0 || len(provider.deleted) != 0 {
+		t.Fatalf("dry run must not delete, got %d removed and deletions %v", report.Removed, provider.deleted)
+	}
+
+	opts.DryRun = false
+	report, err = housekeeper.CleanupTorrents(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Removed != 3 || len(provider.deleted) != 3 {
+		t.Fatalf("expected 3 removals, got %d (%v)", report.Removed, provider.deleted)
+	}
+
+	// Removed torrents and torrents no longer on the account stop being tracked
+	remaining, err := repo.ListDebridTorrents("housekeeping-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, torrent := range remaining {
+		ids = append(ids, torrent.TorrentID)
+	}
+	sort.Strings(ids)
+	if len(ids) != 2 || ids[0] != "fresh" || ids[1] != "played" {
+		t.Fatalf("unexpected tracked torrents %v", ids)
+	}
+}
@@ -0,0 +1,75 @@
+package debrid
+
+import (
+	"net/http"
+	"time"
+
+	"novastream/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	debridRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "novastream_debrid_request_duration_seconds",
+		Help:    "Latency of debrid provider API requests.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"provider"})
+	debridRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_debrid_request_errors_total",
+		Help: "Failed debrid provider API requests by reason (network, rate_limited, client, server).",
+	}, []string{"provider", "reason"})
+	scraperSearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "novastream_scraper_search_duration_seconds",
+		Help:    "Latency of torrent scraper searches.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"scraper"})
+	scraperResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_scraper_results_total",
+		Help: "Results returned by torrent scrapers before filtering.",
+	}, []string{"scraper"})
+	scraperErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_scraper_errors_total",
+		Help: "Failed torrent scraper searches.",
+	}, []string{"scraper"})
+)
+
+// metricsTransport records latency and failures of a provider's API calls.
+type metricsTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+// newMetricsTransport wraps the default transport for the named provider.
+func newMetricsTransport(provider string) http.RoundTripper {
+	return &metricsTransport{provider: provider, base: http.DefaultTransport}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	debridRequestDuration.WithLabelValues(t.provider).Observe(time.Since(start).Seconds())
+
+	switch {
+	case err != nil:
+		debridRequestErrors.WithLabelValues(t.provider, "network").Inc()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		debridRequestErrors.WithLabelValues(t.provider, "rate_limited").Inc()
+	case resp.StatusCode >= 500:
+		debridRequestErrors.WithLabelValues(t.provider, "server").Inc()
+	case resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound:
+		debridRequestErrors.WithLabelValues(t.provider, "client").Inc()
+	}
+	return resp, err
+}
+
+// observeScraperSearch records one scraper search.
+func observeScraperSearch(name string, elapsed time.Duration, results int, err error) {
+	scraperSearchDuration.WithLabelValues(name).Observe(elapsed.Seconds())
+	if err != nil {
+		scraperErrors.WithLabelValues(name).Inc()
+		return
+	}
+	scraperResults.WithLabelValues(name).Add(float64(results))
+}
@@ -56,11 +56,21 @@ func (s *MultiProviderService) CheckCacheAcrossProviders(
 	// Collect enabled providers with their priority (index = priority)
 	var enabledProviders []providerEntry
 
+	// Providers recently found not to have this torrent are skipped
+	infoHash := candidateInfoHash(candidate)
+	knownState := availability.lookup(infoHash)[infoHash]
+	knownUncached := 0
+
 	for i := range settings.Streaming.DebridProviders {
 		p := &settings.Streaming.DebridProviders[i]
 		if !p.Enabled || strings.TrimSpace(p.APIKey) == "" {
 			continue
 		}
+		if entry, ok := knownState[strings.ToLower(p.Provider)]; ok && !entry.Cached {
+			log.Printf("[multi-provider] %s: skipping, not cached as of %s", p.Name, entry.CheckedAt.Format(time.RFC3339))
+			knownUncached++
+			continue
+		}
 
 		client, ok := GetProvider(strings.ToLower(p.Provider), p.APIKey)
 		if !ok {
@@ -81,6 +91,9 @@ func (s *MultiProviderService) CheckCacheAcrossProviders(
 	}
 
 	if len(enabledProviders) == 0 {
+		if knownUncached > 0 {
+			return nil, releaseErrorf("torrent not cached on any enabled provider")
+		}
 		return nil, fmt.Errorf("no enabled debrid providers with API keys configured")
 	}
 
@@ -114,7 +127,7 @@ func (s *MultiProviderService) checkSingleProvider(
 		return nil, result.Error
 	}
 	if !result.IsCached {
-		return nil, fmt.Errorf("torrent not cached on %s", pe.config.Name)
+		return nil, releaseErrorf("torrent not cached on %s", pe.config.Name)
 	}
 	return result, nil
 }
@@ -175,7 +188,7 @@ func (s *MultiProviderService) checkFastestMode(
 	if firstError != nil {
 		return nil, fmt.Errorf("torrent not cached on any provider: %w", firstError)
 	}
-	return nil, fmt.Errorf("torrent not cached on any enabled provider")
+	return nil, releaseErrorf("torrent not cached on any enabled provider")
 }
 
 // checkPreferredMode waits for all providers, returns highest priority cached result
@@ -243,7 +256,7 @@ func (s *MultiProviderService) checkPreferredMode(
 	if firstError != nil {
 		return nil, fmt.Errorf("torrent not cached on any provider: %w", firstError)
 	}
-	return nil, fmt.Errorf("torrent not cached on any enabled provider")
+	return nil, releaseErrorf("torrent not cached on any enabled provider")
 }
 
 // checkProviderCache adds torrent, checks status, returns result (and cleans up if not cached)
@@ -287,6 +300,7 @@ func (s *MultiProviderService) checkProviderCache(
 	}
 
 	result.TorrentID = addResp.ID
+	addedTorrents.added(pe.client.Name(), result.TorrentID, candidateInfoHash(candidate))
 	log.Printf("[multi-provider] %s: torrent added with ID %s", providerName, result.TorrentID)
 
 	// Get info and check status
@@ -303,13 +317,13 @@ func (s *MultiProviderService) checkProviderCache(
 	if selection == nil || len(selection.OrderedIDs) == 0 {
 		_ = pe.client.DeleteTorrent(ctx, result.TorrentID)
 		result.TorrentID = ""
-		result.Error = fmt.Errorf("no media files found")
+		result.Error = releaseErrorf("no media files found")
 		return result
 	}
 	if selection.RejectionReason != "" {
 		_ = pe.client.DeleteTorrent(ctx, result.TorrentID)
 		result.TorrentID = ""
-		result.Error = fmt.Errorf("%s", selection.RejectionReason)
+		result.Error = releaseErrorf("%s", selection.RejectionReason)
 		return result
 	}
 
@@ -332,6 +346,11 @@ func (s *MultiProviderService) checkProviderCache(
 
 	result.IsCached = strings.ToLower(info.Status) == "downloaded"
 	log.Printf("[multi-provider] %s: status=%s cached=%t", providerName, info.Status, result.IsCached)
+	infoHash := candidateInfoHash(candidate)
+	if infoHash == "" {
+		infoHash = info.Hash
+	}
+	availability.record(infoHash, pe.client.Name(), result.IsCached, info.Files)
 
 	if !result.IsCached {
 		// Clean up non-cached torrent
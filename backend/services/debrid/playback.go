@@ -69,7 +69,7 @@ func (s *PlaybackService) Resolve(ctx context.Context, candidate models.NZBResul
 			}
 			if !healthCheck.Healthy || !healthCheck.Cached {
 				log.Printf("[debrid-playback] pre-resolved stream not cached: %s", healthCheck.ErrorMessage)
-				return nil, fmt.Errorf("stream not cached: %s", healthCheck.ErrorMessage)
+				return nil, releaseErrorf("stream not cached: %s", healthCheck.ErrorMessage)
 			}
 			log.Printf("[debrid-playback] pre-resolved stream verified as cached")
 		}
@@ -157,6 +157,10 @@ func (s *PlaybackService) Resolve(ctx context.Context, candidate models.NZBResul
 func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provider, candidate models.NZBResult, infoHash, torrentURL string) (*models.PlaybackResolution, error) {
 	providerName := client.Name()
 
+	if entry, ok := availability.entry(infoHash, providerName); ok && !entry.Cached {
+		return nil, releaseErrorf("torrent not cached on %s (checked %s ago)", providerName, time.Since(entry.CheckedAt).Round(time.Second))
+	}
+
 	var addResp *AddMagnetResult
 	var err error
 
@@ -185,6 +189,7 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 	}
 
 	torrentID := addResp.ID
+	addedTorrents.added(providerName, torrentID, infoHash)
 	log.Printf("[debrid-playback] torrent added with ID %s", torrentID)
 
 	// Get torrent info to see available files
@@ -197,15 +202,15 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 	selection := selectMediaFiles(info.Files, buildSelectionHints(candidate, info.Filename))
 	if selection == nil {
 		_ = client.DeleteTorrent(ctx, torrentID)
-		return nil, fmt.Errorf("no media files found in torrent")
+		return nil, releaseErrorf("no media files found in torrent")
 	}
 	if selection.RejectionReason != "" {
 		_ = client.DeleteTorrent(ctx, torrentID)
-		return nil, fmt.Errorf("%s", selection.RejectionReason)
+		return nil, releaseErrorf("%s", selection.RejectionReason)
 	}
 	if len(selection.OrderedIDs) == 0 {
 		_ = client.DeleteTorrent(ctx, torrentID)
-		return nil, fmt.Errorf("no media files found in torrent")
+		return nil, releaseErrorf("no media files found in torrent")
 	}
 
 	if selection.PreferredID != "" {
@@ -231,6 +236,10 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 	// Check if cached
 	isCached := strings.ToLower(info.Status) == "downloaded"
 	log.Printf("[debrid-playback] torrent %s status=%s cached=%t links=%d", torrentID, info.Status, isCached, len(info.Links))
+	if infoHash == "" {
+		infoHash = info.Hash
+	}
+	availability.record(infoHash, providerName, isCached, info.Files)
 
 	if !isCached {
 		// Torrent is not cached - it may be downloading. We must remove it from the account
@@ -239,7 +248,7 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 		if err := client.DeleteTorrent(ctx, torrentID); err != nil {
 			log.Printf("[debrid-playback] warning: failed to delete non-cached torrent %s: %v", torrentID, err)
 		}
-		return nil, fmt.Errorf("torrent not cached (status: %s)", info.Status)
+		return nil, releaseErrorf("torrent not cached (status: %s)", info.Status)
 	}
 
 	if len(info.Links) == 0 {
@@ -287,38 +296,14 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 		// Check for unsupported archives
 		if archiveExt := detectArchiveExtension(downloadURL); archiveExt != "" {
 			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL points to unsupported archive (%s)", archiveExt)
+			return nil, releaseErrorf("download URL points to unsupported archive (%s)", archiveExt)
 		}
 
 		// Verify the download URL is accessible with a HEAD request
-		log.Printf("[debrid-playback] verifying download URL is accessible: %s", downloadURL)
-
-		// Encode URL properly (handles spaces and special characters)
-		encodedDownloadURL, encErr := utils.EncodeURLWithSpaces(downloadURL)
-		if encErr != nil {
-			log.Printf("[debrid-playback] failed to encode download URL: %v", encErr)
-			encodedDownloadURL = downloadURL // Fall back to original
-		}
-
-		headReq, err := http.NewRequestWithContext(ctx, http.MethodHead, encodedDownloadURL, nil)
-		if err != nil {
-			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("failed to create HEAD request: %w", err)
-		}
-
-		headResp, err := http.DefaultClient.Do(headReq)
-		if err != nil {
+		if err := verifyDownloadURL(ctx, downloadURL); err != nil {
 			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL not accessible: %w", err)
+			return nil, err
 		}
-		defer headResp.Body.Close()
-
-		if headResp.StatusCode >= 400 {
-			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL returned error status: %d %s", headResp.StatusCode, headResp.Status)
-		}
-
-		log.Printf("[debrid-playback] download URL verified accessible (status: %d)", headResp.StatusCode)
 	} else {
 		// For providers like Torbox that use internal references (torrent_id:file_id),
 		// the actual URL is resolved at stream time via UnrestrictLink
@@ -333,6 +318,8 @@ func (s *PlaybackService) resolveWithProvider(ctx context.Context, client Provid
 		SourceNZBPath: downloadURL, // Store the actual download URL here
 	}
 
+	resolution.Pack = torrentPack(providerName, torrentID, infoHash, candidate, info)
+
 	log.Printf("[debrid-playback] resolution successful: webdavPath=%s downloadURL=%s", webdavPath, downloadURL)
 	return resolution, nil
 }
@@ -396,7 +383,7 @@ func (s *PlaybackService) completeResolution(
 	selection := selectMediaFiles(info.Files, buildSelectionHints(candidate, info.Filename))
 	if selection == nil || len(selection.OrderedIDs) == 0 {
 		_ = client.DeleteTorrent(ctx, torrentID)
-		return nil, fmt.Errorf("no media files found in torrent")
+		return nil, releaseErrorf("no media files found in torrent")
 	}
 
 	if selection.PreferredID != "" {
@@ -442,36 +429,13 @@ func (s *PlaybackService) completeResolution(
 	if isActualURL {
 		if archiveExt := detectArchiveExtension(downloadURL); archiveExt != "" {
 			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL points to unsupported archive (%s)", archiveExt)
-		}
-
-		log.Printf("[debrid-playback] verifying download URL is accessible: %s", downloadURL)
-
-		encodedDownloadURL, encErr := utils.EncodeURLWithSpaces(downloadURL)
-		if encErr != nil {
-			log.Printf("[debrid-playback] failed to encode download URL: %v", encErr)
-			encodedDownloadURL = downloadURL
-		}
-
-		headReq, err := http.NewRequestWithContext(ctx, http.MethodHead, encodedDownloadURL, nil)
-		if err != nil {
-			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("failed to create HEAD request: %w", err)
+			return nil, releaseErrorf("download URL points to unsupported archive (%s)", archiveExt)
 		}
 
-		headResp, err := http.DefaultClient.Do(headReq)
-		if err != nil {
+		if err := verifyDownloadURL(ctx, downloadURL); err != nil {
 			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL not accessible: %w", err)
+			return nil, err
 		}
-		defer headResp.Body.Close()
-
-		if headResp.StatusCode >= 400 {
-			_ = client.DeleteTorrent(ctx, torrentID)
-			return nil, fmt.Errorf("download URL returned error status: %d %s", headResp.StatusCode, headResp.Status)
-		}
-
-		log.Printf("[debrid-playback] download URL verified accessible (status: %d)", headResp.StatusCode)
 	} else {
 		log.Printf("[debrid-playback] download link is internal reference, will be resolved at stream time: %s", downloadURL)
 	}
@@ -484,10 +448,42 @@ func (s *PlaybackService) completeResolution(
 		SourceNZBPath: downloadURL,
 	}
 
+	resolution.Pack = torrentPack(providerName, torrentID, info.Hash, candidate, info)
+
 	log.Printf("[debrid-playback] resolution successful: webdavPath=%s downloadURL=%s", webdavPath, downloadURL)
 	return resolution, nil
 }
 
+// verifyDownloadURL checks with a HEAD request that a provider download link is accessible.
+func verifyDownloadURL(ctx context.Context, downloadURL string) error {
+	log.Printf("[debrid-playback] verifying download URL is accessible: %s", downloadURL)
+
+	// Encode URL properly (handles spaces and special characters)
+	encodedDownloadURL, encErr := utils.EncodeURLWithSpaces(downloadURL)
+	if encErr != nil {
+		log.Printf("[debrid-playback] failed to encode download URL: %v", encErr)
+		encodedDownloadURL = downloadURL // Fall back to original
+	}
+
+	headReq, err := http.NewRequestWithContext(ctx, http.MethodHead, encodedDownloadURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HEAD request: %w", err)
+	}
+
+	headResp, err := http.DefaultClient.Do(headReq)
+	if err != nil {
+		return fmt.Errorf("download URL not accessible: %w", err)
+	}
+	defer headResp.Body.Close()
+
+	if headResp.StatusCode >= 400 {
+		return fmt.Errorf("download URL returned error status: %d %s", headResp.StatusCode, headResp.Status)
+	}
+
+	log.Printf("[debrid-playback] download URL verified accessible (status: %d)", headResp.StatusCode)
+	return nil
+}
+
 func detectArchiveExtension(downloadURL string) string {
 	if strings.TrimSpace(downloadURL) == "" {
 		return ""
@@ -553,20 +549,34 @@ func (s *PlaybackService) CheckHealthQuick(ctx context.Context, candidate models
 
 // FilterCachedResults filters a list of results to only include cached debrid items.
 // This is useful for auto-selection or pre-filtering search results.
-// Only checks the first 3 results to minimize API calls.
+// Results already in the cache index are decided without a provider call; of the rest,
+// only the first 3 are checked to minimize API calls.
 func (s *PlaybackService) FilterCachedResults(ctx context.Context, results []models.NZBResult) []models.NZBResult {
 	var cached []models.NZBResult
 
 	log.Printf("[debrid-playback] filtering %d results for cached items (checking first 3 only)", len(results))
 
+	var providers []string
+	if settings, err := s.cfg.Load(); err == nil {
+		providers = enabledProviderNames(settings.Streaming.DebridProviders)
+	}
+
 	checked := 0
 	for i, result := range results {
 		// Only check debrid items
-		if result.ServiceType != models.ServiceTypeDebrid {
+		if result.ServiceType != models.ServiceTypeDebrid && result.ServiceType != models.ServiceTypeDirect {
 			log.Printf("[debrid-playback] [%d/%d] skipping non-debrid result: %s", i+1, len(results), result.Title)
 			continue
 		}
 
+		if cachedOn, known := availability.cachedOn(candidateInfoHash(result), providers); known {
+			log.Printf("[debrid-playback] [%d/%d] %s: cache index cached=%t providers=%v", i+1, len(results), result.Title, len(cachedOn) > 0, cachedOn)
+			if len(cachedOn) > 0 {
+				cached = append(cached, result)
+			}
+			continue
+		}
+
 		// Only check first 3 debrid results to minimize API calls
 		if checked >= 3 {
 			log.Printf("[debrid-playback] reached limit of 3 health checks, skipping remaining results")
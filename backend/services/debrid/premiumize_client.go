@@ -0,0 +1,484 @@
+package debrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// premiumizeCachedWait is how long a transfer of cached content is given to finish.
+	// Premiumize completes these almost immediately, but not always before the first status read.
+	premiumizeCachedWait = 5 * time.Second
+	premiumizePollDelay  = 500 * time.Millisecond
+)
+
+// PremiumizeClient handles API interactions with Premiumize.me.
+// It implements the Provider interface. Torrents are added as transfers, and a finished
+// transfer's cloud folder (or single file) provides the file list and direct links.
+type PremiumizeClient struct {
+	apiKey     string
+	httpClient *http.Client
+	baseURL    string
+}
+
+// Ensure PremiumizeClient implements Provider interface.
+var _ Provider = (*PremiumizeClient)(nil)
+
+// NewPremiumizeClient creates a new Premiumize.me API client.
+func NewPremiumizeClient(apiKey string) *PremiumizeClient {
+	return &PremiumizeClient{
+		apiKey:     strings.TrimSpace(apiKey),
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: newMetricsTransport("premiumize")},
+		baseURL:    "https://www.premiumize.me/api",
+	}
+}
+
+// Name returns the provider identifier.
+func (c *PremiumizeClient) Name() string {
+	return "premiumize"
+}
+
+func init() {
+	RegisterProvider("premiumize", func(apiKey string) Provider {
+		return NewPremiumizeClient(apiKey)
+	})
+}
+
+// premiumizeStatus is embedded in every API response.
+type premiumizeStatus struct {
+	Status  string `json:"status"` // "success" or "error"
+	Message string `json:"message,omitempty"`
+}
+
+// premiumizeTransfer is an entry of /transfer/list.
+type premiumizeTransfer struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Message  string  `json:"message"`
+	Status   string  `json:"status"` // waiting, queued, running, seeding, finished, error, banned, timeout, deleted
+	Progress float64 `json:"progress"`
+	Src      string  `json:"src"`
+	FolderID string  `json:"folder_id"`
+	FileID   string  `json:"file_id"`
+}
+
+// premiumizeItem is a file or folder in the Premiumize cloud.
+type premiumizeItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // "file" or "folder"
+	Size int64  `json:"size"`
+	Link string `json:"link"`
+}
+
+// do performs an authenticated API request and decodes the response into out, which must
+// embed premiumizeStatus. A form is sent as the body of POST requests.
+func (c *PremiumizeClient) do(ctx context.Context, method, endpoint string, params url.Values, out interface{ statusOf() *premiumizeStatus }) error {
+	if c.apiKey == "" {
+		return fmt.Errorf("premiumize API key not configured")
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+
+	var body io.Reader
+	target := c.baseURL + endpoint
+	if method == http.MethodGet {
+		params.Set("apikey", c.apiKey)
+		target += "?" + params.Encode()
+	} else {
+		target += "?" + url.Values{"apikey": {c.apiKey}}.Encode()
+		body = strings.NewReader(params.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return c.send(req, out)
+}
+
+func (c *PremiumizeClient) send(req *http.Request, out interface{ statusOf() *premiumizeStatus }) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("premiumize authentication failed: invalid API key")
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w (body: %s)", err, string(data))
+	}
+
+	if status := out.statusOf(); status.Status != "success" {
+		msg := status.Message
+		if msg == "" {
+			msg = "unknown error"
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
+func (s *premiumizeStatus) statusOf() *premiumizeStatus { return s }
+
+type premiumizeCreateResponse struct {
+	premiumizeStatus
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AddMagnet creates a transfer from a magnet link and returns the transfer ID.
+func (c *PremiumizeClient) AddMagnet(ctx context.Context, magnetURL string) (*AddMagnetResult, error) {
+	trimmedMagnet := strings.TrimSpace(magnetURL)
+	if trimmedMagnet == "" {
+		return nil, fmt.Errorf("magnet URL is required")
+	}
+
+	var result premiumizeCreateResponse
+	if err := c.do(ctx, http.MethodPost, "/transfer/create", url.Values{"src": {trimmedMagnet}}, &result); err != nil {
+		return nil, fmt.Errorf("add magnet failed: %w", err)
+	}
+	if result.ID == "" {
+		return nil, fmt.Errorf("no transfer ID returned")
+	}
+
+	log.Printf("[premiumize] transfer created: id=%s name=%s type=%s", result.ID, result.Name, result.Type)
+	return &AddMagnetResult{
+		ID:  result.ID,
+		URI: trimmedMagnet,
+	}, nil
+}
+
+// AddTorrentFile uploads a .torrent file as a new transfer and returns the transfer ID.
+func (c *PremiumizeClient) AddTorrentFile(ctx context.Context, torrentData []byte, filename string) (*AddMagnetResult, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("premiumize API key not configured")
+	}
+	if len(torrentData) == 0 {
+		return nil, fmt.Errorf("torrent data is empty")
+	}
+	if filename == "" {
+		filename = "upload.torrent"
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := part.Write(torrentData); err != nil {
+		return nil, fmt.Errorf("write torrent data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	endpoint := c.baseURL + "/transfer/create?" + url.Values{"apikey": {c.apiKey}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("build add torrent request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var result premiumizeCreateResponse
+	if err := c.send(req, &result); err != nil {
+		return nil, fmt.Errorf("add torrent failed: %w", err)
+	}
+	if result.ID == "" {
+		return nil, fmt.Errorf("no transfer ID returned")
+	}
+
+	log.Printf("[premiumize] torrent file uploaded: id=%s name=%s", result.ID, result.Name)
+	return &AddMagnetResult{
+		ID:  result.ID,
+		URI: filename,
+	}, nil
+}
+
+// transfers returns every transfer on the account.
+func (c *PremiumizeClient) transfers(ctx context.Context) ([]premiumizeTransfer, error) {
+	var result struct {
+		premiumizeStatus
+		Transfers []premiumizeTransfer `json:"transfers"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/transfer/list", nil, &result); err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	return result.Transfers, nil
+}
+
+// transfer looks up a transfer by ID.
+func (c *PremiumizeClient) transfer(ctx context.Context, transferID string) (*premiumizeTransfer, error) {
+	transfers, err := c.transfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range transfers {
+		if transfers[i].ID == transferID {
+			return &transfers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("transfer %s not found", transferID)
+}
+
+// ListTorrents returns every transfer on the Premiumize account without file lists.
+func (c *PremiumizeClient) ListTorrents(ctx context.Context) ([]TorrentInfo, error) {
+	transfers, err := c.transfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]TorrentInfo, 0, len(transfers))
+	for _, t := range transfers {
+		infos = append(infos, TorrentInfo{
+			ID:       t.ID,
+			Filename: t.Name,
+			Hash:     extractInfoHashFromMagnet(t.Src),
+			Status:   c.mapStatus(t.Status),
+		})
+	}
+	return infos, nil
+}
+
+// GetTorrentInfo retrieves a transfer and, once finished, the files it stored in the cloud.
+func (c *PremiumizeClient) GetTorrentInfo(ctx context.Context, torrentID string) (*TorrentInfo, error) {
+	trimmedID := strings.TrimSpace(torrentID)
+	if trimmedID == "" {
+		return nil, fmt.Errorf("torrent ID is required")
+	}
+
+	transfer, err := c.transfer(ctx, trimmedID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := extractInfoHashFromMagnet(transfer.Src)
+	status := c.mapStatus(transfer.Status)
+	if status != "downloaded" && status != "error" && hash != "" {
+		// Cached content finishes right after creation; give it a moment instead of
+		// reporting a cached torrent as downloading.
+		if cached, err := c.CheckInstantAvailability(ctx, hash); err == nil && cached {
+			deadline := time.Now().Add(premiumizeCachedWait)
+			for status != "downloaded" && time.Now().Before(deadline) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(premiumizePollDelay):
+				}
+				if transfer, err = c.transfer(ctx, trimmedID); err != nil {
+					return nil, err
+				}
+				status = c.mapStatus(transfer.Status)
+			}
+		}
+	}
+
+	info := &TorrentInfo{
+		ID:       transfer.ID,
+		Filename: transfer.Name,
+		Hash:     hash,
+		Status:   status,
+		Files:    make([]File, 0),
+		Links:    make([]string, 0),
+	}
+	if status != "downloaded" {
+		return info, nil
+	}
+
+	// A single-file transfer also reports the folder the file was placed in, which may
+	// be the cloud root, so the file ID wins.
+	switch {
+	case transfer.FileID != "":
+		var item struct {
+			premiumizeStatus
+			premiumizeItem
+		}
+		if err := c.do(ctx, http.MethodGet, "/item/details", url.Values{"id": {transfer.FileID}}, &item); err != nil {
+			return nil, fmt.Errorf("get item details: %w", err)
+		}
+		c.addFile(info, item.Name, item.premiumizeItem)
+	case transfer.FolderID != "":
+		if err := c.listFolder(ctx, transfer.FolderID, "", info); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// premiumizeFolder is the /folder/list response. The root folder has no parent.
+type premiumizeFolder struct {
+	premiumizeStatus
+	Name     string           `json:"name"`
+	ParentID string           `json:"parent_id"`
+	Content  []premiumizeItem `json:"content"`
+}
+
+// listFolder recursively adds the files of a cloud folder to info.
+func (c *PremiumizeClient) listFolder(ctx context.Context, folderID, basePath string, info *TorrentInfo) error {
+	var result premiumizeFolder
+	if err := c.do(ctx, http.MethodGet, "/folder/list", url.Values{"id": {folderID}}, &result); err != nil {
+		return fmt.Errorf("list folder: %w", err)
+	}
+
+	for _, item := range result.Content {
+		itemPath := item.Name
+		if basePath != "" {
+			itemPath = basePath + "/" + item.Name
+		}
+		if item.Type == "folder" {
+			if err := c.listFolder(ctx, item.ID, itemPath, info); err != nil {
+				return err
+			}
+			continue
+		}
+		c.addFile(info, itemPath, item)
+	}
+	return nil
+}
+
+func (c *PremiumizeClient) addFile(info *TorrentInfo, filePath string, item premiumizeItem) {
+	if item.Link == "" {
+		return
+	}
+	info.Files = append(info.Files, File{
+		ID:       len(info.Files) + 1,
+		Path:     filePath,
+		Bytes:    item.Size,
+		Selected: 1,
+	})
+	info.Links = append(info.Links, item.Link)
+	info.Bytes += item.Size
+}
+
+// mapStatus converts Premiumize transfer states to provider-agnostic status.
+func (c *PremiumizeClient) mapStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "finished", "seeding":
+		return "downloaded"
+	case "waiting", "queued":
+		return "queued"
+	case "running":
+		return "downloading"
+	case "error", "banned", "timeout", "deleted":
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// SelectFiles is a no-op for Premiumize since transfers always fetch the whole torrent.
+func (c *PremiumizeClient) SelectFiles(ctx context.Context, torrentID string, fileIDs string) error {
+	log.Printf("[premiumize] SelectFiles called for transfer %s (no-op, Premiumize fetches all files)", torrentID)
+	return nil
+}
+
+// DeleteTorrent removes a transfer and the cloud files it created.
+func (c *PremiumizeClient) DeleteTorrent(ctx context.Context, torrentID string) error {
+	trimmedID := strings.TrimSpace(torrentID)
+	if trimmedID == "" {
+		return fmt.Errorf("torrent ID is required")
+	}
+
+	// Look up the stored files first; they outlive the transfer entry
+	transfer, lookupErr := c.transfer(ctx, trimmedID)
+
+	var result premiumizeStatus
+	if err := c.do(ctx, http.MethodPost, "/transfer/delete", url.Values{"id": {trimmedID}}, &result); err != nil {
+		return fmt.Errorf("delete transfer failed: %w", err)
+	}
+
+	if lookupErr == nil {
+		switch {
+		case transfer.FileID != "":
+			if err := c.do(ctx, http.MethodPost, "/item/delete", url.Values{"id": {transfer.FileID}}, &result); err != nil {
+				log.Printf("[premiumize] failed to delete file %s of transfer %s: %v", transfer.FileID, trimmedID, err)
+			}
+		case transfer.FolderID != "":
+			if !c.ownsFolder(ctx, transfer) {
+				log.Printf("[premiumize] keeping folder %s of transfer %s: not created by the transfer", transfer.FolderID, trimmedID)
+				break
+			}
+			if err := c.do(ctx, http.MethodPost, "/folder/delete", url.Values{"id": {transfer.FolderID}}, &result); err != nil {
+				log.Printf("[premiumize] failed to delete folder %s of transfer %s: %v", transfer.FolderID, trimmedID, err)
+			}
+		}
+	}
+
+	log.Printf("[premiumize] transfer %s deleted", trimmedID)
+	return nil
+}
+
+// ownsFolder reports whether a transfer's folder was created for it: a folder named after
+// the transfer below some parent. The cloud root or a shared parent folder the user
+// pointed transfers at must never be deleted with a transfer.
+func (c *PremiumizeClient) ownsFolder(ctx context.Context, transfer *premiumizeTransfer) bool {
+	var folder premiumizeFolder
+	if err := c.do(ctx, http.MethodGet, "/folder/list", url.Values{"id": {transfer.FolderID}}, &folder); err != nil {
+		log.Printf("[premiumize] failed to inspect folder %s: %v", transfer.FolderID, err)
+		return false
+	}
+	return folder.ParentID != "" && folder.Name != "" && folder.Name == transfer.Name
+}
+
+// UnrestrictLink returns the link unchanged: Premiumize cloud links are already direct
+// download URLs.
+func (c *PremiumizeClient) UnrestrictLink(ctx context.Context, link string) (*UnrestrictResult, error) {
+	trimmedLink := strings.TrimSpace(link)
+	if trimmedLink == "" {
+		return nil, fmt.Errorf("link is required")
+	}
+
+	filename := ""
+	if parsed, err := url.Parse(trimmedLink); err == nil {
+		filename = path.Base(parsed.Path)
+	}
+	return &UnrestrictResult{
+		Filename:    filename,
+		DownloadURL: trimmedLink,
+	}, nil
+}
+
+// CheckInstantAvailability checks if a torrent hash is cached on Premiumize.
+func (c *PremiumizeClient) CheckInstantAvailability(ctx context.Context, infoHash string) (bool, error) {
+	normalizedHash := strings.ToLower(strings.TrimSpace(infoHash))
+	if normalizedHash == "" {
+		return false, fmt.Errorf("info hash is required")
+	}
+
+	var result struct {
+		premiumizeStatus
+		Response []bool `json:"response"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/cache/check", url.Values{"items[]": {normalizedHash}}, &result); err != nil {
+		return false, fmt.Errorf("cache check failed: %w", err)
+	}
+
+	cached := len(result.Response) > 0 && result.Response[0]
+	log.Printf("[premiumize] instant availability: hash %s cached=%v", normalizedHash, cached)
+	return cached, nil
+}
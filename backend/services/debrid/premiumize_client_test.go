@@ -0,0 +1,238 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+const premiumizeTestMagnet = "magnet:?xt=urn:btih:0123456789ABCDEF0123456789ABCDEF01234567&dn=Show.S01"
+
+// fakePremiumize serves a Premiumize API where the "cached" keys have everything cached
+// and the "uncached" key never finishes a transfer. A "cached" transfer lands in its own
+// folder, a "cached-file" transfer is a single file in the cloud root and a "cached-root"
+// transfer reports the cloud root as its folder.
+type fakePremiumize struct {
+	mu        sync.Mutex
+	lists     map[string]int
+	deleted   []string
+	transfers map[string]string // transfer ID -> API key
+}
+
+func (f *fakePremiumize) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r.ParseForm()
+	key := r.URL.Query().Get("apikey")
+	w.Header().Set("Content-Type", "application/json")
+
+	write := func(v interface{}) { json.NewEncoder(w).Encode(v) }
+	switch r.URL.Path {
+	case "/cache/check":
+		write(map[string]interface{}{"status": "success", "response": []bool{strings.HasPrefix(key, "cached")}})
+	case "/transfer/create":
+		if !strings.HasPrefix(r.PostForm.Get("src"), "magnet:") {
+			write(map[string]interface{}{"status": "error", "message": "invalid src"})
+			return
+		}
+		id := "tr-" + key
+		f.transfers[id] = key
+		write(map[string]interface{}{"status": "success", "id": id, "name": "Show.S01", "type": "torrent"})
+	case "/transfer/list":
+		var transfers []map[string]interface{}
+		for id, owner := range f.transfers {
+			if owner != key {
+				continue
+			}
+			f.lists[id]++
+			transfer := map[string]interface{}{"id": id, "name": "Show.S01", "src": premiumizeTestMagnet, "status": "running"}
+			// Cached content finishes shortly after the transfer is created
+			if strings.HasPrefix(key, "cached") && f.lists[id] > 1 {
+				transfer["status"] = "finished"
+				switch key {
+				case "cached":
+					transfer["folder_id"] = "folder-show"
+				case "cached-file":
+					transfer["folder_id"] = "cloud-root"
+					transfer["file_id"] = "f1"
+				case "cached-root":
+					transfer["folder_id"] = "cloud-root"
+				}
+			}
+			transfers = append(transfers, transfer)
+		}
+		write(map[string]interface{}{"status": "success", "transfers": transfers})
+	case "/folder/list":
+		switch r.URL.Query().Get("id") {
+		case "cloud-root":
+			write(map[string]interface{}{"status": "success", "name": "root", "content": []map[string]interface{}{
+				{"id": "folder-show", "name": "Show.S01", "type": "folder"},
+			}})
+		case "folder-show":
+			write(map[string]interface{}{"status": "success", "name": "Show.S01", "parent_id": "cloud-root", "content": []map[string]interface{}{
+				{"id": "f1", "name": "Show.S01E01.mkv", "type": "file", "size": 1000, "link": "https://dl.example/Show.S01E01.mkv"},
+				{"id": "folder-extras", "name": "Extras", "type": "folder"},
+			}})
+		case "folder-extras":
+			write(map[string]interface{}{"status": "success", "name": "Extras", "parent_id": "folder-show", "content": []map[string]interface{}{
+				{"id": "f2", "name": "Show.S01E02.mkv", "type": "file", "size": 2000, "link": "https://dl.example/Show.S01E02.mkv"},
+			}})
+		}
+	case "/item/details":
+		write(map[string]interface{}{"status": "success", "id": "f1", "name": "Show.S01E01.mkv", "type": "file", "size": 1000, "link": "https://dl.example/Show.S01E01.mkv"})
+	case "/transfer/delete", "/folder/delete", "/item/delete":
+		f.deleted = append(f.deleted, r.URL.Path+":"+r.PostForm.Get("id"))
+		delete(f.transfers, r.PostForm.Get("id"))
+		write(map[string]interface{}{"status": "success"})
+	case "/account/info":
+		if key != "cached" {
+			write(map[string]interface{}{"status": "error", "message": "Not logged in."})
+			return
+		}
+		write(map[string]interface{}{"status": "success", "customer_id": 12345, "premium_until": 4102444800})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newPremiumizeTestClient(t *testing.T, apiKey string) (*PremiumizeClient, *fakePremiumize) {
+	t.Helper()
+	fake := &fakePremiumize{lists: make(map[string]int), transfers: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewPremiumizeClient(apiKey)
+	client.baseURL = server.URL
+	return client, fake
+}
+
+func TestPremiumizeClient_MapsFinishedTransferToTorrentInfo(t *testing.T) {
+	client, fake := newPremiumizeTestClient(t, "cached")
+	ctx := context.Background()
+
+	added, err := client.AddMagnet(ctx, premiumizeTestMagnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first transfer read still reports it running; the client waits for the cached transfer to finish
+	info, err := client.GetTorrentInfo(ctx, added.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Status != "downloaded" || info.Hash != "0123456789abcdef0123456789abcdef01234567" || info.Bytes != 3000 {
+		t.Fatalf("unexpected torrent info %+v", info)
+	}
+	if len(info.Files) != 2 || info.Files[1].ID != 2 || info.Files[1].Path != "Extras/Show.S01E02.mkv" || info.Files[1].Selected != 1 {
+		t.Fatalf("unexpected files %+v", info.Files)
+	}
+
+	link, _, _, matched := resolveRestrictedLink(info, "2")
+	if !matched || link != "https://dl.example/Show.S01E02.mkv" {
+		t.Fatalf("expected file 2 to map to its link, got %q", link)
+	}
+	unrestricted, err := client.UnrestrictLink(ctx, link)
+	if err != nil || unrestricted.DownloadURL != link || unrestricted.Filename != "Show.S01E02.mkv" {
+		t.Fatalf("unexpected unrestrict result %+v (err %v)", unrestricted, err)
+	}
+
+	if err := client.DeleteTorrent(ctx, added.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(fake.deleted, ",") != "/transfer/delete:tr-cached,/folder/delete:folder-show" {
+		t.Fatalf("expected transfer and its folder to be deleted, got %v", fake.deleted)
+	}
+}
+
+func TestPremiumizeClient_PrefersFileAndKeepsForeignFolders(t *testing.T) {
+	cases := []struct {
+		apiKey    string
+		files     int
+		firstPath string
+		deleted   string
+	}{
+		{"cached-file", 1, "Show.S01E01.mkv", "/transfer/delete:tr-cached-file,/item/delete:f1"},
+		{"cached-root", 2, "Show.S01/Show.S01E01.mkv", "/transfer/delete:tr-cached-root"},
+	}
+	for _, tc := range cases {
+		client, fake := newPremiumizeTestClient(t, tc.apiKey)
+		ctx := context.Background()
+
+		added, err := client.AddMagnet(ctx, premiumizeTestMagnet)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.apiKey, err)
+		}
+		info, err := client.GetTorrentInfo(ctx, added.ID)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.apiKey, err)
+		}
+		if len(info.Files) != tc.files || info.Files[0].Path != tc.firstPath {
+			t.Fatalf("%s: unexpected files %+v", tc.apiKey, info.Files)
+		}
+
+		if err := client.DeleteTorrent(ctx, added.ID); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.apiKey, err)
+		}
+		if got := strings.Join(fake.deleted, ","); got != tc.deleted {
+			t.Fatalf("%s: deleted %q, want %q", tc.apiKey, got, tc.deleted)
+		}
+	}
+}
+
+func TestPremiumizeClient_MultiProviderModes(t *testing.T) {
+	for _, mode := range []config.MultiProviderMode{config.MultiProviderModeFastest, config.MultiProviderModePreferred} {
+		t.Run(string(mode), func(t *testing.T) {
+			uncached, uncachedFake := newPremiumizeTestClient(t, "uncached")
+			cached, _ := newPremiumizeTestClient(t, "cached")
+			providers := []providerEntry{
+				{config: &config.DebridProviderSettings{Name: "Premiumize (family)", Provider: "premiumize"}, client: uncached, priority: 0},
+				{config: &config.DebridProviderSettings{Name: "Premiumize", Provider: "premiumize"}, client: cached, priority: 1},
+			}
+			candidate := models.NZBResult{Title: "Show.S01", Link: premiumizeTestMagnet}
+
+			svc := &MultiProviderService{}
+			var result *ProviderCacheResult
+			var err error
+			if mode == config.MultiProviderModePreferred {
+				result, err = svc.checkPreferredMode(context.Background(), candidate, providers)
+			} else {
+				result, err = svc.checkFastestMode(context.Background(), candidate, providers)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsCached || result.Provider.Name != "Premiumize" || result.TorrentID != "tr-cached" {
+				t.Fatalf("unexpected winner %+v", result)
+			}
+
+			uncachedFake.mu.Lock()
+			defer uncachedFake.mu.Unlock()
+			if len(uncachedFake.deleted) != 1 || uncachedFake.deleted[0] != "/transfer/delete:tr-uncached" {
+				t.Fatalf("expected uncached transfer to be cleaned up, got %v", uncachedFake.deleted)
+			}
+		})
+	}
+}
+
+func TestPremiumizeClient_GetAccountInfo(t *testing.T) {
+	client, _ := newPremiumizeTestClient(t, "cached")
+	info, err := client.GetAccountInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Username != "12345" || !info.PremiumActive || info.ExpiresAt == nil || info.DaysRemaining <= 0 {
+		t.Fatalf("unexpected account info %+v", info)
+	}
+
+	unauthorized, _ := newPremiumizeTestClient(t, "other")
+	if _, err := unauthorized.GetAccountInfo(context.Background()); err == nil || !strings.Contains(err.Error(), "Not logged in") {
+		t.Fatalf("expected API error to be surfaced, got %v", err)
+	}
+}
@@ -44,6 +44,12 @@ type Configurable interface {
 	Configure(config map[string]string)
 }
 
+// TorrentLister is an optional interface for providers that can list every torrent on the
+// account. Listed torrents carry ID, name, hash and status; file lists may be omitted.
+type TorrentLister interface {
+	ListTorrents(ctx context.Context) ([]TorrentInfo, error)
+}
+
 // AddMagnetResult contains the result of adding a magnet link.
 type AddMagnetResult struct {
 	ID  string // Provider-specific torrent/download ID
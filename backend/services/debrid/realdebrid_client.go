@@ -29,7 +29,7 @@ var _ Provider = (*RealDebridClient)(nil)
 func NewRealDebridClient(apiKey string) *RealDebridClient {
 	return &RealDebridClient{
 		apiKey:     strings.TrimSpace(apiKey),
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: newMetricsTransport("realdebrid")},
 		baseURL:    "https://api.real-debrid.com/rest/1.0",
 	}
 }
@@ -348,6 +348,9 @@ func (c *RealDebridClient) AddMagnet(ctx context.Context, magnetURL string) (*Ad
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		if strings.Contains(string(body), "infringing_file") {
+			return nil, releaseErrorf("real-debrid refused the magnet as an infringing file")
+		}
 		return nil, fmt.Errorf("add magnet failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
@@ -396,6 +399,9 @@ func (c *RealDebridClient) AddTorrentFile(ctx context.Context, torrentData []byt
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		if strings.Contains(string(body), "infringing_file") {
+			return nil, releaseErrorf("real-debrid refused the torrent as an infringing file")
+		}
 		return nil, fmt.Errorf("add torrent failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
@@ -475,6 +481,49 @@ func (c *RealDebridClient) GetTorrentInfo(ctx context.Context, torrentID string)
 	return &info, nil
 }
 
+// ListTorrents returns every torrent on the Real-Debrid account. File lists are not
+// included by the list endpoint.
+func (c *RealDebridClient) ListTorrents(ctx context.Context) ([]TorrentInfo, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("real-debrid API key not configured")
+	}
+
+	endpoint := fmt.Sprintf("%s/torrents?limit=5000", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build list torrents request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+
+	resp, err := c.doWithRetry(req, 3)
+	if err != nil {
+		return nil, fmt.Errorf("list torrents request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("real-debrid authentication failed: invalid API key")
+	}
+
+	// Real-Debrid answers an empty account with 204
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("list torrents failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var torrents []TorrentInfo
+	if err := json.NewDecoder(resp.Body).Decode(&torrents); err != nil {
+		return nil, fmt.Errorf("decode list torrents response: %w", err)
+	}
+
+	return torrents, nil
+}
+
 // DeleteTorrent removes a torrent from Real-Debrid.
 func (c *RealDebridClient) DeleteTorrent(ctx context.Context, torrentID string) error {
 	if c.apiKey == "" {
@@ -0,0 +1,27 @@
+package debrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReleaseError is a resolve failure caused by the release itself: it isn't cached, has no
+// playable file, or the provider reports the torrent dead or infringing. Account, network
+// and provider-side failures stay plain errors so they never count against a release.
+type ReleaseError struct {
+	Reason string
+}
+
+func (e *ReleaseError) Error() string {
+	return e.Reason
+}
+
+func releaseErrorf(format string, args ...any) error {
+	return &ReleaseError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// IsReleaseError reports whether a resolve error was caused by the release itself.
+func IsReleaseError(err error) bool {
+	var releaseErr *ReleaseError
+	return errors.As(err, &releaseErr)
+}
@@ -13,6 +13,7 @@ type SearchRequest struct {
 	MaxResults int
 	Parsed     ParsedQuery
 	IMDBID     string // Optional IMDB ID (e.g., "tt11126994") to bypass search
+	TVDBID     string // Optional TVDB series ID for scrapers that support ID-based TV searches
 }
 
 // Scraper describes a pluggable source capable of returning torrent releases.
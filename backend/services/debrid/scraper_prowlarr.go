@@ -0,0 +1,410 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/models"
+)
+
+// Prowlarr newznab category roots used when no categories are configured.
+const (
+	prowlarrCategoryMovies = "2000"
+	prowlarrCategoryTV     = "5000"
+	// prowlarrAllTorrentIndexers is Prowlarr's pseudo indexer ID for "all torrent indexers".
+	prowlarrAllTorrentIndexers = "-2"
+)
+
+// ProwlarrScraper queries Prowlarr's search API for torrent releases.
+type ProwlarrScraper struct {
+	name       string // User-configured name for display
+	baseURL    string
+	apiKey     string
+	indexerIDs []string // Prowlarr indexer IDs to query (empty = all torrent indexers)
+	categories []string // Newznab category IDs (empty = derived from media type)
+	httpClient *http.Client
+}
+
+// NewProwlarrScraper constructs a Prowlarr scraper with the given URL and API key.
+// The name parameter is the user-configured display name (empty falls back to "Prowlarr").
+// indexerIDs and categories are optional comma-separated lists.
+func NewProwlarrScraper(baseURL, apiKey, name, indexerIDs, categories string, client *http.Client) *ProwlarrScraper {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	// Normalize URL - remove trailing slash
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &ProwlarrScraper{
+		name:       strings.TrimSpace(name),
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		indexerIDs: splitCommaList(indexerIDs),
+		categories: splitCommaList(categories),
+		httpClient: client,
+	}
+}
+
+func (p *ProwlarrScraper) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "Prowlarr"
+}
+
+// prowlarrRelease represents a single result from Prowlarr's /api/v1/search endpoint.
+type prowlarrRelease struct {
+	GUID        string             `json:"guid"`
+	Title       string             `json:"title"`
+	Size        int64              `json:"size"`
+	Indexer     string             `json:"indexer"`
+	IndexerID   int                `json:"indexerId"`
+	Seeders     *int               `json:"seeders"`
+	Leechers    *int               `json:"leechers"`
+	InfoHash    string             `json:"infoHash"`
+	MagnetURL   string             `json:"magnetUrl"`
+	DownloadURL string             `json:"downloadUrl"`
+	InfoURL     string             `json:"infoUrl"`
+	Protocol    string             `json:"protocol"`
+	ImdbID      int                `json:"imdbId"`
+	TvdbID      int                `json:"tvdbId"`
+	PublishDate string             `json:"publishDate"`
+	Categories  []prowlarrCategory `json:"categories"`
+}
+
+type prowlarrCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (p *ProwlarrScraper) Search(ctx context.Context, req SearchRequest) ([]ScrapeResult, error) {
+	cleanTitle := strings.TrimSpace(req.Parsed.Title)
+	imdbID := strings.TrimSpace(req.IMDBID)
+	tvdbID := strings.TrimSpace(req.TVDBID)
+	if cleanTitle == "" && imdbID == "" && tvdbID == "" {
+		return nil, nil
+	}
+
+	log.Printf("[prowlarr] Search called with Query=%q, ParsedTitle=%q, IMDBID=%q, TVDBID=%q, Season=%d, Episode=%d, Year=%d, MediaType=%s",
+		req.Query, cleanTitle, imdbID, tvdbID, req.Parsed.Season, req.Parsed.Episode, req.Parsed.Year, req.Parsed.MediaType)
+
+	isSeries := req.Parsed.MediaType == MediaTypeSeries || (req.Parsed.MediaType == "" && req.Parsed.Season > 0)
+
+	var (
+		results []ScrapeResult
+		err     error
+	)
+
+	// Prefer ID-based searches; they find renamed and foreign-titled releases that text search misses
+	if isSeries && (tvdbID != "" || imdbID != "") {
+		results, err = p.searchTVByID(ctx, imdbID, tvdbID, req.Parsed.Season, req.Parsed.Episode, req.Categories)
+	} else if !isSeries && imdbID != "" {
+		results, err = p.searchMovieByID(ctx, imdbID, req.Categories)
+	}
+	if err != nil {
+		log.Printf("[prowlarr] ID search failed, falling back to text search: %v", err)
+	}
+
+	if len(results) == 0 && cleanTitle != "" {
+		if isSeries {
+			results, err = p.searchTV(ctx, cleanTitle, req.Parsed.Season, req.Parsed.Episode, req.Categories)
+		} else {
+			results, err = p.searchMovie(ctx, cleanTitle, req.Parsed.Year, req.Categories)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit results
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = 50
+	}
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+
+	log.Printf("[prowlarr] Returning %d results for %q", len(results), cleanTitle)
+	return results, nil
+}
+
+// searchMovieByID performs a movie search using Prowlarr's {ImdbId:...} query token.
+func (p *ProwlarrScraper) searchMovieByID(ctx context.Context, imdbID string, categories []string) ([]ScrapeResult, error) {
+	query := fmt.Sprintf("{ImdbId:%s}", imdbID)
+	log.Printf("[prowlarr] Movie ID search: query=%q", query)
+	return p.fetchResults(ctx, "movie", query, p.resolveCategories(categories, prowlarrCategoryMovies))
+}
+
+// searchTVByID performs a TV search using TVDB (preferred) or IMDB ID plus season/episode tokens.
+func (p *ProwlarrScraper) searchTVByID(ctx context.Context, imdbID, tvdbID string, season, episode int, categories []string) ([]ScrapeResult, error) {
+	var query strings.Builder
+	if tvdbID != "" {
+		fmt.Fprintf(&query, "{TvdbId:%s}", tvdbID)
+	} else {
+		fmt.Fprintf(&query, "{ImdbId:%s}", imdbID)
+	}
+	if season > 0 {
+		fmt.Fprintf(&query, "{Season:%d}", season)
+		if episode > 0 {
+			fmt.Fprintf(&query, "{Episode:%d}", episode)
+		}
+	}
+	log.Printf("[prowlarr] TV ID search: query=%q", query.String())
+	return p.fetchResults(ctx, "tvsearch", query.String(), p.resolveCategories(categories, prowlarrCategoryTV))
+}
+
+// searchMovie performs a movie text search with title and year.
+func (p *ProwlarrScraper) searchMovie(ctx context.Context, title string, year int, categories []string) ([]ScrapeResult, error) {
+	query := title
+	if year > 0 {
+		query = fmt.Sprintf("%s %d", title, year)
+	}
+	log.Printf("[prowlarr] Movie search: query=%q", query)
+	return p.fetchResults(ctx, "search", query, p.resolveCategories(categories, prowlarrCategoryMovies))
+}
+
+// searchTV performs a TV text search with title plus season/episode tokens.
+func (p *ProwlarrScraper) searchTV(ctx context.Context, title string, season, episode int, categories []string) ([]ScrapeResult, error) {
+	query := title
+	if season > 0 {
+		query = fmt.Sprintf("%s{Season:%d}", title, season)
+		if episode > 0 {
+			query = fmt.Sprintf("%s{Episode:%d}", query, episode)
+		}
+	}
+	log.Printf("[prowlarr] TV search: query=%q", query)
+	return p.fetchResults(ctx, "tvsearch", query, p.resolveCategories(categories, prowlarrCategoryTV))
+}
+
+// resolveCategories picks request categories, then configured categories, then the media-type default.
+func (p *ProwlarrScraper) resolveCategories(requested []string, fallback string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	if len(p.categories) > 0 {
+		return p.categories
+	}
+	return []string{fallback}
+}
+
+// fetchResults calls /api/v1/search and converts the JSON response into ScrapeResults.
+func (p *ProwlarrScraper) fetchResults(ctx context.Context, searchType, query string, categories []string) ([]ScrapeResult, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("type", searchType)
+	params.Set("limit", "100")
+	indexerIDs := p.indexerIDs
+	if len(indexerIDs) == 0 {
+		indexerIDs = []string{prowlarrAllTorrentIndexers}
+	}
+	for _, id := range indexerIDs {
+		params.Add("indexerIds", id)
+	}
+	for _, cat := range categories {
+		params.Add("categories", cat)
+	}
+
+	body, err := p.doGet(ctx, "/api/v1/search", params)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseResponse(body)
+}
+
+// doGet performs an authenticated GET request against the Prowlarr API.
+func (p *ProwlarrScraper) doGet(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	apiURL := p.baseURL + path
+	if len(params) > 0 {
+		apiURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", p.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("prowlarr request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("prowlarr rejected API key (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("prowlarr returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
+
+// parseResponse parses Prowlarr's JSON release list into ScrapeResults.
+func (p *ProwlarrScraper) parseResponse(body []byte) ([]ScrapeResult, error) {
+	var releases []prowlarrRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+
+	var results []ScrapeResult
+	seen := make(map[string]struct{})
+
+	for _, rel := range releases {
+		// Prowlarr returns usenet releases too when indexers are mixed
+		if rel.Protocol != "" && !strings.EqualFold(rel.Protocol, "torrent") {
+			continue
+		}
+
+		infoHash := strings.ToLower(strings.TrimSpace(rel.InfoHash))
+		if infoHash == "" {
+			infoHash = jackettExtractInfoHash(rel.MagnetURL)
+		}
+		if infoHash == "" {
+			infoHash = jackettExtractInfoHash(rel.GUID)
+		}
+
+		// magnetUrl may be a real magnet or a Prowlarr redirect to one; only trust real magnets
+		var magnet, torrentURL string
+		switch {
+		case strings.HasPrefix(rel.MagnetURL, "magnet:"):
+			magnet = rel.MagnetURL
+		case strings.HasPrefix(rel.GUID, "magnet:"):
+			magnet = rel.GUID
+		case infoHash != "":
+			magnet = buildMagnetFromHash(infoHash, rel.Title)
+		}
+		if magnet == "" && strings.TrimSpace(rel.DownloadURL) != "" {
+			torrentURL = strings.TrimSpace(rel.DownloadURL)
+		}
+
+		if magnet == "" && infoHash == "" && torrentURL == "" {
+			log.Printf("[prowlarr] Skipping result with no magnet/infohash/torrent URL: %s", rel.Title)
+			continue
+		}
+
+		// Deduplicate - prefer infohash, fall back to torrent URL
+		dedupeKey := infoHash
+		if dedupeKey == "" {
+			dedupeKey = torrentURL
+		}
+		if _, exists := seen[dedupeKey]; exists {
+			continue
+		}
+		seen[dedupeKey] = struct{}{}
+
+		seeders := 0
+		if rel.Seeders != nil {
+			seeders = *rel.Seeders
+		}
+
+		attrs := map[string]string{}
+		if rel.IndexerID != 0 {
+			attrs["prowlarrIndexerId"] = strconv.Itoa(rel.IndexerID)
+		}
+		if rel.Leechers != nil {
+			attrs["peers"] = strconv.Itoa(*rel.Leechers)
+		}
+		if rel.ImdbID > 0 {
+			attrs["imdbid"] = fmt.Sprintf("tt%07d", rel.ImdbID)
+		}
+		if rel.TvdbID > 0 {
+			attrs["tvdbid"] = strconv.Itoa(rel.TvdbID)
+		}
+		if rel.PublishDate != "" {
+			attrs["pubDate"] = rel.PublishDate
+		}
+		if rel.InfoURL != "" {
+			attrs["infoUrl"] = rel.InfoURL
+		}
+		if len(rel.Categories) > 0 {
+			cats := make([]string, 0, len(rel.Categories))
+			for _, cat := range rel.Categories {
+				cats = append(cats, strconv.Itoa(cat.ID))
+			}
+			attrs["category"] = strings.Join(cats, ",")
+		}
+
+		tracker := strings.TrimSpace(rel.Indexer)
+		if tracker == "" {
+			tracker = "unknown"
+		}
+
+		results = append(results, ScrapeResult{
+			Title:       rel.Title,
+			Indexer:     p.Name(),
+			Magnet:      magnet,
+			InfoHash:    infoHash,
+			TorrentURL:  torrentURL,
+			FileIndex:   -1, // Prowlarr doesn't provide file index
+			SizeBytes:   rel.Size,
+			Seeders:     seeders,
+			Provider:    tracker, // Keep the individual indexer name
+			Resolution:  extractResolution(rel.Title),
+			Source:      p.Name(),
+			ServiceType: models.ServiceTypeDebrid,
+			Attributes:  attrs,
+		})
+	}
+
+	return results, nil
+}
+
+// prowlarrIndexer is the subset of /api/v1/indexer fields needed for connection tests.
+type prowlarrIndexer struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Enable   bool   `json:"enable"`
+	Protocol string `json:"protocol"`
+}
+
+// TestConnection verifies the API key and returns the number of enabled torrent indexers.
+func (p *ProwlarrScraper) TestConnection(ctx context.Context) (int, error) {
+	if _, err := p.doGet(ctx, "/api/v1/system/status", nil); err != nil {
+		return 0, err
+	}
+
+	body, err := p.doGet(ctx, "/api/v1/indexer", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var indexers []prowlarrIndexer
+	if err := json.Unmarshal(body, &indexers); err != nil {
+		return 0, fmt.Errorf("parse indexers: %w", err)
+	}
+
+	count := 0
+	for _, idx := range indexers {
+		if idx.Enable && strings.EqualFold(idx.Protocol, "torrent") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// splitCommaList splits a comma-separated config value into trimmed, non-empty entries.
+func splitCommaList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
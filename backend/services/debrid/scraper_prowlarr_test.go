@@ -0,0 +1,220 @@
+package debrid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const prowlarrSampleResponse = `[
+  {
+    "guid": "https://tracker.example/details/1",
+    "title": "The.Matrix.1999.1080p.BluRay.x264",
+    "size": 8000000000,
+    "indexer": "TrackerOne",
+    "indexerId": 3,
+    "seeders": 120,
+    "leechers": 4,
+    "infoHash": "ABCDEF1234567890ABCDEF1234567890ABCDEF12",
+    "magnetUrl": "http://prowlarr.local/3/download?link=abc",
+    "downloadUrl": "http://prowlarr.local/3/download?link=abc&file=matrix",
+    "protocol": "torrent",
+    "imdbId": 133093,
+    "categories": [{"id": 2040, "name": "Movies/HD"}]
+  },
+  {
+    "guid": "https://tracker.example/details/2",
+    "title": "The.Matrix.1999.2160p.UHD.BluRay",
+    "size": 60000000000,
+    "indexer": "TrackerTwo",
+    "indexerId": 5,
+    "seeders": 30,
+    "downloadUrl": "http://prowlarr.local/5/download?link=def",
+    "protocol": "torrent"
+  },
+  {
+    "guid": "https://usenet.example/nzb/3",
+    "title": "The.Matrix.1999.720p.WEB",
+    "size": 3000000000,
+    "indexer": "NzbIndexer",
+    "downloadUrl": "http://prowlarr.local/7/download?link=ghi",
+    "protocol": "usenet"
+  },
+  {
+    "guid": "https://tracker.example/details/4",
+    "title": "The.Matrix.1999.1080p.BluRay.x264.DUPE",
+    "size": 8000000000,
+    "indexer": "TrackerThree",
+    "infoHash": "abcdef1234567890abcdef1234567890abcdef12",
+    "protocol": "torrent"
+  }
+]`
+
+func TestProwlarrScraperName(t *testing.T) {
+	if got := NewProwlarrScraper("http://x", "k", "", "", "", nil).Name(); got != "Prowlarr" {
+		t.Errorf("expected default name Prowlarr, got %q", got)
+	}
+	if got := NewProwlarrScraper("http://x", "k", "My Prowlarr", "", "", nil).Name(); got != "My Prowlarr" {
+		t.Errorf("expected configured name, got %q", got)
+	}
+}
+
+func TestProwlarrParseResponse(t *testing.T) {
+	scraper := NewProwlarrScraper("http://prowlarr.local", "key", "", "", "", nil)
+	results, err := scraper.parseResponse([]byte(prowlarrSampleResponse))
+	if err != nil {
+		t.Fatalf("parseResponse failed: %v", err)
+	}
+
+	// usenet result skipped, duplicate infohash skipped
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	first := results[0]
+	if first.InfoHash != "abcdef1234567890abcdef1234567890abcdef12" {
+		t.Errorf("unexpected infohash: %s", first.InfoHash)
+	}
+	if first.Magnet == "" || first.TorrentURL != "" {
+		t.Errorf("expected magnet built from infohash and no torrent URL, got magnet=%q torrentURL=%q", first.Magnet, first.TorrentURL)
+	}
+	if first.Seeders != 120 {
+		t.Errorf("expected 120 seeders, got %d", first.Seeders)
+	}
+	if first.SizeBytes != 8000000000 {
+		t.Errorf("unexpected size: %d", first.SizeBytes)
+	}
+	if first.Provider != "TrackerOne" {
+		t.Errorf("expected provider TrackerOne, got %q", first.Provider)
+	}
+	if first.Attributes["imdbid"] != "tt0133093" {
+		t.Errorf("expected imdbid attribute tt0133093, got %q", first.Attributes["imdbid"])
+	}
+	if first.Resolution != "1080p" {
+		t.Errorf("expected 1080p resolution, got %q", first.Resolution)
+	}
+
+	second := results[1]
+	if second.InfoHash != "" || second.Magnet != "" {
+		t.Errorf("expected no infohash/magnet for second result, got %q/%q", second.InfoHash, second.Magnet)
+	}
+	if second.TorrentURL != "http://prowlarr.local/5/download?link=def" {
+		t.Errorf("unexpected torrent URL: %s", second.TorrentURL)
+	}
+}
+
+func TestProwlarrSearchMovieByID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Api-Key") != "testkey" {
+			t.Errorf("expected X-Api-Key header testkey, got %q", r.Header.Get("X-Api-Key"))
+		}
+		query := r.URL.Query()
+		if query.Get("type") != "movie" {
+			t.Errorf("expected type=movie, got %s", query.Get("type"))
+		}
+		if query.Get("query") != "{ImdbId:tt0133093}" {
+			t.Errorf("unexpected query %q", query.Get("query"))
+		}
+		if ids := query["indexerIds"]; len(ids) != 2 || ids[0] != "3" || ids[1] != "5" {
+			t.Errorf("unexpected indexerIds %v", ids)
+		}
+		if cats := query["categories"]; len(cats) != 1 || cats[0] != "2000" {
+			t.Errorf("unexpected categories %v", cats)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(prowlarrSampleResponse))
+	}))
+	defer server.Close()
+
+	scraper := NewProwlarrScraper(server.URL, "testkey", "", "3, 5", "", nil)
+	results, err := scraper.Search(context.Background(), SearchRequest{
+		Parsed: ParsedQuery{Title: "The Matrix", Year: 1999, MediaType: MediaTypeMovie},
+		IMDBID: "tt0133093",
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+}
+
+func TestProwlarrSearchTVFallsBackToText(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("type") != "tvsearch" {
+			t.Errorf("expected type=tvsearch, got %s", query.Get("type"))
+		}
+		if cats := query["categories"]; len(cats) != 1 || cats[0] != "5000" {
+			t.Errorf("unexpected categories %v", cats)
+		}
+		if ids := query["indexerIds"]; len(ids) != 1 || ids[0] != "-2" {
+			t.Errorf("expected all-torrent indexer selector, got %v", ids)
+		}
+		queries = append(queries, query.Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+		if len(queries) == 1 {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"title":"Breaking.Bad.S05E16.1080p.BluRay","size":3000000000,"indexer":"T","seeders":10,"infoHash":"1234567890abcdef1234567890abcdef12345678","protocol":"torrent"}]`))
+	}))
+	defer server.Close()
+
+	scraper := NewProwlarrScraper(server.URL, "testkey", "", "", "", nil)
+	results, err := scraper.Search(context.Background(), SearchRequest{
+		Parsed: ParsedQuery{Title: "Breaking Bad", Season: 5, Episode: 16, MediaType: MediaTypeSeries},
+		TVDBID: "81189",
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected ID search followed by text search, got %d requests", len(queries))
+	}
+	if queries[0] != "{TvdbId:81189}{Season:5}{Episode:16}" {
+		t.Errorf("unexpected ID query %q", queries[0])
+	}
+	if queries[1] != "Breaking Bad{Season:5}{Episode:16}" {
+		t.Errorf("unexpected text query %q", queries[1])
+	}
+}
+
+func TestProwlarrTestConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/system/status":
+			w.Write([]byte(`{"version":"1.20.0"}`))
+		case "/api/v1/indexer":
+			w.Write([]byte(`[{"id":1,"enable":true,"protocol":"torrent"},{"id":2,"enable":false,"protocol":"torrent"},{"id":3,"enable":true,"protocol":"usenet"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	count, err := NewProwlarrScraper(server.URL, "good", "", "", "", nil).TestConnection(context.Background())
+	if err != nil {
+		t.Fatalf("TestConnection failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 enabled torrent indexer, got %d", count)
+	}
+
+	if _, err := NewProwlarrScraper(server.URL, "bad", "", "", "", nil).TestConnection(context.Background()); err == nil {
+		t.Error("expected error for invalid API key")
+	}
+}
@@ -0,0 +1,397 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/models"
+)
+
+// stremioManifestRetry is how long a failed manifest fetch is remembered before retrying.
+const stremioManifestRetry = 5 * time.Minute
+
+// StremioAddonScraper queries any Stremio addon (Comet, MediaFusion, Jackettio, ...) through
+// its manifest URL. Torrent streams become debrid results; streams that already carry a
+// playable URL are returned as direct results.
+type StremioAddonScraper struct {
+	name       string // User-configured name for display
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	manifest    *StremioManifest
+	manifestErr error
+	fetchedAt   time.Time
+}
+
+// StremioManifest is the subset of an addon manifest needed to query its streams.
+type StremioManifest struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	Types      []string          `json:"types"`
+	IDPrefixes []string          `json:"idPrefixes"`
+	Resources  []stremioResource `json:"resources"`
+}
+
+// stremioResource is a manifest resource, given either as a bare name or as an object that
+// overrides the manifest-level types and ID prefixes.
+type stremioResource struct {
+	Name       string   `json:"name"`
+	Types      []string `json:"types"`
+	IDPrefixes []string `json:"idPrefixes"`
+}
+
+func (r *stremioResource) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		r.Name = name
+		return nil
+	}
+	type plain stremioResource
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// streamSupport returns the types and ID prefixes the addon serves streams for. It returns
+// false when the addon has no stream resource.
+func (m *StremioManifest) streamSupport() (types, idPrefixes []string, ok bool) {
+	for _, res := range m.Resources {
+		if res.Name != "stream" {
+			continue
+		}
+		types, idPrefixes = m.Types, m.IDPrefixes
+		if len(res.Types) > 0 {
+			types = res.Types
+		}
+		if len(res.IDPrefixes) > 0 {
+			idPrefixes = res.IDPrefixes
+		}
+		return types, idPrefixes, true
+	}
+	return nil, nil, false
+}
+
+// NewStremioAddonScraper constructs a scraper for a Stremio addon.
+// The manifestURL may point at manifest.json or the addon root, and stremio:// links are
+// accepted. The name parameter is the user-configured display name (empty falls back to the
+// addon's own name).
+func NewStremioAddonScraper(manifestURL, name string, client *http.Client) *StremioAddonScraper {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	baseURL := strings.TrimSpace(manifestURL)
+	if strings.HasPrefix(strings.ToLower(baseURL), "stremio://") {
+		baseURL = "https://" + baseURL[len("stremio://"):]
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/manifest.json")
+	return &StremioAddonScraper{
+		name:       strings.TrimSpace(name),
+		baseURL:    baseURL,
+		httpClient: client,
+	}
+}
+
+func (s *StremioAddonScraper) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.manifest != nil && strings.TrimSpace(s.manifest.Name) != "" {
+		return strings.TrimSpace(s.manifest.Name)
+	}
+	return "stremio"
+}
+
+// Manifest returns the addon manifest, fetching it on first use.
+func (s *StremioAddonScraper) Manifest(ctx context.Context) (*StremioManifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.manifest != nil {
+		return s.manifest, nil
+	}
+	if s.manifestErr != nil && time.Since(s.fetchedAt) < stremioManifestRetry {
+		return nil, s.manifestErr
+	}
+
+	manifest, err := s.fetchManifest(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.manifestErr = err
+			s.fetchedAt = time.Now()
+		}
+		return nil, err
+	}
+	s.manifest = manifest
+	s.manifestErr = nil
+	log.Printf("[stremio] %s manifest: %s v%s types=%v idPrefixes=%v", s.baseURL, manifest.Name, manifest.Version, manifest.Types, manifest.IDPrefixes)
+	return manifest, nil
+}
+
+func (s *StremioAddonScraper) fetchManifest(ctx context.Context) (*StremioManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/manifest.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	addBrowserHeaders(req)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("manifest returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var manifest StremioManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	if _, _, ok := manifest.streamSupport(); !ok {
+		return nil, fmt.Errorf("addon %q does not provide streams", manifest.Name)
+	}
+	return &manifest, nil
+}
+
+func (s *StremioAddonScraper) Search(ctx context.Context, req SearchRequest) ([]ScrapeResult, error) {
+	// Stremio addons look streams up by ID, they don't support text search
+	imdbID := strings.TrimSpace(req.IMDBID)
+	if imdbID == "" {
+		log.Printf("[stremio] %s: no IMDB ID provided, skipping search", s.Name())
+		return nil, nil
+	}
+	if !strings.HasPrefix(strings.ToLower(imdbID), "tt") {
+		imdbID = "tt" + imdbID
+	}
+
+	manifest, err := s.Manifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("stremio addon %s: %w", s.baseURL, err)
+	}
+	types, idPrefixes, _ := manifest.streamSupport()
+	if !stremioAcceptsID(idPrefixes, imdbID) {
+		log.Printf("[stremio] %s does not accept IMDB IDs (prefixes %v), skipping", s.Name(), idPrefixes)
+		return nil, nil
+	}
+
+	var (
+		results []ScrapeResult
+		errs    []error
+		seen    = make(map[string]struct{})
+	)
+
+	for _, mediaType := range determineMediaCandidates(req.Parsed.MediaType) {
+		stremioType := "movie"
+		streamID := imdbID
+		if mediaType == MediaTypeSeries {
+			stremioType = "series"
+			if req.Parsed.Season > 0 && req.Parsed.Episode > 0 {
+				streamID = fmt.Sprintf("%s:%d:%d", imdbID, req.Parsed.Season, req.Parsed.Episode)
+			}
+		}
+		if !containsFold(types, stremioType) {
+			continue
+		}
+
+		streams, err := s.fetchStreams(ctx, stremioType, streamID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stremio %s %s: %w", stremioType, streamID, err))
+			continue
+		}
+
+		for _, stream := range streams {
+			result, ok := s.toScrapeResult(stream, manifest.ID, imdbID, req.Parsed.Title)
+			if !ok {
+				continue
+			}
+			guid := fmt.Sprintf("%s:%s:%d:%s", s.Name(), result.InfoHash, result.FileIndex, result.TorrentURL)
+			if _, exists := seen[guid]; exists {
+				continue
+			}
+			seen[guid] = struct{}{}
+			results = append(results, result)
+
+			if req.MaxResults > 0 && len(results) >= req.MaxResults {
+				return results, nil
+			}
+		}
+	}
+
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	log.Printf("[stremio] %s found %d streams for %s", s.Name(), len(results), imdbID)
+	return results, nil
+}
+
+type stremioStreamResponse struct {
+	Streams []stremioStream `json:"streams"`
+}
+
+type stremioStream struct {
+	Name          string   `json:"name"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	InfoHash      string   `json:"infoHash"`
+	FileIdx       *int     `json:"fileIdx"`
+	URL           string   `json:"url"`
+	Sources       []string `json:"sources"`
+	BehaviorHints struct {
+		BingeGroup string `json:"bingeGroup"`
+		Filename   string `json:"filename"`
+		VideoSize  int64  `json:"videoSize"`
+	} `json:"behaviorHints"`
+}
+
+func (s *StremioAddonScraper) fetchStreams(ctx context.Context, mediaType, id string) ([]stremioStream, error) {
+	endpoint := fmt.Sprintf("%s/stream/%s/%s.json", s.baseURL, mediaType, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	addBrowserHeaders(req)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload stremioStreamResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode stream response: %w", err)
+	}
+	return payload.Streams, nil
+}
+
+// toScrapeResult maps a stream to a torrent result when it has an info hash, or to a direct
+// result when it has a playable URL. Other streams (external links, YouTube) are skipped.
+func (s *StremioAddonScraper) toScrapeResult(stream stremioStream, addonID, imdbID, metaName string) (ScrapeResult, bool) {
+	infoHash := strings.ToLower(strings.TrimSpace(stream.InfoHash))
+	streamURL := strings.TrimSpace(stream.URL)
+	if infoHash == "" && streamURL == "" {
+		return ScrapeResult{}, false
+	}
+
+	name := strings.TrimSpace(stream.Name)
+	description := strings.TrimSpace(stream.Description)
+	if description == "" {
+		description = strings.TrimSpace(stream.Title)
+	}
+
+	filename := strings.TrimSpace(stream.BehaviorHints.Filename)
+	title := filename
+	if title == "" {
+		title = deriveTitle(description)
+	}
+	if title == "" && streamURL != "" {
+		title = extractFilenameFromURL(streamURL)
+	}
+
+	sizeBytes := stream.BehaviorHints.VideoSize
+	if sizeBytes == 0 {
+		sizeBytes = parseSize(description)
+	}
+	if sizeBytes == 0 {
+		sizeBytes = parseAIODescription(description).sizeBytes
+	}
+
+	attrs := map[string]string{
+		"scraper":   "stremio",
+		"raw_title": title,
+	}
+	if addonID != "" {
+		attrs["addon"] = addonID
+	}
+	if name != "" {
+		attrs["label"] = name
+	}
+	if description != "" {
+		attrs["raw_description"] = description
+	}
+	if stream.BehaviorHints.BingeGroup != "" {
+		attrs["bingeGroup"] = stream.BehaviorHints.BingeGroup
+	}
+
+	result := ScrapeResult{
+		Title:      title,
+		Indexer:    s.Name(),
+		SizeBytes:  sizeBytes,
+		Seeders:    parseInt(nil, description),
+		Provider:   parseProvider(description),
+		Languages:  extractLanguagesFromDesc(description),
+		Resolution: detectResolution(name, title+" "+description),
+		MetaName:   metaName,
+		MetaID:     imdbID,
+		Source:     s.Name(),
+		Attributes: attrs,
+	}
+
+	if infoHash != "" {
+		fileIdx := 0
+		if stream.FileIdx != nil {
+			fileIdx = *stream.FileIdx
+		}
+		result.InfoHash = infoHash
+		result.Magnet = buildMagnet(infoHash, stremioTrackers(stream.Sources))
+		result.FileIndex = fileIdx
+		result.ServiceType = models.ServiceTypeDebrid
+		return result, true
+	}
+
+	// The addon already resolved the stream (e.g. through its own debrid account)
+	attrs["stream_url"] = streamURL
+	attrs["preresolved"] = "true"
+	result.TorrentURL = streamURL
+	result.ServiceType = models.ServiceTypeDirect
+	return result, true
+}
+
+// stremioTrackers extracts announce URLs from a stream's "tracker:" sources.
+func stremioTrackers(sources []string) []string {
+	var trackers []string
+	for _, source := range sources {
+		if tracker, ok := strings.CutPrefix(strings.TrimSpace(source), "tracker:"); ok && tracker != "" {
+			trackers = append(trackers, tracker)
+		}
+	}
+	return trackers
+}
+
+// stremioAcceptsID reports whether an ID matches the addon's prefixes. Addons without
+// prefixes accept any ID.
+func stremioAcceptsID(prefixes []string, id string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
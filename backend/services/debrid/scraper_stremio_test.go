@@ -0,0 +1,112 @@
+package debrid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"novastream/models"
+)
+
+func newStremioTestServer(t *testing.T, manifest string, requests *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/cfg/manifest.json":
+			w.Write([]byte(manifest))
+		case "/cfg/stream/series/tt0903747:1:2.json":
+			w.Write([]byte(`{"streams":[
+				{"name":"Comet 1080p","description":"Breaking.Bad.S01E02.1080p.BluRay.x264\n💾 1.5 GB 👤 42 ⚙️ ThePirateBay","infoHash":"ABCDEF0123456789ABCDEF0123456789ABCDEF01","fileIdx":3,"sources":["tracker:udp://tracker.example:1337/announce","dht:ABCDEF"],"behaviorHints":{"bingeGroup":"comet|1080p","filename":"Breaking.Bad.S01E02.1080p.BluRay.x264.mkv","videoSize":1610612736}},
+				{"name":"MediaFusion 4K","title":"Breaking.Bad.S01E02.2160p.WEB-DL","url":"https://cdn.example.com/dl/Breaking.Bad.S01E02.2160p.WEB-DL.mkv","behaviorHints":{"bingeGroup":"mf|2160p"}},
+				{"name":"Trailer","externalUrl":"https://example.com/trailer"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStremioAddonScraper_MapsTorrentAndDirectStreams(t *testing.T) {
+	var requests []string
+	server := newStremioTestServer(t, `{
+		"id":"comet.example","name":"Comet","version":"1.0.0",
+		"types":["movie","series"],
+		"resources":["catalog",{"name":"stream","types":["series"],"idPrefixes":["tt"]}]
+	}`, &requests)
+
+	scraper := NewStremioAddonScraper(server.URL+"/cfg/manifest.json", "", nil)
+	results, err := scraper.Search(context.Background(), SearchRequest{
+		IMDBID: "tt0903747",
+		Parsed: ParsedQuery{Title: "Breaking Bad", Season: 1, Episode: 2, MediaType: MediaTypeSeries},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if scraper.Name() != "Comet" {
+		t.Errorf("expected addon name to be used, got %q", scraper.Name())
+	}
+
+	torrent := results[0]
+	if torrent.ServiceType != models.ServiceTypeDebrid || torrent.InfoHash != "abcdef0123456789abcdef0123456789abcdef01" || torrent.FileIndex != 3 {
+		t.Errorf("unexpected torrent result %+v", torrent)
+	}
+	if torrent.Title != "Breaking.Bad.S01E02.1080p.BluRay.x264.mkv" || torrent.SizeBytes != 1610612736 || torrent.Seeders != 42 {
+		t.Errorf("unexpected torrent details %+v", torrent)
+	}
+	if torrent.Magnet != "magnet:?xt=urn:btih:ABCDEF0123456789ABCDEF0123456789ABCDEF01&tr=udp%3A%2F%2Ftracker.example%3A1337%2Fannounce" {
+		t.Errorf("unexpected magnet %q", torrent.Magnet)
+	}
+	if torrent.Attributes["bingeGroup"] != "comet|1080p" || torrent.Resolution != "1080p" {
+		t.Errorf("unexpected attributes %+v", torrent.Attributes)
+	}
+
+	direct := results[1]
+	if direct.ServiceType != models.ServiceTypeDirect || direct.InfoHash != "" {
+		t.Errorf("unexpected direct result %+v", direct)
+	}
+	if direct.Attributes["stream_url"] != "https://cdn.example.com/dl/Breaking.Bad.S01E02.2160p.WEB-DL.mkv" || direct.Attributes["preresolved"] != "true" {
+		t.Errorf("unexpected direct attributes %+v", direct.Attributes)
+	}
+	if direct.Title != "Breaking.Bad.S01E02.2160p.WEB-DL" || direct.Resolution != "2160p" {
+		t.Errorf("unexpected direct details %+v", direct)
+	}
+
+	normalized := normalizeScrapeResult(direct)
+	if normalized.ServiceType != models.ServiceTypeDirect || normalized.Link != direct.TorrentURL {
+		t.Errorf("unexpected normalized direct result %+v", normalized)
+	}
+}
+
+func TestStremioAddonScraper_RespectsManifest(t *testing.T) {
+	var requests []string
+	server := newStremioTestServer(t, `{
+		"id":"kitsu.example","name":"Anime","version":"1.0.0",
+		"types":["series"],"idPrefixes":["kitsu:"],
+		"resources":["stream"]
+	}`, &requests)
+
+	scraper := NewStremioAddonScraper(server.URL+"/cfg", "Anime Addon", nil)
+	results, err := scraper.Search(context.Background(), SearchRequest{
+		IMDBID: "tt0903747",
+		Parsed: ParsedQuery{Title: "Breaking Bad", Season: 1, Episode: 2, MediaType: MediaTypeSeries},
+	})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected addon without IMDB support to be skipped, got %d results, err %v", len(results), err)
+	}
+
+	// The manifest is cached and the addon is never asked for streams it can't serve
+	if _, err := scraper.Search(context.Background(), SearchRequest{IMDBID: "tt0133093", Parsed: ParsedQuery{MediaType: MediaTypeMovie}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(requests) != 1 || requests[0] != "/cfg/manifest.json" {
+		t.Fatalf("expected a single cached manifest request, got %v", requests)
+	}
+}
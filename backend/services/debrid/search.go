@@ -36,6 +36,7 @@ type SearchOptions struct {
 	Categories          []string
 	MaxResults          int
 	IMDBID              string                       // Optional IMDB ID to bypass metadata search
+	TVDBID              string                       // Optional TVDB series ID for ID-based TV searches
 	MediaType           string                       // Optional: "movie" or "series" - helps with filtering
 	Year                int                          // Optional: Release year - helps with filtering
 	AlternateTitles     []string                     // Optional: alternate or foreign titles for fuzzy filtering
@@ -43,6 +44,7 @@ type SearchOptions struct {
 	ClientID            string                       // Optional: client ID for per-client filtering settings
 	TotalSeriesEpisodes int                          // Deprecated: use EpisodeResolver instead
 	EpisodeResolver     filter.EpisodeCountResolver  // Optional: resolver for accurate episode counts from metadata
+	Rejections          *filter.Rejections           // Optional: records the results that are dropped and why
 }
 
 // SearchService coordinates queries against configured debrid providers.
@@ -105,6 +107,15 @@ func buildScrapersFromConfig(cfg *config.Manager) []Scraper {
 			}
 			log.Printf("[debrid] Initializing Jackett scraper: %s at %s", scraperCfg.Name, scraperCfg.URL)
 			scrapers = append(scrapers, NewJackettScraper(scraperCfg.URL, scraperCfg.APIKey, scraperCfg.Name, httpClient))
+		case "prowlarr":
+			if scraperCfg.URL == "" || scraperCfg.APIKey == "" {
+				log.Printf("[debrid] Skipping Prowlarr scraper %s: missing URL or API key", scraperCfg.Name)
+				continue
+			}
+			indexerIDs := scraperCfg.Config["indexerIds"]
+			categories := scraperCfg.Config["categories"]
+			log.Printf("[debrid] Initializing Prowlarr scraper: %s at %s (indexers: %q, categories: %q)", scraperCfg.Name, scraperCfg.URL, indexerIDs, categories)
+			scrapers = append(scrapers, NewProwlarrScraper(scraperCfg.URL, scraperCfg.APIKey, scraperCfg.Name, indexerIDs, categories, httpClient))
 		case "zilean":
 			if scraperCfg.URL == "" {
 				log.Printf("[debrid] Skipping Zilean scraper %s: missing URL", scraperCfg.Name)
@@ -120,6 +131,13 @@ func buildScrapersFromConfig(cfg *config.Manager) []Scraper {
 			passthroughFormat := scraperCfg.Config["passthroughFormat"] == "true"
 			log.Printf("[debrid] Initializing AIOStreams scraper: %s at %s (passthrough=%v)", scraperCfg.Name, scraperCfg.URL, passthroughFormat)
 			scrapers = append(scrapers, NewAIOStreamsScraper(scraperCfg.URL, scraperCfg.Name, passthroughFormat, httpClient))
+		case "stremio":
+			if scraperCfg.URL == "" {
+				log.Printf("[debrid] Skipping Stremio addon scraper %s: missing manifest URL", scraperCfg.Name)
+				continue
+			}
+			log.Printf("[debrid] Initializing Stremio addon scraper: %s at %s", scraperCfg.Name, scraperCfg.URL)
+			scrapers = append(scrapers, NewStremioAddonScraper(scraperCfg.URL, scraperCfg.Name, httpClient))
 		case "nyaa":
 			baseURL := scraperCfg.URL
 			if baseURL == "" {
@@ -317,6 +335,7 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 		MaxResults: opts.MaxResults,
 		Parsed:     parsed,
 		IMDBID:     imdbID,
+		TVDBID:     strings.TrimSpace(opts.TVDBID),
 	}
 	log.Printf("[debrid] Using metadata: Title=%q, Season=%d, Episode=%d, Year=%d, MediaType=%s, IMDBID=%s",
 		parsed.Title, parsed.Season, parsed.Episode, parsed.Year, parsed.MediaType, imdbID)
@@ -365,6 +384,7 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 	)
 
 	for sr := range resultsChan {
+		observeScraperSearch(sr.name, sr.elapsed, len(sr.results), sr.err)
 		if sr.err != nil {
 			log.Printf("[debrid] %s search failed: %v", sr.name, sr.err)
 			errs = append(errs, fmt.Errorf("%s scraper: %w", sr.name, sr.err))
@@ -415,15 +435,22 @@ func (s *SearchService) Search(ctx context.Context, opts SearchOptions) ([]model
 			FilterOutTerms:      filterSettings.FilterOutTerms,
 			TotalSeriesEpisodes: opts.TotalSeriesEpisodes,
 			EpisodeResolver:     opts.EpisodeResolver,
+			Rejections:          opts.Rejections,
 		}
 		aggregate = FilterResults(aggregate, filterOpts)
 	}
 
 	// Apply MaxResults limit after filtering
 	if opts.MaxResults > 0 && len(aggregate) > opts.MaxResults {
+		for _, dropped := range aggregate[opts.MaxResults:] {
+			opts.Rejections.Add(dropped, filter.RuleMaxResults, fmt.Sprintf("beyond the first %d debrid results", opts.MaxResults))
+		}
 		aggregate = aggregate[:opts.MaxResults]
 	}
 
+	// Mark results whose cache state on the enabled providers is already known
+	availability.annotate(aggregate, enabledProviderNames(settings.Streaming.DebridProviders))
+
 	return aggregate, nil
 }
 
@@ -456,7 +483,10 @@ func normalizeScrapeResult(res ScrapeResult) models.NZBResult {
 		SizeBytes:   res.SizeBytes,
 		Categories:  nil,
 		Attributes:  map[string]string{},
-		ServiceType: models.ServiceTypeDebrid,
+		ServiceType: res.ServiceType,
+	}
+	if result.ServiceType == models.ServiceTypeUnknown {
+		result.ServiceType = models.ServiceTypeDebrid
 	}
 
 	if res.InfoHash != "" {
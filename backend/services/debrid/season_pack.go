@@ -0,0 +1,120 @@
+package debrid
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"novastream/internal/mediaresolve"
+	"novastream/models"
+)
+
+// torrentPack returns the episode file map of a resolved torrent that holds more than one
+// episode, or nil for movies and single episodes. Only selected files are mapped as only
+// those have download links.
+func torrentPack(provider, torrentID, infoHash string, candidate models.NZBResult, info *TorrentInfo) *models.SeasonPack {
+	if info == nil {
+		return nil
+	}
+
+	byEpisode := make(map[mediaresolve.EpisodeCode]models.PackFile)
+	for _, file := range info.Files {
+		if file.Selected == 0 {
+			continue
+		}
+		if _, ok := mediaExtensionPriority[strings.ToLower(path.Ext(file.Path))]; !ok {
+			continue
+		}
+		code, ok := mediaresolve.ExtractEpisodeCode(path.Base(file.Path), file.Path)
+		if !ok || code.Season <= 0 || code.Episode <= 0 {
+			continue
+		}
+		// Samples and extras share the episode code; the episode itself is the largest file
+		if existing, dup := byEpisode[code]; dup && existing.Size >= file.Bytes {
+			continue
+		}
+		byEpisode[code] = models.PackFile{
+			ID:            fmt.Sprintf("%d", file.ID),
+			Path:          file.Path,
+			Size:          file.Bytes,
+			SeasonNumber:  code.Season,
+			EpisodeNumber: code.Episode,
+		}
+	}
+	if len(byEpisode) < 2 {
+		return nil
+	}
+
+	pack := &models.SeasonPack{
+		ServiceType: models.ServiceTypeDebrid,
+		Provider:    provider,
+		TorrentID:   torrentID,
+		InfoHash:    strings.ToLower(strings.TrimSpace(infoHash)),
+		Release:     candidate,
+	}
+	for _, file := range byEpisode {
+		pack.Files = append(pack.Files, file)
+	}
+	sort.Slice(pack.Files, func(i, j int) bool {
+		if pack.Files[i].SeasonNumber != pack.Files[j].SeasonNumber {
+			return pack.Files[i].SeasonNumber < pack.Files[j].SeasonNumber
+		}
+		return pack.Files[i].EpisodeNumber < pack.Files[j].EpisodeNumber
+	})
+	return pack
+}
+
+// ResolvePackFile resolves an episode of a pack torrent that is still on the debrid account,
+// without adding the torrent or selecting files again.
+func (s *PlaybackService) ResolvePackFile(ctx context.Context, pack models.SeasonPack, file models.PackFile) (*models.PlaybackResolution, error) {
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load settings: %w", err)
+	}
+
+	var client Provider
+	for _, p := range settings.Streaming.DebridProviders {
+		if p.Enabled && strings.TrimSpace(p.APIKey) != "" && strings.EqualFold(p.Provider, pack.Provider) {
+			client, _ = GetProvider(strings.ToLower(p.Provider), p.APIKey)
+			break
+		}
+	}
+	if client == nil {
+		return nil, fmt.Errorf("provider %q not configured or not enabled", pack.Provider)
+	}
+
+	info, err := client.GetTorrentInfo(ctx, pack.TorrentID)
+	if err != nil {
+		return nil, fmt.Errorf("get torrent info: %w", err)
+	}
+	if status := strings.ToLower(info.Status); status != "downloaded" {
+		return nil, fmt.Errorf("pack torrent is no longer cached (status: %s)", info.Status)
+	}
+
+	downloadURL, filename, _, matched := resolveRestrictedLink(info, file.ID)
+	if !matched {
+		return nil, fmt.Errorf("file %s is no longer part of the pack torrent", file.ID)
+	}
+	if strings.HasPrefix(downloadURL, "http://") || strings.HasPrefix(downloadURL, "https://") {
+		if err := verifyDownloadURL(ctx, downloadURL); err != nil {
+			return nil, err
+		}
+	}
+
+	webdavPath := fmt.Sprintf("/debrid/%s/%s/file/%s", client.Name(), pack.TorrentID, file.ID)
+	if filename != "" {
+		webdavPath = fmt.Sprintf("%s/%s", webdavPath, filename)
+	}
+	log.Printf("[debrid-playback] resolved S%02dE%02d from pack torrent %s: %s", file.SeasonNumber, file.EpisodeNumber, pack.TorrentID, webdavPath)
+
+	return &models.PlaybackResolution{
+		WebDAVPath:    webdavPath,
+		HealthStatus:  "cached",
+		FileSize:      file.Size,
+		SourceNZBPath: downloadURL,
+		Pack:          &pack,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package debrid
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestTorrentPackMapsSelectedEpisodes(t *testing.T) {
+	info := &TorrentInfo{
+		ID: "TID",
+		Files: []File{
+			{ID: 1, Path: "/Show.S01.1080p/Show.S01E01.1080p.mkv", Bytes: 1000, Selected: 1},
+			{ID: 2, Path: "/Show.S01.1080p/Show.S01E02.1080p.mkv", Bytes: 1100, Selected: 1},
+			{ID: 3, Path: "/Show.S01.1080p/Sample/Show.S01E02.sample.mkv", Bytes: 10, Selected: 1},
+			{ID: 4, Path: "/Show.S01.1080p/Show.S01E03.1080p.mkv", Bytes: 1200, Selected: 0},
+			{ID: 5, Path: "/Show.S01.1080p/Show.S01E01.nfo", Bytes: 1, Selected: 1},
+		},
+	}
+
+	pack := torrentPack("realdebrid", "TID", "ABCDEF", models.NZBResult{Title: "Show.S01.1080p"}, info)
+	if pack == nil {
+		t.Fatal("expected a pack")
+	}
+	if pack.TorrentID != "TID" || pack.InfoHash != "abcdef" || pack.ServiceType != models.ServiceTypeDebrid {
+		t.Fatalf("unexpected pack identity: %+v", pack)
+	}
+	if len(pack.Files) != 2 {
+		t.Fatalf("expected 2 episode files, got %+v", pack.Files)
+	}
+	if file, ok := pack.File(1, 2); !ok || file.ID != "2" {
+		t.Fatalf("expected the episode over the sample for S01E02, got %+v", file)
+	}
+	if _, ok := pack.File(1, 3); ok {
+		t.Fatal("unselected files have no link and must not be mapped")
+	}
+}
+
+func TestTorrentPackIgnoresSingleEpisodes(t *testing.T) {
+	info := &TorrentInfo{
+		Files: []File{
+			{ID: 1, Path: "/Show.S01E01.1080p.mkv", Bytes: 1000, Selected: 1},
+		},
+	}
+	if pack := torrentPack("realdebrid", "TID", "", models.NZBResult{}, info); pack != nil {
+		t.Fatalf("expected no pack for a single episode, got %+v", pack)
+	}
+}
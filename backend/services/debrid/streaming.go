@@ -117,6 +117,7 @@ func (p *StreamingProvider) GetDirectURL(ctx context.Context, path string) (stri
 	if err != nil {
 		return "", err
 	}
+	addedTorrents.played(provider, torrentID)
 
 	// Check cache first
 	cacheKey := cacheKeyFor(torrentID, fileID)
@@ -199,6 +200,7 @@ func (p *StreamingProvider) Stream(ctx context.Context, req streaming.Request) (
 	if err != nil {
 		return nil, err
 	}
+	addedTorrents.played(provider, torrentID)
 
 	log.Printf("[debrid-stream] streaming request: provider=%s torrentID=%s fileID=%s method=%s range=%q",
 		provider, torrentID, fileID, req.Method, req.RangeHeader)
@@ -32,7 +32,7 @@ var _ Configurable = (*TorboxClient)(nil)
 func NewTorboxClient(apiKey string) *TorboxClient {
 	return &TorboxClient{
 		apiKey:     strings.TrimSpace(apiKey),
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: newMetricsTransport("torbox")},
 		baseURL:    "https://api.torbox.app/v1/api",
 	}
 }
@@ -374,13 +374,18 @@ func (c *TorboxClient) GetTorrentInfo(ctx context.Context, torrentID string) (*T
 		}
 	}
 
-	// Convert to provider-agnostic TorrentInfo
+	return c.toTorrentInfo(torrent), nil
+}
+
+// toTorrentInfo converts a Torbox torrent to the provider-agnostic TorrentInfo.
+func (c *TorboxClient) toTorrentInfo(torrent torboxTorrent) *TorrentInfo {
 	info := &TorrentInfo{
 		ID:       strconv.Itoa(torrent.ID),
 		Filename: torrent.Name,
 		Hash:     torrent.Hash,
 		Bytes:    torrent.Size,
 		Status:   c.mapDownloadState(torrent.DownloadState),
+		Added:    torrent.CreatedAt,
 		Files:    make([]File, 0, len(torrent.Files)),
 		Links:    make([]string, 0, len(torrent.Files)),
 	}
@@ -398,7 +403,7 @@ func (c *TorboxClient) GetTorrentInfo(ctx context.Context, torrentID string) (*T
 		info.Links = append(info.Links, fmt.Sprintf("%d:%d", torrent.ID, f.ID))
 	}
 
-	return info, nil
+	return info
 }
 
 // mapDownloadState converts Torbox download states to provider-agnostic status.
@@ -598,6 +603,24 @@ func (c *TorboxClient) listTorrents(ctx context.Context) ([]torboxTorrent, error
 	return result.Data, nil
 }
 
+// ListTorrents returns every torrent on the Torbox account.
+func (c *TorboxClient) ListTorrents(ctx context.Context) ([]TorrentInfo, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("torbox API key not configured")
+	}
+
+	torrents, err := c.listTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TorrentInfo, 0, len(torrents))
+	for _, t := range torrents {
+		infos = append(infos, *c.toTorrentInfo(t))
+	}
+	return infos, nil
+}
+
 // clearDownloadingTorrents deletes all non-cached torrents (downloading, queued, etc.)
 // to free up slots when hitting the active download limit.
 func (c *TorboxClient) clearDownloadingTorrents(ctx context.Context) (int, error) {
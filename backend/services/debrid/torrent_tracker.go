@@ -0,0 +1,120 @@
+package debrid
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/database"
+)
+
+// torrentPlayedInterval limits how often playback of the same torrent is written back.
+const torrentPlayedInterval = time.Hour
+
+// torrentTrackerStore persists the torrents strmr added to debrid accounts.
+type torrentTrackerStore interface {
+	RecordDebridTorrent(torrent *database.DebridTorrent) error
+	MarkDebridTorrentPlayed(provider, torrentID string, playedAt time.Time) error
+	ListDebridTorrents(provider string) ([]*database.DebridTorrent, error)
+	DeleteDebridTorrent(provider, torrentID string) error
+}
+
+// torrentTracker records which torrents on a debrid account were added by strmr and when
+// they were last streamed, so housekeeping never touches torrents the user added
+// themselves. Like the cache index it is shared process-wide and is a no-op until a
+// repository is set.
+type torrentTracker struct {
+	mu         sync.Mutex
+	store      torrentTrackerStore
+	now        func() time.Time
+	lastPlayed map[string]time.Time
+}
+
+var addedTorrents = &torrentTracker{now: time.Now, lastPlayed: make(map[string]time.Time)}
+
+// SetTorrentRepository enables tracking of torrents added to debrid accounts.
+func SetTorrentRepository(repo *database.DebridTorrentRepository) {
+	addedTorrents.mu.Lock()
+	defer addedTorrents.mu.Unlock()
+	addedTorrents.store = nil
+	if repo != nil {
+		addedTorrents.store = repo
+	}
+	addedTorrents.lastPlayed = make(map[string]time.Time)
+}
+
+func (t *torrentTracker) snapshot() torrentTrackerStore {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store
+}
+
+// added records a torrent strmr just added to a provider.
+func (t *torrentTracker) added(provider, torrentID, infoHash string) {
+	store := t.snapshot()
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	torrentID = strings.TrimSpace(torrentID)
+	if store == nil || provider == "" || torrentID == "" {
+		return
+	}
+
+	torrent := &database.DebridTorrent{
+		Provider:  provider,
+		TorrentID: torrentID,
+		InfoHash:  strings.ToLower(strings.TrimSpace(infoHash)),
+		AddedAt:   t.now(),
+	}
+	if err := store.RecordDebridTorrent(torrent); err != nil {
+		log.Printf("[debrid-housekeeping] failed to track torrent %s on %s: %v", torrentID, provider, err)
+	}
+}
+
+// played records that a torrent is being streamed. Repeated calls within an hour (every
+// range request of a stream) are only written once.
+func (t *torrentTracker) played(provider, torrentID string) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	torrentID = strings.TrimSpace(torrentID)
+	if provider == "" || torrentID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	store := t.store
+	now := t.now()
+	key := provider + ":" + torrentID
+	if store == nil || now.Sub(t.lastPlayed[key]) < torrentPlayedInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastPlayed[key] = now
+	t.mu.Unlock()
+
+	if err := store.MarkDebridTorrentPlayed(provider, torrentID, now); err != nil {
+		log.Printf("[debrid-housekeeping] failed to mark torrent %s on %s played: %v", torrentID, provider, err)
+	}
+}
+
+// list returns the tracked torrents on a provider.
+func (t *torrentTracker) list(provider string) ([]*database.DebridTorrent, error) {
+	store := t.snapshot()
+	if store == nil {
+		return nil, nil
+	}
+	return store.ListDebridTorrents(strings.ToLower(provider))
+}
+
+// forget stops tracking a torrent that was removed from the provider.
+func (t *torrentTracker) forget(provider, torrentID string) {
+	store := t.snapshot()
+	if store == nil {
+		return
+	}
+	provider = strings.ToLower(provider)
+	if err := store.DeleteDebridTorrent(provider, torrentID); err != nil {
+		log.Printf("[debrid-housekeeping] failed to untrack torrent %s on %s: %v", torrentID, provider, err)
+	}
+	t.mu.Lock()
+	delete(t.lastPlayed, provider+":"+torrentID)
+	t.mu.Unlock()
+}
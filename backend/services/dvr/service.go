@@ -0,0 +1,795 @@
+package dvr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+var (
+	ErrStorageDirRequired  = errors.New("storage directory not provided")
+	ErrRecordingNotFound   = errors.New("recording not found")
+	ErrInvalidRecording    = errors.New("invalid recording")
+	ErrRecordingConflict   = errors.New("channel is already being recorded at that time")
+	ErrConnectionLimit     = errors.New("provider connection limit reached")
+	ErrFFmpegNotConfigured = errors.New("ffmpeg is not configured")
+	ErrUnknownChannel      = errors.New("unknown channel")
+)
+
+const (
+	// maxRecordingDuration guards against typos in the end time filling the disk
+	maxRecordingDuration = 12 * time.Hour
+	// dispatchHorizon is how far ahead Schedule arms a recording itself instead of waiting
+	// for the next scheduler tick
+	dispatchHorizon = 2 * time.Minute
+	// stopGrace is how long ffmpeg gets past the end time to finish writing
+	stopGrace         = 30 * time.Second
+	remuxTimeout      = 30 * time.Minute
+	providerTimeout   = 10 * time.Second
+	maxFFmpegErrorLen = 512
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._ -]+`)
+
+// Channel is a Live TV channel as listed by the configured provider.
+type Channel struct {
+	ID        string
+	Name      string
+	TvgID     string
+	StreamURL string
+}
+
+// ChannelResolver looks up channels of the configured Live TV source. Recordings only store
+// the channel ID; the stream URL is resolved when capturing so clients never supply it.
+type ChannelResolver interface {
+	ResolveChannel(ctx context.Context, channelID string) (Channel, error)
+}
+
+// Service schedules Live TV recordings and captures them to disk with ffmpeg.
+type Service struct {
+	mu         sync.RWMutex
+	path       string
+	storageDir string
+	recordings map[string]models.Recording
+
+	cfgManager *config.Manager
+	ffmpegPath string
+	client     *http.Client
+	channels   ChannelResolver
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	armed  map[string]context.CancelFunc // recordings waiting to start or capturing
+
+	// runFFmpeg is replaced in tests
+	runFFmpeg func(ctx context.Context, args ...string) error
+}
+
+// NewService constructs the DVR, persisting its schedule in storageDir. Recordings are written
+// to the configured recordings directory, defaulting to storageDir/recordings.
+func NewService(storageDir string, cfgManager *config.Manager, ffmpegPath string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create dvr dir: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := &Service{
+		path:       filepath.Join(storageDir, "recordings.json"),
+		storageDir: storageDir,
+		recordings: make(map[string]models.Recording),
+		cfgManager: cfgManager,
+		ffmpegPath: strings.TrimSpace(ffmpegPath),
+		client:     &http.Client{Timeout: providerTimeout},
+		ctx:        ctx,
+		cancel:     cancel,
+		armed:      make(map[string]context.CancelFunc),
+	}
+	svc.runFFmpeg = svc.execFFmpeg
+
+	if err := svc.load(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// SetChannelResolver sets where channel stream URLs are looked up.
+func (s *Service) SetChannelResolver(resolver ChannelResolver) {
+	s.channels = resolver
+}
+
+// resolveChannel returns a channel of the configured Live TV source with a usable stream URL.
+func (s *Service) resolveChannel(ctx context.Context, channelID string) (Channel, error) {
+	if s.channels == nil {
+		return Channel{}, fmt.Errorf("%w: live tv is not configured", ErrUnknownChannel)
+	}
+	channel, err := s.channels.ResolveChannel(ctx, channelID)
+	if err != nil {
+		return Channel{}, err
+	}
+	if err := validateStreamURL(channel.StreamURL); err != nil {
+		return Channel{}, err
+	}
+	return channel, nil
+}
+
+// List returns recordings newest first. An empty userID lists every profile's recordings.
+func (s *Service) List(userID string) []models.Recording {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Recording, 0, len(s.recordings))
+	for _, rec := range s.recordings {
+		if userID == "" || rec.UserID == userID {
+			result = append(result, rec)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartAt.After(result[j].StartAt)
+	})
+
+	return result
+}
+
+// Get returns a recording by ID.
+func (s *Service) Get(id string) (models.Recording, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.recordings[id]
+	return rec, ok
+}
+
+// Schedule validates and stores a new recording. The channel must be one of the configured
+// Live TV source. Recordings that overlap one already scheduled on the same channel, or that
+// would exceed the provider's connection limit, are refused.
+func (s *Service) Schedule(ctx context.Context, rec models.Recording) (models.Recording, error) {
+	now := time.Now().UTC()
+
+	rec.UserID = strings.TrimSpace(rec.UserID)
+	rec.ChannelID = strings.TrimSpace(rec.ChannelID)
+	if rec.ChannelID == "" {
+		return models.Recording{}, fmt.Errorf("%w: channelId required", ErrInvalidRecording)
+	}
+	channel, err := s.resolveChannel(ctx, rec.ChannelID)
+	if err != nil {
+		return models.Recording{}, err
+	}
+	rec.ChannelName = channel.Name
+	if rec.TvgID == "" {
+		rec.TvgID = channel.TvgID
+	}
+	rec.Title = strings.TrimSpace(rec.Title)
+	if rec.Title == "" {
+		rec.Title = strings.TrimSpace(rec.ChannelName)
+	}
+	rec.StartAt = rec.StartAt.UTC()
+	rec.EndAt = rec.EndAt.UTC()
+
+	if rec.Title == "" {
+		return models.Recording{}, fmt.Errorf("%w: title or channel name required", ErrInvalidRecording)
+	}
+	if !rec.EndAt.After(rec.StartAt) {
+		return models.Recording{}, fmt.Errorf("%w: end must be after start", ErrInvalidRecording)
+	}
+	if !rec.EndAt.After(now) {
+		return models.Recording{}, fmt.Errorf("%w: end is in the past", ErrInvalidRecording)
+	}
+	if rec.StartAt.Before(now) {
+		rec.StartAt = now
+	}
+	if rec.EndAt.Sub(rec.StartAt) > maxRecordingDuration {
+		return models.Recording{}, fmt.Errorf("%w: recordings are limited to %s", ErrInvalidRecording, maxRecordingDuration)
+	}
+
+	// Ask the provider before taking the lock; this is a network call
+	maxConns, _ := s.connectionLimit(ctx)
+
+	s.mu.Lock()
+	overlapping := 0
+	for _, existing := range s.recordings {
+		if !existing.Active() || !existing.Overlaps(rec.StartAt, rec.EndAt) {
+			continue
+		}
+		if existing.ChannelID == rec.ChannelID {
+			s.mu.Unlock()
+			return models.Recording{}, fmt.Errorf("%w (%q)", ErrRecordingConflict, existing.Title)
+		}
+		overlapping++
+	}
+	if maxConns > 0 && overlapping+1 > maxConns {
+		s.mu.Unlock()
+		return models.Recording{}, fmt.Errorf("%w: %d overlapping recordings with a limit of %d", ErrConnectionLimit, overlapping, maxConns)
+	}
+
+	rec.ID = uuid.NewString()
+	rec.Status = models.RecordingStatusScheduled
+	rec.FilePath = ""
+	rec.FileSize = 0
+	rec.Duration = 0
+	rec.Error = ""
+	rec.CreatedAt = now
+	rec.UpdatedAt = now
+	s.recordings[rec.ID] = rec
+
+	if err := s.saveLocked(); err != nil {
+		delete(s.recordings, rec.ID)
+		s.mu.Unlock()
+		return models.Recording{}, err
+	}
+	s.mu.Unlock()
+
+	log.Printf("[dvr] scheduled %q on %s from %s to %s", rec.Title, rec.ChannelName, rec.StartAt.Format(time.RFC3339), rec.EndAt.Format(time.RFC3339))
+	s.StartDueRecordings(dispatchHorizon)
+	return rec, nil
+}
+
+// Stop ends a recording early, keeping what has been captured. Scheduled recordings that have
+// not started yet are cancelled.
+func (s *Service) Stop(id string) (models.Recording, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.recordings[id]
+	if !ok {
+		return models.Recording{}, ErrRecordingNotFound
+	}
+
+	switch rec.Status {
+	case models.RecordingStatusRecording:
+		if cancel := s.armed[id]; cancel != nil {
+			cancel()
+		}
+	case models.RecordingStatusScheduled:
+		if cancel := s.armed[id]; cancel != nil {
+			cancel()
+		}
+		rec.Status = models.RecordingStatusCancelled
+		rec.UpdatedAt = time.Now().UTC()
+		s.recordings[id] = rec
+		if err := s.saveLocked(); err != nil {
+			return models.Recording{}, err
+		}
+	}
+
+	return rec, nil
+}
+
+// Delete cancels a recording if needed and removes it along with its file.
+func (s *Service) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.recordings[id]
+	if !ok {
+		return ErrRecordingNotFound
+	}
+
+	if cancel := s.armed[id]; cancel != nil {
+		cancel()
+	}
+	delete(s.recordings, id)
+
+	// A capture still running cleans up its own file once it sees the entry is gone
+	if rec.FilePath != "" && rec.Status != models.RecordingStatusRecording {
+		if err := os.Remove(rec.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[dvr] failed to remove recording file %s: %v", rec.FilePath, err)
+		}
+	}
+
+	return s.saveLocked()
+}
+
+// StartDueRecordings arms every scheduled recording that starts within the horizon; each one
+// waits for its start time in the background. It also fails recordings whose window passed
+// while the server was down. It returns the number of recordings armed.
+func (s *Service) StartDueRecordings(horizon time.Duration) int {
+	now := time.Now().UTC()
+	deadline := now.Add(horizon)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	armed := 0
+	changed := false
+	for id, rec := range s.recordings {
+		if rec.Status != models.RecordingStatusScheduled || s.armed[id] != nil {
+			continue
+		}
+		if !rec.EndAt.After(now) {
+			rec.Status = models.RecordingStatusFailed
+			rec.Error = "recording window passed before it could start"
+			rec.UpdatedAt = now
+			s.recordings[id] = rec
+			changed = true
+			continue
+		}
+		if rec.StartAt.After(deadline) {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.armed[id] = cancel
+		s.wg.Add(1)
+		go s.run(ctx, id)
+		armed++
+	}
+
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			log.Printf("[dvr] failed to save recordings: %v", err)
+		}
+	}
+
+	return armed
+}
+
+// Shutdown stops running captures and waits for them to finish writing.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run waits for a recording's start time, checks the provider has a free connection and
+// captures the stream until the end time.
+func (s *Service) run(ctx context.Context, id string) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		if cancel := s.armed[id]; cancel != nil {
+			cancel()
+		}
+		delete(s.armed, id)
+		s.mu.Unlock()
+	}()
+
+	rec, ok := s.Get(id)
+	if !ok {
+		return
+	}
+
+	if wait := time.Until(rec.StartAt); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	if s.ffmpegPath == "" {
+		s.finish(id, func(r *models.Recording) {
+			r.Status = models.RecordingStatusFailed
+			r.Error = ErrFFmpegNotConfigured.Error()
+		})
+		return
+	}
+
+	if maxConns, active := s.connectionLimit(ctx); maxConns > 0 && active >= maxConns {
+		s.finish(id, func(r *models.Recording) {
+			r.Status = models.RecordingStatusFailed
+			r.Error = fmt.Sprintf("%s (%d/%d in use)", ErrConnectionLimit, active, maxConns)
+		})
+		return
+	}
+
+	// Resolved now rather than at scheduling so provider credential changes are picked up
+	channel, err := s.resolveChannel(ctx, rec.ChannelID)
+	if err != nil {
+		s.finish(id, func(r *models.Recording) {
+			r.Status = models.RecordingStatusFailed
+			r.Error = fmt.Sprintf("resolve channel: %v", err)
+		})
+		return
+	}
+
+	dir := s.recordingsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		s.finish(id, func(r *models.Recording) {
+			r.Status = models.RecordingStatusFailed
+			r.Error = fmt.Sprintf("create recordings dir: %v", err)
+		})
+		return
+	}
+
+	base := fmt.Sprintf("%s_%s_%s", sanitizeFileName(rec.Title), rec.StartAt.Local().Format("20060102_1504"), id[:8])
+	tsPath := filepath.Join(dir, base+".ts")
+	started := time.Now()
+
+	claimed := false
+	s.update(id, func(r *models.Recording) {
+		// Cancelled between arming and now
+		if r.Status != models.RecordingStatusScheduled {
+			return
+		}
+		r.Status = models.RecordingStatusRecording
+		r.FilePath = tsPath
+		r.Error = ""
+		claimed = true
+	})
+	if !claimed {
+		return
+	}
+
+	log.Printf("[dvr] recording %q from %s until %s", rec.Title, rec.ChannelName, rec.EndAt.Format(time.RFC3339))
+
+	captureCtx, cancelCapture := context.WithDeadline(ctx, rec.EndAt.Add(stopGrace))
+	seconds := int(time.Until(rec.EndAt).Seconds())
+	captureErr := s.runFFmpeg(captureCtx,
+		"-hide_banner",
+		"-loglevel", "warning",
+		"-reconnect", "1",
+		"-reconnect_streamed", "1",
+		"-reconnect_delay_max", "3",
+		"-i", channel.StreamURL,
+		"-map", "0:v?",
+		"-map", "0:a?",
+		"-c", "copy",
+		"-t", strconv.Itoa(max(seconds, 1)),
+		"-f", "mpegts",
+		"-y", tsPath,
+	)
+	cancelCapture()
+	captured := time.Since(started).Seconds()
+
+	finalPath := tsPath
+	info, statErr := os.Stat(tsPath)
+	if statErr == nil && info.Size() > 0 {
+		finalPath = s.remux(tsPath)
+		if remuxed, err := os.Stat(finalPath); err == nil {
+			info = remuxed
+		}
+	}
+
+	if _, exists := s.Get(id); !exists {
+		// Deleted while capturing
+		_ = os.Remove(finalPath)
+		return
+	}
+
+	s.finish(id, func(r *models.Recording) {
+		r.FilePath = finalPath
+		r.Duration = captured
+		if statErr != nil || info.Size() == 0 {
+			r.Status = models.RecordingStatusFailed
+			r.Error = "no data captured"
+			if captureErr != nil {
+				r.Error = captureErr.Error()
+			}
+			return
+		}
+		r.Status = models.RecordingStatusCompleted
+		r.FileSize = info.Size()
+		if captureErr != nil && ctx.Err() == nil && time.Now().Before(rec.EndAt) {
+			// The stream dropped before the end time; keep the partial capture
+			r.Error = "recording ended early: " + captureErr.Error()
+		}
+	})
+
+	log.Printf("[dvr] finished %q (%.0fs captured)", rec.Title, captured)
+}
+
+// remux converts a captured transport stream to a faststart MP4 with AAC audio so clients can
+// seek in it. The transport stream is kept if the conversion fails.
+func (s *Service) remux(tsPath string) string {
+	mp4Path := strings.TrimSuffix(tsPath, filepath.Ext(tsPath)) + ".mp4"
+
+	ctx, cancel := context.WithTimeout(context.Background(), remuxTimeout)
+	defer cancel()
+
+	err := s.runFFmpeg(ctx,
+		"-hide_banner",
+		"-loglevel", "warning",
+		"-i", tsPath,
+		"-map", "0:v?",
+		"-map", "0:a?",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-movflags", "+faststart",
+		"-y", mp4Path,
+	)
+	if err != nil {
+		log.Printf("[dvr] remux of %s failed, keeping transport stream: %v", tsPath, err)
+		_ = os.Remove(mp4Path)
+		return tsPath
+	}
+
+	if err := os.Remove(tsPath); err != nil {
+		log.Printf("[dvr] failed to remove %s after remux: %v", tsPath, err)
+	}
+	return mp4Path
+}
+
+func (s *Service) execFFmpeg(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, s.ffmpegPath, args...)
+	// Let ffmpeg flush and close the output instead of killing it outright
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 10 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxFFmpegErrorLen {
+			msg = msg[len(msg)-maxFFmpegErrorLen:]
+		}
+		if msg != "" {
+			return fmt.Errorf("ffmpeg: %w: %s", err, msg)
+		}
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+	return nil
+}
+
+// update applies a change to a stored recording. It returns false if the recording was deleted.
+func (s *Service) update(id string, apply func(*models.Recording)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.recordings[id]
+	if !ok {
+		return false
+	}
+	apply(&rec)
+	rec.UpdatedAt = time.Now().UTC()
+	s.recordings[id] = rec
+
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[dvr] failed to save recordings: %v", err)
+	}
+	return true
+}
+
+// finish records the outcome of a recording, unless the user cancelled it before it started.
+func (s *Service) finish(id string, apply func(*models.Recording)) {
+	s.update(id, func(r *models.Recording) {
+		if r.Status == models.RecordingStatusCancelled {
+			return
+		}
+		apply(r)
+	})
+}
+
+// connectionLimit returns the provider's connection limit and how many connections are in use.
+// An explicit MaxConnections setting wins over what an Xtream Codes server reports.
+func (s *Service) connectionLimit(ctx context.Context) (maxConns, active int) {
+	s.mu.RLock()
+	for _, rec := range s.recordings {
+		if rec.Status == models.RecordingStatusRecording {
+			active++
+		}
+	}
+	s.mu.RUnlock()
+
+	if s.cfgManager == nil {
+		return 0, active
+	}
+	settings, err := s.cfgManager.Load()
+	if err != nil {
+		log.Printf("[dvr] failed to load settings: %v", err)
+		return 0, active
+	}
+	live := settings.Live
+	maxConns = live.MaxConnections
+
+	if live.Mode == "xtream" && live.XtreamHost != "" && live.XtreamUsername != "" && live.XtreamPassword != "" {
+		info, err := s.fetchXtreamUserInfo(ctx, live)
+		if err != nil {
+			log.Printf("[dvr] failed to query Xtream connection limit: %v", err)
+			return maxConns, active
+		}
+		if maxConns <= 0 {
+			maxConns = int(info.MaxConnections)
+		}
+		// The provider's count also includes channels being watched right now
+		active = max(active, int(info.ActiveConnections))
+	}
+
+	return maxConns, active
+}
+
+// flexInt decodes numbers that Xtream servers send either as JSON numbers or strings.
+type flexInt int
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if raw == "" || raw == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return err
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+type xtreamUserInfo struct {
+	MaxConnections    flexInt `json:"max_connections"`
+	ActiveConnections flexInt `json:"active_cons"`
+}
+
+func (s *Service) fetchXtreamUserInfo(ctx context.Context, live config.LiveSettings) (xtreamUserInfo, error) {
+	endpoint := fmt.Sprintf("%s/player_api.php?username=%s&password=%s",
+		strings.TrimRight(live.XtreamHost, "/"), url.QueryEscape(live.XtreamUsername), url.QueryEscape(live.XtreamPassword))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return xtreamUserInfo{}, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return xtreamUserInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return xtreamUserInfo{}, fmt.Errorf("player_api returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		UserInfo xtreamUserInfo `json:"user_info"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&payload); err != nil {
+		return xtreamUserInfo{}, fmt.Errorf("decode player_api response: %w", err)
+	}
+	return payload.UserInfo, nil
+}
+
+func (s *Service) recordingsDir() string {
+	if s.cfgManager != nil {
+		if settings, err := s.cfgManager.Load(); err == nil && strings.TrimSpace(settings.Live.RecordingsDirectory) != "" {
+			return strings.TrimSpace(settings.Live.RecordingsDirectory)
+		}
+	}
+	return filepath.Join(s.storageDir, "recordings")
+}
+
+func validateStreamURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return fmt.Errorf("%w: invalid stream url", ErrInvalidRecording)
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported stream url scheme %q", ErrInvalidRecording, parsed.Scheme)
+	}
+}
+
+func sanitizeFileName(name string) string {
+	cleaned := strings.TrimSpace(unsafeFileChars.ReplaceAllString(name, ""))
+	cleaned = strings.ReplaceAll(cleaned, " ", "_")
+	if len(cleaned) > 80 {
+		cleaned = cleaned[:80]
+	}
+	if cleaned == "" {
+		return "recording"
+	}
+	return cleaned
+}
+
+// load reads the schedule from disk. Recordings that were capturing when the server stopped
+// are closed out with whatever reached the disk.
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open recordings: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read recordings: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var list []storedRecording
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("decode recordings: %w", err)
+	}
+
+	changed := false
+	for _, stored := range list {
+		rec := stored.Recording
+		rec.FilePath = stored.FilePath
+		if rec.Status == models.RecordingStatusRecording {
+			rec.Status = models.RecordingStatusFailed
+			rec.Error = "interrupted by server restart"
+			if info, err := os.Stat(rec.FilePath); err == nil && info.Size() > 0 {
+				rec.Status = models.RecordingStatusCompleted
+				rec.FileSize = info.Size()
+			}
+			rec.UpdatedAt = time.Now().UTC()
+			changed = true
+		}
+		s.recordings[rec.ID] = rec
+	}
+
+	if changed {
+		return s.saveLocked()
+	}
+	return nil
+}
+
+// storedRecording keeps the file path on disk, which the API model hides from clients.
+type storedRecording struct {
+	models.Recording
+	FilePath string `json:"filePath,omitempty"`
+}
+
+// saveLocked writes the schedule to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	list := make([]storedRecording, 0, len(s.recordings))
+	for _, rec := range s.recordings {
+		list = append(list, storedRecording{Recording: rec, FilePath: rec.FilePath})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode recordings: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write recordings: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("commit recordings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package dvr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+// fakeChannels lists a fixed set of provider channels by ID
+type fakeChannels map[string]string
+
+func (f fakeChannels) ResolveChannel(_ context.Context, channelID string) (Channel, error) {
+	streamURL, ok := f[channelID]
+	if !ok {
+		return Channel{}, ErrUnknownChannel
+	}
+	return Channel{ID: channelID, Name: channelID, StreamURL: streamURL}, nil
+}
+
+var testChannels = fakeChannels{
+	"one":   "http://provider.example/live/one.ts",
+	"two":   "http://provider.example/live/two.ts",
+	"three": "http://provider.example/live/three.ts",
+	"local": "file:///etc/passwd",
+}
+
+func newTestService(t *testing.T, maxConnections int) (*Service, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := config.DefaultSettings()
+	cfg.Live.MaxConnections = maxConnections
+	cfg.Live.RecordingsDirectory = filepath.Join(dir, "recordings")
+
+	mgr := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("save cfg: %v", err)
+	}
+
+	svc, err := NewService(filepath.Join(dir, "dvr"), mgr, "ffmpeg")
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	svc.SetChannelResolver(testChannels)
+	t.Cleanup(func() { _ = svc.Shutdown(context.Background()) })
+	return svc, dir
+}
+
+func testRecording(channel string, start, end time.Time) models.Recording {
+	return models.Recording{
+		UserID:    "profile",
+		ChannelID: channel,
+		StartAt:   start,
+		EndAt:     end,
+	}
+}
+
+func TestScheduleValidatesAndChecksConflicts(t *testing.T) {
+	svc, _ := newTestService(t, 2)
+	start := time.Now().Add(time.Hour)
+	end := start.Add(30 * time.Minute)
+
+	if _, err := svc.Schedule(context.Background(), testRecording("one", end, start)); !errors.Is(err, ErrInvalidRecording) {
+		t.Fatalf("expected invalid range error, got %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("", start, end)); !errors.Is(err, ErrInvalidRecording) {
+		t.Fatalf("expected missing channel error, got %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("unlisted", start, end)); !errors.Is(err, ErrUnknownChannel) {
+		t.Fatalf("expected unknown channel error, got %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("local", start, end)); !errors.Is(err, ErrInvalidRecording) {
+		t.Fatalf("expected invalid stream url error, got %v", err)
+	}
+
+	first, err := svc.Schedule(context.Background(), testRecording("one", start, end))
+	if err != nil {
+		t.Fatalf("schedule first: %v", err)
+	}
+	if first.Status != models.RecordingStatusScheduled || first.Title != "one" {
+		t.Fatalf("unexpected recording %+v", first)
+	}
+
+	if _, err := svc.Schedule(context.Background(), testRecording("one", start.Add(10*time.Minute), end.Add(time.Hour))); !errors.Is(err, ErrRecordingConflict) {
+		t.Fatalf("expected same-channel conflict, got %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("two", start, end)); err != nil {
+		t.Fatalf("schedule second channel: %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("three", start, end)); !errors.Is(err, ErrConnectionLimit) {
+		t.Fatalf("expected connection limit error, got %v", err)
+	}
+
+	// Back-to-back recordings do not overlap
+	if _, err := svc.Schedule(context.Background(), testRecording("three", end, end.Add(time.Hour))); err != nil {
+		t.Fatalf("schedule after window: %v", err)
+	}
+
+	// Cancelled recordings free their slot
+	if _, err := svc.Stop(first.ID); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if _, err := svc.Schedule(context.Background(), testRecording("three", start, end)); err != nil {
+		t.Fatalf("schedule into freed slot: %v", err)
+	}
+}
+
+func TestRecordingPersistsAcrossRestart(t *testing.T) {
+	svc, dir := newTestService(t, 0)
+	start := time.Now().Add(2 * time.Hour)
+
+	rec, err := svc.Schedule(context.Background(), testRecording("one", start, start.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	reloaded, err := NewService(filepath.Join(dir, "dvr"), svc.cfgManager, "ffmpeg")
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	defer reloaded.Shutdown(context.Background())
+
+	got, ok := reloaded.Get(rec.ID)
+	if !ok || got.Status != models.RecordingStatusScheduled || !got.StartAt.Equal(rec.StartAt) {
+		t.Fatalf("unexpected reloaded recording %+v (found=%v)", got, ok)
+	}
+	if list := reloaded.List("someone-else"); len(list) != 0 {
+		t.Fatalf("expected no recordings for another profile, got %d", len(list))
+	}
+}
+
+func TestRecordingCapturesAndRemuxes(t *testing.T) {
+	svc, dir := newTestService(t, 0)
+
+	var calls [][]string
+	svc.runFFmpeg = func(ctx context.Context, args ...string) error {
+		calls = append(calls, args)
+		return os.WriteFile(args[len(args)-1], []byte("video"), 0o644)
+	}
+
+	now := time.Now()
+	rec, err := svc.Schedule(context.Background(), testRecording("one", now.Add(-time.Minute), now.Add(time.Minute)))
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var got models.Recording
+	for time.Now().Before(deadline) {
+		got, _ = svc.Get(rec.ID)
+		if got.Status == models.RecordingStatusCompleted || got.Status == models.RecordingStatusFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got.Status != models.RecordingStatusCompleted {
+		t.Fatalf("expected completed recording, got %+v", got)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected capture and remux, got %d ffmpeg calls", len(calls))
+	}
+	if !slices.Contains(calls[0], testChannels["one"]) {
+		t.Fatalf("expected capture of the provider stream, got %q", calls[0])
+	}
+	if filepath.Dir(got.FilePath) != filepath.Join(dir, "recordings") || filepath.Ext(got.FilePath) != ".mp4" {
+		t.Fatalf("unexpected recording path %q", got.FilePath)
+	}
+	if _, err := os.Stat(got.FilePath); err != nil {
+		t.Fatalf("recording file missing: %v", err)
+	}
+
+	if err := svc.Delete(rec.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(got.FilePath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected recording file removed, got %v", err)
+	}
+}
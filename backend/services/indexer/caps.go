@@ -0,0 +1,185 @@
+package indexer
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/config"
+	"novastream/services/debrid"
+)
+
+const (
+	// capsTTL is how long an indexer's t=caps response is trusted.
+	capsTTL = 24 * time.Hour
+	// capsFailureTTL is how long text search is used after t=caps fails, before retrying.
+	capsFailureTTL = time.Hour
+)
+
+// indexerCaps lists the parameters an indexer accepts for each search type, from t=caps.
+type indexerCaps struct {
+	TVSearch    map[string]bool
+	MovieSearch map[string]bool
+	fetchedAt   time.Time
+	failed      bool
+}
+
+type capsResponse struct {
+	Searching struct {
+		TVSearch    capsSearch `xml:"tv-search"`
+		MovieSearch capsSearch `xml:"movie-search"`
+	} `xml:"searching"`
+}
+
+type capsSearch struct {
+	Available       string `xml:"available,attr"`
+	SupportedParams string `xml:"supportedParams,attr"`
+}
+
+// params returns the supported parameters of an available search type.
+func (c capsSearch) params() map[string]bool {
+	if !strings.EqualFold(strings.TrimSpace(c.Available), "yes") {
+		return nil
+	}
+	params := make(map[string]bool)
+	for _, p := range strings.Split(c.SupportedParams, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			params[p] = true
+		}
+	}
+	return params
+}
+
+// getCaps returns the cached capabilities of an indexer, fetching t=caps when missing or
+// stale. Indexers whose caps can't be fetched are searched by text.
+func (s *Service) getCaps(ctx context.Context, idx config.IndexerConfig, endpoint string) indexerCaps {
+	key := endpoint + "|" + idx.APIKey
+
+	s.capsMu.Lock()
+	cached, ok := s.caps[key]
+	s.capsMu.Unlock()
+	if ok {
+		ttl := capsTTL
+		if cached.failed {
+			ttl = capsFailureTTL
+		}
+		if time.Since(cached.fetchedAt) < ttl {
+			return cached
+		}
+	}
+
+	caps, err := s.fetchCaps(ctx, idx, endpoint)
+	if err != nil {
+		if ctx.Err() != nil {
+			return indexerCaps{}
+		}
+		log.Printf("[indexer/newznab] caps request for %s failed, using text search: %v", idx.Name, err)
+		caps = indexerCaps{failed: true}
+	}
+	caps.fetchedAt = time.Now()
+
+	s.capsMu.Lock()
+	if s.caps == nil {
+		s.caps = make(map[string]indexerCaps)
+	}
+	s.caps[key] = caps
+	s.capsMu.Unlock()
+	return caps
+}
+
+func (s *Service) fetchCaps(ctx context.Context, idx config.IndexerConfig, endpoint string) (indexerCaps, error) {
+	params := url.Values{}
+	params.Set("t", "caps")
+	if idx.APIKey != "" {
+		params.Set("apikey", idx.APIKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return indexerCaps{}, err
+	}
+	resp, err := s.httpc.Do(req)
+	if err != nil {
+		return indexerCaps{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return indexerCaps{}, fmt.Errorf("caps request failed: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return indexerCaps{}, err
+	}
+
+	var decoded capsResponse
+	if err := xml.Unmarshal(body, &decoded); err != nil {
+		return indexerCaps{}, fmt.Errorf("decode caps: %w", err)
+	}
+	caps := indexerCaps{
+		TVSearch:    decoded.Searching.TVSearch.params(),
+		MovieSearch: decoded.Searching.MovieSearch.params(),
+	}
+	log.Printf("[indexer/newznab] caps for %s: tv-search=%v movie-search=%v", idx.Name, caps.TVSearch, caps.MovieSearch)
+	return caps, nil
+}
+
+// hasSearchIDs reports whether the search could be made by ID, so caps are only fetched
+// for indexers that will use them.
+func hasSearchIDs(opts SearchOptions) bool {
+	return strings.TrimSpace(opts.IMDBID) != "" || strings.TrimSpace(opts.TVDBID) != ""
+}
+
+// idSearchParams builds a t=movie or t=tvsearch request from the IDs in the search options
+// when the indexer supports them. It returns nil when a text search is needed.
+func idSearchParams(caps indexerCaps, opts SearchOptions) url.Values {
+	imdbID := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(opts.IMDBID)), "tt")
+	tvdbID := strings.TrimSpace(opts.TVDBID)
+	if _, err := strconv.Atoi(tvdbID); err != nil {
+		tvdbID = ""
+	}
+
+	params := url.Values{}
+	switch strings.ToLower(strings.TrimSpace(opts.MediaType)) {
+	case "movie", "movies":
+		if imdbID == "" || !caps.MovieSearch["imdbid"] {
+			return nil
+		}
+		params.Set("t", "movie")
+		params.Set("imdbid", imdbID)
+	case "series", "tv", "show":
+		switch {
+		case tvdbID != "" && caps.TVSearch["tvdbid"]:
+			params.Set("tvdbid", tvdbID)
+		case imdbID != "" && caps.TVSearch["imdbid"]:
+			params.Set("imdbid", imdbID)
+		default:
+			return nil
+		}
+		params.Set("t", "tvsearch")
+
+		parsed := debrid.ParseQuery(opts.Query)
+		if parsed.Season > 0 {
+			if !caps.TVSearch["season"] {
+				return nil
+			}
+			params.Set("season", strconv.Itoa(parsed.Season))
+			if parsed.Episode > 0 {
+				if !caps.TVSearch["ep"] {
+					return nil
+				}
+				params.Set("ep", strconv.Itoa(parsed.Episode))
+			}
+		}
+	default:
+		return nil
+	}
+	return params
+}
@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/parsett"
+)
+
+// Result attributes holding a release's position in the audio and video codec preference
+// orders (lower is better).
+const (
+	audioRankAttr      = "audioRank"
+	videoCodecRankAttr = "videoCodecRank"
+)
+
+// codecPreferences are the audio format and video codec orders search results are ranked by.
+type codecPreferences struct {
+	audio      []string
+	videoCodec []string
+	// forceAAC is set when the client has AAC transcoding forced, so releases that already
+	// carry AAC audio play without a transcode and are ranked ahead of the audio order.
+	forceAAC bool
+}
+
+// getEffectiveCodecPreferences returns the codec preference orders to rank search results with.
+// Settings cascade: Global -> Profile -> Client (most specific wins)
+func (s *Service) getEffectiveCodecPreferences(userID, clientID string, globalSettings config.Settings) codecPreferences {
+	prefs := codecPreferences{
+		audio:      globalSettings.Ranking.AudioPreference,
+		videoCodec: globalSettings.Ranking.VideoCodecPreference,
+		forceAAC:   globalSettings.Playback.ForceAACTranscoding,
+	}
+
+	// Layer 2: Profile settings override global
+	if userID != "" && s.userSettings != nil {
+		userSettings, err := s.userSettings.Get(userID)
+		if err != nil {
+			log.Printf("[indexer] failed to get user settings for codec preferences %s: %v", userID, err)
+		} else if userSettings != nil && userSettings.Ranking != nil {
+			if len(userSettings.Ranking.AudioPreference) > 0 {
+				prefs.audio = userSettings.Ranking.AudioPreference
+			}
+			if len(userSettings.Ranking.VideoCodecPreference) > 0 {
+				prefs.videoCodec = userSettings.Ranking.VideoCodecPreference
+			}
+		}
+	}
+
+	// Layer 3: Client settings override profile
+	if clientID != "" && s.clientSettings != nil {
+		clientSettings, err := s.clientSettings.Get(clientID)
+		if err != nil {
+			log.Printf("[indexer] failed to get client settings for codec preferences %s: %v", clientID, err)
+		} else if clientSettings != nil {
+			if clientSettings.AudioPreference != nil {
+				prefs.audio = *clientSettings.AudioPreference
+			}
+			if clientSettings.VideoCodecPreference != nil {
+				prefs.videoCodec = *clientSettings.VideoCodecPreference
+			}
+			if clientSettings.ForceAACTranscoding != nil {
+				prefs.forceAAC = *clientSettings.ForceAACTranscoding
+			}
+		}
+	}
+
+	return prefs
+}
+
+// preferenceOrder is a preference order with each entry expanded to the values it matches.
+type preferenceOrder [][]string
+
+// newPreferenceOrder lowercases the entries of a preference order and adds the parsed form of
+// entries spelled the way release titles spell them ("DTS-HD MA", "x265", "E-AC3").
+func newPreferenceOrder(entries []string, parsedValues func(*parsett.ParsedTitle) []string) preferenceOrder {
+	order := make(preferenceOrder, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		values := []string{strings.ToLower(entry)}
+		if parsed, err := parsett.ParseTitle(entry); err == nil && parsed != nil {
+			for _, v := range parsedValues(parsed) {
+				values = append(values, strings.ToLower(v))
+			}
+		}
+		order = append(order, values)
+	}
+	return order
+}
+
+// rank returns the position of the most preferred of a release's values, or the length of the
+// order when none of them is in it (including releases whose titles name no value).
+func (o preferenceOrder) rank(values []string) int {
+	for i, entry := range o {
+		for _, v := range values {
+			v = strings.ToLower(v)
+			for _, match := range entry {
+				if v == match {
+					return i
+				}
+			}
+		}
+	}
+	return len(o)
+}
+
+func parsedAudio(p *parsett.ParsedTitle) []string { return p.Audio }
+
+func parsedCodec(p *parsett.ParsedTitle) []string {
+	if p.Codec == "" {
+		return nil
+	}
+	return []string{p.Codec}
+}
+
+// applyCodecRanks records each result's audio and video codec ranks in its attributes. With
+// AAC transcoding forced, releases with AAC audio rank ahead of every preferred format.
+func applyCodecRanks(results []models.NZBResult, prefs codecPreferences) {
+	audioOrder := newPreferenceOrder(prefs.audio, parsedAudio)
+	videoOrder := newPreferenceOrder(prefs.videoCodec, parsedCodec)
+
+	parsedByTitle := make(map[string]*parsett.ParsedTitle)
+	for i := range results {
+		title := results[i].Title
+		parsed, ok := parsedByTitle[title]
+		if !ok {
+			parsed, _ = parsett.ParseTitle(title)
+			if parsed == nil {
+				parsed = &parsett.ParsedTitle{}
+			}
+			parsedByTitle[title] = parsed
+		}
+
+		audioRank := audioOrder.rank(parsed.Audio)
+		if prefs.forceAAC && hasAACAudio(parsed.Audio) {
+			audioRank = -1
+		}
+
+		if results[i].Attributes == nil {
+			results[i].Attributes = make(map[string]string)
+		}
+		results[i].Attributes[audioRankAttr] = strconv.Itoa(audioRank)
+		results[i].Attributes[videoCodecRankAttr] = strconv.Itoa(videoOrder.rank(parsedCodec(parsed)))
+	}
+}
+
+func hasAACAudio(audio []string) bool {
+	for _, a := range audio {
+		if strings.EqualFold(a, "AAC") {
+			return true
+		}
+	}
+	return false
+}
+
+// codecRank reads a rank attribute; results without one rank last.
+func codecRank(r models.NZBResult, attr string) int {
+	rank, err := strconv.Atoi(r.Attributes[attr])
+	if err != nil {
+		return math.MaxInt32
+	}
+	return rank
+}
+
+func compareCodecRank(i, j models.NZBResult, attr string) int {
+	rankI := codecRank(i, attr)
+	rankJ := codecRank(j, attr)
+	if rankI < rankJ {
+		return -1
+	}
+	if rankI > rankJ {
+		return 1
+	}
+	return 0
+}
+
+func compareAudio(i, j models.NZBResult) int {
+	return compareCodecRank(i, j, audioRankAttr)
+}
+
+func compareVideoCodec(i, j models.NZBResult) int {
+	return compareCodecRank(i, j, videoCodecRankAttr)
+}
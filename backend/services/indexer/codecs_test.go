@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"sort"
+	"testing"
+
+	"novastream/models"
+)
+
+func sortByCodecs(results []models.NZBResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if c := compareAudio(results[i], results[j]); c != 0 {
+			return c < 0
+		}
+		return compareVideoCodec(results[i], results[j]) < 0
+	})
+}
+
+func titles(results []models.NZBResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Title
+	}
+	return out
+}
+
+func TestCodecRanks(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2020.1080p.WEB-DL.AAC2.0.H.264-GRP"},
+		{Title: "Movie.2020.2160p.WEB-DL.DDP5.1.AV1-GRP"},
+		{Title: "Movie.2020.2160p.BluRay.REMUX.HEVC.TrueHD.7.1.Atmos-GRP"},
+		{Title: "Movie.2020.1080p.BluRay.DTS-HD.MA.5.1.x264-GRP"},
+		{Title: "Movie.2020.1080p.WEB-DL.DDP5.1.x265-GRP"},
+		{Title: "Movie.2020.1080p.WEBRip-GRP"},
+	}
+
+	// Preference entries may be spelled the way release titles spell them
+	applyCodecRanks(results, codecPreferences{
+		audio:      []string{"TrueHD", "DTS-HD MA", "DD+", "AAC"},
+		videoCodec: []string{"x265", "avc"},
+	})
+	sortByCodecs(results)
+
+	want := []string{
+		"Movie.2020.2160p.BluRay.REMUX.HEVC.TrueHD.7.1.Atmos-GRP",
+		"Movie.2020.1080p.BluRay.DTS-HD.MA.5.1.x264-GRP",
+		"Movie.2020.1080p.WEB-DL.DDP5.1.x265-GRP",
+		"Movie.2020.2160p.WEB-DL.DDP5.1.AV1-GRP", // AV1 is not in the order
+		"Movie.2020.1080p.WEB-DL.AAC2.0.H.264-GRP",
+		"Movie.2020.1080p.WEBRip-GRP", // No audio or codec in the title
+	}
+	for i, title := range titles(results) {
+		if title != want[i] {
+			t.Fatalf("order = %q, want %q", titles(results), want)
+		}
+	}
+}
+
+func TestCodecRanksPreferAACWhenTranscodingIsForced(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Movie.2020.2160p.BluRay.REMUX.HEVC.TrueHD.7.1.Atmos-GRP"},
+		{Title: "Movie.2020.1080p.WEB-DL.DDP5.1.H.264-GRP"},
+		{Title: "Movie.2020.1080p.WEB-DL.AAC2.0.H.264-GRP"},
+	}
+
+	applyCodecRanks(results, codecPreferences{
+		audio:    []string{"Atmos", "TrueHD", "DDP", "AAC"},
+		forceAAC: true,
+	})
+	sortByCodecs(results)
+
+	if results[0].Title != "Movie.2020.1080p.WEB-DL.AAC2.0.H.264-GRP" {
+		t.Fatalf("order = %q, want the AAC release first", titles(results))
+	}
+	if results[1].Title != "Movie.2020.2160p.BluRay.REMUX.HEVC.TrueHD.7.1.Atmos-GRP" {
+		t.Fatalf("order = %q, want the audio order after AAC releases", titles(results))
+	}
+}
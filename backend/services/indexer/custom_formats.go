@@ -0,0 +1,263 @@
+package indexer
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"novastream/config"
+	"novastream/models"
+	"novastream/utils/parsett"
+)
+
+// Result attributes holding a release's custom format score and the formats that matched it.
+const (
+	customFormatScoreAttr = "customFormatScore"
+	customFormatsAttr     = "customFormats"
+)
+
+var remuxPattern = regexp.MustCompile(`(?i)\bremux\b`)
+
+// customFormat is an enabled custom format with its patterns compiled. Nil patterns are
+// conditions that are not set.
+type customFormat struct {
+	name         string
+	score        int
+	releaseTitle *regexp.Regexp
+	releaseGroup *regexp.Regexp
+	codec        *regexp.Regexp
+	source       *regexp.Regexp
+	audioFormat  *regexp.Regexp
+	resolution   *regexp.Regexp
+	hdr          *regexp.Regexp
+	remux        string
+}
+
+// getEffectiveCustomFormats returns the custom formats to score search results with.
+// Settings cascade: Global -> Profile -> Client (most specific wins)
+func (s *Service) getEffectiveCustomFormats(userID, clientID string, globalSettings config.Settings) []config.CustomFormat {
+	formats := make([]config.CustomFormat, len(globalSettings.Ranking.CustomFormats))
+	copy(formats, globalSettings.Ranking.CustomFormats)
+	if len(formats) == 0 {
+		return nil
+	}
+
+	// Layer 2: Profile settings override global
+	if userID != "" && s.userSettings != nil {
+		userSettings, err := s.userSettings.Get(userID)
+		if err != nil {
+			log.Printf("[indexer] failed to get user settings for custom formats %s: %v", userID, err)
+		} else if userSettings != nil && userSettings.Ranking != nil && len(userSettings.Ranking.CustomFormats) > 0 {
+			log.Printf("[indexer] applying per-user custom format settings for user %s", userID)
+			formats = applyUserCustomFormatOverrides(formats, userSettings.Ranking.CustomFormats)
+		}
+	}
+
+	// Layer 3: Client settings override profile
+	if clientID != "" && s.clientSettings != nil {
+		clientSettings, err := s.clientSettings.Get(clientID)
+		if err != nil {
+			log.Printf("[indexer] failed to get client settings for custom formats %s: %v", clientID, err)
+		} else if clientSettings != nil && clientSettings.CustomFormats != nil && len(*clientSettings.CustomFormats) > 0 {
+			log.Printf("[indexer] applying per-client custom format settings for client %s", clientID)
+			formats = applyClientCustomFormatOverrides(formats, *clientSettings.CustomFormats)
+		}
+	}
+
+	return formats
+}
+
+// applyUserCustomFormatOverrides applies user-level overrides to the base custom formats.
+func applyUserCustomFormatOverrides(base []config.CustomFormat, overrides []models.UserCustomFormatOverride) []config.CustomFormat {
+	result := make([]config.CustomFormat, len(base))
+	copy(result, base)
+
+	overrideMap := make(map[string]models.UserCustomFormatOverride)
+	for _, o := range overrides {
+		overrideMap[o.ID] = o
+	}
+
+	for i := range result {
+		if override, ok := overrideMap[result[i].ID]; ok {
+			if override.Enabled != nil {
+				result[i].Enabled = *override.Enabled
+			}
+			if override.Score != nil {
+				result[i].Score = *override.Score
+			}
+		}
+	}
+
+	return result
+}
+
+// applyClientCustomFormatOverrides applies client-level overrides to the base custom formats.
+func applyClientCustomFormatOverrides(base []config.CustomFormat, overrides []models.ClientCustomFormatOverride) []config.CustomFormat {
+	result := make([]config.CustomFormat, len(base))
+	copy(result, base)
+
+	overrideMap := make(map[string]models.ClientCustomFormatOverride)
+	for _, o := range overrides {
+		overrideMap[o.ID] = o
+	}
+
+	for i := range result {
+		if override, ok := overrideMap[result[i].ID]; ok {
+			if override.Enabled != nil {
+				result[i].Enabled = *override.Enabled
+			}
+			if override.Score != nil {
+				result[i].Score = *override.Score
+			}
+		}
+	}
+
+	return result
+}
+
+// compileCustomFormats compiles the enabled custom formats. Formats with an invalid pattern
+// are skipped rather than matching everything or nothing.
+func compileCustomFormats(formats []config.CustomFormat) []customFormat {
+	var compiled []customFormat
+	for _, f := range formats {
+		if !f.Enabled || f.Score == 0 {
+			continue
+		}
+
+		cf := customFormat{name: f.Name, score: f.Score, remux: strings.ToLower(strings.TrimSpace(f.Remux))}
+		valid := true
+		for _, field := range []struct {
+			pattern string
+			target  **regexp.Regexp
+		}{
+			{f.ReleaseTitle, &cf.releaseTitle},
+			{f.ReleaseGroup, &cf.releaseGroup},
+			{f.Codec, &cf.codec},
+			{f.Source, &cf.source},
+			{f.AudioFormat, &cf.audioFormat},
+			{f.Resolution, &cf.resolution},
+			{f.HDR, &cf.hdr},
+		} {
+			pattern := strings.TrimSpace(field.pattern)
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				log.Printf("[indexer] skipping custom format %q: invalid pattern %q: %v", f.Name, pattern, err)
+				valid = false
+				break
+			}
+			*field.target = re
+		}
+		if valid {
+			compiled = append(compiled, cf)
+		}
+	}
+	return compiled
+}
+
+// matches reports whether every condition the format sets matches the release.
+func (f customFormat) matches(title string, parsed *parsett.ParsedTitle) bool {
+	if f.releaseTitle != nil && !f.releaseTitle.MatchString(title) {
+		return false
+	}
+
+	isRemux := remuxPattern.MatchString(title)
+	switch f.remux {
+	case config.CustomFormatRemuxOnly:
+		if !isRemux {
+			return false
+		}
+	case config.CustomFormatRemuxExclude:
+		if isRemux {
+			return false
+		}
+	}
+
+	if parsed == nil {
+		parsed = &parsett.ParsedTitle{}
+	}
+	return matchesValue(f.releaseGroup, parsed.Group) &&
+		matchesValue(f.codec, parsed.Codec) &&
+		matchesValue(f.source, parsed.Quality) &&
+		matchesAny(f.audioFormat, parsed.Audio) &&
+		matchesValue(f.resolution, parsed.Resolution) &&
+		matchesAny(f.hdr, parsed.HDR)
+}
+
+// matchesValue reports whether an unset condition or a parsed value matches. Unparsed
+// attributes never match a set condition.
+func matchesValue(re *regexp.Regexp, value string) bool {
+	if re == nil {
+		return true
+	}
+	return value != "" && re.MatchString(value)
+}
+
+func matchesAny(re *regexp.Regexp, values []string) bool {
+	if re == nil {
+		return true
+	}
+	for _, v := range values {
+		if v != "" && re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCustomFormatScores records each result's total custom format score and the names of
+// the formats it matched in its attributes.
+func applyCustomFormatScores(results []models.NZBResult, formats []customFormat) {
+	if len(formats) == 0 {
+		return
+	}
+
+	parsedByTitle := make(map[string]*parsett.ParsedTitle)
+	for i := range results {
+		title := results[i].Title
+		parsed, ok := parsedByTitle[title]
+		if !ok {
+			parsed, _ = parsett.ParseTitle(title)
+			parsedByTitle[title] = parsed
+		}
+
+		score := 0
+		var matched []string
+		for _, f := range formats {
+			if f.matches(title, parsed) {
+				score += f.score
+				matched = append(matched, f.name)
+			}
+		}
+
+		if results[i].Attributes == nil {
+			results[i].Attributes = make(map[string]string)
+		}
+		results[i].Attributes[customFormatScoreAttr] = strconv.Itoa(score)
+		if len(matched) > 0 {
+			results[i].Attributes[customFormatsAttr] = strings.Join(matched, ", ")
+		} else {
+			delete(results[i].Attributes, customFormatsAttr)
+		}
+	}
+}
+
+func customFormatScore(r models.NZBResult) int {
+	score, _ := strconv.Atoi(r.Attributes[customFormatScoreAttr])
+	return score
+}
+
+func compareCustomFormats(i, j models.NZBResult) int {
+	scoreI := customFormatScore(i)
+	scoreJ := customFormatScore(j)
+	if scoreI > scoreJ {
+		return -1
+	}
+	if scoreI < scoreJ {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"sort"
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+)
+
+func TestCustomFormatScores(t *testing.T) {
+	formats := compileCustomFormats([]config.CustomFormat{
+		{Name: "Remux", Enabled: true, Score: 100, Remux: config.CustomFormatRemuxOnly},
+		{Name: "Lossless Audio", Enabled: true, Score: 30, AudioFormat: "TrueHD|DTS Lossless"},
+		{Name: "Good WEB Group", Enabled: true, Score: 50, Source: "^WEB", ReleaseGroup: "^(FLUX|NTb)$"},
+		{Name: "x265 WEB", Enabled: true, Score: -40, Codec: "^hevc$", Source: "^WEB"},
+		{Name: "Disabled", Enabled: false, Score: 1000, ReleaseTitle: "."},
+		{Name: "Broken", Enabled: true, Score: 1000, ReleaseTitle: "(unclosed"},
+	})
+	if len(formats) != 4 {
+		t.Fatalf("compiled %d formats, want 4 (disabled and invalid ones are skipped)", len(formats))
+	}
+
+	results := []models.NZBResult{
+		{Title: "Movie.2020.1080p.WEB-DL.DDP5.1.H.264-FLUX"},
+		{Title: "Movie.2020.2160p.WEB-DL.DDP5.1.HEVC-FLUX"},
+		{Title: "Movie.2020.1080p.BluRay.REMUX.AVC.TrueHD.7.1.Atmos-FGT"},
+		{Title: "Movie.2020.1080p.WEB-DL.DDP5.1.H.264-RandomGroup"},
+		{Title: "Movie.2020.1080p.BluRay.x264-GROUP", Attributes: map[string]string{"customFormats": "stale"}},
+	}
+	applyCustomFormatScores(results, formats)
+
+	want := []struct {
+		score   string
+		matched string
+	}{
+		{"50", "Good WEB Group"},
+		{"10", "Good WEB Group, x265 WEB"},
+		{"130", "Remux, Lossless Audio"},
+		{"0", ""},
+		{"0", ""},
+	}
+	for i, w := range want {
+		attrs := results[i].Attributes
+		if attrs[customFormatScoreAttr] != w.score || attrs[customFormatsAttr] != w.matched {
+			t.Errorf("%s: score=%q formats=%q, want score=%q formats=%q", results[i].Title, attrs[customFormatScoreAttr], attrs[customFormatsAttr], w.score, w.matched)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return compareCustomFormats(results[i], results[j]) < 0
+	})
+	if results[0].Title != "Movie.2020.1080p.BluRay.REMUX.AVC.TrueHD.7.1.Atmos-FGT" || results[1].Title != "Movie.2020.1080p.WEB-DL.DDP5.1.H.264-FLUX" {
+		t.Fatalf("unexpected order: %q, %q", results[0].Title, results[1].Title)
+	}
+}
+
+func TestCustomFormatOverridesLayer(t *testing.T) {
+	global := []config.CustomFormat{
+		{ID: "remux", Name: "Remux", Enabled: true, Score: 100},
+		{ID: "x265", Name: "x265", Enabled: true, Score: -40},
+	}
+	disabled := false
+	profileScore := 20
+	clientScore := 60
+
+	effective := applyUserCustomFormatOverrides(global, []models.UserCustomFormatOverride{
+		{ID: "remux", Score: &profileScore},
+		{ID: "x265", Enabled: &disabled},
+	})
+	effective = applyClientCustomFormatOverrides(effective, []models.ClientCustomFormatOverride{
+		{ID: "remux", Score: &clientScore},
+	})
+
+	if effective[0].Score != 60 || !effective[0].Enabled {
+		t.Errorf("remux = %+v, want the client score", effective[0])
+	}
+	if effective[1].Enabled || effective[1].Score != -40 {
+		t.Errorf("x265 = %+v, want the profile to have disabled it", effective[1])
+	}
+	if global[0].Score != 100 || !global[1].Enabled {
+		t.Error("overrides modified the global formats")
+	}
+}
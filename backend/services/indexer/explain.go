@@ -0,0 +1,221 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+
+	"novastream/config"
+	"novastream/models"
+	release_blocklist "novastream/services/release_blocklist"
+	"novastream/utils/filter"
+	"novastream/utils/language"
+	"novastream/utils/parsett"
+)
+
+// ruleReleaseBlocklist is the rule of results dropped because they failed playback before.
+const ruleReleaseBlocklist = "release-blocklist"
+
+// SearchExplanation records why a search returned what it did: the results that were dropped,
+// with the rule that dropped each, and for the results returned the values each ranking
+// criterion compared. Searches fill it in when SearchOptions.Explain is set.
+type SearchExplanation struct {
+	Criteria        []config.RankingCriterion `json:"criteria"`
+	RankingBypassed bool                      `json:"rankingBypassed,omitempty"` // AIOStreams-only mode keeps the scraper's order
+	Ranked          []RankedResult            `json:"ranked"`
+	Rejected        []filter.Rejection        `json:"rejected"`
+
+	collected filter.Rejections
+}
+
+// RankedResult explains the position of a returned result.
+type RankedResult struct {
+	Position int    `json:"position"`
+	Title    string `json:"title"`
+	GUID     string `json:"guid,omitempty"`
+	// DecidedBy is the criterion that ranked the result below the one before it, or
+	// "release-blocklist" for demoted releases. Empty for the first result and for ties,
+	// which keep the order the results were found in.
+	DecidedBy string          `json:"decidedBy,omitempty"`
+	Factors   []RankingFactor `json:"factors"`
+}
+
+// RankingFactor is the value an enabled ranking criterion compared for a result.
+type RankingFactor struct {
+	Criterion config.RankingCriterionID `json:"criterion"`
+	Value     string                    `json:"value"`
+	// Score is the number the criterion compares; higher ranks first. Audio and video codec
+	// preference ranks are negated, so the most preferred value scores 0.
+	Score int64 `json:"score"`
+}
+
+// rejections returns the collector filters record dropped results in, or nil when the search
+// isn't explained.
+func (e *SearchExplanation) rejections() *filter.Rejections {
+	if e == nil {
+		return nil
+	}
+	return &e.collected
+}
+
+// recordBlocklistDrops records the results the release blocklist dropped.
+func (e *SearchExplanation) recordBlocklistDrops(before, after []models.NZBResult) {
+	if e == nil || len(before) == len(after) {
+		return
+	}
+	kept := make(map[string]int, len(after))
+	for _, r := range after {
+		kept[release_blocklist.ReleaseKey(r)]++
+	}
+	for _, r := range before {
+		key := release_blocklist.ReleaseKey(r)
+		if kept[key] > 0 {
+			kept[key]--
+			continue
+		}
+		e.collected.Add(r, ruleReleaseBlocklist, "failed playback for this title before")
+	}
+}
+
+// finish explains the ranking of the returned results and collects the dropped ones. A nil
+// ranker means ranking was bypassed.
+func (e *SearchExplanation) finish(results []models.NZBResult, rk *ranker) {
+	if e == nil {
+		return
+	}
+
+	// Alternate-title queries filter the same releases again; the first rejection is enough
+	e.Rejected = []filter.Rejection{}
+	seen := make(map[string]bool)
+	for _, rejection := range e.collected.List() {
+		key := rejection.Result.GUID
+		if key == "" {
+			key = rejection.Result.Title
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		e.Rejected = append(e.Rejected, rejection)
+	}
+
+	if rk == nil {
+		e.Criteria = []config.RankingCriterion{}
+		e.RankingBypassed = true
+	} else {
+		e.Criteria = rk.criteria
+	}
+
+	e.Ranked = make([]RankedResult, 0, len(results))
+	for idx, r := range results {
+		entry := RankedResult{
+			Position: idx + 1,
+			Title:    r.Title,
+			GUID:     r.GUID,
+			Factors:  []RankingFactor{},
+		}
+		if rk != nil {
+			entry.Factors = rk.factors(r)
+		}
+		if idx > 0 {
+			prev := results[idx-1]
+			if r.Attributes[release_blocklist.AttrBlocked] != "" && prev.Attributes[release_blocklist.AttrBlocked] == "" {
+				entry.DecidedBy = ruleReleaseBlocklist
+			} else if rk != nil {
+				if result, criterion := rk.compare(prev, r); result != 0 {
+					entry.DecidedBy = string(criterion)
+				}
+			}
+		}
+		e.Ranked = append(e.Ranked, entry)
+	}
+}
+
+// factors returns the values the enabled criteria compare for a result, in criteria order.
+func (rk *ranker) factors(r models.NZBResult) []RankingFactor {
+	var parsed *parsett.ParsedTitle
+	parse := func() *parsett.ParsedTitle {
+		if parsed == nil {
+			parsed, _ = parsett.ParseTitle(r.Title)
+			if parsed == nil {
+				parsed = &parsett.ParsedTitle{}
+			}
+		}
+		return parsed
+	}
+
+	factors := []RankingFactor{}
+	for _, criterion := range rk.criteria {
+		if !criterion.Enabled {
+			continue
+		}
+
+		f := RankingFactor{Criterion: criterion.ID}
+		switch criterion.ID {
+		case config.RankingServicePriority:
+			f.Value = string(r.ServiceType)
+			if isPrioritizedService(r, rk.servicePriority) {
+				f.Score = 1
+			}
+		case config.RankingCustomFormats:
+			f.Value = valueOr(r.Attributes[customFormatsAttr], "none")
+			f.Score = int64(customFormatScore(r))
+		case config.RankingPreferredTerms:
+			var matched []string
+			for _, term := range rk.preferredTerms {
+				if containsPreferredTerm(r.Title, []string{term}) {
+					matched = append(matched, strings.TrimSpace(term))
+				}
+			}
+			f.Value = valueOr(strings.Join(matched, ", "), "none")
+			if len(matched) > 0 {
+				f.Score = 1
+			}
+		case config.RankingResolution:
+			res := extractResolutionFromResult(r)
+			f.Value = "unknown"
+			if res > 0 {
+				f.Value = fmt.Sprintf("%dp", res)
+			}
+			f.Score = int64(res)
+		case config.RankingHDR:
+			switch {
+			case r.Attributes["hasDV"] == "true":
+				f.Value, f.Score = valueOr(r.Attributes["hdr"], "DV"), 2
+			case r.Attributes["hdr"] != "":
+				f.Value, f.Score = r.Attributes["hdr"], 1
+			default:
+				f.Value = "SDR"
+			}
+			if !rk.prioritizeHdr {
+				f.Value += " (not prioritized)"
+				f.Score = 0
+			}
+		case config.RankingAudio:
+			f.Value = valueOr(strings.Join(parse().Audio, ", "), "unknown")
+			f.Score = -int64(codecRank(r, audioRankAttr))
+			if f.Score > 0 {
+				f.Value += " (AAC, no transcode)"
+			}
+		case config.RankingVideoCodec:
+			f.Value = valueOr(parse().Codec, "unknown")
+			f.Score = -int64(codecRank(r, videoCodecRankAttr))
+		case config.RankingLanguage:
+			f.Value = valueOr(r.Attributes["languages"], "unknown")
+			if language.HasPreferredLanguage(r.Attributes["languages"], rk.preferredLang) {
+				f.Score = 1
+			}
+		case config.RankingSize:
+			f.Value = fmt.Sprintf("%.2f GB", float64(r.SizeBytes)/(1024*1024*1024))
+			f.Score = r.SizeBytes
+		}
+		factors = append(factors, f)
+	}
+	return factors
+}
+
+func valueOr(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package indexer
+
+import (
+	"testing"
+
+	"novastream/config"
+	"novastream/models"
+	release_blocklist "novastream/services/release_blocklist"
+	"novastream/utils/filter"
+)
+
+func TestSearchExplanation(t *testing.T) {
+	rk := &ranker{
+		criteria: []config.RankingCriterion{
+			{ID: config.RankingResolution, Enabled: true, Order: 0},
+			{ID: config.RankingHDR, Enabled: false, Order: 1},
+			{ID: config.RankingSize, Enabled: true, Order: 2},
+		},
+	}
+	results := []models.NZBResult{
+		{Title: "Movie.2020.2160p.WEB-DL-GRP", SizeBytes: 10},
+		{Title: "Movie.2020.1080p.WEB-DL-GRP", SizeBytes: 20},
+		{Title: "Movie.2020.1080p.WEBRip-GRP", SizeBytes: 5},
+		{Title: "Movie.2020.1080p.HDTV-GRP", SizeBytes: 5},
+		{Title: "Movie.2020.2160p.BluRay-GRP", SizeBytes: 50, Attributes: map[string]string{release_blocklist.AttrBlocked: "failed"}},
+	}
+
+	explain := &SearchExplanation{}
+	explain.rejections().Add(models.NZBResult{Title: "Other.Movie.2020.1080p"}, filter.RuleTitleSimilarity, "title similarity 10%")
+	explain.rejections().Add(models.NZBResult{Title: "Other.Movie.2020.1080p"}, filter.RuleTitleSimilarity, "title similarity 10%")
+	explain.finish(results, rk)
+
+	if len(explain.Rejected) != 1 {
+		t.Fatalf("expected repeated rejections to be collapsed, got %+v", explain.Rejected)
+	}
+
+	wantDecidedBy := []string{"", string(config.RankingResolution), string(config.RankingSize), "", ruleReleaseBlocklist}
+	for i, ranked := range explain.Ranked {
+		if ranked.Position != i+1 || ranked.DecidedBy != wantDecidedBy[i] {
+			t.Errorf("%s: position=%d decidedBy=%q, want %d %q", ranked.Title, ranked.Position, ranked.DecidedBy, i+1, wantDecidedBy[i])
+		}
+		// Disabled criteria are left out
+		if len(ranked.Factors) != 2 || ranked.Factors[0].Criterion != config.RankingResolution || ranked.Factors[1].Criterion != config.RankingSize {
+			t.Fatalf("%s: unexpected factors %+v", ranked.Title, ranked.Factors)
+		}
+	}
+	if f := explain.Ranked[0].Factors[0]; f.Value != "2160p" || f.Score != 2160 {
+		t.Fatalf("unexpected resolution factor %+v", f)
+	}
+}
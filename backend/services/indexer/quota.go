@@ -0,0 +1,403 @@
+package indexer
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+const (
+	// usageRetentionDays is how long daily usage counters are kept in the database.
+	usageRetentionDays = 30
+	// maxFailureBackoff caps the cooling-off period of a failing indexer.
+	maxFailureBackoff = time.Hour
+	// limitPauseFallback is used when an indexer reports a limit without saying when it resets.
+	limitPauseFallback = time.Hour
+)
+
+// usageStore persists daily indexer counters.
+type usageStore interface {
+	IncrementIndexerUsage(indexer, day string, searches, grabs, failures int) error
+	ListIndexerUsage(day string) ([]*database.IndexerUsage, error)
+	DeleteIndexerUsageBefore(day string) (int64, error)
+}
+
+// apiLimits is the newznab:apilimits element indexers include in search responses.
+type apiLimits struct {
+	APICurrent     string `xml:"apicurrent,attr"`
+	APIMax         string `xml:"apimax,attr"`
+	GrabCurrent    string `xml:"grabcurrent,attr"`
+	GrabMax        string `xml:"grabmax,attr"`
+	APIOldestTime  string `xml:"apioldesttime,attr"`
+	GrabOldestTime string `xml:"graboldesttime,attr"`
+}
+
+// newznabError is the body indexers return instead of a feed when a request is refused.
+type newznabError struct {
+	Code        string `xml:"code,attr"`
+	Description string `xml:"description,attr"`
+}
+
+// limitKind returns "api" or "grab" when the error reports a reached limit.
+func (e newznabError) limitKind() string {
+	desc := strings.ToLower(e.Description)
+	switch {
+	case e.Code == "501" || strings.Contains(desc, "download limit") || strings.Contains(desc, "grab limit"):
+		return "grab"
+	case e.Code == "500" || e.Code == "429" || strings.Contains(desc, "limit"):
+		return "api"
+	}
+	return ""
+}
+
+// IndexerUsageStatus is an indexer's usage today and whether it is currently paused.
+type IndexerUsageStatus struct {
+	Name                string     `json:"name"`
+	Enabled             bool       `json:"enabled"`
+	Searches            int        `json:"searches"`
+	Grabs               int        `json:"grabs"`
+	Failures            int        `json:"failures"`
+	APILimit            int        `json:"apiLimit"`
+	GrabLimit           int        `json:"grabLimit"`
+	ReportedAPICurrent  int        `json:"reportedApiCurrent,omitempty"`
+	ReportedAPIMax      int        `json:"reportedApiMax,omitempty"`
+	ReportedGrabCurrent int        `json:"reportedGrabCurrent,omitempty"`
+	ReportedGrabMax     int        `json:"reportedGrabMax,omitempty"`
+	PausedUntil         *time.Time `json:"pausedUntil,omitempty"`
+	PauseReason         string     `json:"pauseReason,omitempty"`
+	GrabsPausedUntil    *time.Time `json:"grabsPausedUntil,omitempty"`
+	LastError           string     `json:"lastError,omitempty"`
+}
+
+type indexerUsageState struct {
+	searches, grabs, failures int
+
+	consecutiveFailures int
+	pausedUntil         time.Time
+	pauseReason         string
+	grabsPausedUntil    time.Time
+	lastError           string
+
+	reported apiLimits
+}
+
+// usageTracker counts searches and grabs per indexer and UTC day and decides when an
+// indexer has to sit out because of a quota or repeated failures.
+type usageTracker struct {
+	mu       sync.Mutex
+	store    usageStore
+	day      string
+	indexers map[string]*indexerUsageState
+	now      func() time.Time
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{indexers: make(map[string]*indexerUsageState), now: time.Now}
+}
+
+// setStore switches to persisted counters, loading today's usage.
+func (t *usageTracker) setStore(store usageStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+	t.day = ""
+	t.rollover()
+}
+
+// rollover resets the counters when the UTC day changes. Callers hold t.mu.
+func (t *usageTracker) rollover() {
+	now := t.now().UTC()
+	day := now.Format("2006-01-02")
+	if day == t.day {
+		return
+	}
+	t.day = day
+
+	for _, state := range t.indexers {
+		state.searches, state.grabs, state.failures = 0, 0, 0
+	}
+	if t.store == nil {
+		return
+	}
+
+	usage, err := t.store.ListIndexerUsage(day)
+	if err != nil {
+		log.Printf("[indexer] failed to load indexer usage: %v", err)
+	}
+	for _, u := range usage {
+		state := t.state(u.Indexer)
+		state.searches, state.grabs, state.failures = u.Searches, u.Grabs, u.Failures
+	}
+	cutoff := now.AddDate(0, 0, -usageRetentionDays).Format("2006-01-02")
+	if _, err := t.store.DeleteIndexerUsageBefore(cutoff); err != nil {
+		log.Printf("[indexer] failed to prune indexer usage: %v", err)
+	}
+}
+
+// state returns an indexer's state, creating it. Callers hold t.mu.
+func (t *usageTracker) state(name string) *indexerUsageState {
+	state, ok := t.indexers[name]
+	if !ok {
+		state = &indexerUsageState{}
+		t.indexers[name] = state
+	}
+	return state
+}
+
+func (t *usageTracker) persist(name string, searches, grabs, failures int) {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.IncrementIndexerUsage(name, t.day, searches, grabs, failures); err != nil {
+		log.Printf("[indexer] failed to record usage for %s: %v", name, err)
+	}
+}
+
+// allowSearch returns an error when the indexer is paused or out of searches for today.
+func (t *usageTracker) allowSearch(idx config.IndexerConfig) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	state := t.state(idx.Name)
+	if until := state.pausedUntil; t.now().Before(until) {
+		return fmt.Errorf("indexer %s paused until %s: %s", idx.Name, until.Local().Format(time.Kitchen), state.pauseReason)
+	}
+	if idx.APILimit > 0 && state.searches >= idx.APILimit {
+		return fmt.Errorf("indexer %s reached its daily API limit (%d)", idx.Name, idx.APILimit)
+	}
+	return nil
+}
+
+// allowGrab returns an error when the indexer can't serve more NZB downloads today.
+func (t *usageTracker) allowGrab(idx config.IndexerConfig) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	state := t.state(idx.Name)
+	if until := state.grabsPausedUntil; t.now().Before(until) {
+		return fmt.Errorf("indexer %s grab limit reached until %s", idx.Name, until.Local().Format(time.Kitchen))
+	}
+	if idx.GrabLimit > 0 && state.grabs >= idx.GrabLimit {
+		return fmt.Errorf("indexer %s reached its daily grab limit (%d)", idx.Name, idx.GrabLimit)
+	}
+	return nil
+}
+
+// recordSearch counts a search. Failures pause the indexer for a cooling-off period that
+// doubles with each consecutive failure.
+func (t *usageTracker) recordSearch(name string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	state := t.state(name)
+	state.searches++
+	if err == nil {
+		state.consecutiveFailures = 0
+		t.persist(name, 1, 0, 0)
+		return
+	}
+
+	state.failures++
+	state.consecutiveFailures++
+	state.lastError = err.Error()
+	t.persist(name, 1, 0, 1)
+
+	backoff := maxFailureBackoff
+	if state.consecutiveFailures <= 6 {
+		backoff = time.Minute << (state.consecutiveFailures - 1)
+	}
+	if until := t.now().Add(backoff); until.After(state.pausedUntil) {
+		state.pausedUntil = until
+		state.pauseReason = fmt.Sprintf("%d consecutive failures", state.consecutiveFailures)
+		log.Printf("[indexer] pausing %s for %s after %d consecutive failures: %v", name, backoff, state.consecutiveFailures, err)
+	}
+}
+
+// recordGrab counts an NZB download.
+func (t *usageTracker) recordGrab(name string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	state := t.state(name)
+	state.grabs++
+	failures := 0
+	if err != nil {
+		state.failures++
+		state.lastError = err.Error()
+		failures = 1
+	}
+	t.persist(name, 0, 1, failures)
+}
+
+// observeLimits records the quota an indexer reported and pauses it once exhausted.
+func (t *usageTracker) observeLimits(name string, limits apiLimits) {
+	if limits == (apiLimits{}) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(name)
+	state.reported = limits
+
+	if current, limit := atoi(limits.APICurrent), atoi(limits.APIMax); limit > 0 && current >= limit {
+		t.pauseLocked(state, name, "api", t.limitReset(limits.APIOldestTime), fmt.Sprintf("API limit reached (%d/%d)", current, limit))
+	}
+	if current, limit := atoi(limits.GrabCurrent), atoi(limits.GrabMax); limit > 0 && current >= limit {
+		t.pauseLocked(state, name, "grab", t.limitReset(limits.GrabOldestTime), fmt.Sprintf("grab limit reached (%d/%d)", current, limit))
+	}
+}
+
+// limitReached pauses searches ("api") or grabs ("grab") until the given time.
+func (t *usageTracker) limitReached(name, kind string, until time.Time, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pauseLocked(t.state(name), name, kind, until, reason)
+}
+
+func (t *usageTracker) pauseLocked(state *indexerUsageState, name, kind string, until time.Time, reason string) {
+	if kind == "grab" {
+		if until.After(state.grabsPausedUntil) {
+			state.grabsPausedUntil = until
+			log.Printf("[indexer] %s: %s, pausing grabs until %s", name, reason, until.Format(time.RFC3339))
+		}
+		return
+	}
+	if until.After(state.pausedUntil) {
+		state.pausedUntil = until
+		state.pauseReason = reason
+		log.Printf("[indexer] %s: %s, pausing searches until %s", name, reason, until.Format(time.RFC3339))
+	}
+}
+
+// limitReset estimates when a rolling 24 hour quota frees up from the oldest counted hit,
+// falling back to the next UTC midnight.
+func (t *usageTracker) limitReset(oldest string) time.Time {
+	now := t.now()
+	if at := parsePubDate(oldest); !at.IsZero() {
+		if reset := at.Add(24 * time.Hour); reset.After(now) {
+			return reset
+		}
+		return now.Add(limitPauseFallback)
+	}
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// snapshot reports usage for the configured indexers.
+func (t *usageTracker) snapshot(indexers []config.IndexerConfig) []IndexerUsageStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	now := t.now()
+	statuses := make([]IndexerUsageStatus, 0, len(indexers))
+	for _, idx := range indexers {
+		state := t.state(idx.Name)
+		status := IndexerUsageStatus{
+			Name:                idx.Name,
+			Enabled:             idx.Enabled,
+			Searches:            state.searches,
+			Grabs:               state.grabs,
+			Failures:            state.failures,
+			APILimit:            idx.APILimit,
+			GrabLimit:           idx.GrabLimit,
+			ReportedAPICurrent:  atoi(state.reported.APICurrent),
+			ReportedAPIMax:      atoi(state.reported.APIMax),
+			ReportedGrabCurrent: atoi(state.reported.GrabCurrent),
+			ReportedGrabMax:     atoi(state.reported.GrabMax),
+			LastError:           state.lastError,
+		}
+		if now.Before(state.pausedUntil) {
+			until := state.pausedUntil
+			status.PausedUntil = &until
+			status.PauseReason = state.pauseReason
+		}
+		if now.Before(state.grabsPausedUntil) {
+			until := state.grabsPausedUntil
+			status.GrabsPausedUntil = &until
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func atoi(value string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return n
+}
+
+// quota returns the service's usage tracker.
+func (s *Service) quota() *usageTracker {
+	s.usageOnce.Do(func() {
+		if s.usage == nil {
+			s.usage = newUsageTracker()
+		}
+	})
+	return s.usage
+}
+
+// SetUsageRepository persists indexer usage counters so daily limits survive restarts.
+func (s *Service) SetUsageRepository(repo *database.IndexerUsageRepository) {
+	if repo == nil {
+		return
+	}
+	s.quota().setStore(repo)
+}
+
+// AllowGrab returns an error when the named indexer has no NZB downloads left today.
+// Unknown indexers are always allowed.
+func (s *Service) AllowGrab(indexerName string) error {
+	idx, ok := s.configuredIndexer(indexerName)
+	if !ok {
+		return nil
+	}
+	return s.quota().allowGrab(idx)
+}
+
+// RecordGrab counts an NZB download from the named indexer.
+func (s *Service) RecordGrab(indexerName string, err error) {
+	if _, ok := s.configuredIndexer(indexerName); !ok {
+		return
+	}
+	s.quota().recordGrab(indexerName, err)
+}
+
+// Usage reports today's usage and pause state of every configured indexer.
+func (s *Service) Usage() ([]IndexerUsageStatus, error) {
+	if s.cfg == nil {
+		return nil, fmt.Errorf("config manager not configured")
+	}
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load settings: %w", err)
+	}
+	return s.quota().snapshot(settings.Indexers), nil
+}
+
+func (s *Service) configuredIndexer(name string) (config.IndexerConfig, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || s.cfg == nil {
+		return config.IndexerConfig{}, false
+	}
+	settings, err := s.cfg.Load()
+	if err != nil {
+		return config.IndexerConfig{}, false
+	}
+	for _, idx := range settings.Indexers {
+		if idx.Name == name {
+			return idx, true
+		}
+	}
+	return config.IndexerConfig{}, false
+}
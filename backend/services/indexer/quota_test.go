@@ -0,0 +1,167 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+func newQuotaTestService(t *testing.T, indexers ...config.IndexerConfig) (*Service, config.Settings) {
+	t.Helper()
+	mgr := config.NewManager(filepath.Join(t.TempDir(), "settings.json"))
+	settings := config.DefaultSettings()
+	settings.Indexers = indexers
+	settings.Streaming.ServiceMode = config.StreamingServiceModeUsenet
+	if err := mgr.Save(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+	return NewService(mgr, nil, nil), settings
+}
+
+func TestUsageTracker_FailureBackoff(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newUsageTracker()
+	tracker.now = func() time.Time { return now }
+	idx := config.IndexerConfig{Name: "Flaky", Enabled: true}
+
+	tracker.recordSearch("Flaky", errors.New("timeout"))
+	if err := tracker.allowSearch(idx); err == nil {
+		t.Fatal("expected indexer to be paused after a failure")
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := tracker.allowSearch(idx); err != nil {
+		t.Fatalf("expected pause to expire after a minute, got %v", err)
+	}
+
+	tracker.recordSearch("Flaky", errors.New("timeout"))
+	now = now.Add(61 * time.Second)
+	if err := tracker.allowSearch(idx); err == nil {
+		t.Fatal("expected second failure to double the backoff")
+	}
+
+	now = now.Add(time.Minute)
+	tracker.recordSearch("Flaky", nil)
+	tracker.recordSearch("Flaky", errors.New("timeout"))
+	now = now.Add(61 * time.Second)
+	if err := tracker.allowSearch(idx); err != nil {
+		t.Fatalf("expected a success to reset the backoff, got %v", err)
+	}
+
+	status := tracker.snapshot([]config.IndexerConfig{idx})[0]
+	if status.Searches != 4 || status.Failures != 3 || status.LastError != "timeout" {
+		t.Fatalf("unexpected usage %+v", status)
+	}
+}
+
+func TestUsageTracker_DailyLimitsPersist(t *testing.T) {
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "usage.db")})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	repo := database.NewIndexerUsageRepository(db.Connection())
+
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	idx := config.IndexerConfig{Name: "Limited", Enabled: true, APILimit: 2, GrabLimit: 1}
+
+	tracker := newUsageTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.setStore(repo)
+	tracker.recordSearch("Limited", nil)
+	tracker.recordSearch("Limited", nil)
+	tracker.recordGrab("Limited", nil)
+
+	// A restart keeps today's counters
+	restarted := newUsageTracker()
+	restarted.now = func() time.Time { return now }
+	restarted.setStore(repo)
+	if err := restarted.allowSearch(idx); err == nil {
+		t.Fatal("expected daily API limit to be enforced after restart")
+	}
+	if err := restarted.allowGrab(idx); err == nil {
+		t.Fatal("expected daily grab limit to be enforced after restart")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := restarted.allowSearch(idx); err != nil {
+		t.Fatalf("expected limits to reset on a new day, got %v", err)
+	}
+	if err := restarted.allowGrab(idx); err != nil {
+		t.Fatalf("expected grab limit to reset on a new day, got %v", err)
+	}
+}
+
+func TestFetchUsenetResults_PausesIndexerOnLimitError(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<error code="500" description="Request limit reached"/>`))
+	}))
+	defer mockServer.Close()
+
+	svc, settings := newQuotaTestService(t,
+		config.IndexerConfig{Name: "Limited", URL: mockServer.URL, APIKey: "key", Type: "newznab", Enabled: true},
+	)
+
+	if _, err := svc.fetchUsenetResults(context.Background(), settings, SearchOptions{Query: "test"}); err == nil {
+		t.Fatal("expected limit error to be returned")
+	}
+	if _, err := svc.fetchUsenetResults(context.Background(), settings, SearchOptions{Query: "test"}); err == nil {
+		t.Fatal("expected paused indexer to be skipped with an error")
+	}
+	if requests != 1 {
+		t.Fatalf("expected paused indexer not to be queried again, got %d requests", requests)
+	}
+
+	usage, err := svc.Usage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usage) != 1 || usage[0].PausedUntil == nil || usage[0].Searches != 1 || usage[0].Failures != 1 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestSearchTorznab_ObservesReportedLimits(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:newznab="http://www.newznab.com/DTD/2010/feeds/attributes/">
+<channel>
+<newznab:apilimits apicurrent="100" apimax="100" grabcurrent="3" grabmax="25"/>
+</channel>
+</rss>`))
+	}))
+	defer mockServer.Close()
+
+	idx := config.IndexerConfig{Name: "Reporting", URL: mockServer.URL, APIKey: "key", Type: "newznab", Enabled: true}
+	svc, _ := newQuotaTestService(t, idx)
+
+	if _, err := svc.searchTorznab(context.Background(), idx, SearchOptions{Query: "test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.quota().allowSearch(idx); err == nil {
+		t.Fatal("expected exhausted API quota to pause searches")
+	}
+	if err := svc.AllowGrab("Reporting"); err != nil {
+		t.Fatalf("expected grabs to remain available, got %v", err)
+	}
+
+	usage, err := svc.Usage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage[0].ReportedAPIMax != 100 || usage[0].ReportedGrabCurrent != 3 || usage[0].ReportedGrabMax != 25 {
+		t.Fatalf("unexpected reported limits %+v", usage[0])
+	}
+}
@@ -19,12 +19,32 @@ import (
 	"unicode"
 
 	"novastream/config"
+	"novastream/internal/metrics"
 	"novastream/models"
 	"novastream/services/debrid"
+	release_blocklist "novastream/services/release_blocklist"
 	"novastream/utils/filter"
 	"novastream/utils/language"
 
 	"github.com/mozillazg/go-unidecode"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	indexerSearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "novastream_indexer_search_duration_seconds",
+		Help:    "Latency of Newznab/Torznab indexer searches.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"indexer"})
+	indexerResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_indexer_results_total",
+		Help: "Results returned by Newznab/Torznab indexers before filtering.",
+	}, []string{"indexer"})
+	indexerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "novastream_indexer_errors_total",
+		Help: "Failed Newznab/Torznab indexer searches.",
+	}, []string{"indexer"})
 )
 
 // newznabQuerySanitizer removes special characters that interfere with newznab/torznab search APIs.
@@ -79,6 +99,11 @@ type clientSettingsProvider interface {
 	Get(clientID string) (*models.ClientFilterSettings, error)
 }
 
+// releaseBlocklist drops or demotes releases that already failed playback for a title.
+type releaseBlocklist interface {
+	Apply(titleKey, titleName string, results []models.NZBResult) []models.NZBResult
+}
+
 type (
 	debridSearchService interface {
 		Search(context.Context, debrid.SearchOptions) ([]models.NZBResult, error)
@@ -101,6 +126,13 @@ type Service struct {
 	metadata       metadataSearchService
 	userSettings   userSettingsProvider
 	clientSettings clientSettingsProvider
+	blocklist      releaseBlocklist
+
+	capsMu sync.Mutex
+	caps   map[string]indexerCaps // t=caps responses keyed by endpoint and API key
+
+	usageOnce sync.Once
+	usage     *usageTracker
 }
 
 func NewService(cfg *config.Manager, metadataSvc metadataSearchService, debridSvc debridSearchService) *Service {
@@ -126,6 +158,11 @@ func (s *Service) SetClientSettingsProvider(provider clientSettingsProvider) {
 	s.clientSettings = provider
 }
 
+// SetReleaseBlocklist enables dropping or demoting releases that failed playback before.
+func (s *Service) SetReleaseBlocklist(blocklist releaseBlocklist) {
+	s.blocklist = blocklist
+}
+
 // getEffectiveFilterSettings returns the filtering settings to use for a search.
 // Settings cascade: Global -> Profile -> Client (client settings win)
 func (s *Service) getEffectiveFilterSettings(userID, clientID string, globalSettings config.Settings) models.FilterSettings {
@@ -299,16 +336,60 @@ func applyClientRankingOverrides(base []config.RankingCriterion, overrides []mod
 	return result
 }
 
+// ranker orders search results by the enabled ranking criteria, in order.
+type ranker struct {
+	criteria        []config.RankingCriterion
+	servicePriority config.StreamingServicePriority
+	preferredTerms  []string
+	prioritizeHdr   bool
+	preferredLang   string
+}
+
+// compare returns -1 if i ranks first, 1 if j does and 0 for a tie, with the criterion that
+// decided it.
+func (rk *ranker) compare(i, j models.NZBResult) (int, config.RankingCriterionID) {
+	for _, criterion := range rk.criteria {
+		if !criterion.Enabled {
+			continue
+		}
+
+		var result int
+		switch criterion.ID {
+		case config.RankingServicePriority:
+			result = compareServicePriority(i, j, rk.servicePriority)
+		case config.RankingCustomFormats:
+			result = compareCustomFormats(i, j)
+		case config.RankingPreferredTerms:
+			result = comparePreferredTerms(i, j, rk.preferredTerms)
+		case config.RankingResolution:
+			result = compareResolution(i, j)
+		case config.RankingHDR:
+			result = compareHDR(i, j, rk.prioritizeHdr)
+		case config.RankingAudio:
+			result = compareAudio(i, j)
+		case config.RankingVideoCodec:
+			result = compareVideoCodec(i, j)
+		case config.RankingLanguage:
+			result = compareLanguage(i, j, rk.preferredLang)
+		case config.RankingSize:
+			result = compareSize(i, j)
+		}
+
+		if result != 0 {
+			return result, criterion.ID
+		}
+	}
+	return 0, ""
+}
+
 // Comparison functions return -1 if i wins, 0 if tie, 1 if j wins.
 
 func compareServicePriority(i, j models.NZBResult, priority config.StreamingServicePriority) int {
 	if priority == config.StreamingServicePriorityNone {
 		return 0
 	}
-	iIsPrioritized := (priority == config.StreamingServicePriorityUsenet && i.ServiceType == models.ServiceTypeUsenet) ||
-		(priority == config.StreamingServicePriorityDebrid && i.ServiceType == models.ServiceTypeDebrid)
-	jIsPrioritized := (priority == config.StreamingServicePriorityUsenet && j.ServiceType == models.ServiceTypeUsenet) ||
-		(priority == config.StreamingServicePriorityDebrid && j.ServiceType == models.ServiceTypeDebrid)
+	iIsPrioritized := isPrioritizedService(i, priority)
+	jIsPrioritized := isPrioritizedService(j, priority)
 
 	if iIsPrioritized && !jIsPrioritized {
 		return -1
@@ -319,6 +400,13 @@ func compareServicePriority(i, j models.NZBResult, priority config.StreamingServ
 	return 0
 }
 
+// isPrioritizedService reports whether a result comes from the prioritized service. Direct
+// streams come from debrid scrapers and count as debrid.
+func isPrioritizedService(r models.NZBResult, priority config.StreamingServicePriority) bool {
+	return (priority == config.StreamingServicePriorityUsenet && r.ServiceType == models.ServiceTypeUsenet) ||
+		(priority == config.StreamingServicePriorityDebrid && (r.ServiceType == models.ServiceTypeDebrid || r.ServiceType == models.ServiceTypeDirect))
+}
+
 func comparePreferredTerms(i, j models.NZBResult, terms []string) int {
 	if len(terms) == 0 {
 		return 0
@@ -401,12 +489,16 @@ type SearchOptions struct {
 	Categories          []string
 	MaxResults          int
 	IMDBID              string
+	TVDBID              string // Optional TVDB series ID for ID-based TV searches
 	MediaType           string // "movie" or "series"
 	Year                int    // Release year (for movies)
 	UserID              string // Optional: user ID for per-user filtering settings
 	ClientID            string // Optional: client ID for per-client filtering settings
 	TotalSeriesEpisodes int    // Deprecated: use EpisodeResolver instead
 	EpisodeResolver     filter.EpisodeCountResolver // Optional: resolver for accurate episode counts from metadata
+	Explain             *SearchExplanation          // Optional: filled in with why results were dropped and how the rest were ranked
+
+	alternateQuery bool // Alternate-title query; indexers searched by ID already covered it
 }
 
 func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBResult, error) {
@@ -478,6 +570,7 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 				Categories:          append([]string{}, opts.Categories...),
 				MaxResults:          opts.MaxResults,
 				IMDBID:              opts.IMDBID,
+				TVDBID:              opts.TVDBID,
 				MediaType:           opts.MediaType,
 				Year:                opts.Year,
 				AlternateTitles:     append([]string{}, alternateTitles...),
@@ -485,6 +578,7 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 				ClientID:            opts.ClientID,
 				TotalSeriesEpisodes: opts.TotalSeriesEpisodes,
 				EpisodeResolver:     opts.EpisodeResolver,
+				Rejections:          opts.Explain.rejections(),
 			}
 			debridResults, err := s.debrid.Search(ctx, debOpts)
 			if err != nil {
@@ -531,6 +625,7 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 		isOnlyAIOStreamsEnabled(settings.TorrentScrapers) &&
 		!includeUsenet
 
+	var rk *ranker
 	if bypassRanking {
 		log.Printf("[indexer] Bypassing strmr ranking - AIOStreams is the only enabled scraper and bypass setting is enabled")
 	} else {
@@ -538,42 +633,48 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 		rankingCriteria := s.getEffectiveRankingCriteria(opts.UserID, opts.ClientID, settings)
 		log.Printf("[indexer] Sorting %d results with %d ranking criteria, ServicePriority=%q", len(aggregated), len(rankingCriteria), settings.Streaming.ServicePriority)
 
-		// Cache settings needed for comparison functions
-		servicePriority := settings.Streaming.ServicePriority
-		preferredTerms := filterSettings.PreferredTerms
-		prioritizeHdr := models.BoolVal(filterSettings.PrioritizeHdr, false)
-		preferredLang := settings.Metadata.Language
-
-		sort.SliceStable(aggregated, func(i, j int) bool {
-			for _, criterion := range rankingCriteria {
-				if !criterion.Enabled {
-					continue
-				}
-
-				var result int
-				switch criterion.ID {
-				case config.RankingServicePriority:
-					result = compareServicePriority(aggregated[i], aggregated[j], servicePriority)
-				case config.RankingPreferredTerms:
-					result = comparePreferredTerms(aggregated[i], aggregated[j], preferredTerms)
-				case config.RankingResolution:
-					result = compareResolution(aggregated[i], aggregated[j])
-				case config.RankingHDR:
-					result = compareHDR(aggregated[i], aggregated[j], prioritizeHdr)
-				case config.RankingLanguage:
-					result = compareLanguage(aggregated[i], aggregated[j], preferredLang)
-				case config.RankingSize:
-					result = compareSize(aggregated[i], aggregated[j])
-				}
-
-				if result != 0 {
-					return result < 0
+		// Score custom formats and rank codecs once up front; the comparisons only read the results
+		codecsRanked := false
+		for _, criterion := range rankingCriteria {
+			if !criterion.Enabled {
+				continue
+			}
+			switch criterion.ID {
+			case config.RankingCustomFormats:
+				applyCustomFormatScores(aggregated, compileCustomFormats(s.getEffectiveCustomFormats(opts.UserID, opts.ClientID, settings)))
+			case config.RankingAudio, config.RankingVideoCodec:
+				if !codecsRanked {
+					applyCodecRanks(aggregated, s.getEffectiveCodecPreferences(opts.UserID, opts.ClientID, settings))
+					codecsRanked = true
 				}
 			}
-			return false
+		}
+
+		rk = &ranker{
+			criteria:        rankingCriteria,
+			servicePriority: settings.Streaming.ServicePriority,
+			preferredTerms:  filterSettings.PreferredTerms,
+			prioritizeHdr:   models.BoolVal(filterSettings.PrioritizeHdr, false),
+			preferredLang:   settings.Metadata.Language,
+		}
+		sort.SliceStable(aggregated, func(i, j int) bool {
+			result, _ := rk.compare(aggregated[i], aggregated[j])
+			return result < 0
 		})
 	}
 
+	// Releases that failed playback for this title go last (or are dropped)
+	if s.blocklist != nil {
+		year := opts.Year
+		if year == 0 {
+			year = parsedQuery.Year
+		}
+		titleKey := release_blocklist.TitleKey(opts.IMDBID, parsedQuery.Title, year, parsedQuery.Season, parsedQuery.Episode)
+		ranked := aggregated
+		aggregated = s.blocklist.Apply(titleKey, strings.TrimSpace(opts.Query), aggregated)
+		opts.Explain.recordBlocklistDrops(ranked, aggregated)
+	}
+
 	// Debug: log top results after sorting
 	for idx := 0; idx < len(aggregated) && idx < 5; idx++ {
 		res := extractResolutionFromResult(aggregated[idx])
@@ -581,9 +682,14 @@ func (s *Service) Search(ctx context.Context, opts SearchOptions) ([]models.NZBR
 	}
 
 	if opts.MaxResults > 0 && len(aggregated) > opts.MaxResults {
+		for _, dropped := range aggregated[opts.MaxResults:] {
+			opts.Explain.rejections().Add(dropped, filter.RuleMaxResults, fmt.Sprintf("ranked below the first %d results", opts.MaxResults))
+		}
 		aggregated = aggregated[:opts.MaxResults]
 	}
 
+	opts.Explain.finish(aggregated, rk)
+
 	return aggregated, nil
 }
 
@@ -828,6 +934,7 @@ func (s *Service) searchUsenetWithFilter(ctx context.Context, settings config.Se
 		go func(priority int, q string) {
 			queryOpts := opts
 			queryOpts.Query = q
+			queryOpts.alternateQuery = priority > 0
 
 			if priority > 0 {
 				log.Printf("[indexer/usenet] parallel search with alternate query: %q", q)
@@ -959,11 +1066,27 @@ func (s *Service) fetchUsenetResults(ctx context.Context, settings config.Settin
 
 		switch strings.ToLower(strings.TrimSpace(idx.Type)) {
 		case "", "newznab", "torznab":
+			if err := s.quota().allowSearch(idx); err != nil {
+				log.Printf("[indexer] skipping %s: %v", idx.Name, err)
+				lastErr = err
+				continue
+			}
+			start := time.Now()
 			results, err := s.searchTorznab(ctx, idx, opts)
+			indexerSearchDuration.WithLabelValues(idx.Name).Observe(time.Since(start).Seconds())
+			// nil results without an error mean the search was skipped. Searches cancelled
+			// because another query already answered don't count against the indexer.
+			if results != nil || err != nil {
+				if err == nil || ctx.Err() == nil {
+					s.quota().recordSearch(idx.Name, err)
+				}
+			}
 			if err != nil {
+				indexerErrors.WithLabelValues(idx.Name).Inc()
 				lastErr = err
 				continue
 			}
+			indexerResults.WithLabelValues(idx.Name).Add(float64(len(results)))
 			allResults = append(allResults, results...)
 		default:
 			lastErr = fmt.Errorf("unsupported indexer type %q", idx.Type)
@@ -1022,6 +1145,7 @@ func (s *Service) applyUsenetFilteringWithSettings(results []models.NZBResult, o
 		PrioritizeHdr:    models.BoolVal(filterSettings.PrioritizeHdr, false),
 		AlternateTitles:  alternateTitles,
 		FilterOutTerms:   filterSettings.FilterOutTerms,
+		Rejections:       opts.Explain.rejections(),
 	}
 
 	log.Printf("[indexer/usenet] Applying filter with title=%q, year=%d, isMovie=%t",
@@ -1063,7 +1187,8 @@ func shouldUseDebrid(mode config.StreamingServiceMode) bool {
 
 type rssFeed struct {
 	Channel struct {
-		Items []rssItem `xml:"item"`
+		Items     []rssItem `xml:"item"`
+		APILimits apiLimits `xml:"apilimits"`
 	} `xml:"channel"`
 }
 
@@ -1109,10 +1234,25 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 		return nil, fmt.Errorf("parse indexer url: %w", err)
 	}
 
-	params := url.Values{}
+	searchURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join(u.Path, "")}
+
+	// Prefer ID searches, which find renamed and foreign-titled releases that text misses
+	var params url.Values
+	if hasSearchIDs(opts) {
+		params = idSearchParams(s.getCaps(ctx, idx, searchURL.String()), opts)
+	}
+	idSearch := params != nil
+	if idSearch && opts.alternateQuery {
+		return nil, nil
+	}
+	if idSearch {
+		log.Printf("[indexer/newznab] using ID search for %s: %s", idx.Name, params.Encode())
+	} else {
+		params = url.Values{}
+		params.Set("t", "search")
+	}
 	params.Set("apikey", idx.APIKey)
-	params.Set("t", "search")
-	if opts.Query != "" {
+	if !idSearch && opts.Query != "" {
 		// Sanitize query to remove special characters that break newznab/torznab searches
 		sanitizedQuery := sanitizeNewznabQuery(opts.Query)
 		params.Set("q", sanitizedQuery)
@@ -1128,7 +1268,6 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 		params.Set("cat", strings.Join(opts.Categories, ","))
 	}
 
-	searchURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join(u.Path, "")}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -1141,6 +1280,13 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		until := time.Now().Add(limitPauseFallback)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			until = time.Now().Add(time.Duration(secs) * time.Second)
+		}
+		s.quota().limitReached(idx.Name, "api", until, "rate limited (HTTP 429)")
+	}
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
 		return nil, fmt.Errorf("torznab %s search failed: %s: %s", idx.Name, resp.Status, strings.TrimSpace(string(body)))
@@ -1158,6 +1304,18 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 		log.Printf("[indexer/torznab] sanitized %d unescaped ampersand(s) in XML response from %s", fixCount, idx.Name)
 	}
 
+	// Indexers refuse requests (bad key, quota reached) with an <error> document and HTTP 200
+	var apiErr struct {
+		XMLName xml.Name `xml:"error"`
+		newznabError
+	}
+	if xml.Unmarshal(sanitized, &apiErr) == nil {
+		if kind := apiErr.limitKind(); kind != "" {
+			s.quota().limitReached(idx.Name, kind, s.quota().limitReset(""), apiErr.Description)
+		}
+		return nil, fmt.Errorf("torznab %s search failed: error %s: %s", idx.Name, apiErr.Code, apiErr.Description)
+	}
+
 	var feed rssFeed
 	if err := xml.Unmarshal(sanitized, &feed); err != nil {
 		// Log a snippet of the problematic XML for debugging
@@ -1168,6 +1326,7 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 		log.Printf("[indexer/torznab] XML parse error from %s: %v\nXML snippet: %s", idx.Name, err, string(snippet))
 		return nil, fmt.Errorf("decode torznab feed: %w", err)
 	}
+	s.quota().observeLimits(idx.Name, feed.Channel.APILimits)
 
 	results := make([]models.NZBResult, 0, len(feed.Channel.Items))
 	for _, item := range feed.Channel.Items {
@@ -1193,6 +1352,9 @@ func (s *Service) searchTorznab(ctx context.Context, idx config.IndexerConfig, o
 			Categories:  dedupe(append([]string{}, item.Categories...)),
 			Attributes:  attrs,
 		}
+		if idSearch {
+			result.Attributes[filter.IDMatchAttribute] = params.Get("t")
+		}
 		results = append(results, result)
 	}
 
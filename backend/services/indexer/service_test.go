@@ -267,3 +267,91 @@ func TestDedupe(t *testing.T) {
 		t.Fatalf("expected second item to be Drama, got %s", got[1])
 	}
 }
+
+func TestSearchTorznab_IDSearchFromCaps(t *testing.T) {
+	var capsRequests int
+	var lastQuery map[string]string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		if r.URL.Query().Get("t") == "caps" {
+			capsRequests++
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<caps>
+  <searching>
+    <search available="yes" supportedParams="q"/>
+    <tv-search available="yes" supportedParams="q,tvdbid,season,ep"/>
+    <movie-search available="no" supportedParams="q,imdbid"/>
+  </searching>
+</caps>`))
+			return
+		}
+		lastQuery = map[string]string{}
+		for key := range r.URL.Query() {
+			lastQuery[key] = r.URL.Query().Get(key)
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><item><title>La.Casa.de.Papel.S01E02.1080p</title><guid>1</guid></item></channel></rss>`))
+	}))
+	defer mockServer.Close()
+
+	svc := &Service{httpc: &http.Client{}}
+	idx := config.IndexerConfig{Name: "IDIndexer", URL: mockServer.URL, APIKey: "testkey", Type: "newznab", Enabled: true}
+
+	results, err := svc.searchTorznab(context.Background(), idx, SearchOptions{Query: "Money Heist S01E02", MediaType: "series", TVDBID: "327417"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lastQuery["t"] != "tvsearch" || lastQuery["tvdbid"] != "327417" || lastQuery["season"] != "1" || lastQuery["ep"] != "2" || lastQuery["q"] != "" {
+		t.Errorf("expected tvsearch by tvdbid, got %v", lastQuery)
+	}
+	if len(results) != 1 || results[0].Attributes["idmatch"] != "tvsearch" {
+		t.Errorf("expected ID-matched result, got %+v", results)
+	}
+
+	// Alternate-title queries add nothing once the indexer has been searched by ID
+	results, err = svc.searchTorznab(context.Background(), idx, SearchOptions{Query: "La Casa de Papel S01E02", MediaType: "series", TVDBID: "327417", alternateQuery: true})
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected alternate query to be skipped, got %v (err=%v)", results, err)
+	}
+
+	// Movie search is unavailable on this indexer, so fall back to text
+	_, err = svc.searchTorznab(context.Background(), idx, SearchOptions{Query: "The Matrix 1999", MediaType: "movie", IMDBID: "tt0133093"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lastQuery["t"] != "search" || lastQuery["q"] != "The Matrix 1999" || lastQuery["imdbid"] != "" {
+		t.Errorf("expected text search fallback, got %v", lastQuery)
+	}
+
+	if capsRequests != 1 {
+		t.Errorf("expected caps to be fetched once and cached, got %d requests", capsRequests)
+	}
+}
+
+func TestIDSearchParams(t *testing.T) {
+	caps := indexerCaps{
+		MovieSearch: map[string]bool{"q": true, "imdbid": true},
+		TVSearch:    map[string]bool{"q": true, "imdbid": true, "season": true},
+	}
+
+	params := idSearchParams(caps, SearchOptions{Query: "The Matrix", MediaType: "movie", IMDBID: "tt0133093"})
+	if params.Get("t") != "movie" || params.Get("imdbid") != "0133093" {
+		t.Errorf("unexpected movie params %v", params)
+	}
+
+	// No tvdbid support: use the IMDB ID
+	params = idSearchParams(caps, SearchOptions{Query: "Show", MediaType: "series", IMDBID: "tt123", TVDBID: "456"})
+	if params.Get("t") != "tvsearch" || params.Get("imdbid") != "123" || params.Get("tvdbid") != "" || params.Get("season") != "" {
+		t.Errorf("unexpected tv params %v", params)
+	}
+
+	// Episode searches need ep support
+	if params := idSearchParams(caps, SearchOptions{Query: "Show S02E03", MediaType: "series", IMDBID: "tt123"}); params != nil {
+		t.Errorf("expected text search without ep support, got %v", params)
+	}
+
+	if params := idSearchParams(caps, SearchOptions{Query: "The Matrix", MediaType: "movie"}); params != nil {
+		t.Errorf("expected text search without IDs, got %v", params)
+	}
+}
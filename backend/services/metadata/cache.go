@@ -7,9 +7,33 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
+
+	"novastream/internal/metrics"
 )
 
+// Cache lookups are counted for the hit ratio reported on /metrics.
+var cacheHits, cacheMisses atomic.Int64
+
+func init() {
+	metrics.NewCounterFunc("novastream_metadata_cache_lookups_total", "Metadata cache lookups by result (hit or miss).",
+		[]string{"result"}, func() []metrics.Sample {
+			return []metrics.Sample{
+				{LabelValues: []string{"hit"}, Value: float64(cacheHits.Load())},
+				{LabelValues: []string{"miss"}, Value: float64(cacheMisses.Load())},
+			}
+		})
+	metrics.NewGaugeFunc("novastream_metadata_cache_hit_ratio", "Share of metadata cache lookups served from the cache since startup.",
+		nil, func() []metrics.Sample {
+			hits, misses := cacheHits.Load(), cacheMisses.Load()
+			if hits+misses == 0 {
+				return []metrics.Sample{{Value: 0}}
+			}
+			return []metrics.Sample{{Value: float64(hits) / float64(hits+misses)}}
+		})
+}
+
 type fileCache struct {
 	dir string
 	ttl time.Duration
@@ -30,6 +54,16 @@ func (c *fileCache) jitteredTTL(key string) time.Duration {
 }
 
 func (c *fileCache) get(key string, v any) (bool, error) {
+	ok, err := c.lookup(key, v)
+	if ok {
+		cacheHits.Add(1)
+	} else {
+		cacheMisses.Add(1)
+	}
+	return ok, err
+}
+
+func (c *fileCache) lookup(key string, v any) (bool, error) {
 	if key == "" {
 		return false, errors.New("empty key")
 	}
@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"novastream/models"
+)
+
+// certificationWorkers bounds concurrent TMDB lookups when filtering lists
+const certificationWorkers = 8
+
+// Certifications returns a title's age ratings keyed by country (ISO 3166-1), from TMDB
+// release certifications for movies and content ratings for series.
+func (s *Service) Certifications(ctx context.Context, title models.Title) (map[string]string, error) {
+	mediaType := models.RatingMediaType(title.MediaType)
+	tmdbID := s.resolveCertificationTMDBID(ctx, title, mediaType)
+	if tmdbID <= 0 {
+		return nil, fmt.Errorf("no tmdb id for %s %q", mediaType, title.Name)
+	}
+
+	var cached map[string]string
+	if ok, _ := s.cache.get(certificationsCacheKey(mediaType, tmdbID), &cached); ok {
+		return cached, nil
+	}
+
+	var (
+		certs map[string]string
+		err   error
+	)
+	if mediaType == "series" {
+		certs, err = s.tmdb.tvContentRatings(ctx, tmdbID)
+	} else {
+		certs, err = s.tmdb.movieCertifications(ctx, tmdbID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheCertifications(mediaType, tmdbID, certs)
+	return certs, nil
+}
+
+func certificationsCacheKey(mediaType string, tmdbID int64) string {
+	return cacheKey("certifications", mediaType, strconv.FormatInt(tmdbID, 10))
+}
+
+// cacheCertifications stores a title's certifications, also when they were fetched as
+// part of another lookup, so list filtering doesn't request them again.
+func (s *Service) cacheCertifications(mediaType string, tmdbID int64, certs map[string]string) {
+	if err := s.cache.set(certificationsCacheKey(mediaType, tmdbID), certs); err != nil {
+		log.Printf("[metadata] failed to cache certifications for %s/%d: %v", mediaType, tmdbID, err)
+	}
+}
+
+// CheckContentRestriction reports whether a title passes a kids profile restriction and the
+// certification it was judged on. Titles whose rating cannot be determined are refused.
+func (s *Service) CheckContentRestriction(ctx context.Context, title models.Title, restriction models.ContentRestriction) (bool, string) {
+	certs, err := s.Certifications(ctx, title)
+	if err != nil {
+		log.Printf("[metadata] certification lookup failed for %q: %v", title.Name, err)
+		return false, ""
+	}
+	return restriction.Allows(models.RatingMediaType(title.MediaType), certs)
+}
+
+// FilterTrendingByRestriction drops trending or list items above a kids profile restriction,
+// keeping the original order.
+func (s *Service) FilterTrendingByRestriction(ctx context.Context, items []models.TrendingItem, restriction models.ContentRestriction) []models.TrendingItem {
+	allowed := s.allowedTitles(ctx, len(items), func(i int) models.Title { return items[i].Title }, restriction)
+	result := make([]models.TrendingItem, 0, len(items))
+	for i, item := range items {
+		if allowed[i] {
+			result = append(result, item)
+		}
+	}
+	log.Printf("[metadata] content restriction %s kept %d/%d items", describeRestriction(restriction), len(result), len(items))
+	return result
+}
+
+// FilterSearchByRestriction drops search results above a kids profile restriction, keeping
+// the original order.
+func (s *Service) FilterSearchByRestriction(ctx context.Context, results []models.SearchResult, restriction models.ContentRestriction) []models.SearchResult {
+	allowed := s.allowedTitles(ctx, len(results), func(i int) models.Title { return results[i].Title }, restriction)
+	filtered := make([]models.SearchResult, 0, len(results))
+	for i, result := range results {
+		if allowed[i] {
+			filtered = append(filtered, result)
+		}
+	}
+	log.Printf("[metadata] content restriction %s kept %d/%d search results", describeRestriction(restriction), len(filtered), len(results))
+	return filtered
+}
+
+func (s *Service) allowedTitles(ctx context.Context, n int, titleAt func(int) models.Title, restriction models.ContentRestriction) []bool {
+	allowed := make([]bool, n)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < certificationWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				allowed[i], _ = s.CheckContentRestriction(ctx, titleAt(i), restriction)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return allowed
+}
+
+// resolveCertificationTMDBID finds the TMDB ID of a title from its own fields or its
+// IMDB/TVDB IDs. Lookups are cached with the other stable ID mappings.
+func (s *Service) resolveCertificationTMDBID(ctx context.Context, title models.Title, mediaType string) int64 {
+	if title.TMDBID > 0 {
+		return title.TMDBID
+	}
+	if rest, ok := strings.CutPrefix(title.ID, "tmdb:"); ok {
+		if idx := strings.LastIndex(rest, ":"); idx >= 0 {
+			if id, err := strconv.ParseInt(rest[idx+1:], 10, 64); err == nil && id > 0 {
+				return id
+			}
+		}
+	}
+
+	var source, externalID string
+	switch {
+	case strings.TrimSpace(title.IMDBID) != "":
+		source, externalID = "imdb_id", strings.TrimSpace(title.IMDBID)
+	case title.TVDBID > 0:
+		source, externalID = "tvdb_id", strconv.FormatInt(title.TVDBID, 10)
+	default:
+		return 0
+	}
+
+	cacheID := cacheKey("id", source+"-to-tmdb", mediaType, externalID)
+	var cached int64
+	if ok, _ := s.idCache.get(cacheID, &cached); ok {
+		return cached
+	}
+
+	tmdbID, err := s.tmdb.findByExternalID(ctx, source, externalID, mediaType)
+	if err != nil {
+		log.Printf("[metadata] failed to resolve TMDB ID for %s %s: %v", source, externalID, err)
+		return 0
+	}
+	if err := s.idCache.set(cacheID, tmdbID); err != nil {
+		log.Printf("[metadata] failed to cache TMDB ID mapping: %v", err)
+	}
+	return tmdbID
+}
+
+func describeRestriction(r models.ContentRestriction) string {
+	return fmt.Sprintf("%s %s/%s", r.Country, r.MaxMovieRating, r.MaxTVRating)
+}
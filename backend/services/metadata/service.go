@@ -2615,11 +2615,14 @@ func (s *Service) enrichMovieReleases(ctx context.Context, title *models.Title,
 		return true
 	}
 
-	releases, err := s.tmdb.movieReleaseDates(ctx, tmdbID)
-	if err != nil || len(releases) == 0 {
-		if err != nil {
-			log.Printf("[metadata] WARN: tmdb release dates fetch failed tmdbId=%d err=%v", tmdbID, err)
-		}
+	releases, certs, err := s.tmdb.movieReleaseDates(ctx, tmdbID)
+	if err != nil {
+		log.Printf("[metadata] WARN: tmdb release dates fetch failed tmdbId=%d err=%v", tmdbID, err)
+		return false
+	}
+	// The same response carries the certifications kids profile filtering needs
+	s.cacheCertifications("movie", tmdbID, certs)
+	if len(releases) == 0 {
 		return false
 	}
 
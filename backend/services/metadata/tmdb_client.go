@@ -156,6 +156,13 @@ type tmdbReleaseDatesResponse struct {
 	Results []tmdbReleaseCountry `json:"results"`
 }
 
+type tmdbContentRatingsResponse struct {
+	Results []struct {
+		ISO31661 string `json:"iso_3166_1"`
+		Rating   string `json:"rating"`
+	} `json:"results"`
+}
+
 type tmdbCreditsResponse struct {
 	Cast []struct {
 		ID          int64  `json:"id"`
@@ -746,19 +753,21 @@ func (c *tmdbClient) fetchTVCredits(ctx context.Context, tmdbID int64) (*models.
 	return &models.Credits{Cast: cast}, nil
 }
 
-func (c *tmdbClient) movieReleaseDates(ctx context.Context, tmdbID int64) ([]models.Release, error) {
+// movieReleaseDates returns a movie's releases and, from the same response, its
+// certification per country (ISO 3166-1)
+func (c *tmdbClient) movieReleaseDates(ctx context.Context, tmdbID int64) ([]models.Release, map[string]string, error) {
 	if !c.isConfigured() {
-		return nil, errors.New("tmdb api key not configured")
+		return nil, nil, errors.New("tmdb api key not configured")
 	}
 
 	endpoint, err := url.JoinPath(tmdbBaseURL, "movie", fmt.Sprintf("%d", tmdbID), "release_dates")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	q := req.URL.Query()
@@ -767,17 +776,17 @@ func (c *tmdbClient) movieReleaseDates(ctx context.Context, tmdbID int64) ([]mod
 
 	resp, err := c.httpc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("tmdb movie release dates failed: %s", resp.Status)
+		return nil, nil, fmt.Errorf("tmdb movie release dates failed: %s", resp.Status)
 	}
 
 	var payload tmdbReleaseDatesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	now := time.Now()
@@ -813,7 +822,111 @@ func (c *tmdbClient) movieReleaseDates(ctx context.Context, tmdbID int64) ([]mod
 		}
 	}
 
-	return releases, nil
+	return releases, certificationsFromReleaseDates(payload), nil
+}
+
+// movieCertifications returns a movie's certification per country (ISO 3166-1)
+func (c *tmdbClient) movieCertifications(ctx context.Context, tmdbID int64) (map[string]string, error) {
+	if !c.isConfigured() {
+		return nil, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := url.JoinPath(tmdbBaseURL, "movie", fmt.Sprintf("%d", tmdbID), "release_dates")
+	if err != nil {
+		return nil, err
+	}
+	endpoint = endpoint + "?api_key=" + c.apiKey
+
+	var payload tmdbReleaseDatesResponse
+	if err := c.doGET(ctx, endpoint, &payload); err != nil {
+		return nil, fmt.Errorf("tmdb release dates for movie/%d failed: %w", tmdbID, err)
+	}
+
+	return certificationsFromReleaseDates(payload), nil
+}
+
+// certificationsFromReleaseDates picks one certification per country, preferring the
+// theatrical release since later releases are often unrated or extended cuts
+func certificationsFromReleaseDates(payload tmdbReleaseDatesResponse) map[string]string {
+	certs := make(map[string]string, len(payload.Results))
+	for _, country := range payload.Results {
+		code := strings.ToUpper(strings.TrimSpace(country.ISO31661))
+		if code == "" {
+			continue
+		}
+		for _, entry := range country.ReleaseDates {
+			cert := strings.TrimSpace(entry.Certification)
+			if cert == "" {
+				continue
+			}
+			if _, ok := certs[code]; !ok || entry.Type == 3 {
+				certs[code] = cert
+			}
+		}
+	}
+	return certs
+}
+
+// tvContentRatings returns a series' content rating per country (ISO 3166-1)
+func (c *tmdbClient) tvContentRatings(ctx context.Context, tmdbID int64) (map[string]string, error) {
+	if !c.isConfigured() {
+		return nil, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := url.JoinPath(tmdbBaseURL, "tv", fmt.Sprintf("%d", tmdbID), "content_ratings")
+	if err != nil {
+		return nil, err
+	}
+	endpoint = endpoint + "?api_key=" + c.apiKey
+
+	var payload tmdbContentRatingsResponse
+	if err := c.doGET(ctx, endpoint, &payload); err != nil {
+		return nil, fmt.Errorf("tmdb content ratings for tv/%d failed: %w", tmdbID, err)
+	}
+
+	ratings := make(map[string]string, len(payload.Results))
+	for _, r := range payload.Results {
+		code := strings.ToUpper(strings.TrimSpace(r.ISO31661))
+		rating := strings.TrimSpace(r.Rating)
+		if code != "" && rating != "" {
+			ratings[code] = rating
+		}
+	}
+	return ratings, nil
+}
+
+// findByExternalID looks up a TMDB ID from an IMDB ("imdb_id") or TVDB ("tvdb_id") ID
+func (c *tmdbClient) findByExternalID(ctx context.Context, source, externalID, mediaType string) (int64, error) {
+	if !c.isConfigured() {
+		return 0, errors.New("tmdb api key not configured")
+	}
+
+	endpoint, err := url.JoinPath(tmdbBaseURL, "find", externalID)
+	if err != nil {
+		return 0, err
+	}
+	endpoint = endpoint + "?api_key=" + c.apiKey + "&external_source=" + source
+
+	var result struct {
+		MovieResults []struct {
+			ID int64 `json:"id"`
+		} `json:"movie_results"`
+		TVResults []struct {
+			ID int64 `json:"id"`
+		} `json:"tv_results"`
+	}
+	if err := c.doGET(ctx, endpoint, &result); err != nil {
+		return 0, fmt.Errorf("tmdb find %s failed: %w", externalID, err)
+	}
+
+	if mediaType == "series" {
+		if len(result.TVResults) > 0 {
+			return result.TVResults[0].ID, nil
+		}
+	} else if len(result.MovieResults) > 0 {
+		return result.MovieResults[0].ID, nil
+	}
+	return 0, fmt.Errorf("no %s found for %s %s", mediaType, source, externalID)
 }
 
 func (c *tmdbClient) fetchExternalID(ctx context.Context, mediaType string, tmdbID int64) (string, error) {
@@ -45,3 +45,29 @@ func TestParseTMDBYear(t *testing.T) {
 		t.Fatalf("expected 0 for invalid date, got %d", year)
 	}
 }
+
+func TestCertificationsFromReleaseDatesPrefersTheatrical(t *testing.T) {
+	payload := tmdbReleaseDatesResponse{
+		Results: []tmdbReleaseCountry{
+			{ISO31661: "us", ReleaseDates: []tmdbReleaseEntry{
+				{Certification: "", Type: 1},
+				{Certification: "NC-17", Type: 4},
+				{Certification: "R", Type: 3},
+				{Certification: "NR", Type: 5},
+			}},
+			{ISO31661: "GB", ReleaseDates: []tmdbReleaseEntry{{Certification: "15", Type: 5}}},
+			{ISO31661: "DE", ReleaseDates: []tmdbReleaseEntry{{Certification: "", Type: 3}}},
+		},
+	}
+
+	certs := certificationsFromReleaseDates(payload)
+	if certs["US"] != "R" {
+		t.Fatalf("expected theatrical US certification R, got %q", certs["US"])
+	}
+	if certs["GB"] != "15" {
+		t.Fatalf("expected GB certification 15, got %q", certs["GB"])
+	}
+	if _, ok := certs["DE"]; ok {
+		t.Fatalf("expected no DE certification, got %q", certs["DE"])
+	}
+}
@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"novastream/internal/events"
+	"novastream/services/debrid"
+)
+
+const (
+	// expiryWarningDays is how close to expiry a debrid subscription has to be before
+	// it is reported
+	expiryWarningDays  = 7
+	expiryCheckDelay   = 2 * time.Minute
+	expiryCheckEvery   = 12 * time.Hour
+	expiryCheckTimeout = 30 * time.Second
+)
+
+// StartDebridExpiryWatcher periodically checks the configured debrid accounts and publishes
+// an event when a subscription is about to lapse or has lapsed. Each account is reported
+// at most once a day.
+func (s *Service) StartDebridExpiryWatcher() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		reported := make(map[string]string) // provider name -> day last reported
+		timer := time.NewTimer(expiryCheckDelay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-timer.C:
+				s.checkDebridExpiry(reported)
+				timer.Reset(expiryCheckEvery)
+			}
+		}
+	}()
+}
+
+func (s *Service) checkDebridExpiry(reported map[string]string) {
+	settings, err := s.cfgManager.Load()
+	if err != nil {
+		log.Printf("[notifications] failed to load settings: %v", err)
+		return
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	for _, p := range settings.Streaming.DebridProviders {
+		if !p.Enabled || strings.TrimSpace(p.APIKey) == "" {
+			continue
+		}
+		name := p.Name
+		if name == "" {
+			name = p.Provider
+		}
+		if reported[name] == today {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(s.ctx, expiryCheckTimeout)
+		info, err := debrid.FetchAccountInfo(ctx, p.Provider, p.APIKey)
+		cancel()
+		if err != nil {
+			log.Printf("[notifications] failed to check %s account: %v", name, err)
+			continue
+		}
+
+		if event, ok := expiryEvent(name, p.Provider, info); ok {
+			events.Publish(event)
+			reported[name] = today
+		}
+	}
+}
+
+// expiryEvent builds the event for an account that has expired or expires within
+// expiryWarningDays.
+func expiryEvent(name, provider string, info *debrid.AccountInfo) (events.Event, bool) {
+	if info == nil || info.IsLifetime {
+		return events.Event{}, false
+	}
+
+	fields := map[string]string{
+		"provider": provider,
+		"username": info.Username,
+	}
+	if info.ExpiresAt != nil {
+		fields["expires"] = info.ExpiresAt.UTC().Format("2006-01-02")
+	}
+
+	if !info.PremiumActive {
+		return events.Event{
+			Type:    events.DebridAccountExpiring,
+			Title:   "Debrid subscription expired",
+			Message: fmt.Sprintf("The %s premium subscription is no longer active.", name),
+			Fields:  fields,
+		}, true
+	}
+	if info.ExpiresAt == nil || info.DaysRemaining > expiryWarningDays {
+		return events.Event{}, false
+	}
+
+	fields["daysRemaining"] = strconv.Itoa(info.DaysRemaining)
+	return events.Event{
+		Type:    events.DebridAccountExpiring,
+		Title:   "Debrid subscription expiring",
+		Message: fmt.Sprintf("The %s premium subscription expires in %d day(s).", name, info.DaysRemaining),
+		Fields:  fields,
+	}, true
+}
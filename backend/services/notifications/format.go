@@ -0,0 +1,208 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/events"
+)
+
+const senderName = "NovaStream"
+
+// templateError marks a webhook whose custom template cannot produce a body. These are
+// configuration mistakes and are not retried.
+type templateError struct {
+	err error
+}
+
+func (e *templateError) Error() string { return "webhook template: " + e.err.Error() }
+func (e *templateError) Unwrap() error { return e.err }
+
+var templateFuncs = template.FuncMap{
+	// json renders a value as a JSON literal, e.g. {"text": {{json .Message}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// buildRequest renders an event as an HTTP request for the webhook's format. A custom
+// template replaces the default body; for JSON-based formats it must render valid JSON.
+func buildRequest(ctx context.Context, hook config.WebhookSettings, e events.Event) (*http.Request, error) {
+	format := strings.ToLower(strings.TrimSpace(hook.Format))
+	if format == "" {
+		format = config.WebhookFormatJSON
+	}
+
+	var (
+		body        []byte
+		contentType = "application/json"
+		err         error
+	)
+	if strings.TrimSpace(hook.Template) != "" {
+		body, err = renderTemplate(hook.Template, e)
+		if err != nil {
+			return nil, &templateError{err: err}
+		}
+		if format == config.WebhookFormatNtfy {
+			contentType = "text/plain; charset=utf-8"
+		} else if !json.Valid(body) {
+			return nil, &templateError{err: fmt.Errorf("rendered body is not valid JSON")}
+		}
+	} else {
+		switch format {
+		case config.WebhookFormatJSON:
+			body, err = json.Marshal(e)
+		case config.WebhookFormatDiscord:
+			body, err = json.Marshal(discordPayload(e))
+		case config.WebhookFormatNtfy:
+			body, contentType = []byte(plainMessage(e)), "text/plain; charset=utf-8"
+		case config.WebhookFormatGotify:
+			body, err = json.Marshal(map[string]interface{}{
+				"title":    e.Title,
+				"message":  plainMessage(e),
+				"priority": gotifyPriority(e.Type),
+				"extras": map[string]interface{}{
+					"client::display": map[string]string{"contentType": "text/plain"},
+				},
+			})
+		default:
+			return nil, &templateError{err: fmt.Errorf("unsupported format %q", hook.Format)}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode payload: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(hook.URL), bytes.NewReader(body))
+	if err != nil {
+		return nil, &templateError{err: fmt.Errorf("invalid url: %w", err)}
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", senderName)
+
+	token := strings.TrimSpace(hook.Token)
+	switch format {
+	case config.WebhookFormatNtfy:
+		req.Header.Set("Title", e.Title)
+		req.Header.Set("Tags", ntfyTag(e.Type))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case config.WebhookFormatGotify:
+		if token != "" {
+			req.Header.Set("X-Gotify-Key", token)
+		}
+	case config.WebhookFormatDiscord:
+		// Discord authenticates with the token embedded in the webhook URL
+	default:
+		req.Header.Set("X-NovaStream-Event", string(e.Type))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return req, nil
+}
+
+func renderTemplate(text string, e events.Event) ([]byte, error) {
+	tpl, err := template.New("webhook").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// plainMessage is the event message followed by its fields, one per line.
+func plainMessage(e events.Event) string {
+	var sb strings.Builder
+	sb.WriteString(e.Message)
+	for _, key := range sortedKeys(e.Fields) {
+		fmt.Fprintf(&sb, "\n%s: %s", key, e.Fields[key])
+	}
+	return sb.String()
+}
+
+func discordPayload(e events.Event) map[string]interface{} {
+	fields := make([]map[string]interface{}, 0, len(e.Fields))
+	for _, key := range sortedKeys(e.Fields) {
+		fields = append(fields, map[string]interface{}{"name": key, "value": e.Fields[key], "inline": true})
+	}
+	return map[string]interface{}{
+		"username": senderName,
+		"embeds": []map[string]interface{}{{
+			"title":       e.Title,
+			"description": e.Message,
+			"color":       discordColor(e.Type),
+			"timestamp":   e.Time.UTC().Format(time.RFC3339),
+			"fields":      fields,
+		}},
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key, value := range m {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isAlert reports whether an event signals a problem rather than activity.
+func isAlert(t events.Type) bool {
+	switch t {
+	case events.ImportFailed, events.FileCorrupted, events.TaskFailed, events.LoginFailed, events.DebridAccountExpiring:
+		return true
+	default:
+		return false
+	}
+}
+
+func discordColor(t events.Type) int {
+	if isAlert(t) {
+		return 0xE74C3C
+	}
+	return 0x3498DB
+}
+
+func gotifyPriority(t events.Type) int {
+	if isAlert(t) {
+		return 8
+	}
+	return 4
+}
+
+func ntfyTag(t events.Type) string {
+	switch t {
+	case events.PlaybackStarted:
+		return "arrow_forward"
+	case events.PlaybackStopped:
+		return "stop_button"
+	case events.ProfileCreated:
+		return "bust_in_silhouette"
+	case events.Test:
+		return "white_check_mark"
+	default:
+		if isAlert(t) {
+			return "warning"
+		}
+		return "bell"
+	}
+}
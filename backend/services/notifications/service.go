@@ -0,0 +1,552 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"novastream/config"
+	"novastream/internal/events"
+)
+
+var ErrStorageDirRequired = errors.New("storage directory not provided")
+
+// Delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryRetrying  = "retrying"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	// maxLogEntries bounds the persisted delivery log
+	maxLogEntries = 200
+	// queueSize is how many deliveries may wait for a worker before new events are dropped
+	queueSize = 256
+	workers   = 2
+	// repeatCooldown suppresses the same event (type and key) firing repeatedly, e.g. a
+	// corrupted file that is read again or a client retrying a bad password
+	repeatCooldown = 10 * time.Minute
+	requestTimeout = 15 * time.Second
+	maxErrorLen    = 300
+)
+
+// defaultBackoff is the wait before each retry; its length is the number of retries.
+var defaultBackoff = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// Delivery is one attempt to send an event to a webhook, as shown in the admin UI.
+type Delivery struct {
+	ID          string      `json:"id"`
+	Webhook     string      `json:"webhook"`
+	Format      string      `json:"format"`
+	Event       events.Type `json:"event"`
+	Title       string      `json:"title"`
+	Status      string      `json:"status"`
+	Attempts    int         `json:"attempts"`
+	StatusCode  int         `json:"statusCode,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	NextRetryAt *time.Time  `json:"nextRetryAt,omitempty"`
+}
+
+type job struct {
+	deliveryID string
+	webhook    config.WebhookSettings
+	event      events.Event
+	attempts   int // attempts already made
+}
+
+// Service sends server events to the webhooks configured in settings, retrying failed
+// deliveries with backoff and keeping a log of recent deliveries.
+type Service struct {
+	mu         sync.Mutex
+	path       string
+	deliveries []Delivery // oldest first
+	lastSent   map[string]time.Time
+
+	cfgManager *config.Manager
+	client     *http.Client
+	incoming   chan events.Event
+	queue      chan job
+	backoff    []time.Duration
+	retries    map[string]*time.Timer // deliveries waiting to be queued again, by delivery ID
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	unsubscribe func()
+}
+
+// NewService constructs the notifier, persisting its delivery log in storageDir.
+func NewService(storageDir string, cfgManager *config.Manager) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create notifications dir: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := &Service{
+		path:       filepath.Join(storageDir, "webhook_deliveries.json"),
+		lastSent:   make(map[string]time.Time),
+		cfgManager: cfgManager,
+		client:     &http.Client{Timeout: requestTimeout},
+		incoming:   make(chan events.Event, queueSize),
+		queue:      make(chan job, queueSize),
+		backoff:    defaultBackoff,
+		retries:    make(map[string]*time.Timer),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	if err := svc.load(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Start subscribes to server events and starts the delivery workers.
+func (s *Service) Start() {
+	s.wg.Add(1)
+	go s.dispatch()
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+	s.unsubscribe = events.Subscribe(s.receive)
+}
+
+// Shutdown stops accepting events and waits for in-flight deliveries. Deliveries that are
+// waiting to retry are marked failed.
+func (s *Service) Shutdown(ctx context.Context) error {
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	s.cancel()
+
+	s.mu.Lock()
+	waiting := make([]string, 0, len(s.retries))
+	for id, timer := range s.retries {
+		if timer.Stop() {
+			waiting = append(waiting, id)
+		}
+		delete(s.retries, id)
+	}
+	s.mu.Unlock()
+	for _, id := range waiting {
+		s.failRetry(id)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Deliveries returns the delivery log, newest first.
+func (s *Service) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Delivery, len(s.deliveries))
+	for i, d := range s.deliveries {
+		result[len(s.deliveries)-1-i] = d
+	}
+	return result
+}
+
+// ClearDeliveries empties the delivery log.
+func (s *Service) ClearDeliveries() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = nil
+	return s.saveLocked()
+}
+
+// Test sends a test event to hook once, without retries, and records the result in the
+// delivery log. The webhook does not need to be saved or enabled.
+func (s *Service) Test(ctx context.Context, hook config.WebhookSettings) (Delivery, error) {
+	if strings.TrimSpace(hook.URL) == "" {
+		return Delivery{}, errors.New("webhook url is required")
+	}
+
+	event := events.Event{
+		Type:    events.Test,
+		Title:   "Test notification",
+		Message: fmt.Sprintf("Webhook %q is configured correctly.", hook.Name),
+		Time:    time.Now().UTC(),
+	}
+	d := s.record(hook, event)
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	statusCode, err := s.send(ctx, hook, event)
+	s.updateDelivery(d.ID, func(d *Delivery) {
+		d.Attempts = 1
+		d.StatusCode = statusCode
+		d.Status = DeliveryDelivered
+		if err != nil {
+			d.Status = DeliveryFailed
+			d.Error = truncate(err.Error(), maxErrorLen)
+		}
+	})
+	d, _ = s.delivery(d.ID)
+	return d, err
+}
+
+// receive is the bus subscriber. Publishers may hold locks, so it only hands the event
+// to the dispatcher.
+func (s *Service) receive(e events.Event) {
+	select {
+	case s.incoming <- e:
+	default:
+		log.Printf("[notifications] event queue full, dropping %s", e.Type)
+	}
+}
+
+func (s *Service) dispatch() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case e := <-s.incoming:
+			s.handleEvent(e)
+		}
+	}
+}
+
+// handleEvent fans an event out to every enabled webhook subscribed to its type.
+func (s *Service) handleEvent(e events.Event) {
+	if e.Key != "" {
+		key := string(e.Type) + "|" + e.Key
+		s.mu.Lock()
+		last, seen := s.lastSent[key]
+		if seen && e.Time.Sub(last) < repeatCooldown {
+			s.mu.Unlock()
+			return
+		}
+		s.lastSent[key] = e.Time
+		s.pruneLastSentLocked(e.Time)
+		s.mu.Unlock()
+	}
+
+	settings, err := s.cfgManager.Load()
+	if err != nil {
+		log.Printf("[notifications] failed to load settings: %v", err)
+		return
+	}
+
+	for _, hook := range settings.Webhooks {
+		if !hook.Enabled || strings.TrimSpace(hook.URL) == "" || !wantsEvent(hook, e.Type) {
+			continue
+		}
+		if _, ok := s.enqueue(hook, e); !ok {
+			log.Printf("[notifications] delivery queue full, dropping %s for webhook %q", e.Type, hook.Name)
+		}
+	}
+}
+
+func wantsEvent(hook config.WebhookSettings, t events.Type) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, want := range hook.Events {
+		if events.Type(want) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneLastSentLocked drops cooldown entries that can no longer suppress anything.
+func (s *Service) pruneLastSentLocked(now time.Time) {
+	if len(s.lastSent) < 1024 {
+		return
+	}
+	for key, at := range s.lastSent {
+		if now.Sub(at) >= repeatCooldown {
+			delete(s.lastSent, key)
+		}
+	}
+}
+
+func (s *Service) enqueue(hook config.WebhookSettings, e events.Event) (Delivery, bool) {
+	d := s.record(hook, e)
+
+	select {
+	case s.queue <- job{deliveryID: d.ID, webhook: hook, event: e}:
+		return d, true
+	default:
+		s.updateDelivery(d.ID, func(d *Delivery) {
+			d.Status = DeliveryFailed
+			d.Error = "delivery queue full"
+		})
+		return d, false
+	}
+}
+
+// record adds a pending delivery to the log.
+func (s *Service) record(hook config.WebhookSettings, e events.Event) Delivery {
+	now := time.Now().UTC()
+	d := Delivery{
+		ID:        uuid.NewString(),
+		Webhook:   hook.Name,
+		Format:    hook.Format,
+		Event:     e.Type,
+		Title:     e.Title,
+		Status:    DeliveryPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, d)
+	if over := len(s.deliveries) - maxLogEntries; over > 0 {
+		s.deliveries = append([]Delivery(nil), s.deliveries[over:]...)
+	}
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[notifications] failed to persist delivery log: %v", err)
+	}
+	s.mu.Unlock()
+
+	return d
+}
+
+func (s *Service) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case j := <-s.queue:
+			s.deliver(j)
+		}
+	}
+}
+
+// deliver makes one attempt at a job. Transient failures are queued again after their
+// backoff by a timer, so a worker is never held while a delivery waits to retry.
+func (s *Service) deliver(j job) {
+	j.attempts++
+	attempt := j.attempts
+
+	statusCode, err := s.send(s.ctx, j.webhook, j.event)
+	if err == nil {
+		s.updateDelivery(j.deliveryID, func(d *Delivery) {
+			d.Status = DeliveryDelivered
+			d.Attempts = attempt
+			d.StatusCode = statusCode
+			d.Error = ""
+			d.NextRetryAt = nil
+		})
+		return
+	}
+
+	retry := attempt <= len(s.backoff) && retryable(statusCode, err)
+	s.updateDelivery(j.deliveryID, func(d *Delivery) {
+		d.Attempts = attempt
+		d.StatusCode = statusCode
+		d.Error = truncate(err.Error(), maxErrorLen)
+		d.NextRetryAt = nil
+		d.Status = DeliveryFailed
+		if retry {
+			next := time.Now().UTC().Add(s.backoff[attempt-1])
+			d.Status = DeliveryRetrying
+			d.NextRetryAt = &next
+		}
+	})
+	if !retry {
+		log.Printf("[notifications] delivery of %s to %q failed after %d attempt(s): %v", j.event.Type, j.webhook.Name, attempt, err)
+		return
+	}
+
+	s.scheduleRetry(j, s.backoff[attempt-1])
+}
+
+// scheduleRetry queues a job again once wait has passed.
+func (s *Service) scheduleRetry(j job, wait time.Duration) {
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		s.failRetry(j.deliveryID)
+		return
+	}
+	defer s.mu.Unlock()
+	s.retries[j.deliveryID] = time.AfterFunc(wait, func() {
+		s.mu.Lock()
+		delete(s.retries, j.deliveryID)
+		s.mu.Unlock()
+
+		if s.ctx.Err() != nil {
+			s.failRetry(j.deliveryID)
+			return
+		}
+		select {
+		case s.queue <- j:
+		case <-s.ctx.Done():
+			s.failRetry(j.deliveryID)
+		}
+	})
+}
+
+// failRetry marks a delivery that was waiting to retry as failed on shutdown.
+func (s *Service) failRetry(deliveryID string) {
+	s.updateDelivery(deliveryID, func(d *Delivery) {
+		d.Status = DeliveryFailed
+		d.NextRetryAt = nil
+		d.Error = truncate("server shut down before retry: "+d.Error, maxErrorLen)
+	})
+}
+
+// send performs a single delivery attempt and returns the HTTP status code, if any.
+func (s *Service) send(ctx context.Context, hook config.WebhookSettings, e events.Event) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := buildRequest(ctx, hook, e)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a failed attempt may succeed later. Configuration errors
+// (bad template, rejected payload) are not retried.
+func retryable(statusCode int, err error) bool {
+	var tplErr *templateError
+	if errors.As(err, &tplErr) {
+		return false
+	}
+	switch {
+	case statusCode == 0:
+		return true
+	case statusCode == http.StatusTooManyRequests, statusCode == http.StatusRequestTimeout:
+		return true
+	case statusCode >= 500:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Service) delivery(id string) (Delivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Delivery{}, false
+}
+
+func (s *Service) updateDelivery(id string, update func(*Delivery)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == id {
+			update(&s.deliveries[i])
+			s.deliveries[i].UpdatedAt = time.Now().UTC()
+			break
+		}
+	}
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[notifications] failed to persist delivery log: %v", err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read delivery log: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &s.deliveries); err != nil {
+		return fmt.Errorf("decode delivery log: %w", err)
+	}
+
+	// Retries do not survive a restart
+	for i := range s.deliveries {
+		if s.deliveries[i].Status == DeliveryPending || s.deliveries[i].Status == DeliveryRetrying {
+			s.deliveries[i].Status = DeliveryFailed
+			s.deliveries[i].NextRetryAt = nil
+			if s.deliveries[i].Error == "" {
+				s.deliveries[i].Error = "interrupted by server restart"
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) saveLocked() error {
+	data, err := json.MarshalIndent(s.deliveries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode delivery log: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write delivery log: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("commit delivery log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/events"
+	"novastream/services/debrid"
+)
+
+func newTestService(t *testing.T, hooks ...config.WebhookSettings) *Service {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := config.DefaultSettings()
+	cfg.Webhooks = hooks
+
+	mgr := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("save cfg: %v", err)
+	}
+
+	svc, err := NewService(dir, mgr)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	svc.backoff = []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}
+	return svc
+}
+
+func waitForStatus(t *testing.T, svc *Service, want string) Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if list := svc.Deliveries(); len(list) > 0 && list[0].Status == want {
+			return list[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("delivery never reached %q: %+v", want, svc.Deliveries())
+	return Delivery{}
+}
+
+func TestDeliveryRetriesWithBackoff(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	var received events.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		mu.Lock()
+		json.NewDecoder(r.Body).Decode(&received)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, config.WebhookSettings{
+		Name: "hook", URL: server.URL, Format: config.WebhookFormatJSON, Token: "secret", Enabled: true,
+	})
+	svc.Start()
+	defer svc.Shutdown(context.Background())
+
+	events.Publish(events.Event{Type: events.ImportFailed, Title: "Import failed", Message: "broken.nzb", Key: "broken.nzb"})
+
+	d := waitForStatus(t, svc, DeliveryDelivered)
+	if d.Attempts != 3 || d.Event != events.ImportFailed || d.Webhook != "hook" {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received.Type != events.ImportFailed || received.Message != "broken.nzb" {
+		t.Fatalf("unexpected payload %+v", received)
+	}
+}
+
+func TestDeliveryGivesUpOnClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, config.WebhookSettings{Name: "hook", URL: server.URL, Enabled: true})
+	svc.Start()
+	defer svc.Shutdown(context.Background())
+
+	events.Publish(events.Event{Type: events.TaskFailed, Title: "Task failed"})
+
+	d := waitForStatus(t, svc, DeliveryFailed)
+	if d.Attempts != 1 || d.StatusCode != http.StatusBadRequest || !strings.Contains(d.Error, "bad payload") {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestRetryWaitDoesNotHoldWorkers(t *testing.T) {
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer flaky.Close()
+	var delivered int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer ok.Close()
+
+	svc := newTestService(t,
+		config.WebhookSettings{Name: "flaky", URL: flaky.URL, Enabled: true},
+		config.WebhookSettings{Name: "ok", URL: ok.URL, Enabled: true},
+	)
+	svc.backoff = []time.Duration{time.Hour}
+	svc.Start()
+
+	// More failing deliveries than workers, each waiting an hour to retry
+	for i := 0; i < workers+1; i++ {
+		svc.handleEvent(events.Event{Type: events.TaskFailed, Title: "Task failed", Time: time.Now()})
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&delivered) < workers+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&delivered); got != workers+1 {
+		t.Fatalf("expected %d deliveries while others wait to retry, got %d", workers+1, got)
+	}
+
+	if err := svc.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range svc.Deliveries() {
+		if d.Webhook == "flaky" && (d.Status != DeliveryFailed || !strings.Contains(d.Error, "shut down")) {
+			t.Fatalf("waiting retry not failed on shutdown: %+v", d)
+		}
+	}
+}
+
+func TestEventFilterAndRepeatSuppression(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	svc := newTestService(t,
+		config.WebhookSettings{Name: "corrupt", URL: server.URL, Events: []string{string(events.FileCorrupted)}, Enabled: true},
+		config.WebhookSettings{Name: "disabled", URL: server.URL},
+	)
+
+	svc.handleEvent(events.Event{Type: events.LoginFailed, Time: time.Now()})
+	svc.handleEvent(events.Event{Type: events.FileCorrupted, Key: "/a.mkv", Time: time.Now()})
+	svc.handleEvent(events.Event{Type: events.FileCorrupted, Key: "/a.mkv", Time: time.Now()})
+	svc.handleEvent(events.Event{Type: events.FileCorrupted, Key: "/b.mkv", Time: time.Now()})
+
+	if got := len(svc.Deliveries()); got != 2 {
+		t.Fatalf("expected 2 queued deliveries, got %d: %+v", got, svc.Deliveries())
+	}
+	for _, d := range svc.Deliveries() {
+		if d.Webhook != "corrupt" || d.Status != DeliveryPending {
+			t.Fatalf("unexpected delivery %+v", d)
+		}
+	}
+}
+
+func TestDeliveryLogPersists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	svc := newTestService(t)
+	d, err := svc.Test(context.Background(), config.WebhookSettings{Name: "draft", URL: server.URL, Format: config.WebhookFormatDiscord})
+	if err != nil {
+		t.Fatalf("test send: %v", err)
+	}
+	if d.Status != DeliveryDelivered || d.Event != events.Test {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+
+	reloaded, err := NewService(filepath.Dir(svc.path), svc.cfgManager)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if list := reloaded.Deliveries(); len(list) != 1 || list[0].ID != d.ID {
+		t.Fatalf("unexpected reloaded log %+v", list)
+	}
+}
+
+func TestBuildRequestFormats(t *testing.T) {
+	e := events.Event{
+		Type:    events.PlaybackStarted,
+		Title:   "Playback started",
+		Message: "Alex started playing Movie.mkv.",
+		Fields:  map[string]string{"title": "Movie.mkv", "profile": "Alex"},
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	body := func(req *http.Request) string {
+		data, _ := io.ReadAll(req.Body)
+		return string(data)
+	}
+
+	req, err := buildRequest(context.Background(), config.WebhookSettings{URL: "http://x", Format: "discord"}, e)
+	if err != nil {
+		t.Fatalf("discord: %v", err)
+	}
+	var discord struct {
+		Embeds []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal([]byte(body(req)), &discord); err != nil || len(discord.Embeds) != 1 || discord.Embeds[0].Title != e.Title {
+		t.Fatalf("unexpected discord payload %+v (%v)", discord, err)
+	}
+
+	req, err = buildRequest(context.Background(), config.WebhookSettings{URL: "http://x", Format: "ntfy", Token: "tk"}, e)
+	if err != nil {
+		t.Fatalf("ntfy: %v", err)
+	}
+	if req.Header.Get("Title") != e.Title || req.Header.Get("Authorization") != "Bearer tk" || !strings.HasPrefix(body(req), e.Message+"\nprofile: Alex") {
+		t.Fatalf("unexpected ntfy request %v", req.Header)
+	}
+
+	req, err = buildRequest(context.Background(), config.WebhookSettings{URL: "http://x", Format: "gotify", Token: "app"}, e)
+	if err != nil {
+		t.Fatalf("gotify: %v", err)
+	}
+	if req.Header.Get("X-Gotify-Key") != "app" || !strings.Contains(body(req), `"priority":4`) {
+		t.Fatalf("unexpected gotify request %v", req.Header)
+	}
+
+	req, err = buildRequest(context.Background(), config.WebhookSettings{URL: "http://x", Template: `{"text": {{json .Message}}, "who": {{json .Fields.profile}}}`}, e)
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	if got := body(req); got != `{"text": "Alex started playing Movie.mkv.", "who": "Alex"}` {
+		t.Fatalf("unexpected templated body %s", got)
+	}
+
+	_, err = buildRequest(context.Background(), config.WebhookSettings{URL: "http://x", Template: `{"text": {{.Message}}}`}, e)
+	var tplErr *templateError
+	if !errors.As(err, &tplErr) {
+		t.Fatalf("expected template error for invalid JSON, got %v", err)
+	}
+}
+
+func TestExpiryEvent(t *testing.T) {
+	soon := time.Now().Add(3 * 24 * time.Hour)
+	later := time.Now().Add(60 * 24 * time.Hour)
+
+	if _, ok := expiryEvent("RD", "realdebrid", &debrid.AccountInfo{PremiumActive: true, ExpiresAt: &later, DaysRemaining: 60}); ok {
+		t.Fatal("did not expect an event for a distant expiry")
+	}
+	if _, ok := expiryEvent("RD", "realdebrid", &debrid.AccountInfo{IsLifetime: true}); ok {
+		t.Fatal("did not expect an event for a lifetime account")
+	}
+	e, ok := expiryEvent("RD", "realdebrid", &debrid.AccountInfo{PremiumActive: true, ExpiresAt: &soon, DaysRemaining: 3})
+	if !ok || e.Type != events.DebridAccountExpiring || e.Fields["daysRemaining"] != "3" {
+		t.Fatalf("unexpected expiry event %+v (ok=%v)", e, ok)
+	}
+	if e, ok := expiryEvent("RD", "realdebrid", &debrid.AccountInfo{}); !ok || !strings.Contains(e.Title, "expired") {
+		t.Fatalf("expected expired event, got %+v (ok=%v)", e, ok)
+	}
+}
@@ -31,6 +31,7 @@ type PrequeueRequest struct {
 	UserID    string `json:"userId"`
 	ClientID  string `json:"clientId,omitempty"` // Client device ID for per-client filtering
 	ImdbID    string `json:"imdbId,omitempty"`
+	TvdbID    string `json:"tvdbId,omitempty"` // Optional: read from tvdb title IDs when empty
 	Year      int    `json:"year,omitempty"`
 	// For series: episode info (determined by backend based on watch history)
 	SeasonNumber  int     `json:"seasonNumber,omitempty"`
@@ -0,0 +1,98 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"novastream/internal/mediaresolve"
+	"novastream/models"
+)
+
+// directoryPack returns the episode file map of an imported usenet directory that holds more
+// than one episode, or nil for movies and single episodes.
+func directoryPack(dirPath string, candidates []mediaFileCandidate, release models.NZBResult) *models.SeasonPack {
+	byEpisode := make(map[mediaresolve.EpisodeCode]models.PackFile)
+	for _, candidate := range candidates {
+		code, ok := mediaresolve.ExtractEpisodeCode(path.Base(candidate.path), candidate.path)
+		if !ok || code.Season <= 0 || code.Episode <= 0 {
+			continue
+		}
+		// Prefer the container we'd pick anyway when an episode exists in several formats
+		if existing, dup := byEpisode[code]; dup && playableExtensionPriority[strings.ToLower(path.Ext(existing.Path))] <= candidate.priority {
+			continue
+		}
+		byEpisode[code] = models.PackFile{
+			Path:          candidate.path,
+			SeasonNumber:  code.Season,
+			EpisodeNumber: code.Episode,
+		}
+	}
+	if len(byEpisode) < 2 {
+		return nil
+	}
+
+	pack := &models.SeasonPack{
+		ServiceType: models.ServiceTypeUsenet,
+		Directory:   dirPath,
+		Release:     release,
+	}
+	for _, file := range byEpisode {
+		pack.Files = append(pack.Files, file)
+	}
+	sort.Slice(pack.Files, func(i, j int) bool {
+		if pack.Files[i].SeasonNumber != pack.Files[j].SeasonNumber {
+			return pack.Files[i].SeasonNumber < pack.Files[j].SeasonNumber
+		}
+		return pack.Files[i].EpisodeNumber < pack.Files[j].EpisodeNumber
+	})
+	return pack
+}
+
+// ResolvePackFile returns a playback resolution for an episode of a pack resolved earlier,
+// either a debrid torrent still on the account or an imported usenet directory.
+func (s *Service) ResolvePackFile(ctx context.Context, pack models.SeasonPack, file models.PackFile) (*models.PlaybackResolution, error) {
+	if pack.ServiceType == models.ServiceTypeDebrid {
+		if s.debrid == nil {
+			return nil, fmt.Errorf("debrid service not configured")
+		}
+		return s.debrid.ResolvePackFile(ctx, pack, file)
+	}
+
+	if s.metadataSvc == nil {
+		return nil, fmt.Errorf("metadata service not configured")
+	}
+
+	cfg, err := s.cfg.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	files, err := s.metadataSvc.ListDirectory(path.Dir(file.Path))
+	if err != nil {
+		return nil, fmt.Errorf("list pack directory: %w", err)
+	}
+	found := false
+	for _, name := range files {
+		if name == path.Base(file.Path) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("file %q is no longer part of the imported pack", file.Path)
+	}
+
+	webdavPath := fmt.Sprintf("%s%s", strings.TrimRight(cfg.WebDAV.Prefix, "/"), file.Path)
+	log.Printf("[playback] resolved S%02dE%02d from imported pack %q: %s", file.SeasonNumber, file.EpisodeNumber, pack.Directory, webdavPath)
+
+	return &models.PlaybackResolution{
+		WebDAVPath:   webdavPath,
+		HealthStatus: "healthy",
+		FileSize:     file.Size,
+		Pack:         &pack,
+	}, nil
+}
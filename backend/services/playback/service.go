@@ -40,6 +40,12 @@ type metadataService interface {
 	ListSubdirectories(virtualPath string) ([]string, error)
 }
 
+// grabTracker enforces and counts per-indexer NZB download quotas.
+type grabTracker interface {
+	AllowGrab(indexerName string) error
+	RecordGrab(indexerName string, err error)
+}
+
 // Service coordinates NZB validation and prepares backend-hosted playback streams.
 type Service struct {
 	cfg         *config.Manager
@@ -48,6 +54,7 @@ type Service struct {
 	debrid      *debrid.PlaybackService
 	nzbSystem   *integration.NzbSystem
 	metadataSvc metadataService
+	grabs       grabTracker
 }
 
 var (
@@ -55,6 +62,15 @@ var (
 	ErrQueueItemFailed   = errors.New("playback queue item failed")
 )
 
+// HealthError reports an NZB whose health check failed, as opposed to an error while checking.
+type HealthError struct {
+	Status string
+}
+
+func (e *HealthError) Error() string {
+	return fmt.Sprintf("nzb health check reported %s", e.Status)
+}
+
 // HealthCheckResult holds the result of a parallel health check for a single candidate
 type HealthCheckResult struct {
 	Index     int                    // Original index in the results slice (for priority)
@@ -90,8 +106,8 @@ func NewService(cfg *config.Manager, usenetSvc usenetHealthService, nzbSystem *i
 func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*models.PlaybackResolution, error) {
 	log.Printf("[playback] resolve start title=%q downloadURL=%q link=%q serviceType=%q", strings.TrimSpace(candidate.Title), strings.TrimSpace(candidate.DownloadURL), strings.TrimSpace(candidate.Link), candidate.ServiceType)
 
-	// Route to debrid service if this is a debrid result
-	if candidate.ServiceType == models.ServiceTypeDebrid {
+	// Route to debrid service if this is a debrid result or an addon-resolved direct stream
+	if candidate.ServiceType == models.ServiceTypeDebrid || candidate.ServiceType == models.ServiceTypeDirect {
 		if s.debrid == nil {
 			return nil, fmt.Errorf("debrid service not configured")
 		}
@@ -139,7 +155,7 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 			}
 			log.Printf("[playback] backend health status=%q healthy=%t sampled=%t missing=%d", healthStatus, check.Healthy, check.Sampled, len(check.MissingSegments))
 			if !check.Healthy {
-				return nil, fmt.Errorf("nzb health check reported %s", healthStatus)
+				return nil, &HealthError{Status: healthStatus}
 			}
 		}
 	} else {
@@ -175,10 +191,10 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 
 	// If storagePath is a directory (multi-file NZB), find the best playable file within it
 	finalPath := storagePath
+	var pack *models.SeasonPack
 	if s.metadataSvc != nil && s.isLikelyDirectory(storagePath) {
 		log.Printf("[playback] storagePath appears to be a directory, scanning for media files: %q", storagePath)
-		hints := buildSelectionHintsFromCandidate(candidate, storagePath)
-		mediaFile, findErr := s.findBestMediaFile(storagePath, hints)
+		mediaFile, dirPack, findErr := s.resolveDirectory(storagePath, candidate)
 		if findErr != nil {
 			return nil, fmt.Errorf("directory contains no playable media files: %w", findErr)
 		}
@@ -186,6 +202,7 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 			finalPath = mediaFile
 			log.Printf("[playback] selected media file from directory: %q", finalPath)
 		}
+		pack = dirPack
 	}
 
 	sourceNZBPath := strings.TrimSpace(fileName)
@@ -215,6 +232,7 @@ func (s *Service) Resolve(ctx context.Context, candidate models.NZBResult) (*mod
 		FileSize:      fileSize,
 		SourceNZBPath: sourceNZBPath,
 		WebDAVPath:    webdavPath,
+		Pack:          pack,
 	}
 
 	log.Printf("[playback] NZB processed and ready for playback, webdavPath=%q", webdavPath)
@@ -235,7 +253,7 @@ func (s *Service) ParallelHealthCheck(ctx context.Context, candidates []models.N
 		candidate models.NZBResult
 	}
 	for i, c := range candidates {
-		if c.ServiceType != models.ServiceTypeDebrid {
+		if c.ServiceType != models.ServiceTypeDebrid && c.ServiceType != models.ServiceTypeDirect {
 			usenetCandidates = append(usenetCandidates, struct {
 				index     int
 				candidate models.NZBResult
@@ -422,6 +440,21 @@ func (s *Service) ResolveWithHealthResult(ctx context.Context, result HealthChec
 
 	log.Printf("[playback] NZB processed successfully, storagePath=%q", storagePath)
 
+	// Season packs import as a directory, play the target episode from it
+	finalPath := storagePath
+	var pack *models.SeasonPack
+	if s.metadataSvc != nil && s.isLikelyDirectory(storagePath) {
+		mediaFile, dirPack, findErr := s.resolveDirectory(storagePath, result.Candidate)
+		if findErr != nil {
+			return nil, fmt.Errorf("directory contains no playable media files: %w", findErr)
+		}
+		if mediaFile != "" {
+			finalPath = mediaFile
+			log.Printf("[playback] selected media file from directory: %q", finalPath)
+		}
+		pack = dirPack
+	}
+
 	sourceNZBPath := strings.TrimSpace(result.FileName)
 	if result.Check != nil && strings.TrimSpace(result.Check.FileName) != "" {
 		sourceNZBPath = strings.TrimSpace(result.Check.FileName)
@@ -442,13 +475,14 @@ func (s *Service) ResolveWithHealthResult(ctx context.Context, result HealthChec
 	}
 
 	// Prepend WebDAV prefix to the storage path
-	webdavPath := fmt.Sprintf("%s%s", strings.TrimRight(cfg.WebDAV.Prefix, "/"), storagePath)
+	webdavPath := fmt.Sprintf("%s%s", strings.TrimRight(cfg.WebDAV.Prefix, "/"), finalPath)
 
 	resolution := &models.PlaybackResolution{
 		HealthStatus:  "healthy",
 		FileSize:      fileSize,
 		SourceNZBPath: sourceNZBPath,
 		WebDAVPath:    webdavPath,
+		Pack:          pack,
 	}
 
 	log.Printf("[playback] NZB processed and ready for playback, webdavPath=%q", webdavPath)
@@ -507,7 +541,19 @@ func (s *Service) QueueStatus(_ context.Context, queueID int64) (*models.Playbac
 	}
 }
 
-func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate models.NZBResult) ([]byte, string, error) {
+// SetGrabTracker enables per-indexer grab quotas for NZB downloads.
+func (s *Service) SetGrabTracker(tracker grabTracker) {
+	s.grabs = tracker
+}
+
+func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate models.NZBResult) (data []byte, fileName string, err error) {
+	if s.grabs != nil {
+		if err := s.grabs.AllowGrab(candidate.Indexer); err != nil {
+			return nil, "", err
+		}
+		defer func() { s.grabs.RecordGrab(candidate.Indexer, err) }()
+	}
+
 	log.Printf("[playback] fetching nzb url=%q title=%q", downloadURL, strings.TrimSpace(candidate.Title))
 
 	// Create a context with timeout for the entire fetch operation
@@ -562,8 +608,7 @@ func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate mo
 			log.Printf("[playback] warning: nzb file may have been truncated at %d bytes", maxNZBSize)
 		}
 		log.Printf("[playback] nzb body read complete size=%d", len(result.data))
-		fileName := deriveFileName(resp, downloadURL, candidate)
-		return result.data, fileName, nil
+		return result.data, deriveFileName(resp, downloadURL, candidate), nil
 	}
 }
 
@@ -685,9 +730,30 @@ func buildSelectionHintsFromCandidate(candidate models.NZBResult, directory stri
 
 // findBestMediaFile recursively scans a directory for the best playable media file
 func (s *Service) findBestMediaFile(dirPath string, hints mediaresolve.SelectionHints) (string, error) {
+	candidates, err := s.listPlayableFiles(dirPath)
+	if err != nil {
+		return "", err
+	}
+	return selectMediaFile(candidates, dirPath, hints)
+}
+
+// resolveDirectory picks the media file to play from an imported multi-file NZB and maps
+// its episodes if it is a season or complete pack.
+func (s *Service) resolveDirectory(dirPath string, candidate models.NZBResult) (string, *models.SeasonPack, error) {
+	candidates, err := s.listPlayableFiles(dirPath)
+	if err != nil {
+		return "", nil, err
+	}
+	mediaFile, err := selectMediaFile(candidates, dirPath, buildSelectionHintsFromCandidate(candidate, dirPath))
+	if err != nil {
+		return "", nil, err
+	}
+	return mediaFile, directoryPack(dirPath, candidates, candidate), nil
+}
+
+// listPlayableFiles recursively lists the playable media files in a directory
+func (s *Service) listPlayableFiles(dirPath string) ([]mediaFileCandidate, error) {
 	var candidates []mediaFileCandidate
-	var resolverCandidates []mediaresolve.Candidate
-	bestIdx := -1
 
 	var scan func(currentPath string, depth int) error
 	scan = func(currentPath string, depth int) error {
@@ -717,14 +783,6 @@ func (s *Service) findBestMediaFile(dirPath string, hints mediaresolve.Selection
 					path:     filePath,
 					priority: priority,
 				})
-				resolverCandidates = append(resolverCandidates, mediaresolve.Candidate{
-					Label:    filePath,
-					Priority: priority,
-				})
-				idx := len(candidates) - 1
-				if bestIdx == -1 || candidates[idx].priority < candidates[bestIdx].priority {
-					bestIdx = idx
-				}
 			}
 		}
 
@@ -748,9 +806,14 @@ func (s *Service) findBestMediaFile(dirPath string, hints mediaresolve.Selection
 	}
 
 	if err := scan(dirPath, 0); err != nil {
-		return "", err
+		return nil, err
 	}
+	return candidates, nil
+}
 
+// selectMediaFile picks the playable file matching the selection hints, falling back to
+// extension priority
+func selectMediaFile(candidates []mediaFileCandidate, dirPath string, hints mediaresolve.SelectionHints) (string, error) {
 	if len(candidates) == 0 {
 		return "", fmt.Errorf("no playable media files found")
 	}
@@ -760,6 +823,18 @@ func (s *Service) findBestMediaFile(dirPath string, hints mediaresolve.Selection
 		return candidates[0].path, nil
 	}
 
+	resolverCandidates := make([]mediaresolve.Candidate, 0, len(candidates))
+	bestIdx := -1
+	for idx, candidate := range candidates {
+		resolverCandidates = append(resolverCandidates, mediaresolve.Candidate{
+			Label:    candidate.path,
+			Priority: candidate.priority,
+		})
+		if bestIdx == -1 || candidate.priority < candidates[bestIdx].priority {
+			bestIdx = idx
+		}
+	}
+
 	selectorHints := hints
 	if strings.TrimSpace(selectorHints.Directory) == "" {
 		selectorHints.Directory = dirPath
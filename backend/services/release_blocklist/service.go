@@ -0,0 +1,237 @@
+package release_blocklist
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+// pruneInterval is how often recording a failure also removes expired entries.
+const pruneInterval = time.Hour
+
+var ErrRepositoryRequired = errors.New("release blocklist repository not provided")
+
+// What made a release fail.
+const (
+	SourceUsenetHealth = "usenet_health" // NZB health check found missing articles
+	SourceResolve      = "resolve"       // Debrid or addon stream could not be resolved
+	SourceDVProfile    = "dv_profile"    // Dolby Vision profile 5 rejected by the HDR policy
+	SourceTranscode    = "transcode"     // FFmpeg gave up on the stream (HLS fatal error)
+)
+
+// Attributes set on search results so a later failure is recorded against the title that
+// was searched for, and so demoted results can be shown as such.
+const (
+	AttrTitleKey  = "blocklistTitleKey"
+	AttrTitleName = "blocklistTitleName"
+	AttrBlocked   = "blocklisted" // Reason the release is blocklisted, on demoted results
+)
+
+// Service keeps a per-title blocklist of releases that failed playback, so searches for the
+// same title stop picking them until the entry expires.
+type Service struct {
+	repo       *database.ReleaseBlocklistRepository
+	cfgManager *config.Manager
+	now        func() time.Time
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewService creates a release blocklist backed by the queue database.
+func NewService(repo *database.ReleaseBlocklistRepository, cfgManager *config.Manager) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo, cfgManager: cfgManager, now: time.Now}, nil
+}
+
+func (s *Service) settings() config.ReleaseBlocklistSettings {
+	if s.cfgManager != nil {
+		if settings, err := s.cfgManager.Load(); err == nil {
+			return settings.ReleaseBlocklist
+		}
+	}
+	return config.DefaultSettings().ReleaseBlocklist
+}
+
+// RecordFailure blocklists a search result for the title it was found for. Results that
+// didn't come from a search carry no title and are ignored.
+func (s *Service) RecordFailure(candidate models.NZBResult, source, reason string) {
+	titleKey := strings.TrimSpace(candidate.Attributes[AttrTitleKey])
+	releaseKey := ReleaseKey(candidate)
+	if titleKey == "" || releaseKey == "" {
+		log.Printf("[release-blocklist] cannot blocklist %q (%s): result has no title or release key", candidate.Title, source)
+		return
+	}
+
+	now := s.now()
+	expiryDays := s.settings().ExpiryDays
+	if expiryDays <= 0 {
+		expiryDays = config.DefaultSettings().ReleaseBlocklist.ExpiryDays
+	}
+	release := &database.BlockedRelease{
+		TitleKey:     titleKey,
+		ReleaseKey:   releaseKey,
+		TitleName:    strings.TrimSpace(candidate.Attributes[AttrTitleName]),
+		ReleaseTitle: strings.TrimSpace(candidate.Title),
+		ServiceType:  string(candidate.ServiceType),
+		Source:       source,
+		Reason:       strings.TrimSpace(reason),
+		LastFailedAt: now,
+		ExpiresAt:    now.AddDate(0, 0, expiryDays),
+	}
+	if err := s.repo.RecordBlockedRelease(release); err != nil {
+		log.Printf("[release-blocklist] failed to blocklist %q: %v", candidate.Title, err)
+		return
+	}
+	log.Printf("[release-blocklist] blocklisted %q for %s (%s): %s", candidate.Title, titleKey, source, release.Reason)
+
+	s.mu.Lock()
+	due := now.Sub(s.lastPrune) >= pruneInterval
+	if due {
+		s.lastPrune = now
+	}
+	s.mu.Unlock()
+	if due {
+		if _, err := s.Prune(); err != nil {
+			log.Printf("[release-blocklist] prune failed: %v", err)
+		}
+	}
+}
+
+// Apply tags ranked search results with the title they were found for and drops or demotes
+// the ones blocklisted for that title, depending on the configured mode.
+func (s *Service) Apply(titleKey, titleName string, results []models.NZBResult) []models.NZBResult {
+	if titleKey == "" || len(results) == 0 {
+		return results
+	}
+	for i := range results {
+		if results[i].Attributes == nil {
+			results[i].Attributes = map[string]string{}
+		}
+		results[i].Attributes[AttrTitleKey] = titleKey
+		if titleName != "" {
+			results[i].Attributes[AttrTitleName] = titleName
+		}
+	}
+
+	mode := s.settings().Mode
+	if mode == config.ReleaseBlocklistModeOff {
+		return results
+	}
+
+	entries, err := s.repo.ListBlockedReleases(titleKey, s.now())
+	if err != nil {
+		log.Printf("[release-blocklist] failed to load blocklist for %s: %v", titleKey, err)
+		return results
+	}
+	if len(entries) == 0 {
+		return results
+	}
+	blocked := make(map[string]*database.BlockedRelease, len(entries))
+	for _, entry := range entries {
+		blocked[entry.ReleaseKey] = entry
+	}
+
+	kept := make([]models.NZBResult, 0, len(results))
+	var demoted []models.NZBResult
+	for _, result := range results {
+		entry, ok := blocked[ReleaseKey(result)]
+		if !ok {
+			kept = append(kept, result)
+			continue
+		}
+		if mode == config.ReleaseBlocklistModeDrop {
+			continue
+		}
+		result.Attributes[AttrBlocked] = entry.Reason
+		demoted = append(demoted, result)
+	}
+
+	if removed := len(results) - len(kept); removed > 0 {
+		log.Printf("[release-blocklist] %s: %d of %d results blocklisted (mode=%s)", titleKey, removed, len(results), mode)
+	}
+	return append(kept, demoted...)
+}
+
+// List returns the active entries of a title, or of every title when titleKey is empty.
+func (s *Service) List(titleKey string) ([]*database.BlockedRelease, error) {
+	return s.repo.ListBlockedReleases(titleKey, s.now())
+}
+
+// Remove clears entries. An empty releaseKey clears the whole title; both empty clear all.
+func (s *Service) Remove(titleKey, releaseKey string) (int64, error) {
+	removed, err := s.repo.DeleteBlockedReleases(titleKey, releaseKey)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("[release-blocklist] removed %d entries (title=%q release=%q)", removed, titleKey, releaseKey)
+	return removed, nil
+}
+
+// Prune removes expired entries.
+func (s *Service) Prune() (int64, error) {
+	removed, err := s.repo.DeleteExpiredBlockedReleases(s.now())
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		log.Printf("[release-blocklist] pruned %d expired entries", removed)
+	}
+	return removed, nil
+}
+
+var titleKeyCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// TitleKey identifies what a search was for: the IMDB ID when known, otherwise the
+// normalized title and year, plus the season and episode for series.
+func TitleKey(imdbID, title string, year, season, episode int) string {
+	var key string
+	if id := strings.ToLower(strings.TrimSpace(imdbID)); id != "" {
+		key = "imdb:" + id
+	} else {
+		name := strings.TrimSpace(titleKeyCleaner.ReplaceAllString(strings.ToLower(title), " "))
+		if name == "" {
+			return ""
+		}
+		key = "title:" + name
+		if year > 0 {
+			key = fmt.Sprintf("%s:%d", key, year)
+		}
+	}
+
+	switch {
+	case season > 0 && episode > 0:
+		key = fmt.Sprintf("%s:s%02de%02d", key, season, episode)
+	case season > 0:
+		key = fmt.Sprintf("%s:s%02d", key, season)
+	}
+	return key
+}
+
+// ReleaseKey identifies a release by infohash for torrents, otherwise by NZB GUID (or its
+// download URL when the indexer sets none).
+func ReleaseKey(result models.NZBResult) string {
+	if hash := strings.ToLower(strings.TrimSpace(result.Attributes["infoHash"])); hash != "" {
+		return "hash:" + hash
+	}
+	if guid := strings.TrimSpace(result.GUID); guid != "" {
+		return "guid:" + guid
+	}
+	if url := strings.TrimSpace(result.DownloadURL); url != "" {
+		return "url:" + url
+	}
+	if link := strings.TrimSpace(result.Link); link != "" {
+		return "url:" + link
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package release_blocklist
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+func newTestService(t *testing.T, mode config.ReleaseBlocklistMode) (*Service, *config.Manager) {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(dir, "queue.db")})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg := config.DefaultSettings()
+	cfg.ReleaseBlocklist.Mode = mode
+	cfg.ReleaseBlocklist.ExpiryDays = 7
+	mgr := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("save cfg: %v", err)
+	}
+
+	svc, err := NewService(database.NewReleaseBlocklistRepository(db.Connection()), mgr)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return svc, mgr
+}
+
+func searchResults() []models.NZBResult {
+	return []models.NZBResult{
+		{Title: "Movie.2020.2160p.BluRay", ServiceType: models.ServiceTypeDebrid, GUID: "magnet:aaa", Attributes: map[string]string{"infoHash": "AAA"}},
+		{Title: "Movie.2020.1080p.WEB", ServiceType: models.ServiceTypeUsenet, GUID: "nzb-1"},
+		{Title: "Movie.2020.720p", ServiceType: models.ServiceTypeUsenet, DownloadURL: "https://indexer/get/2"},
+	}
+}
+
+func titles(results []models.NZBResult) []string {
+	var out []string
+	for _, r := range results {
+		out = append(out, r.Title)
+	}
+	return out
+}
+
+func TestFailedReleasesAreDemotedForTheirTitle(t *testing.T) {
+	svc, _ := newTestService(t, config.ReleaseBlocklistModeDemote)
+	titleKey := TitleKey("tt0000001", "Movie", 2020, 0, 0)
+
+	results := svc.Apply(titleKey, "Movie (2020)", searchResults())
+	svc.RecordFailure(results[0], SourceResolve, "torrent not cached")
+	svc.RecordFailure(results[1], SourceUsenetHealth, "nzb health check reported unhealthy")
+
+	results = svc.Apply(titleKey, "Movie (2020)", searchResults())
+	got := titles(results)
+	want := []string{"Movie.2020.720p", "Movie.2020.2160p.BluRay", "Movie.2020.1080p.WEB"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if results[1].Attributes[AttrBlocked] != "torrent not cached" {
+		t.Fatalf("expected demoted result to carry its reason, got %q", results[1].Attributes[AttrBlocked])
+	}
+
+	// Another title (or another episode) is not affected
+	other := svc.Apply(TitleKey("tt0000002", "", 0, 1, 2), "Other", searchResults())
+	if other[0].Title != "Movie.2020.2160p.BluRay" {
+		t.Fatalf("expected other titles to keep their ranking, got %v", titles(other))
+	}
+
+	entries, err := svc.List(titleKey)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 2 || entries[0].TitleName != "Movie (2020)" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestDropModeAndRemoval(t *testing.T) {
+	svc, _ := newTestService(t, config.ReleaseBlocklistModeDrop)
+	titleKey := TitleKey("", "Show", 0, 1, 2)
+
+	results := svc.Apply(titleKey, "Show S01E02", searchResults())
+	svc.RecordFailure(results[2], SourceTranscode, "Stream contains malformed video data that cannot be processed")
+	svc.RecordFailure(results[2], SourceTranscode, "Stream contains malformed video data that cannot be processed")
+
+	results = svc.Apply(titleKey, "Show S01E02", searchResults())
+	if len(results) != 2 {
+		t.Fatalf("expected the failed release to be dropped, got %v", titles(results))
+	}
+
+	entries, err := svc.List("")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Failures != 2 || entries[0].ReleaseKey != "url:https://indexer/get/2" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	removed, err := svc.Remove(titleKey, entries[0].ReleaseKey)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 removal, got %d (%v)", removed, err)
+	}
+	if results := svc.Apply(titleKey, "Show S01E02", searchResults()); len(results) != 3 {
+		t.Fatalf("expected all results after removal, got %v", titles(results))
+	}
+}
+
+func TestEntriesExpire(t *testing.T) {
+	svc, _ := newTestService(t, config.ReleaseBlocklistModeDrop)
+	titleKey := TitleKey("tt0000001", "", 0, 0, 0)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	results := svc.Apply(titleKey, "", searchResults())
+	svc.RecordFailure(results[0], SourceDVProfile, "DV_PROFILE_INCOMPATIBLE: profile 5 has no HDR fallback layer")
+	if got := svc.Apply(titleKey, "", searchResults()); len(got) != 2 {
+		t.Fatalf("expected the failed release to be dropped, got %v", titles(got))
+	}
+
+	now = now.AddDate(0, 0, 8)
+	if got := svc.Apply(titleKey, "", searchResults()); len(got) != 3 {
+		t.Fatalf("expected the entry to expire after 7 days, got %v", titles(got))
+	}
+	if removed, err := svc.Prune(); err != nil || removed != 1 {
+		t.Fatalf("expected 1 pruned entry, got %d (%v)", removed, err)
+	}
+}
+
+func TestUntaggedResultsAreNotRecorded(t *testing.T) {
+	svc, _ := newTestService(t, config.ReleaseBlocklistModeDemote)
+	svc.RecordFailure(searchResults()[0], SourceResolve, "failed")
+	entries, err := svc.List("")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected nothing recorded without a title, got %+v", entries)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		got, want string
+	}{
+		{TitleKey("TT0133093", "ignored", 1999, 0, 0), "imdb:tt0133093"},
+		{TitleKey("", "The Matrix!", 1999, 0, 0), "title:the matrix:1999"},
+		{TitleKey("tt0944947", "", 0, 1, 2), "imdb:tt0944947:s01e02"},
+		{TitleKey("", "Show", 0, 3, 0), "title:show:s03"},
+		{TitleKey("", "  ", 0, 0, 0), ""},
+		{ReleaseKey(models.NZBResult{GUID: "magnet:abc", Attributes: map[string]string{"infoHash": "ABC"}}), "hash:abc"},
+		{ReleaseKey(models.NZBResult{GUID: "nzb-guid"}), "guid:nzb-guid"},
+		{ReleaseKey(models.NZBResult{Link: "https://indexer/nzb"}), "url:https://indexer/nzb"},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("expected %q, got %q", c.want, c.got)
+		}
+	}
+}
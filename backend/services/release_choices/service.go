@@ -0,0 +1,275 @@
+package release_choices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/mediaresolve"
+	"novastream/models"
+	season_packs "novastream/services/season_packs"
+)
+
+var (
+	ErrStorageDirRequired = errors.New("storage directory not provided")
+	ErrUserIDRequired     = errors.New("user id is required")
+	ErrContentIDRequired  = errors.New("content id is required")
+)
+
+// Service persists the release each profile last played per title.
+type Service struct {
+	mu      sync.RWMutex
+	path    string
+	choices map[string]map[string]models.ReleaseChoice // userID -> contentID -> choice
+}
+
+// NewService constructs a release choice service backed by a JSON file on disk.
+func NewService(storageDir string) (*Service, error) {
+	if strings.TrimSpace(storageDir) == "" {
+		return nil, ErrStorageDirRequired
+	}
+
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create release choices dir: %w", err)
+	}
+
+	svc := &Service{
+		path:    filepath.Join(storageDir, "release_choices.json"),
+		choices: make(map[string]map[string]models.ReleaseChoice),
+	}
+
+	if err := svc.load(); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// Get retrieves the release choice for a title.
+// Returns nil if nothing was played yet.
+func (s *Service) Get(userID, contentID string) (*models.ReleaseChoice, error) {
+	userID = strings.TrimSpace(userID)
+	contentID = strings.TrimSpace(strings.ToLower(contentID))
+
+	if userID == "" {
+		return nil, ErrUserIDRequired
+	}
+	if contentID == "" {
+		return nil, ErrContentIDRequired
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	choice, ok := s.choices[userID][contentID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &choice, nil
+}
+
+// Record stores the release last played for a title, replacing any earlier choice.
+func (s *Service) Record(userID string, choice models.ReleaseChoice) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+
+	contentID := strings.TrimSpace(strings.ToLower(choice.ContentID))
+	if contentID == "" {
+		return ErrContentIDRequired
+	}
+
+	choice.ContentID = contentID
+	choice.ContentType = normalizeContentType(choice.ContentType)
+	if choice.ContentType == "series" {
+		choice.SeasonPack = isSeasonPack(choice.Release.Title)
+	} else {
+		choice.SeasonNumber = 0
+		choice.EpisodeNumber = 0
+		choice.SeasonPack = false
+	}
+	choice.UpdatedAt = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser := s.ensureUserLocked(userID)
+	perUser[contentID] = choice
+
+	return s.saveLocked()
+}
+
+// Delete forgets the release choice for a title.
+func (s *Service) Delete(userID, contentID string) error {
+	userID = strings.TrimSpace(userID)
+	contentID = strings.TrimSpace(strings.ToLower(contentID))
+
+	if userID == "" {
+		return ErrUserIDRequired
+	}
+	if contentID == "" {
+		return ErrContentIDRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	perUser, ok := s.choices[userID]
+	if !ok {
+		return nil // Nothing to delete
+	}
+	if _, ok := perUser[contentID]; !ok {
+		return nil
+	}
+
+	delete(perUser, contentID)
+
+	// Clean up empty user maps
+	if len(perUser) == 0 {
+		delete(s.choices, userID)
+	}
+
+	return s.saveLocked()
+}
+
+// Candidate returns the release to try first for the requested episode (ignored for movies):
+// the stored release for the same episode, or the stored season pack for another episode of
+// the same season. Season packs are retargeted to the requested episode so the right file is
+// picked from them.
+func Candidate(choice *models.ReleaseChoice, season, episode int) (models.NZBResult, bool) {
+	if choice == nil || (choice.Release.Title == "" && choice.Release.GUID == "") {
+		return models.NZBResult{}, false
+	}
+	if choice.ContentType != "series" {
+		return choice.Release, true
+	}
+	if season <= 0 || episode <= 0 || season != choice.SeasonNumber {
+		return models.NZBResult{}, false
+	}
+	if episode == choice.EpisodeNumber {
+		return choice.Release, true
+	}
+	if !choice.SeasonPack {
+		return models.NZBResult{}, false
+	}
+
+	return season_packs.EpisodeRelease(choice.Release, season, episode), true
+}
+
+// isSeasonPack reports whether a series release holds more than one episode, which is the
+// case when its title has no SxxEyy code.
+func isSeasonPack(title string) bool {
+	if strings.TrimSpace(title) == "" {
+		return false
+	}
+	_, ok := mediaresolve.ExtractEpisodeCode(title)
+	return !ok
+}
+
+func normalizeContentType(contentType string) string {
+	switch strings.ToLower(strings.TrimSpace(contentType)) {
+	case "series", "episode", "tv", "show":
+		return "series"
+	default:
+		return "movie"
+	}
+}
+
+// ensureUserLocked creates the per-user map if it doesn't exist.
+// Must be called with s.mu held.
+func (s *Service) ensureUserLocked(userID string) map[string]models.ReleaseChoice {
+	perUser, ok := s.choices[userID]
+	if !ok {
+		perUser = make(map[string]models.ReleaseChoice)
+		s.choices[userID] = perUser
+	}
+	return perUser
+}
+
+// load reads the release choices from disk.
+func (s *Service) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.choices = make(map[string]map[string]models.ReleaseChoice)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open release choices: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("read release choices: %w", err)
+	}
+	if len(data) == 0 {
+		s.choices = make(map[string]map[string]models.ReleaseChoice)
+		return nil
+	}
+
+	// Load as map[userID][]ReleaseChoice (array format for storage)
+	var loaded map[string][]models.ReleaseChoice
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("decode release choices: %w", err)
+	}
+
+	s.choices = make(map[string]map[string]models.ReleaseChoice)
+	for userID, items := range loaded {
+		userID = strings.TrimSpace(userID)
+		if userID == "" {
+			continue
+		}
+		perUser := make(map[string]models.ReleaseChoice, len(items))
+		for _, choice := range items {
+			contentID := strings.ToLower(choice.ContentID)
+			choice.ContentID = contentID
+			perUser[contentID] = choice
+		}
+		s.choices[userID] = perUser
+	}
+
+	log.Printf("[release_choices] loaded release choices for %d users", len(s.choices))
+	return nil
+}
+
+// saveLocked writes the release choices to disk.
+// Must be called with s.mu held.
+func (s *Service) saveLocked() error {
+	// Convert to array format for storage
+	toSave := make(map[string][]models.ReleaseChoice)
+	for userID, perUser := range s.choices {
+		items := make([]models.ReleaseChoice, 0, len(perUser))
+		for _, choice := range perUser {
+			items = append(items, choice)
+		}
+		// Sort by most recently updated
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].UpdatedAt.After(items[j].UpdatedAt)
+		})
+		toSave[userID] = items
+	}
+
+	data, err := json.MarshalIndent(toSave, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode release choices: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write release choices: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package release_choices
+
+import (
+	"testing"
+
+	"novastream/models"
+)
+
+func TestRecordPersistsPerProfile(t *testing.T) {
+	dir := t.TempDir()
+	svc, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	release := models.NZBResult{Title: "Movie.2020.Directors.Cut.2160p", GUID: "nzb-1", ServiceType: models.ServiceTypeUsenet}
+	if err := svc.Record("profile-a", models.ReleaseChoice{
+		ContentID:   "TMDB:movie:1",
+		ContentType: "movie",
+		Release:     release,
+		SourcePath:  "/webdav/usenet/movie.mkv",
+		Manual:      true,
+	}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	reloaded, err := NewService(dir)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	choice, err := reloaded.Get("profile-a", "tmdb:movie:1")
+	if err != nil || choice == nil {
+		t.Fatalf("expected a stored choice, got %+v (%v)", choice, err)
+	}
+	if choice.Release.GUID != "nzb-1" || !choice.Manual || choice.SourcePath != "/webdav/usenet/movie.mkv" {
+		t.Fatalf("unexpected choice %+v", choice)
+	}
+	if other, _ := reloaded.Get("profile-b", "tmdb:movie:1"); other != nil {
+		t.Fatalf("expected choices to be per profile, got %+v", other)
+	}
+
+	if err := reloaded.Delete("profile-a", "tmdb:movie:1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if choice, _ := reloaded.Get("profile-a", "tmdb:movie:1"); choice != nil {
+		t.Fatalf("expected the choice to be deleted, got %+v", choice)
+	}
+}
+
+func TestCandidateReusesSeasonPacks(t *testing.T) {
+	svc, err := NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	pack := models.NZBResult{
+		Title:       "Show.S02.1080p.BluRay.x264",
+		GUID:        "magnet:pack",
+		ServiceType: models.ServiceTypeDebrid,
+		Attributes:  map[string]string{"targetSeason": "2", "targetEpisode": "3", "targetEpisodeCode": "S02E03"},
+	}
+	if err := svc.Record("profile", models.ReleaseChoice{ContentID: "tmdb:tv:9", ContentType: "episode", SeasonNumber: 2, EpisodeNumber: 3, Release: pack}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	choice, _ := svc.Get("profile", "tmdb:tv:9")
+	if choice.ContentType != "series" || !choice.SeasonPack {
+		t.Fatalf("expected a series season pack, got %+v", choice)
+	}
+
+	next, ok := Candidate(choice, 2, 4)
+	if !ok || next.Attributes["targetEpisodeCode"] != "S02E04" || next.Attributes["targetEpisode"] != "4" {
+		t.Fatalf("expected the pack retargeted to S02E04, got %+v (%v)", next.Attributes, ok)
+	}
+	if choice.Release.Attributes["targetEpisode"] != "3" {
+		t.Fatalf("expected the stored release to be unchanged, got %+v", choice.Release.Attributes)
+	}
+	if _, ok := Candidate(choice, 3, 1); ok {
+		t.Fatalf("expected no candidate for another season")
+	}
+
+	single := models.NZBResult{Title: "Show.S02E05.1080p.WEB", GUID: "nzb-5"}
+	if err := svc.Record("profile", models.ReleaseChoice{ContentID: "tmdb:tv:9", ContentType: "series", SeasonNumber: 2, EpisodeNumber: 5, Release: single}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	choice, _ = svc.Get("profile", "tmdb:tv:9")
+	if got, ok := Candidate(choice, 2, 5); !ok || got.GUID != "nzb-5" {
+		t.Fatalf("expected the same release for the same episode, got %+v (%v)", got, ok)
+	}
+	if _, ok := Candidate(choice, 2, 6); ok {
+		t.Fatalf("expected a single episode release not to be reused for the next episode")
+	}
+}
@@ -11,18 +11,38 @@ import (
 	"time"
 
 	"novastream/config"
+	"novastream/internal/events"
 	"novastream/models"
+	"novastream/services/debrid"
 	"novastream/services/plex"
 	"novastream/services/trakt"
 	"novastream/services/watchlist"
 )
 
+// EPGRefresher reloads the Live TV program guide.
+type EPGRefresher interface {
+	RefreshEPG(ctx context.Context) (int, error)
+}
+
+// DebridHousekeeper removes stale torrents strmr added to debrid accounts.
+type DebridHousekeeper interface {
+	CleanupTorrents(ctx context.Context, opts debrid.CleanupOptions) (*debrid.CleanupReport, error)
+}
+
+// RecordingRunner starts Live TV recordings that are due.
+type RecordingRunner interface {
+	StartDueRecordings(horizon time.Duration) int
+}
+
 // Service manages scheduled task execution
 type Service struct {
 	configManager    *config.Manager
 	plexClient       *plex.Client
 	traktClient      *trakt.Client
 	watchlistService *watchlist.Service
+	epgRefresher     EPGRefresher
+	recordingRunner  RecordingRunner
+	housekeeper      DebridHousekeeper
 
 	// Runtime state
 	mu      sync.RWMutex
@@ -60,6 +80,21 @@ func NewService(
 	}
 }
 
+// SetEPGRefresher sets the Live TV guide used by EPG refresh tasks
+func (s *Service) SetEPGRefresher(refresher EPGRefresher) {
+	s.epgRefresher = refresher
+}
+
+// SetDebridHousekeeper sets the housekeeper used by debrid cleanup tasks
+func (s *Service) SetDebridHousekeeper(housekeeper DebridHousekeeper) {
+	s.housekeeper = housekeeper
+}
+
+// SetRecordingRunner sets the DVR whose due recordings are started on every scheduler tick
+func (s *Service) SetRecordingRunner(runner RecordingRunner) {
+	s.recordingRunner = runner
+}
+
 // Start begins the scheduler background loop
 func (s *Service) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -130,6 +165,7 @@ func (s *Service) schedulerLoop() {
 
 	// Run check immediately on start
 	s.checkAndRunTasks()
+	s.startDueRecordings(checkInterval)
 
 	for {
 		select {
@@ -137,10 +173,22 @@ func (s *Service) schedulerLoop() {
 			return
 		case <-ticker.C:
 			s.checkAndRunTasks()
+			s.startDueRecordings(checkInterval)
 		}
 	}
 }
 
+// startDueRecordings arms recordings starting before the tick after next, so a recording
+// waits for its exact start time rather than the scheduler's check interval.
+func (s *Service) startDueRecordings(checkInterval time.Duration) {
+	if s.recordingRunner == nil {
+		return
+	}
+	if armed := s.recordingRunner.StartDueRecordings(2 * checkInterval); armed > 0 {
+		log.Printf("[scheduler] Armed %d Live TV recording(s)", armed)
+	}
+}
+
 // checkAndRunTasks checks all enabled tasks and runs those that are due
 func (s *Service) checkAndRunTasks() {
 	settings, err := s.configManager.Load()
@@ -231,6 +279,10 @@ func (s *Service) executeTask(task config.ScheduledTask) {
 		result, err = s.executePlexWatchlistSync(task)
 	case config.ScheduledTaskTypeTraktListSync:
 		result, err = s.executeTraktListSync(task)
+	case config.ScheduledTaskTypeEPGRefresh:
+		result, err = s.executeEPGRefresh()
+	case config.ScheduledTaskTypeDebridCleanup:
+		result, err = s.executeDebridCleanup(task)
 	default:
 		log.Printf("[scheduler] Unknown task type: %s", task.Type)
 		return
@@ -269,6 +321,14 @@ func (s *Service) updateTaskStatus(taskID string, err error, result SyncResult)
 				settings.ScheduledTasks.Tasks[i].LastStatus = config.ScheduledTaskStatusError
 				settings.ScheduledTasks.Tasks[i].LastError = err.Error()
 				log.Printf("[scheduler] Task %s failed: %v", taskID, err)
+				task := settings.ScheduledTasks.Tasks[i]
+				events.Publish(events.Event{
+					Type:    events.TaskFailed,
+					Title:   "Scheduled task failed",
+					Message: fmt.Sprintf("Task %q failed: %v", task.Name, err),
+					Fields:  map[string]string{"task": task.Name, "type": string(task.Type), "error": err.Error()},
+					Key:     taskID,
+				})
 			} else {
 				settings.ScheduledTasks.Tasks[i].LastStatus = config.ScheduledTaskStatusSuccess
 				settings.ScheduledTasks.Tasks[i].LastError = ""
@@ -345,6 +405,74 @@ func (s *Service) IsTaskRunning(taskID string) bool {
 	return s.taskRunning[taskID]
 }
 
+// executeEPGRefresh reloads the Live TV guide. The download itself is skipped while the
+// cached guide is younger than the playlist cache TTL.
+func (s *Service) executeEPGRefresh() (SyncResult, error) {
+	if s.epgRefresher == nil {
+		return SyncResult{}, errors.New("live TV guide not configured")
+	}
+
+	ctx := context.Background()
+	s.mu.RLock()
+	if s.ctx != nil {
+		ctx = s.ctx
+	}
+	s.mu.RUnlock()
+
+	count, err := s.epgRefresher.RefreshEPG(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return SyncResult{Count: count}, nil
+}
+
+// executeDebridCleanup removes torrents strmr added to debrid accounts that weren't played
+// within unplayedDays or are stuck downloading for stuckHours.
+func (s *Service) executeDebridCleanup(task config.ScheduledTask) (SyncResult, error) {
+	if s.housekeeper == nil {
+		return SyncResult{}, errors.New("debrid housekeeping not configured")
+	}
+
+	unplayedDays := 7
+	if v, err := strconv.Atoi(task.Config["unplayedDays"]); err == nil && v > 0 {
+		unplayedDays = v
+	}
+	stuckHours := 6
+	if v, err := strconv.Atoi(task.Config["stuckHours"]); err == nil && v > 0 {
+		stuckHours = v
+	}
+	dryRun := task.Config["dryRun"] == "true"
+
+	ctx := context.Background()
+	s.mu.RLock()
+	if s.ctx != nil {
+		ctx = s.ctx
+	}
+	s.mu.RUnlock()
+
+	report, err := s.housekeeper.CleanupTorrents(ctx, debrid.CleanupOptions{
+		UnplayedFor: time.Duration(unplayedDays) * 24 * time.Hour,
+		StuckFor:    time.Duration(stuckHours) * time.Hour,
+		DryRun:      dryRun,
+	})
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	result := SyncResult{Count: report.Removed, DryRun: dryRun}
+	if dryRun {
+		result.Count = len(report.Candidates)
+		for _, c := range report.Candidates {
+			result.ToRemove = append(result.ToRemove, config.DryRunItem{
+				Name:      fmt.Sprintf("%s (%s)", c.Name, c.Reason),
+				MediaType: c.Provider,
+				ID:        c.TorrentID,
+			})
+		}
+	}
+	return result, nil
+}
+
 // executePlexWatchlistSync syncs a Plex watchlist to/from a profile
 func (s *Service) executePlexWatchlistSync(task config.ScheduledTask) (SyncResult, error) {
 	plexAccountID := task.Config["plexAccountId"]
@@ -0,0 +1,161 @@
+package season_packs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+const (
+	// pruneInterval is how often recording a pack also removes unused ones.
+	pruneInterval = time.Hour
+
+	// Packs that nothing was played from for this long are forgotten
+	unusedRetention = 30 * 24 * time.Hour
+)
+
+var ErrRepositoryRequired = errors.New("season pack repository not provided")
+
+// Service remembers the season and complete packs resolved for each series, with their file
+// maps, so later episodes are played from the torrent or usenet directory that is already
+// there instead of searching and adding the release again.
+type Service struct {
+	repo *database.SeasonPackRepository
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewService creates a season pack index backed by the queue database.
+func NewService(repo *database.SeasonPackRepository) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo, now: time.Now}, nil
+}
+
+// Record remembers a resolved pack for every season it has episodes of.
+func (s *Service) Record(titleID string, pack models.SeasonPack) {
+	titleID = strings.ToLower(strings.TrimSpace(titleID))
+	id := packID(pack)
+	if titleID == "" || id == "" || len(pack.Files) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(pack)
+	if err != nil {
+		log.Printf("[season-packs] failed to encode pack %q: %v", pack.Release.Title, err)
+		return
+	}
+
+	now := s.now()
+	for _, season := range pack.Seasons() {
+		entry := &database.SeasonPackEntry{
+			TitleID:      titleID,
+			SeasonNumber: season,
+			ServiceType:  string(pack.ServiceType),
+			Provider:     pack.Provider,
+			PackID:       id,
+			InfoHash:     pack.InfoHash,
+			ReleaseTitle: pack.Release.Title,
+			Pack:         string(data),
+			CreatedAt:    now,
+			LastUsedAt:   now,
+		}
+		if err := s.repo.UpsertSeasonPack(entry); err != nil {
+			log.Printf("[season-packs] failed to remember %q for %s season %d: %v", pack.Release.Title, titleID, season, err)
+			return
+		}
+	}
+	log.Printf("[season-packs] remembered %q (%d episodes) for %s seasons %v", pack.Release.Title, len(pack.Files), titleID, pack.Seasons())
+
+	s.mu.Lock()
+	due := now.Sub(s.lastPrune) >= pruneInterval
+	if due {
+		s.lastPrune = now
+	}
+	s.mu.Unlock()
+	if due {
+		if _, err := s.Prune(); err != nil {
+			log.Printf("[season-packs] prune failed: %v", err)
+		}
+	}
+}
+
+// Find returns the pack holding an episode of a series and the episode's file in it.
+func (s *Service) Find(titleID string, season, episode int) (*models.SeasonPack, models.PackFile, bool) {
+	titleID = strings.ToLower(strings.TrimSpace(titleID))
+	if titleID == "" || season <= 0 || episode <= 0 {
+		return nil, models.PackFile{}, false
+	}
+
+	entry, err := s.repo.GetSeasonPack(titleID, season)
+	if err != nil {
+		log.Printf("[season-packs] lookup failed for %s season %d: %v", titleID, season, err)
+		return nil, models.PackFile{}, false
+	}
+	if entry == nil {
+		return nil, models.PackFile{}, false
+	}
+
+	var pack models.SeasonPack
+	if err := json.Unmarshal([]byte(entry.Pack), &pack); err != nil {
+		log.Printf("[season-packs] failed to decode pack for %s season %d: %v", titleID, season, err)
+		return nil, models.PackFile{}, false
+	}
+	file, ok := pack.File(season, episode)
+	if !ok {
+		return nil, models.PackFile{}, false
+	}
+
+	if err := s.repo.TouchSeasonPack(titleID, season, s.now()); err != nil {
+		log.Printf("[season-packs] %v", err)
+	}
+	return &pack, file, true
+}
+
+// Forget drops a pack that can no longer be played from, e.g. because the torrent was
+// removed from the debrid account.
+func (s *Service) Forget(pack models.SeasonPack) {
+	removed, err := s.repo.DeleteSeasonPacks(string(pack.ServiceType), pack.Provider, packID(pack))
+	if err != nil {
+		log.Printf("[season-packs] failed to forget %q: %v", pack.Release.Title, err)
+		return
+	}
+	log.Printf("[season-packs] forgot %q (%d seasons)", pack.Release.Title, removed)
+}
+
+// Prune removes packs that nothing was played from recently.
+func (s *Service) Prune() (int64, error) {
+	return s.repo.DeleteSeasonPacksUnusedSince(s.now().Add(-unusedRetention))
+}
+
+// EpisodeRelease returns a copy of a pack release targeted at one of its episodes, the way
+// search results for that episode are, so the right file is picked when it is resolved.
+func EpisodeRelease(release models.NZBResult, season, episode int) models.NZBResult {
+	attrs := make(map[string]string, len(release.Attributes)+3)
+	for k, v := range release.Attributes {
+		attrs[k] = v
+	}
+	attrs["targetSeason"] = fmt.Sprintf("%d", season)
+	attrs["targetEpisode"] = fmt.Sprintf("%d", episode)
+	attrs["targetEpisodeCode"] = fmt.Sprintf("S%02dE%02d", season, episode)
+	release.Attributes = attrs
+	return release
+}
+
+// packID is the torrent ID of a debrid pack or the directory of a usenet pack.
+func packID(pack models.SeasonPack) string {
+	if pack.ServiceType == models.ServiceTypeDebrid {
+		return strings.TrimSpace(pack.TorrentID)
+	}
+	return strings.TrimSpace(pack.Directory)
+}
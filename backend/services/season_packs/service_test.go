@@ -0,0 +1,101 @@
+package season_packs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/internal/database"
+	"novastream/models"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(t.TempDir(), "queue.db")})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	svc, err := NewService(database.NewSeasonPackRepository(db.Connection()))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return svc
+}
+
+func completePack() models.SeasonPack {
+	return models.SeasonPack{
+		ServiceType: models.ServiceTypeDebrid,
+		Provider:    "realdebrid",
+		TorrentID:   "TID",
+		Release:     models.NZBResult{Title: "Show.Complete.1080p", Attributes: map[string]string{"infoHash": "abc"}},
+		Files: []models.PackFile{
+			{ID: "1", Path: "/S01E01.mkv", SeasonNumber: 1, EpisodeNumber: 1},
+			{ID: "2", Path: "/S01E02.mkv", SeasonNumber: 1, EpisodeNumber: 2},
+			{ID: "3", Path: "/S02E01.mkv", SeasonNumber: 2, EpisodeNumber: 1},
+		},
+	}
+}
+
+func TestRecordAndFind(t *testing.T) {
+	svc := newTestService(t)
+	svc.Record("tmdb:tv:1", completePack())
+
+	pack, file, ok := svc.Find("TMDB:TV:1", 2, 1)
+	if !ok {
+		t.Fatal("expected the pack to be found for season 2")
+	}
+	if pack.TorrentID != "TID" || file.ID != "3" {
+		t.Fatalf("unexpected pack or file: %+v %+v", pack, file)
+	}
+	if _, _, ok := svc.Find("tmdb:tv:1", 1, 5); ok {
+		t.Fatal("episodes missing from the pack must not be found")
+	}
+	if _, _, ok := svc.Find("tmdb:tv:2", 1, 1); ok {
+		t.Fatal("packs must not be shared between titles")
+	}
+}
+
+func TestForgetRemovesAllSeasons(t *testing.T) {
+	svc := newTestService(t)
+	svc.Record("tmdb:tv:1", completePack())
+	svc.Forget(completePack())
+
+	for _, season := range []int{1, 2} {
+		if _, _, ok := svc.Find("tmdb:tv:1", season, 1); ok {
+			t.Fatalf("season %d should be forgotten", season)
+		}
+	}
+}
+
+func TestPruneRemovesUnusedPacks(t *testing.T) {
+	svc := newTestService(t)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	svc.Record("tmdb:tv:1", completePack())
+
+	svc.now = func() time.Time { return now.Add(unusedRetention + time.Hour) }
+	removed, err := svc.Prune()
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 seasons pruned, got %d", removed)
+	}
+	if _, _, ok := svc.Find("tmdb:tv:1", 1, 1); ok {
+		t.Fatal("pruned pack should not be found")
+	}
+}
+
+func TestEpisodeReleaseTargetsEpisode(t *testing.T) {
+	release := completePack().Release
+	targeted := EpisodeRelease(release, 1, 2)
+	if targeted.Attributes["targetEpisodeCode"] != "S01E02" || targeted.Attributes["infoHash"] != "abc" {
+		t.Fatalf("unexpected attributes: %v", targeted.Attributes)
+	}
+	if _, ok := release.Attributes["targetEpisodeCode"]; ok {
+		t.Fatal("the original release must not be modified")
+	}
+}
@@ -0,0 +1,90 @@
+package stream_history
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+// pruneInterval is how often recording a session also removes sessions past retention.
+const pruneInterval = time.Hour
+
+var ErrRepositoryRequired = errors.New("stream session repository not provided")
+
+// Service persists finished playback sessions so the admin can review what was watched,
+// how it was delivered and why it failed.
+type Service struct {
+	repo       *database.StreamSessionRepository
+	cfgManager *config.Manager
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewService creates a stream history service backed by the queue database.
+func NewService(repo *database.StreamSessionRepository, cfgManager *config.Manager) (*Service, error) {
+	if repo == nil {
+		return nil, ErrRepositoryRequired
+	}
+	return &Service{repo: repo, cfgManager: cfgManager}, nil
+}
+
+// Record stores a finished session and prunes expired ones at most once an hour.
+func (s *Service) Record(session *database.StreamSession) error {
+	if err := s.repo.AddStreamSession(session); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	due := time.Since(s.lastPrune) >= pruneInterval
+	if due {
+		s.lastPrune = time.Now()
+	}
+	s.mu.Unlock()
+
+	if due {
+		if _, err := s.Prune(); err != nil {
+			log.Printf("[stream-history] prune failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// List returns sessions matching the filter, newest first, and the total match count.
+func (s *Service) List(filter database.StreamSessionFilter) ([]*database.StreamSession, int, error) {
+	return s.repo.ListStreamSessions(filter)
+}
+
+// Stats aggregates sessions per "profile" or per "client".
+func (s *Service) Stats(groupBy string, filter database.StreamSessionFilter) ([]*database.StreamSessionStats, error) {
+	return s.repo.GetStreamSessionStats(groupBy, filter)
+}
+
+// Clear removes all recorded sessions.
+func (s *Service) Clear() error {
+	_, err := s.repo.DeleteStreamSessionsBefore(time.Time{})
+	return err
+}
+
+// Prune removes sessions older than the configured retention.
+func (s *Service) Prune() (int64, error) {
+	days := config.DefaultSettings().StreamHistory.RetentionDays
+	if s.cfgManager != nil {
+		if settings, err := s.cfgManager.Load(); err == nil {
+			days = settings.StreamHistory.RetentionDays
+		}
+	}
+
+	removed, err := s.repo.DeleteStreamSessionsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		log.Printf("[stream-history] pruned %d sessions older than %d days", removed, days)
+	}
+	return removed, nil
+}
@@ -0,0 +1,124 @@
+package stream_history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"novastream/config"
+	"novastream/internal/database"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := database.NewDB(database.Config{DatabasePath: filepath.Join(dir, "queue.db")})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg := config.DefaultSettings()
+	cfg.StreamHistory.RetentionDays = 7
+	mgr := config.NewManager(filepath.Join(dir, "settings.json"))
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("save cfg: %v", err)
+	}
+
+	svc, err := NewService(database.NewStreamSessionRepository(db.Connection()), mgr)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return svc
+}
+
+func TestRecordAndFilterSessions(t *testing.T) {
+	svc := newTestService(t)
+	now := time.Now()
+
+	sessions := []*database.StreamSession{
+		{ProfileID: "p1", ProfileName: "Alex", ClientID: "tv", Title: "Movie", ReleaseName: "Movie.2020.1080p.mkv",
+			Source: "usenet", Delivery: "remux", AudioPlan: "truehd -> aac", BytesServed: 1000, DurationSeconds: 600,
+			Errors:    []string{"Stream contains malformed video data that cannot be processed"},
+			StartedAt: now.Add(-2 * time.Hour), EndedAt: now.Add(-110 * time.Minute)},
+		{ProfileID: "p1", ProfileName: "Alex", ClientID: "phone", Title: "Show", ReleaseName: "Show.S01E01.mkv",
+			Source: "debrid", Delivery: "direct", BytesServed: 500, DurationSeconds: 300,
+			StartedAt: now.Add(-time.Hour), EndedAt: now.Add(-55 * time.Minute)},
+		{ProfileID: "p2", ProfileName: "Sam", ClientIP: "10.0.0.5", Title: "Show", ReleaseName: "Show.S01E02.mkv",
+			Source: "debrid", Delivery: "transcode", BytesServed: 200, DurationSeconds: 60,
+			StartedAt: now.Add(-30 * time.Minute), EndedAt: now.Add(-29 * time.Minute)},
+	}
+	for _, s := range sessions {
+		if err := svc.Record(s); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	list, total, err := svc.List(database.StreamSessionFilter{})
+	if err != nil || total != 3 || len(list) != 3 || list[0].ProfileName != "Sam" {
+		t.Fatalf("unexpected list %v (total=%d, err=%v)", list, total, err)
+	}
+
+	failed, total, err := svc.List(database.StreamSessionFilter{ErrorsOnly: true})
+	if err != nil || total != 1 || failed[0].AudioPlan != "truehd -> aac" || len(failed[0].Errors) != 1 {
+		t.Fatalf("unexpected failed sessions %+v (total=%d, err=%v)", failed, total, err)
+	}
+
+	filtered, total, err := svc.List(database.StreamSessionFilter{ProfileID: "p1", Source: "debrid", Search: "S01E01"})
+	if err != nil || total != 1 || filtered[0].ClientID != "phone" {
+		t.Fatalf("unexpected filtered sessions %+v (total=%d, err=%v)", filtered, total, err)
+	}
+
+	byClient, _, err := svc.List(database.StreamSessionFilter{ClientID: "10.0.0.5"})
+	if err != nil || len(byClient) != 1 || byClient[0].ProfileID != "p2" {
+		t.Fatalf("expected anonymous client to match by IP, got %+v (err=%v)", byClient, err)
+	}
+
+	stats, err := svc.Stats("profile", database.StreamSessionFilter{})
+	if err != nil || len(stats) != 2 {
+		t.Fatalf("unexpected profile stats %+v (err=%v)", stats, err)
+	}
+	if s := stats[0]; s.Key != "p1" || s.Name != "Alex" || s.Sessions != 2 || s.FailedSessions != 1 || s.BytesServed != 1500 || s.DurationSeconds != 900 || s.LastSeenAt.IsZero() {
+		t.Fatalf("unexpected stats for p1 %+v", s)
+	}
+
+	clients, err := svc.Stats("client", database.StreamSessionFilter{})
+	if err != nil || len(clients) != 3 {
+		t.Fatalf("unexpected client stats %+v (err=%v)", clients, err)
+	}
+
+	if _, err := svc.Stats("title", database.StreamSessionFilter{}); err == nil {
+		t.Fatal("expected an error for an unsupported grouping")
+	}
+}
+
+func TestPruneHonoursRetention(t *testing.T) {
+	svc := newTestService(t)
+	now := time.Now()
+
+	old := &database.StreamSession{Title: "Old", StartedAt: now.AddDate(0, 0, -10), EndedAt: now.AddDate(0, 0, -10)}
+	recent := &database.StreamSession{Title: "Recent", StartedAt: now.AddDate(0, 0, -1), EndedAt: now.AddDate(0, 0, -1)}
+	for _, s := range []*database.StreamSession{old, recent} {
+		if err := svc.repo.AddStreamSession(s); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	removed, err := svc.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one pruned session, got %d (err=%v)", removed, err)
+	}
+
+	list, _, _ := svc.List(database.StreamSessionFilter{})
+	if len(list) != 1 || list[0].Title != "Recent" {
+		t.Fatalf("unexpected remaining sessions %+v", list)
+	}
+
+	if err := svc.Clear(); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, total, _ := svc.List(database.StreamSessionFilter{}); total != 0 {
+		t.Fatalf("expected empty history after clear, got %d", total)
+	}
+}
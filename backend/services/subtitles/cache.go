@@ -0,0 +1,61 @@
+package subtitles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrCacheDirRequired = errors.New("subtitle cache directory not provided")
+
+// Cache keeps converted subtitles on disk, grouped by title and language, so a subtitle is
+// only downloaded once no matter how often it is played.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a subtitle cache in dir.
+func NewCache(dir string) (*Cache, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, ErrCacheDirRequired
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create subtitle cache dir: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Get returns a cached subtitle.
+func (c *Cache) Get(query Query, provider, id string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(query, provider, id))
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores a converted subtitle.
+func (c *Cache) Put(query Query, provider, id string, vtt []byte) error {
+	path := c.path(query, provider, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create subtitle cache entry: %w", err)
+	}
+
+	// Write to a temp file first so a concurrent reader never sees a partial subtitle
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, vtt, 0o644); err != nil {
+		return fmt.Errorf("write subtitle cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write subtitle cache entry: %w", err)
+	}
+	return nil
+}
+
+// path is <dir>/<title>/<language>/<provider>-<id>.vtt.
+func (c *Cache) path(query Query, provider, id string) string {
+	return filepath.Join(c.dir, query.contentKey(), sanitizeKey(query.Language), sanitizeKey(provider)+"-"+sanitizeKey(id)+".vtt")
+}
@@ -0,0 +1,155 @@
+package subtitles
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	assTimestamp  = regexp.MustCompile(`^(\d+):(\d{2}):(\d{2})\.(\d{2})$`)
+	assOverrides  = regexp.MustCompile(`\{[^}]*\}`)
+	srtBlockSplit = regexp.MustCompile(`\n{2,}`)
+)
+
+// ToVTT converts a downloaded subtitle file to WebVTT. ASS/SSA files are detected by their
+// section headers, anything else is treated as SRT.
+func ToVTT(data []byte) string {
+	content := decodeText(data)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	if strings.HasPrefix(strings.TrimSpace(content), "WEBVTT") {
+		return content
+	}
+	if strings.Contains(content, "[Script Info]") || strings.Contains(content, "[V4+ Styles]") || strings.Contains(content, "[Events]") {
+		return assToVTT(content)
+	}
+	return srtToVTT(content)
+}
+
+// decodeText returns subtitle bytes as UTF-8. Files without a byte order mark that are not
+// valid UTF-8 are assumed to be Windows-1252, the most common legacy encoding on subtitle
+// sites.
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err == nil {
+			return string(decoded)
+		}
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// srtToVTT drops the cue numbers and switches the millisecond separator to a dot.
+func srtToVTT(content string) string {
+	lines := []string{"WEBVTT", ""}
+
+	for _, block := range srtBlockSplit.Split(strings.TrimSpace(content), -1) {
+		blockLines := strings.Split(strings.TrimSpace(block), "\n")
+		if len(blockLines) < 2 {
+			continue
+		}
+
+		timing := -1
+		for i, line := range blockLines {
+			if strings.Contains(line, "-->") && strings.Contains(line, ",") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 || timing == len(blockLines)-1 {
+			continue
+		}
+
+		lines = append(lines, strings.ReplaceAll(blockLines[timing], ",", "."))
+		lines = append(lines, blockLines[timing+1:]...)
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// assToVTT keeps the dialogue events of an ASS/SSA file, without styling or positioning.
+func assToVTT(content string) string {
+	lines := []string{"WEBVTT", ""}
+
+	inEvents := false
+	var format []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+
+		if lower == "[events]" {
+			inEvents = true
+			continue
+		}
+		if strings.HasPrefix(line, "[") && inEvents {
+			break
+		}
+		if !inEvents {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(lower, "format:"):
+			format = format[:0]
+			for _, field := range strings.Split(line[len("format:"):], ",") {
+				format = append(format, strings.ToLower(strings.TrimSpace(field)))
+			}
+		case strings.HasPrefix(lower, "dialogue:"):
+			if len(format) == 0 {
+				continue
+			}
+			parts := strings.SplitN(line[len("dialogue:"):], ",", len(format))
+			if len(parts) < len(format) {
+				continue
+			}
+			fields := make(map[string]string, len(format))
+			for i, name := range format {
+				fields[name] = strings.TrimSpace(parts[i])
+			}
+
+			start, end, text := fields["start"], fields["end"], fields["text"]
+			if start == "" || end == "" || text == "" {
+				continue
+			}
+			text = assOverrides.ReplaceAllString(text, "")
+			text = strings.NewReplacer(`\N`, "\n", `\n`, "\n").Replace(text)
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+
+			lines = append(lines, assToVTTTimestamp(start)+" --> "+assToVTTTimestamp(end), text, "")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// assToVTTTimestamp converts H:MM:SS.cc to HH:MM:SS.mmm.
+func assToVTTTimestamp(ts string) string {
+	m := assTimestamp.FindStringSubmatch(ts)
+	if m == nil {
+		return ts
+	}
+	var hours int
+	fmt.Sscanf(m[1], "%d", &hours)
+	return fmt.Sprintf("%02d:%s:%s.%s0", hours, m[2], m[3], m[4])
+}
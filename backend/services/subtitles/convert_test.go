@@ -0,0 +1,37 @@
+package subtitles
+
+import "testing"
+
+func TestToVTTFromSRT(t *testing.T) {
+	srt := "\xEF\xBB\xBF1\r\n00:00:01,000 --> 00:00:02,500\r\nHello there.\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nGeneral Kenobi!\r\nYou are a bold one.\r\n"
+	want := "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nHello there.\n\n00:00:03.000 --> 00:00:04.000\nGeneral Kenobi!\nYou are a bold one.\n"
+
+	if got := ToVTT([]byte(srt)); got != want {
+		t.Fatalf("ToVTT() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestToVTTFromASS(t *testing.T) {
+	ass := `[Script Info]
+Title: Test
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,{\an8}First line\NSecond, with comma
+Dialogue: 0,1:02:03.04,1:02:05.00,Default,,0,0,0,,{\pos(10,10)}
+`
+	want := "WEBVTT\n\n00:00:01.500 --> 00:00:03.000\nFirst line\nSecond, with comma\n"
+
+	if got := ToVTT([]byte(ass)); got != want {
+		t.Fatalf("ToVTT() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestToVTTDecodesWindows1252(t *testing.T) {
+	srt := "1\n00:00:01,000 --> 00:00:02,000\nCaf\xe9 \x93quoted\x94\n"
+	want := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nCafé “quoted”\n"
+
+	if got := ToVTT([]byte(srt)); got != want {
+		t.Fatalf("ToVTT() =\n%q\nwant\n%q", got, want)
+	}
+}
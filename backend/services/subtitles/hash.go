@@ -0,0 +1,132 @@
+package subtitles
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"novastream/services/streaming"
+)
+
+// hashChunkSize is how much of the start and the end of a file the OpenSubtitles hash reads.
+const hashChunkSize = 64 * 1024
+
+// MovieHash computes the OpenSubtitles hash of a stream, reading only its first and last
+// 64 KB through the streaming provider. It returns the hash and the file size, which
+// providers match on together.
+func MovieHash(ctx context.Context, streams streaming.Provider, path string) (string, int64, error) {
+	head, size, err := readRange(ctx, streams, path, 0)
+	if err != nil {
+		return "", 0, fmt.Errorf("read start of %s: %w", path, err)
+	}
+	if size < hashChunkSize {
+		return "", 0, fmt.Errorf("%s is too small to hash (%d bytes)", path, size)
+	}
+
+	tail, _, err := readRange(ctx, streams, path, size-hashChunkSize)
+	if err != nil {
+		return "", 0, fmt.Errorf("read end of %s: %w", path, err)
+	}
+
+	return fmt.Sprintf("%016x", movieHash(size, head, tail)), size, nil
+}
+
+// movieHash is the file size plus the sums of the first and last 64 KB read as little-endian
+// 64-bit words, wrapping on overflow.
+func movieHash(size int64, head, tail []byte) uint64 {
+	hash := uint64(size)
+	for _, chunk := range [][]byte{head, tail} {
+		for i := 0; i+8 <= len(chunk); i += 8 {
+			hash += binary.LittleEndian.Uint64(chunk[i:])
+		}
+	}
+	return hash
+}
+
+// readRange reads one hash chunk starting at offset and returns it with the total size of
+// the file.
+func readRange(ctx context.Context, streams streaming.Provider, path string, offset int64) ([]byte, int64, error) {
+	resp, err := streams.Stream(ctx, streaming.Request{
+		Path:        path,
+		Method:      http.MethodGet,
+		RangeHeader: fmt.Sprintf("bytes=%d-%d", offset, offset+hashChunkSize-1),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Close()
+
+	size := int64(-1)
+	switch resp.Status {
+	case http.StatusPartialContent:
+		size = contentRangeSize(resp.Headers.Get("Content-Range"))
+	case http.StatusOK, 0:
+		if offset > 0 {
+			return nil, 0, fmt.Errorf("stream does not support range requests")
+		}
+		size = resp.ContentLength
+	default:
+		return nil, 0, fmt.Errorf("unexpected stream status %d", resp.Status)
+	}
+	if size < 0 {
+		return nil, 0, fmt.Errorf("stream did not report its size")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, hashChunkSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	if int64(len(data)) < hashChunkSize && offset+int64(len(data)) < size {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return data, size, nil
+}
+
+// urlStreams reads pre-resolved external streams, such as debrid CDN links and addon URLs,
+// which the streaming provider doesn't serve, with plain HTTP range requests.
+type urlStreams struct {
+	client *http.Client
+}
+
+func (u urlStreams) Stream(ctx context.Context, req streaming.Request) (*streaming.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.RangeHeader != "" {
+		httpReq.Header.Set("Range", req.RangeHeader)
+	}
+	resp, err := u.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	return &streaming.Response{
+		Body:          resp.Body,
+		Headers:       resp.Header,
+		Status:        resp.StatusCode,
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// isURL reports whether a stream path is an external URL rather than a provider path.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// contentRangeSize returns the total size of a "bytes start-end/size" Content-Range header,
+// or -1 when it is missing or unknown.
+func contentRangeSize(header string) int64 {
+	idx := strings.LastIndex(header, "/")
+	if idx < 0 {
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(header[idx+1:]), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
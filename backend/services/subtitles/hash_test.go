@@ -0,0 +1,110 @@
+package subtitles
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"novastream/services/streaming"
+)
+
+// rangeStreamer serves byte ranges of an in-memory file like the usenet and debrid providers.
+type rangeStreamer struct {
+	data     []byte
+	requests []string
+}
+
+func (s *rangeStreamer) Stream(_ context.Context, req streaming.Request) (*streaming.Response, error) {
+	s.requests = append(s.requests, req.RangeHeader)
+
+	var start, end int64
+	if _, err := fmt.Sscanf(req.RangeHeader, "bytes=%d-%d", &start, &end); err != nil {
+		return nil, err
+	}
+	size := int64(len(s.data))
+	if end >= size {
+		end = size - 1
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	return &streaming.Response{
+		Body:          io.NopCloser(bytes.NewReader(s.data[start : end+1])),
+		Headers:       headers,
+		Status:        http.StatusPartialContent,
+		ContentLength: end - start + 1,
+	}, nil
+}
+
+func TestMovieHashReadsOnlyTheEnds(t *testing.T) {
+	// 1 MB file whose 64-bit words are all 1 at the start and 2 at the end
+	data := make([]byte, 1<<20)
+	for i := 0; i < hashChunkSize; i += 8 {
+		binary.LittleEndian.PutUint64(data[i:], 1)
+		binary.LittleEndian.PutUint64(data[len(data)-hashChunkSize+i:], 2)
+	}
+	streamer := &rangeStreamer{data: data}
+
+	hash, size, err := MovieHash(context.Background(), streamer, "/movie.mkv")
+	if err != nil {
+		t.Fatalf("MovieHash: %v", err)
+	}
+	if size != int64(len(data)) {
+		t.Fatalf("size = %d, want %d", size, len(data))
+	}
+	words := uint64(hashChunkSize / 8)
+	want := fmt.Sprintf("%016x", uint64(len(data))+words*1+words*2)
+	if hash != want {
+		t.Fatalf("hash = %s, want %s", hash, want)
+	}
+
+	wantRequests := []string{"bytes=0-65535", fmt.Sprintf("bytes=%d-%d", len(data)-hashChunkSize, len(data)-1)}
+	if fmt.Sprint(streamer.requests) != fmt.Sprint(wantRequests) {
+		t.Fatalf("requests = %v, want %v", streamer.requests, wantRequests)
+	}
+}
+
+func TestMovieHashWrapsOnOverflow(t *testing.T) {
+	head := make([]byte, 16)
+	binary.LittleEndian.PutUint64(head, ^uint64(0))
+	binary.LittleEndian.PutUint64(head[8:], 2)
+
+	if got := movieHash(0, head, nil); got != 1 {
+		t.Fatalf("movieHash = %d, want 1", got)
+	}
+}
+
+func TestMovieHashRejectsSmallFiles(t *testing.T) {
+	streamer := &rangeStreamer{data: make([]byte, 1000)}
+	if _, _, err := MovieHash(context.Background(), streamer, "/sample.mkv"); err == nil {
+		t.Fatal("expected an error for a file smaller than a hash chunk")
+	}
+}
+
+func TestMovieHashOfExternalURL(t *testing.T) {
+	data := make([]byte, 1<<20)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "movie.mkv", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	want, _, err := MovieHash(context.Background(), &rangeStreamer{data: data}, "/movie.mkv")
+	if err != nil {
+		t.Fatalf("MovieHash: %v", err)
+	}
+	got, size, err := MovieHash(context.Background(), urlStreams{client: server.Client()}, server.URL+"/movie.mkv?token=abc")
+	if err != nil {
+		t.Fatalf("MovieHash of URL: %v", err)
+	}
+	if got != want || size != int64(len(data)) {
+		t.Fatalf("hash = %s (size %d), want %s (size %d)", got, size, want, len(data))
+	}
+}
@@ -0,0 +1,408 @@
+package subtitles
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	openSubtitlesBaseURL   = "https://api.opensubtitles.com/api/v1"
+	openSubtitlesUserAgent = "strmr v1.0"
+
+	// The API allows 5 requests per second per IP
+	openSubtitlesMinInterval = 250 * time.Millisecond
+
+	// Login tokens are valid for 24 hours; renew them a little early
+	openSubtitlesTokenTTL = 23 * time.Hour
+
+	// Longest Retry-After honoured before giving up on a rate-limited request
+	openSubtitlesMaxRetryWait = 10 * time.Second
+	openSubtitlesMaxRetries   = 2
+)
+
+// OpenSubtitles is a client for the OpenSubtitles.com REST API. Searching only needs the API
+// key; logging in raises the daily download quota. The login token is reused until it
+// expires or is rejected.
+type OpenSubtitles struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	username   string
+	password   string
+	now        func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenURL    string // Per-account API host returned by login
+	tokenExpiry time.Time
+
+	rateMu      sync.Mutex
+	nextRequest time.Time
+}
+
+// NewOpenSubtitles creates an OpenSubtitles.com client. username and password are optional.
+func NewOpenSubtitles(apiKey, username, password string) *OpenSubtitles {
+	return &OpenSubtitles{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    openSubtitlesBaseURL,
+		apiKey:     strings.TrimSpace(apiKey),
+		username:   strings.TrimSpace(username),
+		password:   password,
+		now:        time.Now,
+	}
+}
+
+// Name identifies the provider.
+func (o *OpenSubtitles) Name() string {
+	return "opensubtitles"
+}
+
+type openSubtitlesSearchResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Language        string `json:"language"`
+			DownloadCount   int    `json:"download_count"`
+			HearingImpaired bool   `json:"hearing_impaired"`
+			Release         string `json:"release"`
+			URL             string `json:"url"`
+			MovieHashMatch  bool   `json:"moviehash_match"`
+			Files           []struct {
+				FileID   int64  `json:"file_id"`
+				FileName string `json:"file_name"`
+			} `json:"files"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// Search finds subtitles by IMDB ID (or title when there is none), flagging those made for
+// the file with the query's movie hash.
+func (o *OpenSubtitles) Search(ctx context.Context, query Query) ([]Result, error) {
+	params := url.Values{}
+	params.Set("languages", strings.ToLower(query.Language))
+	if imdb := imdbNumber(query.ImdbID); imdb != "" {
+		if query.IsEpisode() {
+			params.Set("parent_imdb_id", imdb)
+		} else {
+			params.Set("imdb_id", imdb)
+		}
+	} else {
+		params.Set("query", strings.ToLower(strings.TrimSpace(query.Title)))
+		if query.Year > 0 {
+			params.Set("year", strconv.Itoa(query.Year))
+		}
+	}
+	if query.IsEpisode() {
+		params.Set("season_number", strconv.Itoa(query.Season))
+		params.Set("episode_number", strconv.Itoa(query.Episode))
+	}
+	if query.MovieHash != "" {
+		params.Set("moviehash", query.MovieHash)
+	}
+
+	var resp openSubtitlesSearchResponse
+	// Encode sorts the parameters, which the API expects to avoid redirects
+	if err := o.do(ctx, http.MethodGet, "/subtitles?"+params.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(resp.Data))
+	for _, item := range resp.Data {
+		attrs := item.Attributes
+		if len(attrs.Files) == 0 {
+			continue
+		}
+		release := attrs.Release
+		if release == "" {
+			release = attrs.Files[0].FileName
+		}
+		results = append(results, Result{
+			ID:              strconv.FormatInt(attrs.Files[0].FileID, 10),
+			Provider:        o.Name(),
+			Language:        attrs.Language,
+			Release:         release,
+			Downloads:       attrs.DownloadCount,
+			HearingImpaired: attrs.HearingImpaired,
+			PageLink:        attrs.URL,
+			HashMatch:       attrs.MovieHashMatch,
+		})
+	}
+	return results, nil
+}
+
+// Download requests a download link for a subtitle file and fetches it.
+func (o *OpenSubtitles) Download(ctx context.Context, id string) ([]byte, error) {
+	fileID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opensubtitles file id %q", id)
+	}
+
+	var link struct {
+		Link      string `json:"link"`
+		Remaining int    `json:"remaining"`
+		Message   string `json:"message"`
+	}
+	if err := o.do(ctx, http.MethodPost, "/download", map[string]int64{"file_id": fileID}, &link); err != nil {
+		return nil, err
+	}
+	if link.Link == "" {
+		return nil, fmt.Errorf("opensubtitles returned no download link: %s", link.Message)
+	}
+	log.Printf("[subtitles] opensubtitles download of file %d, %d downloads left today", fileID, link.Remaining)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.Link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch subtitle: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch subtitle: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Login checks the credentials and caches the token for later requests.
+func (o *OpenSubtitles) Login(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.loginLocked(ctx)
+}
+
+func (o *OpenSubtitles) loginLocked(ctx context.Context) error {
+	if o.username == "" || o.password == "" {
+		return errors.New("opensubtitles username and password are required to log in")
+	}
+
+	payload, _ := json.Marshal(map[string]string{"username": o.username, "password": o.password})
+	resp, err := o.send(ctx, http.MethodPost, o.baseURL+"/login", payload, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("invalid opensubtitles username or password")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return apiError("login", resp)
+	}
+
+	var login struct {
+		Token   string `json:"token"`
+		BaseURL string `json:"base_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&login); err != nil {
+		return fmt.Errorf("decode opensubtitles login: %w", err)
+	}
+	if login.Token == "" {
+		return errors.New("opensubtitles login returned no token")
+	}
+
+	o.token = login.Token
+	o.tokenURL = ""
+	if host := strings.Trim(login.BaseURL, "/"); host != "" {
+		if base, err := url.Parse(o.baseURL); err == nil && base.Host != host {
+			base.Host = host
+			o.tokenURL = base.String()
+		}
+	}
+	o.tokenExpiry = o.now().Add(openSubtitlesTokenTTL)
+	return nil
+}
+
+// session returns the token and API host to use, logging in when there is no valid token.
+// Without credentials requests are made anonymously with only the API key.
+func (o *OpenSubtitles) session(ctx context.Context) (token, baseURL string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.username == "" || o.password == "" {
+		return "", o.baseURL, nil
+	}
+	if o.token == "" || !o.now().Before(o.tokenExpiry) {
+		if err := o.loginLocked(ctx); err != nil {
+			return "", "", err
+		}
+	}
+	if o.tokenURL != "" {
+		return o.token, o.tokenURL, nil
+	}
+	return o.token, o.baseURL, nil
+}
+
+// invalidate drops a token the API rejected so the next request logs in again.
+func (o *OpenSubtitles) invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == token {
+		o.token = ""
+		o.tokenURL = ""
+	}
+}
+
+// do sends an API request and decodes the JSON response into out. Rejected tokens are
+// renewed once; rate-limited requests are retried after the wait the API asks for.
+func (o *OpenSubtitles) do(ctx context.Context, method, endpoint string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	relogged := false
+	retries := 0
+	for {
+		token, baseURL, err := o.session(ctx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := o.send(ctx, method, baseURL+endpoint, payload, token)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && token != "" && !relogged:
+			resp.Body.Close()
+			o.invalidate(token)
+			relogged = true
+			continue
+		case resp.StatusCode == http.StatusTooManyRequests:
+			wait := retryAfter(resp.Header)
+			resp.Body.Close()
+			if retries >= openSubtitlesMaxRetries || wait > openSubtitlesMaxRetryWait {
+				return ErrRateLimited
+			}
+			retries++
+			log.Printf("[subtitles] opensubtitles rate limited, retrying in %s", wait)
+			o.delay(wait)
+			continue
+		case resp.StatusCode == http.StatusNotAcceptable && endpoint == "/download":
+			// Daily download quota used up
+			resp.Body.Close()
+			return ErrRateLimited
+		case resp.StatusCode != http.StatusOK:
+			err := apiError(strings.TrimPrefix(strings.SplitN(endpoint, "?", 2)[0], "/"), resp)
+			resp.Body.Close()
+			return err
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(out)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("decode opensubtitles response: %w", err)
+		}
+		return nil
+	}
+}
+
+// send makes one request, spacing requests out to stay under the API's rate limit.
+func (o *OpenSubtitles) send(ctx context.Context, method, target string, payload []byte, token string) (*http.Response, error) {
+	if err := o.throttle(ctx); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Api-Key", o.apiKey)
+	req.Header.Set("User-Agent", openSubtitlesUserAgent)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("opensubtitles request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// throttle waits until the next request slot.
+func (o *OpenSubtitles) throttle(ctx context.Context) error {
+	o.rateMu.Lock()
+	now := o.now()
+	wait := o.nextRequest.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	o.nextRequest = now.Add(wait + openSubtitlesMinInterval)
+	o.rateMu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay pushes the next request slot back, e.g. after the API asked to slow down.
+func (o *OpenSubtitles) delay(wait time.Duration) {
+	o.rateMu.Lock()
+	defer o.rateMu.Unlock()
+	if next := o.now().Add(wait); next.After(o.nextRequest) {
+		o.nextRequest = next
+	}
+}
+
+// retryAfter reads how long to wait from a 429 response, defaulting to one second.
+func retryAfter(header http.Header) time.Duration {
+	for _, name := range []string{"Retry-After", "Ratelimit-Reset", "X-Ratelimit-Reset"} {
+		if value := strings.TrimSpace(header.Get(name)); value != "" {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return time.Second
+}
+
+func apiError(operation string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	var body struct {
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		if body.Message == "" && len(body.Errors) > 0 {
+			body.Message = strings.Join(body.Errors, "; ")
+		}
+		if body.Message != "" {
+			return fmt.Errorf("opensubtitles %s failed (status %d): %s", operation, resp.StatusCode, body.Message)
+		}
+	}
+	return fmt.Errorf("opensubtitles %s failed: status %d", operation, resp.StatusCode)
+}
@@ -0,0 +1,176 @@
+package subtitles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeOpenSubtitlesAPI struct {
+	mu          sync.Mutex
+	logins      int
+	tokens      []string // Authorization headers of non-login requests
+	rejectToken string   // Token answered with 401
+	rateLimited int      // Requests to answer with 429 before succeeding
+	lastQuery   string
+}
+
+func (f *fakeOpenSubtitlesAPI) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if r.URL.Path == "/file/42.srt" {
+			// Download links are plain file URLs
+			w.Write([]byte("1\n00:00:01,000 --> 00:00:02,000\nHi\n"))
+			return
+		}
+		if r.Header.Get("Api-Key") != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path == "/login" {
+			f.logins++
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":    "token-" + string(rune('0'+f.logins)),
+				"base_url": r.Host,
+			})
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		f.tokens = append(f.tokens, auth)
+		if f.rejectToken != "" && auth == "Bearer "+f.rejectToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if f.rateLimited > 0 {
+			f.rateLimited--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/subtitles":
+			f.lastQuery = r.URL.RawQuery
+			w.Write([]byte(`{"data":[{"id":"1","attributes":{"language":"en","download_count":10,"release":"Movie.2020.1080p.WEB","url":"https://example.com/1","moviehash_match":true,"files":[{"file_id":42,"file_name":"movie.srt"}]}},{"id":"2","attributes":{"language":"en","files":[]}}]}`))
+		case "/download":
+			var body struct {
+				FileID int64 `json:"file_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.FileID != 42 {
+				t.Errorf("download file_id = %d, want 42", body.FileID)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"link": "http://" + r.Host + "/file/42.srt", "remaining": 19})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func newTestOpenSubtitles(t *testing.T, api *fakeOpenSubtitlesAPI, username, password string) *OpenSubtitles {
+	server := httptest.NewServer(api.handler(t))
+	t.Cleanup(server.Close)
+
+	client := NewOpenSubtitles("key", username, password)
+	client.baseURL = server.URL
+	return client
+}
+
+func TestOpenSubtitlesSearch(t *testing.T) {
+	api := &fakeOpenSubtitlesAPI{}
+	client := newTestOpenSubtitles(t, api, "", "")
+
+	results, err := client.Search(context.Background(), Query{
+		ImdbID:    "tt0903747",
+		Season:    1,
+		Episode:   2,
+		Language:  "EN",
+		MovieHash: "0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	wantQuery := "episode_number=2&languages=en&moviehash=0123456789abcdef&parent_imdb_id=903747&season_number=1"
+	if api.lastQuery != wantQuery {
+		t.Fatalf("query = %q, want %q", api.lastQuery, wantQuery)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1 (results without files are skipped)", len(results))
+	}
+	want := Result{ID: "42", Provider: "opensubtitles", Language: "en", Release: "Movie.2020.1080p.WEB", Downloads: 10, PageLink: "https://example.com/1", HashMatch: true}
+	if results[0] != want {
+		t.Fatalf("result = %+v, want %+v", results[0], want)
+	}
+	if api.logins != 0 || api.tokens[0] != "" {
+		t.Fatalf("anonymous search should not log in (logins=%d auth=%q)", api.logins, api.tokens[0])
+	}
+}
+
+func TestOpenSubtitlesReusesLoginToken(t *testing.T) {
+	api := &fakeOpenSubtitlesAPI{}
+	client := newTestOpenSubtitles(t, api, "user", "pass")
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Search(context.Background(), Query{Title: "Movie", Language: "en"}); err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+	}
+	data, err := client.Download(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !strings.Contains(string(data), "Hi") {
+		t.Fatalf("Download returned %q", data)
+	}
+
+	if api.logins != 1 {
+		t.Fatalf("logins = %d, want 1", api.logins)
+	}
+	for _, auth := range api.tokens[:3] {
+		if auth != "Bearer token-1" {
+			t.Fatalf("request used %q, want the cached token", auth)
+		}
+	}
+}
+
+func TestOpenSubtitlesRenewsRejectedToken(t *testing.T) {
+	api := &fakeOpenSubtitlesAPI{rejectToken: "token-1"}
+	client := newTestOpenSubtitles(t, api, "user", "pass")
+
+	if _, err := client.Search(context.Background(), Query{Title: "Movie", Language: "en"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if api.logins != 2 {
+		t.Fatalf("logins = %d, want 2", api.logins)
+	}
+	if got := api.tokens[len(api.tokens)-1]; got != "Bearer token-2" {
+		t.Fatalf("retry used %q, want the new token", got)
+	}
+}
+
+func TestOpenSubtitlesRetriesRateLimitedRequests(t *testing.T) {
+	api := &fakeOpenSubtitlesAPI{rateLimited: 1}
+	client := newTestOpenSubtitles(t, api, "", "")
+
+	if _, err := client.Search(context.Background(), Query{Title: "Movie", Language: "en"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(api.tokens) != 2 {
+		t.Fatalf("requests = %d, want 2", len(api.tokens))
+	}
+
+	api.rateLimited = openSubtitlesMaxRetries + 1
+	_, err := client.Search(context.Background(), Query{Title: "Movie", Language: "en"})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+}
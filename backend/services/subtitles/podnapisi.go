@@ -0,0 +1,192 @@
+package subtitles
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	podnapisiBaseURL = "https://www.podnapisi.net/subtitles/"
+
+	// Podnapisi pages its search results; later pages rarely hold anything better
+	podnapisiMaxPages = 3
+)
+
+// Podnapisi is a client for podnapisi.net, which needs no account. It is the fallback when
+// OpenSubtitles is not configured.
+type Podnapisi struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewPodnapisi creates a podnapisi.net client.
+func NewPodnapisi() *Podnapisi {
+	return &Podnapisi{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    podnapisiBaseURL,
+	}
+}
+
+// Name identifies the provider.
+func (p *Podnapisi) Name() string {
+	return "podnapisi"
+}
+
+type podnapisiResults struct {
+	Pagination struct {
+		Current int `xml:"current"`
+		Count   int `xml:"count"`
+	} `xml:"pagination"`
+	Subtitles []struct {
+		PID       string `xml:"pid"`
+		URL       string `xml:"url"`
+		Language  string `xml:"language"`
+		Release   string `xml:"release"`
+		Season    string `xml:"tvSeason"`
+		Episode   string `xml:"tvEpisode"`
+		Flags     string `xml:"flags"`
+		Downloads string `xml:"downloads"`
+	} `xml:"subtitle"`
+}
+
+// Search finds subtitles by title. Podnapisi has no IMDB or hash lookup.
+func (p *Podnapisi) Search(ctx context.Context, query Query) ([]Result, error) {
+	title := strings.TrimSpace(query.Title)
+	if title == "" {
+		return nil, nil
+	}
+
+	params := url.Values{}
+	params.Set("sXML", "1")
+	params.Set("sL", strings.ToLower(query.Language))
+	params.Set("sK", title)
+	if query.Year > 0 {
+		params.Set("sY", strconv.Itoa(query.Year))
+	}
+	if query.IsEpisode() {
+		params.Set("sTS", strconv.Itoa(query.Season))
+		params.Set("sTE", strconv.Itoa(query.Episode))
+	}
+
+	var results []Result
+	for page := 1; page <= podnapisiMaxPages; page++ {
+		if page > 1 {
+			params.Set("page", strconv.Itoa(page))
+		}
+		data, err := p.get(ctx, p.baseURL+"search/old?"+params.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed podnapisiResults
+		if err := xml.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("decode podnapisi results: %w", err)
+		}
+
+		for _, sub := range parsed.Subtitles {
+			if sub.PID == "" {
+				continue
+			}
+			// Title searches also return other episodes of the series
+			if query.IsEpisode() && (atoi(sub.Season) != query.Season || atoi(sub.Episode) != query.Episode) {
+				continue
+			}
+			release := ""
+			if fields := strings.Fields(sub.Release); len(fields) > 0 {
+				release = strings.TrimRight(fields[0], ".")
+			}
+			language := sub.Language
+			if language == "" {
+				language = query.Language
+			}
+			results = append(results, Result{
+				ID:              sub.PID,
+				Provider:        p.Name(),
+				Language:        language,
+				Release:         release,
+				Downloads:       atoi(sub.Downloads),
+				HearingImpaired: strings.Contains(sub.Flags, "n"),
+				PageLink:        sub.URL,
+			})
+		}
+
+		if parsed.Pagination.Current >= parsed.Pagination.Count {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Download fetches a subtitle, which podnapisi serves as a zip archive holding one file.
+func (p *Podnapisi) Download(ctx context.Context, id string) ([]byte, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, "/?#") {
+		return nil, fmt.Errorf("invalid podnapisi subtitle id %q", id)
+	}
+
+	data, err := p.get(ctx, p.baseURL+id+"/download?container=zip")
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open podnapisi archive: %w", err)
+	}
+	for _, file := range archive.File {
+		switch strings.ToLower(path.Ext(file.Name)) {
+		case ".srt", ".ass", ".ssa", ".vtt":
+		default:
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file.Name, err)
+		}
+		return content, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (p *Podnapisi) get(ctx context.Context, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("podnapisi request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	case http.StatusTooManyRequests:
+		return nil, ErrRateLimited
+	default:
+		return nil, fmt.Errorf("podnapisi request failed: status %d", resp.StatusCode)
+	}
+}
+
+func atoi(value string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return n
+}
@@ -0,0 +1,98 @@
+package subtitles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrNotFound    = errors.New("subtitle not found")
+	ErrRateLimited = errors.New("subtitle provider rate limit reached")
+)
+
+// Provider searches and downloads subtitles from one subtitle site.
+type Provider interface {
+	// Name identifies the provider in results and download requests.
+	Name() string
+	Search(ctx context.Context, query Query) ([]Result, error)
+	// Download returns the raw subtitle file (SRT, ASS, ...) of a search result.
+	Download(ctx context.Context, id string) ([]byte, error)
+}
+
+// Query describes what to find subtitles for. MovieHash and FileSize identify the exact
+// release being played when they are known.
+type Query struct {
+	ImdbID    string
+	Title     string
+	Year      int
+	Season    int
+	Episode   int
+	Language  string // ISO 639-1 code, e.g. "en"
+	MovieHash string
+	FileSize  int64
+}
+
+// IsEpisode reports whether the query is for a series episode.
+func (q Query) IsEpisode() bool {
+	return q.Season > 0 && q.Episode > 0
+}
+
+// Result is a single subtitle found by a provider.
+type Result struct {
+	ID              string `json:"id"`
+	Provider        string `json:"provider"`
+	Language        string `json:"language"`
+	Release         string `json:"release"`
+	Downloads       int    `json:"downloads"`
+	HearingImpaired bool   `json:"hearing_impaired"`
+	PageLink        string `json:"page_link"`
+	// HashMatch is set when the subtitle was made for the exact file being played
+	HashMatch bool `json:"hash_match"`
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// contentKey identifies the title a subtitle is for: its IMDB ID, or its name and year when
+// there is none, plus the episode.
+func (q Query) contentKey() string {
+	key := strings.ToLower(strings.TrimSpace(q.ImdbID))
+	if !strings.HasPrefix(key, "tt") {
+		key = strings.ToLower(strings.TrimSpace(q.Title))
+		if q.Year > 0 {
+			key = fmt.Sprintf("%s-%d", key, q.Year)
+		}
+	}
+	if q.IsEpisode() {
+		key = fmt.Sprintf("%s-s%02de%02d", key, q.Season, q.Episode)
+	}
+	return sanitizeKey(key)
+}
+
+// sanitizeKey makes a value safe to use as a cache path component.
+func sanitizeKey(value string) string {
+	value = unsafeKeyChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(value)), "_")
+	value = strings.Trim(value, "._")
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// imdbNumber returns the numeric part of an IMDB ID ("tt0133093" -> "133093"), or "" when the
+// ID is not an IMDB ID.
+func imdbNumber(imdbID string) string {
+	imdbID = strings.ToLower(strings.TrimSpace(imdbID))
+	if !strings.HasPrefix(imdbID, "tt") {
+		return ""
+	}
+	number := strings.TrimLeft(imdbID[2:], "0")
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return number
+}
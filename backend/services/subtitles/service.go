@@ -0,0 +1,190 @@
+package subtitles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"novastream/config"
+	"novastream/services/streaming"
+)
+
+// maxHashEntries bounds the remembered stream hashes; the map is reset when it is full.
+const maxHashEntries = 256
+
+type streamHash struct {
+	hash string
+	size int64
+}
+
+// Service searches the configured subtitle providers, matching on the hash of the stream
+// being played when there is one, and serves downloads from the on-disk cache.
+type Service struct {
+	cfg     *config.Manager
+	streams streaming.Provider
+	urls    streaming.Provider // external URLs the streaming provider doesn't serve
+	cache   *Cache
+
+	mu            sync.Mutex
+	openSubtitles *OpenSubtitles
+	podnapisi     Provider
+	hashes        map[string]streamHash // stream path -> hash
+}
+
+// NewService creates a subtitle service. streams may be nil, in which case searches are only
+// matched against streams played from external URLs.
+func NewService(cfg *config.Manager, streams streaming.Provider, cache *Cache) *Service {
+	return &Service{
+		cfg:       cfg,
+		streams:   streams,
+		urls:      urlStreams{client: &http.Client{Timeout: 30 * time.Second}},
+		cache:     cache,
+		podnapisi: NewPodnapisi(),
+		hashes:    make(map[string]streamHash),
+	}
+}
+
+// Search finds subtitles for a query across all providers. When streamPath is set the
+// stream's movie hash is added to the query, and subtitles made for that exact release are
+// listed first. Other results are ordered by downloads.
+func (s *Service) Search(ctx context.Context, query Query, streamPath string) ([]Result, error) {
+	if streamPath = strings.TrimSpace(streamPath); streamPath != "" && query.MovieHash == "" {
+		if hash, size, err := s.hash(ctx, streamPath); err != nil {
+			log.Printf("[subtitles] could not hash %s, searching by title: %v", streamPath, err)
+		} else {
+			query.MovieHash, query.FileSize = hash, size
+		}
+	}
+
+	results := make([]Result, 0)
+	var errs []error
+	for _, provider := range s.providers() {
+		found, err := provider.Search(ctx, query)
+		if err != nil {
+			log.Printf("[subtitles] %s search failed: %v", provider.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		results = append(results, found...)
+	}
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].HashMatch != results[j].HashMatch {
+			return results[i].HashMatch
+		}
+		return results[i].Downloads > results[j].Downloads
+	})
+
+	hashMatches := 0
+	for _, r := range results {
+		if r.HashMatch {
+			hashMatches++
+		}
+	}
+	log.Printf("[subtitles] found %d subtitles (%d for the exact release) for %q %s", len(results), hashMatches, query.Title, query.Language)
+	return results, nil
+}
+
+// Download returns a subtitle as WebVTT, from the cache when it was downloaded before.
+func (s *Service) Download(ctx context.Context, query Query, providerName, id string) ([]byte, error) {
+	if s.cache != nil {
+		if vtt, ok := s.cache.Get(query, providerName, id); ok {
+			log.Printf("[subtitles] serving %s subtitle %s from cache", providerName, id)
+			return vtt, nil
+		}
+	}
+
+	var provider Provider
+	for _, p := range s.providers() {
+		if p.Name() == providerName {
+			provider = p
+			break
+		}
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("subtitle provider %q is not configured", providerName)
+	}
+
+	raw, err := provider.Download(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	vtt := []byte(ToVTT(raw))
+
+	if s.cache != nil {
+		if err := s.cache.Put(query, providerName, id, vtt); err != nil {
+			log.Printf("[subtitles] failed to cache %s subtitle %s: %v", providerName, id, err)
+		}
+	}
+	return vtt, nil
+}
+
+// providers returns the providers to query, OpenSubtitles first when it has an API key.
+// The OpenSubtitles client is kept while its settings are unchanged so its login token is
+// reused across requests.
+func (s *Service) providers() []Provider {
+	var settings config.SubtitleSettings
+	if s.cfg != nil {
+		if loaded, err := s.cfg.Load(); err == nil {
+			settings = loaded.Subtitles
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var providers []Provider
+	apiKey := strings.TrimSpace(settings.OpenSubtitlesAPIKey)
+	if apiKey == "" {
+		s.openSubtitles = nil
+	} else {
+		current := s.openSubtitles
+		if current == nil || current.apiKey != apiKey || current.username != strings.TrimSpace(settings.OpenSubtitlesUsername) || current.password != settings.OpenSubtitlesPassword {
+			s.openSubtitles = NewOpenSubtitles(apiKey, settings.OpenSubtitlesUsername, settings.OpenSubtitlesPassword)
+		}
+		providers = append(providers, s.openSubtitles)
+	}
+	if s.podnapisi != nil {
+		providers = append(providers, s.podnapisi)
+	}
+	return providers
+}
+
+// hash returns the movie hash of a stream, computing it once per path.
+func (s *Service) hash(ctx context.Context, streamPath string) (string, int64, error) {
+	s.mu.Lock()
+	cached, ok := s.hashes[streamPath]
+	s.mu.Unlock()
+	if ok {
+		return cached.hash, cached.size, nil
+	}
+	streams := s.streams
+	if isURL(streamPath) {
+		streams = s.urls
+	}
+	if streams == nil {
+		return "", 0, errors.New("no streaming provider")
+	}
+
+	hash, size, err := MovieHash(ctx, streams, streamPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	s.mu.Lock()
+	if len(s.hashes) >= maxHashEntries {
+		s.hashes = make(map[string]streamHash)
+	}
+	s.hashes[streamPath] = streamHash{hash: hash, size: size}
+	s.mu.Unlock()
+	return hash, size, nil
+}
@@ -0,0 +1,98 @@
+package subtitles
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeProvider struct {
+	results   []Result
+	downloads int
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Search(context.Context, Query) ([]Result, error) {
+	return p.results, nil
+}
+
+func (p *fakeProvider) Download(context.Context, string) ([]byte, error) {
+	p.downloads++
+	return []byte("1\n00:00:01,000 --> 00:00:02,000\nHi\n"), nil
+}
+
+func newTestService(t *testing.T, provider Provider) *Service {
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	svc := NewService(nil, nil, cache)
+	svc.podnapisi = provider
+	return svc
+}
+
+func TestSearchListsHashMatchesFirst(t *testing.T) {
+	provider := &fakeProvider{results: []Result{
+		{ID: "popular", Downloads: 500},
+		{ID: "exact", Downloads: 3, HashMatch: true},
+		{ID: "other", Downloads: 40},
+	}}
+	svc := newTestService(t, provider)
+
+	results, err := svc.Search(context.Background(), Query{Title: "Movie", Language: "en"}, "")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	var ids []string
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	if got, want := len(ids), 3; got != want || ids[0] != "exact" || ids[1] != "popular" || ids[2] != "other" {
+		t.Fatalf("order = %v, want [exact popular other]", ids)
+	}
+}
+
+func TestDownloadIsCachedPerTitleAndLanguage(t *testing.T) {
+	provider := &fakeProvider{}
+	svc := newTestService(t, provider)
+	query := Query{ImdbID: "tt0903747", Season: 1, Episode: 2, Language: "en"}
+
+	for i := 0; i < 2; i++ {
+		vtt, err := svc.Download(context.Background(), query, "fake", "7")
+		if err != nil {
+			t.Fatalf("Download: %v", err)
+		}
+		if want := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nHi\n"; string(vtt) != want {
+			t.Fatalf("Download = %q, want %q", vtt, want)
+		}
+	}
+	if provider.downloads != 1 {
+		t.Fatalf("provider downloads = %d, want 1", provider.downloads)
+	}
+
+	// Another language of the same subtitle ID is a different cache entry
+	query.Language = "de"
+	if _, err := svc.Download(context.Background(), query, "fake", "7"); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if provider.downloads != 2 {
+		t.Fatalf("provider downloads = %d, want 2", provider.downloads)
+	}
+}
+
+func TestContentKey(t *testing.T) {
+	cases := []struct {
+		query Query
+		want  string
+	}{
+		{Query{ImdbID: "tt0133093", Title: "The Matrix"}, "tt0133093"},
+		{Query{ImdbID: "TT0903747", Season: 1, Episode: 2}, "tt0903747-s01e02"},
+		{Query{Title: "The Matrix", Year: 1999}, "the_matrix-1999"},
+		{Query{Title: "../../etc/passwd"}, "etc_passwd"},
+	}
+	for _, tc := range cases {
+		if got := tc.query.contentKey(); got != tc.want {
+			t.Errorf("contentKey(%+v) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
@@ -19,8 +19,6 @@ import (
 	"novastream/config"
 	"novastream/internal/pool"
 	"novastream/models"
-
-	"github.com/javi11/nntppool"
 )
 
 type statClient interface {
@@ -30,6 +28,12 @@ type statClient interface {
 
 type dialerFunc func(ctx context.Context, settings config.UsenetSettings) (statClient, error)
 
+// grabTracker enforces and counts per-indexer NZB download quotas.
+type grabTracker interface {
+	AllowGrab(indexerName string) error
+	RecordGrab(indexerName string, err error)
+}
+
 type Service struct {
 	cfg         *config.Manager
 	httpClient  *http.Client
@@ -37,6 +41,7 @@ type Service struct {
 	poolManager pool.Manager // Connection pool for faster health checks
 	maxSegments int
 	rand        *rand.Rand
+	grabs       grabTracker
 }
 
 const (
@@ -177,7 +182,19 @@ func (s *Service) loadUsenetSettings() (config.Settings, error) {
 	return settings, nil
 }
 
-func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate models.NZBResult) ([]byte, string, error) {
+// SetGrabTracker enables per-indexer grab quotas for NZB downloads.
+func (s *Service) SetGrabTracker(tracker grabTracker) {
+	s.grabs = tracker
+}
+
+func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate models.NZBResult) (data []byte, fileName string, err error) {
+	if s.grabs != nil {
+		if err := s.grabs.AllowGrab(candidate.Indexer); err != nil {
+			return nil, "", err
+		}
+		defer func() { s.grabs.RecordGrab(candidate.Indexer, err) }()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if err != nil {
 		return nil, "", fmt.Errorf("build nzb request: %w", err)
@@ -194,13 +211,12 @@ func (s *Service) fetchNZB(ctx context.Context, downloadURL string, candidate mo
 		return nil, "", fmt.Errorf("download nzb failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err = io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, "", fmt.Errorf("read nzb body: %w", err)
 	}
 
-	fileName := deriveNZBFileName(resp, downloadURL, candidate.Title)
-	return data, fileName, nil
+	return data, deriveNZBFileName(resp, downloadURL, candidate.Title), nil
 }
 
 type nzbDocument struct {
@@ -420,7 +436,8 @@ func (s *Service) checkSegmentsConcurrently(ctx context.Context, segments []stri
 		return nil, nil
 	}
 
-	enabled := filterEnabledUsenetProviders(providers)
+	// Primaries first so backup (block) accounts are only asked about segments primaries lack
+	enabled := config.SortUsenetProviders(filterEnabledUsenetProviders(providers))
 	if len(enabled) == 0 {
 		return nil, fmt.Errorf("no enabled usenet providers configured")
 	}
@@ -432,19 +449,21 @@ func (s *Service) checkSegmentsConcurrently(ctx context.Context, segments []stri
 	return s.checkSegmentsWithDialer(ctx, segments, enabled)
 }
 
-// checkSegmentsWithPool uses the connection pool for faster health checks
+// checkSegmentsWithPool uses the connection pool for faster health checks. Segments are
+// checked against the primary providers only; backups are asked about the segments the
+// primaries reported missing, so busy primaries never push checks onto block accounts.
 func (s *Service) checkSegmentsWithPool(ctx context.Context, segments []string, providers []config.UsenetSettings) ([]string, error) {
-	pool, err := s.poolManager.GetPool()
+	cp, err := s.poolManager.GetPool()
 	if err != nil {
 		return nil, fmt.Errorf("get connection pool: %w", err)
 	}
 
 	var (
-		wg                sync.WaitGroup
-		mu                sync.Mutex
-		retryIDs          []string               // Segments that need retry (connection issues)
-		definitelyMissing []string               // Segments confirmed missing from all providers
-		seen              = make(map[string]struct{})
+		wg             sync.WaitGroup
+		mu             sync.Mutex
+		retryIDs       []string // Segments that need retry (connection issues)
+		primaryMissing []string // Segments no primary provider carries
+		seen           = make(map[string]struct{})
 	)
 
 	// Check all segments concurrently using the pool
@@ -455,7 +474,7 @@ func (s *Service) checkSegmentsWithPool(ctx context.Context, segments []string,
 			defer wg.Done()
 
 			normalizedID := normalizeMessageID(segmentID)
-			code, err := pool.Stat(ctx, normalizedID, nil)
+			found, err := pool.StatPrimaries(ctx, cp, normalizedID, nil)
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -469,31 +488,36 @@ func (s *Service) checkSegmentsWithPool(ctx context.Context, segments []string,
 				if errors.Is(err, context.Canceled) {
 					return
 				}
-				// Check if pool confirmed segment is missing from all providers
-				if errors.Is(err, nntppool.ErrArticleNotFoundInProviders) {
-					// Pool already checked all providers - no need to retry
-					definitelyMissing = append(definitelyMissing, segmentID)
-					return
-				}
-				// Other errors (connection issues) - queue for retry
+				// Connection issues - queue for retry
 				log.Printf("[usenet] warning: failed to check segment %s: %v", segmentID, err)
 				retryIDs = append(retryIDs, segmentID)
 				return
 			}
 
-			if code != 223 {
-				// Non-found response - queue for retry with fresh connections
-				retryIDs = append(retryIDs, segmentID)
+			if !found {
+				primaryMissing = append(primaryMissing, segmentID)
 			}
 		}()
 	}
 
 	wg.Wait()
 
-	// If any segments are definitively missing, fail fast
-	if len(definitelyMissing) > 0 {
-		log.Printf("[usenet] %d segment(s) confirmed missing from all providers by pool", len(definitelyMissing))
-		return definitelyMissing, nil
+	if len(primaryMissing) > 0 {
+		var backups []config.UsenetSettings
+		for _, provider := range providers {
+			if provider.IsBackup() {
+				backups = append(backups, provider)
+			}
+		}
+		if len(backups) == 0 {
+			log.Printf("[usenet] %d segment(s) missing from all providers by pool", len(primaryMissing))
+			return primaryMissing, nil
+		}
+		log.Printf("[usenet] checking %d segment(s) missing from primaries on backup providers", len(primaryMissing))
+		missing, err := s.checkSegmentsWithDialer(ctx, primaryMissing, backups)
+		if err != nil || len(missing) > 0 {
+			return missing, err
+		}
 	}
 
 	// Retry segments that had connection issues
@@ -502,7 +526,7 @@ func (s *Service) checkSegmentsWithPool(ctx context.Context, segments []string,
 	}
 
 	log.Printf("[usenet] retrying %d segment(s) with fresh connections", len(retryIDs))
-	missing, err := s.checkSegmentsWithDialer(ctx, retryIDs, providers)
+	missing, err := s.checkSegmentsWithDialer(ctx, retryIDs, config.SortUsenetProviders(providers))
 	if err != nil {
 		return nil, err
 	}
@@ -529,6 +553,9 @@ func (s *Service) checkSegmentsWithDialer(ctx context.Context, segments []string
 
 			// If this provider has the segment (missing is empty), mark as found
 			if len(missing) == 0 {
+				if provider.IsBackup() {
+					log.Printf("[usenet] segment %s only available on backup provider %s", segment, provider.Name)
+				}
 				segmentFound = true
 				break // No need to check other providers for this segment
 			}
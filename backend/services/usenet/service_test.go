@@ -18,6 +18,7 @@ import (
 	"github.com/javi11/nntppool"
 
 	"novastream/config"
+	"novastream/internal/pool"
 	"novastream/models"
 )
 
@@ -59,6 +60,8 @@ func (s *stubPoolManager) ClearPool() error {
 
 func (s *stubPoolManager) HasPool() bool { return s.pool != nil }
 
+func (s *stubPoolManager) ProviderStats() []pool.ProviderStats { return nil }
+
 type stubPool struct {
 	mu    sync.Mutex
 	stats map[string]struct {
@@ -654,6 +657,60 @@ func TestCheckSegmentsConcurrentlyFallsBackToSecondaryProvider(t *testing.T) {
 	}
 }
 
+func TestCheckSegmentsConcurrentlyChecksBackupTierLast(t *testing.T) {
+	ctx := context.Background()
+	presentID := "present@example"
+	missingID := "fill@example"
+
+	// Backup listed first in config must still be consulted only after the primary
+	providers := []config.UsenetSettings{
+		{Name: "Block", Host: "block.example", Enabled: true, Connections: 1, Tier: config.UsenetTierBackup},
+		{Name: "Primary", Host: "primary.example", Enabled: true, Connections: 1},
+	}
+
+	var (
+		mu         sync.Mutex
+		blockCalls []string
+	)
+	svc := NewService(nil, nil)
+	svc.dialer = func(ctx context.Context, settings config.UsenetSettings) (statClient, error) {
+		switch settings.Host {
+		case "primary.example":
+			return &stubClient{results: map[string]bool{missingID: false}}, nil
+		case "block.example":
+			return &recordingStatClient{onCheck: func(id string) {
+				mu.Lock()
+				blockCalls = append(blockCalls, id)
+				mu.Unlock()
+			}}, nil
+		default:
+			return nil, fmt.Errorf("unexpected host %s", settings.Host)
+		}
+	}
+
+	missing, err := svc.checkSegmentsConcurrently(ctx, []string{presentID, missingID}, providers)
+	if err != nil {
+		t.Fatalf("checkSegmentsConcurrently returned error: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("expected no missing segments, got %v", missing)
+	}
+	if len(blockCalls) != 1 || blockCalls[0] != missingID {
+		t.Fatalf("expected backup to be asked only for %s, got %v", missingID, blockCalls)
+	}
+}
+
+type recordingStatClient struct {
+	onCheck func(id string)
+}
+
+func (r *recordingStatClient) CheckArticle(ctx context.Context, messageID string) (bool, error) {
+	r.onCheck(messageID)
+	return true, nil
+}
+
+func (r *recordingStatClient) Close() error { return nil }
+
 func TestCheckSegmentsWithPoolTrustsArticleNotFoundError(t *testing.T) {
 	t.Skip("Pool is bypassed for health checks due to nntppool v1.5.5 Stat bug")
 	// When the pool returns ErrArticleNotFoundInProviders, we trust that
@@ -204,6 +204,12 @@ func isSettingsEmpty(s models.UserSettings) bool {
 		return false
 	}
 
+	// Check Ranking
+	if s.Ranking != nil && (len(s.Ranking.Criteria) > 0 || len(s.Ranking.CustomFormats) > 0 ||
+		len(s.Ranking.AudioPreference) > 0 || len(s.Ranking.VideoCodecPreference) > 0) {
+		return false
+	}
+
 	return true
 }
 
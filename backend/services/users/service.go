@@ -29,6 +29,7 @@ var (
 	ErrInvalidIconURL     = errors.New("invalid icon URL")
 	ErrIconDownloadFailed = errors.New("failed to download icon")
 	ErrInvalidImageFormat = errors.New("invalid image format, must be PNG or JPG")
+	ErrInvalidRatingLimit = errors.New("unknown rating country or certification")
 )
 
 // Service manages persistence of NovaStream user profiles.
@@ -570,6 +571,45 @@ func (s *Service) SetKidsProfile(id string, isKids bool) (models.User, error) {
 	return user, nil
 }
 
+// SetRatingLimits sets the maximum certifications for a kids profile.
+// Passing nil restores the defaults (US PG / TV-PG).
+func (s *Service) SetRatingLimits(id string, limits *models.ContentRestriction) (models.User, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.User{}, ErrUserNotFound
+	}
+
+	if limits != nil {
+		normalized := models.ContentRestriction{
+			Country:        strings.ToUpper(strings.TrimSpace(limits.Country)),
+			MaxMovieRating: strings.ToUpper(strings.TrimSpace(limits.MaxMovieRating)),
+			MaxTVRating:    strings.ToUpper(strings.TrimSpace(limits.MaxTVRating)),
+		}
+		if !normalized.Validate() {
+			return models.User{}, ErrInvalidRatingLimit
+		}
+		limits = &normalized
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return models.User{}, ErrUserNotFound
+	}
+
+	user.RatingLimits = limits
+	user.UpdatedAt = time.Now().UTC()
+	s.users[id] = user
+
+	if err := s.saveLocked(); err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
 // SetTraktAccountID associates a Trakt account with the user.
 func (s *Service) SetTraktAccountID(id, traktAccountID string) (models.User, error) {
 	id = strings.TrimSpace(id)
@@ -1,6 +1,7 @@
 package users_test
 
 import (
+	"errors"
 	"testing"
 
 	"novastream/models"
@@ -69,3 +70,42 @@ func TestDeleteDefaultUserFails(t *testing.T) {
 		t.Fatalf("expected delete to fail for default user")
 	}
 }
+
+func TestServiceSetRatingLimits(t *testing.T) {
+	svc, err := users.NewService(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	if _, err := svc.SetKidsProfile(models.DefaultUserID, true); err != nil {
+		t.Fatalf("set kids profile returned error: %v", err)
+	}
+
+	user, ok := svc.Get(models.DefaultUserID)
+	if !ok {
+		t.Fatalf("expected default user")
+	}
+	if r := user.ContentRestriction(); r == nil || r.MaxMovieRating != models.DefaultKidsMovieRating {
+		t.Fatalf("expected default kids restriction, got %+v", r)
+	}
+
+	updated, err := svc.SetRatingLimits(models.DefaultUserID, &models.ContentRestriction{Country: "gb", MaxMovieRating: "12a", MaxTVRating: "pg"})
+	if err != nil {
+		t.Fatalf("set rating limits returned error: %v", err)
+	}
+	if r := updated.ContentRestriction(); r == nil || r.Country != "GB" || r.MaxMovieRating != "12A" || r.MaxTVRating != "PG" {
+		t.Fatalf("unexpected restriction after update: %+v", r)
+	}
+
+	if _, err := svc.SetRatingLimits(models.DefaultUserID, &models.ContentRestriction{Country: "US", MaxMovieRating: "15", MaxTVRating: "TV-PG"}); !errors.Is(err, users.ErrInvalidRatingLimit) {
+		t.Fatalf("expected ErrInvalidRatingLimit, got %v", err)
+	}
+
+	if _, err := svc.SetKidsProfile(models.DefaultUserID, false); err != nil {
+		t.Fatalf("set kids profile returned error: %v", err)
+	}
+	user, _ = svc.Get(models.DefaultUserID)
+	if r := user.ContentRestriction(); r != nil {
+		t.Fatalf("expected no restriction for regular profile, got %+v", r)
+	}
+}
@@ -1,9 +1,11 @@
 package filter
 
 import (
+	"fmt"
 	"log"
 	"regexp"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/mozillazg/go-unidecode"
@@ -20,6 +22,10 @@ const (
 
 	// MaxYearDifference is the maximum difference in years allowed for movies
 	MaxYearDifference = 1
+
+	// IDMatchAttribute marks results returned by an IMDB/TVDB ID search, which skip the
+	// title similarity check
+	IDMatchAttribute = "idmatch"
 )
 
 // HDRDVPolicy determines what HDR/DV content to exclude from search results.
@@ -95,6 +101,54 @@ type Options struct {
 	FilterOutTerms      []string               // Terms to filter out from results (case-insensitive match in title)
 	TotalSeriesEpisodes int                    // Deprecated: use EpisodeResolver instead
 	EpisodeResolver     EpisodeCountResolver   // Resolver for accurate episode counts from metadata
+	Rejections          *Rejections            // Optional: records the results that are dropped and why
+}
+
+// Rules that drop a result, as recorded in a Rejection.
+const (
+	RuleFilterOutTerm   = "filter-out-term"
+	RuleTitleSimilarity = "title-similarity"
+	RuleMediaType       = "media-type"
+	RuleYear            = "year"
+	RuleMaxSize         = "max-size"
+	RulePackSize        = "pack-size" // Per-episode size of a pack, from its episode count
+	RuleMaxResolution   = "max-resolution"
+	RuleHDRDVPolicy     = "hdr-dv-policy"
+	RuleMaxResults      = "max-results" // Beyond the number of results asked for, applied by searches
+)
+
+// Rejection is a result that was dropped and the rule that dropped it.
+type Rejection struct {
+	Result models.NZBResult `json:"result"`
+	Rule   string           `json:"rule"`
+	Reason string           `json:"reason"`
+}
+
+// Rejections collects dropped results when a search is explained. It is safe for concurrent
+// use since usenet and debrid results are filtered in parallel. A nil *Rejections records nothing.
+type Rejections struct {
+	mu    sync.Mutex
+	items []Rejection
+}
+
+// Add records a dropped result.
+func (r *Rejections) Add(result models.NZBResult, rule, reason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, Rejection{Result: result, Rule: rule, Reason: reason})
+}
+
+// List returns the recorded rejections in the order they were recorded.
+func (r *Rejections) List() []Rejection {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Rejection(nil), r.items...)
 }
 
 // filteredResult holds a result with its HDR status for sorting
@@ -188,7 +242,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 	log.Printf("[filter] Filtering %d results with expected title=%q, year=%d, mediaType=%s",
 		len(results), opts.ExpectedTitle, opts.ExpectedYear, mediaType)
 
-	// BATCH PARSING: Parse all titles in one Python subprocess call
+	// BATCH PARSING: Parse all titles up front
 	titles := make([]string, len(results))
 	candidateTitles := normalizeCandidateTitles(opts.ExpectedTitle, opts.AlternateTitles)
 
@@ -203,6 +257,10 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 	}
 
 	filtered := make([]filteredResult, 0, len(results))
+	reject := func(result models.NZBResult, rule, reason string) {
+		log.Printf("[filter] Rejecting %q: %s", result.Title, reason)
+		opts.Rejections.Add(result, rule, reason)
+	}
 
 	for i, result := range results {
 		// Check filter out terms first (before parsing)
@@ -212,7 +270,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 			for _, term := range opts.FilterOutTerms {
 				termLower := strings.ToLower(strings.TrimSpace(term))
 				if termLower != "" && strings.Contains(titleLower, termLower) {
-					log.Printf("[filter] Rejecting %q: contains filtered term %q", result.Title, term)
+					reject(result, RuleFilterOutTerm, fmt.Sprintf("contains filtered term %q", term))
 					shouldFilter = true
 					break
 				}
@@ -248,9 +306,10 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 				ref, parsed.Title, titleSim*100)
 		}
 
-		if titleSim < MinTitleSimilarity {
-			log.Printf("[filter] Rejecting %q: title similarity %.2f%% < %.2f%% (parsed title: %q, best match: %q)",
-				result.Title, titleSim*100, MinTitleSimilarity*100, parsed.Title, matchedTitle)
+		// Releases found by an IMDB/TVDB ID search are the right title whatever they're named
+		if titleSim < MinTitleSimilarity && result.Attributes[IDMatchAttribute] == "" {
+			reject(result, RuleTitleSimilarity, fmt.Sprintf("title similarity %.2f%% < %.2f%% (parsed title: %q, best match: %q)",
+				titleSim*100, MinTitleSimilarity*100, parsed.Title, matchedTitle))
 			continue
 		}
 
@@ -263,16 +322,15 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 
 		if opts.IsMovie && hasTVPattern {
 			// Searching for a movie but result has TV show pattern (S01E01, volumes, etc)
-			log.Printf("[filter] Rejecting %q: searching for movie but result has TV pattern (seasons=%v, episodes=%v, volumes=%v)",
-				result.Title, parsed.Seasons, parsed.Episodes, parsed.Volumes)
+			reject(result, RuleMediaType, fmt.Sprintf("searching for movie but result has TV pattern (seasons=%v, episodes=%v, volumes=%v)",
+				parsed.Seasons, parsed.Episodes, parsed.Volumes))
 			continue
 		}
 
 		if !opts.IsMovie && !hasTVPattern && !isCompletePack && !hasEpisodeResolver {
 			// Searching for a TV show but result has no TV indicators, isn't a complete pack,
 			// and we don't have an episode resolver to map files to episodes
-			log.Printf("[filter] Rejecting %q: searching for TV show but result has no season/episode info",
-				result.Title)
+			reject(result, RuleMediaType, "searching for TV show but result has no season/episode info")
 			continue
 		}
 
@@ -281,8 +339,8 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 			if parsed.Year > 0 {
 				yearDiff := abs(opts.ExpectedYear - parsed.Year)
 				if yearDiff > MaxYearDifference {
-					log.Printf("[filter] Rejecting %q: year difference %d > %d (expected: %d, got: %d)",
-						result.Title, yearDiff, MaxYearDifference, opts.ExpectedYear, parsed.Year)
+					reject(result, RuleYear, fmt.Sprintf("year difference %d > %d (expected: %d, got: %d)",
+						yearDiff, MaxYearDifference, opts.ExpectedYear, parsed.Year))
 					continue
 				}
 			} else {
@@ -298,8 +356,8 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 
 			if opts.IsMovie && opts.MaxSizeMovieGB > 0 {
 				if sizeGB > opts.MaxSizeMovieGB {
-					log.Printf("[filter] Rejecting %q: size %.2f GB > %.2f GB limit (movie)",
-						result.Title, sizeGB, opts.MaxSizeMovieGB)
+					reject(result, RuleMaxSize, fmt.Sprintf("size %.2f GB > %.2f GB limit (movie)",
+						sizeGB, opts.MaxSizeMovieGB))
 					continue
 				}
 			} else if !opts.IsMovie && opts.MaxSizeEpisodeGB > 0 {
@@ -310,6 +368,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 				isMultiEpisodePack := len(parsed.Episodes) > 1
 				isPack := isCompletePack || (len(parsed.Seasons) > 0 && len(parsed.Episodes) == 0) || isMultiEpisodePack
 				effectiveSizeGB := sizeGB
+				packEpisodes := 0
 
 				if isPack {
 					var episodeCount int
@@ -323,6 +382,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 					}
 					if episodeCount > 0 {
 						effectiveSizeGB = sizeGB / float64(episodeCount)
+						packEpisodes = episodeCount
 						result.EpisodeCount = episodeCount // Pass to frontend for display
 						log.Printf("[filter] Pack detected: %q - %.2f GB / %d episodes = %.2f GB per episode",
 							result.Title, sizeGB, episodeCount, effectiveSizeGB)
@@ -335,8 +395,13 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 				}
 
 				if effectiveSizeGB > opts.MaxSizeEpisodeGB {
-					log.Printf("[filter] Rejecting %q: size %.2f GB > %.2f GB limit (episode)",
-						result.Title, effectiveSizeGB, opts.MaxSizeEpisodeGB)
+					if packEpisodes > 0 {
+						reject(result, RulePackSize, fmt.Sprintf("size %.2f GB / %d episodes = %.2f GB per episode > %.2f GB limit (episode)",
+							sizeGB, packEpisodes, effectiveSizeGB, opts.MaxSizeEpisodeGB))
+					} else {
+						reject(result, RuleMaxSize, fmt.Sprintf("size %.2f GB > %.2f GB limit (episode)",
+							effectiveSizeGB, opts.MaxSizeEpisodeGB))
+					}
 					continue
 				}
 			}
@@ -360,8 +425,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 			}
 			// Only filter if we can parse both resolutions
 			if maxRes > 0 && parsedRes > 0 && parsedRes > maxRes {
-				log.Printf("[filter] Rejecting %q: resolution %s > %s limit",
-					result.Title, resSource, opts.MaxResolution)
+				reject(result, RuleMaxResolution, fmt.Sprintf("resolution %s > %s limit", resSource, opts.MaxResolution))
 				continue
 			}
 		}
@@ -378,7 +442,7 @@ func Results(results []models.NZBResult, opts Options) []models.NZBResult {
 		case HDRDVPolicyNoExclusion:
 			// Exclude all HDR/DV content - only allow SDR
 			if hasHDR || hasDV {
-				log.Printf("[filter] Rejecting %q: policy excludes HDR/DV content", result.Title)
+				reject(result, RuleHDRDVPolicy, fmt.Sprintf("policy excludes HDR/DV content (%s)", strings.Join(parsed.HDR, ", ")))
 				continue
 			}
 		case HDRDVPolicyIncludeHDR:
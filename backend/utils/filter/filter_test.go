@@ -663,3 +663,67 @@ func TestResults_RegressionMovieVsTVPatterns(t *testing.T) {
 		}
 	})
 }
+
+func TestResults_Rejections(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "The.Matrix.1999.1080p.BluRay.x264-SPARKS"},
+		{Title: "Inception.2010.1080p.BluRay.x264"},
+		{Title: "The.Matrix.2005.1080p.BluRay.x264"},
+		{Title: "The.Matrix.1999.2160p.UHD.BluRay.x265-GRP"},
+		{Title: "The.Matrix.1999.1080p.CAM.x264"},
+		{Title: "The.Matrix.1999.1080p.BluRay.x264-BIG", SizeBytes: 30 * 1024 * 1024 * 1024},
+	}
+
+	rejections := &Rejections{}
+	filtered := Results(results, Options{
+		ExpectedTitle:  "The Matrix",
+		ExpectedYear:   1999,
+		IsMovie:        true,
+		MaxSizeMovieGB: 20,
+		MaxResolution:  "1080p",
+		FilterOutTerms: []string{"CAM"},
+		Rejections:     rejections,
+	})
+	if len(filtered) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(filtered))
+	}
+
+	want := map[string]string{
+		"Inception.2010.1080p.BluRay.x264":          RuleTitleSimilarity,
+		"The.Matrix.2005.1080p.BluRay.x264":         RuleYear,
+		"The.Matrix.1999.2160p.UHD.BluRay.x265-GRP": RuleMaxResolution,
+		"The.Matrix.1999.1080p.CAM.x264":            RuleFilterOutTerm,
+		"The.Matrix.1999.1080p.BluRay.x264-BIG":     RuleMaxSize,
+	}
+	got := rejections.List()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d rejections, got %+v", len(want), got)
+	}
+	for _, rejection := range got {
+		if want[rejection.Result.Title] != rejection.Rule {
+			t.Errorf("%s: rule %q, want %q", rejection.Result.Title, rejection.Rule, want[rejection.Result.Title])
+		}
+		if rejection.Reason == "" {
+			t.Errorf("%s: missing reason", rejection.Result.Title)
+		}
+	}
+}
+
+func TestResults_PackSizeRejection(t *testing.T) {
+	results := []models.NZBResult{
+		{Title: "Breaking.Bad.S01.1080p.BluRay.x264-GRP", SizeBytes: 35 * 1024 * 1024 * 1024},
+	}
+
+	rejections := &Rejections{}
+	Results(results, Options{
+		ExpectedTitle:    "Breaking Bad",
+		MaxSizeEpisodeGB: 2,
+		EpisodeResolver:  NewSeriesEpisodeResolver(map[int]int{1: 7}),
+		Rejections:       rejections,
+	})
+
+	got := rejections.List()
+	if len(got) != 1 || got[0].Rule != RulePackSize {
+		t.Fatalf("expected a pack-size rejection, got %+v", got)
+	}
+}
@@ -0,0 +1,501 @@
+package parsett
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// handler extracts one attribute from a release title. Handlers run in order over the
+// title, the same way PTT's do: the earliest match of any handler marks where the title
+// ends, and matches that are removed can't be picked up again by later handlers.
+type handler struct {
+	re *regexp.Regexp
+	// apply stores the match in the result and reports whether it was accepted;
+	// rest is the text after the match
+	apply func(p *ParsedTitle, m []string, rest string) bool
+	// skip reports whether the attribute was already found
+	skip func(p *ParsedTitle) bool
+	// span is the submatch whose position counts as the match, for patterns that need
+	// context around the value (0 = the whole match)
+	span int
+
+	remove        bool // cut the match out of the title
+	skipFromTitle bool // the match doesn't mark the end of the title
+	skipIfFirst   bool // the match can't be the first thing in the title ("2012.2009.1080p")
+	afterTitle    bool // only match past the end of the title found so far (the words also appear in titles)
+}
+
+var (
+	bracketPattern    = regexp.MustCompile(`\[[^\[\]]*\]|\(\s*\)|\{[^{}]*\}`)
+	spacesPattern     = regexp.MustCompile(`\s+`)
+	digitsPattern     = regexp.MustCompile(`\d+`)
+	followingYear     = regexp.MustCompile(`^[ .(\[]+(?:19|20)\d{2}\b`)
+	laterYear         = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	titleTrimCutset   = " -:._([{/\\|,;+~"
+	titleSeparatorSet = " .-_([{"
+)
+
+// notGroupPattern matches tokens after a trailing dash that end an episode range or a
+// quality tag rather than naming a release group: "S02E01-E03", "S01-S03", "Show.2019-1080p"
+var notGroupPattern = re(`^(?:S\d{1,3}(?:E\d{1,4})?|E\d{1,4}|\d{1,4}|\d{3,4}[pi]|[248]k|UHD|HD|SD|DL|Rip|[xh]\.?26[45])$`)
+
+func re(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)` + pattern)
+}
+
+// setString returns an apply func storing a fixed value in a string field.
+func setString(field func(p *ParsedTitle) *string, value string) func(*ParsedTitle, []string, string) bool {
+	return func(p *ParsedTitle, _ []string, _ string) bool {
+		*field(p) = value
+		return true
+	}
+}
+
+// appendUnique returns an apply func adding a fixed value to a list field once.
+func appendUnique(field func(p *ParsedTitle) *[]string, value string) func(*ParsedTitle, []string, string) bool {
+	return func(p *ParsedTitle, _ []string, _ string) bool {
+		list := field(p)
+		for _, existing := range *list {
+			if existing == value {
+				return true
+			}
+		}
+		*list = append(*list, value)
+		return true
+	}
+}
+
+func setFlag(field func(p *ParsedTitle) *bool) func(*ParsedTitle, []string, string) bool {
+	return func(p *ParsedTitle, _ []string, _ string) bool {
+		*field(p) = true
+		return true
+	}
+}
+
+// numberRange expands "1" and "3" to [1 2 3]; ranges that are reversed or implausibly
+// long are kept as their two ends.
+func numberRange(from, to int) []int {
+	if to <= from {
+		return []int{from}
+	}
+	if to-from > 500 {
+		return []int{from, to}
+	}
+	out := make([]int, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		out = append(out, n)
+	}
+	return out
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimLeft(s, "0"))
+	return n
+}
+
+func mergeInts(list []int, values ...int) []int {
+	seen := make(map[int]bool, len(list))
+	for _, n := range list {
+		seen[n] = true
+	}
+	for _, n := range values {
+		if !seen[n] {
+			seen[n] = true
+			list = append(list, n)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
+
+var (
+	hasResolution = func(p *ParsedTitle) bool { return p.Resolution != "" }
+	hasQuality    = func(p *ParsedTitle) bool { return p.Quality != "" }
+	hasCodec      = func(p *ParsedTitle) bool { return p.Codec != "" }
+	hasSeasons    = func(p *ParsedTitle) bool { return len(p.Seasons) > 0 }
+	hasEpisodes   = func(p *ParsedTitle) bool { return len(p.Episodes) > 0 }
+	hasGroup      = func(p *ParsedTitle) bool { return p.Group != "" }
+
+	resolutionField = func(p *ParsedTitle) *string { return &p.Resolution }
+	qualityField    = func(p *ParsedTitle) *string { return &p.Quality }
+	codecField      = func(p *ParsedTitle) *string { return &p.Codec }
+	audioField      = func(p *ParsedTitle) *[]string { return &p.Audio }
+	channelsField   = func(p *ParsedTitle) *[]string { return &p.Channels }
+	languagesField  = func(p *ParsedTitle) *[]string { return &p.Languages }
+	hdrField        = func(p *ParsedTitle) *[]string { return &p.HDR }
+)
+
+func resolution(pattern, value string) handler {
+	return handler{re: re(pattern), apply: setString(resolutionField, value), skip: hasResolution, remove: true}
+}
+
+func quality(pattern, value string) handler {
+	return handler{re: re(pattern), apply: setString(qualityField, value), skip: hasQuality, remove: true}
+}
+
+func codec(pattern, value string) handler {
+	return handler{re: re(pattern), apply: setString(codecField, value), skip: hasCodec, remove: true}
+}
+
+func audio(pattern, value string) handler {
+	return handler{re: re(pattern), apply: appendUnique(audioField, value), remove: true}
+}
+
+func hdr(pattern, value string) handler {
+	return handler{re: re(pattern), apply: appendUnique(hdrField, value), remove: true}
+}
+
+func language(pattern, value string) handler {
+	return handler{re: re(pattern), apply: appendUnique(languagesField, value), remove: true, afterTitle: true}
+}
+
+// episodeList parses the episodes following the first one in "S01E01E02" (a list) or
+// "S01E01-E03" / "S01E01-03" (a range).
+func episodeList(first int, tail string) []int {
+	numbers := digitsPattern.FindAllString(tail, -1)
+	if len(numbers) == 0 {
+		return []int{first}
+	}
+	if strings.Contains(tail, "-") {
+		return numberRange(first, atoi(numbers[len(numbers)-1]))
+	}
+	episodes := []int{first}
+	for _, n := range numbers {
+		episodes = append(episodes, atoi(n))
+	}
+	return episodes
+}
+
+// handlers in the order they run. Episode codes and the year go first as they are the
+// most reliable end of the title; the release group is looked for last, once quality and
+// codec tags like "WEB-DL" are removed.
+var handlers = []handler{
+	// Site prefix: "www.site.com - Title"
+	{
+		re: re(`^(?:www\.)?([a-z0-9-]+\.(?:com|org|net|to|me|ru|tv|io|cc|xyz|info|se|li))\s+-\s+`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Site = m[1]
+			return true
+		},
+		remove: true, skipFromTitle: true,
+	},
+	// Anime style group prefix: "[Group] Title - 01"
+	{
+		re: re(`^\[([^\[\]]+)\]`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Group = strings.TrimSpace(m[1])
+			return p.Group != ""
+		},
+		remove: true, skipFromTitle: true,
+	},
+	{
+		re: re(`\.(mkv|avi|mp4|wmv|mpg|mpeg|m4v|ts|webm)$`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Container = strings.ToLower(m[1])
+			return true
+		},
+		remove: true, skipFromTitle: true,
+	},
+
+	// Seasons and episodes
+	{
+		re: re(`\bS(\d{1,3})[ .-]?E(\d{1,4})((?:(?:[ .-]?E|-)\d{1,4})*)\b`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Seasons = mergeInts(p.Seasons, atoi(m[1]))
+			p.Episodes = mergeInts(p.Episodes, episodeList(atoi(m[2]), m[3])...)
+			return true
+		},
+	},
+	{
+		re:   re(`\bS(\d{1,2})[ .]?(?:-|to|thru)[ .]?S?(\d{1,2})\b`),
+		skip: hasSeasons,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Seasons = mergeInts(p.Seasons, numberRange(atoi(m[1]), atoi(m[2]))...)
+			return true
+		},
+	},
+	{
+		re:   re(`\b(?:Seasons?|Saison|Temporada|Stagione|Staffel)[ .]?(\d{1,2})(?:[ .]?(?:-|to|&|and)[ .]?(\d{1,2}))?\b`),
+		skip: hasSeasons,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			if m[2] != "" {
+				p.Seasons = mergeInts(p.Seasons, numberRange(atoi(m[1]), atoi(m[2]))...)
+			} else {
+				p.Seasons = mergeInts(p.Seasons, atoi(m[1]))
+			}
+			return true
+		},
+	},
+	{
+		re:   re(`\bS(\d{1,2})\b`),
+		skip: hasSeasons,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Seasons = mergeInts(p.Seasons, atoi(m[1]))
+			return true
+		},
+	},
+	{
+		re:   re(`\b(\d{1,2})x(\d{2,3})\b`),
+		skip: hasEpisodes,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Seasons = mergeInts(p.Seasons, atoi(m[1]))
+			p.Episodes = mergeInts(p.Episodes, atoi(m[2]))
+			return true
+		},
+	},
+	{
+		re:   re(`\b(?:Episode|Ep)[ .]?(\d{1,4})\b`),
+		skip: hasEpisodes,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			p.Episodes = mergeInts(p.Episodes, atoi(m[1]))
+			return true
+		},
+	},
+	// Anime batches: "Title 01-26", "Title - 01~12"
+	{
+		re:   re(`(?:^|[ .])(0\d{1,2})[ ]?[-~][ ]?(\d{2,3})\b`),
+		skip: hasEpisodes,
+		span: 1,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			from, to := atoi(m[1]), atoi(m[2])
+			if to <= from {
+				return false
+			}
+			p.Episodes = mergeInts(p.Episodes, numberRange(from, to)...)
+			return true
+		},
+	},
+	// Anime episodes: "Title - 05 [1080p]"
+	{
+		re:   re(`(?:^|\s)-\s(\d{1,4})(?:v\d)?(?:\s|\[|\(|$)`),
+		skip: hasEpisodes,
+		span: 1,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			n := atoi(m[1])
+			if n >= 1900 && n <= 2099 {
+				return false // a year, not an episode
+			}
+			p.Episodes = mergeInts(p.Episodes, n)
+			return true
+		},
+	},
+	// DVD/BD volumes; "Kill.Bill.Vol.2.2004" is a movie title, not a volume
+	{
+		re: re(`\bVol(?:ume)?s?[ .]?(\d{1,3})(?:[ .]?[-~][ .]?(\d{1,3}))?\b`),
+		apply: func(p *ParsedTitle, m []string, rest string) bool {
+			if laterYear.MatchString(rest) {
+				return false
+			}
+			if m[2] != "" {
+				p.Volumes = mergeInts(p.Volumes, numberRange(atoi(m[1]), atoi(m[2]))...)
+			} else {
+				p.Volumes = mergeInts(p.Volumes, atoi(m[1]))
+			}
+			return true
+		},
+	},
+
+	// Year; when two years follow each other the first is part of the title ("Blade.Runner.2049.2017")
+	{
+		re: re(`\b((?:19|20)\d{2})\b`),
+		apply: func(p *ParsedTitle, m []string, rest string) bool {
+			if followingYear.MatchString(rest) {
+				return false
+			}
+			p.Year = atoi(m[1])
+			return true
+		},
+		skipIfFirst: true,
+	},
+
+	resolution(`\b(?:3840x2160|2160[pi])\b`, "2160p"),
+	resolution(`\b(?:2560x1440|1440[pi])\b`, "1440p"),
+	resolution(`\b(?:1920x1080|1080[pi])\b`, "1080p"),
+	resolution(`\b(?:1280x720|720[pi])\b`, "720p"),
+	resolution(`\b576[pi]\b`, "576p"),
+	resolution(`\b480[pi]\b`, "480p"),
+	resolution(`\b360p\b`, "360p"),
+	resolution(`\b(?:4k|UHD)\b`, "2160p"),
+	resolution(`\bFHD\b`, "1080p"),
+
+	{re: re(`\bCOMPLETE\b|\bINTEGRALE?\b|\bBox[ .-]?Set\b`), apply: setFlag(func(p *ParsedTitle) *bool { return &p.Complete })},
+
+	hdr(`\b(?:DV|DoVi|Dolby[ .]?Vision)\b`, "DV"),
+	hdr(`\bHDR10(?:\+|P(?:lus)?\b)`, "HDR10+"),
+	hdr(`\bHDR(?:10)?\b`, "HDR"),
+	{
+		re: re(`\b(?:(8|10|12)[ .-]?bits?|hi(10)p?)\b`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			depth := m[1]
+			if depth == "" {
+				depth = m[2]
+			}
+			p.BitDepth = depth + "bit"
+			return true
+		},
+		skip:   func(p *ParsedTitle) bool { return p.BitDepth != "" },
+		remove: true,
+	},
+
+	quality(`\b(?:Blu[ .-]?Ray|BD)[ .-]?REMUX\b`, "BluRay REMUX"),
+	quality(`\bREMUX\b`, "REMUX"),
+	quality(`\bBD[ .-]?Rip\b`, "BDRip"),
+	quality(`\bBR[ .-]?Rip\b`, "BRRip"),
+	quality(`\bBlu[ .-]?Ray\b`, "BluRay"),
+	quality(`\bWEB[ .-]?DL\b`, "WEB-DL"),
+	quality(`\bWEB[ .-]?Rip\b`, "WEBRip"),
+	quality(`\bHD[ .-]?TV\b`, "HDTV"),
+	quality(`\bPDTV\b`, "PDTV"),
+	quality(`\bHD[ .-]?Rip\b`, "HDRip"),
+	quality(`\bDVD[ .-]?Rip\b`, "DVDRip"),
+	quality(`\b(?:DVD)?SCR(?:EENER)?\b`, "SCR"),
+	quality(`\bHD[ .-]?CAM\b|\bCAM[ .-]?Rip\b`, "CAM"),
+	quality(`\bTELESYNC\b|\bHD[ .-]?TS\b`, "TeleSync"),
+	quality(`\bTELECINE\b`, "TeleCine"),
+	quality(`\bSAT[ .-]?Rip\b`, "SATRip"),
+	quality(`\bTV[ .-]?Rip\b`, "TVRip"),
+	quality(`\bVHS[ .-]?Rip\b`, "VHSRip"),
+	{re: re(`\bWEB\b`), apply: setString(qualityField, "WEB"), skip: hasQuality, remove: true, afterTitle: true},
+	{re: re(`\bDVD(?:[59]|R)?\b`), apply: setString(qualityField, "DVD"), skip: hasQuality, remove: true, afterTitle: true},
+	{re: re(`\bCAM\b`), apply: setString(qualityField, "CAM"), skip: hasQuality, remove: true, afterTitle: true},
+
+	codec(`\b[xh][ .]?265\b|\bHEVC\b`, "hevc"),
+	codec(`\b[xh][ .]?264\b|\bAVC\b`, "avc"),
+	codec(`\bAV1\b`, "av1"),
+	codec(`\b(?:XviD|DivX)\b`, "xvid"),
+	codec(`\bMPEG[ .-]?2\b`, "mpeg2"),
+
+	// Channels come before the audio codecs they're glued to ("DDP5.1", "AAC2.0")
+	{
+		re: re(`(?:^|\D)([257]\.[01])(?:ch)?(?:\D|$)`),
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			return appendUnique(channelsField, m[1])(p, m, "")
+		},
+		span:   1,
+		remove: true,
+	},
+	{re: re(`\bstereo\b`), apply: appendUnique(channelsField, "stereo"), remove: true, afterTitle: true},
+	{re: re(`\bmono\b`), apply: appendUnique(channelsField, "mono"), remove: true, afterTitle: true},
+
+	audio(`\bDTS[ .-]?(?:HD(?:[ .-]?MA)?|X)\b|\bDTS[ .-]?MA\b`, "DTS Lossless"),
+	audio(`\bDTS\b`, "DTS Lossy"),
+	audio(`\bTrue[ .-]?HD\b`, "TrueHD"),
+	audio(`\bAtmos\b`, "Atmos"),
+	audio(`\bDD(?:P|\+)|\bE-?AC-?3\b|\bDolby[ .]Digital[ .]Plus\b`, "DDP"),
+	audio(`\bDD\b|\bAC-?3\b|\bDolby[ .]Digital\b`, "DD"),
+	audio(`\bAAC\b`, "AAC"),
+	audio(`\bFLAC\b`, "FLAC"),
+	audio(`\bOPUS\b`, "OPUS"),
+	audio(`\bMP3\b`, "MP3"),
+
+	// Subtitles first, so "Multi-Subs" isn't read as multi audio
+	language(`\bmulti(?:ple)?[ .-]?sub\w*\b`, "multi subs"),
+	language(`\bMULTi(?:[ .-]?audio)?\b`, "multi audio"),
+	language(`\bdual[ .-]?audio\b`, "dual audio"),
+	language(`\b(?:ENG|ENGLISH)\b`, "en"),
+	language(`\b(?:FRENCH|TRUEFRENCH|VFF|VFQ|VF2?|VOSTFR)\b`, "fr"),
+	language(`\bLATINO\b`, "la"),
+	language(`\b(?:SPANISH|ESP|CASTELLANO)\b`, "es"),
+	language(`\b(?:GERMAN|GER|DEUTSCH)\b`, "de"),
+	language(`\b(?:ITALIAN|ITA)\b`, "it"),
+	language(`\b(?:PORTUGUESE|POR|PT[ .-]?BR|DUBLADO)\b`, "pt"),
+	language(`\b(?:RUSSIAN|RUS)\b`, "ru"),
+	language(`\b(?:JAPANESE|JAP|JPN)\b`, "ja"),
+	language(`\b(?:KOREAN|KOR)\b`, "ko"),
+	language(`\b(?:CHINESE|CHI|MANDARIN|CANTONESE)\b`, "zh"),
+	language(`\b(?:HINDI|HIN)\b`, "hi"),
+	language(`\b(?:DUTCH|NLD)\b`, "nl"),
+	language(`\b(?:POLISH|PLK)\b`, "pl"),
+	language(`\b(?:ARABIC|ARA)\b`, "ar"),
+
+	{re: re(`\bEXTENDED(?:[ .](?:CUT|EDITION))?\b`), apply: setFlag(func(p *ParsedTitle) *bool { return &p.Extended }), remove: true},
+	{re: re(`\b(?:HC|HARDCODED)\b`), apply: setFlag(func(p *ParsedTitle) *bool { return &p.Hardcoded }), remove: true, afterTitle: true},
+	{re: re(`\b(?:REAL[ .])?PROPER\b`), apply: setFlag(func(p *ParsedTitle) *bool { return &p.Proper }), remove: true},
+	{re: re(`\bRE-?PACK\b|\bRERIP\b`), apply: setFlag(func(p *ParsedTitle) *bool { return &p.Repack }), remove: true},
+
+	// Release group at the end: "-SPARKS", "-[TroubleGod]"
+	{
+		re:   re(`-\s?(?:\[([^\[\]]+)\]|([a-z0-9][a-z0-9_]*))\s*$`),
+		skip: hasGroup,
+		apply: func(p *ParsedTitle, m []string, _ string) bool {
+			group := strings.TrimSpace(m[1] + m[2])
+			if group == "" || (m[2] != "" && notGroupPattern.MatchString(group)) {
+				return false
+			}
+			p.Group = group
+			return true
+		},
+		remove: true, afterTitle: true,
+	},
+}
+
+// parse runs the handlers over a release title.
+func parse(raw string) *ParsedTitle {
+	p := &ParsedTitle{}
+	s := strings.TrimSpace(strings.ReplaceAll(raw, "_", " "))
+	end := len(s)
+
+	for _, h := range handlers {
+		if h.skip != nil && h.skip(p) {
+			continue
+		}
+		from := 0
+		if h.afterTitle {
+			from = end
+		}
+
+		for _, loc := range h.re.FindAllStringSubmatchIndex(s[from:], -1) {
+			start, stop := loc[0]+from, loc[1]+from
+			if h.span > 0 && loc[2*h.span] >= 0 {
+				start, stop = loc[2*h.span]+from, loc[2*h.span+1]+from
+			}
+			if h.skipIfFirst && strings.Trim(s[:start], titleSeparatorSet) == "" {
+				continue
+			}
+			m := make([]string, len(loc)/2)
+			for i := range m {
+				if loc[2*i] >= 0 {
+					m[i] = s[loc[2*i]+from : loc[2*i+1]+from]
+				}
+			}
+			if !h.apply(p, m, s[stop:]) {
+				continue
+			}
+
+			// Matches at the very start don't end the title (e.g. a leading group tag)
+			if !h.skipFromTitle && start > 1 && start < end {
+				end = start
+			}
+			if h.remove {
+				s = s[:start] + s[stop:]
+				switch {
+				case stop <= end:
+					end -= stop - start
+				case start < end:
+					end = start
+				}
+			}
+			break
+		}
+	}
+
+	p.Title = cleanTitle(s[:end])
+	return p
+}
+
+// cleanTitle turns the text before the first parsed attribute into a display title.
+func cleanTitle(title string) string {
+	title = bracketPattern.ReplaceAllString(title, " ")
+	if !strings.Contains(title, " ") || strings.Count(title, ".") > strings.Count(title, " ") {
+		title = strings.ReplaceAll(title, ".", " ")
+	}
+	title = spacesPattern.ReplaceAllString(title, " ")
+	title = strings.Trim(title, titleTrimCutset)
+	// Unclosed brackets left by tags cut from the end: "Title (2019"
+	if i := strings.LastIndexAny(title, "(["); i >= 0 && !strings.ContainsAny(title[i:], ")]") {
+		title = strings.Trim(title[:i], titleTrimCutset)
+	}
+	return title
+}
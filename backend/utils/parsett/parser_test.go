@@ -0,0 +1,228 @@
+package parsett
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTitleAttributes(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected ParsedTitle
+	}{
+		{
+			input: "The.Dark.Knight.2008.1080p.BluRay.x264.DTS-HD.MA.5.1-RARBG",
+			expected: ParsedTitle{Title: "The Dark Knight", Year: 2008, Resolution: "1080p", Quality: "BluRay", Codec: "avc",
+				Audio: []string{"DTS Lossless"}, Channels: []string{"5.1"}, Group: "RARBG"},
+		},
+		{
+			input: "The.Simpsons.S01E01.1080p.BluRay.x265.HEVC.10bit.AAC.5.1.Tigole",
+			expected: ParsedTitle{Title: "The Simpsons", Resolution: "1080p", Quality: "BluRay", Codec: "hevc",
+				Audio: []string{"AAC"}, Channels: []string{"5.1"}, Seasons: []int{1}, Episodes: []int{1}, BitDepth: "10bit"},
+		},
+		{
+			input: "Dune.Part.Two.2024.2160p.UHD.BluRay.REMUX.DV.HDR10+.TrueHD.Atmos.7.1-FGT",
+			expected: ParsedTitle{Title: "Dune Part Two", Year: 2024, Resolution: "2160p", Quality: "BluRay REMUX",
+				Audio: []string{"TrueHD", "Atmos"}, Channels: []string{"7.1"}, Group: "FGT", HDR: []string{"DV", "HDR10+"}},
+		},
+		{
+			input: "Stranger Things S04E01-E03 2160p NF WEB-DL DDP5.1 Atmos HDR HEVC-GROUP",
+			expected: ParsedTitle{Title: "Stranger Things", Resolution: "2160p", Quality: "WEB-DL", Codec: "hevc",
+				Audio: []string{"Atmos", "DDP"}, Channels: []string{"5.1"}, Group: "GROUP",
+				Seasons: []int{4}, Episodes: []int{1, 2, 3}, HDR: []string{"HDR"}},
+		},
+		{
+			input:    "Breaking.Bad.COMPLETE.S01-S05.2160p.WEB-DL",
+			expected: ParsedTitle{Title: "Breaking Bad", Resolution: "2160p", Quality: "WEB-DL", Seasons: []int{1, 2, 3, 4, 5}, Complete: true},
+		},
+		{
+			input:    "The Office (US) Season 1-3 Complete 720p",
+			expected: ParsedTitle{Title: "The Office (US)", Resolution: "720p", Seasons: []int{1, 2, 3}, Complete: true},
+		},
+		{
+			input:    "[SubsPlease] Frieren - 05 (1080p) [ABCD1234].mkv",
+			expected: ParsedTitle{Title: "Frieren", Resolution: "1080p", Group: "SubsPlease", Container: "mkv", Episodes: []int{5}},
+		},
+		{
+			input:    "Cowboy.Bebop.Vol.1-3.Complete",
+			expected: ParsedTitle{Title: "Cowboy Bebop", Complete: true, Volumes: []int{1, 2, 3}},
+		},
+		{
+			input:    "Kill.Bill.Vol.2.2004.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "Kill Bill Vol 2", Year: 2004, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input: "Blade.Runner.2049.2017.1080p.WEB-DL.DDP5.1.H.264",
+			expected: ParsedTitle{Title: "Blade Runner 2049", Year: 2017, Resolution: "1080p", Quality: "WEB-DL", Codec: "avc",
+				Audio: []string{"DDP"}, Channels: []string{"5.1"}},
+		},
+		{
+			input:    "2012.2009.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "2012", Year: 2009, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input: "The.Italian.Job.2003.FRENCH.720p.BluRay",
+			expected: ParsedTitle{Title: "The Italian Job", Year: 2003, Resolution: "720p", Quality: "BluRay",
+				Languages: []string{"fr"}},
+		},
+		{
+			input:    "Show_Name_S01E01_720p_HDTV",
+			expected: ParsedTitle{Title: "Show Name", Resolution: "720p", Quality: "HDTV", Seasons: []int{1}, Episodes: []int{1}},
+		},
+		{
+			input:    "Friends.1x05.720p",
+			expected: ParsedTitle{Title: "Friends", Resolution: "720p", Seasons: []int{1}, Episodes: []int{5}},
+		},
+		{
+			input:    "Ocean's.Eleven.2001.PROPER.REPACK.720p",
+			expected: ParsedTitle{Title: "Ocean's Eleven", Year: 2001, Resolution: "720p", Proper: true, Repack: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := ParseTitle(tc.input)
+			if err != nil {
+				t.Fatalf("ParseTitle failed: %v", err)
+			}
+			if !reflect.DeepEqual(*result, tc.expected) {
+				t.Errorf("got  %+v\nwant %+v", *result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestParseTitleCorpus checks every field PTT fills in for the batch corpus, multi-episode
+// ranges, anime releases and foreign-language titles.
+func TestParseTitleCorpus(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected ParsedTitle
+	}{
+		// Batch corpus
+		{
+			input:    "The.Matrix.1999.1080p.BluRay.x264-SPARKS",
+			expected: ParsedTitle{Title: "The Matrix", Year: 1999, Resolution: "1080p", Quality: "BluRay", Codec: "avc", Group: "SPARKS"},
+		},
+		{
+			input:    "Inception.2010.720p.BluRay.x264",
+			expected: ParsedTitle{Title: "Inception", Year: 2010, Resolution: "720p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input: "The.Simpsons.S01E01.1080p.BluRay.x265",
+			expected: ParsedTitle{Title: "The Simpsons", Resolution: "1080p", Quality: "BluRay", Codec: "hevc",
+				Seasons: []int{1}, Episodes: []int{1}},
+		},
+		{
+			input:    "Interstellar.2014.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "Interstellar", Year: 2014, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input:    "The.Godfather.1972.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "The Godfather", Year: 1972, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input:    "Pulp.Fiction.1994.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "Pulp Fiction", Year: 1994, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input:    "Fight.Club.1999.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "Fight Club", Year: 1999, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input:    "Forrest.Gump.1994.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "Forrest Gump", Year: 1994, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+		{
+			input:    "The.Shawshank.Redemption.1994.1080p.BluRay.x264",
+			expected: ParsedTitle{Title: "The Shawshank Redemption", Year: 1994, Resolution: "1080p", Quality: "BluRay", Codec: "avc"},
+		},
+
+		// Multi-episode and multi-season ranges; the end of a range is not a release group
+		{
+			input: "The Office US S02E01-E03 720p WEB-DL DD5.1 H264",
+			expected: ParsedTitle{Title: "The Office US", Resolution: "720p", Quality: "WEB-DL", Codec: "avc",
+				Audio: []string{"DD"}, Channels: []string{"5.1"}, Seasons: []int{2}, Episodes: []int{1, 2, 3}},
+		},
+		{
+			input: "Game.of.Thrones.S08E01E02E03.1080p.WEB.H264-MEMENTO",
+			expected: ParsedTitle{Title: "Game of Thrones", Resolution: "1080p", Quality: "WEB", Codec: "avc", Group: "MEMENTO",
+				Seasons: []int{8}, Episodes: []int{1, 2, 3}},
+		},
+		{
+			input: "The.Mandalorian.S02E01-08.2160p.DSNP.WEB-DL.DDP5.1.Atmos.DV.HDR.H.265-FLUX",
+			expected: ParsedTitle{Title: "The Mandalorian", Resolution: "2160p", Quality: "WEB-DL", Codec: "hevc",
+				Audio: []string{"Atmos", "DDP"}, Channels: []string{"5.1"}, Group: "FLUX",
+				Seasons: []int{2}, Episodes: []int{1, 2, 3, 4, 5, 6, 7, 8}, HDR: []string{"DV", "HDR"}},
+		},
+		{
+			input: "Severance.S01-S02.1080p.ATVP.WEB-DL.DDP5.1.H.264-NTb",
+			expected: ParsedTitle{Title: "Severance", Resolution: "1080p", Quality: "WEB-DL", Codec: "avc",
+				Audio: []string{"DDP"}, Channels: []string{"5.1"}, Group: "NTb", Seasons: []int{1, 2}},
+		},
+
+		// Anime
+		{
+			input: "[Erai-raws] Sousou no Frieren - 01 ~ 28 [1080p][Multiple Subtitle]",
+			expected: ParsedTitle{Title: "Sousou no Frieren", Resolution: "1080p", Group: "Erai-raws",
+				Episodes: numberRange(1, 28), Languages: []string{"multi subs"}},
+		},
+		{
+			input:    "[SubsPlease] Jujutsu Kaisen - 24v2 (720p) [A1B2C3D4].mkv",
+			expected: ParsedTitle{Title: "Jujutsu Kaisen", Resolution: "720p", Group: "SubsPlease", Container: "mkv", Episodes: []int{24}},
+		},
+		{
+			input:    "One Piece - 1071 [1080p] [HEVC]",
+			expected: ParsedTitle{Title: "One Piece", Resolution: "1080p", Codec: "hevc", Episodes: []int{1071}},
+		},
+		{
+			input: "[Judas] Shingeki no Kyojin (Attack on Titan) - S04E28 [1080p][HEVC x265 10bit][Multi-Subs]",
+			expected: ParsedTitle{Title: "Shingeki no Kyojin (Attack on Titan)", Resolution: "1080p", Codec: "hevc", Group: "Judas",
+				Seasons: []int{4}, Episodes: []int{28}, Languages: []string{"multi subs"}, BitDepth: "10bit"},
+		},
+
+		// Foreign-language releases
+		{
+			input: "Les.Miserables.2012.FRENCH.1080p.BluRay.x264-LOST",
+			expected: ParsedTitle{Title: "Les Miserables", Year: 2012, Resolution: "1080p", Quality: "BluRay", Codec: "avc", Group: "LOST",
+				Languages: []string{"fr"}},
+		},
+		{
+			input: "La.Casa.de.Papel.S03E01.SPANISH.1080p.NF.WEB-DL.DDP5.1.x264-MiXED",
+			expected: ParsedTitle{Title: "La Casa de Papel", Resolution: "1080p", Quality: "WEB-DL", Codec: "avc",
+				Audio: []string{"DDP"}, Channels: []string{"5.1"}, Group: "MiXED", Seasons: []int{3}, Episodes: []int{1}, Languages: []string{"es"}},
+		},
+		{
+			input: "Dark.S01E01.GERMAN.DL.1080p.WEB.x264-WvF",
+			expected: ParsedTitle{Title: "Dark", Resolution: "1080p", Quality: "WEB", Codec: "avc", Group: "WvF",
+				Seasons: []int{1}, Episodes: []int{1}, Languages: []string{"de"}},
+		},
+		{
+			input: "Amelie.2001.MULTi.1080p.BluRay.x264-ZEST",
+			expected: ParsedTitle{Title: "Amelie", Year: 2001, Resolution: "1080p", Quality: "BluRay", Codec: "avc", Group: "ZEST",
+				Languages: []string{"multi audio"}},
+		},
+		{
+			input: "Parasite.2019.KOREAN.1080p.BluRay.H264.AAC-VXT",
+			expected: ParsedTitle{Title: "Parasite", Year: 2019, Resolution: "1080p", Quality: "BluRay", Codec: "avc",
+				Audio: []string{"AAC"}, Group: "VXT", Languages: []string{"ko"}},
+		},
+		{
+			input: "Le.Bureau.des.Legendes.S05E01.VOSTFR.720p.WEB.H264-AMB3R",
+			expected: ParsedTitle{Title: "Le Bureau des Legendes", Resolution: "720p", Quality: "WEB", Codec: "avc", Group: "AMB3R",
+				Seasons: []int{5}, Episodes: []int{1}, Languages: []string{"fr"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := ParseTitle(tc.input)
+			if err != nil {
+				t.Fatalf("ParseTitle failed: %v", err)
+			}
+			if !reflect.DeepEqual(*result, tc.expected) {
+				t.Errorf("got  %+v\nwant %+v", *result, tc.expected)
+			}
+		})
+	}
+}
@@ -1,42 +1,12 @@
 package parsett
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
+	"strings"
 )
 
-// getScriptPaths returns the paths to the Python script and venv python executable.
-// It first checks for Docker paths (scripts at /), then falls back to local development paths.
-func getScriptPaths(scriptName string) (scriptPath, pythonPath string, err error) {
-	// Docker paths (scripts copied to / in container)
-	dockerScript := "/" + scriptName
-	dockerPython := "/.venv/bin/python3"
-
-	if _, err := os.Stat(dockerScript); err == nil {
-		if _, err := os.Stat(dockerPython); err == nil {
-			return dockerScript, dockerPython, nil
-		}
-	}
-
-	// Local development paths (scripts in backend/, venv in project root)
-	_, currentFile, _, ok := runtime.Caller(1)
-	if !ok {
-		return "", "", fmt.Errorf("failed to get current file path")
-	}
-
-	// From backend/utils/parsett/, go up 2 levels to backend/
-	scriptPath = filepath.Join(filepath.Dir(currentFile), "..", "..", scriptName)
-	// From backend/utils/parsett/, go up 3 levels to project root for .venv
-	pythonPath = filepath.Join(filepath.Dir(currentFile), "..", "..", "..", ".venv", "bin", "python3")
-
-	return scriptPath, pythonPath, nil
-}
-
-// ParsedTitle represents the result from PTT's parse_title function
+// ParsedTitle holds the attributes parsed from a release title. Fields follow the output of
+// PTT's parse_title, which this parser replaces.
 type ParsedTitle struct {
 	Title      string   `json:"title"`
 	Year       int      `json:"year,omitempty"`
@@ -61,82 +31,30 @@ type ParsedTitle struct {
 	HDR        []string `json:"hdr,omitempty"`        // HDR formats like DV, HDR, HDR10+
 }
 
-// ParseTitle calls the Python PTT library to parse a media title
+// ParseTitle parses a media release title
 // Returns a ParsedTitle struct with the parsed information
 func ParseTitle(title string) (*ParsedTitle, error) {
-	scriptPath, venvPython, err := getScriptPaths("parse_title.py")
-	if err != nil {
-		return nil, err
-	}
-
-	// Execute the Python script with the title as an argument
-	cmd := exec.Command(venvPython, scriptPath, title)
-
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("python script error: %s", string(exitErr.Stderr))
-		}
-		return nil, fmt.Errorf("failed to execute python script: %w", err)
-	}
-
-	// Parse the JSON output
-	var result ParsedTitle
-	if err := json.Unmarshal(output, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON output: %w (output: %s)", err, string(output))
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("empty title")
 	}
-
-	return &result, nil
-}
-
-// BatchResult represents a single result from batch parsing
-type BatchResult struct {
-	Title  string       `json:"title"`
-	Parsed *ParsedTitle `json:"parsed"`
-	Error  string       `json:"error"`
+	return parse(title), nil
 }
 
-// ParseTitleBatch parses multiple titles in a single Python subprocess call
-// This is much faster than calling ParseTitle repeatedly (100x speedup for 50 items)
-// Returns a map of title -> parsed result
+// ParseTitleBatch parses multiple titles
+// Returns a map of title -> parsed result; titles that can't be parsed map to nil
 func ParseTitleBatch(titles []string) (map[string]*ParsedTitle, error) {
-	if len(titles) == 0 {
-		return make(map[string]*ParsedTitle), nil
-	}
-
-	scriptPath, venvPython, err := getScriptPaths("parse_title_batch.py")
-	if err != nil {
-		return nil, err
-	}
-
-	// Build command with all titles as arguments
-	args := append([]string{scriptPath}, titles...)
-	cmd := exec.Command(venvPython, args...)
-
-	output, err := cmd.Output()
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("python batch script error: %s", string(exitErr.Stderr))
+	resultMap := make(map[string]*ParsedTitle, len(titles))
+	for _, title := range titles {
+		if _, done := resultMap[title]; done {
+			continue
 		}
-		return nil, fmt.Errorf("failed to execute python batch script: %w", err)
-	}
-
-	// Parse the JSON array output
-	var results []BatchResult
-	if err := json.Unmarshal(output, &results); err != nil {
-		return nil, fmt.Errorf("failed to parse batch JSON output: %w (output: %s)", err, string(output))
-	}
-
-	// Build result map
-	resultMap := make(map[string]*ParsedTitle, len(results))
-	for _, r := range results {
-		if r.Error != "" {
-			// Store nil for failed parses (will be treated as parse errors in filter)
-			resultMap[r.Title] = nil
-		} else {
-			resultMap[r.Title] = r.Parsed
+		// Store nil for failed parses (will be treated as parse errors in filter)
+		parsed, err := ParseTitle(title)
+		if err != nil {
+			resultMap[title] = nil
+			continue
 		}
+		resultMap[title] = parsed
 	}
-
 	return resultMap, nil
 }
@@ -413,8 +413,8 @@ function IndexScreen() {
         if (!shelf.listUrl) continue;
         // Use shelf's configured limit if set, otherwise use default
         const itemLimit = shelf.limit && shelf.limit > 0 ? shelf.limit : MAX_SHELF_ITEMS_ON_HOME;
-        // Create cache key that includes URL, limit, and hideUnreleased so changes trigger re-fetch
-        const cacheKey = `${shelf.listUrl}:${itemLimit}:${shelf.hideUnreleased ?? false}`;
+        // Create cache key that includes URL, limit, hideUnreleased and profile so changes trigger re-fetch
+        const cacheKey = `${shelf.listUrl}:${itemLimit}:${shelf.hideUnreleased ?? false}:${activeUserId ?? ''}`;
         // Skip if we've already fetched this URL with these parameters
         if (fetchedListUrlsRef.current.has(cacheKey)) continue;
 
@@ -427,6 +427,7 @@ function IndexScreen() {
             itemLimit,
             undefined, // offset
             shelf.hideUnreleased,
+            activeUserId ?? undefined,
           );
           setCustomListData((prev) => ({ ...prev, [shelf.id]: items }));
           setCustomListTotals((prev) => ({ ...prev, [shelf.id]: total }));
@@ -441,7 +442,7 @@ function IndexScreen() {
     };
 
     void fetchCustomLists();
-  }, [customShelves]);
+  }, [customShelves, activeUserId]);
 
   const backendLoadError = useMemo(() => {
     if (settingsLoading || settingsError) {
@@ -123,7 +123,7 @@ export default function SearchScreen() {
   const inputRef = useRef<TextInput>(null);
   const router = useRouter();
   const { isOpen: isMenuOpen, openMenu } = useMenuContext();
-  const { pendingPinUserId } = useUserProfiles();
+  const { activeUserId, pendingPinUserId } = useUserProfiles();
   const isFocused = useIsFocused();
   const isActive = isFocused && !isMenuOpen && !pendingPinUserId;
 
@@ -176,7 +176,7 @@ export default function SearchScreen() {
       { key: 'series', label: 'TV Shows', icon: 'tv-outline' },
     ];
 
-  const { data: searchResults, loading, error } = useSearchTitles(submittedQuery);
+  const { data: searchResults, loading, error } = useSearchTitles(submittedQuery, activeUserId);
   const items = useMemo(() => {
     const seen = new Map<string, number>();
     const titlesWithKeys =
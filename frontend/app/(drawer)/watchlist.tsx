@@ -199,6 +199,7 @@ export default function WatchlistScreen() {
             limit,
             offset,
             shelfConfig?.hideUnreleased,
+            activeUserId ?? undefined,
           );
           items = response.items;
           total = response.total;
@@ -1052,6 +1052,7 @@ export default function DetailsScreen() {
           mediaType: isSeries ? 'series' : 'movie',
           userId: activeUserId,
           imdbId: imdbId || undefined,
+          tvdbId: isSeries ? tvdbId || undefined : undefined,
           year: yearNumber || undefined,
           seasonNumber: targetEpisode?.seasonNumber,
           episodeNumber: targetEpisode?.episodeNumber,
@@ -1107,7 +1108,7 @@ export default function DetailsScreen() {
       }
       prequeuePromiseRef.current = null;
     };
-  }, [titleId, title, mediaType, isSeries, activeUserId, imdbId, yearNumber, activeEpisode, nextUpEpisode]);
+  }, [titleId, title, mediaType, isSeries, activeUserId, imdbId, tvdbId, yearNumber, activeEpisode, nextUpEpisode]);
 
   // Poll prequeue status until ready
   useEffect(() => {
@@ -1736,9 +1737,10 @@ export default function DetailsScreen() {
         mediaType,
         yearNumber,
         activeUserId ?? undefined,
+        isSeries ? tvdbId || undefined : undefined,
       );
     },
-    [title, imdbId, mediaType, yearNumber, activeUserId],
+    [title, imdbId, tvdbId, isSeries, mediaType, yearNumber, activeUserId],
   );
 
   const getEpisodeSearchContext = useCallback(
@@ -1766,7 +1768,12 @@ export default function DetailsScreen() {
   );
 
   const initiatePlaybackRef = useRef<
-    ((result: NZBResult, signal?: AbortSignal, overrides?: { useDebugPlayer?: boolean }) => Promise<void>) | null
+    | ((
+        result: NZBResult,
+        signal?: AbortSignal,
+        overrides?: { useDebugPlayer?: boolean; alternatives?: NZBResult[] },
+      ) => Promise<void>)
+    | null
   >(null);
   const pendingStartOffsetRef = useRef<number | null>(null);
 
@@ -1865,7 +1872,11 @@ export default function DetailsScreen() {
   }, [isSeries, shouldShowReleaseSkeleton]);
 
   const handleInitiatePlayback = useCallback(
-    async (result: NZBResult, signal?: AbortSignal, overrides?: { useDebugPlayer?: boolean }) => {
+    async (
+      result: NZBResult,
+      signal?: AbortSignal,
+      overrides?: { useDebugPlayer?: boolean; manualSelection?: boolean; alternatives?: NZBResult[] },
+    ) => {
       // Note: Loading screen is now shown earlier (in checkAndShowResumeModal or handleResumePlayback/handlePlayFromBeginning)
       // so users see it immediately when they click play, not after the stream resolves
 
@@ -1915,6 +1926,8 @@ export default function DetailsScreen() {
             return {};
           })(),
           ...(overrides?.useDebugPlayer ? { debugPlayer: true } : {}),
+          ...(overrides?.manualSelection ? { manualSelection: true } : {}),
+          ...(overrides?.alternatives?.length ? { alternatives: overrides.alternatives } : {}),
           // Hide loading screen when launching external player
           onExternalPlayerLaunch: hideLoadingScreen,
           // Per-user settings override for track selection
@@ -2804,7 +2817,11 @@ export default function DetailsScreen() {
             `🎬 [${index + 1}/${prioritizedResults.length}] Trying: "${candidate.title}" (${candidate.serviceType}) from ${candidate.indexer}`,
           );
           try {
-            await playbackHandler(candidate, abortController.signal, { useDebugPlayer });
+            // The candidates after this one are the fallbacks if its stream breaks mid-playback
+            await playbackHandler(candidate, abortController.signal, {
+              useDebugPlayer,
+              alternatives: prioritizedResults.slice(index + 1),
+            });
 
             // Check if aborted after successful playback initiation
             if (abortController.signal.aborted) {
@@ -3437,7 +3454,7 @@ export default function DetailsScreen() {
         await showLoadingScreenIfEnabled();
 
         try {
-          await handleInitiatePlayback(result, abortController.signal);
+          await handleInitiatePlayback(result, abortController.signal, { manualSelection: true });
 
           // Check if aborted after playback
           if (abortController.signal.aborted) {
@@ -132,7 +132,9 @@ export const ManualSelection = ({
       const key = getResultKey(result);
       const healthState = healthChecks[key];
       const isUnplayable = isResultUnplayable(healthState);
-      const serviceType = (result.serviceType ?? 'usenet').toLowerCase() as 'usenet' | 'debrid';
+      const serviceType = (result.serviceType === 'direct' ? 'debrid' : (result.serviceType ?? 'usenet').toLowerCase()) as
+        | 'usenet'
+        | 'debrid';
       const serviceLabel = serviceType === 'debrid' ? 'D' : 'U';
 
       let statusLabel: string | null = null;
@@ -429,7 +431,8 @@ export const useManualHealthChecks = (_results: NZBResult[]) => {
   // Return healthChecks state and a function to manually check a specific result
   const checkHealth = useCallback(async (result: NZBResult) => {
     const key = getResultKey(result);
-    const serviceType = (result.serviceType ?? 'usenet').toLowerCase();
+    // Addon-resolved direct streams are checked like debrid results
+    const serviceType = result.serviceType === 'direct' ? 'debrid' : (result.serviceType ?? 'usenet').toLowerCase();
 
     // Set to checking state
     setHealthChecks((prev) => ({
@@ -132,7 +132,8 @@ const describePlaybackStatusMessage = (
   update: PlaybackResolutionResponse,
   demoMode?: boolean,
 ): string | null => {
-  const serviceType = (result.serviceType ?? 'usenet').toLowerCase();
+  // Addon-resolved direct streams are checked and played like debrid results
+  const serviceType = result.serviceType === 'direct' ? 'debrid' : (result.serviceType ?? 'usenet').toLowerCase();
   if (serviceType === 'debrid') {
     return describeDebridStatus(result, update, demoMode);
   }
@@ -673,11 +674,14 @@ export const initiatePlayback = async (
     profileId?: string;
     profileName?: string;
     shuffleMode?: boolean;
+    manualSelection?: boolean; // Release was picked by hand from the release list
+    alternatives?: NZBResult[]; // Ranked results below this one, for source failover during HLS playback
   } = {},
 ) => {
   setSelectionError(null);
   const releaseTitle = result.title?.trim() || 'this release';
-  const serviceType = (result.serviceType ?? 'usenet').toLowerCase();
+  // Addon-resolved direct streams are checked and played like debrid results
+  const serviceType = result.serviceType === 'direct' ? 'debrid' : (result.serviceType ?? 'usenet').toLowerCase();
   const demoMode = settings?.demoMode;
   // Generate displayName for demo mode to mask actual filenames in player
   const displayName = demoMode
@@ -701,6 +705,14 @@ export const initiatePlayback = async (
     signal: options.signal,
     // Pass startOffset for subtitle extraction to start from resume position
     startOffset: options.startOffset,
+    // Remember this release for the profile so resume and next episode play it again
+    userId: options.profileId,
+    titleId: options.titleId,
+    mediaType: options.mediaType,
+    seasonNumber: options.seasonNumber,
+    episodeNumber: options.episodeNumber,
+    manual: options.manualSelection,
+    alternatives: options.alternatives,
   });
 
   // Check if using external player - they handle HDR natively and don't need HLS
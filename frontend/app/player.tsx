@@ -517,6 +517,7 @@ export default function PlayerScreen() {
           season: seasonNumber,
           episode: episodeNumber,
           language,
+          path: sourcePath,
         });
         // Only update results if this is still the current search (user hasn't switched language)
         if (currentSubtitleSearchLanguageRef.current === language) {
@@ -536,7 +537,7 @@ export default function PlayerScreen() {
         }
       }
     },
-    [imdbId, title, seriesTitle, year, seasonNumber, episodeNumber],
+    [imdbId, title, seriesTitle, year, seasonNumber, episodeNumber, sourcePath],
   );
 
   const handleSelectExternalSubtitle = useCallback(
@@ -585,6 +586,7 @@ export default function PlayerScreen() {
           season: seasonNumber,
           episode: episodeNumber,
           language,
+          path: sourcePath,
         });
 
         console.log('[player] auto-subtitle search returned', results.length, 'results');
@@ -670,6 +672,7 @@ export default function PlayerScreen() {
       year,
       seasonNumber,
       episodeNumber,
+      sourcePath,
       releaseName,
       isEnglishLanguage,
       subtitleStreamMetadata,
@@ -699,13 +702,7 @@ export default function PlayerScreen() {
       return;
     }
 
-    // Check if OpenSubtitles credentials are configured
-    const hasCredentials = settings?.subtitles?.openSubtitlesUsername && settings?.subtitles?.openSubtitlesPassword;
-    if (!hasCredentials) {
-      console.log('[player] auto-subtitle search skipped: no OpenSubtitles credentials configured');
-      return;
-    }
-
+    // No credential check: Podnapisi needs none, and the backend skips OpenSubtitles without an API key
     console.log('[player] triggering auto-subtitle search for language:', subtitleLang);
     autoSubtitleTriggeredRef.current = true;
     performAutoSubtitleSearch(subtitleLang);
@@ -1390,6 +1387,8 @@ export default function PlayerScreen() {
 
   // Track if we've already shown a fatal error alert (to prevent duplicate alerts)
   const hasShownFatalErrorRef = useRef(false);
+  // Source failovers already announced for the current HLS session
+  const shownSourceChangesRef = useRef(0);
 
   // Set up callback refs for HLS session hook
   useEffect(() => {
@@ -1471,6 +1470,11 @@ export default function PlayerScreen() {
         showToast(`Stream error: ${status.fatalError}`, { tone: 'danger', duration: 5000 });
         router.back();
       }
+      if (status && status.sourceChanges > shownSourceChangesRef.current) {
+        shownSourceChangesRef.current = status.sourceChanges;
+        console.log('[player] HLS session switched to another release:', status.sourceChanges);
+        showToast('The release stopped working, switched to the next best one.', { duration: 4000 });
+      }
     }, 10000);
 
     console.log('[player] started keepalive/status interval for HLS stream');
@@ -1479,6 +1483,7 @@ export default function PlayerScreen() {
       console.log('[player] stopping keepalive/status pings (unmounted or stream changed)');
       clearInterval(intervalId);
       hasShownFatalErrorRef.current = false;
+      shownSourceChangesRef.current = 0;
     };
   }, [isHlsStream, hlsSessionActions, hlsSessionIdRef, showToast, router]);
 
@@ -2829,6 +2834,8 @@ export default function PlayerScreen() {
         titleName: cleanSeriesTitle || title || '',
         mediaType: 'series',
         userId: activeUserId,
+        imdbId: imdbId || undefined,
+        tvdbId: tvdbId || undefined,
         seasonNumber: nextEpisode.seasonNumber,
         episodeNumber: nextEpisode.episodeNumber,
       });
@@ -152,6 +152,7 @@ export interface BackendFilterSettings {
 }
 
 export interface BackendSubtitleSettings {
+  openSubtitlesApiKey?: string;
   openSubtitlesUsername?: string;
   openSubtitlesPassword?: string;
 }
@@ -173,8 +174,26 @@ export interface BackendRankingCriterion {
   order: number;
 }
 
+export interface BackendCustomFormat {
+  id: string;
+  name: string;
+  enabled: boolean;
+  score: number;
+  releaseTitle?: string;
+  releaseGroup?: string;
+  codec?: string;
+  source?: string;
+  audioFormat?: string;
+  resolution?: string;
+  hdr?: string;
+  remux?: '' | 'only' | 'exclude';
+}
+
 export interface BackendRankingSettings {
   criteria: BackendRankingCriterion[];
+  customFormats?: BackendCustomFormat[];
+  audioPreference?: string[];
+  videoCodecPreference?: string[];
 }
 
 export interface BackendSettings {
@@ -123,7 +123,7 @@ function mergeAndSortResults(results: SearchResult[]): SearchResult[] {
   return Array.from(deduped.values()).sort((a, b) => b.score - a.score);
 }
 
-export function useSearchTitles(query: string): UseApiState<SearchResult[]> {
+export function useSearchTitles(query: string, userId?: string | null): UseApiState<SearchResult[]> {
   const { backendUrl, isReady } = useBackendSettings();
   const [data, setData] = useState<SearchResult[] | null>(null);
   const [loading, setLoading] = useState(false);
@@ -185,7 +185,7 @@ export function useSearchTitles(query: string): UseApiState<SearchResult[]> {
 
     // Fire both searches independently - show results as they arrive
     apiService
-      .searchMovies(debouncedQuery)
+      .searchMovies(debouncedQuery, userId ?? undefined)
       .then((results) => {
         handleResults(results, 'movie');
         handleComplete();
@@ -193,7 +193,7 @@ export function useSearchTitles(query: string): UseApiState<SearchResult[]> {
       .catch(handleError);
 
     apiService
-      .searchTVShows(debouncedQuery)
+      .searchTVShows(debouncedQuery, userId ?? undefined)
       .then((results) => {
         handleResults(results, 'series');
         handleComplete();
@@ -203,7 +203,7 @@ export function useSearchTitles(query: string): UseApiState<SearchResult[]> {
     return () => {
       cancelled = true;
     };
-  }, [debouncedQuery, backendUrl, isReady, refreshToken]);
+  }, [debouncedQuery, userId, backendUrl, isReady, refreshToken]);
 
   // Memoize return value to prevent unnecessary re-renders of consumers
   return useMemo(() => ({ data, loading, error, refetch }), [data, loading, error, refetch]);
@@ -121,6 +121,7 @@ export interface HlsSessionActions {
     duration: number;
     segmentsCreated: number;
     fatalError?: string;
+    sourceChanges: number;
   } | null>;
   /** Build full playlist URL with auth token */
   buildPlaylistUrl: (playlistPath: string) => string;
@@ -525,6 +526,7 @@ export function useHlsSession(options: HlsSessionOptions): [HlsSessionState, Hls
         duration: status.duration || 0,
         segmentsCreated: status.segmentsCreated,
         fatalError: status.fatalError,
+        sourceChanges: status.sourceChanges ?? 0,
       };
     } catch (error) {
       console.warn('[useHlsSession] Get status failed:', error);
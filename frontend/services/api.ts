@@ -136,6 +136,8 @@ export interface SubtitleSearchResult {
   downloads: number;
   hearing_impaired: boolean;
   page_link?: string;
+  // Subtitle was made for the exact release being played (matched by file hash)
+  hash_match?: boolean;
 }
 
 export interface SeriesEpisode {
@@ -249,7 +251,7 @@ export interface NZBResult {
   publishDate: string;
   categories?: string[];
   attributes?: Record<string, string>;
-  serviceType?: 'usenet' | 'debrid';
+  serviceType?: 'usenet' | 'debrid' | 'direct'; // direct: stream URL already resolved by a Stremio addon
   episodeCount?: number; // Number of episodes in pack (0 if not a pack)
 }
 
@@ -383,6 +385,10 @@ export interface ClientFilterSettings {
   prioritizeHdr?: boolean;
   filterOutTerms?: string[];
   preferredTerms?: string[];
+  // Codec preference orders for ranking, most preferred first
+  audioPreference?: string[];
+  videoCodecPreference?: string[];
+  forceAacTranscoding?: boolean;
   // Network settings for URL switching based on WiFi
   homeWifiSSID?: string;
   homeBackendUrl?: string;
@@ -410,6 +416,8 @@ export interface HlsSessionStatus {
   hdrMetadataDisabled: boolean;
   dvDisabled: boolean;
   recoveryAttempts: number;
+  sourceChanges: number; // Times the session failed over to another release
+  sourceChangedAt?: number; // Unix timestamp
 }
 
 export interface HlsSeekResponse {
@@ -736,6 +744,7 @@ export interface PrequeueRequest {
   mediaType: string; // "movie" or "series"
   userId: string;
   imdbId?: string;
+  tvdbId?: string; // Series TVDB ID for indexers that search by it
   year?: number;
   seasonNumber?: number;
   episodeNumber?: number;
@@ -1111,8 +1120,12 @@ class ApiService {
     limit?: number,
     offset?: number,
     hideUnreleased?: boolean,
+    userId?: string,
   ): Promise<{ items: TrendingItem[]; total: number; unfilteredTotal?: number }> {
     const params = new URLSearchParams({ url: listUrl });
+    if (userId) {
+      params.set('userId', userId);
+    }
     if (limit && limit > 0) {
       params.set('limit', limit.toString());
     }
@@ -1128,15 +1141,21 @@ class ApiService {
   }
 
   // Search movies
-  async searchMovies(query: string): Promise<SearchResult[]> {
-    const encodedQuery = encodeURIComponent(query);
-    return this.request<SearchResult[]>(`/search?q=${encodedQuery}&type=movie`);
+  async searchMovies(query: string, userId?: string): Promise<SearchResult[]> {
+    const params = new URLSearchParams({ q: query, type: 'movie' });
+    if (userId) {
+      params.set('userId', userId);
+    }
+    return this.request<SearchResult[]>(`/search?${params.toString()}`);
   }
 
   // Search TV shows
-  async searchTVShows(query: string): Promise<SearchResult[]> {
-    const encodedQuery = encodeURIComponent(query);
-    return this.request<SearchResult[]>(`/search?q=${encodedQuery}&type=series`);
+  async searchTVShows(query: string, userId?: string): Promise<SearchResult[]> {
+    const params = new URLSearchParams({ q: query, type: 'series' });
+    if (userId) {
+      params.set('userId', userId);
+    }
+    return this.request<SearchResult[]>(`/search?${params.toString()}`);
   }
 
   async getSeriesDetails(params: {
@@ -1439,6 +1458,7 @@ class ApiService {
     mediaType?: string,
     year?: number,
     userId?: string,
+    tvdbId?: string,
   ): Promise<NZBResult[]> {
     const params = new URLSearchParams();
     if (query) {
@@ -1450,6 +1470,9 @@ class ApiService {
     if (imdbId) {
       params.append('imdbId', imdbId);
     }
+    if (tvdbId) {
+      params.append('tvdbId', tvdbId);
+    }
     if (mediaType) {
       params.append('mediaType', mediaType);
     }
@@ -1467,12 +1490,35 @@ class ApiService {
 
   async resolvePlayback(
     result: NZBResult,
-    options?: { onStatus?: (update: PlaybackResolutionResponse) => void; signal?: AbortSignal; startOffset?: number },
+    options?: {
+      onStatus?: (update: PlaybackResolutionResponse) => void;
+      signal?: AbortSignal;
+      startOffset?: number;
+      // Title context so the backend remembers this release for resume and next episode
+      userId?: string;
+      titleId?: string;
+      mediaType?: string;
+      seasonNumber?: number;
+      episodeNumber?: number;
+      manual?: boolean;
+      // Ranked results below this one, tried in order if the stream breaks during HLS playback
+      alternatives?: NZBResult[];
+    },
   ): Promise<PlaybackResolution> {
     try {
       const initial = await this.request<PlaybackResolutionResponse>('/playback/resolve', {
         method: 'POST',
-        body: JSON.stringify({ result, startOffset: options?.startOffset }),
+        body: JSON.stringify({
+          result,
+          startOffset: options?.startOffset,
+          userId: options?.userId,
+          titleId: options?.titleId,
+          mediaType: options?.mediaType,
+          seasonNumber: options?.seasonNumber,
+          episodeNumber: options?.episodeNumber,
+          manual: options?.manual,
+          alternatives: options?.alternatives,
+        }),
         signal: options?.signal,
       });
 
@@ -2350,6 +2396,8 @@ class ApiService {
     season?: number;
     episode?: number;
     language?: string;
+    // Stream path of the release being played, hashed to find subtitles made for it
+    path?: string;
   }): Promise<SubtitleSearchResult[]> {
     const query = new URLSearchParams();
     if (params.imdbId) query.set('imdbId', params.imdbId);
@@ -2358,6 +2406,7 @@ class ApiService {
     if (params.season !== undefined) query.set('season', String(params.season));
     if (params.episode !== undefined) query.set('episode', String(params.episode));
     if (params.language) query.set('language', params.language);
+    if (params.path) query.set('path', params.path);
 
     return this.request<SubtitleSearchResult[]>(`/subtitles/search?${query.toString()}`);
   }